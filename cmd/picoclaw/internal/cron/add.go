@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,8 +15,17 @@ func newAddCommand(storePath func() string) *cobra.Command {
 		message string
 		every   int64
 		cronExp string
+		phrase  string
+		tz      string
 		channel string
 		to      string
+		misfire string
+		grace   time.Duration
+		retries int
+		backoff time.Duration
+		manual  bool
+		silent  bool
+		flow    string
 	)
 
 	cmd := &cobra.Command{
@@ -23,16 +33,34 @@ func newAddCommand(storePath func() string) *cobra.Command {
 		Short: "Add a new scheduled job",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			if every <= 0 && cronExp == "" {
-				return fmt.Errorf("either --every or --cron must be specified")
+			if every <= 0 && cronExp == "" && phrase == "" && !manual {
+				return fmt.Errorf("one of --every, --cron, --schedule or --manual must be specified")
+			}
+			loc, err := cron.LoadLocation(tz)
+			if err != nil {
+				return fmt.Errorf("unknown timezone %q", tz)
+			}
+			if err := cron.ValidateMisfireMode(misfire); err != nil {
+				return err
+			}
+			if retries < 0 {
+				return fmt.Errorf("--retries must not be negative")
 			}
 
 			var schedule cron.CronSchedule
-			if every > 0 {
+			switch {
+			case manual:
+				schedule = cron.CronSchedule{Kind: cron.ScheduleManual}
+			case phrase != "":
+				schedule, err = cron.ParseSchedule(phrase, time.Now(), loc)
+				if err != nil {
+					return fmt.Errorf("invalid --schedule: %w", err)
+				}
+			case every > 0:
 				everyMS := every * 1000
 				schedule = cron.CronSchedule{Kind: "every", EveryMS: &everyMS}
-			} else {
-				schedule = cron.CronSchedule{Kind: "cron", Expr: cronExp}
+			default:
+				schedule = cron.CronSchedule{Kind: "cron", Expr: cronExp, TZ: tz}
 			}
 
 			cs := cron.NewCronService(storePath(), nil)
@@ -41,7 +69,21 @@ func newAddCommand(storePath func() string) *cobra.Command {
 				return fmt.Errorf("error adding job: %w", err)
 			}
 
+			if misfire != "" || retries > 0 || silent || flow != "" {
+				job.Payload.Silent = silent
+				job.Payload.Workflow = flow
+				job.Misfire = cron.MisfirePolicy{Mode: misfire, GraceMS: grace.Milliseconds()}
+				job.Retry = cron.RetryPolicy{MaxRetries: retries, BackoffMS: backoff.Milliseconds()}
+				if err := cs.UpdateJob(job); err != nil {
+					return fmt.Errorf("error saving job policies: %w", err)
+				}
+			}
+
 			fmt.Printf("✓ Added job '%s' (%s)\n", job.Name, job.ID)
+			if job.State.NextRunAtMS != nil {
+				next := time.UnixMilli(*job.State.NextRunAtMS).In(loc)
+				fmt.Printf("  Next run: %s\n", next.Format("2006-01-02 15:04 MST"))
+			}
 
 			return nil
 		},
@@ -51,12 +93,23 @@ func newAddCommand(storePath func() string) *cobra.Command {
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Message for agent")
 	cmd.Flags().Int64VarP(&every, "every", "e", 0, "Run every N seconds")
 	cmd.Flags().StringVarP(&cronExp, "cron", "c", "", "Cron expression (e.g. '0 9 * * *')")
+	cmd.Flags().StringVarP(&phrase, "schedule", "s", "",
+		"Schedule in plain English (e.g. 'every weekday at 8:30', 'in 20 minutes')")
+	cmd.Flags().StringVar(&tz, "tz", "", "IANA timezone for wall-clock schedules (default: system timezone)")
 	cmd.Flags().StringVar(&to, "to", "", "Recipient for delivery")
 	cmd.Flags().StringVar(&channel, "channel", "", "Channel for delivery")
+	cmd.Flags().StringVar(&misfire, "misfire", "",
+		"What to do with runs missed while stopped: skip, run_once or run_all (default skip)")
+	cmd.Flags().DurationVar(&grace, "misfire-grace", 0, "Only catch up runs missed within this window (e.g. 2h)")
+	cmd.Flags().IntVar(&retries, "retries", 0, "Retry a failed run up to N times")
+	cmd.Flags().DurationVar(&backoff, "retry-backoff", 0, "Initial retry backoff, doubled on each attempt (default 30s)")
+	cmd.Flags().BoolVar(&manual, "manual", false, "Never run on a schedule; only when triggered by another job")
+	cmd.Flags().BoolVar(&silent, "silent", false, "Do not deliver the job's output; useful for jobs that only feed a chain")
+	cmd.Flags().StringVar(&flow, "workflow", "", "Run this workspace workflow with the message as its input")
 
 	_ = cmd.MarkFlagRequired("name")
 	_ = cmd.MarkFlagRequired("message")
-	cmd.MarkFlagsMutuallyExclusive("every", "cron")
+	cmd.MarkFlagsMutuallyExclusive("every", "cron", "schedule", "manual")
 
 	return cmd
 }
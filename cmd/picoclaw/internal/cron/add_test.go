@@ -1,11 +1,14 @@
 package cron
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
 )
 
 func TestNewAddSubcommand(t *testing.T) {
@@ -21,6 +24,11 @@ func TestNewAddSubcommand(t *testing.T) {
 
 	assert.NotNil(t, cmd.Flags().Lookup("every"))
 	assert.NotNil(t, cmd.Flags().Lookup("cron"))
+	assert.NotNil(t, cmd.Flags().Lookup("schedule"))
+	assert.NotNil(t, cmd.Flags().Lookup("tz"))
+	assert.NotNil(t, cmd.Flags().Lookup("manual"))
+	assert.NotNil(t, cmd.Flags().Lookup("silent"))
+	assert.NotNil(t, cmd.Flags().Lookup("workflow"))
 	assert.NotNil(t, cmd.Flags().Lookup("to"))
 	assert.NotNil(t, cmd.Flags().Lookup("channel"))
 
@@ -54,3 +62,54 @@ func TestNewAddCommandEveryAndCronMutuallyExclusive(t *testing.T) {
 	err := cmd.Execute()
 	require.Error(t, err)
 }
+
+func TestNewAddCommandSchedule(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cmd := newAddCommand(func() string { return storePath })
+
+	cmd.SetArgs([]string{
+		"--name", "standup",
+		"--message", "hello",
+		"--schedule", "every weekday at 8:30",
+		"--tz", "Asia/Shanghai",
+	})
+	require.NoError(t, cmd.Execute())
+
+	jobs := cron.NewCronService(storePath, nil).ListJobs(true)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "cron", jobs[0].Schedule.Kind)
+	assert.Equal(t, "30 8 * * 1-5", jobs[0].Schedule.Expr)
+	assert.Equal(t, "Asia/Shanghai", jobs[0].Schedule.TZ)
+}
+
+func TestNewAddCommandRejectsUnknownSchedule(t *testing.T) {
+	cmd := newAddCommand(func() string { return filepath.Join(t.TempDir(), "jobs.json") })
+
+	cmd.SetArgs([]string{
+		"--name", "job",
+		"--message", "hello",
+		"--schedule", "whenever",
+	})
+	require.Error(t, cmd.Execute())
+}
+
+func TestNewAddCommandManual(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cmd := newAddCommand(func() string { return storePath })
+
+	cmd.SetArgs([]string{
+		"--name", "notify",
+		"--message", "hello",
+		"--manual",
+		"--silent",
+		"--workflow", "digest",
+	})
+	require.NoError(t, cmd.Execute())
+
+	jobs := cron.NewCronService(storePath, nil).ListJobs(true)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, cron.ScheduleManual, jobs[0].Schedule.Kind)
+	assert.Nil(t, jobs[0].State.NextRunAtMS)
+	assert.True(t, jobs[0].Payload.Silent)
+	assert.Equal(t, "digest", jobs[0].Payload.Workflow)
+}
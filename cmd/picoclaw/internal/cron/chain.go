@@ -0,0 +1,69 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+func newChainCommand(storePath func() string) *cobra.Command {
+	var (
+		then       string
+		on         string
+		passOutput bool
+		ifRegex    string
+		ifJSON     string
+		op         string
+		value      string
+		ifLLM      string
+		negate     bool
+		clear      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "chain",
+		Short: "Trigger another job after a job runs",
+		Args:  cobra.ExactArgs(1),
+		Example: `picoclaw cron chain 1 --then 2 --if-regex 'rain|snow' --pass-output
+picoclaw cron chain 1 --then 3 --on failure
+picoclaw cron chain 1 --clear`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if !clear && then == "" {
+				return fmt.Errorf("one of --then or --clear must be specified")
+			}
+
+			action := cron.CronAction{On: on, JobID: then, PassOutput: passOutput}
+			switch {
+			case ifRegex != "":
+				action.If = &cron.CronCondition{Kind: cron.ConditionRegex, Pattern: ifRegex}
+			case ifJSON != "":
+				action.If = &cron.CronCondition{Kind: cron.ConditionJSON, Path: ifJSON, Op: op, Value: value}
+			case ifLLM != "":
+				action.If = &cron.CronCondition{Kind: cron.ConditionLLM, Question: ifLLM}
+			}
+			if action.If != nil {
+				action.If.Negate = negate
+			}
+
+			return cronChainCmd(storePath(), args[0], action, clear)
+		},
+	}
+
+	cmd.Flags().StringVar(&then, "then", "", "ID of the job to trigger")
+	cmd.Flags().StringVar(&on, "on", "", "Outcome to trigger on: success, failure or always (default success)")
+	cmd.Flags().BoolVar(&passOutput, "pass-output", false, "Hand this job's output to the triggered job")
+	cmd.Flags().StringVar(&ifRegex, "if-regex", "", "Only trigger when the output matches this regular expression")
+	cmd.Flags().StringVar(&ifJSON, "if-json", "", "Only trigger when this path in the JSON output matches (e.g. '$.rain')")
+	cmd.Flags().StringVar(&op, "op", "", "Comparison for --if-json: ==, !=, >, >=, <, <= or contains")
+	cmd.Flags().StringVar(&value, "value", "", "Value compared by --op")
+	cmd.Flags().StringVar(&ifLLM, "if-llm", "", "Only trigger when the model answers yes to this question about the output")
+	cmd.Flags().BoolVar(&negate, "negate", false, "Invert the condition")
+	cmd.Flags().BoolVar(&clear, "clear", false, "Remove all jobs triggered by this job")
+
+	cmd.MarkFlagsMutuallyExclusive("if-regex", "if-json", "if-llm")
+	cmd.MarkFlagsMutuallyExclusive("then", "clear")
+
+	return cmd
+}
@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+func TestNewChainSubcommand(t *testing.T) {
+	cmd := newChainCommand(func() string { return "" })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "chain", cmd.Use)
+	assert.Equal(t, "Trigger another job after a job runs", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+	for _, name := range []string{"then", "on", "pass-output", "if-regex", "if-json", "op", "value", "if-llm", "negate", "clear"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), name)
+	}
+}
+
+func TestChainCommand(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cs := cron.NewCronService(storePath, nil)
+	everyMS := int64(60_000)
+	weather, err := cs.AddJob("weather", cron.CronSchedule{Kind: "every", EveryMS: &everyMS}, "check weather", "", "")
+	require.NoError(t, err)
+	notify, err := cs.AddJob("notify", cron.CronSchedule{Kind: cron.ScheduleManual}, "tell me", "", "")
+	require.NoError(t, err)
+
+	cmd := newChainCommand(func() string { return storePath })
+	cmd.SetArgs([]string{weather.ID, "--then", notify.ID, "--if-regex", "rain", "--pass-output"})
+	require.NoError(t, cmd.Execute())
+
+	job, ok := cron.NewCronService(storePath, nil).GetJob(weather.ID)
+	require.True(t, ok)
+	require.Len(t, job.Payload.Then, 1)
+	assert.Equal(t, notify.ID, job.Payload.Then[0].JobID)
+	assert.True(t, job.Payload.Then[0].PassOutput)
+	require.NotNil(t, job.Payload.Then[0].If)
+	assert.Equal(t, "rain", job.Payload.Then[0].If.Pattern)
+
+	// Closing the loop is rejected.
+	cmd = newChainCommand(func() string { return storePath })
+	cmd.SetArgs([]string{notify.ID, "--then", weather.ID})
+	require.Error(t, cmd.Execute())
+
+	cmd = newChainCommand(func() string { return storePath })
+	cmd.SetArgs([]string{weather.ID, "--clear"})
+	require.NoError(t, cmd.Execute())
+
+	job, _ = cron.NewCronService(storePath, nil).GetJob(weather.ID)
+	assert.Empty(t, job.Payload.Then)
+}
@@ -35,6 +35,8 @@ func TestNewCronCommand(t *testing.T) {
 		"remove",
 		"enable",
 		"disable",
+		"history",
+		"chain",
 	}
 
 	subcommands := cmd.Commands()
@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/cron"
@@ -24,11 +25,19 @@ func cronListCmd(storePath string) {
 			schedule = fmt.Sprintf("every %ds", *job.Schedule.EveryMS/1000)
 		} else if job.Schedule.Kind == "cron" {
 			schedule = job.Schedule.Expr
+			if job.Schedule.TZ != "" {
+				schedule += " (" + job.Schedule.TZ + ")"
+			}
+		} else if job.Schedule.Kind == cron.ScheduleManual {
+			schedule = "manual (triggered by other jobs)"
 		} else {
 			schedule = "one-time"
 		}
 
 		nextRun := "scheduled"
+		if job.Schedule.Kind == cron.ScheduleManual {
+			nextRun = "on trigger"
+		}
 		if job.State.NextRunAtMS != nil {
 			nextTime := time.UnixMilli(*job.State.NextRunAtMS)
 			nextRun = nextTime.Format("2006-01-02 15:04")
@@ -43,7 +52,102 @@ func cronListCmd(storePath string) {
 		fmt.Printf("    Schedule: %s\n", schedule)
 		fmt.Printf("    Status: %s\n", status)
 		fmt.Printf("    Next run: %s\n", nextRun)
+		if job.State.LastStatus != "" && job.State.LastRunAtMS != nil {
+			lastRun := time.UnixMilli(*job.State.LastRunAtMS).Format("2006-01-02 15:04")
+			fmt.Printf("    Last run: %s (%s)\n", lastRun, job.State.LastStatus)
+		}
+		if job.Misfire.Mode != "" && job.Misfire.Mode != cron.MisfireSkip {
+			fmt.Printf("    Misfire: %s\n", job.Misfire.Mode)
+		}
+		if job.Retry.MaxRetries > 0 {
+			fmt.Printf("    Retries: %d\n", job.Retry.MaxRetries)
+		}
+		if job.Payload.Workflow != "" {
+			fmt.Printf("    Workflow: %s\n", job.Payload.Workflow)
+		}
+		if job.Payload.Silent {
+			fmt.Println("    Delivery: silent")
+		}
+		for _, action := range job.Payload.Then {
+			target := ""
+			if t, ok := cs.GetJob(action.JobID); ok {
+				target = t.Name
+			}
+			fmt.Printf("    Then: %s\n", cron.DescribeAction(action, target))
+		}
+	}
+}
+
+func cronHistoryCmd(storePath, jobID string, limit int) error {
+	cs := cron.NewCronService(storePath, nil)
+	job, ok := cs.GetJob(jobID)
+	if !ok {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+
+	runs, err := cs.History(jobID, limit)
+	if err != nil {
+		return fmt.Errorf("error reading history: %w", err)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("Job '%s' has no recorded runs.\n", job.Name)
+		return nil
+	}
+
+	fmt.Printf("\nRun history of '%s' (%s):\n", job.Name, job.ID)
+	fmt.Println("----------------")
+	for _, run := range runs {
+		started := time.UnixMilli(run.StartedAtMS).Format("2006-01-02 15:04:05")
+		fmt.Printf("  %s  %-6s  %-8s  %6dms", started, run.Status, run.Trigger, run.DurationMS)
+		if run.Attempt > 1 {
+			fmt.Printf("  attempt %d", run.Attempt)
+		}
+		if run.TriggeredBy != "" {
+			fmt.Printf("  ← %s", run.TriggeredBy)
+		}
+		if run.DeliveredTo != "" {
+			fmt.Printf("  → %s", run.DeliveredTo)
+		}
+		fmt.Println()
+		if run.Error != "" {
+			fmt.Printf("    Error: %s\n", run.Error)
+		}
+		if run.Output != "" {
+			fmt.Printf("    Output: %s\n", run.Output)
+		}
+	}
+	return nil
+}
+
+func cronChainCmd(storePath, jobID string, action cron.CronAction, clear bool) error {
+	cs := cron.NewCronService(storePath, nil)
+	job, ok := cs.GetJob(jobID)
+	if !ok {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+
+	actions := append(slices.Clone(job.Payload.Then), action)
+	if clear {
+		actions = nil
+	}
+	job, err := cs.SetActions(jobID, actions)
+	if err != nil {
+		return err
+	}
+
+	if len(job.Payload.Then) == 0 {
+		fmt.Printf("✓ Job '%s' no longer triggers other jobs\n", job.Name)
+		return nil
+	}
+	fmt.Printf("✓ Chain of '%s':\n", job.Name)
+	for _, a := range job.Payload.Then {
+		target := ""
+		if t, ok := cs.GetJob(a.JobID); ok {
+			target = t.Name
+		}
+		fmt.Printf("  %s\n", cron.DescribeAction(a, target))
 	}
+	return nil
 }
 
 func cronRemoveCmd(storePath, jobID string) {
@@ -0,0 +1,32 @@
+package cron
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistorySubcommand(t *testing.T) {
+	fn := func() string { return "" }
+	cmd := newHistoryCommand(fn)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "history", cmd.Use)
+	assert.Equal(t, "Show the run history of a job", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+	assert.NotNil(t, cmd.Flags().Lookup("limit"))
+}
+
+func TestHistoryCommandUnknownJob(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cmd := newHistoryCommand(func() string { return storePath })
+	cmd.SetArgs([]string{"missing"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
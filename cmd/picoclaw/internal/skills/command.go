@@ -81,6 +81,9 @@ func NewSkillsCommand() *cobra.Command {
 		newRemoveCommand(installerFn),
 		newSearchCommand(),
 		newShowCommand(loaderFn),
+		newUpdateCommand(installerFn),
+		newSyncCommand(installerFn),
+		newVetCommand(),
 	)
 
 	return cmd
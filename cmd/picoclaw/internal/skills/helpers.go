@@ -2,6 +2,7 @@ package skills
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,6 +18,60 @@ import (
 
 const skillsSearchMaxResults = 20
 
+func newRegistryManager(cfg *config.Config) *skills.RegistryManager {
+	clawHubConfig := cfg.Tools.Skills.Registries.ClawHub
+	return skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
+		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
+		ClawHub: skills.ClawHubConfig{
+			Enabled:         clawHubConfig.Enabled,
+			BaseURL:         clawHubConfig.BaseURL,
+			AuthToken:       clawHubConfig.AuthToken.String(),
+			SearchPath:      clawHubConfig.SearchPath,
+			SkillsPath:      clawHubConfig.SkillsPath,
+			DownloadPath:    clawHubConfig.DownloadPath,
+			Timeout:         clawHubConfig.Timeout,
+			MaxZipSize:      clawHubConfig.MaxZipSize,
+			MaxResponseSize: clawHubConfig.MaxResponseSize,
+		},
+	})
+}
+
+func securityPolicy(cfg *config.Config) skills.SecurityPolicy {
+	security := cfg.Tools.Skills.Security
+	return skills.SecurityPolicy{
+		TrustedKeys:      security.TrustedKeys,
+		RequireSignature: security.RequireSignature,
+		BlockOn:          security.BlockOn,
+		MaxBinarySize:    int64(security.MaxBinarySizeKB) * 1024,
+		AllowedHosts:     security.AllowedHosts,
+	}
+}
+
+// reviewSkill prints the security report of a downloaded skill and asks
+// before installing a flagged one. assumeYes installs flagged skills without
+// asking.
+func reviewSkill(assumeYes bool) func(*skills.SecurityReport) bool {
+	return func(report *skills.SecurityReport) bool {
+		fmt.Println(report.Format())
+		if report.Verdict != skills.VerdictFlag || assumeYes {
+			return true
+		}
+		fmt.Print("Install anyway? (y/n): ")
+		var response string
+		fmt.Scanln(&response)
+		return response == "y"
+	}
+}
+
+// printBlockedReport prints the report of a skill the security policy
+// blocked; reports of other skills were shown by reviewSkill.
+func printBlockedReport(err error) {
+	var securityErr *skills.SecurityError
+	if errors.As(err, &securityErr) && securityErr.Report.Verdict == skills.VerdictBlock {
+		fmt.Println(securityErr.Report.Format())
+	}
+}
+
 func skillsListCmd(loader *skills.SkillsLoader) {
 	allSkills := loader.ListSkills()
 
@@ -42,6 +97,7 @@ func skillsInstallCmd(installer *skills.SkillInstaller, repo string) error {
 	defer cancel()
 
 	if err := installer.InstallFromGitHub(ctx, repo); err != nil {
+		printBlockedReport(err)
 		return fmt.Errorf("failed to install skill: %w", err)
 	}
 
@@ -51,7 +107,11 @@ func skillsInstallCmd(installer *skills.SkillInstaller, repo string) error {
 }
 
 // skillsInstallFromRegistry installs a skill from a named registry (e.g. clawhub).
-func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) error {
+func skillsInstallFromRegistry(
+	cfg *config.Config,
+	installer *skills.SkillInstaller,
+	registryName, slug string,
+) error {
 	err := utils.ValidateSkillIdentifier(registryName)
 	if err != nil {
 		return fmt.Errorf("✗  invalid registry name: %w", err)
@@ -64,21 +124,7 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 
 	fmt.Printf("Installing skill '%s' from %s registry...\n", slug, registryName)
 
-	clawHubConfig := cfg.Tools.Skills.Registries.ClawHub
-	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
-		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
-		ClawHub: skills.ClawHubConfig{
-			Enabled:         clawHubConfig.Enabled,
-			BaseURL:         clawHubConfig.BaseURL,
-			AuthToken:       clawHubConfig.AuthToken.String(),
-			SearchPath:      clawHubConfig.SearchPath,
-			SkillsPath:      clawHubConfig.SkillsPath,
-			DownloadPath:    clawHubConfig.DownloadPath,
-			Timeout:         clawHubConfig.Timeout,
-			MaxZipSize:      clawHubConfig.MaxZipSize,
-			MaxResponseSize: clawHubConfig.MaxResponseSize,
-		},
-	})
+	registryMgr := newRegistryManager(cfg)
 
 	registry := registryMgr.GetRegistry(registryName)
 	if registry == nil {
@@ -95,25 +141,26 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	if err = os.MkdirAll(filepath.Join(workspace, "skills"), 0o755); err != nil {
+	skillsDir := filepath.Join(workspace, "skills")
+	if err = os.MkdirAll(skillsDir, 0o755); err != nil {
 		return fmt.Errorf("\u2717 failed to create skills directory: %v", err)
 	}
 
-	result, err := registry.DownloadAndInstall(ctx, slug, "", targetDir)
+	// Download next to the skills, where the loader does not look, and move
+	// the skill into place once it is vetted.
+	stageRoot, err := os.MkdirTemp(skillsDir, "."+slug+"-install-*")
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to prepare install: %v", err)
+	}
+	defer os.RemoveAll(stageRoot)
+	stagedDir := filepath.Join(stageRoot, slug)
+
+	result, err := registry.DownloadAndInstall(ctx, slug, "", stagedDir)
 	if err != nil {
-		rmErr := os.RemoveAll(targetDir)
-		if rmErr != nil {
-			fmt.Printf("\u2717 Failed to remove partial install: %v\n", rmErr)
-		}
 		return fmt.Errorf("✗ failed to install skill: %w", err)
 	}
 
 	if result.IsMalwareBlocked {
-		rmErr := os.RemoveAll(targetDir)
-		if rmErr != nil {
-			fmt.Printf("\u2717 Failed to remove partial install: %v\n", rmErr)
-		}
-
 		return fmt.Errorf("\u2717 Skill '%s' is flagged as malicious and cannot be installed.\n", slug)
 	}
 
@@ -121,6 +168,23 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 		fmt.Printf("\u26a0\ufe0f  Warning: skill '%s' is flagged as suspicious.\n", slug)
 	}
 
+	if err = installer.Vet(stagedDir, slug); err != nil {
+		printBlockedReport(err)
+		return fmt.Errorf("\u2717 %w", err)
+	}
+	if err = os.Rename(stagedDir, targetDir); err != nil {
+		return fmt.Errorf("\u2717 failed to activate skill: %v", err)
+	}
+
+	err = skills.RecordInstall(workspace, slug, skills.LockEntry{
+		Source:  registry.Name(),
+		Slug:    slug,
+		Version: result.Version,
+	})
+	if err != nil {
+		fmt.Printf("\u26a0\ufe0f  Warning: skill not recorded in %s: %v\n", skills.LockFileName, err)
+	}
+
 	fmt.Printf("\u2713 Skill '%s' v%s installed successfully!\n", slug, result.Version)
 	if result.Summary != "" {
 		fmt.Printf("  %s\n", result.Summary)
@@ -140,6 +204,82 @@ func skillsRemoveCmd(installer *skills.SkillInstaller, skillName string) {
 	fmt.Printf("✓ Skill '%s' removed successfully!\n", skillName)
 }
 
+func skillsUpdateCmd(
+	installer *skills.SkillInstaller,
+	registries *skills.RegistryManager,
+	name string,
+	force bool,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := installer.Update(ctx, registries, name, force)
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to update skills: %w", err)
+	}
+	return printUpdateResults(results)
+}
+
+func skillsSyncCmd(installer *skills.SkillInstaller, registries *skills.RegistryManager, force bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := installer.Sync(ctx, registries, force)
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to sync skills: %w", err)
+	}
+	return printUpdateResults(results)
+}
+
+func printUpdateResults(results []skills.UpdateResult) error {
+	if len(results) == 0 {
+		fmt.Printf("No skills recorded in %s.\n", skills.LockFileName)
+		return nil
+	}
+
+	failures := 0
+	for _, r := range results {
+		switch r.Status {
+		case skills.UpdateUpToDate:
+			fmt.Printf("  \u2713 %s: up to date (%s)\n", r.Name, r.To)
+		case skills.UpdateUpdated:
+			fmt.Printf("  \u2713 %s: updated %s -> %s\n", r.Name, r.From, r.To)
+		case skills.UpdateInstalled:
+			fmt.Printf("  \u2713 %s: installed %s\n", r.Name, r.To)
+		case skills.UpdateModified:
+			fmt.Printf("  \u2298 %s: modified locally, skipped (use --force to overwrite)\n", r.Name)
+		case skills.UpdateFailed:
+			failures++
+			printBlockedReport(r.Err)
+			fmt.Printf("  \u2717 %s: %v\n", r.Name, r.Err)
+		}
+		if r.HashMismatch {
+			fmt.Printf("    \u26a0\ufe0f  content differs from the hash in %s\n", skills.LockFileName)
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d skill(s) failed", failures)
+	}
+	return nil
+}
+
+func skillsVetCmd(cfg *config.Config, dir string) error {
+	info, err := os.Stat(filepath.Join(dir, "SKILL.md"))
+	if err != nil || info.IsDir() {
+		return fmt.Errorf("\u2717 %s is not a skill directory (no SKILL.md)", dir)
+	}
+	report, err := skills.VetSkill(dir, filepath.Base(filepath.Clean(dir)), securityPolicy(cfg))
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to vet skill: %w", err)
+	}
+	fmt.Println(report.Format())
+	if report.Verdict == skills.VerdictBlock {
+		return fmt.Errorf("skill would be blocked by the security policy")
+	}
+	return nil
+}
+
 func skillsInstallBuiltinCmd(workspace string) {
 	builtinSkillsDir := "./picoclaw/skills"
 	workspaceSkillsDir := filepath.Join(workspace, "skills")
@@ -237,21 +377,7 @@ func skillsSearchCmd(query string) {
 		return
 	}
 
-	clawHubConfig := cfg.Tools.Skills.Registries.ClawHub
-	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
-		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
-		ClawHub: skills.ClawHubConfig{
-			Enabled:         clawHubConfig.Enabled,
-			BaseURL:         clawHubConfig.BaseURL,
-			AuthToken:       clawHubConfig.AuthToken.String(),
-			SearchPath:      clawHubConfig.SearchPath,
-			SkillsPath:      clawHubConfig.SkillsPath,
-			DownloadPath:    clawHubConfig.DownloadPath,
-			Timeout:         clawHubConfig.Timeout,
-			MaxZipSize:      clawHubConfig.MaxZipSize,
-			MaxResponseSize: clawHubConfig.MaxResponseSize,
-		},
-	})
+	registryMgr := newRegistryManager(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -11,6 +11,7 @@ import (
 
 func newInstallCommand(installerFn func() (*skills.SkillInstaller, error)) *cobra.Command {
 	var registry string
+	var yes bool
 
 	cmd := &cobra.Command{
 		Use:   "install",
@@ -38,14 +39,14 @@ picoclaw skills install --registry clawhub github
 			if err != nil {
 				return err
 			}
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return err
+			}
+			installer.SetSecurity(securityPolicy(cfg), reviewSkill(yes))
 
 			if registry != "" {
-				cfg, err := internal.LoadConfig()
-				if err != nil {
-					return err
-				}
-
-				return skillsInstallFromRegistry(cfg, registry, args[0])
+				return skillsInstallFromRegistry(cfg, installer, registry, args[0])
 			}
 
 			return skillsInstallCmd(installer, args[0])
@@ -53,6 +54,7 @@ picoclaw skills install --registry clawhub github
 	}
 
 	cmd.Flags().StringVar(&registry, "registry", "", "Install from registry: --registry <name> <slug>")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Install skills flagged by the security scan without asking")
 
 	return cmd
 }
@@ -23,6 +23,7 @@ func TestNewInstallSubcommand(t *testing.T) {
 
 	assert.True(t, cmd.HasFlags())
 	assert.NotNil(t, cmd.Flags().Lookup("registry"))
+	assert.NotNil(t, cmd.Flags().Lookup("yes"))
 
 	assert.Len(t, cmd.Aliases, 0)
 }
@@ -0,0 +1,38 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newSyncCommand(installerFn func() (*skills.SkillInstaller, error)) *cobra.Command {
+	var force, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Install the skills recorded in skills.lock",
+		Args:  cobra.NoArgs,
+		Example: `picoclaw skills sync
+picoclaw skills sync --force`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			installer, err := installerFn()
+			if err != nil {
+				return err
+			}
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return err
+			}
+			installer.SetSecurity(securityPolicy(cfg), reviewSkill(yes))
+			return skillsSyncCmd(installer, newRegistryManager(cfg), force)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Restore skills that were modified locally")
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Install skills flagged by the security scan without asking")
+
+	return cmd
+}
@@ -0,0 +1,27 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyncSubcommand(t *testing.T) {
+	cmd := newSyncCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "sync", cmd.Use)
+	assert.Equal(t, "Install the skills recorded in skills.lock", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.NotNil(t, cmd.Flags().Lookup("force"))
+	assert.NotNil(t, cmd.Flags().Lookup("yes"))
+	assert.Error(t, cmd.Args(cmd, []string{"weather"}))
+}
@@ -0,0 +1,43 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newUpdateCommand(installerFn func() (*skills.SkillInstaller, error)) *cobra.Command {
+	var force, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "update [name]",
+		Short: "Update installed skills from their source",
+		Args:  cobra.MaximumNArgs(1),
+		Example: `picoclaw skills update
+picoclaw skills update weather
+picoclaw skills update --force weather`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			installer, err := installerFn()
+			if err != nil {
+				return err
+			}
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return err
+			}
+			installer.SetSecurity(securityPolicy(cfg), reviewSkill(yes))
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return skillsUpdateCmd(installer, newRegistryManager(cfg), name, force)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite skills that were modified locally")
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Update skills flagged by the security scan without asking")
+
+	return cmd
+}
@@ -0,0 +1,26 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func newVetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vet <dir>",
+		Short: "Check a skill directory against the security policy",
+		Args:  cobra.ExactArgs(1),
+		Example: `picoclaw skills vet ./weather
+picoclaw skills vet ~/.picoclaw/workspace/skills/github`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return err
+			}
+			return skillsVetCmd(cfg, args[0])
+		},
+	}
+
+	return cmd
+}
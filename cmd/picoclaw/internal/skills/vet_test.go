@@ -0,0 +1,26 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVetSubcommand(t *testing.T) {
+	cmd := newVetCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "vet <dir>", cmd.Use)
+	assert.Equal(t, "Check a skill directory against the security policy", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.Error(t, cmd.Args(cmd, []string{}))
+	assert.NoError(t, cmd.Args(cmd, []string{"./weather"}))
+}
@@ -1,12 +1,19 @@
 package status
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/swarm"
 )
 
 func statusCmd() {
@@ -56,4 +63,38 @@ func statusCmd() {
 			}
 		}
 	}
+	if cfg.Swarm.Enabled {
+		fmt.Println()
+		printSwarmStatus(os.Stdout, filepath.Join(workspace, "state", swarm.StateFile), time.Now())
+	}
+}
+
+// printSwarmStatus shows the peer table recorded by the running gateway.
+func printSwarmStatus(w io.Writer, statePath string, now time.Time) {
+	st, err := swarm.LoadState(statePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(w, "Swarm: enabled, no peer table yet (is the gateway running?)")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Swarm: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "Swarm: node %s, updated %s ago\n", st.Node, now.Sub(st.UpdatedAt).Round(time.Second))
+	if len(st.Peers) == 0 {
+		fmt.Fprintln(w, "  no peers discovered")
+		return
+	}
+	for _, p := range st.Peers {
+		status := "offline"
+		if p.Online(now, st.PeerTTL) {
+			status = "online"
+		}
+		fmt.Fprintf(w, "  %s: %s (last seen %s ago) %s\n",
+			p.Name, status, now.Sub(p.LastSeen).Round(time.Second), p.URL)
+		if len(p.Agents) > 0 {
+			fmt.Fprintf(w, "    agents: %s\n", strings.Join(p.Agents, ", "))
+		}
+	}
 }
@@ -0,0 +1,44 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/swarm"
+)
+
+func TestPrintSwarmStatus(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), swarm.StateFile)
+
+	var out bytes.Buffer
+	printSwarmStatus(&out, path, now)
+	assert.Contains(t, out.String(), "is the gateway running?")
+
+	data, err := json.Marshal(swarm.State{
+		Node:      "server",
+		UpdatedAt: now.Add(-5 * time.Second),
+		PeerTTL:   30 * time.Second,
+		Peers: []swarm.Peer{
+			{Name: "camera", URL: "ws://10.0.0.7:18790/swarm/ws", Agents: []string{"main", "vision"}, LastSeen: now.Add(-5 * time.Second)},
+			{Name: "printer", URL: "ws://10.0.0.9:18790/swarm/ws", LastSeen: now.Add(-2 * time.Minute)},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	out.Reset()
+	printSwarmStatus(&out, path, now)
+	got := out.String()
+	assert.Contains(t, got, "Swarm: node server, updated 5s ago")
+	assert.Contains(t, got, "camera: online (last seen 5s ago) ws://10.0.0.7:18790/swarm/ws")
+	assert.Contains(t, got, "agents: main, vision")
+	assert.Contains(t, got, "printer: offline (last seen 2m0s ago)")
+}
@@ -0,0 +1,115 @@
+package trigger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newAddCommand(configPath func() string) *cobra.Command {
+	var (
+		tc        config.TriggerConfig
+		disabled  bool
+		paths     []string
+		recursive bool
+		fileEvs   []string
+		pattern   string
+		secret    string
+		devKind   string
+		devAction []string
+		devMatch  string
+		broker    string
+		topic     string
+		qos       uint8
+		username  string
+		password  string
+		url       string
+		interval  int
+		headers   []string
+		always    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add an event trigger",
+		Args:  cobra.NoArgs,
+		Example: `picoclaw trigger add -n inbox -s file --path ~/inbox --pattern '*.pdf' -p 'Summarize {{.path}}'
+picoclaw trigger add -n deploy -s webhook -p 'CI reported: {{.body}}'
+picoclaw trigger add -n door -s mqtt --broker tcp://nas:1883 --topic home/door -p 'Door sensor: {{.payload}}'
+picoclaw trigger add -n status -s http_poll --url https://example.com/status --interval 300 -p 'Status page changed: {{.body}}'`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			tc.Enabled = !disabled
+			switch tc.Source {
+			case config.TriggerSourceFile:
+				tc.File = &config.FileTriggerConfig{
+					Paths:     paths,
+					Recursive: recursive,
+					Events:    fileEvs,
+					Pattern:   pattern,
+				}
+			case config.TriggerSourceWebhook:
+				tc.Webhook = &config.WebhookTriggerConfig{Secret: *config.NewSecureString(secret)}
+			case config.TriggerSourceDevice:
+				tc.Device = &config.DeviceTriggerConfig{Kind: devKind, Actions: devAction, Match: devMatch}
+			case config.TriggerSourceMQTT:
+				tc.MQTT = &config.MQTTTriggerConfig{
+					Broker:   broker,
+					Topic:    topic,
+					QoS:      qos,
+					Username: username,
+					Password: *config.NewSecureString(password),
+				}
+			case config.TriggerSourceHTTPPoll:
+				tc.HTTPPoll = &config.HTTPPollTriggerConfig{URL: url, IntervalSeconds: interval, Always: always}
+				for _, h := range headers {
+					k, v, ok := strings.Cut(h, "=")
+					if !ok {
+						return fmt.Errorf("invalid --header %q, want Name=Value", h)
+					}
+					if tc.HTTPPoll.Headers == nil {
+						tc.HTTPPoll.Headers = make(config.SecureHeaders)
+					}
+					tc.HTTPPoll.Headers[strings.TrimSpace(k)] = config.NewSecureString(strings.TrimSpace(v))
+				}
+			}
+			return triggerAddCmd(configPath(), tc)
+		},
+	}
+
+	cmd.Flags().StringVarP(&tc.Name, "name", "n", "", "Trigger name")
+	cmd.Flags().StringVarP(&tc.Source, "source", "s", "", "Event source: file, webhook, device, mqtt or http_poll")
+	cmd.Flags().StringVarP(&tc.Prompt, "prompt", "p", "", "Prompt template with event fields, e.g. '{{.path}} was {{.event}}'")
+	cmd.Flags().StringVar(&tc.AgentID, "agent", "", "Agent that handles the event (default agent if empty)")
+	cmd.Flags().StringVar(&tc.Channel, "channel", "", "Channel for the reply (default: last active channel)")
+	cmd.Flags().StringVar(&tc.ChatID, "chat-id", "", "Chat ID for the reply")
+	cmd.Flags().IntVar(&tc.DebounceSeconds, "debounce", 0, "Coalesce events arriving within N seconds (file default 2)")
+	cmd.Flags().IntVar(&tc.MaxPerHour, "max-per-hour", 0, "Maximum turns started per hour (default 30)")
+	cmd.Flags().BoolVar(&disabled, "disabled", false, "Add the trigger disabled")
+
+	cmd.Flags().StringArrayVar(&paths, "path", nil, "file: path to watch (repeatable)")
+	cmd.Flags().BoolVar(&recursive, "recursive", false, "file: also watch subdirectories")
+	cmd.Flags().StringSliceVar(&fileEvs, "events", nil, "file: create, write, remove, rename, chmod (default all)")
+	cmd.Flags().StringVar(&pattern, "pattern", "", "file: glob matched against file names")
+	cmd.Flags().StringVar(&secret, "secret", "", "webhook: HMAC secret (generated if empty)")
+	cmd.Flags().StringVar(&devKind, "device-kind", "", "device: device kind, e.g. usb")
+	cmd.Flags().StringSliceVar(&devAction, "device-actions", nil, "device: add, remove, change (default all)")
+	cmd.Flags().StringVar(&devMatch, "match", "", "device: substring of the vendor or product name")
+	cmd.Flags().StringVar(&broker, "broker", "", "mqtt: broker URL, e.g. tcp://localhost:1883")
+	cmd.Flags().StringVar(&topic, "topic", "", "mqtt: topic filter to subscribe to")
+	cmd.Flags().Uint8Var(&qos, "qos", 0, "mqtt: subscription QoS")
+	cmd.Flags().StringVar(&username, "username", "", "mqtt: username")
+	cmd.Flags().StringVar(&password, "password", "", "mqtt: password")
+	cmd.Flags().StringVar(&url, "url", "", "http_poll: URL to fetch")
+	cmd.Flags().IntVar(&interval, "interval", 0, "http_poll: seconds between polls (default 300)")
+	cmd.Flags().StringArrayVar(&headers, "header", nil, "http_poll: request header Name=Value (repeatable)")
+	cmd.Flags().BoolVar(&always, "always", false, "http_poll: fire on every poll, not only on changes")
+
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("source")
+	_ = cmd.MarkFlagRequired("prompt")
+
+	return cmd
+}
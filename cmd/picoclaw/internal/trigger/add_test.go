@@ -0,0 +1,76 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewAddSubcommand(t *testing.T) {
+	cmd := newAddCommand(func() string { return "" })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "add", cmd.Use)
+	assert.Equal(t, "Add an event trigger", cmd.Short)
+	assert.True(t, cmd.HasExample())
+
+	for _, name := range []string{"name", "source", "prompt"} {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, name)
+		_, required := flag.Annotations[cobra.BashCompOneRequiredFlag]
+		assert.True(t, required, name)
+	}
+}
+
+func TestAddCommandFileTrigger(t *testing.T) {
+	path := newTestConfig(t)
+	cmd := newAddCommand(func() string { return path })
+	cmd.SetArgs([]string{
+		"--name", "inbox",
+		"--source", "file",
+		"--prompt", "New file {{.path}}",
+		"--path", "/srv/inbox",
+		"--events", "create,write",
+		"--pattern", "*.pdf",
+	})
+	require.NoError(t, cmd.Execute())
+
+	rules := loadRules(t, path)
+	require.Len(t, rules, 1)
+	assert.True(t, rules[0].Enabled)
+	require.NotNil(t, rules[0].File)
+	assert.Equal(t, []string{"/srv/inbox"}, rules[0].File.Paths)
+	assert.Equal(t, []string{"create", "write"}, rules[0].File.Events)
+	assert.Nil(t, rules[0].Webhook)
+}
+
+func TestAddCommandWebhookGeneratesSecret(t *testing.T) {
+	path := newTestConfig(t)
+	cmd := newAddCommand(func() string { return path })
+	cmd.SetArgs([]string{"-n", "deploy", "-s", "webhook", "-p", "{{.body}}"})
+	require.NoError(t, cmd.Execute())
+
+	rules := loadRules(t, path)
+	require.Len(t, rules, 1)
+	require.NotNil(t, rules[0].Webhook)
+	assert.Len(t, rules[0].Webhook.Secret.String(), 48)
+
+	// Names are unique.
+	cmd = newAddCommand(func() string { return path })
+	cmd.SetArgs([]string{"-n", "deploy", "-s", "webhook", "-p", "{{.body}}"})
+	require.Error(t, cmd.Execute())
+}
+
+func TestAddCommandRejectsInvalidTrigger(t *testing.T) {
+	path := newTestConfig(t)
+	cmd := newAddCommand(func() string { return path })
+	cmd.SetArgs([]string{"-n", "door", "-s", config.TriggerSourceMQTT, "-p", "{{.payload}}"})
+	require.Error(t, cmd.Execute())
+
+	assert.Empty(t, loadRules(t, path))
+}
@@ -0,0 +1,28 @@
+package trigger
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func NewTriggerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trigger",
+		Short: "Manage event triggers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newListCommand(internal.GetConfigPath),
+		newAddCommand(internal.GetConfigPath),
+		newRemoveCommand(internal.GetConfigPath),
+		newEnableCommand(internal.GetConfigPath),
+		newDisableCommand(internal.GetConfigPath),
+	)
+
+	return cmd
+}
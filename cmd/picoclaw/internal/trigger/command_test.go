@@ -0,0 +1,60 @@
+package trigger
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// newTestConfig writes a default config to a temp dir and returns its path.
+func newTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, config.SaveConfig(path, config.DefaultConfig()))
+	return path
+}
+
+func loadRules(t *testing.T, path string) []config.TriggerConfig {
+	t.Helper()
+	cfg, err := config.LoadConfig(path)
+	require.NoError(t, err)
+	return cfg.Triggers.Rules
+}
+
+func TestNewTriggerCommand(t *testing.T) {
+	cmd := NewTriggerCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "trigger", cmd.Use)
+	assert.Equal(t, "Manage event triggers", cmd.Short)
+
+	assert.False(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.RunE)
+	assert.True(t, cmd.HasSubCommands())
+
+	allowedCommands := []string{
+		"list",
+		"add",
+		"remove",
+		"enable",
+		"disable",
+	}
+
+	subcommands := cmd.Commands()
+	assert.Len(t, subcommands, len(allowedCommands))
+
+	for _, subcmd := range subcommands {
+		found := slices.Contains(allowedCommands, subcmd.Name())
+		assert.True(t, found, "unexpected subcommand %q", subcmd.Name())
+
+		assert.False(t, subcmd.Hidden)
+		assert.Nil(t, subcmd.Run)
+		assert.NotNil(t, subcmd.RunE)
+	}
+}
@@ -0,0 +1,15 @@
+package trigger
+
+import "github.com/spf13/cobra"
+
+func newDisableCommand(configPath func() string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "disable",
+		Short:   "Disable a trigger",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw trigger disable inbox`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return triggerSetEnabled(configPath(), args[0], false)
+		},
+	}
+}
@@ -0,0 +1,15 @@
+package trigger
+
+import "github.com/spf13/cobra"
+
+func newEnableCommand(configPath func() string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "enable",
+		Short:   "Enable a trigger",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw trigger enable inbox`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return triggerSetEnabled(configPath(), args[0], true)
+		},
+	}
+}
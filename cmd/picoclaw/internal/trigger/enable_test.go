@@ -0,0 +1,40 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewEnableSubcommand(t *testing.T) {
+	cmd := newEnableCommand(func() string { return "" })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "Enable a trigger", cmd.Short)
+	assert.True(t, cmd.HasExample())
+}
+
+func TestEnableAndDisableCommands(t *testing.T) {
+	path := newTestConfig(t)
+	require.NoError(t, triggerAddCmd(path, config.TriggerConfig{
+		Name: "usb", Source: config.TriggerSourceDevice, Prompt: "plugged",
+	}))
+
+	cmd := newEnableCommand(func() string { return path })
+	cmd.SetArgs([]string{"usb"})
+	require.NoError(t, cmd.Execute())
+	assert.True(t, loadRules(t, path)[0].Enabled)
+
+	cmd = newDisableCommand(func() string { return path })
+	cmd.SetArgs([]string{"usb"})
+	require.NoError(t, cmd.Execute())
+	assert.False(t, loadRules(t, path)[0].Enabled)
+
+	cmd = newDisableCommand(func() string { return path })
+	cmd.SetArgs([]string{"missing"})
+	require.Error(t, cmd.Execute())
+}
@@ -0,0 +1,157 @@
+package trigger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/triggers"
+)
+
+func findTrigger(cfg *config.Config, name string) int {
+	return slices.IndexFunc(cfg.Triggers.Rules, func(r config.TriggerConfig) bool { return r.Name == name })
+}
+
+func triggerListCmd(configPath string) error {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	if len(cfg.Triggers.Rules) == 0 {
+		fmt.Println("No triggers configured.")
+		return nil
+	}
+
+	state := "enabled"
+	if !cfg.Triggers.Enabled {
+		state = "disabled (set triggers.enabled to true)"
+	}
+	fmt.Printf("\nTriggers (%s):\n", state)
+	fmt.Println("----------------")
+	for _, r := range cfg.Triggers.Rules {
+		status := "enabled"
+		if !r.Enabled {
+			status = "disabled"
+		}
+		fmt.Printf("  %s (%s, %s)\n", r.Name, r.Source, status)
+		if detail := describeSource(r); detail != "" {
+			fmt.Printf("    Watches: %s\n", detail)
+		}
+		if r.AgentID != "" {
+			fmt.Printf("    Agent: %s\n", r.AgentID)
+		}
+		if r.Channel != "" {
+			fmt.Printf("    Deliver to: %s:%s\n", r.Channel, r.ChatID)
+		}
+		fmt.Printf("    Prompt: %s\n", r.Prompt)
+	}
+	return nil
+}
+
+func describeSource(r config.TriggerConfig) string {
+	switch {
+	case r.File != nil:
+		d := strings.Join(r.File.Paths, ", ")
+		if r.File.Pattern != "" {
+			d += " (" + r.File.Pattern + ")"
+		}
+		return d
+	case r.Source == config.TriggerSourceWebhook:
+		return "POST " + triggers.WebhookPathPrefix + r.Name
+	case r.Device != nil && r.Device.Kind != "":
+		return r.Device.Kind + " devices"
+	case r.MQTT != nil:
+		return r.MQTT.Broker + " " + r.MQTT.Topic
+	case r.HTTPPoll != nil:
+		return r.HTTPPoll.URL
+	}
+	return ""
+}
+
+func triggerAddCmd(configPath string, tc config.TriggerConfig) error {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if findTrigger(cfg, tc.Name) >= 0 {
+		return fmt.Errorf("trigger %s already exists", tc.Name)
+	}
+
+	generatedSecret := false
+	if tc.Source == config.TriggerSourceWebhook && tc.Webhook.Secret.String() == "" {
+		tc.Webhook.Secret.Set(randomSecret())
+		generatedSecret = true
+	}
+	if err := triggers.Validate(tc); err != nil {
+		return err
+	}
+
+	cfg.Triggers.Rules = append(cfg.Triggers.Rules, tc)
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Added trigger '%s' (%s)\n", tc.Name, tc.Source)
+	if tc.Source == config.TriggerSourceWebhook {
+		fmt.Printf("  Endpoint: POST %s%s on the gateway\n", triggers.WebhookPathPrefix, tc.Name)
+		if generatedSecret {
+			fmt.Printf("  Secret: %s\n", tc.Webhook.Secret.String())
+		}
+		fmt.Printf("  Send the Unix time as %s and the HMAC-SHA256 of \"<time>.<body>\" as %s: sha256=<hex>\n",
+			triggers.TimestampHeader, triggers.SignatureHeader)
+	}
+	if !cfg.Triggers.Enabled {
+		fmt.Println("  Note: triggers are disabled; set triggers.enabled to true to activate them.")
+	}
+	return nil
+}
+
+func triggerRemoveCmd(configPath, name string) error {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	idx := findTrigger(cfg, name)
+	if idx < 0 {
+		return fmt.Errorf("trigger %s not found", name)
+	}
+
+	cfg.Triggers.Rules = slices.Delete(cfg.Triggers.Rules, idx, idx+1)
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("✓ Removed trigger %s\n", name)
+	return nil
+}
+
+func triggerSetEnabled(configPath, name string, enabled bool) error {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	idx := findTrigger(cfg, name)
+	if idx < 0 {
+		return fmt.Errorf("trigger %s not found", name)
+	}
+
+	cfg.Triggers.Rules[idx].Enabled = enabled
+	if err := config.SaveConfig(configPath, cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	status := "enabled"
+	if !enabled {
+		status = "disabled"
+	}
+	fmt.Printf("✓ Trigger '%s' %s\n", name, status)
+	return nil
+}
+
+func randomSecret() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
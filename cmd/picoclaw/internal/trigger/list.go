@@ -0,0 +1,14 @@
+package trigger
+
+import "github.com/spf13/cobra"
+
+func newListCommand(configPath func() string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all triggers",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return triggerListCmd(configPath())
+		},
+	}
+}
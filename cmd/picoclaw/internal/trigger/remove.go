@@ -0,0 +1,15 @@
+package trigger
+
+import "github.com/spf13/cobra"
+
+func newRemoveCommand(configPath func() string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove",
+		Short:   "Remove a trigger by name",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw trigger remove inbox`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return triggerRemoveCmd(configPath(), args[0])
+		},
+	}
+}
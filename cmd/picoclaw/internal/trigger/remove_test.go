@@ -0,0 +1,35 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewRemoveSubcommand(t *testing.T) {
+	cmd := newRemoveCommand(func() string { return "" })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "Remove a trigger by name", cmd.Short)
+	assert.True(t, cmd.HasExample())
+}
+
+func TestRemoveCommand(t *testing.T) {
+	path := newTestConfig(t)
+	require.NoError(t, triggerAddCmd(path, config.TriggerConfig{
+		Name: "usb", Enabled: true, Source: config.TriggerSourceDevice, Prompt: "plugged",
+	}))
+
+	cmd := newRemoveCommand(func() string { return path })
+	cmd.SetArgs([]string{"usb"})
+	require.NoError(t, cmd.Execute())
+	assert.Empty(t, loadRules(t, path))
+
+	cmd = newRemoveCommand(func() string { return path })
+	cmd.SetArgs([]string{"usb"})
+	require.Error(t, cmd.Execute())
+}
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/browser"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// replaceBrowser closes the browser of the previous config and returns a
+// new one for cfg, or nil when the browser tool is disabled. The browser
+// only starts when a page is first used.
+func (al *AgentLoop) replaceBrowser(cfg *config.Config) *browser.Browser {
+	al.closeBrowser()
+	if !cfg.Tools.IsToolEnabled("browser") {
+		return nil
+	}
+
+	bc := cfg.Tools.Browser
+	b, err := tools.NewBrowser(tools.BrowserOptions{
+		CDPURL:               bc.CDPURL,
+		ExecutablePath:       bc.ExecutablePath,
+		Proxy:                cfg.Tools.Web.Proxy,
+		IdleTimeout:          time.Duration(bc.IdleTimeoutMinutes) * time.Minute,
+		AllowedDomains:       bc.AllowedDomains,
+		DeniedDomains:        bc.DeniedDomains,
+		PrivateHostWhitelist: cfg.Tools.Web.PrivateHostWhitelist,
+	})
+	if err != nil {
+		logger.ErrorCF("agent", "Failed to create browser tool", map[string]any{"error": err.Error()})
+		return nil
+	}
+
+	al.browserMu.Lock()
+	al.browser = b
+	al.browserMu.Unlock()
+	return b
+}
+
+func (al *AgentLoop) closeBrowser() {
+	al.browserMu.Lock()
+	b := al.browser
+	al.browser = nil
+	al.browserMu.Unlock()
+
+	if b == nil {
+		return
+	}
+	if err := b.Close(); err != nil {
+		logger.WarnCF("agent", "Failed to close browser", map[string]any{"error": err.Error()})
+	}
+}
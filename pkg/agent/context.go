@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/facts"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/skills"
@@ -27,6 +29,18 @@ type ContextBuilder struct {
 	toolDiscoveryRegex bool
 	splitOnMarker      bool
 
+	// Skill selection: in auto mode the system prompt lists only the skills
+	// relevant to the current message instead of every installed skill.
+	skillsAuto     bool
+	skillsTopK     int
+	skillsMinScore float64
+	skillsBudget   int // tokens skill text may use per request; 0 means no limit
+
+	// Long-term memory: the facts most relevant to the current message are
+	// recalled into each request.
+	facts     *facts.Store
+	factsTopK int
+
 	// Cache for system prompt to avoid rebuilding on every call.
 	// This fixes issue #607: repeated reprocessing of the entire context.
 	// The cache auto-invalidates when workspace source files change (mtime check).
@@ -57,6 +71,30 @@ func (cb *ContextBuilder) WithSplitOnMarker(enabled bool) *ContextBuilder {
 	return cb
 }
 
+// WithSkillSelection switches the skills listing to per-message selection
+// of the topK skills that score at least minScore.
+func (cb *ContextBuilder) WithSkillSelection(auto bool, topK int, minScore float64) *ContextBuilder {
+	cb.skillsAuto = auto
+	cb.skillsTopK = topK
+	cb.skillsMinScore = minScore
+	return cb
+}
+
+// WithSkillBudget limits the tokens that active and selected skills may add
+// to a request.
+func (cb *ContextBuilder) WithSkillBudget(tokens int) *ContextBuilder {
+	cb.skillsBudget = tokens
+	return cb
+}
+
+// WithFactRecall recalls the topK facts of store that are most relevant to
+// each message into its request.
+func (cb *ContextBuilder) WithFactRecall(store *facts.Store, topK int) *ContextBuilder {
+	cb.facts = store
+	cb.factsTopK = topK
+	return cb
+}
+
 func getGlobalConfigDir() string {
 	return config.GetHome()
 }
@@ -139,14 +177,13 @@ func (cb *ContextBuilder) BuildSystemPrompt() string {
 		parts = append(parts, bootstrapContent)
 	}
 
-	// Skills - show summary, AI can read full content with read_file tool
-	skillsSummary := cb.skillsLoader.BuildSkillsSummary()
-	if skillsSummary != "" {
-		parts = append(parts, fmt.Sprintf(`# Skills
-
-The following skills extend your capabilities. To use a skill, read its SKILL.md file using the read_file tool.
-
-%s`, skillsSummary))
+	// Skills - show summary, AI can read full content with read_file tool.
+	// In auto mode the summary depends on the message and is added by
+	// BuildMessages instead.
+	if !cb.skillsAuto {
+		if skillsSummary := cb.skillsLoader.BuildSkillsSummary(); skillsSummary != "" {
+			parts = append(parts, formatSkillsSection(skillsSummary))
+		}
 	}
 
 	// Memory context
@@ -509,7 +546,10 @@ func (cb *ContextBuilder) buildDynamicContext(channel, chatID, senderID, senderD
 	return sb.String()
 }
 
+// BuildMessages assembles the messages of one LLM call. ctx bounds the
+// recall of facts, which may call the embedding model.
 func (cb *ContextBuilder) BuildMessages(
+	ctx context.Context,
 	history []providers.Message,
 	summary string,
 	currentMessage string,
@@ -549,10 +589,27 @@ func (cb *ContextBuilder) BuildMessages(
 		{Type: "text", Text: dynamicCtx},
 	}
 
-	if skillsText := cb.buildActiveSkillsContext(activeSkills); skillsText != "" {
+	skillsText, skillTokens := cb.buildActiveSkillsContext(activeSkills)
+	if skillsText != "" {
 		stringParts = append(stringParts, skillsText)
 		contentBlocks = append(contentBlocks, providers.ContentBlock{Type: "text", Text: skillsText})
 	}
+	if cb.skillsAuto {
+		query := skillSelectionQuery(currentMessage, history)
+		if selectedText := cb.buildSelectedSkillsContext(query, activeSkills, skillTokens); selectedText != "" {
+			stringParts = append(stringParts, selectedText)
+			contentBlocks = append(contentBlocks, providers.ContentBlock{Type: "text", Text: selectedText})
+		}
+	}
+
+	if cb.facts != nil {
+		query := skillSelectionQuery(currentMessage, history)
+		if recalled := cb.facts.Recall(ctx, senderID, query, cb.factsTopK); len(recalled) > 0 {
+			factsText := formatRecalledFacts(recalled)
+			stringParts = append(stringParts, factsText)
+			contentBlocks = append(contentBlocks, providers.ContentBlock{Type: "text", Text: factsText})
+		}
+	}
 
 	if summary != "" {
 		summaryText := fmt.Sprintf(
@@ -774,12 +831,17 @@ func (cb *ContextBuilder) AddAssistantMessage(
 	return messages
 }
 
-func (cb *ContextBuilder) buildActiveSkillsContext(skillNames []string) string {
+// buildActiveSkillsContext returns the full text of the active skills and
+// its estimated tokens. Active skills were asked for explicitly with /use or
+// /pin, so they are loaded even past the skill budget; the budget only limits
+// the skills selected automatically.
+func (cb *ContextBuilder) buildActiveSkillsContext(skillNames []string) (string, int) {
 	if cb.skillsLoader == nil || len(skillNames) == 0 {
-		return ""
+		return "", 0
 	}
 
-	var ordered []string
+	var parts []string
+	tokens := 0
 	seen := make(map[string]struct{}, len(skillNames))
 	for _, name := range skillNames {
 		canonical, ok := cb.ResolveSkillName(name)
@@ -790,22 +852,89 @@ func (cb *ContextBuilder) buildActiveSkillsContext(skillNames []string) string {
 			continue
 		}
 		seen[canonical] = struct{}{}
-		ordered = append(ordered, canonical)
+
+		content := cb.skillsLoader.LoadSkillsForContext([]string{canonical})
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		tokens += estimateTextTokens(content)
+		parts = append(parts, content)
 	}
-	if len(ordered) == 0 {
+	if len(parts) == 0 {
+		return "", 0
+	}
+
+	return fmt.Sprintf(`# Active Skills
+
+The following skills are active for this request. Follow them when relevant.
+
+%s`, strings.Join(parts, "\n\n---\n\n")), tokens
+}
+
+// buildSelectedSkillsContext lists the skills most relevant to query that
+// are not already active, within what is left of the skill budget.
+func (cb *ContextBuilder) buildSelectedSkillsContext(query string, activeSkills []string, usedTokens int) string {
+	if cb.skillsLoader == nil {
 		return ""
 	}
 
-	content := cb.skillsLoader.LoadSkillsForContext(ordered)
-	if strings.TrimSpace(content) == "" {
+	active := make(map[string]struct{}, len(activeSkills))
+	for _, name := range activeSkills {
+		active[strings.ToLower(strings.TrimSpace(name))] = struct{}{}
+	}
+
+	var selected []skills.SkillInfo
+	for _, info := range cb.skillsLoader.SelectSkills(query, cb.skillsTopK, cb.skillsMinScore) {
+		if _, ok := active[strings.ToLower(info.Name)]; ok {
+			continue
+		}
+		selected = append(selected, info)
+		if cb.skillsBudget > 0 &&
+			usedTokens+estimateTextTokens(formatSkillsSection(skills.BuildSkillsSummaryFor(selected))) > cb.skillsBudget {
+			selected = selected[:len(selected)-1]
+			break
+		}
+	}
+	if len(selected) == 0 {
 		return ""
 	}
+	return formatSkillsSection(skills.BuildSkillsSummaryFor(selected))
+}
 
-	return fmt.Sprintf(`# Active Skills
+// skillSelectionQuery returns the text skills are selected for: the current
+// message, or the last user message when the turn is rebuilt after
+// compression.
+func skillSelectionQuery(currentMessage string, history []providers.Message) string {
+	if strings.TrimSpace(currentMessage) != "" {
+		return currentMessage
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			return history[i].Content
+		}
+	}
+	return ""
+}
 
-The following skills are active for this request. Follow them when relevant.
+// formatRecalledFacts lists recalled facts with their IDs, which the forget
+// tool takes.
+func formatRecalledFacts(recalled []facts.Fact) string {
+	var sb strings.Builder
+	sb.WriteString("# Relevant Memories\n\n")
+	sb.WriteString("Facts remembered from earlier conversations that may relate to this message. ")
+	sb.WriteString("Newer information from the user takes precedence.\n")
+	for _, f := range recalled {
+		fmt.Fprintf(&sb, "\n- [%s] %s (%s)", f.ID, f.Text, f.UpdatedAt.Format("2006-01-02"))
+	}
+	return sb.String()
+}
+
+func formatSkillsSection(summary string) string {
+	return fmt.Sprintf(`# Skills
+
+The following skills extend your capabilities. To use a skill, read its SKILL.md file using the read_file tool.
 
-%s`, content)
+%s`, summary)
 }
 
 func (cb *ContextBuilder) ListSkillNames() []string {
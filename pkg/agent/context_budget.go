@@ -170,6 +170,24 @@ func estimateToolDefsTokens(defs []providers.ToolDefinition) int {
 	return totalChars * 2 / 5
 }
 
+// estimateTextTokens estimates the token count of plain text, such as the
+// skills added to the system prompt, with the heuristic of
+// estimateMessageTokens.
+func estimateTextTokens(text string) int {
+	return utf8.RuneCountInString(text) * 2 / 5
+}
+
+// skillBudgetTokens returns how many tokens skill text may add to a request:
+// percent of the context window left after the output reserve. Zero means
+// no limit.
+func skillBudgetTokens(contextWindow, maxTokens, percent int) int {
+	available := contextWindow - maxTokens
+	if contextWindow <= 0 || available <= 0 || percent <= 0 {
+		return 0
+	}
+	return available * percent / 100
+}
+
 // isOverContextBudget checks whether the assembled messages plus tool definitions
 // and output reserve would exceed the model's context window. This enables
 // proactive compression before calling the LLM, rather than reacting to 400 errors.
@@ -844,3 +844,20 @@ func TestIsOverContextBudget_RealisticSession(t *testing.T) {
 		t.Error("realistic session should exceed 500 context window")
 	}
 }
+
+func TestSkillBudgetTokens(t *testing.T) {
+	tests := []struct {
+		contextWindow, maxTokens, percent, want int
+	}{
+		{16384, 4096, 25, 3072},
+		{16384, 4096, 0, 0},
+		{0, 4096, 25, 0},
+		{4096, 8192, 25, 0},
+	}
+	for _, tt := range tests {
+		if got := skillBudgetTokens(tt.contextWindow, tt.maxTokens, tt.percent); got != tt.want {
+			t.Errorf("skillBudgetTokens(%d, %d, %d) = %d, want %d",
+				tt.contextWindow, tt.maxTokens, tt.percent, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
 )
 
 // legacyContextManager wraps the existing summarization/compression logic
@@ -23,7 +24,7 @@ func (m *legacyContextManager) Assemble(_ context.Context, req *AssembleRequest)
 	// Legacy: read history from session, return as-is.
 	// Budget enforcement happens in BuildMessages caller via
 	// isOverContextBudget + forceCompression.
-	agent := m.al.registry.GetDefaultAgent()
+	agent := m.sessionAgent(req.SessionKey)
 	if agent == nil {
 		return &AssembleResponse{}, nil
 	}
@@ -35,6 +36,17 @@ func (m *legacyContextManager) Assemble(_ context.Context, req *AssembleRequest)
 	}, nil
 }
 
+// sessionAgent returns the agent whose store holds sessionKey. Agent-scoped
+// keys name their agent; other keys belong to the default agent.
+func (m *legacyContextManager) sessionAgent(sessionKey string) *AgentInstance {
+	if parsed := routing.ParseAgentSessionKey(sessionKey); parsed != nil {
+		if agent, ok := m.al.registry.GetAgent(parsed.AgentID); ok {
+			return agent
+		}
+	}
+	return m.al.registry.GetDefaultAgent()
+}
+
 func (m *legacyContextManager) Compact(_ context.Context, req *CompactRequest) error {
 	switch req.Reason {
 	case ContextCompressReasonProactive, ContextCompressReasonRetry:
@@ -64,7 +76,7 @@ func (m *legacyContextManager) Ingest(_ context.Context, _ *IngestRequest) error
 // maybeSummarize triggers summarization if the session history exceeds thresholds.
 // It runs asynchronously in a goroutine.
 func (m *legacyContextManager) maybeSummarize(sessionKey string) {
-	agent := m.al.registry.GetDefaultAgent()
+	agent := m.sessionAgent(sessionKey)
 	if agent == nil {
 		return
 	}
@@ -102,7 +114,7 @@ type compressionResult struct {
 // It drops the oldest ~50% of Turns (a Turn is a complete user→LLM→response
 // cycle, as defined in #1316), so tool-call sequences are never split.
 func (m *legacyContextManager) forceCompression(sessionKey string) (compressionResult, bool) {
-	agent := m.al.registry.GetDefaultAgent()
+	agent := m.sessionAgent(sessionKey)
 	if agent == nil {
 		return compressionResult{}, false
 	}
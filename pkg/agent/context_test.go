@@ -1,6 +1,9 @@
 package agent
 
 import (
+	"context"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -306,3 +309,66 @@ func TestSanitizeHistoryForProvider_PartialToolResultsInMiddle(t *testing.T) {
 	}
 	assertRoles(t, result, "user", "assistant", "tool", "assistant", "user", "user", "assistant", "tool", "assistant")
 }
+
+func TestBuildMessages_AutoSkillSelection(t *testing.T) {
+	tmpDir := setupWorkspace(t, map[string]string{
+		"skills/weather/SKILL.md": "---\nname: weather\ndescription: Weather forecasts and rain alerts\n---\n\n# Weather\n",
+		"skills/git/SKILL.md":     "---\nname: git\ndescription: Git branches and rebases\n---\n\n# Git\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	ctx := context.Background()
+	cb := NewContextBuilder(tmpDir).WithSkillSelection(true, 5, 0.5)
+	if strings.Contains(cb.BuildSystemPrompt(), "<skills>") {
+		t.Fatal("static system prompt should not list skills in auto mode")
+	}
+
+	system := cb.BuildMessages(ctx, nil, "", "will it rain today?", nil, "cli", "direct", "", "")[0].Content
+	if !strings.Contains(system, "<name>weather</name>") || strings.Contains(system, "<name>git</name>") {
+		t.Fatalf("system prompt should list only the weather skill:\n%s", system)
+	}
+
+	// Rebuilding a turn without the current message selects for the last
+	// user message in history.
+	history := []providers.Message{msg("user", "use git to list branches"), msg("assistant", "ok")}
+	system = cb.BuildMessages(ctx, history, "", "", nil, "cli", "direct", "", "")[0].Content
+	if !strings.Contains(system, "<name>git</name>") || strings.Contains(system, "<name>weather</name>") {
+		t.Fatalf("system prompt should list only the git skill:\n%s", system)
+	}
+
+	// Active skills are loaded in full and not listed again.
+	system = cb.BuildMessages(ctx, nil, "", "will it rain today?", nil, "cli", "direct", "", "", "weather")[0].Content
+	if !strings.Contains(system, "### Skill: weather") || strings.Contains(system, "<name>weather</name>") {
+		t.Fatalf("active weather skill should be loaded, not listed:\n%s", system)
+	}
+
+	system = cb.BuildMessages(ctx, nil, "", "tell me a joke", nil, "cli", "direct", "", "")[0].Content
+	if strings.Contains(system, "<skills>") {
+		t.Fatalf("no skill should be listed for an unrelated message:\n%s", system)
+	}
+}
+
+func TestBuildMessages_SkillBudget(t *testing.T) {
+	tmpDir := setupWorkspace(t, map[string]string{
+		"skills/small/SKILL.md": "---\nname: small\ndescription: Small skill\n---\n\nShort.\n",
+		"skills/large/SKILL.md": "---\nname: large\ndescription: Large skill\n---\n\n" + strings.Repeat("word ", 400),
+	})
+	defer os.RemoveAll(tmpDir)
+
+	ctx := context.Background()
+	cb := NewContextBuilder(tmpDir).WithSkillBudget(100)
+	system := cb.BuildMessages(ctx, nil, "", "hi", nil, "cli", "direct", "", "", "small", "large")[0].Content
+	if !strings.Contains(system, "### Skill: small") || !strings.Contains(system, "### Skill: large") {
+		t.Fatalf("skills asked for explicitly should be loaded even over the budget:\n%s", system)
+	}
+
+	cb = NewContextBuilder(tmpDir).WithSkillBudget(300).WithSkillSelection(true, 5, 0)
+	system = cb.BuildMessages(ctx, nil, "", "small skill", nil, "cli", "direct", "", "")[0].Content
+	if !strings.Contains(system, "<name>small</name>") {
+		t.Fatalf("small skill should be selected within the budget:\n%s", system)
+	}
+	system = cb.BuildMessages(ctx, nil, "", "small skill", nil, "cli", "direct", "", "", "large")[0].Content
+	if strings.Contains(system, "<name>small</name>") {
+		t.Fatalf("no skill should be selected once active skills use up the budget:\n%s", system)
+	}
+}
@@ -81,6 +81,15 @@ func (k EventKind) String() string {
 	return eventKindNames[k]
 }
 
+// EventKinds returns every event kind in declaration order.
+func EventKinds() []EventKind {
+	kinds := make([]EventKind, eventKindCount)
+	for i := range kinds {
+		kinds[i] = EventKind(i)
+	}
+	return kinds
+}
+
 // Event is the structured envelope broadcast by the agent EventBus.
 type Event struct {
 	Kind    EventKind
@@ -0,0 +1,246 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/facts"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// factExtractionTimeout bounds the model call that extracts facts after a
+// turn.
+const factExtractionTimeout = 2 * time.Minute
+
+// knownFactsForExtraction is how many stored facts are shown to the
+// extraction model so it can reuse their keys.
+const knownFactsForExtraction = 10
+
+// resolveExtractModel creates the provider of memory.extract_model. It
+// returns nil when none is configured or it cannot be used, in which case
+// facts are extracted like Complete does: with the light model when routing
+// is configured, else with the agent model.
+func resolveExtractModel(
+	cfg *config.Config,
+	defaults *config.AgentDefaults,
+	workspace, agentID string,
+) (providers.LLMProvider, string) {
+	name := defaults.Memory.ExtractModel
+	if name == "" {
+		return nil, ""
+	}
+	modelCfg, err := resolvedModelConfig(cfg, name, workspace)
+	if err != nil {
+		logger.WarnCF("agent", "Memory extract model config invalid; using the agent model",
+			map[string]any{"extract_model": name, "agent_id": agentID, "error": err.Error()})
+		return nil, ""
+	}
+	provider, modelID, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		logger.WarnCF("agent", "Memory extract model provider init failed; using the agent model",
+			map[string]any{"extract_model": name, "agent_id": agentID, "error": err.Error()})
+		return nil, ""
+	}
+	return provider, modelID
+}
+
+// factEmbedder embeds facts with the model of memory.embedding_model.
+type factEmbedder struct {
+	provider providers.EmbeddingProvider
+	model    string
+}
+
+func (e factEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.provider.Embed(ctx, texts, e.model)
+}
+
+// resolveFactEmbedder creates the embedder of memory.embedding_model. It
+// returns nil, which keeps recall keyword-only, when none is configured or
+// its provider cannot embed.
+func resolveFactEmbedder(
+	cfg *config.Config,
+	defaults *config.AgentDefaults,
+	workspace, agentID string,
+) facts.Embedder {
+	name := defaults.Memory.EmbeddingModel
+	if name == "" {
+		return nil
+	}
+	modelCfg, err := resolvedModelConfig(cfg, name, workspace)
+	if err != nil {
+		logger.WarnCF("agent", "Memory embedding model config invalid; using keyword recall",
+			map[string]any{"embedding_model": name, "agent_id": agentID, "error": err.Error()})
+		return nil
+	}
+	provider, modelID, err := providers.CreateProviderFromConfig(modelCfg)
+	if err != nil {
+		logger.WarnCF("agent", "Memory embedding model provider init failed; using keyword recall",
+			map[string]any{"embedding_model": name, "agent_id": agentID, "error": err.Error()})
+		return nil
+	}
+	embedder, ok := provider.(providers.EmbeddingProvider)
+	if !ok {
+		logger.WarnCF("agent", "Memory embedding model provider cannot embed; using keyword recall",
+			map[string]any{"embedding_model": name, "agent_id": agentID})
+		return nil
+	}
+	return factEmbedder{provider: embedder, model: modelID}
+}
+
+// scheduleFactExtraction extracts facts from a finished turn in the
+// background, so the reply is not delayed. The facts are scoped to the
+// sender and the identities linked to them; turns without a sender are
+// skipped, as their facts would be recalled for everyone.
+func (al *AgentLoop) scheduleFactExtraction(agent *AgentInstance, sessionKey, senderID, userMessage, reply string) {
+	if senderID == "" {
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.WarnCF("agent", "Fact extraction panic recovered", map[string]any{
+					"session_key": sessionKey,
+					"panic":       r,
+				})
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), factExtractionTimeout)
+		defer cancel()
+		al.extractFacts(ctx, agent, sessionKey, senderID, userMessage, reply)
+	}()
+}
+
+// extractFacts stores the facts the extraction model finds in one exchange
+// that reach the configured confidence.
+func (al *AgentLoop) extractFacts(
+	ctx context.Context,
+	agent *AgentInstance,
+	sessionKey, senderID, userMessage, reply string,
+) {
+	provider, model := agent.FactsProvider, agent.FactsModel
+	if provider == nil {
+		provider = agent.Provider
+		model = resolvedCandidateModel(agent.Candidates, agent.Model)
+		if agent.Router != nil && agent.LightProvider != nil && len(agent.LightCandidates) > 0 {
+			provider = agent.LightProvider
+			model = resolvedCandidateModel(agent.LightCandidates, agent.Router.LightModel())
+		}
+	}
+
+	known := agent.Facts.Recall(ctx, senderID, userMessage+"\n"+reply, knownFactsForExtraction)
+	al.activeRequests.Add(1)
+	candidates, err := facts.Extract(ctx, provider, model, known, userMessage, reply)
+	al.activeRequests.Done()
+	if err != nil {
+		logger.WarnCF("agent", "Fact extraction failed",
+			map[string]any{"session_key": sessionKey, "model": model, "error": err.Error()})
+		return
+	}
+
+	minConfidence := al.GetConfig().Agents.Defaults.GetMemoryMinConfidence()
+	var added, merged, replaced int
+	for _, c := range candidates {
+		if c.Confidence < minConfidence {
+			continue
+		}
+		_, outcome, err := agent.Facts.Add(facts.Fact{
+			Text:       c.Text,
+			Key:        c.Key,
+			Kind:       c.Kind,
+			Confidence: c.Confidence,
+			Source:     sessionKey,
+			Scope:      senderID,
+		})
+		if err != nil {
+			logger.WarnCF("agent", "Failed to store fact",
+				map[string]any{"session_key": sessionKey, "error": err.Error()})
+			return
+		}
+		switch outcome {
+		case facts.Added:
+			added++
+		case facts.Merged:
+			merged++
+		case facts.Replaced:
+			replaced++
+		}
+	}
+	if added+merged+replaced > 0 {
+		logger.InfoCF("agent", "Facts extracted", map[string]any{
+			"session_key": sessionKey,
+			"added":       added,
+			"merged":      merged,
+			"replaced":    replaced,
+		})
+	}
+}
+
+// factScopes returns the scopes whose facts senderID shares when it is
+// linked to other identities: the name of its link group, which facts are
+// stored under, then the linked identities, so facts stored before the link
+// are kept.
+func (al *AgentLoop) factScopes(senderID string) []string {
+	name, members, ok := al.identityLinks.Group(senderID)
+	if !ok {
+		return nil
+	}
+	members = slices.DeleteFunc(members, func(m string) bool { return strings.EqualFold(m, name) })
+	return append([]string{name}, members...)
+}
+
+// setMemoryCommands wires /memory to the facts of agent that are in the
+// scope of the sender.
+func (al *AgentLoop) setMemoryCommands(rt *commands.Runtime, agent *AgentInstance, opts *processOptions) {
+	if agent.Facts == nil {
+		return
+	}
+	var senderID string
+	if opts != nil {
+		senderID = opts.SenderID
+	}
+	rt.ListFacts = func(all bool) []commands.FactInfo {
+		list := agent.Facts.List(all)
+		scopes := agent.Facts.Scopes(senderID)
+		list = slices.DeleteFunc(list, func(f facts.Fact) bool { return !f.InScope(scopes...) })
+		return factInfos(list)
+	}
+	rt.SearchFacts = func(query string) []commands.FactInfo {
+		return factInfos(agent.Facts.Recall(context.Background(), senderID, query, 10))
+	}
+	rt.ForgetFact = func(id string) (commands.FactInfo, bool, error) {
+		if f, ok := agent.Facts.Get(id); ok && !f.InScope(agent.Facts.Scopes(senderID)...) {
+			return commands.FactInfo{}, false, nil
+		}
+		f, ok, err := agent.Facts.Forget(id)
+		if ok && err == nil {
+			logger.InfoCF("agent", "Fact forgotten", map[string]any{"agent_id": agent.ID, "fact_id": id})
+		}
+		return factInfo(f), ok, err
+	}
+}
+
+func factInfos(list []facts.Fact) []commands.FactInfo {
+	out := make([]commands.FactInfo, len(list))
+	for i, f := range list {
+		out[i] = factInfo(f)
+	}
+	return out
+}
+
+func factInfo(f facts.Fact) commands.FactInfo {
+	return commands.FactInfo{
+		ID:           f.ID,
+		Text:         f.Text,
+		Key:          f.Key,
+		Kind:         f.Kind,
+		Confidence:   f.Confidence,
+		Source:       f.Source,
+		UpdatedAt:    f.UpdatedAt,
+		SupersededBy: f.SupersededBy,
+	}
+}
@@ -0,0 +1,195 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// factProvider answers extraction requests with a fixed fact and records
+// the system prompt of other requests.
+type factProvider struct {
+	mu           sync.Mutex
+	lastSystem   string
+	extractCalls int
+}
+
+func (p *factProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if strings.Contains(messages[0].Content, "long-term memory of a personal assistant") {
+		p.extractCalls++
+		return &providers.LLMResponse{
+			Content: `[{"text":"The user is allergic to peanuts.","key":"user.allergies","confidence":0.9},` +
+				`{"text":"The user might like rain.","confidence":0.2}]`,
+		}, nil
+	}
+	p.lastSystem = messages[0].Content
+	return &providers.LLMResponse{Content: "noted"}, nil
+}
+
+func (p *factProvider) GetDefaultModel() string {
+	return "fact-model"
+}
+
+func TestFactMemory_ExtractsAndRecalls(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Memory:            config.MemoryConfig{Enabled: true},
+			},
+		},
+	}
+	provider := &factProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	agent := al.GetRegistry().GetDefaultAgent()
+	if agent.Facts == nil {
+		t.Fatal("memory enabled but agent has no fact store")
+	}
+	for _, name := range []string{"remember", "forget", "recall"} {
+		if _, ok := agent.Tools.Get(name); !ok {
+			t.Fatalf("tool %s not registered", name)
+		}
+	}
+
+	send := func(content string) {
+		t.Helper()
+		if _, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: "telegram", SenderID: "user1", ChatID: "chat1", Content: content,
+		}); err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+	}
+
+	send("I can't eat peanuts, I'm allergic")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(agent.Facts.List(false)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	stored := agent.Facts.List(false)
+	if len(stored) != 1 || stored[0].Key != "user.allergies" || stored[0].Source == "" || stored[0].Scope != "user1" {
+		t.Fatalf("stored facts = %+v", stored)
+	}
+
+	send("Suggest a snack with peanuts")
+	provider.mu.Lock()
+	system := provider.lastSystem
+	provider.mu.Unlock()
+	if !strings.Contains(system, "# Relevant Memories") ||
+		!strings.Contains(system, "["+stored[0].ID+"] The user is allergic to peanuts.") {
+		t.Fatalf("system prompt misses the recalled fact:\n%s", system)
+	}
+
+	// The second turn repeats the fact; wait for the merge so no write
+	// outlives the test.
+	for time.Now().Before(deadline) {
+		if facts := agent.Facts.List(false); len(facts) == 1 && facts[0].UpdatedAt.After(stored[0].UpdatedAt) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reply, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel: "telegram", SenderID: "user1", ChatID: "chat1", Content: "/memory list",
+	})
+	if err != nil || !strings.Contains(reply, "allergic to peanuts") {
+		t.Fatalf("/memory list = %q, %v", reply, err)
+	}
+
+	reply, err = al.processMessage(context.Background(), bus.InboundMessage{
+		Channel: "telegram", SenderID: "user2", ChatID: "chat1", Content: "/memory list",
+	})
+	if err != nil || strings.Contains(reply, "allergic to peanuts") {
+		t.Fatalf("/memory list of another sender = %q, %v", reply, err)
+	}
+}
+
+func TestFactMemory_DisabledByDefault(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace: t.TempDir(),
+				ModelName: "test-model",
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &factProvider{})
+	agent := al.GetRegistry().GetDefaultAgent()
+	if agent.Facts != nil {
+		t.Fatal("fact store created without memory.enabled")
+	}
+	if _, ok := agent.Tools.Get("remember"); ok {
+		t.Fatal("remember tool registered without memory.enabled")
+	}
+}
+
+func TestFactMemory_SharedByLinkedIdentities(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Memory:            config.MemoryConfig{Enabled: true},
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &factProvider{})
+	agent := al.GetRegistry().GetDefaultAgent()
+
+	code, err := al.identityLinks.IssueCode("telegram:1")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	if _, err := al.identityLinks.Redeem(code, "discord:2"); err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	if _, err := al.identityLinks.Confirm("telegram:1", "discord:2"); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+
+	send := func(channel, senderID, content string) string {
+		t.Helper()
+		reply, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: channel, SenderID: senderID, ChatID: "chat1", Content: content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return reply
+	}
+
+	send("discord", "discord:2", "I can't eat peanuts, I'm allergic")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(agent.Facts.List(false)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	stored := agent.Facts.List(false)
+	if len(stored) != 1 || stored[0].Scope != "telegram:1" {
+		t.Fatalf("stored facts = %+v, want one under the link group", stored)
+	}
+
+	if reply := send("telegram", "telegram:1", "/memory list"); !strings.Contains(reply, "allergic to peanuts") {
+		t.Fatalf("/memory list of the linked identity = %q", reply)
+	}
+	if reply := send("telegram", "telegram:3", "/memory list"); strings.Contains(reply, "allergic to peanuts") {
+		t.Fatalf("/memory list of another sender = %q", reply)
+	}
+}
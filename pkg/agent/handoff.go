@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// handoff rebinds a routed session to another agent. It is keyed by the
+// session key the router resolves for the chat, so every message of that
+// chat goes to the target agent until the handoff ends.
+type handoff struct {
+	baseAgent string // agent the router resolves for the chat
+	toAgent   string
+	targetKey string // session key of the target agent
+	until     time.Time
+}
+
+func (h *handoff) expired(now time.Time) bool {
+	return !h.until.IsZero() && now.After(h.until)
+}
+
+func (h *handoff) info() commands.HandoffInfo {
+	return commands.HandoffInfo{From: h.baseAgent, To: h.toAgent, Until: h.until}
+}
+
+// applyHandoff rebinds route to the agent the conversation was handed to.
+// Expired handoffs and handoffs to agents that no longer exist are dropped.
+func (al *AgentLoop) applyHandoff(route routing.ResolvedRoute) routing.ResolvedRoute {
+	al.handoffMu.Lock()
+	defer al.handoffMu.Unlock()
+
+	h, ok := al.handoffs[route.SessionKey]
+	if !ok {
+		return route
+	}
+	if h.expired(time.Now()) {
+		delete(al.handoffs, route.SessionKey)
+		logger.InfoCF("agent", "Handoff expired",
+			map[string]any{"session_key": route.SessionKey, "agent_id": h.toAgent})
+		return route
+	}
+	if _, ok := al.GetRegistry().GetAgent(h.toAgent); !ok {
+		delete(al.handoffs, route.SessionKey)
+		return route
+	}
+	return route.WithAgent(h.toAgent, "handoff")
+}
+
+// handoffBaseKeyLocked maps the session key of a turn to the routed session
+// key its handoff is stored under.
+func (al *AgentLoop) handoffBaseKeyLocked(sessionKey string) string {
+	if _, ok := al.handoffs[sessionKey]; ok {
+		return sessionKey
+	}
+	for baseKey, h := range al.handoffs {
+		if h.targetKey == sessionKey {
+			return baseKey
+		}
+	}
+	return sessionKey
+}
+
+// startHandoff implements the handoff tool for the turn in ctx. The summary
+// is stored as the target session's summary so the target agent picks the
+// conversation up where it was left.
+func (al *AgentLoop) startHandoff(ctx context.Context, req tools.HandoffRequest) (time.Time, error) {
+	ts := turnStateFromContext(ctx)
+	if ts == nil || ts.agent == nil || ts.sessionKey == "" {
+		return time.Time{}, fmt.Errorf("no active conversation to hand off")
+	}
+	if ts.depth > 0 || routing.IsSubagentSessionKey(ts.sessionKey) {
+		return time.Time{}, fmt.Errorf("subagents cannot hand off a conversation")
+	}
+	target, ok := al.GetRegistry().GetAgent(req.AgentID)
+	if !ok {
+		return time.Time{}, fmt.Errorf("agent '%s' not found", req.AgentID)
+	}
+	if target.ID == ts.agent.ID {
+		return time.Time{}, fmt.Errorf("the conversation is already with agent '%s'", target.ID)
+	}
+
+	var until time.Time
+	if req.Duration > 0 {
+		until = time.Now().Add(req.Duration)
+	}
+
+	al.handoffMu.Lock()
+	baseKey := al.handoffBaseKeyLocked(ts.sessionKey)
+	baseAgent := ts.agent.ID
+	if parsed := routing.ParseAgentSessionKey(baseKey); parsed != nil {
+		baseAgent = parsed.AgentID
+	}
+	targetKey := routing.ResolvedRoute{SessionKey: baseKey}.WithAgent(target.ID, "handoff").SessionKey
+	if routing.NormalizeAgentID(target.ID) == routing.NormalizeAgentID(baseAgent) {
+		// Handing back to the routed agent ends the handoff.
+		delete(al.handoffs, baseKey)
+		targetKey = baseKey
+	} else {
+		if al.handoffs == nil {
+			al.handoffs = make(map[string]*handoff)
+		}
+		al.handoffs[baseKey] = &handoff{
+			baseAgent: baseAgent,
+			toAgent:   target.ID,
+			targetKey: targetKey,
+			until:     until,
+		}
+	}
+	al.handoffMu.Unlock()
+
+	if target.Sessions != nil {
+		note := fmt.Sprintf("Agent '%s' handed this conversation to you. Summary:\n%s", ts.agent.ID, req.Summary)
+		if existing := target.Sessions.GetSummary(targetKey); existing != "" {
+			note = existing + "\n\n" + note
+		}
+		target.Sessions.SetSummary(targetKey, note)
+		if err := target.Sessions.Save(targetKey); err != nil {
+			logger.WarnCF("agent", "Failed to save handoff summary",
+				map[string]any{"session_key": targetKey, "error": err.Error()})
+		}
+	}
+
+	logger.InfoCF("agent", "Conversation handed off",
+		map[string]any{
+			"from":        ts.agent.ID,
+			"to":          target.ID,
+			"session_key": baseKey,
+			"until":       until,
+		})
+	return until, nil
+}
+
+// activeHandoff returns the handoff that applies to sessionKey, if any.
+func (al *AgentLoop) activeHandoff(sessionKey string) (commands.HandoffInfo, bool) {
+	al.handoffMu.Lock()
+	defer al.handoffMu.Unlock()
+	h, ok := al.handoffs[al.handoffBaseKeyLocked(sessionKey)]
+	if !ok || h.expired(time.Now()) {
+		return commands.HandoffInfo{}, false
+	}
+	return h.info(), true
+}
+
+// endHandoff returns the conversation of sessionKey to the routed agent.
+func (al *AgentLoop) endHandoff(sessionKey string) (commands.HandoffInfo, bool) {
+	al.handoffMu.Lock()
+	defer al.handoffMu.Unlock()
+	baseKey := al.handoffBaseKeyLocked(sessionKey)
+	h, ok := al.handoffs[baseKey]
+	if !ok {
+		return commands.HandoffInfo{}, false
+	}
+	delete(al.handoffs, baseKey)
+	logger.InfoCF("agent", "Handoff ended",
+		map[string]any{"session_key": baseKey, "agent_id": h.toAgent})
+	return h.info(), !h.expired(time.Now())
+}
+
+// setHandoffCommands wires /back and the handoff line of /show agents to the
+// conversation of sessionKey.
+func (al *AgentLoop) setHandoffCommands(rt *commands.Runtime, sessionKey string) {
+	if sessionKey == "" {
+		return
+	}
+	rt.GetHandoff = func() (commands.HandoffInfo, bool) {
+		return al.activeHandoff(sessionKey)
+	}
+	rt.EndHandoff = func() (commands.HandoffInfo, bool) {
+		return al.endHandoff(sessionKey)
+	}
+}
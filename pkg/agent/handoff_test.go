@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// handoffProvider hands every conversation from the agent that has the
+// handoff tool to "billing"; the billing agent just answers.
+type handoffProvider struct {
+	lastMessages []providers.Message
+}
+
+func (p *handoffProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.lastMessages = append([]providers.Message(nil), messages...)
+	if messages[len(messages)-1].Role == "user" {
+		for _, tool := range tools {
+			if tool.Function.Name == "handoff" {
+				return &providers.LLMResponse{
+					ToolCalls: []providers.ToolCall{{
+						ID:   "call_handoff",
+						Type: "function",
+						Name: "handoff",
+						Arguments: map[string]any{
+							"agent_id": "billing",
+							"summary":  "User wants a refund for order 42.",
+						},
+					}},
+				}, nil
+			}
+		}
+	}
+	return &providers.LLMResponse{Content: "billing here"}, nil
+}
+
+func (p *handoffProvider) GetDefaultModel() string {
+	return "handoff-model"
+}
+
+func newHandoffTestLoop(t *testing.T) (*AgentLoop, *handoffProvider) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+			List: []config.AgentConfig{
+				{ID: "main", Default: true, Subagents: &config.SubagentsConfig{AllowAgents: []string{"billing"}}},
+				{ID: "billing"},
+			},
+		},
+	}
+	cfg.Tools.Handoff.Enabled = true
+	provider := &handoffProvider{}
+	return NewAgentLoop(cfg, bus.NewMessageBus(), provider), provider
+}
+
+func TestHandoff_RoutesConversationUntilBack(t *testing.T) {
+	al, provider := newHandoffTestLoop(t)
+	ctx := context.Background()
+	msg := func(content string) bus.InboundMessage {
+		return bus.InboundMessage{
+			Channel:  "telegram",
+			SenderID: "user1",
+			ChatID:   "chat1",
+			Content:  content,
+			Peer:     bus.Peer{Kind: "direct", ID: "user1"},
+		}
+	}
+
+	if _, ok := al.GetRegistry().GetDefaultAgent().Tools.Get("handoff"); !ok {
+		t.Fatal("expected handoff tool for an agent with allow_agents")
+	}
+	billing, _ := al.GetRegistry().GetAgent("billing")
+	if _, ok := billing.Tools.Get("handoff"); ok {
+		t.Fatal("handoff tool registered for an agent without allow_agents")
+	}
+
+	if _, err := al.processMessage(ctx, msg("I need a refund")); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+
+	route, agent, err := al.resolveMessageRoute(msg("hello"))
+	if err != nil {
+		t.Fatalf("resolveMessageRoute() error = %v", err)
+	}
+	if agent.ID != "billing" || route.MatchedBy != "handoff" {
+		t.Fatalf("route after handoff = %+v (agent %s), want billing", route, agent.ID)
+	}
+	if summary := billing.Sessions.GetSummary(route.SessionKey); !strings.Contains(summary, "refund for order 42") {
+		t.Fatalf("billing session summary = %q", summary)
+	}
+
+	response, err := al.processMessage(ctx, msg("where is my money?"))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "billing here" {
+		t.Fatalf("response = %q, want billing agent reply", response)
+	}
+	if !strings.Contains(provider.lastMessages[0].Content, "refund for order 42") {
+		t.Fatal("billing agent did not see the handoff summary")
+	}
+
+	if response, _ := al.processMessage(ctx, msg("/show agents")); !strings.Contains(response, "Handoff: main -> billing") {
+		t.Fatalf("/show agents = %q", response)
+	}
+	if response, _ := al.processMessage(ctx, msg("/back")); response != "You are back with agent 'main'." {
+		t.Fatalf("/back = %q", response)
+	}
+	if _, agent, _ := al.resolveMessageRoute(msg("hi")); agent.ID != "main" {
+		t.Fatalf("agent after /back = %s, want main", agent.ID)
+	}
+	if response, _ := al.processMessage(ctx, msg("/back")); response != "No handoff is active in this chat." {
+		t.Fatalf("second /back = %q", response)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tracing"
 )
 
 const (
@@ -60,6 +61,8 @@ type processHookRPCMessage struct {
 	Params  json.RawMessage      `json:"params,omitempty"`
 	Result  json.RawMessage      `json:"result,omitempty"`
 	Error   *processHookRPCError `json:"error,omitempty"`
+	// Traceparent is the W3C trace context of the turn, when tracing is on.
+	Traceparent string `json:"traceparent,omitempty"`
 }
 
 type processHookRPCError struct {
@@ -299,8 +302,9 @@ func (ph *ProcessHook) hello(ctx context.Context) error {
 
 func (ph *ProcessHook) notify(ctx context.Context, method string, params any) error {
 	msg := processHookRPCMessage{
-		JSONRPC: processHookJSONRPCVersion,
-		Method:  method,
+		JSONRPC:     processHookJSONRPCVersion,
+		Method:      method,
+		Traceparent: tracing.Traceparent(ctx),
 	}
 	if params != nil {
 		body, err := json.Marshal(params)
@@ -324,9 +328,10 @@ func (ph *ProcessHook) call(ctx context.Context, method string, params any, out
 	ph.pendingMu.Unlock()
 
 	msg := processHookRPCMessage{
-		JSONRPC: processHookJSONRPCVersion,
-		ID:      id,
-		Method:  method,
+		JSONRPC:     processHookJSONRPCVersion,
+		ID:          id,
+		Method:      method,
+		Traceparent: tracing.Traceparent(ctx),
 	}
 	if params != nil {
 		body, err := json.Marshal(params)
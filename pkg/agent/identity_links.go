@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// setLinkCommands wires /link and /unlink to the canonical identity of the
+// sender.
+func (al *AgentLoop) setLinkCommands(rt *commands.Runtime, senderID string) {
+	if al.identityLinks == nil {
+		return
+	}
+	if _, _, ok := identity.ParseCanonicalID(senderID); !ok {
+		return
+	}
+	rt.IssueLinkCode = func() (string, time.Duration, error) {
+		code, err := al.identityLinks.IssueCode(senderID)
+		return code, identity.LinkCodeTTL, err
+	}
+	rt.RedeemLinkCode = func(code string) (string, error) {
+		issuer, err := al.identityLinks.Redeem(code, senderID)
+		if err != nil {
+			logger.WarnCF("agent", "Link code rejected",
+				map[string]any{"sender_id": senderID, "error": err.Error()})
+		}
+		return issuer, err
+	}
+	rt.ConfirmLink = func(claimant string) ([]string, error) {
+		linked, err := al.identityLinks.Confirm(senderID, claimant)
+		if err == nil {
+			logger.InfoCF("agent", "Identities linked",
+				map[string]any{"sender_id": senderID, "linked": linked})
+		}
+		return linked, err
+	}
+	rt.UnlinkIdentity = func() (bool, error) {
+		removed, err := al.identityLinks.Unlink(senderID)
+		if removed && err == nil {
+			logger.InfoCF("agent", "Identity unlinked", map[string]any{"sender_id": senderID})
+		}
+		return removed, err
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/facts"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/memory"
@@ -51,6 +52,13 @@ type AgentInstance struct {
 	// LightProvider is the concrete provider instance for the configured light model.
 	// It is only used when routing selects the light tier for a turn.
 	LightProvider providers.LLMProvider
+
+	// Facts is the agent's long-term memory; nil when memory is disabled.
+	Facts *facts.Store
+	// FactsProvider and FactsModel extract facts after each turn when
+	// memory.extract_model is set.
+	FactsProvider providers.LLMProvider
+	FactsModel    string
 }
 
 // NewAgentInstance creates an agent instance from config.
@@ -121,7 +129,12 @@ func NewAgentInstance(
 			mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseBM25,
 			mcpDiscoveryActive && cfg.Tools.MCP.Discovery.UseRegex,
 		).
-		WithSplitOnMarker(cfg.Agents.Defaults.SplitOnMarker)
+		WithSplitOnMarker(cfg.Agents.Defaults.SplitOnMarker).
+		WithSkillSelection(
+			cfg.Agents.Defaults.IsSkillsAutoMode(),
+			cfg.Agents.Defaults.GetSkillsTopK(),
+			cfg.Agents.Defaults.GetSkillsMinScore(),
+		)
 
 	agentID := routing.DefaultAgentID
 	agentName := ""
@@ -155,6 +168,7 @@ func NewAgentInstance(
 		// forceCompression handles any overshoot.
 		contextWindow = maxTokens * 4
 	}
+	contextBuilder.WithSkillBudget(skillBudgetTokens(contextWindow, maxTokens, defaults.GetSkillsBudgetPercent()))
 
 	temperature := 0.7
 	if defaults.Temperature != nil {
@@ -212,6 +226,21 @@ func NewAgentInstance(
 		}
 	}
 
+	var factStore *facts.Store
+	var factsProvider providers.LLMProvider
+	var factsModel string
+	if defaults.IsMemoryEnabled() {
+		factStore = facts.NewStore(facts.StorePath(workspace), facts.Options{
+			MaxFacts: defaults.Memory.MaxFacts,
+			Embedder: resolveFactEmbedder(cfg, defaults, workspace, agentID),
+		})
+		contextBuilder.WithFactRecall(factStore, defaults.GetMemoryTopK())
+		toolsRegistry.Register(tools.NewRememberTool(factStore))
+		toolsRegistry.Register(tools.NewForgetTool(factStore))
+		toolsRegistry.Register(tools.NewRecallTool(factStore))
+		factsProvider, factsModel = resolveExtractModel(cfg, defaults, workspace, agentID)
+	}
+
 	return &AgentInstance{
 		ID:                        agentID,
 		Name:                      agentName,
@@ -235,6 +264,9 @@ func NewAgentInstance(
 		Router:                    router,
 		LightCandidates:           lightCandidates,
 		LightProvider:             lightProvider,
+		Facts:                     factStore,
+		FactsProvider:             factsProvider,
+		FactsModel:                factsModel,
 	}
 }
 
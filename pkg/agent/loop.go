@@ -20,11 +20,13 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/audio/asr"
 	"github.com/sipeed/picoclaw/pkg/audio/tts"
+	"github.com/sipeed/picoclaw/pkg/browser"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -32,6 +34,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/tracing"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -69,6 +72,33 @@ type AgentLoop struct {
 	activeRequests sync.WaitGroup
 
 	reloadFunc func() error
+
+	// Subagent managers by agent ID. They outlive config reloads so queued
+	// and running tasks are not picked up twice.
+	subagentMu sync.Mutex
+	subagents  map[string]*tools.SubagentManager
+
+	// Active handoffs by routed session key.
+	handoffMu sync.Mutex
+	handoffs  map[string]*handoff
+
+	// Forks created with /fork, by routed session key.
+	forkMu sync.Mutex
+	forks  map[string]*sessionForks
+
+	// Skills pinned with /pin, by session key.
+	skillPinsMu sync.Mutex
+	skillPins   map[string][]string
+
+	// Browser shared by the browser tools of all agents.
+	browserMu sync.Mutex
+	browser   *browser.Browser
+
+	// Sessions of threads started for long replies.
+	threadSessions threadSessions
+
+	// Identities linked with /link.
+	identityLinks *identity.LinkStore
 }
 
 // processOptions configures how a message is processed
@@ -140,6 +170,8 @@ func NewAgentLoop(
 		fallback:    fallbackChain,
 		cmdRegistry: commands.NewRegistry(commands.BuiltinDefinitions()),
 		steering:    newSteeringQueue(parseSteeringMode(cfg.Agents.Defaults.SteeringMode)),
+
+		identityLinks: identity.NewLinkStore(identity.LinkStorePath(cfg.WorkspacePath())),
 	}
 	al.hooks = NewHookManager(eventBus)
 	configureHookManagerFromConfig(al.hooks, cfg)
@@ -168,12 +200,18 @@ func registerSharedTools(
 		}
 	}
 
+	sharedBrowser := al.replaceBrowser(cfg)
+
 	for _, agentID := range registry.ListAgentIDs() {
 		agent, ok := registry.GetAgent(agentID)
 		if !ok {
 			continue
 		}
 
+		if agent.Facts != nil {
+			agent.Facts.SetScopes(al.factScopes)
+		}
+
 		if cfg.Tools.IsToolEnabled("web") {
 			searchTool, err := tools.NewWebSearchTool(tools.WebSearchToolOptions{
 				BraveAPIKeys:          cfg.Tools.Web.Brave.APIKeys.Values(),
@@ -221,6 +259,12 @@ func registerSharedTools(
 				agent.Tools.Register(fetchTool)
 			}
 		}
+		if sharedBrowser != nil {
+			agent.Tools.Register(tools.NewBrowserTool(
+				sharedBrowser,
+				time.Duration(cfg.Tools.Browser.TimeoutSeconds)*time.Second,
+			))
+		}
 
 		// Hardware tools (I2C, SPI) - Linux only, returns error on other platforms
 		if cfg.Tools.IsToolEnabled("i2c") {
@@ -264,6 +308,19 @@ func registerSharedTools(
 			})
 			agent.Tools.Register(reactionTool)
 		}
+		if cfg.Tools.IsToolEnabled("ask_user") {
+			timeout := time.Duration(cfg.Tools.AskUser.TimeoutSeconds) * time.Second
+			if timeout <= 0 {
+				timeout = 5 * time.Minute
+			}
+			send := func(ctx context.Context, msg bus.InteractiveMessage) error {
+				if al.channelManager == nil {
+					return fmt.Errorf("channel manager not configured")
+				}
+				return al.channelManager.SendInteractive(ctx, msg)
+			}
+			agent.Tools.Register(tools.NewAskUserTool(msgBus, send, timeout))
+		}
 
 		// Send file tool (outbound media via MediaStore — store injected later by SetMediaStore)
 		if cfg.Tools.IsToolEnabled("send_file") {
@@ -322,7 +379,16 @@ func registerSharedTools(
 			}
 
 			if install_skills_enable {
-				agent.Tools.Register(tools.NewInstallSkillTool(registryMgr, agent.Workspace))
+				security := cfg.Tools.Skills.Security
+				installTool := tools.NewInstallSkillTool(registryMgr, agent.Workspace)
+				installTool.SetSecurityPolicy(skills.SecurityPolicy{
+					TrustedKeys:      security.TrustedKeys,
+					RequireSignature: security.RequireSignature,
+					BlockOn:          security.BlockOn,
+					MaxBinarySize:    int64(security.MaxBinarySizeKB) * 1024,
+					AllowedHosts:     security.AllowedHosts,
+				})
+				agent.Tools.Register(installTool)
 			}
 		}
 
@@ -331,7 +397,7 @@ func registerSharedTools(
 		spawnEnabled := cfg.Tools.IsToolEnabled("spawn")
 		spawnStatusEnabled := cfg.Tools.IsToolEnabled("spawn_status")
 		if (spawnEnabled || spawnStatusEnabled) && cfg.Tools.IsToolEnabled("subagent") {
-			subagentManager := tools.NewSubagentManager(provider, agent.Model, agent.Workspace)
+			subagentManager := al.subagentManager(agent, provider, cfg)
 			subagentManager.SetLLMOptions(agent.MaxTokens, agent.Temperature)
 
 			// Inject a media resolver so the legacy RunToolLoop fallback path can
@@ -353,13 +419,17 @@ func registerSharedTools(
 			) (*tools.ToolResult, error) {
 				// 1. Recover parent Turn State from Context
 				parentTS := turnStateFromContext(ctx)
-				if parentTS == nil {
+				adhoc := parentTS == nil
+				if adhoc {
 					// Fallback: If no turnState exists in context, create an isolated ad-hoc root turn state
-					// so that the tool can still function outside of an agent loop (e.g. tests, raw invocations).
+					// so that the tool can still function outside of an agent loop (e.g. queued tasks, tests).
 					parentTS = &turnState{
 						ctx:            ctx,
+						agent:          agent,
 						turnID:         "adhoc-root",
 						depth:          0,
+						channel:        tools.ToolChannel(ctx),
+						chatID:         tools.ToolChatID(ctx),
 						session:        nil, // Ephemeral session not needed for adhoc spawn
 						pendingResults: make(chan *tools.ToolResult, 16),
 						concurrencySem: make(chan struct{}, 5),
@@ -393,6 +463,10 @@ func registerSharedTools(
 					Model:        modelToUse,
 					Tools:        tlSlice,
 					SystemPrompt: systemPrompt,
+					OnProgress:   tools.TaskProgress(ctx),
+					// Queued tasks have no parent turn; canceling the task
+					// must stop the sub-turn.
+					FollowParentCancel: adhoc,
 				}
 				if hasMaxTokens {
 					cfg.MaxTokens = maxTokens
@@ -428,6 +502,23 @@ func registerSharedTools(
 		} else if (spawnEnabled || spawnStatusEnabled) && !cfg.Tools.IsToolEnabled("subagent") {
 			logger.WarnCF("agent", "spawn/spawn_status tools require subagent to be enabled", nil)
 		}
+
+		// Handoff passes the conversation to one of the agents this agent
+		// may call as a subagent. Registered after the subagent tools are
+		// cloned so subagents cannot hand off.
+		if cfg.Tools.IsToolEnabled("handoff") && agent.Subagents != nil && len(agent.Subagents.AllowAgents) > 0 {
+			handoffTool := tools.NewHandoffTool(al.startHandoff,
+				time.Duration(cfg.Tools.Handoff.DefaultMinutes)*time.Minute)
+			currentAgentID := agentID
+			handoffTool.SetAllowlistChecker(func(targetAgentID string) bool {
+				return registry.CanSpawnSubagent(currentAgentID, targetAgentID)
+			})
+			agent.Tools.Register(handoffTool)
+		}
+
+		if cfg.Tools.IsToolEnabled("run_workflow") {
+			al.registerWorkflowTool(agent)
+		}
 	}
 }
 
@@ -440,6 +531,7 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 	if err := al.ensureMCPInitialized(ctx); err != nil {
 		return err
 	}
+	al.startSubagentQueues()
 
 	idleTicker := time.NewTicker(100 * time.Millisecond)
 	defer idleTicker.Stop()
@@ -523,10 +615,10 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				for al.pendingSteeringCountForScope(target.SessionKey) > 0 {
 					logger.InfoCF("agent", "Continuing queued steering after turn end",
 						map[string]any{
-							"channel":     target.Channel,
-							"chat_id":     target.ChatID,
-							"session_key": target.SessionKey,
-							"queue_depth": al.pendingSteeringCountForScope(target.SessionKey),
+							"channel":              target.Channel,
+							"chat_id":              target.ChatID,
+							logger.FieldSessionKey: target.SessionKey,
+							"queue_depth":          al.pendingSteeringCountForScope(target.SessionKey),
 						})
 
 					continued, continueErr := al.Continue(ctx, target.SessionKey, target.Channel, target.ChatID)
@@ -551,10 +643,10 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				for al.pendingSteeringCountForScope(target.SessionKey) > 0 {
 					logger.InfoCF("agent", "Draining steering queued during turn shutdown",
 						map[string]any{
-							"channel":     target.Channel,
-							"chat_id":     target.ChatID,
-							"session_key": target.SessionKey,
-							"queue_depth": al.pendingSteeringCountForScope(target.SessionKey),
+							"channel":              target.Channel,
+							"chat_id":              target.ChatID,
+							logger.FieldSessionKey: target.SessionKey,
+							"queue_depth":          al.pendingSteeringCountForScope(target.SessionKey),
 						})
 
 					continued, continueErr := al.Continue(ctx, target.SessionKey, target.Channel, target.ChatID)
@@ -575,7 +667,7 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				}
 
 				if finalResponse != "" {
-					al.PublishResponseIfNeeded(ctx, target.Channel, target.ChatID, finalResponse)
+					al.publishResponse(ctx, msg, target.SessionKey, finalResponse)
 				}
 			}()
 		}
@@ -658,9 +750,16 @@ func (al *AgentLoop) Stop() {
 }
 
 func (al *AgentLoop) PublishResponseIfNeeded(ctx context.Context, channel, chatID, response string) {
+	al.publishResponse(ctx, bus.InboundMessage{Channel: channel, ChatID: chatID}, "", response)
+}
+
+// publishResponse sends response to the chat of msg unless the message tool
+// already answered. Long replies may go to a new thread, see replyThread.
+func (al *AgentLoop) publishResponse(ctx context.Context, msg bus.InboundMessage, sessionKey, response string) {
 	if response == "" {
 		return
 	}
+	channel, chatID := msg.Channel, msg.ChatID
 
 	alreadySent := false
 	defaultAgent := al.GetRegistry().GetDefaultAgent()
@@ -681,19 +780,53 @@ func (al *AgentLoop) PublishResponseIfNeeded(ctx context.Context, channel, chatI
 		return
 	}
 
+	threadID := al.replyThread(ctx, msg, sessionKey, response)
 	al.bus.PublishOutbound(ctx, bus.OutboundMessage{
-		Channel: channel,
-		ChatID:  chatID,
-		Content: response,
+		Channel:  channel,
+		ChatID:   chatID,
+		Content:  response,
+		ThreadID: threadID,
 	})
 	logger.InfoCF("agent", "Published outbound response",
 		map[string]any{
 			"channel":     channel,
 			"chat_id":     chatID,
+			"thread_id":   threadID,
 			"content_len": len(response),
 		})
 }
 
+// replyThread starts a thread under msg for a response longer than
+// session.thread_replies_over and returns its ID, or "" to reply in place.
+// Only messages in the main timeline of a group or channel qualify. The
+// thread keeps sessionKey, so it continues the conversation it branched
+// from.
+func (al *AgentLoop) replyThread(ctx context.Context, msg bus.InboundMessage, sessionKey, response string) string {
+	if al.cfg == nil || al.channelManager == nil || msg.MessageID == "" || msg.ThreadID != "" ||
+		msg.Peer.Kind == "" || msg.Peer.Kind == "direct" {
+		return ""
+	}
+	limit := al.cfg.Session.ThreadRepliesOver
+	if limit <= 0 || len([]rune(response)) <= limit {
+		return ""
+	}
+
+	title, _, _ := strings.Cut(strings.TrimSpace(msg.Content), "\n")
+	threadID, err := al.channelManager.StartThread(ctx, msg.Channel, msg.ChatID, msg.MessageID,
+		utils.Truncate(title, 80))
+	if err != nil {
+		logger.DebugCF("agent", "Replying in place, no thread started", map[string]any{
+			"channel": msg.Channel,
+			"error":   err.Error(),
+		})
+		return ""
+	}
+	if sessionKey != "" {
+		al.threadSessions.store(msg.Channel+":"+threadID, sessionKey)
+	}
+	return threadID
+}
+
 func (al *AgentLoop) buildContinuationTarget(msg bus.InboundMessage) (*continuationTarget, error) {
 	if msg.Channel == "system" {
 		return nil, nil
@@ -713,6 +846,9 @@ func (al *AgentLoop) buildContinuationTarget(msg bus.InboundMessage) (*continuat
 
 // Close releases resources held by agent session stores. Call after Stop.
 func (al *AgentLoop) Close() {
+	al.stopSubagentQueues()
+	al.closeBrowser()
+
 	mcpManager := al.mcp.takeManager()
 
 	if mcpManager != nil {
@@ -775,6 +911,20 @@ func (al *AgentLoop) EventDrops(kind EventKind) int64 {
 	return al.eventBus.Dropped(kind)
 }
 
+// ProviderStatus returns the cooldown state of providers that have failed.
+func (al *AgentLoop) ProviderStatus() []providers.ProviderStatus {
+	if al == nil {
+		return nil
+	}
+	al.mu.RLock()
+	fallback := al.fallback
+	al.mu.RUnlock()
+	if fallback == nil {
+		return nil
+	}
+	return fallback.Cooldowns().Snapshot()
+}
+
 type turnEventScope struct {
 	agentID    string
 	sessionKey string
@@ -856,11 +1006,11 @@ func hookDeniedToolContent(prefix, reason string) string {
 
 func (al *AgentLoop) logEvent(evt Event) {
 	fields := map[string]any{
-		"event_kind":  evt.Kind.String(),
-		"agent_id":    evt.Meta.AgentID,
-		"turn_id":     evt.Meta.TurnID,
-		"session_key": evt.Meta.SessionKey,
-		"iteration":   evt.Meta.Iteration,
+		"event_kind":           evt.Kind.String(),
+		logger.FieldAgentID:    evt.Meta.AgentID,
+		logger.FieldTurnID:     evt.Meta.TurnID,
+		logger.FieldSessionKey: evt.Meta.SessionKey,
+		"iteration":            evt.Meta.Iteration,
 	}
 
 	if evt.Meta.TracePath != "" {
@@ -964,6 +1114,9 @@ func (al *AgentLoop) RegisterTool(tool tools.Tool) {
 
 func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
 	al.channelManager = cm
+	if cm != nil {
+		cm.SetIdentityLinks(al.identityLinks)
+	}
 }
 
 // ReloadProviderAndConfig atomically swaps the provider and config with proper synchronization.
@@ -1322,6 +1475,109 @@ func (al *AgentLoop) ProcessHeartbeat(
 	})
 }
 
+// ProcessTrigger runs an event-triggered turn on the given agent, falling
+// back to the default agent. The turn keeps its own session so the agent
+// can relate successive events of the same trigger.
+func (al *AgentLoop) ProcessTrigger(
+	ctx context.Context,
+	agentID, content, sessionKey, channel, chatID string,
+) (string, error) {
+	if err := al.ensureHooksInitialized(ctx); err != nil {
+		return "", err
+	}
+	if err := al.ensureMCPInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	registry := al.GetRegistry()
+	agent, ok := registry.GetAgent(agentID)
+	if !ok {
+		agent = registry.GetDefaultAgent()
+	}
+	if agent == nil {
+		return "", fmt.Errorf("no agent available for trigger (agent_id=%s)", agentID)
+	}
+	return al.runAgentLoop(ctx, agent, processOptions{
+		SessionKey:           sessionKey,
+		Channel:              channel,
+		ChatID:               chatID,
+		UserMessage:          content,
+		DefaultResponse:      defaultResponse,
+		EnableSummary:        true,
+		SendResponse:         false,
+		SuppressToolFeedback: true,
+	})
+}
+
+// ProcessRemoteTask runs a task delegated by a swarm peer on the given agent,
+// or on the default agent when agentID is empty. An unknown agent is an
+// error rather than a silent fallback. Tasks are self-contained, so no
+// history is loaded; the reply goes back to the peer instead of a channel.
+func (al *AgentLoop) ProcessRemoteTask(ctx context.Context, agentID, content, peer string) (string, error) {
+	if err := al.ensureHooksInitialized(ctx); err != nil {
+		return "", err
+	}
+	if err := al.ensureMCPInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	registry := al.GetRegistry()
+	agent := registry.GetDefaultAgent()
+	if agentID != "" {
+		var ok bool
+		if agent, ok = registry.GetAgent(agentID); !ok {
+			return "", fmt.Errorf("agent %q not found", agentID)
+		}
+	}
+	if agent == nil {
+		return "", fmt.Errorf("no agent available for swarm task")
+	}
+	return al.runAgentLoop(ctx, agent, processOptions{
+		SessionKey:           "swarm:" + peer,
+		Channel:              "cli",
+		ChatID:               "direct",
+		UserMessage:          content,
+		DefaultResponse:      defaultResponse,
+		SendResponse:         false,
+		SuppressToolFeedback: true,
+		NoHistory:            true,
+	})
+}
+
+// Complete sends a single stateless prompt to the default agent's model and
+// returns the reply. No tools, history or session are involved; the light
+// model is preferred when routing is configured.
+func (al *AgentLoop) Complete(ctx context.Context, prompt string) (string, error) {
+	agent := al.GetRegistry().GetDefaultAgent()
+	if agent == nil {
+		return "", fmt.Errorf("no default agent for completion")
+	}
+
+	provider := agent.Provider
+	model := resolvedCandidateModel(agent.Candidates, agent.Model)
+	if agent.Router != nil && agent.LightProvider != nil && len(agent.LightCandidates) > 0 {
+		provider = agent.LightProvider
+		model = resolvedCandidateModel(agent.LightCandidates, agent.Router.LightModel())
+	}
+
+	al.activeRequests.Add(1)
+	defer al.activeRequests.Done()
+	resp, err := provider.Chat(
+		ctx,
+		[]providers.Message{{Role: "user", Content: prompt}},
+		nil,
+		model,
+		map[string]any{
+			"max_tokens":  agent.MaxTokens,
+			"temperature": 0.0,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
 	// Add message preview to log (show full content for error messages)
 	var logContent string
@@ -1334,10 +1590,10 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		"agent",
 		fmt.Sprintf("Processing message from %s:%s: %s", msg.Channel, msg.SenderID, logContent),
 		map[string]any{
-			"channel":     msg.Channel,
-			"chat_id":     msg.ChatID,
-			"sender_id":   msg.SenderID,
-			"session_key": msg.SessionKey,
+			"channel":              msg.Channel,
+			"chat_id":              msg.ChatID,
+			"sender_id":            msg.SenderID,
+			logger.FieldSessionKey: msg.SessionKey,
 		},
 	)
 
@@ -1373,12 +1629,12 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 
 	logger.InfoCF("agent", "Routed message",
 		map[string]any{
-			"agent_id":      agent.ID,
-			"scope_key":     scopeKey,
-			"session_key":   sessionKey,
-			"matched_by":    route.MatchedBy,
-			"route_agent":   route.AgentID,
-			"route_channel": route.Channel,
+			logger.FieldAgentID:    agent.ID,
+			"scope_key":            scopeKey,
+			logger.FieldSessionKey: sessionKey,
+			"matched_by":           route.MatchedBy,
+			"route_agent":          route.AgentID,
+			"route_channel":        route.Channel,
 		})
 
 	opts := processOptions{
@@ -1402,12 +1658,15 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		return response, nil
 	}
 
+	if pinned := al.pinnedSkills(opts.SessionKey); len(pinned) > 0 {
+		opts.ForcedSkills = append(opts.ForcedSkills, pinned...)
+	}
 	if pending := al.takePendingSkills(opts.SessionKey); len(pending) > 0 {
 		opts.ForcedSkills = append(opts.ForcedSkills, pending...)
 		logger.InfoCF("agent", "Applying pending skill override",
 			map[string]any{
-				"session_key": opts.SessionKey,
-				"skills":      strings.Join(pending, ","),
+				logger.FieldSessionKey: opts.SessionKey,
+				"skills":               strings.Join(pending, ","),
 			})
 	}
 
@@ -1423,7 +1682,18 @@ func (al *AgentLoop) resolveMessageRoute(msg bus.InboundMessage) (routing.Resolv
 		ParentPeer: extractParentPeer(msg),
 		GuildID:    inboundMetadata(msg, metadataKeyGuildID),
 		TeamID:     inboundMetadata(msg, metadataKeyTeamID),
+		ThreadID:   msg.ThreadID,
+
+		IdentityLinks: al.identityLinks.Groups(),
 	})
+	if msg.ThreadID != "" {
+		if sessionKey, ok := al.threadSessions.load(msg.Channel + ":" + msg.ThreadID); ok {
+			route.SessionKey = sessionKey
+		}
+	}
+
+	route = al.applySessionFork(route)
+	route = al.applyHandoff(route)
 
 	agent, ok := registry.GetAgent(route.AgentID)
 	if !ok {
@@ -1565,10 +1835,7 @@ func (al *AgentLoop) runAgentLoop(
 	for _, followUp := range result.followUps {
 		if pubErr := al.bus.PublishInbound(ctx, followUp); pubErr != nil {
 			logger.WarnCF("agent", "Failed to publish follow-up after turn",
-				map[string]any{
-					"turn_id": ts.turnID,
-					"error":   pubErr.Error(),
-				})
+				ts.logFields(map[string]any{"error": pubErr.Error()}))
 		}
 	}
 
@@ -1584,10 +1851,10 @@ func (al *AgentLoop) runAgentLoop(
 		responsePreview := utils.Truncate(result.finalContent, 120)
 		logger.InfoCF("agent", fmt.Sprintf("Response: %s", responsePreview),
 			map[string]any{
-				"agent_id":     agent.ID,
-				"session_key":  opts.SessionKey,
-				"iterations":   ts.currentIteration(),
-				"final_length": len(result.finalContent),
+				logger.FieldAgentID:    agent.ID,
+				logger.FieldSessionKey: opts.SessionKey,
+				"iterations":           ts.currentIteration(),
+				"final_length":         len(result.finalContent),
 			})
 	}
 
@@ -1662,8 +1929,23 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState) (turnResult, er
 	al.registerActiveTurn(ts)
 	defer al.clearActiveTurn(ts)
 
+	turnCtx, turnSpan := tracing.Start(turnCtx, "turn "+ts.agentID, tracing.SpanKindInternal,
+		tracing.String("picoclaw.agent_id", ts.agentID),
+		tracing.String("picoclaw.session_key", ts.sessionKey),
+		tracing.String("picoclaw.turn_id", ts.turnID),
+		tracing.String("picoclaw.channel", ts.channel),
+	)
+
 	turnStatus := TurnEndStatusCompleted
 	defer func() {
+		turnSpan.SetAttributes(
+			tracing.String("picoclaw.turn.status", string(turnStatus)),
+			tracing.Int("picoclaw.turn.iterations", ts.currentIteration()),
+		)
+		if turnStatus != TurnEndStatusCompleted {
+			turnSpan.SetError("turn " + string(turnStatus))
+		}
+		turnSpan.End()
 		al.emitEvent(
 			EventKindTurnEnd,
 			ts.eventMeta("runTurn", "turn.end"),
@@ -1702,7 +1984,9 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState) (turnResult, er
 	}
 	ts.captureRestorePoint(history, summary)
 
+	skillNames := activeSkillNames(ts.agent, ts.opts)
 	messages := ts.agent.ContextBuilder.BuildMessages(
+		turnCtx,
 		history,
 		summary,
 		ts.userMessage,
@@ -1711,26 +1995,26 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState) (turnResult, er
 		ts.chatID,
 		ts.opts.SenderID,
 		ts.opts.SenderDisplayName,
-		activeSkillNames(ts.agent, ts.opts)...,
+		skillNames...,
 	)
+	ts.skillTools = buildSkillTools(ts.agent, skillNames)
 
 	cfg := al.GetConfig()
 	maxMediaSize := cfg.Agents.Defaults.GetMaxMediaSize()
 	messages = resolveMediaRefs(messages, al.mediaStore, maxMediaSize)
 
 	if !ts.opts.NoHistory {
-		toolDefs := ts.agent.Tools.ToProviderDefs()
+		toolDefs := ts.toolDefs()
 		if isOverContextBudget(ts.agent.ContextWindow, messages, toolDefs, ts.agent.MaxTokens) {
 			logger.WarnCF("agent", "Proactive compression: context budget exceeded before LLM call",
-				map[string]any{"session_key": ts.sessionKey})
+				ts.logFields(nil))
 			if err := al.contextManager.Compact(turnCtx, &CompactRequest{
 				SessionKey: ts.sessionKey,
 				Reason:     ContextCompressReasonProactive,
 			}); err != nil {
-				logger.WarnCF("agent", "Proactive compact failed", map[string]any{
-					"session_key": ts.sessionKey,
-					"error":       err.Error(),
-				})
+				logger.WarnCF("agent", "Proactive compact failed", ts.logFields(map[string]any{
+					"error": err.Error(),
+				}))
 			}
 			ts.refreshRestorePointFromSession(ts.agent)
 			// Re-assemble from CM after compact.
@@ -1743,7 +2027,7 @@ func (al *AgentLoop) runTurn(ctx context.Context, ts *turnState) (turnResult, er
 				summary = resp.Summary
 			}
 			messages = ts.agent.ContextBuilder.BuildMessages(
-				history, summary, ts.userMessage,
+				turnCtx, history, summary, ts.userMessage,
 				ts.media, ts.channel, ts.chatID,
 				ts.opts.SenderID, ts.opts.SenderDisplayName,
 				activeSkillNames(ts.agent, ts.opts)...,
@@ -1803,18 +2087,15 @@ turnLoop:
 		// Check if parent turn has ended (SubTurn support from HEAD)
 		if ts.parentTurnState != nil && ts.IsParentEnded() {
 			if !ts.critical {
-				logger.InfoCF("agent", "Parent turn ended, non-critical SubTurn exiting gracefully", map[string]any{
-					"agent_id":  ts.agentID,
-					"iteration": iteration,
-					"turn_id":   ts.turnID,
-				})
+				logger.InfoCF("agent", "Parent turn ended, non-critical SubTurn exiting gracefully",
+					ts.logFields(map[string]any{
+						"iteration": iteration,
+					}))
 				break
 			}
-			logger.InfoCF("agent", "Parent turn ended, critical SubTurn continues running", map[string]any{
-				"agent_id":  ts.agentID,
+			logger.InfoCF("agent", "Parent turn ended, critical SubTurn continues running", ts.logFields(map[string]any{
 				"iteration": iteration,
-				"turn_id":   ts.turnID,
-			})
+			}))
 		}
 
 		// Poll for pending SubTurn results (from HEAD)
@@ -1843,12 +2124,11 @@ turnLoop:
 					ts.recordPersistedMessage(pm)
 				}
 				logger.InfoCF("agent", "Injected steering message into context",
-					map[string]any{
-						"agent_id":    ts.agent.ID,
+					ts.logFields(map[string]any{
 						"iteration":   iteration,
 						"content_len": len(pm.Content),
 						"media_count": len(pm.Media),
-					})
+					}))
 			}
 			al.emitEvent(
 				EventKindSteeringInjected,
@@ -1862,14 +2142,13 @@ turnLoop:
 		}
 
 		logger.DebugCF("agent", "LLM iteration",
-			map[string]any{
-				"agent_id":  ts.agent.ID,
+			ts.logFields(map[string]any{
 				"iteration": iteration,
 				"max":       ts.agent.MaxIterations,
-			})
+			}))
 
 		gracefulTerminal, _ := ts.gracefulInterruptRequested()
-		providerToolDefs := ts.agent.Tools.ToProviderDefs()
+		providerToolDefs := ts.toolDefs()
 
 		// Native web search support (from HEAD)
 		_, hasWebSearch := ts.agent.Tools.Get("web_search")
@@ -1922,7 +2201,7 @@ turnLoop:
 				llmOpts["thinking_level"] = string(ts.agent.ThinkingLevel)
 			} else {
 				logger.WarnCF("agent", "thinking_level is set but current provider does not support it, ignoring",
-					map[string]any{"agent_id": ts.agent.ID, "thinking_level": string(ts.agent.ThinkingLevel)})
+					ts.logFields(map[string]any{"thinking_level": string(ts.agent.ThinkingLevel)}))
 			}
 		}
 
@@ -1969,8 +2248,7 @@ turnLoop:
 		)
 
 		logger.DebugCF("agent", "LLM request",
-			map[string]any{
-				"agent_id":          ts.agent.ID,
+			ts.logFields(map[string]any{
 				"iteration":         iteration,
 				"model":             llmModel,
 				"messages_count":    len(callMessages),
@@ -1978,7 +2256,7 @@ turnLoop:
 				"max_tokens":        ts.agent.MaxTokens,
 				"temperature":       ts.agent.Temperature,
 				"system_prompt_len": len(callMessages[0].Content),
-			})
+			}))
 		logger.DebugCF("agent", "Full LLM request",
 			map[string]any{
 				"iteration":     iteration,
@@ -1986,7 +2264,10 @@ turnLoop:
 				"tools_json":    formatToolsForLog(providerToolDefs),
 			})
 
-		callLLM := func(messagesForCall []providers.Message, toolDefsForCall []providers.ToolDefinition) (*providers.LLMResponse, error) {
+		callLLM := func(
+			messagesForCall []providers.Message,
+			toolDefsForCall []providers.ToolDefinition,
+		) (resp *providers.LLMResponse, err error) {
 			providerCtx, providerCancel := context.WithCancel(turnCtx)
 			ts.setProviderCancel(providerCancel)
 			defer func() {
@@ -1994,6 +2275,10 @@ turnLoop:
 				ts.clearProviderCancel(providerCancel)
 			}()
 
+			providerCtx, llmSpan := tracing.Start(providerCtx, "chat "+llmModel, tracing.SpanKindClient,
+				llmSpanAttrs(llmModel, activeCandidates, iteration, len(messagesForCall), len(toolDefsForCall))...)
+			defer func() { endLLMSpan(llmSpan, resp, err) }()
+
 			al.activeRequests.Add(1)
 			defer al.activeRequests.Done()
 
@@ -2002,7 +2287,13 @@ turnLoop:
 					providerCtx,
 					activeCandidates,
 					func(ctx context.Context, provider, model string) (*providers.LLMResponse, error) {
-						return activeProvider.Chat(ctx, messagesForCall, toolDefsForCall, model, llmOpts)
+						ctx, attemptSpan := tracing.Start(ctx, "chat "+model, tracing.SpanKindClient,
+							tracing.String("gen_ai.system", provider),
+							tracing.String("gen_ai.request.model", model),
+						)
+						resp, err := activeProvider.Chat(ctx, messagesForCall, toolDefsForCall, model, llmOpts)
+						endLLMSpan(attemptSpan, resp, err)
+						return resp, err
 					},
 				)
 				if fbErr != nil {
@@ -2013,9 +2304,14 @@ turnLoop:
 						"agent",
 						fmt.Sprintf("Fallback: succeeded with %s/%s after %d attempts",
 							fbResult.Provider, fbResult.Model, len(fbResult.Attempts)+1),
-						map[string]any{"agent_id": ts.agent.ID, "iteration": iteration},
+						ts.logFields(map[string]any{"iteration": iteration}),
 					)
 				}
+				llmSpan.SetAttributes(
+					tracing.String("gen_ai.system", fbResult.Provider),
+					tracing.String("gen_ai.response.model", fbResult.Model),
+					tracing.Int("picoclaw.fallback.attempts", len(fbResult.Attempts)+1),
+				)
 				return fbResult.Response, nil
 			}
 			return activeProvider.Chat(providerCtx, messagesForCall, toolDefsForCall, llmModel, llmOpts)
@@ -2113,10 +2409,9 @@ turnLoop:
 					SessionKey: ts.sessionKey,
 					Reason:     ContextCompressReasonRetry,
 				}); compactErr != nil {
-					logger.WarnCF("agent", "Context overflow compact failed", map[string]any{
-						"session_key": ts.sessionKey,
-						"error":       compactErr.Error(),
-					})
+					logger.WarnCF("agent", "Context overflow compact failed", ts.logFields(map[string]any{
+						"error": compactErr.Error(),
+					}))
 				}
 				ts.refreshRestorePointFromSession(ts.agent)
 				// Re-assemble from CM after compact.
@@ -2129,7 +2424,7 @@ turnLoop:
 					summary = asmResp.Summary
 				}
 				messages = ts.agent.ContextBuilder.BuildMessages(
-					history, summary, "",
+					turnCtx, history, summary, "",
 					nil, ts.channel, ts.chatID, ts.opts.SenderID, ts.opts.SenderDisplayName,
 					activeSkillNames(ts.agent, ts.opts)...,
 				)
@@ -2153,12 +2448,11 @@ turnLoop:
 				},
 			)
 			logger.ErrorCF("agent", "LLM call failed",
-				map[string]any{
-					"agent_id":  ts.agent.ID,
+				ts.logFields(map[string]any{
 					"iteration": iteration,
 					"model":     llmModel,
 					"error":     err.Error(),
-				})
+				}))
 			return turnResult{}, fmt.Errorf("LLM call failed after retries: %w", err)
 		}
 
@@ -2213,16 +2507,18 @@ turnLoop:
 				HasReasoning: response.Reasoning != "" || response.ReasoningContent != "",
 			},
 		)
+		if ts.onProgress != nil && response.Content != "" {
+			ts.onProgress(response.Content)
+		}
 
-		llmResponseFields := map[string]any{
-			"agent_id":       ts.agent.ID,
+		llmResponseFields := ts.logFields(map[string]any{
 			"iteration":      iteration,
 			"content_chars":  len(response.Content),
 			"tool_calls":     len(response.ToolCalls),
 			"reasoning":      response.Reasoning,
 			"target_channel": al.targetReasoningChannelID(ts.channel),
 			"channel":        ts.channel,
-		}
+		})
 		if response.Usage != nil {
 			llmResponseFields["prompt_tokens"] = response.Usage.PromptTokens
 			llmResponseFields["completion_tokens"] = response.Usage.CompletionTokens
@@ -2237,21 +2533,19 @@ turnLoop:
 			}
 			if steerMsgs := al.dequeueSteeringMessagesForScope(ts.sessionKey); len(steerMsgs) > 0 {
 				logger.InfoCF("agent", "Steering arrived after direct LLM response; continuing turn",
-					map[string]any{
-						"agent_id":       ts.agent.ID,
+					ts.logFields(map[string]any{
 						"iteration":      iteration,
 						"steering_count": len(steerMsgs),
-					})
+					}))
 				pendingMessages = append(pendingMessages, steerMsgs...)
 				continue
 			}
 			finalContent = responseContent
 			logger.InfoCF("agent", "LLM response without tool calls (direct answer)",
-				map[string]any{
-					"agent_id":      ts.agent.ID,
+				ts.logFields(map[string]any{
 					"iteration":     iteration,
 					"content_chars": len(finalContent),
-				})
+				}))
 			break
 		}
 
@@ -2265,12 +2559,11 @@ turnLoop:
 			toolNames = append(toolNames, tc.Name)
 		}
 		logger.InfoCF("agent", "LLM requested tool calls",
-			map[string]any{
-				"agent_id":  ts.agent.ID,
+			ts.logFields(map[string]any{
 				"tools":     toolNames,
 				"count":     len(normalizedToolCalls),
 				"iteration": iteration,
-			})
+			}))
 
 		allResponsesHandled := len(normalizedToolCalls) > 0
 		assistantMsg := providers.Message{
@@ -2397,11 +2690,10 @@ turnLoop:
 			argsJSON, _ := json.Marshal(toolArgs)
 			argsPreview := utils.Truncate(string(argsJSON), 200)
 			logger.InfoCF("agent", fmt.Sprintf("Tool call: %s(%s)", toolName, argsPreview),
-				map[string]any{
-					"agent_id":  ts.agent.ID,
+				ts.logFields(map[string]any{
 					"tool":      toolName,
 					"iteration": iteration,
-				})
+				}))
 			al.emitEvent(
 				EventKindToolExecStart,
 				ts.eventMeta("runTurn", "turn.tool.start"),
@@ -2489,7 +2781,13 @@ turnLoop:
 				ts.opts.MessageID,
 				ts.opts.ReplyToMessageID,
 			)
-			toolResult := ts.agent.Tools.ExecuteWithContext(
+			execCtx = tools.WithToolSender(execCtx, ts.opts.SenderID)
+			execCtx, toolSpan := tracing.Start(execCtx, "execute_tool "+toolName, tracing.SpanKindInternal,
+				tracing.String("gen_ai.tool.name", toolName),
+				tracing.String("gen_ai.tool.call.id", toolCallID),
+				tracing.Int("picoclaw.iteration", iteration),
+			)
+			toolResult := ts.toolRegistryFor(toolName).ExecuteWithContext(
 				execCtx,
 				toolName,
 				toolArgs,
@@ -2498,6 +2796,13 @@ turnLoop:
 				asyncCallback,
 			)
 			toolDuration := time.Since(toolStart)
+			if toolResult != nil {
+				toolSpan.SetAttributes(tracing.Bool("picoclaw.tool.async", toolResult.Async))
+				if toolResult.IsError {
+					toolSpan.SetError(utils.Truncate(toolResult.ContentForLLM(), 200))
+				}
+			}
+			toolSpan.End()
 
 			if ts.hardAbortRequested() {
 				turnStatus = TurnEndStatusAborted
@@ -2560,37 +2865,17 @@ turnLoop:
 			}
 
 			if len(toolResult.Media) > 0 && toolResult.ResponseHandled {
-				parts := make([]bus.MediaPart, 0, len(toolResult.Media))
-				for _, ref := range toolResult.Media {
-					part := bus.MediaPart{Ref: ref}
-					if al.mediaStore != nil {
-						if _, meta, err := al.mediaStore.ResolveWithMeta(ref); err == nil {
-							part.Filename = meta.Filename
-							part.ContentType = meta.ContentType
-							part.Type = inferMediaType(meta.Filename, meta.ContentType)
-						}
-					}
-					parts = append(parts, part)
-				}
-				outboundMedia := bus.OutboundMediaMessage{
-					Channel: ts.channel,
-					ChatID:  ts.chatID,
-					Parts:   parts,
-				}
-				if al.channelManager != nil && ts.channel != "" && !constants.IsInternalChannel(ts.channel) {
-					if err := al.channelManager.SendMedia(ctx, outboundMedia); err != nil {
-						logger.WarnCF("agent", "Failed to deliver handled tool media",
-							map[string]any{
-								"agent_id": ts.agent.ID,
-								"tool":     toolName,
-								"channel":  ts.channel,
-								"chat_id":  ts.chatID,
-								"error":    err.Error(),
-							})
-						toolResult = tools.ErrorResult(fmt.Sprintf("failed to deliver attachment: %v", err)).WithError(err)
-					}
-				} else if al.bus != nil {
-					al.bus.PublishOutboundMedia(ctx, outboundMedia)
+				delivered, err := al.deliverMediaRefs(ctx, ts.channel, ts.chatID, toolResult.Media)
+				if err != nil {
+					logger.WarnCF("agent", "Failed to deliver handled tool media",
+						ts.logFields(map[string]any{
+							"tool":    toolName,
+							"channel": ts.channel,
+							"chat_id": ts.chatID,
+							"error":   err.Error(),
+						}))
+					toolResult = tools.ErrorResult(fmt.Sprintf("failed to deliver attachment: %v", err)).WithError(err)
+				} else if !delivered {
 					// Queuing media is only best-effort; it has not been delivered yet.
 					toolResult.ResponseHandled = false
 				}
@@ -2663,12 +2948,11 @@ turnLoop:
 				remaining := len(normalizedToolCalls) - i - 1
 				if remaining > 0 {
 					logger.InfoCF("agent", "Turn checkpoint: skipping remaining tools",
-						map[string]any{
-							"agent_id":  ts.agent.ID,
+						ts.logFields(map[string]any{
 							"completed": i + 1,
 							"skipped":   remaining,
 							"reason":    skipReason,
-						})
+						}))
 					for j := i + 1; j < len(normalizedToolCalls); j++ {
 						skippedTC := normalizedToolCalls[j]
 						al.emitEvent(
@@ -2713,22 +2997,18 @@ turnLoop:
 		if allResponsesHandled {
 			if len(pendingMessages) > 0 {
 				logger.InfoCF("agent", "Pending steering exists after handled tool delivery; continuing turn before finalizing",
-					map[string]any{
-						"agent_id":       ts.agent.ID,
+					ts.logFields(map[string]any{
 						"steering_count": len(pendingMessages),
-						"session_key":    ts.sessionKey,
-					})
+					}))
 				finalContent = ""
 				goto turnLoop
 			}
 
 			if steerMsgs := al.dequeueSteeringMessagesForScope(ts.sessionKey); len(steerMsgs) > 0 {
 				logger.InfoCF("agent", "Steering arrived after handled tool delivery; continuing turn before finalizing",
-					map[string]any{
-						"agent_id":       ts.agent.ID,
+					ts.logFields(map[string]any{
 						"steering_count": len(steerMsgs),
-						"session_key":    ts.sessionKey,
-					})
+					}))
 				pendingMessages = append(pendingMessages, steerMsgs...)
 				finalContent = ""
 				goto turnLoop
@@ -2763,11 +3043,10 @@ turnLoop:
 			ts.setPhase(TurnPhaseCompleted)
 			ts.setFinalContent("")
 			logger.InfoCF("agent", "Tool output satisfied delivery; ending turn without follow-up LLM",
-				map[string]any{
-					"agent_id":   ts.agent.ID,
+				ts.logFields(map[string]any{
 					"iteration":  iteration,
 					"tool_count": len(normalizedToolCalls),
-				})
+				}))
 			return turnResult{
 				finalContent: "",
 				status:       turnStatus,
@@ -2776,18 +3055,16 @@ turnLoop:
 		}
 
 		ts.agent.Tools.TickTTL()
-		logger.DebugCF("agent", "TTL tick after tool execution", map[string]any{
-			"agent_id": ts.agent.ID, "iteration": iteration,
-		})
+		logger.DebugCF("agent", "TTL tick after tool execution", ts.logFields(map[string]any{
+			"iteration": iteration,
+		}))
 	}
 
 	if steerMsgs := al.dequeueSteeringMessagesForScope(ts.sessionKey); len(steerMsgs) > 0 {
 		logger.InfoCF("agent", "Steering arrived after turn completion; continuing turn before finalizing",
-			map[string]any{
-				"agent_id":       ts.agent.ID,
+			ts.logFields(map[string]any{
 				"steering_count": len(steerMsgs),
-				"session_key":    ts.sessionKey,
-			})
+			}))
 		pendingMessages = append(pendingMessages, steerMsgs...)
 		finalContent = ""
 		goto turnLoop
@@ -2837,6 +3114,10 @@ turnLoop:
 		)
 	}
 
+	if ts.agent.Facts != nil && !ts.opts.NoHistory && strings.TrimSpace(ts.userMessage) != "" {
+		al.scheduleFactExtraction(ts.agent, ts.sessionKey, ts.opts.SenderID, ts.userMessage, finalContent)
+	}
+
 	ts.setPhase(TurnPhaseCompleted)
 	return turnResult{
 		finalContent: finalContent,
@@ -2896,19 +3177,19 @@ func (al *AgentLoop) selectCandidates(
 	if !usedLight {
 		logger.DebugCF("agent", "Model routing: primary model selected",
 			map[string]any{
-				"agent_id":  agent.ID,
-				"score":     score,
-				"threshold": agent.Router.Threshold(),
+				logger.FieldAgentID: agent.ID,
+				"score":             score,
+				"threshold":         agent.Router.Threshold(),
 			})
 		return agent.Candidates, resolvedCandidateModel(agent.Candidates, agent.Model), false
 	}
 
 	logger.InfoCF("agent", "Model routing: light model selected",
 		map[string]any{
-			"agent_id":    agent.ID,
-			"light_model": agent.Router.LightModel(),
-			"score":       score,
-			"threshold":   agent.Router.Threshold(),
+			logger.FieldAgentID: agent.ID,
+			"light_model":       agent.Router.LightModel(),
+			"score":             score,
+			"threshold":         agent.Router.Threshold(),
 		})
 	return agent.LightCandidates, resolvedCandidateModel(agent.LightCandidates, agent.Router.LightModel()), true
 }
@@ -3210,7 +3491,18 @@ func (al *AgentLoop) buildCommandsRuntime(agent *AgentInstance, opts *processOpt
 		}
 		return al.reloadFunc()
 	}
+	if opts != nil {
+		al.setHandoffCommands(rt, opts.SessionKey)
+		al.setSkillPinCommands(rt, agent, opts.SessionKey)
+		al.setLinkCommands(rt, opts.SenderID)
+	}
 	if agent != nil {
+		al.setTaskCommands(rt, agent.ID)
+		al.setMemoryCommands(rt, agent, opts)
+		if opts != nil {
+			al.setWorkflowCommands(rt, agent, opts.Channel, opts.ChatID)
+			al.setSessionCommands(rt, agent, opts)
+		}
 		if agent.ContextBuilder != nil {
 			rt.ListSkillNames = agent.ContextBuilder.ListSkillNames
 		}
@@ -3281,7 +3573,7 @@ func buildUseCommandHelp(agent *AgentInstance) string {
 	}
 
 	return fmt.Sprintf(
-		"Usage: /use <skill> [message]\n\nInstalled Skills:\n- %s\n\nUse /use <skill> to apply a skill to your next message, or /use <skill> <message> to force it immediately.",
+		"Usage: /use <skill> [message]\n\nInstalled Skills:\n- %s\n\nUse /use <skill> to apply a skill to your next message, or /use <skill> <message> to force it immediately. Use /pin <skill> to keep it active for the session.",
 		strings.Join(names, "\n- "),
 	)
 }
@@ -51,6 +51,34 @@ func (r *mcpRuntime) takeManager() *mcp.Manager {
 	return manager
 }
 
+// MCPServerStatus reports, for each enabled MCP server in the config,
+// whether it is connected.
+func (al *AgentLoop) MCPServerStatus() map[string]bool {
+	al.mu.RLock()
+	cfg := al.cfg
+	al.mu.RUnlock()
+	if cfg == nil || !cfg.Tools.IsToolEnabled("mcp") {
+		return nil
+	}
+
+	al.mcp.mu.Lock()
+	manager := al.mcp.manager
+	al.mcp.mu.Unlock()
+
+	status := make(map[string]bool)
+	for name, serverCfg := range cfg.Tools.MCP.Servers {
+		if !serverCfg.Enabled {
+			continue
+		}
+		connected := false
+		if manager != nil {
+			_, connected = manager.GetServer(name)
+		}
+		status[name] = connected
+	}
+	return status
+}
+
 func (r *mcpRuntime) hasManager() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
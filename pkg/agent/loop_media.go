@@ -8,6 +8,7 @@ package agent
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"io"
 	"os"
@@ -15,6 +16,8 @@ import (
 
 	"github.com/h2non/filetype"
 
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -87,6 +90,43 @@ func resolveMediaRefs(messages []providers.Message, store media.MediaStore, maxS
 	return result
 }
 
+// deliverMediaRefs sends media refs to a chat. On internal channels, or
+// without a channel manager, the media is only queued on the bus and
+// delivered is false.
+func (al *AgentLoop) deliverMediaRefs(
+	ctx context.Context,
+	channel, chatID string,
+	refs []string,
+) (delivered bool, err error) {
+	parts := make([]bus.MediaPart, 0, len(refs))
+	for _, ref := range refs {
+		part := bus.MediaPart{Ref: ref}
+		if al.mediaStore != nil {
+			if _, meta, err := al.mediaStore.ResolveWithMeta(ref); err == nil {
+				part.Filename = meta.Filename
+				part.ContentType = meta.ContentType
+				part.Type = inferMediaType(meta.Filename, meta.ContentType)
+			}
+		}
+		parts = append(parts, part)
+	}
+	outboundMedia := bus.OutboundMediaMessage{
+		Channel: channel,
+		ChatID:  chatID,
+		Parts:   parts,
+	}
+	if al.channelManager != nil && channel != "" && !constants.IsInternalChannel(channel) {
+		if err := al.channelManager.SendMedia(ctx, outboundMedia); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if al.bus != nil {
+		al.bus.PublishOutboundMedia(ctx, outboundMedia)
+	}
+	return false, nil
+}
+
 func buildArtifactTags(store media.MediaStore, refs []string) []string {
 	if store == nil || len(refs) == 0 {
 		return nil
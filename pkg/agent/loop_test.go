@@ -327,6 +327,75 @@ func TestProcessMessage_UseCommandArmsSkillForNextMessage(t *testing.T) {
 	}
 }
 
+func TestProcessMessage_PinCommandKeepsSkillActive(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillDir := filepath.Join(tmpDir, "skills", "shell")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatalf("mkdir skill dir: %v", err)
+	}
+	if err := os.WriteFile(
+		filepath.Join(skillDir, "SKILL.md"),
+		[]byte("# shell\n\nPrefer concise shell commands and explain them briefly."),
+		0o644,
+	); err != nil {
+		t.Fatalf("write skill file: %v", err)
+	}
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	provider := &recordingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	send := func(content string) string {
+		t.Helper()
+		response, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel:  "telegram",
+			SenderID: "telegram:123",
+			ChatID:   "chat-1",
+			Content:  content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return response
+	}
+
+	if response := send("/pin nope"); !strings.Contains(response, `unknown skill "nope"`) {
+		t.Fatalf("pin unknown response = %q, want unknown skill error", response)
+	}
+	if response := send("/pin shell"); !strings.Contains(response, `Skill "shell" is active for this session`) {
+		t.Fatalf("pin response = %q, want pinned confirmation", response)
+	}
+	if response := send("/pin"); !strings.Contains(response, "shell") {
+		t.Fatalf("pin list response = %q, want shell listed", response)
+	}
+
+	for _, content := range []string{"explain how to list files", "and how to count them"} {
+		send(content)
+		if !strings.Contains(provider.lastMessages[0].Content, "### Skill: shell") {
+			t.Fatalf("system prompt for %q missing pinned skill content", content)
+		}
+	}
+
+	if response := send("/unuse shell"); !strings.Contains(response, "shell") {
+		t.Fatalf("unuse response = %q, want shell deactivated", response)
+	}
+	send("what about directories?")
+	if strings.Contains(provider.lastMessages[0].Content, "### Skill: shell") {
+		t.Fatal("system prompt still contains skill after /unuse")
+	}
+	if response := send("/unuse"); response != "No skills are pinned." {
+		t.Fatalf("unuse response = %q, want no pinned skills", response)
+	}
+}
+
 func TestApplyExplicitSkillCommand_ArmsSkillForNextMessage(t *testing.T) {
 	al, cfg, _, _, cleanup := newTestAgentLoop(t)
 	defer cleanup()
@@ -3046,3 +3115,94 @@ func TestProcessMessage_ContextOverflow_AnthropicStyle(t *testing.T) {
 		t.Fatalf("expected 2 calls for retry, got %d", provider.calls)
 	}
 }
+
+type fakeThreadChannel struct{ fakeChannel }
+
+func (f *fakeThreadChannel) StartThread(ctx context.Context, chatID, messageID, title string) (string, error) {
+	return "thread-" + messageID, nil
+}
+
+func TestThreadSessions_EvictsLeastRecentlyUsed(t *testing.T) {
+	var ts threadSessions
+	ts.store("slack:first", "s1")
+	for i := range maxThreadSessions {
+		if i == maxThreadSessions/2 {
+			ts.load("slack:first")
+		}
+		ts.store(fmt.Sprintf("slack:t%d", i), "s")
+	}
+	if got, ok := ts.load("slack:first"); !ok || got != "s1" {
+		t.Fatalf("recently used thread = %q, %v", got, ok)
+	}
+	if _, ok := ts.load("slack:t0"); ok {
+		t.Fatal("least recently used thread was kept")
+	}
+	if ts.order.Len() != maxThreadSessions {
+		t.Fatalf("remembered %d threads, want %d", ts.order.Len(), maxThreadSessions)
+	}
+}
+
+func TestReplyThread_LongReplyKeepsSession(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Session.ThreadRepliesOver = 10
+	cfg.Session.ThreadScope = "per-thread"
+
+	chManager, err := channels.NewManager(&config.Config{}, bus.NewMessageBus(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create channel manager: %v", err)
+	}
+	chManager.RegisterChannel("slack", &fakeThreadChannel{})
+	al.SetChannelManager(chManager)
+
+	msg := bus.InboundMessage{
+		Channel:   "slack",
+		ChatID:    "C1",
+		MessageID: "m1",
+		Content:   "Explain the release process",
+		Peer:      bus.Peer{Kind: "channel", ID: "C1"},
+	}
+	route, _, err := al.resolveMessageRoute(msg)
+	if err != nil {
+		t.Fatalf("resolveMessageRoute() error = %v", err)
+	}
+
+	if got := al.replyThread(context.Background(), msg, route.SessionKey, "short"); got != "" {
+		t.Fatalf("short reply started thread %q", got)
+	}
+	threadID := al.replyThread(context.Background(), msg, route.SessionKey, strings.Repeat("long ", 10))
+	if threadID != "thread-m1" {
+		t.Fatalf("replyThread() = %q, want thread-m1", threadID)
+	}
+
+	followUp := msg
+	followUp.MessageID = "m2"
+	followUp.ThreadID = threadID
+	threadRoute, _, err := al.resolveMessageRoute(followUp)
+	if err != nil {
+		t.Fatalf("resolveMessageRoute() error = %v", err)
+	}
+	if threadRoute.SessionKey != route.SessionKey {
+		t.Fatalf("thread session = %q, want %q", threadRoute.SessionKey, route.SessionKey)
+	}
+
+	// With per-thread sessions, other threads of the channel get their own.
+	followUp.ThreadID = "thread-other"
+	otherRoute, _, _ := al.resolveMessageRoute(followUp)
+	if otherRoute.SessionKey == route.SessionKey {
+		t.Fatalf("unrelated thread shares the channel session %q", otherRoute.SessionKey)
+	}
+}
+
+func TestProcessRemoteTask_UnknownAgent(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	if _, err := al.ProcessRemoteTask(context.Background(), "nobody", "hello", "peer"); err == nil ||
+		!strings.Contains(err.Error(), `agent "nobody" not found`) {
+		t.Fatalf("ProcessRemoteTask() error = %v", err)
+	}
+	if _, err := al.ProcessRemoteTask(context.Background(), "", "hello", "peer"); err != nil {
+		t.Fatalf("ProcessRemoteTask() on the default agent error = %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// sessionForks are the branches of a routed session created with /fork.
+// Like handoffs they live in memory: after a restart the chat is back on its
+// main conversation, and /fork <name> switches to a fork kept on disk.
+type sessionForks struct {
+	active string // "" for the main conversation
+	names  []string
+}
+
+func forkSessionKey(baseKey, name string) string {
+	return baseKey + ":fork:" + name
+}
+
+// applySessionFork rebinds route to the fork its chat switched to.
+func (al *AgentLoop) applySessionFork(route routing.ResolvedRoute) routing.ResolvedRoute {
+	al.forkMu.Lock()
+	defer al.forkMu.Unlock()
+	if f, ok := al.forks[route.SessionKey]; ok && f.active != "" {
+		route.SessionKey = forkSessionKey(route.SessionKey, f.active)
+	}
+	return route
+}
+
+// forkBaseKeyLocked maps the session key of a turn to the routed session key
+// its forks are stored under.
+func (al *AgentLoop) forkBaseKeyLocked(sessionKey string) string {
+	for baseKey, f := range al.forks {
+		if f.active != "" && forkSessionKey(baseKey, f.active) == sessionKey {
+			return baseKey
+		}
+	}
+	return sessionKey
+}
+
+// rewindHistory drops the last n turns of history. Cuts fall on turn
+// boundaries, so a tool call is never separated from its result.
+func rewindHistory(history []providers.Message, n int) ([]providers.Message, int) {
+	turns := parseTurnBoundaries(history)
+	n = min(n, len(turns))
+	if n <= 0 {
+		return history, 0
+	}
+	return history[:turns[len(turns)-n]], n
+}
+
+// setSessionCommands wires /rewind, /fork, /export and /import to the
+// conversation of opts.
+func (al *AgentLoop) setSessionCommands(rt *commands.Runtime, agent *AgentInstance, opts *processOptions) {
+	if agent.Sessions == nil || opts.SessionKey == "" {
+		return
+	}
+	sessionKey := opts.SessionKey
+	idle := func() error {
+		if al.GetActiveTurnBySession(sessionKey) != nil {
+			return fmt.Errorf("wait until the current reply is finished")
+		}
+		return nil
+	}
+
+	rt.RewindHistory = func(turns int) (int, error) {
+		if err := idle(); err != nil {
+			return 0, err
+		}
+		history, removed := rewindHistory(agent.Sessions.GetHistory(sessionKey), turns)
+		if removed == 0 {
+			return 0, nil
+		}
+		agent.Sessions.SetHistory(sessionKey, history)
+		if err := agent.Sessions.Save(sessionKey); err != nil {
+			return 0, err
+		}
+		logger.InfoCF("agent", "Session rewound",
+			map[string]any{"session_key": sessionKey, "turns": removed})
+		return removed, nil
+	}
+
+	rt.ForkSession = func(name string) (bool, error) {
+		if err := idle(); err != nil {
+			return false, err
+		}
+		return al.forkSession(agent, sessionKey, name)
+	}
+
+	rt.ListForks = func() (string, []string) {
+		al.forkMu.Lock()
+		defer al.forkMu.Unlock()
+		f := al.forks[al.forkBaseKeyLocked(sessionKey)]
+		if f == nil {
+			return commands.MainForkName, nil
+		}
+		current := f.active
+		if current == "" {
+			current = commands.MainForkName
+		}
+		return current, slices.Clone(f.names)
+	}
+
+	rt.ExportSession = func(ctx context.Context, format string) (string, error) {
+		return al.exportSession(ctx, agent, opts, format)
+	}
+
+	rt.ImportSession = func(path string) (int, error) {
+		if err := idle(); err != nil {
+			return 0, err
+		}
+		return al.importSession(agent, opts, path)
+	}
+}
+
+// forkSession switches the chat of sessionKey to the fork name, copying the
+// current conversation into it when the fork does not exist yet.
+func (al *AgentLoop) forkSession(agent *AgentInstance, sessionKey, name string) (bool, error) {
+	if _, ok := al.activeHandoff(sessionKey); ok {
+		return false, fmt.Errorf("the conversation is handed off; use /back first")
+	}
+
+	al.forkMu.Lock()
+	defer al.forkMu.Unlock()
+	baseKey := al.forkBaseKeyLocked(sessionKey)
+	if al.forks == nil {
+		al.forks = make(map[string]*sessionForks)
+	}
+	f := al.forks[baseKey]
+	if f == nil {
+		f = &sessionForks{}
+		al.forks[baseKey] = f
+	}
+	if name == commands.MainForkName {
+		f.active = ""
+		return false, nil
+	}
+
+	forkKey := forkSessionKey(baseKey, name)
+	created := false
+	if len(agent.Sessions.GetHistory(forkKey)) == 0 && agent.Sessions.GetSummary(forkKey) == "" {
+		err := replaceSession(agent.Sessions, forkKey,
+			agent.Sessions.GetSummary(sessionKey), agent.Sessions.GetHistory(sessionKey))
+		if err != nil {
+			return false, err
+		}
+		created = true
+	}
+	if !slices.Contains(f.names, name) {
+		f.names = append(f.names, name)
+	}
+	f.active = name
+
+	logger.InfoCF("agent", "Session forked",
+		map[string]any{"session_key": baseKey, "fork": name, "created": created})
+	return created, nil
+}
+
+// replaceSession sets the summary and history of the session key and saves
+// it. The legacy SessionManager ignores SetHistory and SetSummary for a key
+// it has not seen, so the session is created first and the write checked.
+func replaceSession(sessions session.SessionStore, key, summary string, history []providers.Message) error {
+	if m, ok := sessions.(interface{ GetOrCreate(string) *session.Session }); ok {
+		m.GetOrCreate(key)
+	}
+	sessions.SetHistory(key, history)
+	sessions.SetSummary(key, summary)
+	if len(sessions.GetHistory(key)) != len(history) || sessions.GetSummary(key) != summary {
+		return fmt.Errorf("session %s could not be written", key)
+	}
+	return sessions.Save(key)
+}
+
+// exportSession writes the conversation to the workspace's exports
+// directory and sends it to the chat with the send_file tool.
+func (al *AgentLoop) exportSession(
+	ctx context.Context,
+	agent *AgentInstance,
+	opts *processOptions,
+	format string,
+) (string, error) {
+	if _, ok := agent.Tools.Get("send_file"); !ok {
+		return "", fmt.Errorf("the send_file tool is disabled")
+	}
+	history := agent.Sessions.GetHistory(opts.SessionKey)
+	summary := agent.Sessions.GetSummary(opts.SessionKey)
+	if len(history) == 0 && summary == "" {
+		return "", fmt.Errorf("the conversation is empty")
+	}
+
+	transcript := session.NewTranscript(opts.SessionKey, summary, history)
+	var data []byte
+	if format == "json" {
+		var err error
+		if data, err = transcript.JSON(); err != nil {
+			return "", err
+		}
+	} else {
+		data = []byte(transcript.Markdown())
+	}
+
+	filename := fmt.Sprintf("conversation-%s.%s", time.Now().Format("20060102-150405"), format)
+	path := filepath.Join(agent.Workspace, "exports", filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+
+	result := agent.Tools.ExecuteWithContext(ctx, "send_file",
+		map[string]any{"path": path, "filename": filename}, opts.Channel, opts.ChatID, nil)
+	if result.IsError {
+		return "", fmt.Errorf("%s", result.ForLLM)
+	}
+	if _, err := al.deliverMediaRefs(ctx, opts.Channel, opts.ChatID, result.Media); err != nil {
+		return "", err
+	}
+	return filepath.Join("exports", filename), nil
+}
+
+// importSession replaces the conversation with a JSON transcript, read from
+// the file attached to the command or else from path inside the workspace.
+// Channels add tags such as "[file]" to the text of attachments, so an
+// attachment wins over path.
+func (al *AgentLoop) importSession(agent *AgentInstance, opts *processOptions, path string) (int, error) {
+	switch {
+	case len(opts.Media) > 0 && al.mediaStore != nil:
+		resolved, err := al.mediaStore.Resolve(opts.Media[0])
+		if err != nil {
+			return 0, err
+		}
+		path = resolved
+	case path != "":
+		if !filepath.IsLocal(path) {
+			return 0, fmt.Errorf("the path must be inside the workspace")
+		}
+		path = filepath.Join(agent.Workspace, path)
+	default:
+		return 0, fmt.Errorf("attach an exported .json file or give its path in the workspace")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if maxSize := al.GetConfig().Agents.Defaults.GetMaxMediaSize(); info.Size() > int64(maxSize) {
+		return 0, fmt.Errorf("file too large: %d bytes (max %d bytes)", info.Size(), maxSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	transcript, err := session.ParseTranscript(data)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := replaceSession(agent.Sessions, opts.SessionKey, transcript.Summary, transcript.Messages); err != nil {
+		return 0, err
+	}
+	logger.InfoCF("agent", "Session imported",
+		map[string]any{"session_key": opts.SessionKey, "messages": len(transcript.Messages)})
+	return len(transcript.Messages), nil
+}
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// historyProvider answers with the last user message and remembers what it
+// was sent.
+type historyProvider struct {
+	lastMessages []providers.Message
+}
+
+func (p *historyProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.lastMessages = append([]providers.Message(nil), messages...)
+	return &providers.LLMResponse{Content: "echo: " + messages[len(messages)-1].Content}, nil
+}
+
+func (p *historyProvider) GetDefaultModel() string {
+	return "echo-model"
+}
+
+func userContents(messages []providers.Message) []string {
+	var out []string
+	for _, m := range messages {
+		if m.Role == "user" {
+			out = append(out, m.Content)
+		}
+	}
+	return out
+}
+
+func TestRewindHistory_CutsAtTurnBoundaries(t *testing.T) {
+	history := []providers.Message{
+		{Role: "user", Content: "a"},
+		{Role: "assistant", Content: "A"},
+		{Role: "user", Content: "b"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "c1"}}},
+		{Role: "tool", ToolCallID: "c1"},
+		{Role: "assistant", Content: "B"},
+	}
+
+	kept, removed := rewindHistory(history, 1)
+	if removed != 1 || len(kept) != 2 {
+		t.Fatalf("rewind 1: removed %d, kept %d messages", removed, len(kept))
+	}
+	kept, removed = rewindHistory(history, 5)
+	if removed != 2 || len(kept) != 0 {
+		t.Fatalf("rewind 5: removed %d, kept %d messages", removed, len(kept))
+	}
+	if _, removed = rewindHistory(nil, 1); removed != 0 {
+		t.Fatalf("rewind of empty history removed %d", removed)
+	}
+}
+
+func TestReplaceSession_CreatesLegacySession(t *testing.T) {
+	sessions := session.NewSessionManager(t.TempDir())
+	history := []providers.Message{{Role: "user", Content: "a"}, {Role: "assistant", Content: "A"}}
+
+	if err := replaceSession(sessions, "fork-key", "earlier", history); err != nil {
+		t.Fatalf("replaceSession() error = %v", err)
+	}
+	if got := sessions.GetHistory("fork-key"); len(got) != 2 || got[1].Content != "A" {
+		t.Fatalf("history = %+v", got)
+	}
+	if got := sessions.GetSummary("fork-key"); got != "earlier" {
+		t.Fatalf("summary = %q", got)
+	}
+}
+
+func TestSessionCommands_RewindForkExportImport(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	cfg.Tools.SendFile.Enabled = true
+	provider := &historyProvider{}
+	msgBus := bus.NewMessageBus()
+	al := NewAgentLoop(cfg, msgBus, provider)
+	al.SetMediaStore(media.NewFileMediaStore())
+	ctx := context.Background()
+
+	send := func(chatID, content string) string {
+		t.Helper()
+		response, err := al.processMessage(ctx, bus.InboundMessage{
+			Channel:  "telegram",
+			SenderID: "user1",
+			ChatID:   chatID,
+			Content:  content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage(%q) error = %v", content, err)
+		}
+		return response
+	}
+
+	send("chat1", "one")
+	send("chat1", "two")
+	if reply := send("chat1", "/rewind"); reply != "Removed the last turn." {
+		t.Fatalf("/rewind reply = %q", reply)
+	}
+	send("chat1", "three")
+	if got := userContents(provider.lastMessages); strings.Join(got, ",") != "one,three" {
+		t.Fatalf("after rewind the model saw %v", got)
+	}
+
+	if reply := send("chat1", "/fork draft"); !strings.Contains(reply, `Forked this conversation as "draft"`) {
+		t.Fatalf("/fork draft reply = %q", reply)
+	}
+	send("chat1", "experiment")
+	if got := userContents(provider.lastMessages); strings.Join(got, ",") != "one,three,experiment" {
+		t.Fatalf("in the fork the model saw %v", got)
+	}
+	if reply := send("chat1", "/fork"); !strings.Contains(reply, "Current conversation: draft") {
+		t.Fatalf("/fork reply = %q", reply)
+	}
+
+	send("chat1", "/fork main")
+	send("chat1", "back")
+	if got := userContents(provider.lastMessages); strings.Join(got, ",") != "one,three,back" {
+		t.Fatalf("back on main the model saw %v", got)
+	}
+
+	reply := send("chat1", "/export json")
+	if !strings.HasPrefix(reply, "Exported the conversation to exports/") {
+		t.Fatalf("/export json reply = %q", reply)
+	}
+	path := strings.TrimSuffix(strings.Fields(reply)[4], ".")
+	select {
+	case out := <-msgBus.OutboundMediaChan():
+		if len(out.Parts) != 1 || out.ChatID != "chat1" || !strings.HasSuffix(out.Parts[0].Filename, ".json") {
+			t.Fatalf("outbound media = %+v", out)
+		}
+	default:
+		t.Fatal("export was not sent")
+	}
+
+	if reply := send("chat2", "/import "+path); reply != "Imported 6 messages into this conversation." {
+		t.Fatalf("/import reply = %q", reply)
+	}
+	send("chat2", "continued")
+	if got := userContents(provider.lastMessages); strings.Join(got, ",") != "one,three,back,continued" {
+		t.Fatalf("after import the model saw %v", got)
+	}
+	if reply := send("chat2", "/import ../secret.json"); !strings.Contains(reply, "inside the workspace") {
+		t.Fatalf("/import outside workspace reply = %q", reply)
+	}
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+)
+
+// pinSkill keeps skill active for every message of the session until it is
+// unpinned.
+func (al *AgentLoop) pinSkill(sessionKey, skill string) {
+	al.skillPinsMu.Lock()
+	defer al.skillPinsMu.Unlock()
+
+	for _, pinned := range al.skillPins[sessionKey] {
+		if strings.EqualFold(pinned, skill) {
+			return
+		}
+	}
+	if al.skillPins == nil {
+		al.skillPins = make(map[string][]string)
+	}
+	al.skillPins[sessionKey] = append(al.skillPins[sessionKey], skill)
+}
+
+// unpinSkills unpins skill, or every skill when skill is empty, and returns
+// the skills that were unpinned.
+func (al *AgentLoop) unpinSkills(sessionKey, skill string) []string {
+	al.skillPinsMu.Lock()
+	defer al.skillPinsMu.Unlock()
+
+	pinned := al.skillPins[sessionKey]
+	if skill == "" {
+		delete(al.skillPins, sessionKey)
+		return pinned
+	}
+
+	var kept, removed []string
+	for _, name := range pinned {
+		if strings.EqualFold(name, skill) {
+			removed = append(removed, name)
+		} else {
+			kept = append(kept, name)
+		}
+	}
+	if len(kept) == 0 {
+		delete(al.skillPins, sessionKey)
+	} else {
+		al.skillPins[sessionKey] = kept
+	}
+	return removed
+}
+
+func (al *AgentLoop) pinnedSkills(sessionKey string) []string {
+	al.skillPinsMu.Lock()
+	defer al.skillPinsMu.Unlock()
+	return append([]string(nil), al.skillPins[sessionKey]...)
+}
+
+func (al *AgentLoop) setSkillPinCommands(rt *commands.Runtime, agent *AgentInstance, sessionKey string) {
+	if sessionKey == "" || agent == nil || agent.ContextBuilder == nil {
+		return
+	}
+	rt.PinSkill = func(name string) (string, error) {
+		skill, ok := agent.ContextBuilder.ResolveSkillName(name)
+		if !ok {
+			return "", fmt.Errorf("unknown skill %q, see /list skills for installed skills", name)
+		}
+		al.pinSkill(sessionKey, skill)
+		return skill, nil
+	}
+	rt.UnpinSkills = func(name string) []string {
+		if name == "" {
+			al.clearPendingSkills(sessionKey)
+		}
+		return al.unpinSkills(sessionKey, name)
+	}
+	rt.ListPinnedSkills = func() []string {
+		return al.pinnedSkills(sessionKey)
+	}
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// buildSkillTools returns a registry with the tools declared by the active
+// skills of a turn, or nil when there are none. Skill tools run through the
+// agent's exec tool, so they are only offered when exec is enabled. A skill
+// tool never shadows a tool the agent already has.
+func buildSkillTools(agent *AgentInstance, skillNames []string) *tools.ToolRegistry {
+	if agent == nil || agent.ContextBuilder == nil || agent.ContextBuilder.skillsLoader == nil ||
+		len(skillNames) == 0 {
+		return nil
+	}
+	execTool, ok := agent.Tools.Get("exec")
+	if !ok {
+		return nil
+	}
+	runner, ok := execTool.(*tools.ExecTool)
+	if !ok {
+		return nil
+	}
+
+	var registry *tools.ToolRegistry
+	for _, name := range skillNames {
+		specs, err := agent.ContextBuilder.skillsLoader.LoadSkillTools(name)
+		if err != nil {
+			logger.WarnCF("agent", "Skipping tools of skill", map[string]any{
+				"agent_id": agent.ID,
+				"skill":    name,
+				"error":    err.Error(),
+			})
+			continue
+		}
+		for _, spec := range specs {
+			if _, exists := agent.Tools.Get(spec.Name); exists {
+				logger.WarnCF("agent", "Skill tool conflicts with an existing tool", map[string]any{
+					"agent_id": agent.ID,
+					"skill":    name,
+					"tool":     spec.Name,
+				})
+				continue
+			}
+			if registry == nil {
+				registry = tools.NewToolRegistry()
+			} else if _, exists := registry.Get(spec.Name); exists {
+				logger.WarnCF("agent", "Skill tool declared by several active skills", map[string]any{
+					"agent_id": agent.ID,
+					"skill":    name,
+					"tool":     spec.Name,
+				})
+				continue
+			}
+			registry.Register(tools.NewSkillTool(spec, runner))
+		}
+	}
+	return registry
+}
+
+// toolDefs returns the definitions of the agent's tools and the tools of the
+// turn's active skills.
+func (ts *turnState) toolDefs() []providers.ToolDefinition {
+	defs := ts.agent.Tools.ToProviderDefs()
+	if ts.skillTools != nil {
+		defs = append(defs, ts.skillTools.ToProviderDefs()...)
+	}
+	return defs
+}
+
+// toolRegistryFor returns the registry that executes the named tool.
+func (ts *turnState) toolRegistryFor(name string) *tools.ToolRegistry {
+	if ts.skillTools != nil {
+		if _, ok := ts.skillTools.Get(name); ok {
+			return ts.skillTools
+		}
+	}
+	return ts.agent.Tools
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+func TestBuildSkillTools(t *testing.T) {
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "weather")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skill := `---
+name: weather
+description: Weather lookups
+tools:
+  - name: get_forecast
+    command: echo sunny
+  - name: read_file
+    command: echo shadowed
+---
+# Weather
+`
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skill), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := &AgentInstance{
+		ID:             "main",
+		Tools:          tools.NewToolRegistry(),
+		ContextBuilder: NewContextBuilder(workspace),
+	}
+	agent.Tools.Register(tools.NewReadFileTool(workspace, true, 0))
+
+	if reg := buildSkillTools(agent, []string{"weather"}); reg != nil {
+		t.Fatalf("skill tools offered without the exec tool: %v", reg.List())
+	}
+
+	execTool, err := tools.NewExecTool(workspace, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent.Tools.Register(execTool)
+
+	if reg := buildSkillTools(agent, nil); reg != nil {
+		t.Fatalf("skill tools offered without active skills: %v", reg.List())
+	}
+
+	reg := buildSkillTools(agent, []string{"weather"})
+	if reg == nil {
+		t.Fatal("expected skill tools for the active skill")
+	}
+	if got := reg.List(); len(got) != 1 || got[0] != "get_forecast" {
+		t.Fatalf("skill tools = %v, want [get_forecast]", got)
+	}
+
+	ts := &turnState{agent: agent, skillTools: reg}
+	if ts.toolRegistryFor("get_forecast") != reg {
+		t.Fatal("get_forecast should run from the skill tools")
+	}
+	if ts.toolRegistryFor("read_file") != agent.Tools {
+		t.Fatal("read_file should run from the agent tools")
+	}
+	if defs := ts.toolDefs(); len(defs) != 3 {
+		t.Fatalf("tool definitions = %d, want 3", len(defs))
+	}
+}
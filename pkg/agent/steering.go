@@ -448,8 +448,8 @@ func (al *AgentLoop) HardAbort(sessionKey string) error {
 	}
 
 	logger.InfoCF("agent", "Hard abort triggered", map[string]any{
-		"session_key":            sessionKey,
-		"turn_id":                ts.turnID,
+		logger.FieldSessionKey:   sessionKey,
+		logger.FieldTurnID:       ts.turnID,
 		"depth":                  ts.depth,
 		"initial_history_length": ts.initialHistoryLength,
 	})
@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// SubagentTaskEntry is a persisted subagent task together with the agent
+// whose queue holds it.
+type SubagentTaskEntry struct {
+	Owner string `json:"owner"`
+	tools.SubagentTask
+}
+
+// subagentManager returns the agent's subagent manager, creating it with a
+// task store under <workspace>/tasks on first use. Later calls (after a
+// config reload) update the provider and queue options of the same manager.
+func (al *AgentLoop) subagentManager(
+	agent *AgentInstance,
+	provider providers.LLMProvider,
+	cfg *config.Config,
+) *tools.SubagentManager {
+	opts := tools.TaskQueueOptions{
+		MaxConcurrent:     cfg.Tools.Spawn.MaxConcurrent,
+		ResumeInterrupted: cfg.Tools.Spawn.ResumeInterrupted,
+		KeepFinished:      cfg.Tools.Spawn.KeepFinished,
+	}
+
+	al.subagentMu.Lock()
+	defer al.subagentMu.Unlock()
+
+	if sm, ok := al.subagents[agent.ID]; ok {
+		sm.SetProvider(provider, agent.Model)
+		sm.SetQueueOptions(opts)
+		return sm
+	}
+
+	sm := tools.NewSubagentManager(provider, agent.Model, agent.Workspace)
+	storePath := filepath.Join(agent.Workspace, "tasks", agent.ID+".json")
+	if err := sm.EnableQueue(storePath, opts); err != nil {
+		logger.WarnCF("agent", "Subagent task queue unavailable, spawned tasks will not persist",
+			map[string]any{"agent_id": agent.ID, "path": storePath, "error": err.Error()})
+	}
+	sm.SetResultHandler(al.deliverSubagentResult)
+	if al.running.Load() {
+		sm.Start()
+	}
+
+	if al.subagents == nil {
+		al.subagents = make(map[string]*tools.SubagentManager)
+	}
+	al.subagents[agent.ID] = sm
+	return sm
+}
+
+func (al *AgentLoop) subagentManagers() map[string]*tools.SubagentManager {
+	al.subagentMu.Lock()
+	defer al.subagentMu.Unlock()
+	managers := make(map[string]*tools.SubagentManager, len(al.subagents))
+	for id, sm := range al.subagents {
+		managers[id] = sm
+	}
+	return managers
+}
+
+func (al *AgentLoop) startSubagentQueues() {
+	for _, sm := range al.subagentManagers() {
+		sm.Start()
+	}
+}
+
+func (al *AgentLoop) stopSubagentQueues() {
+	for _, sm := range al.subagentManagers() {
+		sm.Stop()
+	}
+}
+
+// deliverSubagentResult reports a task result to the chat that spawned the
+// task when the spawning turn's callback is gone, e.g. after a restart. It
+// mirrors the async tool callback: the user part is sent directly and the
+// LLM part is handed back to the agent as a system message.
+func (al *AgentLoop) deliverSubagentResult(task tools.SubagentTask, result *tools.ToolResult) {
+	if task.OriginChannel == "" {
+		logger.InfoCF("agent", "Subagent task finished without an origin chat",
+			map[string]any{"task_id": task.ID, "status": task.Status})
+		return
+	}
+
+	if !result.Silent && result.ForUser != "" {
+		outCtx, outCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer outCancel()
+		_ = al.bus.PublishOutbound(outCtx, bus.OutboundMessage{
+			Channel: task.OriginChannel,
+			ChatID:  task.OriginChatID,
+			Content: result.ForUser,
+		})
+	}
+
+	content := result.ContentForLLM()
+	if content == "" {
+		return
+	}
+	content = al.GetConfig().FilterSensitiveData(content)
+
+	logger.InfoCF("agent", "Delivering subagent task result",
+		map[string]any{
+			"task_id":     task.ID,
+			"status":      task.Status,
+			"channel":     task.OriginChannel,
+			"content_len": len(content),
+		})
+	pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer pubCancel()
+	_ = al.bus.PublishInbound(pubCtx, bus.InboundMessage{
+		Channel:  "system",
+		SenderID: "async:spawn",
+		ChatID:   fmt.Sprintf("%s:%s", task.OriginChannel, task.OriginChatID),
+		Content:  fmt.Sprintf("Task '%s' (%s) %s.\n\nResult:\n%s", taskName(task), task.ID, task.Status, content),
+	})
+}
+
+func taskName(task tools.SubagentTask) string {
+	if task.Label != "" {
+		return task.Label
+	}
+	return task.Task
+}
+
+// ListSubagentTasks returns the tasks of every agent's queue, oldest first.
+func (al *AgentLoop) ListSubagentTasks() []SubagentTaskEntry {
+	var entries []SubagentTaskEntry
+	for owner, sm := range al.subagentManagers() {
+		for _, task := range sm.ListTaskCopies() {
+			entries = append(entries, SubagentTaskEntry{Owner: owner, SubagentTask: task})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Created != entries[j].Created {
+			return entries[i].Created < entries[j].Created
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// CancelSubagentTask cancels a queued or running task of the owner agent.
+func (al *AgentLoop) CancelSubagentTask(owner, taskID string) error {
+	sm, ok := al.subagentManagers()[owner]
+	if !ok {
+		return fmt.Errorf("agent %s has no subagent tasks", owner)
+	}
+	return sm.CancelTask(taskID)
+}
+
+// RerunSubagentTask queues a finished task of the owner agent again.
+func (al *AgentLoop) RerunSubagentTask(owner, taskID string) (tools.SubagentTask, error) {
+	sm, ok := al.subagentManagers()[owner]
+	if !ok {
+		return tools.SubagentTask{}, fmt.Errorf("agent %s has no subagent tasks", owner)
+	}
+	return sm.RerunTask(taskID)
+}
+
+// setTaskCommands wires /tasks to the agent's task queue.
+func (al *AgentLoop) setTaskCommands(rt *commands.Runtime, agentID string) {
+	sm, ok := al.subagentManagers()[agentID]
+	if !ok || !sm.QueueEnabled() {
+		return
+	}
+	rt.ListTasks = func() []commands.TaskInfo {
+		tasks := sm.ListTaskCopies()
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].Created < tasks[j].Created })
+		infos := make([]commands.TaskInfo, 0, len(tasks))
+		for _, task := range tasks {
+			infos = append(infos, commands.TaskInfo{
+				ID:            task.ID,
+				Label:         task.Label,
+				Task:          task.Task,
+				Status:        task.Status,
+				Priority:      task.Priority,
+				Output:        task.Output,
+				Result:        task.Result,
+				OriginChannel: task.OriginChannel,
+				OriginChatID:  task.OriginChatID,
+				Created:       task.Created,
+			})
+		}
+		return infos
+	}
+	rt.CancelTask = sm.CancelTask
+	rt.RerunTask = func(id string) (string, error) {
+		task, err := sm.RerunTask(id)
+		return task.ID, err
+	}
+}
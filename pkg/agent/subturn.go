@@ -11,6 +11,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/tracing"
 )
 
 // ====================== Config & Constants ======================
@@ -172,6 +173,20 @@ type SubTurnConfig struct {
 	// Used by team tool to enforce token limits across all team members.
 	InitialTokenBudget *atomic.Int64
 
+	// OnProgress, if set, receives the assistant text of each LLM response
+	// as the SubTurn runs. Used to record partial output of queued tasks.
+	OnProgress func(content string)
+
+	// FollowParentCancel cancels the SubTurn when the caller's ctx is
+	// canceled, instead of letting it run independently of the parent.
+	FollowParentCancel bool
+
+	// Agent runs the SubTurn as this agent instead of the parent's agent.
+	Agent *AgentInstance
+
+	// Label names the SubTurn in its spawn event; defaults to the SubTurn ID.
+	Label string
+
 	// Can be extended with temperature, topP, etc.
 }
 
@@ -328,6 +343,10 @@ func spawnSubTurn(
 	// The child has its own timeout for self-protection.
 	childCtx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
+	if cfg.FollowParentCancel {
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+	}
 
 	childID := al.generateSubTurnID()
 
@@ -335,6 +354,9 @@ func spawnSubTurn(
 	// Wrap it in a shallow copy that uses an ephemeral (in-memory only) session store
 	// so that child turns never pollute or persist to the parent's session history.
 	baseAgent := parentTS.agent
+	if cfg.Agent != nil {
+		baseAgent = cfg.Agent
+	}
 	if baseAgent == nil {
 		baseAgent = al.registry.GetDefaultAgent()
 	}
@@ -377,6 +399,7 @@ func spawnSubTurn(
 	// Set SubTurn-specific fields
 	childTS.cancelFunc = cancel
 	childTS.critical = cfg.Critical
+	childTS.onProgress = cfg.OnProgress
 	childTS.depth = parentTS.depth + 1
 	childTS.parentTurnID = parentTS.turnID
 	childTS.parentTurnState = parentTS
@@ -399,6 +422,17 @@ func spawnSubTurn(
 		childTS.tokenBudget = budget
 	}
 
+	// The child context is not derived from ctx, so carry the trace over
+	// explicitly: the child turn's span nests under the spawn span.
+	spawnCtx, spawnSpan := tracing.Start(ctx, "subturn "+agent.ID, tracing.SpanKindInternal,
+		tracing.String("picoclaw.agent_id", agent.ID),
+		tracing.String("picoclaw.turn_id", childID),
+		tracing.String("picoclaw.parent_turn_id", parentTS.turnID),
+		tracing.String("picoclaw.subturn.label", cfg.Label),
+		tracing.Bool("picoclaw.subturn.async", cfg.Async),
+	)
+	childCtx = tracing.ContextWithSpan(childCtx, tracing.SpanFromContext(spawnCtx))
+
 	// IMPORTANT: Put childTS into childCtx so that code inside runTurn can retrieve it
 	childCtx = withTurnState(childCtx, childTS)
 	childCtx = WithAgentLoop(childCtx, al) // Propagate AgentLoop to child turn
@@ -415,11 +449,15 @@ func spawnSubTurn(
 	parentTS.mu.Unlock()
 
 	// 6. Emit Spawn event
+	label := cfg.Label
+	if label == "" {
+		label = childID
+	}
 	al.emitEvent(EventKindSubTurnSpawn,
 		childTS.eventMeta("spawnSubTurn", "subturn.spawn"),
 		SubTurnSpawnPayload{
 			AgentID:      childTS.agentID,
-			Label:        childID,
+			Label:        label,
 			ParentTurnID: parentTS.turnID,
 		},
 	)
@@ -446,6 +484,8 @@ func spawnSubTurn(
 		if err != nil {
 			status = "error"
 		}
+		spawnSpan.RecordError(err)
+		spawnSpan.End()
 		al.emitEvent(EventKindSubTurnEnd,
 			childTS.eventMeta("spawnSubTurn", "subturn.end"),
 			SubTurnEndPayload{
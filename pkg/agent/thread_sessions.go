@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"container/list"
+	"sync"
+)
+
+// maxThreadSessions bounds the threads remembered by threadSessions. A
+// thread evicted from it falls back to the session routing gives it.
+const maxThreadSessions = 1024
+
+// threadSessions remembers the session of each thread started for a long
+// reply, by "channel:threadID", so that follow-ups in the thread continue
+// the conversation it branched from. Beyond maxThreadSessions the least
+// recently used threads are forgotten. The zero value is ready to use.
+type threadSessions struct {
+	mu    sync.Mutex
+	order list.List // of *threadSession, most recently used first
+	byKey map[string]*list.Element
+}
+
+type threadSession struct {
+	key        string
+	sessionKey string
+}
+
+func (t *threadSessions) store(key, sessionKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.byKey[key]; ok {
+		e.Value.(*threadSession).sessionKey = sessionKey
+		t.order.MoveToFront(e)
+		return
+	}
+	if t.byKey == nil {
+		t.byKey = make(map[string]*list.Element)
+	}
+	t.byKey[key] = t.order.PushFront(&threadSession{key: key, sessionKey: sessionKey})
+	for t.order.Len() > maxThreadSessions {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+		delete(t.byKey, oldest.Value.(*threadSession).key)
+	}
+}
+
+func (t *threadSessions) load(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.byKey[key]
+	if !ok {
+		return "", false
+	}
+	t.order.MoveToFront(e)
+	return e.Value.(*threadSession).sessionKey, true
+}
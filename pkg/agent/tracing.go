@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tracing"
+)
+
+// llmSpanAttrs returns the attributes of an LLM request span. Attribute
+// names follow the OpenTelemetry GenAI conventions where one exists.
+func llmSpanAttrs(
+	model string,
+	candidates []providers.FallbackCandidate,
+	iteration, messages, tools int,
+) []tracing.Attr {
+	attrs := []tracing.Attr{
+		tracing.String("gen_ai.operation.name", "chat"),
+		tracing.String("gen_ai.request.model", model),
+		tracing.Int("picoclaw.iteration", iteration),
+		tracing.Int("picoclaw.llm.messages", messages),
+		tracing.Int("picoclaw.llm.tools", tools),
+	}
+	if len(candidates) > 0 {
+		attrs = append(attrs, tracing.String("gen_ai.system", candidates[0].Provider))
+	}
+	return attrs
+}
+
+// endLLMSpan records the outcome of an LLM request and ends its span.
+func endLLMSpan(span *tracing.Span, resp *providers.LLMResponse, err error) {
+	span.RecordError(err)
+	if resp != nil {
+		if resp.FinishReason != "" {
+			span.SetAttributes(tracing.String("gen_ai.response.finish_reasons", resp.FinishReason))
+		}
+		span.SetAttributes(tracing.Int("picoclaw.llm.tool_calls", len(resp.ToolCalls)))
+		if resp.Usage != nil {
+			span.SetAttributes(
+				tracing.Int("gen_ai.usage.input_tokens", resp.Usage.PromptTokens),
+				tracing.Int("gen_ai.usage.output_tokens", resp.Usage.CompletionTokens),
+			)
+		}
+	}
+	span.End()
+}
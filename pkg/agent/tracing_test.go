@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/tracing"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) Export(span tracing.SpanData) {
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+}
+
+func (r *spanRecorder) Close() error { return nil }
+
+func TestAgentLoop_TracesTurnLLMAndToolSpans(t *testing.T) {
+	recorder := &spanRecorder{}
+	tracing.SetTracer(tracing.NewTracer(tracing.Options{SampleRatio: 1, Exporter: recorder}))
+	defer tracing.SetTracer(nil)
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &scriptedToolProvider{})
+	al.RegisterTool(&mockCustomTool{})
+
+	_, err := al.runAgentLoop(context.Background(), al.registry.GetDefaultAgent(), processOptions{
+		SessionKey:      "session-1",
+		Channel:         "cli",
+		ChatID:          "direct",
+		UserMessage:     "run tool",
+		DefaultResponse: defaultResponse,
+	})
+	if err != nil {
+		t.Fatalf("runAgentLoop failed: %v", err)
+	}
+
+	recorder.mu.Lock()
+	spans := append([]tracing.SpanData(nil), recorder.spans...)
+	recorder.mu.Unlock()
+
+	var turn *tracing.SpanData
+	var chats, toolSpans []tracing.SpanData
+	for i, s := range spans {
+		switch {
+		case strings.HasPrefix(s.Name, "turn "):
+			turn = &spans[i]
+		case s.Kind == tracing.SpanKindClient:
+			chats = append(chats, s)
+		case s.Name == "execute_tool mock_custom":
+			toolSpans = append(toolSpans, s)
+		}
+	}
+	if turn == nil {
+		t.Fatalf("no turn span in %d spans", len(spans))
+	}
+	if len(chats) != 2 || len(toolSpans) != 1 {
+		t.Fatalf("got %d chat and %d tool spans, want 2 and 1", len(chats), len(toolSpans))
+	}
+	for _, s := range append(chats, toolSpans...) {
+		if s.SpanContext.TraceID != turn.SpanContext.TraceID || s.Parent != turn.SpanContext.SpanID {
+			t.Errorf("span %q is not a child of the turn span", s.Name)
+		}
+	}
+	if !hasAttr(*turn, "picoclaw.session_key", "session-1") ||
+		!hasAttr(*turn, "picoclaw.turn.status", string(TurnEndStatusCompleted)) {
+		t.Errorf("turn attributes = %v", turn.Attributes)
+	}
+	if !hasAttr(chats[0], "picoclaw.llm.tool_calls", int64(1)) {
+		t.Errorf("first chat span attributes = %v", chats[0].Attributes)
+	}
+	if !hasAttr(toolSpans[0], "gen_ai.tool.call.id", "call-1") {
+		t.Errorf("tool span attributes = %v", toolSpans[0].Attributes)
+	}
+}
+
+func hasAttr(s tracing.SpanData, key string, value any) bool {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			return a.Value == value
+		}
+	}
+	return false
+}
@@ -76,6 +76,9 @@ type turnState struct {
 	providerCancel        context.CancelFunc
 	turnCancel            context.CancelFunc
 
+	// skillTools holds the tools declared by the turn's active skills.
+	skillTools *tools.ToolRegistry
+
 	restorePointHistory []providers.Message
 	restorePointSummary string
 	persistedMessages   []providers.Message
@@ -94,6 +97,7 @@ type turnState struct {
 	ctx             context.Context    // Context for this turn
 	cancelFunc      context.CancelFunc // Cancel function for this turn's context
 	critical        bool               // Whether this SubTurn should continue after parent ends
+	onProgress      func(string)       // Receives assistant text as the SubTurn runs
 	parentTurnState *turnState         // Reference to parent turnState
 	parentEnded     atomic.Bool        // Whether parent has ended
 	closeOnce       sync.Once          // Ensures pendingResults channel is closed once
@@ -108,6 +112,18 @@ type turnState struct {
 	al *AgentLoop
 }
 
+// logFields adds the agent, session and turn of ts to the fields of a log
+// line.
+func (ts *turnState) logFields(fields map[string]any) map[string]any {
+	if fields == nil {
+		fields = make(map[string]any, 3)
+	}
+	fields[logger.FieldAgentID] = ts.agentID
+	fields[logger.FieldSessionKey] = ts.sessionKey
+	fields[logger.FieldTurnID] = ts.turnID
+	return fields
+}
+
 func newTurnState(agent *AgentInstance, opts processOptions, scope turnEventScope) *turnState {
 	ts := &turnState{
 		agent:       agent,
@@ -349,10 +365,9 @@ func (ts *turnState) ingestMessage(ctx context.Context, al *AgentLoop, msg provi
 		SessionKey: ts.sessionKey,
 		Message:    msg,
 	}); err != nil {
-		logger.WarnCF("agent", "Context manager ingest failed", map[string]any{
-			"session_key": ts.sessionKey,
-			"error":       err.Error(),
-		})
+		logger.WarnCF("agent", "Context manager ingest failed", ts.logFields(map[string]any{
+			"error": err.Error(),
+		}))
 	}
 }
 
@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/commands"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/workflow"
+)
+
+// runWorkflow runs the workflow called name from the workspace of agent.
+// Every agent invocation is a SubTurn of the turn in ctx (or of an ad-hoc
+// root turn when there is none), so steps show up as subturn events.
+func (al *AgentLoop) runWorkflow(
+	ctx context.Context,
+	agent *AgentInstance,
+	name, input, channel, chatID string,
+) (*workflow.Result, error) {
+	def, err := workflow.Find(workflow.Dir(agent.Workspace), name)
+	if err != nil {
+		return nil, err
+	}
+	// Steps run other agents like the spawn tool does, so they obey the
+	// same subagents.allow_agents list. Checked up front so a workflow
+	// never stops halfway on a forbidden step.
+	for _, id := range def.Agents() {
+		if routing.NormalizeAgentID(id) == agent.ID {
+			continue
+		}
+		if _, ok := al.GetRegistry().GetAgent(id); !ok {
+			return nil, fmt.Errorf("agent '%s' not found", id)
+		}
+		if !al.GetRegistry().CanSpawnSubagent(agent.ID, id) {
+			return nil, fmt.Errorf("workflow %q: agent '%s' is not allowed to run agent '%s'", def.Name, agent.ID, id)
+		}
+	}
+
+	parentTS := turnStateFromContext(ctx)
+	if parentTS == nil {
+		parentTS = &turnState{
+			ctx:            ctx,
+			agent:          agent,
+			turnID:         "adhoc-root",
+			depth:          0,
+			channel:        channel,
+			chatID:         chatID,
+			pendingResults: make(chan *tools.ToolResult, 16),
+			concurrencySem: make(chan struct{}, 5),
+		}
+	}
+
+	step := func(ctx context.Context, call workflow.Call) (string, error) {
+		stepAgent := agent
+		if call.AgentID != "" {
+			a, ok := al.GetRegistry().GetAgent(call.AgentID)
+			if !ok {
+				return "", fmt.Errorf("agent '%s' not found", call.AgentID)
+			}
+			stepAgent = a
+		}
+		res, err := spawnSubTurn(ctx, al, parentTS, SubTurnConfig{
+			Model:              stepAgent.Model,
+			Agent:              stepAgent,
+			SystemPrompt:       call.Prompt,
+			Timeout:            call.Timeout,
+			Label:              fmt.Sprintf("workflow:%s/%s", call.Workflow, call.Step),
+			FollowParentCancel: true,
+		})
+		if err != nil {
+			return "", err
+		}
+		return res.ForLLM, nil
+	}
+
+	start := time.Now()
+	logger.InfoCF("agent", "Workflow started",
+		map[string]any{"workflow": def.Name, "agent_id": agent.ID, "steps": len(def.Steps)})
+	result, err := workflow.Run(ctx, def, input, step)
+	if err != nil {
+		logger.WarnCF("agent", "Workflow failed",
+			map[string]any{"workflow": def.Name, "error": err.Error()})
+		return result, err
+	}
+	logger.InfoCF("agent", "Workflow finished",
+		map[string]any{"workflow": def.Name, "duration": time.Since(start).String()})
+	return result, nil
+}
+
+// RunWorkflowDirect runs a workflow as the default agent outside of any
+// conversation turn. Used by cron jobs.
+func (al *AgentLoop) RunWorkflowDirect(ctx context.Context, name, input, channel, chatID string) (string, error) {
+	agent := al.GetRegistry().GetDefaultAgent()
+	if agent == nil {
+		return "", fmt.Errorf("no default agent")
+	}
+	result, err := al.runWorkflow(ctx, agent, name, input, channel, chatID)
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// registerWorkflowTool gives agent the run_workflow tool when its workspace
+// has a workflows directory; /run and cron jobs work regardless.
+func (al *AgentLoop) registerWorkflowTool(agent *AgentInstance) {
+	if _, err := os.Stat(workflow.Dir(agent.Workspace)); err != nil {
+		return
+	}
+	agent.Tools.Register(tools.NewRunWorkflowTool(func(ctx context.Context, name, input string) (string, error) {
+		result, err := al.runWorkflow(ctx, agent, name, input, tools.ToolChannel(ctx), tools.ToolChatID(ctx))
+		if err != nil {
+			return "", err
+		}
+		return result.Output, nil
+	}))
+}
+
+// listWorkflows returns the names of the workflows of agent's workspace.
+func listWorkflows(agent *AgentInstance) []string {
+	defs, err := workflow.LoadAll(workflow.Dir(agent.Workspace))
+	if err != nil {
+		logger.WarnCF("agent", "Some workflows failed to load",
+			map[string]any{"agent_id": agent.ID, "error": err.Error()})
+	}
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+// setWorkflowCommands wires /run to the workflows of agent.
+func (al *AgentLoop) setWorkflowCommands(rt *commands.Runtime, agent *AgentInstance, channel, chatID string) {
+	rt.ListWorkflows = func() []string {
+		return listWorkflows(agent)
+	}
+	rt.RunWorkflow = func(ctx context.Context, name, input string) (string, error) {
+		result, err := al.runWorkflow(ctx, agent, name, input, channel, chatID)
+		if err != nil {
+			return "", err
+		}
+		return result.Output, nil
+	}
+}
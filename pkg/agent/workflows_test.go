@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// echoProvider answers every turn with the last user message, so a workflow's
+// output shows which prompts its steps received.
+type echoProvider struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (p *echoProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	last := messages[len(messages)-1].Content
+	p.mu.Lock()
+	p.prompts = append(p.prompts, last)
+	p.mu.Unlock()
+	return &providers.LLMResponse{Content: "[" + last + "]"}, nil
+}
+
+func (p *echoProvider) GetDefaultModel() string {
+	return "echo-model"
+}
+
+func TestRunWorkflowCommand(t *testing.T) {
+	workspace := t.TempDir()
+	dir := filepath.Join(workspace, "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	def := "steps:\n" +
+		"  - id: draft\n    prompt: \"draft {{.Input}}\"\n" +
+		"  - id: polish\n    agent: editor\n    prompt: \"polish {{.Previous}}\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "note.yaml"), []byte(def), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				ModelName:         "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+			List: []config.AgentConfig{
+				{ID: "main", Default: true, Subagents: &config.SubagentsConfig{AllowAgents: []string{"editor"}}},
+				{ID: "editor"},
+				{ID: "ops"},
+			},
+		},
+	}
+	cfg.Tools.RunWorkflow.Enabled = true
+	provider := &echoProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+
+	if _, ok := al.GetRegistry().GetDefaultAgent().Tools.Get("run_workflow"); !ok {
+		t.Fatal("expected run_workflow tool for a workspace with workflows")
+	}
+
+	msg := func(content string) bus.InboundMessage {
+		return bus.InboundMessage{
+			Channel:  "telegram",
+			SenderID: "user1",
+			ChatID:   "chat1",
+			Content:  content,
+			Peer:     bus.Peer{Kind: "direct", ID: "user1"},
+		}
+	}
+	ctx := context.Background()
+
+	response, err := al.processMessage(ctx, msg("/run"))
+	if err != nil || !strings.Contains(response, "Workflows: note") {
+		t.Fatalf("/run = %q, %v", response, err)
+	}
+
+	response, err = al.processMessage(ctx, msg("/run note a haiku"))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if response != "[polish [draft a haiku]]" {
+		t.Fatalf("/run note = %q", response)
+	}
+	if len(provider.prompts) != 2 {
+		t.Fatalf("provider calls = %d, want 2", len(provider.prompts))
+	}
+
+	// A workflow may only run the agents the caller may spawn.
+	def = "steps:\n" +
+		"  - id: draft\n    prompt: \"draft {{.Input}}\"\n" +
+		"  - id: deploy\n    agent: ops\n    prompt: \"deploy {{.Previous}}\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "deploy.yaml"), []byte(def), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	response, err = al.processMessage(ctx, msg("/run deploy now"))
+	if err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if !strings.Contains(response, "not allowed to run agent 'ops'") {
+		t.Fatalf("/run deploy = %q", response)
+	}
+	if len(provider.prompts) != 2 {
+		t.Fatalf("provider calls = %d after a rejected workflow, want 2", len(provider.prompts))
+	}
+}
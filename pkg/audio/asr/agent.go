@@ -26,6 +26,7 @@ type speechAccumulator struct {
 	speakerID   string
 	sessionID   string
 	channel     string
+	peerKind    string
 }
 
 func (a *speechAccumulator) Push(chunk bus.AudioChunk) {
@@ -139,6 +140,7 @@ func (a *Agent) handleChunk(chunk bus.AudioChunk) {
 			speakerID:   chunk.SpeakerID,
 			sessionID:   chunk.SessionID,
 			channel:     chunk.Channel,
+			peerKind:    chunk.PeerKind,
 		}
 		a.sessions[key] = acc
 		logger.DebugCF("voice-agent", "Started accumulating voice", map[string]any{"key": key, "file": filename})
@@ -235,6 +237,11 @@ func (a *Agent) processUtterance(ctx context.Context, acc *speechAccumulator) {
 		return
 	}
 
+	peerKind := acc.peerKind
+	if peerKind == "" {
+		peerKind = "channel"
+	}
+
 	oralPrompt := "\n\n[SYSTEM]: The user just spoke this to you over voice chat. Please reply in a highly concise, conversational, oral style suitable for text-to-speech. Do not use markdown, emojis, asterisks, or code blocks. Speak naturally."
 
 	if err := a.bus.PublishInbound(ctx, bus.InboundMessage{
@@ -242,7 +249,7 @@ func (a *Agent) processUtterance(ctx context.Context, acc *speechAccumulator) {
 		SenderID: acc.speakerID,
 		ChatID:   acc.chatID,
 		Content:  res.Text + oralPrompt,
-		Peer:     bus.Peer{Kind: "channel", ID: acc.chatID},
+		Peer:     bus.Peer{Kind: peerKind, ID: acc.chatID},
 		Metadata: map[string]string{
 			"is_voice": "true",
 		},
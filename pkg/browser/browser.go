@@ -0,0 +1,394 @@
+// Package browser drives a local headless Chromium over the Chrome DevTools
+// Protocol.
+//
+// A Browser either launches Chromium on first use or attaches to one that is
+// already running at a DevTools URL. Every key (one per chat) gets its own
+// page in its own browser context, so cookies and logins persist for the
+// chat without leaking into others. All requests a page makes, including
+// redirects and subresources, are intercepted and checked with
+// Options.AllowURL before they leave the browser.
+package browser
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultIdleTimeout = 15 * time.Minute
+
+	launchTimeout = 20 * time.Second
+)
+
+// executableNames are looked up in PATH when no executable is configured.
+var executableNames = []string{
+	"chromium",
+	"chromium-browser",
+	"google-chrome",
+	"google-chrome-stable",
+	"chrome",
+	"headless_shell",
+	"msedge",
+}
+
+// Options configures a Browser.
+type Options struct {
+	// CDPURL attaches to a running browser instead of launching one. It is
+	// either the WebSocket debugger URL or the http://host:port DevTools
+	// endpoint.
+	CDPURL string
+	// ExecutablePath is the Chromium binary; empty searches PATH.
+	ExecutablePath string
+	// Proxy is passed to a launched browser as --proxy-server.
+	Proxy string
+	// IdleTimeout closes pages that have not been used for this long.
+	IdleTimeout time.Duration
+	// AllowURL decides whether a page may request rawURL. A nil AllowURL
+	// allows everything.
+	AllowURL func(ctx context.Context, rawURL string) error
+}
+
+// Browser is a shared browser process with one page per key.
+type Browser struct {
+	opts Options
+
+	mu      sync.Mutex
+	conn    *conn
+	cmd     *exec.Cmd
+	dataDir string
+	pages   map[string]*Page // by key
+	closed  bool
+	byID    sync.Map // CDP session ID -> *Page, read by event handlers
+}
+
+// New returns a Browser that connects on first use.
+func New(opts Options) *Browser {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	return &Browser{
+		opts:  opts,
+		pages: make(map[string]*Page),
+	}
+}
+
+// Page returns the page for key, opening it (and the browser) if needed.
+// Pages unused for longer than the idle timeout are closed first.
+func (b *Browser) Page(ctx context.Context, key string) (*Page, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, errClosed
+	}
+	b.closeIdleLocked(ctx)
+	if p, ok := b.pages[key]; ok && !b.conn.closed() {
+		p.touch()
+		return p, nil
+	}
+	if err := b.ensureLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	var created struct {
+		BrowserContextID string `json:"browserContextId"`
+	}
+	if err := b.conn.call(ctx, "", "Target.createBrowserContext", nil, &created); err != nil {
+		return nil, err
+	}
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := b.conn.call(ctx, "", "Target.createTarget", map[string]any{
+		"url":              "about:blank",
+		"browserContextId": created.BrowserContextID,
+	}, &target); err != nil {
+		b.disposeContext(ctx, created.BrowserContextID)
+		return nil, err
+	}
+	var attached struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := b.conn.call(ctx, "", "Target.attachToTarget", map[string]any{
+		"targetId": target.TargetID,
+		"flatten":  true,
+	}, &attached); err != nil {
+		b.disposeContext(ctx, created.BrowserContextID)
+		return nil, err
+	}
+
+	p := &Page{
+		browser:   b,
+		conn:      b.conn,
+		key:       key,
+		sessionID: attached.SessionID,
+		targetID:  target.TargetID,
+		contextID: created.BrowserContextID,
+	}
+	p.touch()
+	b.byID.Store(p.sessionID, p)
+	if err := p.setup(ctx); err != nil {
+		b.byID.Delete(p.sessionID)
+		b.disposeContext(ctx, created.BrowserContextID)
+		return nil, err
+	}
+	b.pages[key] = p
+	return p, nil
+}
+
+// CheckURL applies Options.AllowURL to rawURL.
+func (b *Browser) CheckURL(ctx context.Context, rawURL string) error {
+	if b.opts.AllowURL == nil {
+		return nil
+	}
+	return b.opts.AllowURL(ctx, rawURL)
+}
+
+// ClosePage closes the page for key and forgets its cookies and storage.
+// It reports whether there was a page to close.
+func (b *Browser) ClosePage(ctx context.Context, key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p, ok := b.pages[key]
+	if !ok {
+		return false
+	}
+	b.closePageLocked(ctx, p)
+	return true
+}
+
+// Close closes all pages and stops the browser if it was launched by b.
+// The Browser cannot be used afterwards.
+func (b *Browser) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, p := range b.pages {
+		b.closePageLocked(ctx, p)
+	}
+	return b.shutdownLocked()
+}
+
+func (b *Browser) closeIdleLocked(ctx context.Context) {
+	for _, p := range b.pages {
+		if p.idleFor() > b.opts.IdleTimeout {
+			logger.DebugCF("browser", "Closing idle page", map[string]any{"key": p.key})
+			b.closePageLocked(ctx, p)
+		}
+	}
+}
+
+func (b *Browser) closePageLocked(ctx context.Context, p *Page) {
+	delete(b.pages, p.key)
+	b.byID.Delete(p.sessionID)
+	if b.conn == nil || b.conn.closed() || p.conn != b.conn {
+		return
+	}
+	_ = b.conn.call(ctx, "", "Target.closeTarget", map[string]any{"targetId": p.targetID}, nil)
+	b.disposeContext(ctx, p.contextID)
+}
+
+func (b *Browser) disposeContext(ctx context.Context, id string) {
+	if id == "" {
+		return
+	}
+	_ = b.conn.call(ctx, "", "Target.disposeBrowserContext", map[string]any{"browserContextId": id}, nil)
+}
+
+// ensureLocked connects to the browser, launching it when no CDPURL is
+// configured, and reconnects if the previous connection was lost.
+func (b *Browser) ensureLocked(ctx context.Context) error {
+	if b.conn != nil && !b.conn.closed() {
+		return nil
+	}
+	if b.conn != nil {
+		logger.WarnCF("browser", "Browser connection lost, reconnecting", nil)
+		b.pages = make(map[string]*Page)
+		b.byID.Clear()
+		_ = b.shutdownLocked()
+	}
+
+	wsURL := b.opts.CDPURL
+	if wsURL == "" {
+		var err error
+		if wsURL, err = b.launchLocked(); err != nil {
+			return err
+		}
+	} else if strings.HasPrefix(wsURL, "http://") || strings.HasPrefix(wsURL, "https://") {
+		var err error
+		if wsURL, err = debuggerURL(ctx, wsURL); err != nil {
+			return err
+		}
+	}
+
+	c, err := dial(ctx, wsURL, b.handleEvent)
+	if err != nil {
+		_ = b.shutdownLocked()
+		return err
+	}
+	b.conn = c
+	return nil
+}
+
+func (b *Browser) launchLocked() (string, error) {
+	path := b.opts.ExecutablePath
+	if path == "" {
+		for _, name := range executableNames {
+			if found, err := exec.LookPath(name); err == nil {
+				path = found
+				break
+			}
+		}
+		if path == "" {
+			return "", fmt.Errorf("no Chromium found in PATH (tried %s); set tools.browser.executable_path",
+				strings.Join(executableNames, ", "))
+		}
+	}
+
+	dataDir, err := os.MkdirTemp("", "picoclaw-browser-*")
+	if err != nil {
+		return "", fmt.Errorf("create browser profile dir: %w", err)
+	}
+	args := []string{
+		"--headless=new",
+		"--remote-debugging-port=0",
+		"--user-data-dir=" + dataDir,
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-gpu",
+		"--disable-dev-shm-usage",
+		"--disable-extensions",
+		"--disable-background-networking",
+		"--mute-audio",
+	}
+	if b.opts.Proxy != "" {
+		args = append(args, "--proxy-server="+b.opts.Proxy)
+	}
+	if os.Geteuid() == 0 {
+		// Chromium refuses to start its sandbox as root, as in most containers.
+		args = append(args, "--no-sandbox")
+	}
+	args = append(args, "about:blank")
+
+	cmd := exec.Command(path, args...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		os.RemoveAll(dataDir)
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dataDir)
+		return "", fmt.Errorf("start %s: %w", path, err)
+	}
+	b.cmd = cmd
+	b.dataDir = dataDir
+
+	wsURL, err := readDebuggerURL(stderr, launchTimeout)
+	if err != nil {
+		_ = b.shutdownLocked()
+		return "", fmt.Errorf("start %s: %w", path, err)
+	}
+	logger.InfoCF("browser", "Browser started", map[string]any{"path": path, "pid": cmd.Process.Pid})
+	return wsURL, nil
+}
+
+// readDebuggerURL waits for the "DevTools listening on ws://..." line
+// Chromium prints on startup, then keeps draining stderr.
+func readDebuggerURL(stderr io.Reader, timeout time.Duration) (string, error) {
+	found := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		var sent bool
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !sent {
+				if i := strings.Index(line, "ws://"); i >= 0 && strings.Contains(line, "DevTools listening") {
+					found <- strings.TrimSpace(line[i:])
+					sent = true
+				}
+			}
+		}
+		if !sent {
+			close(found)
+		}
+	}()
+
+	select {
+	case wsURL, ok := <-found:
+		if !ok {
+			return "", errors.New("browser exited before DevTools was ready")
+		}
+		return wsURL, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("DevTools not ready after %s", timeout)
+	}
+}
+
+// debuggerURL resolves the browser WebSocket URL of a DevTools HTTP endpoint.
+func debuggerURL(ctx context.Context, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/json/version", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("query DevTools endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("query DevTools endpoint: HTTP %d", resp.StatusCode)
+	}
+	var version struct {
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", fmt.Errorf("decode DevTools version: %w", err)
+	}
+	if version.WebSocketDebuggerURL == "" {
+		return "", errors.New("DevTools endpoint did not report a WebSocket URL")
+	}
+	return version.WebSocketDebuggerURL, nil
+}
+
+func (b *Browser) shutdownLocked() error {
+	var err error
+	if b.conn != nil {
+		err = b.conn.close()
+		b.conn = nil
+	}
+	if b.cmd != nil && b.cmd.Process != nil {
+		_ = b.cmd.Process.Kill()
+		_ = b.cmd.Wait()
+		b.cmd = nil
+	}
+	if b.dataDir != "" {
+		os.RemoveAll(b.dataDir)
+		b.dataDir = ""
+	}
+	return err
+}
+
+func (b *Browser) handleEvent(sessionID, method string, params json.RawMessage) {
+	if method != "Fetch.requestPaused" {
+		return
+	}
+	if p, ok := b.byID.Load(sessionID); ok {
+		p.(*Page).handleRequestPaused(params)
+	}
+}
@@ -0,0 +1,283 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCDP is a DevTools endpoint with a single scripted page per target.
+type fakeCDP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	calls   []string
+	targets int
+	url     map[string]string // by session ID
+	replies map[string]chan map[string]any
+	// subresources are requested by the page after every navigation.
+	subresources []string
+}
+
+func newFakeCDP(t *testing.T) *fakeCDP {
+	t.Helper()
+	f := &fakeCDP{t: t, url: map[string]string{}, replies: map[string]chan map[string]any{}}
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/version", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"webSocketDebuggerUrl": "ws://" + r.Host + "/devtools/browser/fake",
+		})
+	})
+	mux.HandleFunc("/devtools/browser/fake", func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.ws = ws
+		f.mu.Unlock()
+		for {
+			var msg cdpMessage
+			if err := ws.ReadJSON(&msg); err != nil {
+				return
+			}
+			go f.handle(msg)
+		}
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeCDP) send(v any) {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+	f.mu.Lock()
+	ws := f.ws
+	f.mu.Unlock()
+	_ = ws.WriteJSON(v)
+}
+
+func (f *fakeCDP) called() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeCDP) handle(msg cdpMessage) {
+	var params map[string]any
+	_ = json.Unmarshal(msg.Params, &params)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, msg.Method)
+	f.mu.Unlock()
+
+	result := map[string]any{}
+	switch msg.Method {
+	case "Target.createBrowserContext":
+		result["browserContextId"] = "ctx"
+	case "Target.createTarget":
+		f.mu.Lock()
+		f.targets++
+		result["targetId"] = "target-" + string(rune('0'+f.targets))
+		f.mu.Unlock()
+	case "Target.attachToTarget":
+		result["sessionId"] = "session-" + strings.TrimPrefix(params["targetId"].(string), "target-")
+	case "Page.navigate":
+		target := params["url"].(string)
+		f.mu.Lock()
+		f.url[msg.SessionID] = target
+		subresources := f.subresources
+		f.mu.Unlock()
+		for i, sub := range subresources {
+			id := "req-" + string(rune('a'+i))
+			ch := make(chan map[string]any, 1)
+			f.mu.Lock()
+			f.replies[id] = ch
+			f.mu.Unlock()
+			f.send(map[string]any{
+				"method":    "Fetch.requestPaused",
+				"sessionId": msg.SessionID,
+				"params":    map[string]any{"requestId": id, "request": map[string]any{"url": sub}},
+			})
+			select {
+			case <-ch:
+			case <-time.After(2 * time.Second):
+				f.t.Errorf("request %s was neither continued nor failed", sub)
+			}
+		}
+		result["frameId"] = "frame"
+	case "Fetch.continueRequest", "Fetch.failRequest":
+		f.mu.Lock()
+		ch := f.replies[params["requestId"].(string)]
+		f.mu.Unlock()
+		if ch != nil {
+			ch <- map[string]any{"method": msg.Method}
+		}
+	case "Runtime.evaluate":
+		expr := params["expression"].(string)
+		f.mu.Lock()
+		current := f.url[msg.SessionID]
+		f.mu.Unlock()
+		var value any
+		switch {
+		case strings.Contains(expr, "readyState"):
+			value = true
+		case strings.Contains(expr, "location.href, title"):
+			value = map[string]any{"url": current, "title": "Fake Page"}
+		case strings.Contains(expr, "outerHTML"):
+			value = "<html><body><h1>Hello</h1><p>World</p></body></html>"
+		case strings.Contains(expr, "querySelector"):
+			value = strings.Contains(expr, "#ready")
+		case strings.Contains(expr, "location.href.includes"):
+			value = false
+		}
+		result["result"] = map[string]any{"type": "object", "value": value}
+	case "Accessibility.getFullAXTree":
+		result["nodes"] = []map[string]any{
+			{"nodeId": "1", "role": map[string]any{"value": "RootWebArea"}, "name": map[string]any{"value": "Fake Page"},
+				"childIds": []string{"2", "3", "5"}},
+			{"nodeId": "2", "parentId": "1", "role": map[string]any{"value": "heading"},
+				"name": map[string]any{"value": "Hello"}, "childIds": []string{"6"}},
+			{"nodeId": "6", "parentId": "2", "role": map[string]any{"value": "StaticText"},
+				"name": map[string]any{"value": "Hello"}},
+			{"nodeId": "3", "parentId": "1", "role": map[string]any{"value": "generic"}, "childIds": []string{"4"}},
+			{"nodeId": "4", "parentId": "3", "role": map[string]any{"value": "textbox"},
+				"name": map[string]any{"value": "Search"}, "backendDOMNodeId": 17},
+			{"nodeId": "5", "parentId": "1", "role": map[string]any{"value": "button"},
+				"name": map[string]any{"value": "Go"}, "backendDOMNodeId": 21,
+				"properties": []map[string]any{{"name": "disabled", "value": map[string]any{"value": false}}}},
+		}
+	case "DOM.getContentQuads":
+		if params["backendNodeId"].(float64) != 21 {
+			f.send(map[string]any{"id": msg.ID, "error": map[string]any{"code": -32000, "message": "No node found"}})
+			return
+		}
+		result["quads"] = [][]float64{{10, 10, 30, 10, 30, 20, 10, 20}}
+	case "Page.captureScreenshot":
+		result["data"] = base64.StdEncoding.EncodeToString([]byte("\x89PNG fake"))
+	}
+	f.send(map[string]any{"id": msg.ID, "sessionId": msg.SessionID, "result": result})
+}
+
+func TestBrowserPageActions(t *testing.T) {
+	fake := newFakeCDP(t)
+	b := New(Options{CDPURL: fake.server.URL})
+	defer b.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	page, err := b.Page(ctx, "telegram:1")
+	require.NoError(t, err)
+	assert.Contains(t, fake.called(), "Fetch.enable")
+
+	info, err := page.Navigate(ctx, "https://example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, PageInfo{URL: "https://example.com/", Title: "Fake Page"}, info)
+
+	snapshot, err := page.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `- document "Fake Page"
+  - heading "Hello"
+  - textbox "Search" [ref=e17]
+  - button "Go" [ref=e21]`, snapshot)
+
+	html, err := page.HTML(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, html, "<h1>Hello</h1>")
+
+	require.NoError(t, page.Click(ctx, "e21"))
+	err = page.Click(ctx, "e99")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "take a new snapshot")
+	assert.Error(t, page.Click(ctx, "button"))
+
+	require.NoError(t, page.Type(ctx, "e17", "picoclaw", true))
+	calls := fake.called()
+	assert.Contains(t, calls, "Input.insertText")
+	assert.Contains(t, calls, "Input.dispatchKeyEvent")
+
+	shot, err := page.Screenshot(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("\x89PNG fake"), shot)
+
+	require.NoError(t, page.Wait(ctx, WaitCondition{Selector: "#ready"}))
+	waitCtx, waitCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer waitCancel()
+	err = page.Wait(waitCtx, WaitCondition{URL: "/done"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "err = %v", err)
+}
+
+func TestBrowserPagePerKey(t *testing.T) {
+	fake := newFakeCDP(t)
+	b := New(Options{CDPURL: fake.server.URL})
+	defer b.Close()
+	ctx := context.Background()
+
+	first, err := b.Page(ctx, "chat-a")
+	require.NoError(t, err)
+	again, err := b.Page(ctx, "chat-a")
+	require.NoError(t, err)
+	other, err := b.Page(ctx, "chat-b")
+	require.NoError(t, err)
+	assert.Same(t, first, again, "a chat keeps its page")
+	assert.NotSame(t, first, other)
+
+	assert.True(t, b.ClosePage(ctx, "chat-a"))
+	assert.False(t, b.ClosePage(ctx, "chat-a"))
+	assert.Contains(t, fake.called(), "Target.disposeBrowserContext")
+
+	b.opts.IdleTimeout = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	fresh, err := b.Page(ctx, "chat-b")
+	require.NoError(t, err)
+	assert.NotSame(t, other, fresh, "idle pages are closed")
+
+	require.NoError(t, b.Close())
+	_, err = b.Page(ctx, "chat-b")
+	assert.ErrorIs(t, err, errClosed)
+}
+
+func TestBrowserBlocksRequests(t *testing.T) {
+	fake := newFakeCDP(t)
+	fake.subresources = []string{"https://example.com/app.js", "https://tracker.example/pixel.gif"}
+	b := New(Options{
+		CDPURL: fake.server.URL,
+		AllowURL: func(_ context.Context, rawURL string) error {
+			if strings.Contains(rawURL, "tracker.example") {
+				return errors.New("denied")
+			}
+			return nil
+		},
+	})
+	defer b.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	page, err := b.Page(ctx, "chat")
+	require.NoError(t, err)
+	_, err = page.Navigate(ctx, "https://example.com/")
+	require.NoError(t, err)
+
+	calls := fake.called()
+	assert.Contains(t, calls, "Fetch.continueRequest")
+	assert.Contains(t, calls, "Fetch.failRequest")
+	assert.Equal(t, []string{"https://tracker.example/pixel.gif"}, page.TakeBlocked())
+	assert.Empty(t, page.TakeBlocked())
+}
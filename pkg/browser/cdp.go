@@ -0,0 +1,172 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// errClosed is returned for calls on a connection that has gone away, for
+// example because the browser exited.
+var errClosed = errors.New("browser connection closed")
+
+// cdpError is an error returned by the browser for a command.
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *cdpError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// cdpMessage is a command, response or event on the DevTools WebSocket.
+type cdpMessage struct {
+	ID        int64           `json:"id,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *cdpError       `json:"error,omitempty"`
+}
+
+// eventHandler receives protocol events. It runs on its own goroutine, so it
+// may issue commands on the same connection.
+type eventHandler func(sessionID, method string, params json.RawMessage)
+
+// conn is a Chrome DevTools Protocol connection to the browser target.
+// Page targets are driven through flattened sessions on the same socket.
+type conn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+	nextID  atomic.Int64
+	onEvent eventHandler
+
+	mu      sync.Mutex
+	pending map[int64]chan cdpMessage
+	done    chan struct{}
+	err     error
+}
+
+func dial(ctx context.Context, wsURL string, onEvent eventHandler) (*conn, error) {
+	dialer := websocket.Dialer{ReadBufferSize: 1 << 16, WriteBufferSize: 1 << 16}
+	ws, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", wsURL, err)
+	}
+	// Screenshots and full accessibility trees are large messages.
+	ws.SetReadLimit(64 << 20)
+	c := &conn{
+		ws:      ws,
+		onEvent: onEvent,
+		pending: make(map[int64]chan cdpMessage),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *conn) readLoop() {
+	var err error
+	for {
+		var msg cdpMessage
+		if err = c.ws.ReadJSON(&msg); err != nil {
+			break
+		}
+		if msg.ID == 0 {
+			if msg.Method != "" && c.onEvent != nil {
+				go c.onEvent(msg.SessionID, msg.Method, msg.Params)
+			}
+			continue
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[msg.ID]
+		delete(c.pending, msg.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+
+	c.mu.Lock()
+	c.err = err
+	c.pending = nil
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// call sends method to the browser, or to the page session when sessionID
+// is set, and decodes the result into result unless it is nil.
+func (c *conn) call(ctx context.Context, sessionID, method string, params, result any) error {
+	id := c.nextID.Add(1)
+	ch := make(chan cdpMessage, 1)
+
+	c.mu.Lock()
+	if c.pending == nil {
+		c.mu.Unlock()
+		return errClosed
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	msg := map[string]any{"id": id, "method": method}
+	if params != nil {
+		msg["params"] = params
+	}
+	if sessionID != "" {
+		msg["sessionId"] = sessionID
+	}
+	c.writeMu.Lock()
+	err := c.ws.WriteJSON(msg)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.forget(id)
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return fmt.Errorf("%s: %w", method, resp.Error)
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("%s: decode result: %w", method, err)
+			}
+		}
+		return nil
+	case <-c.done:
+		return fmt.Errorf("%s: %w", method, errClosed)
+	case <-ctx.Done():
+		c.forget(id)
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+func (c *conn) forget(id int64) {
+	c.mu.Lock()
+	if c.pending != nil {
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+}
+
+func (c *conn) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *conn) close() error {
+	err := c.ws.Close()
+	<-c.done
+	return err
+}
@@ -0,0 +1,355 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const pollInterval = 200 * time.Millisecond
+
+// Page is a browser tab owned by one key. Callers hold Lock while running a
+// sequence of actions so actions from the same chat do not interleave.
+type Page struct {
+	sync.Mutex
+
+	browser   *Browser
+	conn      *conn
+	key       string
+	sessionID string
+	targetID  string
+	contextID string
+
+	lastUsed atomic.Int64 // UnixNano
+
+	blockedMu sync.Mutex
+	blocked   []string // URLs refused by AllowURL since the last action
+}
+
+// PageInfo describes the document loaded in a page.
+type PageInfo struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// WaitCondition is what Wait waits for. Exactly one field should be set;
+// an empty condition waits for the document to finish loading.
+type WaitCondition struct {
+	Selector string // a CSS selector matches an element
+	Text     string // the visible text contains Text
+	URL      string // the page URL contains URL
+}
+
+func (p *Page) setup(ctx context.Context) error {
+	for _, method := range []string{"Page.enable", "DOM.enable", "Accessibility.enable"} {
+		if err := p.call(ctx, method, nil, nil); err != nil {
+			return err
+		}
+	}
+	// Pause every request so AllowURL sees it before it is sent.
+	return p.call(ctx, "Fetch.enable", map[string]any{
+		"patterns": []map[string]any{{"urlPattern": "*"}},
+	}, nil)
+}
+
+func (p *Page) call(ctx context.Context, method string, params, result any) error {
+	return p.conn.call(ctx, p.sessionID, method, params, result)
+}
+
+func (p *Page) touch() { p.lastUsed.Store(time.Now().UnixNano()) }
+
+func (p *Page) idleFor() time.Duration { return time.Since(time.Unix(0, p.lastUsed.Load())) }
+
+func (p *Page) handleRequestPaused(params json.RawMessage) {
+	var ev struct {
+		RequestID string `json:"requestId"`
+		Request   struct {
+			URL string `json:"url"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if allow := p.browser.opts.AllowURL; allow != nil {
+		if err := allow(ctx, ev.Request.URL); err != nil {
+			logger.DebugCF("browser", "Request blocked", map[string]any{"url": ev.Request.URL, "reason": err.Error()})
+			p.blockedMu.Lock()
+			p.blocked = append(p.blocked, ev.Request.URL)
+			p.blockedMu.Unlock()
+			_ = p.call(ctx, "Fetch.failRequest", map[string]any{
+				"requestId":   ev.RequestID,
+				"errorReason": "BlockedByClient",
+			}, nil)
+			return
+		}
+	}
+	_ = p.call(ctx, "Fetch.continueRequest", map[string]any{"requestId": ev.RequestID}, nil)
+}
+
+// TakeBlocked returns and clears the URLs blocked since the last call.
+func (p *Page) TakeBlocked() []string {
+	p.blockedMu.Lock()
+	defer p.blockedMu.Unlock()
+	blocked := p.blocked
+	p.blocked = nil
+	return blocked
+}
+
+// Navigate loads rawURL and waits for the document to finish loading.
+func (p *Page) Navigate(ctx context.Context, rawURL string) (PageInfo, error) {
+	p.touch()
+	var nav struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := p.call(ctx, "Page.navigate", map[string]any{"url": rawURL}, &nav); err != nil {
+		return PageInfo{}, err
+	}
+	if nav.ErrorText != "" {
+		return PageInfo{}, fmt.Errorf("navigation failed: %s", nav.ErrorText)
+	}
+	if err := p.Wait(ctx, WaitCondition{}); err != nil {
+		return PageInfo{}, err
+	}
+	return p.Info(ctx)
+}
+
+// Info returns the URL and title of the current document.
+func (p *Page) Info(ctx context.Context) (PageInfo, error) {
+	var info PageInfo
+	err := p.evaluate(ctx, `({url: location.href, title: document.title})`, &info)
+	return info, err
+}
+
+// HTML returns the serialized current document.
+func (p *Page) HTML(ctx context.Context) (string, error) {
+	p.touch()
+	var html string
+	err := p.evaluate(ctx, `document.documentElement ? document.documentElement.outerHTML : ""`, &html)
+	return html, err
+}
+
+// Wait polls until cond holds or ctx is done.
+func (p *Page) Wait(ctx context.Context, cond WaitCondition) error {
+	p.touch()
+	var expr string
+	switch {
+	case cond.Selector != "":
+		expr = fmt.Sprintf(`!!document.querySelector(%s)`, jsString(cond.Selector))
+	case cond.Text != "":
+		expr = fmt.Sprintf(`!!document.body && document.body.innerText.includes(%s)`, jsString(cond.Text))
+	case cond.URL != "":
+		expr = fmt.Sprintf(`location.href.includes(%s)`, jsString(cond.URL))
+	default:
+		expr = `document.readyState === "complete"`
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		var ok bool
+		err := p.evaluate(ctx, expr, &ok)
+		if err == nil && ok {
+			return nil
+		}
+		// A navigation in progress destroys the execution context; keep
+		// polling until the deadline.
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("wait: %w (last error: %v)", ctx.Err(), err)
+			}
+			return fmt.Errorf("wait: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Click clicks the middle of the element with the given snapshot ref.
+func (p *Page) Click(ctx context.Context, ref string) error {
+	p.touch()
+	nodeID, err := parseRef(ref)
+	if err != nil {
+		return err
+	}
+	_ = p.call(ctx, "DOM.scrollIntoViewIfNeeded", map[string]any{"backendNodeId": nodeID}, nil)
+
+	var quads struct {
+		Quads [][]float64 `json:"quads"`
+	}
+	if err := p.call(ctx, "DOM.getContentQuads", map[string]any{"backendNodeId": nodeID}, &quads); err != nil {
+		return fmt.Errorf("element %s is gone, take a new snapshot: %w", ref, err)
+	}
+	if len(quads.Quads) == 0 || len(quads.Quads[0]) < 8 {
+		return fmt.Errorf("element %s is not visible", ref)
+	}
+	q := quads.Quads[0]
+	x := (q[0] + q[2] + q[4] + q[6]) / 4
+	y := (q[1] + q[3] + q[5] + q[7]) / 4
+
+	for _, ev := range []map[string]any{
+		{"type": "mouseMoved", "x": x, "y": y},
+		{"type": "mousePressed", "x": x, "y": y, "button": "left", "clickCount": 1},
+		{"type": "mouseReleased", "x": x, "y": y, "button": "left", "clickCount": 1},
+	} {
+		if err := p.call(ctx, "Input.dispatchMouseEvent", ev, nil); err != nil {
+			return err
+		}
+	}
+	p.settle(ctx)
+	return nil
+}
+
+// Type replaces the value of the element with the given snapshot ref with
+// text, and presses Enter afterwards when submit is set.
+func (p *Page) Type(ctx context.Context, ref, text string, submit bool) error {
+	p.touch()
+	nodeID, err := parseRef(ref)
+	if err != nil {
+		return err
+	}
+	if err := p.call(ctx, "DOM.focus", map[string]any{"backendNodeId": nodeID}, nil); err != nil {
+		return fmt.Errorf("element %s cannot be focused, take a new snapshot: %w", ref, err)
+	}
+
+	var resolved struct {
+		Object struct {
+			ObjectID string `json:"objectId"`
+		} `json:"object"`
+	}
+	if err := p.call(ctx, "DOM.resolveNode", map[string]any{"backendNodeId": nodeID}, &resolved); err == nil &&
+		resolved.Object.ObjectID != "" {
+		_ = p.call(ctx, "Runtime.callFunctionOn", map[string]any{
+			"objectId": resolved.Object.ObjectID,
+			"functionDeclaration": `function() {
+				if ("value" in this) { this.value = ""; this.dispatchEvent(new Event("input", {bubbles: true})); }
+				else if (this.isContentEditable) { this.textContent = ""; }
+			}`,
+		}, nil)
+	}
+
+	if err := p.call(ctx, "Input.insertText", map[string]any{"text": text}, nil); err != nil {
+		return err
+	}
+	if submit {
+		for _, typ := range []string{"keyDown", "keyUp"} {
+			ev := map[string]any{
+				"type": typ, "key": "Enter", "code": "Enter", "windowsVirtualKeyCode": 13,
+			}
+			if typ == "keyDown" {
+				ev["text"] = "\r"
+			}
+			if err := p.call(ctx, "Input.dispatchKeyEvent", ev, nil); err != nil {
+				return err
+			}
+		}
+		p.settle(ctx)
+	}
+	return nil
+}
+
+// Screenshot captures the viewport, or the whole page when fullPage is set,
+// as PNG.
+func (p *Page) Screenshot(ctx context.Context, fullPage bool) ([]byte, error) {
+	p.touch()
+	params := map[string]any{"format": "png"}
+	if fullPage {
+		var metrics struct {
+			CSSContentSize struct {
+				Width  float64 `json:"width"`
+				Height float64 `json:"height"`
+			} `json:"cssContentSize"`
+		}
+		if err := p.call(ctx, "Page.getLayoutMetrics", nil, &metrics); err != nil {
+			return nil, err
+		}
+		if metrics.CSSContentSize.Width > 0 && metrics.CSSContentSize.Height > 0 {
+			params["captureBeyondViewport"] = true
+			params["clip"] = map[string]any{
+				"x": 0, "y": 0, "scale": 1,
+				"width":  metrics.CSSContentSize.Width,
+				"height": metrics.CSSContentSize.Height,
+			}
+		}
+	}
+	var shot struct {
+		Data string `json:"data"`
+	}
+	if err := p.call(ctx, "Page.captureScreenshot", params, &shot); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(shot.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode screenshot: %w", err)
+	}
+	return data, nil
+}
+
+// settle gives a click or submit a moment to start a navigation and waits
+// for it to load. Pages that do not navigate return after the first poll.
+func (p *Page) settle(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(pollInterval):
+	}
+	_ = p.Wait(ctx, WaitCondition{})
+}
+
+func (p *Page) evaluate(ctx context.Context, expr string, result any) error {
+	var resp struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	if err := p.call(ctx, "Runtime.evaluate", map[string]any{
+		"expression":    expr,
+		"returnByValue": true,
+		"awaitPromise":  true,
+	}, &resp); err != nil {
+		return err
+	}
+	if ex := resp.ExceptionDetails; ex != nil {
+		if ex.Exception.Description != "" {
+			return errors.New(ex.Exception.Description)
+		}
+		return errors.New(ex.Text)
+	}
+	if result == nil || len(resp.Result.Value) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Result.Value, result)
+}
+
+// parseRef turns a snapshot ref ("e42") into a backend DOM node ID.
+func parseRef(ref string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(ref), "e"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid element ref %q, use a ref from the snapshot such as e12", ref)
+	}
+	return id, nil
+}
+
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,166 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// interactiveRoles get a ref in snapshots so they can be clicked or typed into.
+var interactiveRoles = map[string]bool{
+	"button":           true,
+	"checkbox":         true,
+	"combobox":         true,
+	"link":             true,
+	"listbox":          true,
+	"menuitem":         true,
+	"menuitemcheckbox": true,
+	"menuitemradio":    true,
+	"option":           true,
+	"radio":            true,
+	"searchbox":        true,
+	"slider":           true,
+	"spinbutton":       true,
+	"switch":           true,
+	"tab":              true,
+	"textbox":          true,
+	"treeitem":         true,
+}
+
+// transparentRoles are left out of snapshots; their children are shown in
+// their place.
+var transparentRoles = map[string]bool{
+	"":              true,
+	"generic":       true,
+	"none":          true,
+	"presentation":  true,
+	"InlineTextBox": true,
+	"LineBreak":     true,
+}
+
+type axValue struct {
+	Value any `json:"value"`
+}
+
+func (v *axValue) String() string {
+	if v == nil || v.Value == nil {
+		return ""
+	}
+	if s, ok := v.Value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v.Value)
+}
+
+type axNode struct {
+	NodeID           string    `json:"nodeId"`
+	Ignored          bool      `json:"ignored"`
+	Role             *axValue  `json:"role"`
+	Name             *axValue  `json:"name"`
+	Value            *axValue  `json:"value"`
+	ChildIDs         []string  `json:"childIds"`
+	ParentID         string    `json:"parentId"`
+	BackendDOMNodeID int64     `json:"backendDOMNodeId"`
+	Properties       []axProp  `json:"properties"`
+	children         []*axNode `json:"-"`
+}
+
+type axProp struct {
+	Name  string   `json:"name"`
+	Value *axValue `json:"value"`
+}
+
+// Snapshot renders the accessibility tree of the page as an indented
+// outline. Interactive elements carry a ref ("e42") for Click and Type.
+func (p *Page) Snapshot(ctx context.Context) (string, error) {
+	p.touch()
+	var tree struct {
+		Nodes []*axNode `json:"nodes"`
+	}
+	if err := p.call(ctx, "Accessibility.getFullAXTree", nil, &tree); err != nil {
+		return "", err
+	}
+	return renderAXTree(tree.Nodes), nil
+}
+
+func renderAXTree(nodes []*axNode) string {
+	byID := make(map[string]*axNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.NodeID] = n
+	}
+	var roots []*axNode
+	for _, n := range nodes {
+		for _, id := range n.ChildIDs {
+			if child, ok := byID[id]; ok {
+				n.children = append(n.children, child)
+			}
+		}
+		if _, ok := byID[n.ParentID]; n.ParentID == "" || !ok {
+			roots = append(roots, n)
+		}
+	}
+
+	var b strings.Builder
+	for _, root := range roots {
+		writeAXNode(&b, root, 0)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeAXNode(b *strings.Builder, n *axNode, depth int) {
+	role := n.Role.String()
+	name := strings.TrimSpace(n.Name.String())
+
+	if n.Ignored || transparentRoles[role] || (role == "StaticText" && name == "") {
+		for _, child := range n.children {
+			writeAXNode(b, child, depth)
+		}
+		return
+	}
+	// Text nodes only have inline text boxes below them.
+	if role == "StaticText" {
+		b.WriteString(strings.Repeat("  ", depth))
+		fmt.Fprintf(b, "- text %q\n", name)
+		return
+	}
+	if role == "RootWebArea" {
+		role = "document"
+	}
+
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("- ")
+	b.WriteString(role)
+	if name != "" {
+		fmt.Fprintf(b, " %q", name)
+	}
+	if value := n.Value.String(); value != "" && value != name {
+		fmt.Fprintf(b, " value=%q", value)
+	}
+	for _, prop := range n.Properties {
+		switch prop.Name {
+		case "checked", "selected", "expanded", "disabled", "required":
+			if v := prop.Value.String(); v != "" && v != "false" {
+				fmt.Fprintf(b, " %s", prop.Name)
+				if v != "true" {
+					fmt.Fprintf(b, "=%s", v)
+				}
+			}
+		}
+	}
+	if interactiveRoles[role] && n.BackendDOMNodeID > 0 {
+		fmt.Fprintf(b, " [ref=e%d]", n.BackendDOMNodeID)
+	}
+	b.WriteByte('\n')
+
+	// Children of a named interactive element usually just spell out its
+	// name again.
+	if interactiveRoles[role] && name != "" {
+		return
+	}
+	for _, child := range n.children {
+		if child.Role.String() == "StaticText" && strings.TrimSpace(child.Name.String()) == name {
+			continue
+		}
+		writeAXNode(b, child, depth+1)
+	}
+}
@@ -30,6 +30,10 @@ type Streamer interface {
 	Cancel(ctx context.Context)
 }
 
+// InboundInterceptor sees inbound messages before the agent loop does and
+// returns true to consume one.
+type InboundInterceptor func(msg InboundMessage) bool
+
 type MessageBus struct {
 	inbound       chan InboundMessage
 	outbound      chan OutboundMessage
@@ -42,6 +46,10 @@ type MessageBus struct {
 	closed         atomic.Bool
 	wg             sync.WaitGroup
 	streamDelegate atomic.Value // stores StreamDelegate
+
+	interceptMu     sync.RWMutex
+	interceptors    map[uint64]InboundInterceptor
+	nextInterceptor uint64
 }
 
 func NewMessageBus() *MessageBus {
@@ -84,9 +92,42 @@ func publish[T any](ctx context.Context, mb *MessageBus, ch chan T, msg T) error
 }
 
 func (mb *MessageBus) PublishInbound(ctx context.Context, msg InboundMessage) error {
+	if mb.intercept(msg) {
+		return nil
+	}
 	return publish(ctx, mb, mb.inbound, msg)
 }
 
+// AddInboundInterceptor registers f to see every inbound message published
+// from now on, until the returned remove function is called. f runs on the
+// publisher's goroutine and must not block.
+func (mb *MessageBus) AddInboundInterceptor(f InboundInterceptor) (remove func()) {
+	mb.interceptMu.Lock()
+	defer mb.interceptMu.Unlock()
+	if mb.interceptors == nil {
+		mb.interceptors = make(map[uint64]InboundInterceptor)
+	}
+	mb.nextInterceptor++
+	id := mb.nextInterceptor
+	mb.interceptors[id] = f
+	return func() {
+		mb.interceptMu.Lock()
+		defer mb.interceptMu.Unlock()
+		delete(mb.interceptors, id)
+	}
+}
+
+func (mb *MessageBus) intercept(msg InboundMessage) bool {
+	mb.interceptMu.RLock()
+	defer mb.interceptMu.RUnlock()
+	for _, f := range mb.interceptors {
+		if f(msg) {
+			return true
+		}
+	}
+	return false
+}
+
 func (mb *MessageBus) InboundChan() <-chan InboundMessage {
 	return mb.inbound
 }
@@ -136,6 +177,17 @@ func (mb *MessageBus) GetStreamer(ctx context.Context, channel, chatID string) (
 	return nil, false
 }
 
+// QueueDepths returns the number of messages waiting in each bus queue.
+func (mb *MessageBus) QueueDepths() map[string]int {
+	return map[string]int{
+		"inbound":        len(mb.inbound),
+		"outbound":       len(mb.outbound),
+		"outbound_media": len(mb.outboundMedia),
+		"audio_chunks":   len(mb.audioChunks),
+		"voice_controls": len(mb.voiceControls),
+	}
+}
+
 func (mb *MessageBus) Close() {
 	mb.closeOnce.Do(func() {
 		// notify all blocked publishers to exit
@@ -245,3 +245,50 @@ func TestCloseIdempotent(t *testing.T) {
 		t.Fatalf("expected ErrBusClosed after multiple closes, got %v", err)
 	}
 }
+
+func TestInboundInterceptor(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	ctx := context.Background()
+
+	var claimed []InboundMessage
+	remove := mb.AddInboundInterceptor(func(msg InboundMessage) bool {
+		if msg.PromptID != "p1" {
+			return false
+		}
+		claimed = append(claimed, msg)
+		return true
+	})
+
+	if err := mb.PublishInbound(ctx, InboundMessage{ChatID: "c", PromptID: "p1", ActionID: "yes"}); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	if err := mb.PublishInbound(ctx, InboundMessage{ChatID: "c", Content: "hello"}); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ActionID != "yes" {
+		t.Fatalf("interceptor claimed %+v, want the prompt reply only", claimed)
+	}
+	if got := <-mb.InboundChan(); got.Content != "hello" {
+		t.Fatalf("expected the unclaimed message on the inbound channel, got %+v", got)
+	}
+
+	remove()
+	if err := mb.PublishInbound(ctx, InboundMessage{ChatID: "c", PromptID: "p1"}); err != nil {
+		t.Fatalf("PublishInbound failed: %v", err)
+	}
+	if got := <-mb.InboundChan(); got.PromptID != "p1" {
+		t.Fatalf("expected the message to pass through after remove, got %+v", got)
+	}
+}
+
+func TestInteractiveMessage_MatchReply(t *testing.T) {
+	m := InteractiveMessage{Actions: []Action{{ID: "1", Label: "Yes"}, {ID: "2", Label: "No"}}}
+	tests := map[string]string{"2": "2", " 1. ": "1", "no": "2", "YES": "1", "3": "", "maybe": ""}
+	for reply, want := range tests {
+		a, ok := m.MatchReply(reply)
+		if ok != (want != "") || a.ID != want {
+			t.Errorf("MatchReply(%q) = %+v, %v; want %q", reply, a, ok, want)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package bus
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Peer identifies the routing peer for a message (direct, group, channel, etc.)
 type Peer struct {
 	Kind string `json:"kind"` // "direct" | "group" | "channel" | ""
@@ -24,9 +30,14 @@ type InboundMessage struct {
 	Media      []string          `json:"media,omitempty"`
 	Peer       Peer              `json:"peer"`                  // routing peer
 	MessageID  string            `json:"message_id,omitempty"`  // platform message ID
+	ThreadID   string            `json:"thread_id,omitempty"`   // thread or forum topic within ChatID
 	MediaScope string            `json:"media_scope,omitempty"` // media lifecycle scope
 	SessionKey string            `json:"session_key"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	// PromptID and ActionID are set when the message is a choice made on an
+	// interactive message rather than typed text.
+	PromptID string `json:"prompt_id,omitempty"`
+	ActionID string `json:"action_id,omitempty"`
 }
 
 type OutboundMessage struct {
@@ -34,9 +45,59 @@ type OutboundMessage struct {
 	ChatID           string            `json:"chat_id"`
 	Content          string            `json:"content"`
 	ReplyToMessageID string            `json:"reply_to_message_id,omitempty"`
+	ThreadID         string            `json:"thread_id,omitempty"` // thread to post in, unless ChatID names one
 	Metadata         map[string]string `json:"metadata,omitempty"`
 }
 
+// Action is one choice offered by an interactive message, rendered as a
+// button where the channel supports it.
+type Action struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// InteractiveMessage is a message with a set of actions to choose from.
+// The choice comes back as an InboundMessage carrying PromptID and the
+// chosen action's ID.
+type InteractiveMessage struct {
+	Channel  string   `json:"channel"`
+	ChatID   string   `json:"chat_id"`
+	Content  string   `json:"content"`
+	PromptID string   `json:"prompt_id"`
+	Actions  []Action `json:"actions"`
+}
+
+// FallbackText renders the message for channels without buttons, as the
+// content followed by a numbered list of the actions.
+func (m InteractiveMessage) FallbackText() string {
+	var b strings.Builder
+	b.WriteString(m.Content)
+	b.WriteString("\n")
+	for i, a := range m.Actions {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, a.Label)
+	}
+	b.WriteString("\n\nReply with the number of your choice.")
+	return b.String()
+}
+
+// MatchReply maps a typed reply to one of the actions: either its number
+// in FallbackText or its label, ignoring case.
+func (m InteractiveMessage) MatchReply(text string) (Action, bool) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "."))
+	if n, err := strconv.Atoi(text); err == nil {
+		if n >= 1 && n <= len(m.Actions) {
+			return m.Actions[n-1], true
+		}
+		return Action{}, false
+	}
+	for _, a := range m.Actions {
+		if strings.EqualFold(text, a.Label) {
+			return a, true
+		}
+	}
+	return Action{}, false
+}
+
 // MediaPart describes a single media attachment to send.
 type MediaPart struct {
 	Type        string `json:"type"`                   // "image" | "audio" | "video" | "file"
@@ -59,6 +120,7 @@ type AudioChunk struct {
 	SpeakerID  string `json:"speaker_id"` // User ID or SSRC
 	ChatID     string `json:"chat_id"`    // Where to respond
 	Channel    string `json:"channel"`    // Source channel type (e.g. "discord")
+	PeerKind   string `json:"peer_kind"`  // Peer kind of the resulting message; defaults to "channel"
 	Sequence   uint64 `json:"sequence"`
 	Timestamp  uint32 `json:"timestamp"`
 	SampleRate int    `json:"sample_rate"`
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/commands"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -93,6 +94,7 @@ type BaseChannel struct {
 	mediaStore          media.MediaStore
 	placeholderRecorder PlaceholderRecorder
 	owner               Channel // the concrete channel that embeds this BaseChannel
+	identityLinker      IdentityLinker
 	reasoningChannelID  string
 }
 
@@ -227,10 +229,32 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 	return false
 }
 
-// IsAllowedSender checks whether a structured SenderInfo is permitted by the allow-list.
+// IsAllowedSender checks whether a structured SenderInfo is permitted by the allow-list,
+// directly or through an identity linked to it with /link.
+func (c *BaseChannel) IsAllowedSender(sender bus.SenderInfo) bool {
+	return c.allowedSender(sender)
+}
+
+// IsAllowedMessage is IsAllowedSender for a message whose text is known. It
+// also lets "/link <code>" through from unlisted senders while a code is
+// pending, so that a code can be redeemed on a channel that does not list
+// the account yet.
+func (c *BaseChannel) IsAllowedMessage(sender bus.SenderInfo, content string) bool {
+	return c.allowedSender(sender) || c.redeemsLinkCode(sender, content)
+}
+
+// allowedSender checks the allow-list and the identities linked to sender.
+func (c *BaseChannel) allowedSender(sender bus.SenderInfo) bool {
+	if c.allowListed(sender) {
+		return true
+	}
+	return c.identityLinker != nil && c.identityLinker.LinkedAllowed(sender)
+}
+
+// allowListed checks sender against this channel's allow-list only.
 // It delegates to identity.MatchAllowed for each entry, providing unified matching
 // across all legacy formats and the new canonical "platform:id" format.
-func (c *BaseChannel) IsAllowedSender(sender bus.SenderInfo) bool {
+func (c *BaseChannel) allowListed(sender bus.SenderInfo) bool {
 	if len(c.allowList) == 0 {
 		return true
 	}
@@ -258,7 +282,7 @@ func (c *BaseChannel) HandleMessage(
 		sender = senderOpts[0]
 	}
 	if sender.CanonicalID != "" || sender.PlatformID != "" {
-		if !c.IsAllowedSender(sender) {
+		if !c.IsAllowedMessage(sender, content) {
 			return
 		}
 	} else {
@@ -284,6 +308,7 @@ func (c *BaseChannel) HandleMessage(
 		Media:      media,
 		Peer:       peer,
 		MessageID:  messageID,
+		ThreadID:   metadata["thread_id"],
 		MediaScope: scope,
 		Metadata:   metadata,
 	}
@@ -349,6 +374,31 @@ func (c *BaseChannel) GetPlaceholderRecorder() PlaceholderRecorder {
 	return c.placeholderRecorder
 }
 
+// listsSender reports whether an allow-list entry other than "*" names sender.
+func (c *BaseChannel) listsSender(sender bus.SenderInfo) bool {
+	for _, allowed := range c.allowList {
+		if allowed != "*" && identity.MatchAllowed(sender, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetIdentityLinker injects the IdentityLinker that extends the allow-list
+// to linked identities.
+func (c *BaseChannel) SetIdentityLinker(l IdentityLinker) {
+	c.identityLinker = l
+}
+
+// redeemsLinkCode reports whether content redeems a pending /link code.
+func (c *BaseChannel) redeemsLinkCode(sender bus.SenderInfo, content string) bool {
+	if sender.CanonicalID == "" || c.identityLinker == nil || !c.identityLinker.LinkPending() {
+		return false
+	}
+	_, ok := commands.LinkCode(content)
+	return ok
+}
+
 // SetOwner injects the concrete channel that embeds this BaseChannel.
 // This allows HandleMessage to auto-trigger TypingCapable / ReactionCapable / PlaceholderCapable.
 func (c *BaseChannel) SetOwner(ch Channel) {
@@ -224,7 +224,7 @@ func (c *DingTalkChannel) onChatBotMessageReceived(
 		DisplayName: senderNick,
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		return nil, nil
 	}
 
@@ -103,6 +103,7 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 	c.botUserID = botUser.ID
 
 	c.session.AddHandler(c.handleMessage)
+	c.session.AddHandler(c.handleInteraction)
 
 	go c.listenVoiceControl(c.ctx)
 
@@ -153,6 +154,10 @@ func (c *DiscordChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]s
 	if channelID == "" {
 		return nil, fmt.Errorf("channel ID is empty")
 	}
+	// Threads are channels; posting in one only needs its ID.
+	if msg.ThreadID != "" {
+		channelID = msg.ThreadID
+	}
 
 	if len([]rune(msg.Content)) == 0 {
 		return nil, nil
@@ -387,7 +392,7 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 	}
 	sender.DisplayName = displayName
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, m.Content) {
 		logger.DebugCF("discord", "Message rejected by allowlist", map[string]any{
 			"user_id": m.Author.ID,
 		})
@@ -499,11 +504,17 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 		"preview":     utils.Truncate(content, 50),
 	})
 
+	// A thread is a channel of its own: it stays the peer, so its session
+	// key and peer bindings are unchanged, and the parent channel is
+	// reported as the parent peer so bindings of the channel cover it.
 	peerKind := "channel"
 	peerID := m.ChannelID
+	parentID := ""
 	if m.GuildID == "" {
 		peerKind = "direct"
 		peerID = senderID
+	} else {
+		parentID = threadParent(s, m.ChannelID)
 	}
 
 	peer := bus.Peer{Kind: peerKind, ID: peerID}
@@ -516,6 +527,11 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 		"channel_id":   m.ChannelID,
 		"is_dm":        fmt.Sprintf("%t", m.GuildID == ""),
 	}
+	if parentID != "" {
+		metadata["thread_id"] = m.ChannelID
+		metadata["parent_peer_kind"] = "channel"
+		metadata["parent_peer_id"] = parentID
+	}
 
 	c.HandleMessage(c.ctx, peer, m.ID, senderID, m.ChannelID, content, mediaPaths, metadata, sender)
 }
@@ -1,6 +1,7 @@
 package discord
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"testing"
@@ -89,3 +90,44 @@ func TestApplyDiscordProxy_InvalidProxyURL(t *testing.T) {
 		t.Fatal("applyDiscordProxy() expected error for invalid proxy URL, got nil")
 	}
 }
+
+func TestButtonLabel(t *testing.T) {
+	var msg discordgo.Message
+	raw := `{"content":"Deploy now?","components":[{"type":1,"components":[
+		{"type":2,"label":"Yes","style":1,"custom_id":"pc:ab12:1"},
+		{"type":2,"label":"No","style":1,"custom_id":"pc:ab12:2"}]}]}`
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		t.Fatalf("unmarshal message: %v", err)
+	}
+	if got := buttonLabel(&msg, "pc:ab12:2"); got != "No" {
+		t.Fatalf("buttonLabel() = %q, want %q", got, "No")
+	}
+	if got := buttonLabel(&msg, "pc:other:1"); got != "" {
+		t.Fatalf("buttonLabel() = %q for an unknown button, want empty", got)
+	}
+}
+
+func TestThreadParent(t *testing.T) {
+	session, err := discordgo.New("Bot test-token")
+	if err != nil {
+		t.Fatalf("discordgo.New() error: %v", err)
+	}
+	if err = session.State.GuildAdd(&discordgo.Guild{ID: "g1"}); err != nil {
+		t.Fatalf("GuildAdd() error: %v", err)
+	}
+	for _, ch := range []*discordgo.Channel{
+		{ID: "c1", GuildID: "g1", Type: discordgo.ChannelTypeGuildText},
+		{ID: "t1", GuildID: "g1", ParentID: "c1", Type: discordgo.ChannelTypeGuildPublicThread},
+	} {
+		if err = session.State.ChannelAdd(ch); err != nil {
+			t.Fatalf("ChannelAdd(%s) error: %v", ch.ID, err)
+		}
+	}
+
+	if got := threadParent(session, "t1"); got != "c1" {
+		t.Errorf("threadParent(thread) = %q, want c1", got)
+	}
+	if got := threadParent(session, "c1"); got != "" {
+		t.Errorf("threadParent(channel) = %q, want empty", got)
+	}
+}
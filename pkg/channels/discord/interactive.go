@@ -0,0 +1,128 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxButtonsPerRow is Discord's limit for buttons in one action row.
+const maxButtonsPerRow = 5
+
+// SendInteractive implements channels.InteractiveCapable with message
+// component buttons.
+func (c *DiscordChannel) SendInteractive(ctx context.Context, msg bus.InteractiveMessage) (string, error) {
+	if !c.IsRunning() {
+		return "", channels.ErrNotRunning
+	}
+
+	var rows []discordgo.MessageComponent
+	var row discordgo.ActionsRow
+	for _, a := range msg.Actions {
+		if len(row.Components) == maxButtonsPerRow {
+			rows = append(rows, row)
+			row = discordgo.ActionsRow{}
+		}
+		row.Components = append(row.Components, discordgo.Button{
+			Label:    a.Label,
+			Style:    discordgo.PrimaryButton,
+			CustomID: channels.EncodeActionData(msg.PromptID, a.ID),
+		})
+	}
+	if len(row.Components) > 0 {
+		rows = append(rows, row)
+	}
+
+	sent, err := c.session.ChannelMessageSendComplex(msg.ChatID, &discordgo.MessageSend{
+		Content:    msg.Content,
+		Components: rows,
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("discord send: %w", channels.ErrTemporary)
+	}
+	return sent.ID, nil
+}
+
+// handleInteraction turns a press on a SendInteractive button into an
+// inbound message and removes the buttons so the choice cannot be made
+// twice.
+func (c *DiscordChannel) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i == nil || i.Interaction == nil || i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		return
+	}
+
+	data := i.MessageComponentData().CustomID
+	sender := bus.SenderInfo{
+		Platform:    "discord",
+		PlatformID:  user.ID,
+		CanonicalID: identity.BuildCanonicalID("discord", user.ID),
+		Username:    user.Username,
+		DisplayName: user.Username,
+	}
+	peer := bus.Peer{Kind: "channel", ID: i.ChannelID}
+	if i.GuildID == "" {
+		peer = bus.Peer{Kind: "direct", ID: user.ID}
+	}
+
+	if !c.HandleAction(c.ctx, peer, user.ID, i.ChannelID, data, buttonLabel(i.Message, data), sender) {
+		return
+	}
+
+	resp := &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}
+	if i.Message != nil {
+		resp = &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    i.Message.Content,
+				Components: []discordgo.MessageComponent{},
+			},
+		}
+	}
+	if err := s.InteractionRespond(i.Interaction, resp); err != nil {
+		logger.DebugCF("discord", "Failed to acknowledge button press", map[string]any{
+			"error": err.Error(),
+		})
+	}
+}
+
+// buttonLabel finds the label of the button with customID on msg.
+func buttonLabel(msg *discordgo.Message, customID string) string {
+	if msg == nil {
+		return ""
+	}
+	for _, component := range msg.Components {
+		var buttons []discordgo.MessageComponent
+		switch row := component.(type) {
+		case *discordgo.ActionsRow:
+			buttons = row.Components
+		case discordgo.ActionsRow:
+			buttons = row.Components
+		}
+		for _, b := range buttons {
+			switch button := b.(type) {
+			case *discordgo.Button:
+				if button.CustomID == customID {
+					return button.Label
+				}
+			case discordgo.Button:
+				if button.CustomID == customID {
+					return button.Label
+				}
+			}
+		}
+	}
+	return ""
+}
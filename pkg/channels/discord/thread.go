@@ -0,0 +1,64 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+const (
+	// maxThreadNameLength is Discord's limit for thread names.
+	maxThreadNameLength = 100
+	// threadArchiveMinutes archives threads started by the bot after a day
+	// without activity.
+	threadArchiveMinutes = 1440
+)
+
+// threadParent returns the parent channel of channelID when it is a
+// thread, and "" otherwise.
+func threadParent(s *discordgo.Session, channelID string) string {
+	ch, err := s.State.Channel(channelID)
+	if err != nil {
+		if ch, err = s.Channel(channelID); err != nil {
+			return ""
+		}
+		_ = s.State.ChannelAdd(ch)
+	}
+	if !ch.IsThread() {
+		return ""
+	}
+	return ch.ParentID
+}
+
+// StartThread implements channels.ThreadStarter. A thread started from a
+// message is a channel of its own, so its ID is both the thread ID and the
+// chat ID of replies posted in it.
+func (c *DiscordChannel) StartThread(ctx context.Context, chatID, messageID, title string) (string, error) {
+	if !c.IsRunning() {
+		return "", channels.ErrNotRunning
+	}
+	if threadParent(c.session, chatID) != "" {
+		return chatID, nil
+	}
+	name := []rune(title)
+	if len(name) == 0 {
+		name = []rune("Reply")
+	}
+	if len(name) > maxThreadNameLength {
+		name = name[:maxThreadNameLength]
+	}
+	thread, err := c.session.MessageThreadStart(chatID, messageID, string(name), threadArchiveMinutes,
+		discordgo.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("discord start thread: %w", channels.ErrTemporary)
+	}
+	return thread.ID, nil
+}
+
+// DeleteMessage implements channels.MessageDeleter.
+func (c *DiscordChannel) DeleteMessage(ctx context.Context, chatID string, messageID string) error {
+	return c.session.ChannelMessageDelete(chatID, messageID, discordgo.WithContext(ctx))
+}
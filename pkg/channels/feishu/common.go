@@ -7,6 +7,7 @@ import (
 
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 
+	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 )
 
@@ -42,6 +43,40 @@ func buildMarkdownCard(content string) (string, error) {
 	return string(data), nil
 }
 
+// buildActionCard builds a markdown card like buildMarkdownCard followed by
+// one callback button per action. Each button's value carries the
+// EncodeActionData payload and the label under "action" and "label".
+func buildActionCard(content, promptID string, actions []bus.Action) (string, error) {
+	elements := []map[string]any{{"tag": "markdown", "content": content}}
+	for i, a := range actions {
+		style := "default"
+		if i == 0 {
+			style = "primary"
+		}
+		elements = append(elements, map[string]any{
+			"tag":  "button",
+			"type": style,
+			"text": map[string]any{"tag": "plain_text", "content": a.Label},
+			"behaviors": []map[string]any{{
+				"type": "callback",
+				"value": map[string]string{
+					"action": channels.EncodeActionData(promptID, a.ID),
+					"label":  a.Label,
+				},
+			}},
+		})
+	}
+	card := map[string]any{
+		"schema": "2.0",
+		"body":   map[string]any{"elements": elements},
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // extractJSONStringField unmarshals content as JSON and returns the value of the given string field.
 // Returns "" if the content is invalid JSON or the field is missing/empty.
 func extractJSONStringField(content, field string) string {
@@ -43,6 +43,7 @@ type FeishuChannel struct {
 	tokenCache *tokenCache // custom cache that supports invalidation
 
 	botOpenID atomic.Value // stores string; populated lazily for @mention detection
+	prompts   sync.Map     // prompt ID → question of cards sent by SendInteractive
 
 	mu     sync.Mutex
 	cancel context.CancelFunc
@@ -82,7 +83,8 @@ func (c *FeishuChannel) Start(ctx context.Context) error {
 	}
 
 	dispatcher := larkdispatcher.NewEventDispatcher(c.config.VerificationToken.String(), c.config.EncryptKey.String()).
-		OnP2MessageReceiveV1(c.handleMessageReceive)
+		OnP2MessageReceiveV1(c.handleMessageReceive).
+		OnP2CardActionTrigger(c.handleCardAction)
 
 	runCtx, cancel := context.WithCancel(ctx)
 
@@ -411,12 +413,11 @@ func (c *FeishuChannel) handleMessageReceive(ctx context.Context, event *larkim.
 		PlatformID:  senderID,
 		CanonicalID: identity.BuildCanonicalID("feishu", senderID),
 	}
-	if !c.IsAllowedSender(senderInfo) {
-		return nil
-	}
-
 	// Extract content based on message type
 	content := extractContent(messageType, rawContent)
+	if !c.IsAllowedMessage(senderInfo, content) {
+		return nil
+	}
 
 	// Handle media messages (download and store)
 	var mediaRefs []string
@@ -0,0 +1,105 @@
+//go:build amd64 || arm64 || riscv64 || mips64 || ppc64
+
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+)
+
+// SendInteractive implements channels.InteractiveCapable with a card that
+// has one callback button per action.
+func (c *FeishuChannel) SendInteractive(ctx context.Context, msg bus.InteractiveMessage) (string, error) {
+	if !c.IsRunning() {
+		return "", channels.ErrNotRunning
+	}
+	cardContent, err := buildActionCard(msg.Content, msg.PromptID, msg.Actions)
+	if err != nil {
+		return "", fmt.Errorf("feishu interactive: card build failed: %w", err)
+	}
+
+	req := larkim.NewCreateMessageReqBuilder().
+		ReceiveIdType(larkim.ReceiveIdTypeChatId).
+		Body(larkim.NewCreateMessageReqBodyBuilder().
+			ReceiveId(msg.ChatID).
+			MsgType(larkim.MsgTypeInteractive).
+			Content(cardContent).
+			Build()).
+		Build()
+	resp, err := c.client.Im.V1.Message.Create(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("feishu send: %w", channels.ErrTemporary)
+	}
+	if !resp.Success() {
+		c.invalidateTokenOnAuthError(resp.Code)
+		return "", fmt.Errorf("feishu interactive api error (code=%d msg=%s)", resp.Code, resp.Msg)
+	}
+
+	c.prompts.Store(msg.PromptID, msg.Content)
+	if resp.Data != nil && resp.Data.MessageId != nil {
+		return *resp.Data.MessageId, nil
+	}
+	return "", nil
+}
+
+// handleCardAction turns a press on a SendInteractive button into an
+// inbound message. The card is replaced by the question and the choice so
+// it cannot be made twice.
+func (c *FeishuChannel) handleCardAction(
+	ctx context.Context,
+	event *callback.CardActionTriggerEvent,
+) (*callback.CardActionTriggerResponse, error) {
+	if event == nil || event.Event == nil || event.Event.Action == nil || event.Event.Context == nil {
+		return nil, nil
+	}
+	data, _ := event.Event.Action.Value["action"].(string)
+	label, _ := event.Event.Action.Value["label"].(string)
+	chatID := event.Event.Context.OpenChatID
+
+	senderID := ""
+	if op := event.Event.Operator; op != nil {
+		senderID = op.OpenID
+		if op.UserID != nil && *op.UserID != "" {
+			senderID = *op.UserID
+		}
+	}
+	if senderID == "" || chatID == "" {
+		return nil, nil
+	}
+	sender := bus.SenderInfo{
+		Platform:    "feishu",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("feishu", senderID),
+	}
+
+	// Card callbacks do not say whether the chat is p2p; presses are
+	// normally consumed by the waiting prompt, so the group peer is only a
+	// fallback.
+	peer := bus.Peer{Kind: "group", ID: chatID}
+	if !c.HandleAction(ctx, peer, senderID, chatID, data, label, sender) {
+		return nil, nil
+	}
+
+	promptID, _, _ := channels.DecodeActionData(data)
+	question, _ := c.prompts.LoadAndDelete(promptID)
+	text, _ := question.(string)
+	cardContent, err := buildMarkdownCard(fmt.Sprintf("%s\n\n**%s**", text, label))
+	if err != nil {
+		return nil, nil
+	}
+	var card any
+	if err := json.Unmarshal([]byte(cardContent), &card); err != nil {
+		return nil, nil
+	}
+	return &callback.CardActionTriggerResponse{
+		Card: &callback.Card{Type: "raw", Data: card},
+	}, nil
+}
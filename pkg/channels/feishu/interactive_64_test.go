@@ -0,0 +1,78 @@
+//go:build amd64 || arm64 || riscv64 || mips64 || ppc64
+
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestBuildActionCard(t *testing.T) {
+	content, err := buildActionCard("Deploy now?", "ab12", []bus.Action{{ID: "1", Label: "Yes"}, {ID: "2", Label: "No"}})
+	if err != nil {
+		t.Fatalf("buildActionCard: %v", err)
+	}
+	var card struct {
+		Body struct {
+			Elements []struct {
+				Tag       string `json:"tag"`
+				Behaviors []struct {
+					Value map[string]string `json:"value"`
+				} `json:"behaviors"`
+			} `json:"elements"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(content), &card); err != nil {
+		t.Fatalf("invalid card JSON: %v", err)
+	}
+	if len(card.Body.Elements) != 3 || card.Body.Elements[2].Tag != "button" {
+		t.Fatalf("unexpected elements: %s", content)
+	}
+	if got := card.Body.Elements[2].Behaviors[0].Value["action"]; got != channels.EncodeActionData("ab12", "2") {
+		t.Errorf("button value = %q", got)
+	}
+}
+
+func TestHandleCardAction(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	ch, err := NewFeishuChannel(config.FeishuConfig{AllowFrom: []string{"*"}}, msgBus)
+	if err != nil {
+		t.Fatalf("NewFeishuChannel: %v", err)
+	}
+	ch.prompts.Store("ab12", "Deploy now?")
+
+	resp, err := ch.handleCardAction(context.Background(), &callback.CardActionTriggerEvent{
+		Event: &callback.CardActionTriggerRequest{
+			Operator: &callback.Operator{OpenID: "ou_1"},
+			Action: &callback.CallBackAction{Value: map[string]any{
+				"action": channels.EncodeActionData("ab12", "1"),
+				"label":  "Yes",
+			}},
+			Context: &callback.Context{OpenChatID: "oc_1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handleCardAction: %v", err)
+	}
+
+	msg := <-msgBus.InboundChan()
+	if msg.ChatID != "oc_1" || msg.PromptID != "ab12" || msg.ActionID != "1" || msg.Content != "Yes" {
+		t.Errorf("unexpected inbound message: %+v", msg)
+	}
+	if resp == nil || resp.Card == nil {
+		t.Fatal("expected the card to be replaced")
+	}
+	data, _ := json.Marshal(resp.Card.Data)
+	if strings.Contains(string(data), "button") || !strings.Contains(string(data), "Deploy now?") {
+		t.Errorf("unexpected replacement card: %s", data)
+	}
+}
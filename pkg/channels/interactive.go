@@ -0,0 +1,122 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// actionDataPrefix marks button payloads created by EncodeActionData so that
+// channels can tell them apart from other callbacks.
+const actionDataPrefix = "pc:"
+
+// EncodeActionData returns the opaque payload a channel attaches to the
+// button for actionID. It stays well under Telegram's 64-byte limit for
+// the short IDs used by the ask_user tool.
+func EncodeActionData(promptID, actionID string) string {
+	return actionDataPrefix + promptID + ":" + actionID
+}
+
+// DecodeActionData reverses EncodeActionData.
+func DecodeActionData(data string) (promptID, actionID string, ok bool) {
+	rest, ok := strings.CutPrefix(data, actionDataPrefix)
+	if !ok {
+		return "", "", false
+	}
+	promptID, actionID, ok = strings.Cut(rest, ":")
+	if !ok || promptID == "" || actionID == "" {
+		return "", "", false
+	}
+	return promptID, actionID, true
+}
+
+// HandleAction publishes a button press as an InboundMessage. data is the
+// payload from EncodeActionData and label the pressed button's text, which
+// becomes the message content. Presses from senders that are not allowed
+// are dropped, as are payloads this package did not create; it reports
+// whether the press was handled so channels can acknowledge it.
+func (c *BaseChannel) HandleAction(
+	ctx context.Context,
+	peer bus.Peer,
+	senderID, chatID, data, label string,
+	sender bus.SenderInfo,
+) bool {
+	promptID, actionID, ok := DecodeActionData(data)
+	if !ok {
+		return false
+	}
+	if sender.CanonicalID != "" || sender.PlatformID != "" {
+		if !c.allowedSender(sender) {
+			return false
+		}
+	} else if !c.IsAllowed(senderID) {
+		return false
+	}
+	if sender.CanonicalID != "" {
+		senderID = sender.CanonicalID
+	}
+	if label == "" {
+		label = actionID
+	}
+
+	msg := bus.InboundMessage{
+		Channel:  c.name,
+		SenderID: senderID,
+		Sender:   sender,
+		ChatID:   chatID,
+		Content:  label,
+		Peer:     peer,
+		PromptID: promptID,
+		ActionID: actionID,
+	}
+	if err := c.bus.PublishInbound(ctx, msg); err != nil {
+		logger.ErrorCF("channels", "Failed to publish action", map[string]any{
+			"channel": c.name,
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+	return true
+}
+
+// SendInteractive sends msg with its actions as buttons on channels that
+// implement InteractiveCapable, and as a numbered list otherwise, so that
+// replies can be matched with InteractiveMessage.MatchReply.
+func (m *Manager) SendInteractive(ctx context.Context, msg bus.InteractiveMessage) error {
+	m.mu.RLock()
+	ch, ok := m.channels[msg.Channel]
+	w := m.workers[msg.Channel]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("channel %s not found", msg.Channel)
+	}
+	if w != nil {
+		if err := w.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	// The prompt asks for input mid-turn; a "Thinking…" placeholder above it
+	// would later turn into the answer out of order, so drop it.
+	if deleter, ok := ch.(MessageDeleter); ok {
+		if v, loaded := m.placeholders.LoadAndDelete(msg.Channel + ":" + msg.ChatID); loaded {
+			if entry, ok := v.(placeholderEntry); ok && entry.id != "" {
+				deleter.DeleteMessage(ctx, msg.ChatID, entry.id) // best effort
+			}
+		}
+	}
+
+	if ic, ok := ch.(InteractiveCapable); ok {
+		_, err := ic.SendInteractive(ctx, msg)
+		return err
+	}
+	_, err := ch.Send(ctx, bus.OutboundMessage{
+		Channel: msg.Channel,
+		ChatID:  msg.ChatID,
+		Content: msg.FallbackText(),
+	})
+	return err
+}
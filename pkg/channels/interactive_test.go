@@ -0,0 +1,47 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestActionData_RoundTrip(t *testing.T) {
+	promptID, actionID, ok := DecodeActionData(EncodeActionData("ab12", "3"))
+	if !ok || promptID != "ab12" || actionID != "3" {
+		t.Fatalf("DecodeActionData() = %q, %q, %v", promptID, actionID, ok)
+	}
+	for _, data := range []string{"", "ab12:3", "pc:", "pc:ab12", "pc::3"} {
+		if _, _, ok := DecodeActionData(data); ok {
+			t.Fatalf("DecodeActionData(%q) should fail", data)
+		}
+	}
+}
+
+func TestSendInteractive_FallsBackToNumberedText(t *testing.T) {
+	m := newTestManager()
+	ch := &mockChannel{}
+	m.channels["test"] = ch
+	m.workers["test"] = &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	err := m.SendInteractive(context.Background(), bus.InteractiveMessage{
+		Channel:  "test",
+		ChatID:   "chat1",
+		Content:  "Deploy now?",
+		PromptID: "p1",
+		Actions:  []bus.Action{{ID: "1", Label: "Yes"}, {ID: "2", Label: "No"}},
+	})
+	if err != nil {
+		t.Fatalf("SendInteractive() error = %v", err)
+	}
+	if len(ch.sentMessages) != 1 {
+		t.Fatalf("expected one message, got %d", len(ch.sentMessages))
+	}
+	if got := ch.sentMessages[0].Content; !strings.Contains(got, "1. Yes") || !strings.Contains(got, "2. No") {
+		t.Fatalf("unexpected fallback text: %q", got)
+	}
+}
@@ -49,6 +49,21 @@ type StreamingCapable interface {
 	BeginStream(ctx context.Context, chatID string) (Streamer, error)
 }
 
+// InteractiveCapable — channels that can attach a set of actions to a
+// message as buttons. A pressed button MUST be published as an
+// InboundMessage with PromptID and ActionID set (see BaseChannel.HandleAction).
+// Channels without it get a numbered text list from Manager.SendInteractive.
+type InteractiveCapable interface {
+	SendInteractive(ctx context.Context, msg bus.InteractiveMessage) (messageID string, err error)
+}
+
+// ThreadStarter — channels that can start a thread under an existing
+// message. StartThread returns the ID to put in OutboundMessage.ThreadID to
+// post in it; replies in the thread MUST arrive with the same ThreadID.
+type ThreadStarter interface {
+	StartThread(ctx context.Context, chatID, messageID, title string) (threadID string, err error)
+}
+
 // Streamer is defined in pkg/bus to avoid circular imports.
 // This alias keeps channel implementations using channels.Streamer unchanged.
 type Streamer = bus.Streamer
@@ -62,6 +77,16 @@ type PlaceholderRecorder interface {
 	RecordReactionUndo(channel, chatID string, undo func())
 }
 
+// IdentityLinker is injected into channels by Manager. It extends a channel's
+// allow-list to identities linked with /link.
+type IdentityLinker interface {
+	// LinkedAllowed reports whether an identity linked to sender is allowed
+	// on its own channel.
+	LinkedAllowed(sender bus.SenderInfo) bool
+	// LinkPending reports whether a /link code is waiting to be redeemed.
+	LinkPending() bool
+}
+
 // CommandRegistrarCapable is implemented by channels that can register
 // command menus with their upstream platform (e.g. Telegram BotCommand).
 // Channels that do not support platform-level command menus can ignore it.
@@ -69,7 +69,7 @@ func (c *IRCChannel) onPrivmsg(conn *ircevent.Connection, e ircmsg.Message) {
 		DisplayName: nick,
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		return
 	}
 
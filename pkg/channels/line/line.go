@@ -388,7 +388,7 @@ func (c *LINEChannel) processEvent(event lineEvent) {
 		CanonicalID: identity.BuildCanonicalID("line", senderID),
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		return
 	}
 
@@ -21,6 +21,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 )
@@ -91,6 +92,8 @@ type Manager struct {
 	reactionUndos sync.Map          // "channel:chatID" → reactionEntry
 	streamActive  sync.Map          // "channel:chatID" → true (set when streamer.Finalize sent the message)
 	channelHashes map[string]string // channel name → config hash
+	identityLinks *identity.LinkStore
+	sendStats     sync.Map // channel name → *sendCounters
 }
 
 type asyncTask struct {
@@ -191,7 +194,20 @@ func (m *Manager) preSend(ctx context.Context, name string, msg bus.OutboundMess
 		return nil, true
 	}
 
-	// 4. Try editing placeholder
+	// 4. A reply moved to a thread leaves the placeholder behind in the
+	// chat; remove it where possible.
+	if msg.ThreadID != "" {
+		if v, loaded := m.placeholders.LoadAndDelete(key); loaded {
+			if entry, ok := v.(placeholderEntry); ok && entry.id != "" {
+				if deleter, ok := ch.(MessageDeleter); ok {
+					deleter.DeleteMessage(ctx, msg.ChatID, entry.id) // best effort
+				}
+			}
+		}
+		return nil, false
+	}
+
+	// 5. Try editing placeholder
 	if v, loaded := m.placeholders.LoadAndDelete(key); loaded {
 		if entry, ok := v.(placeholderEntry); ok && entry.id != "" {
 			if editor, ok := ch.(MessageEditor); ok {
@@ -264,19 +280,30 @@ func NewManager(cfg *config.Config, messageBus *bus.MessageBus, store media.Medi
 }
 
 // GetStreamer implements bus.StreamDelegate.
-// It checks if the named channel supports streaming and returns a Streamer.
+// It returns the channel's native streamer if it is StreamingCapable, and
+// otherwise an EditStreamer if the channel can edit messages. The
+// EditStreamer takes over the chat's placeholder, if any, as its first
+// message.
 func (m *Manager) GetStreamer(ctx context.Context, channelName, chatID string) (bus.Streamer, bool) {
 	m.mu.RLock()
 	ch, exists := m.channels[channelName]
+	var limiter *rate.Limiter
+	if w, ok := m.workers[channelName]; ok && w != nil {
+		limiter = w.limiter
+	}
 	m.mu.RUnlock()
 
 	if !exists {
 		return nil, false
 	}
 
+	key := channelName + ":" + chatID
 	sc, ok := ch.(StreamingCapable)
 	if !ok {
-		return nil, false
+		if _, ok := ch.(MessageEditor); !ok {
+			return nil, false
+		}
+		return m.editStreamer(ch, chatID, key, limiter), true
 	}
 
 	streamer, err := sc.BeginStream(ctx, chatID)
@@ -289,27 +316,70 @@ func (m *Manager) GetStreamer(ctx context.Context, channelName, chatID string) (
 	}
 
 	// Mark streamActive on Finalize so preSend knows to clean up the placeholder
-	key := channelName + ":" + chatID
 	return &finalizeHookStreamer{
 		Streamer:   streamer,
 		onFinalize: func() { m.streamActive.Store(key, true) },
 	}, true
 }
 
-// finalizeHookStreamer wraps a Streamer to run a hook on Finalize.
+// editStreamer builds an EditStreamer for a channel without native
+// streaming. If the stream is canceled or fails to finalize, its first
+// message is recorded as the placeholder again so that the regular outbound
+// message replaces it instead of leaving a partial answer behind.
+func (m *Manager) editStreamer(ch Channel, chatID, key string, limiter *rate.Limiter) bus.Streamer {
+	var placeholderID string
+	if v, loaded := m.placeholders.LoadAndDelete(key); loaded {
+		if entry, ok := v.(placeholderEntry); ok {
+			placeholderID = entry.id
+		}
+	}
+	maxLen := 0
+	if mlp, ok := ch.(MessageLengthProvider); ok {
+		maxLen = mlp.MaxMessageLength()
+	}
+	if limiter == nil {
+		limiter = newChannelLimiter(ch.Name())
+	}
+
+	es, _ := NewEditStreamer(ch, chatID, placeholderID, maxLen, limiter) // ch is a MessageEditor
+	handBack := func() {
+		if id := es.MessageID(); id != "" {
+			m.RecordPlaceholder(ch.Name(), chatID, id)
+		}
+	}
+	return &finalizeHookStreamer{
+		Streamer:   es,
+		onFinalize: func() { m.streamActive.Store(key, true) },
+		onFail:     handBack,
+	}
+}
+
+// finalizeHookStreamer wraps a Streamer to run a hook on Finalize. The
+// optional onFail hook runs when the stream is canceled or Finalize fails.
 type finalizeHookStreamer struct {
 	Streamer
 	onFinalize func()
+	onFail     func()
 }
 
 func (s *finalizeHookStreamer) Finalize(ctx context.Context, content string) error {
 	if err := s.Streamer.Finalize(ctx, content); err != nil {
+		if s.onFail != nil {
+			s.onFail()
+		}
 		return err
 	}
 	s.onFinalize()
 	return nil
 }
 
+func (s *finalizeHookStreamer) Cancel(ctx context.Context) {
+	s.Streamer.Cancel(ctx)
+	if s.onFail != nil {
+		s.onFail()
+	}
+}
+
 // initChannel is a helper that looks up a factory by name and creates the channel.
 func (m *Manager) initChannel(name, displayName string) {
 	f, ok := getFactory(name)
@@ -339,6 +409,10 @@ func (m *Manager) initChannel(name, displayName string) {
 		if setter, ok := ch.(interface{ SetPlaceholderRecorder(r PlaceholderRecorder) }); ok {
 			setter.SetPlaceholderRecorder(m)
 		}
+		// Inject IdentityLinker so allow-lists cover identities linked with /link
+		if setter, ok := ch.(interface{ SetIdentityLinker(l IdentityLinker) }); ok {
+			setter.SetIdentityLinker(m)
+		}
 		// Inject owner reference so BaseChannel.HandleMessage can auto-trigger typing/reaction
 		if setter, ok := ch.(interface{ SetOwner(ch Channel) }); ok {
 			setter.SetOwner(ch)
@@ -624,22 +698,27 @@ func (m *Manager) StopAll(ctx context.Context) error {
 // newChannelWorker creates a channelWorker with a rate limiter configured
 // for the given channel name.
 func newChannelWorker(name string, ch Channel) *channelWorker {
-	rateVal := float64(defaultRateLimit)
-	if r, ok := channelRateConfig[name]; ok {
-		rateVal = r
-	}
-	burst := int(math.Max(1, math.Ceil(rateVal/2)))
-
 	return &channelWorker{
 		ch:         ch,
 		queue:      make(chan bus.OutboundMessage, defaultChannelQueueSize),
 		mediaQueue: make(chan bus.OutboundMediaMessage, defaultChannelQueueSize),
 		done:       make(chan struct{}),
 		mediaDone:  make(chan struct{}),
-		limiter:    rate.NewLimiter(rate.Limit(rateVal), burst),
+		limiter:    newChannelLimiter(name),
 	}
 }
 
+// newChannelLimiter returns a rate limiter using the channel's entry in
+// channelRateConfig, or defaultRateLimit.
+func newChannelLimiter(name string) *rate.Limiter {
+	rateVal := float64(defaultRateLimit)
+	if r, ok := channelRateConfig[name]; ok {
+		rateVal = r
+	}
+	burst := int(math.Max(1, math.Ceil(rateVal/2)))
+	return rate.NewLimiter(rate.Limit(rateVal), burst)
+}
+
 // runWorker processes outbound messages for a single channel.
 // Message processing follows this order:
 //  1. SplitByMarker (if enabled in config) - LLM semantic marker-based splitting
@@ -711,16 +790,23 @@ func (m *Manager) sendWithRetry(
 		return nil, false
 	}
 
+	stats := m.sendCounters(name)
+
 	// Pre-send: stop typing and try to edit placeholder
 	if msgIDs, handled := m.preSend(ctx, name, msg, w.ch); handled {
+		stats.sent.Add(1)
 		return msgIDs, true
 	}
 
 	var lastErr error
 	var msgIDs []string
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			stats.retries.Add(1)
+		}
 		msgIDs, lastErr = w.ch.Send(ctx, msg)
 		if lastErr == nil {
+			stats.sent.Add(1)
 			return msgIDs, true
 		}
 
@@ -754,6 +840,7 @@ func (m *Manager) sendWithRetry(
 	}
 
 	// All retries exhausted or permanent failure
+	stats.failed.Add(1)
 	logger.ErrorCF("channels", "Send failed", map[string]any{
 		"channel": name,
 		"chat_id": msg.ChatID,
@@ -897,11 +984,16 @@ func (m *Manager) sendMediaWithRetry(
 	// Pre-send: stop typing and clean up any placeholder before sending media.
 	m.preSendMedia(ctx, name, msg, w.ch)
 
+	stats := m.sendCounters(name)
 	var lastErr error
 	var msgIDs []string
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			stats.retries.Add(1)
+		}
 		msgIDs, lastErr = ms.SendMedia(ctx, msg)
 		if lastErr == nil {
+			stats.sent.Add(1)
 			return msgIDs, nil
 		}
 
@@ -935,6 +1027,7 @@ func (m *Manager) sendMediaWithRetry(
 	}
 
 	// All retries exhausted or permanent failure
+	stats.failed.Add(1)
 	logger.ErrorCF("channels", "SendMedia failed", map[string]any{
 		"channel": name,
 		"chat_id": msg.ChatID,
@@ -1100,6 +1193,17 @@ func (m *Manager) Reload(ctx context.Context, cfg *config.Config) error {
 	return nil
 }
 
+// RegisterHTTPHandler serves handler for pattern on the shared HTTP server,
+// replacing any handler previously registered for it. It is a no-op before
+// SetupHTTPServer.
+func (m *Manager) RegisterHTTPHandler(pattern string, handler http.Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mux != nil {
+		m.mux.Handle(pattern, handler)
+	}
+}
+
 func (m *Manager) RegisterChannel(name string, channel Channel) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -1158,6 +1262,57 @@ func (m *Manager) SendMessage(ctx context.Context, msg bus.OutboundMessage) erro
 	return nil
 }
 
+// StartThread starts a thread under messageID in chatID on channels that
+// implement ThreadStarter and returns its ID for OutboundMessage.ThreadID.
+func (m *Manager) StartThread(ctx context.Context, channel, chatID, messageID, title string) (string, error) {
+	m.mu.RLock()
+	ch, ok := m.channels[channel]
+	m.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("channel %s not found", channel)
+	}
+	ts, ok := ch.(ThreadStarter)
+	if !ok {
+		return "", fmt.Errorf("channel %s cannot start threads", channel)
+	}
+	return ts.StartThread(ctx, chatID, messageID, title)
+}
+
+// SetIdentityLinks sets the store of identities linked with /link, whose
+// members share allow-list status across channels.
+func (m *Manager) SetIdentityLinks(s *identity.LinkStore) {
+	m.mu.Lock()
+	m.identityLinks = s
+	m.mu.Unlock()
+}
+
+// LinkedAllowed implements IdentityLinker: sender is allowed if an identity
+// linked to it is named on the allow-list of the channel of its platform.
+// Open allow-lists do not count, or linking to an identity on an open
+// channel would open every other channel too.
+func (m *Manager) LinkedAllowed(sender bus.SenderInfo) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, id := range m.identityLinks.Linked(sender.CanonicalID) {
+		platform, platformID, ok := identity.ParseCanonicalID(id)
+		if !ok || id == sender.CanonicalID {
+			continue
+		}
+		ch, ok := m.channels[platform].(interface{ listsSender(bus.SenderInfo) bool })
+		if ok && ch.listsSender(bus.SenderInfo{Platform: platform, PlatformID: platformID, CanonicalID: id}) {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkPending implements IdentityLinker.
+func (m *Manager) LinkPending() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.identityLinks.Pending()
+}
+
 // SendMedia sends outbound media synchronously through the channel worker's
 // rate limiter and retry logic. It blocks until the media is delivered (or all
 // retries are exhausted), which preserves ordering when later agent behavior
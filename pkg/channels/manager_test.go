@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,6 +14,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/identity"
 )
 
 // mockChannel is a test double that delegates Send to a configurable function.
@@ -138,6 +140,9 @@ func TestSendWithRetry_TemporaryThenSuccess(t *testing.T) {
 	if callCount != 3 {
 		t.Fatalf("expected 3 Send calls (2 failures + 1 success), got %d", callCount)
 	}
+	if got := m.SendStats()["test"]; got.Sent != 1 || got.Retries != 2 || got.Failed != 0 {
+		t.Fatalf("SendStats = %+v, want 1 sent and 2 retries", got)
+	}
 }
 
 func TestSendWithRetry_PermanentFailure(t *testing.T) {
@@ -162,6 +167,9 @@ func TestSendWithRetry_PermanentFailure(t *testing.T) {
 	if callCount != 1 {
 		t.Fatalf("expected 1 Send call (no retry for permanent failure), got %d", callCount)
 	}
+	if got := m.SendStats()["test"]; got.Failed != 1 || got.Retries != 0 {
+		t.Fatalf("SendStats = %+v, want 1 failure without retries", got)
+	}
 }
 
 func TestSendWithRetry_NotRunning(t *testing.T) {
@@ -644,6 +652,23 @@ func TestPreSend_PlaceholderEditSuccess(t *testing.T) {
 	}
 }
 
+func TestPreSend_ThreadReplyDeletesPlaceholder(t *testing.T) {
+	m := newTestManager()
+	ch := &mockDeletingMediaChannel{}
+	m.RecordPlaceholder("test", "123", "456")
+
+	msg := bus.OutboundMessage{Channel: "test", ChatID: "123", Content: "hello", ThreadID: "789"}
+	if _, handled := m.preSend(context.Background(), "test", msg, ch); handled {
+		t.Fatal("expected a reply in a thread to be sent, not to replace the placeholder")
+	}
+	if ch.deleteCalls != 1 || ch.lastDeleted.messageID != "456" {
+		t.Fatalf("expected placeholder 456 to be deleted, got %d calls %+v", ch.deleteCalls, ch.lastDeleted)
+	}
+	if ch.editedMessages != 0 {
+		t.Fatalf("expected no edits, got %d", ch.editedMessages)
+	}
+}
+
 func TestPreSend_PlaceholderEditFails_FallsThrough(t *testing.T) {
 	m := newTestManager()
 
@@ -1384,3 +1409,61 @@ func TestManager_SendPlaceholder(t *testing.T) {
 		t.Error("expected SendPlaceholder to fail for unknown channel")
 	}
 }
+
+func TestLinkedIdentitiesShareAllowList(t *testing.T) {
+	links := identity.NewLinkStore(filepath.Join(t.TempDir(), "links.json"))
+	m := newTestManager()
+	m.SetIdentityLinks(links)
+
+	tg := &mockChannel{BaseChannel: *NewBaseChannel("telegram", nil, nil, []string{"123"})}
+	dc := &mockChannel{BaseChannel: *NewBaseChannel("discord", nil, nil, []string{"999"})}
+	open := &mockChannel{BaseChannel: *NewBaseChannel("slack", nil, nil, []string{"*"})}
+	for _, ch := range []*mockChannel{tg, dc, open} {
+		ch.SetIdentityLinker(m)
+		m.channels[ch.Name()] = ch
+	}
+
+	discordUser := bus.SenderInfo{Platform: "discord", PlatformID: "456", CanonicalID: "discord:456"}
+	if dc.IsAllowedSender(discordUser) {
+		t.Fatal("unlinked sender allowed")
+	}
+
+	code, err := links.IssueCode("telegram:123")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	if dc.IsAllowedSender(discordUser) {
+		t.Fatal("a pending code should not open the allow-list")
+	}
+	if !dc.IsAllowedMessage(discordUser, "/link "+code) || dc.IsAllowedMessage(discordUser, "hello") {
+		t.Fatal("IsAllowedMessage() did not single out /link <code>")
+	}
+
+	if _, err := links.Redeem(code, "discord:456"); err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	if dc.IsAllowedSender(discordUser) || dc.IsAllowedMessage(discordUser, "/link "+code) {
+		t.Fatal("sender allowed before the issuer confirmed the link")
+	}
+	if _, err := links.Confirm("telegram:123", "discord:456"); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if !dc.IsAllowedSender(discordUser) {
+		t.Fatal("sender linked to an allowed telegram user is blocked")
+	}
+
+	// Identities allowed only by an open allow-list do not vouch for others.
+	slackUser := bus.SenderInfo{Platform: "slack", PlatformID: "U1", CanonicalID: "slack:U1"}
+	if code, err = links.IssueCode("slack:U1"); err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	if _, err = links.Redeem(code, "discord:777"); err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	if !open.allowedSender(slackUser) {
+		t.Fatal("open channel blocked its own sender")
+	}
+	if dc.allowedSender(bus.SenderInfo{Platform: "discord", PlatformID: "777", CanonicalID: "discord:777"}) {
+		t.Fatal("sender linked to an open channel identity is allowed")
+	}
+}
@@ -385,17 +385,22 @@ func (c *MatrixChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]st
 		return nil, channels.ErrNotRunning
 	}
 
-	roomID := id.RoomID(strings.TrimSpace(msg.ChatID))
+	roomID, threadRoot := parseMatrixChatID(msg.ChatID)
 	if roomID == "" {
 		return nil, fmt.Errorf("matrix room ID is empty: %w", channels.ErrSendFailed)
 	}
+	if threadRoot == "" {
+		threadRoot = id.EventID(msg.ThreadID)
+	}
 
 	content := strings.TrimSpace(msg.Content)
 	if content == "" {
 		return nil, nil
 	}
 
-	resp, err := c.client.SendMessageEvent(ctx, roomID, event.EventMessage, c.messageContent(content))
+	mc := c.messageContent(content)
+	inThread(mc, threadRoot)
+	resp, err := c.client.SendMessageEvent(ctx, roomID, event.EventMessage, mc)
 	if err != nil {
 		return nil, fmt.Errorf("matrix send: %w", channels.ErrTemporary)
 	}
@@ -421,7 +426,7 @@ func (c *MatrixChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 		sendCtx = context.Background()
 	}
 
-	roomID := id.RoomID(strings.TrimSpace(msg.ChatID))
+	roomID, threadRoot := parseMatrixChatID(msg.ChatID)
 	if roomID == "" {
 		return nil, fmt.Errorf("matrix room ID is empty: %w", channels.ErrSendFailed)
 	}
@@ -511,6 +516,7 @@ func (c *MatrixChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMess
 			fileInfo.Size(),
 			uploadResp.ContentURI.CUString(),
 		)
+		inThread(content, threadRoot)
 
 		sendResp, err := c.client.SendMessageEvent(sendCtx, roomID, event.EventMessage, content)
 		if err != nil {
@@ -535,7 +541,7 @@ func (c *MatrixChannel) StartTyping(ctx context.Context, chatID string) (func(),
 		return func() {}, nil
 	}
 
-	roomID := id.RoomID(strings.TrimSpace(chatID))
+	roomID, _ := parseMatrixChatID(chatID)
 	if roomID == "" {
 		return func() {}, fmt.Errorf("matrix room ID is empty")
 	}
@@ -574,17 +580,19 @@ func (c *MatrixChannel) SendPlaceholder(ctx context.Context, chatID string) (str
 		return "", nil
 	}
 
-	roomID := id.RoomID(strings.TrimSpace(chatID))
+	roomID, threadRoot := parseMatrixChatID(chatID)
 	if roomID == "" {
 		return "", fmt.Errorf("matrix room ID is empty")
 	}
 
 	text := c.config.Placeholder.GetRandomText()
 
-	resp, err := c.client.SendMessageEvent(ctx, roomID, event.EventMessage, &event.MessageEventContent{
+	content := &event.MessageEventContent{
 		MsgType: event.MsgNotice,
 		Body:    text,
-	})
+	}
+	inThread(content, threadRoot)
+	resp, err := c.client.SendMessageEvent(ctx, roomID, event.EventMessage, content)
 	if err != nil {
 		return "", err
 	}
@@ -594,7 +602,7 @@ func (c *MatrixChannel) SendPlaceholder(ctx context.Context, chatID string) (str
 
 // EditMessage implements channels.MessageEditor.
 func (c *MatrixChannel) EditMessage(ctx context.Context, chatID string, messageID string, content string) error {
-	roomID := id.RoomID(strings.TrimSpace(chatID))
+	roomID, _ := parseMatrixChatID(chatID)
 	if roomID == "" {
 		return fmt.Errorf("matrix room ID is empty")
 	}
@@ -702,7 +710,7 @@ func (c *MatrixChannel) handleMessageEvent(ctx context.Context, evt *event.Event
 		DisplayName: senderID,
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		logger.DebugCF("matrix", "Message rejected by allowlist", map[string]any{
 			"sender_id": senderID,
 		})
@@ -752,12 +760,20 @@ func (c *MatrixChannel) handleMessageEvent(ctx context.Context, evt *event.Event
 		metadata["reply_to_msg_id"] = replyTo.String()
 	}
 
+	// Messages in a thread are answered in it: the chat ID carries the
+	// thread root as "roomID/rootEventID".
+	chatID := roomID
+	if threadRoot := msgEvt.GetRelatesTo().GetThreadParent(); threadRoot != "" {
+		chatID = roomID + "/" + threadRoot.String()
+		metadata["thread_id"] = threadRoot.String()
+	}
+
 	c.HandleMessage(
 		c.baseContext(),
 		bus.Peer{Kind: peerKind, ID: peerID},
 		evt.ID.String(),
 		senderID,
-		roomID,
+		chatID,
 		content,
 		mediaPaths,
 		metadata,
@@ -1227,9 +1243,10 @@ func (c *MatrixChannel) stopTypingSessions(ctx context.Context) {
 	if stopCtx == nil {
 		stopCtx = context.Background()
 	}
-	for roomID, session := range sessions {
+	for chatID, session := range sessions {
 		session.stop()
-		_, _ = c.client.UserTyping(stopCtx, id.RoomID(roomID), false, 0)
+		roomID, _ := parseMatrixChatID(chatID)
+		_, _ = c.client.UserTyping(stopCtx, roomID, false, 0)
 	}
 }
 
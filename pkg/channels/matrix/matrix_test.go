@@ -459,3 +459,26 @@ func TestMessageContent(t *testing.T) {
 		t.Errorf("plain: expected no formatting, got format=%q formattedBody=%q", mc.Format, mc.FormattedBody)
 	}
 }
+
+func TestParseMatrixChatID(t *testing.T) {
+	roomID, threadRoot := parseMatrixChatID("!room:example.org")
+	if roomID != "!room:example.org" || threadRoot != "" {
+		t.Errorf("room only: got %q, %q", roomID, threadRoot)
+	}
+	roomID, threadRoot = parseMatrixChatID("!room:example.org/$root")
+	if roomID != "!room:example.org" || threadRoot != "$root" {
+		t.Errorf("thread: got %q, %q", roomID, threadRoot)
+	}
+}
+
+func TestInThread(t *testing.T) {
+	mc := &event.MessageEventContent{MsgType: event.MsgText, Body: "hi"}
+	inThread(mc, "")
+	if mc.RelatesTo != nil {
+		t.Fatalf("expected no relation without a thread, got %+v", mc.RelatesTo)
+	}
+	inThread(mc, "$root")
+	if got := mc.RelatesTo.GetThreadParent(); got != "$root" {
+		t.Errorf("thread parent = %q, want $root", got)
+	}
+}
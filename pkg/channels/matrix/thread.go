@@ -0,0 +1,53 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+// parseMatrixChatID splits "roomID/threadRootEventID" into its components.
+// Room IDs never contain "/", so a plain room ID has no thread.
+func parseMatrixChatID(chatID string) (id.RoomID, id.EventID) {
+	roomID, threadRoot, _ := strings.Cut(strings.TrimSpace(chatID), "/")
+	return id.RoomID(roomID), id.EventID(threadRoot)
+}
+
+// inThread puts content in the thread rooted at threadRoot. The fallback
+// reply to the root keeps the message in context for clients without
+// thread support.
+func inThread(content *event.MessageEventContent, threadRoot id.EventID) {
+	if threadRoot == "" {
+		return
+	}
+	if content.RelatesTo == nil {
+		content.RelatesTo = &event.RelatesTo{}
+	}
+	content.RelatesTo.SetThread(threadRoot, threadRoot)
+}
+
+// StartThread implements channels.ThreadStarter. A Matrix thread is named
+// after its root event and exists once a reply is posted in it.
+func (c *MatrixChannel) StartThread(ctx context.Context, chatID, messageID, title string) (string, error) {
+	if _, threadRoot := parseMatrixChatID(chatID); threadRoot != "" {
+		return threadRoot.String(), nil
+	}
+	return messageID, nil
+}
+
+// DeleteMessage implements channels.MessageDeleter by redacting the event.
+func (c *MatrixChannel) DeleteMessage(ctx context.Context, chatID string, messageID string) error {
+	roomID, _ := parseMatrixChatID(chatID)
+	if roomID == "" {
+		return fmt.Errorf("matrix room ID is empty")
+	}
+	if _, err := c.client.RedactEvent(ctx, roomID, id.EventID(messageID)); err != nil {
+		return fmt.Errorf("matrix redact: %w", channels.ErrTemporary)
+	}
+	return nil
+}
@@ -853,7 +853,7 @@ func (c *OneBotChannel) handleRawEvent(raw *oneBotRawEvent) {
 				PlatformID:  strconv.FormatInt(userID, 10),
 				CanonicalID: identity.BuildCanonicalID("onebot", strconv.FormatInt(userID, 10)),
 			}
-			if !c.IsAllowedSender(sender) {
+			if !c.IsAllowedMessage(sender, raw.RawMessage) {
 				logger.DebugCF("onebot", "Message rejected by allowlist", map[string]any{
 					"user_id": userID,
 				})
@@ -1065,7 +1065,7 @@ func (c *OneBotChannel) handleMessage(raw *oneBotRawEvent) {
 		DisplayName: sender.Nickname,
 	}
 
-	if !c.IsAllowedSender(senderInfo) {
+	if !c.IsAllowedMessage(senderInfo, content) {
 		logger.DebugCF("onebot", "Message rejected by allowlist (senderInfo)", map[string]any{
 			"sender": senderID,
 		})
@@ -262,7 +262,7 @@ func (c *PicoClientChannel) handleServerMessage(pc *picoConn, msg PicoMessage) {
 		CanonicalID: identity.BuildCanonicalID("pico_client", senderID),
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		return
 	}
 
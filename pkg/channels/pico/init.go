@@ -1,6 +1,7 @@
 package pico
 
 import (
+	"github.com/sipeed/picoclaw/pkg/audio/tts"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -8,7 +9,11 @@ import (
 
 func init() {
 	channels.RegisterFactory("pico", func(cfg *config.Config, b *bus.MessageBus) (channels.Channel, error) {
-		return NewPicoChannel(cfg.Channels.Pico, b)
+		ch, err := NewPicoChannel(cfg.Channels.Pico, b)
+		if err == nil && cfg.Channels.Pico.Voice.Enabled {
+			ch.tts = tts.DetectTTS(cfg)
+		}
+		return ch, err
 	})
 	channels.RegisterFactory("pico_client", func(cfg *config.Config, b *bus.MessageBus) (channels.Channel, error) {
 		return NewPicoClientChannel(cfg.Channels.PicoClient, b)
@@ -0,0 +1,64 @@
+package pico
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+)
+
+// SendInteractive implements channels.InteractiveCapable. The prompt is a
+// message.create whose payload also carries "prompt_id" and an "actions"
+// list of {id, label}; the client answers with message.action.
+func (c *PicoChannel) SendInteractive(ctx context.Context, msg bus.InteractiveMessage) (string, error) {
+	if !c.IsRunning() {
+		return "", channels.ErrNotRunning
+	}
+
+	actions := make([]map[string]any, 0, len(msg.Actions))
+	for _, a := range msg.Actions {
+		actions = append(actions, map[string]any{"id": a.ID, "label": a.Label})
+	}
+	msgID := uuid.New().String()
+	outMsg := newMessage(TypeMessageCreate, map[string]any{
+		"content":    msg.Content,
+		"message_id": msgID,
+		"prompt_id":  msg.PromptID,
+		"actions":    actions,
+	})
+	if err := c.broadcastToSession(msg.ChatID, outMsg); err != nil {
+		return "", err
+	}
+	return msgID, nil
+}
+
+// handleMessageAction processes an inbound message.action from a client.
+func (c *PicoChannel) handleMessageAction(pc *picoConn, msg PicoMessage) {
+	promptID, _ := msg.Payload["prompt_id"].(string)
+	actionID, _ := msg.Payload["action_id"].(string)
+	label, _ := msg.Payload["label"].(string)
+	if promptID == "" || actionID == "" {
+		pc.writeJSON(newError("invalid_action", "prompt_id and action_id are required"))
+		return
+	}
+
+	sessionID := msg.SessionID
+	if sessionID == "" {
+		sessionID = pc.sessionID
+	}
+
+	chatID := "pico:" + sessionID
+	senderID := "pico-user"
+	peer := bus.Peer{Kind: "direct", ID: "pico:" + sessionID}
+	sender := bus.SenderInfo{
+		Platform:    "pico",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("pico", senderID),
+	}
+
+	data := channels.EncodeActionData(promptID, actionID)
+	c.HandleAction(c.ctx, peer, senderID, chatID, data, label, sender)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
+	"github.com/sipeed/picoclaw/pkg/audio/tts"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -61,6 +62,10 @@ type PicoChannel struct {
 	connsMu            sync.RWMutex
 	ctx                context.Context
 	cancel             context.CancelFunc
+	bus                *bus.MessageBus
+	tts                tts.TTSProvider
+	calls              map[string]*voiceCall // connID -> active WebRTC voice call
+	callsMu            sync.Mutex
 }
 
 // NewPicoChannel creates a new Pico Protocol channel.
@@ -95,6 +100,8 @@ func NewPicoChannel(cfg config.PicoConfig, messageBus *bus.MessageBus) (*PicoCha
 		},
 		connections:        make(map[string]*picoConn),
 		sessionConnections: make(map[string]map[string]*picoConn),
+		bus:                messageBus,
+		calls:              make(map[string]*voiceCall),
 	}, nil
 }
 
@@ -207,6 +214,7 @@ func (c *PicoChannel) Stop(ctx context.Context) error {
 
 	// Close all connections
 	for _, pc := range c.takeAllConnections() {
+		c.hangupConn(pc.id)
 		pc.close()
 	}
 
@@ -243,6 +251,8 @@ func (c *PicoChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]stri
 		"content": msg.Content,
 	})
 
+	c.speak(strings.TrimPrefix(msg.ChatID, "pico:"), msg.Content)
+
 	return nil, c.broadcastToSession(msg.ChatID, outMsg)
 }
 
@@ -424,6 +434,7 @@ func (c *PicoChannel) matchedSubprotocol(r *http.Request) string {
 // readLoop reads messages from a WebSocket connection.
 func (c *PicoChannel) readLoop(pc *picoConn) {
 	defer func() {
+		c.hangupConn(pc.id)
 		pc.close()
 		if removed := c.removeConnection(pc.id); removed != nil {
 			logger.InfoCF("pico", "WebSocket client disconnected", map[string]any{
@@ -516,6 +527,18 @@ func (c *PicoChannel) handleMessage(pc *picoConn, msg PicoMessage) {
 	case TypeMessageSend:
 		c.handleMessageSend(pc, msg)
 
+	case TypeMessageAction:
+		c.handleMessageAction(pc, msg)
+
+	case TypeWebRTCOffer:
+		c.handleWebRTCOffer(pc, msg)
+
+	case TypeWebRTCCandidate:
+		c.handleWebRTCCandidate(pc, msg)
+
+	case TypeWebRTCHangup:
+		c.hangupConn(pc.id)
+
 	default:
 		errMsg := newError("unknown_type", fmt.Sprintf("unknown message type: %s", msg.Type))
 		pc.writeJSON(errMsg)
@@ -558,13 +581,23 @@ func (c *PicoChannel) handleMessageSend(pc *picoConn, msg PicoMessage) {
 		CanonicalID: identity.BuildCanonicalID("pico", senderID),
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		return
 	}
 
 	c.HandleMessage(c.ctx, peer, msg.ID, senderID, chatID, content, nil, metadata, sender)
 }
 
+// VoiceCapabilities implements channels.VoiceCapabilityProvider.
+// Voice is only available through WebRTC calls, so both directions depend on
+// the voice config; TTS also needs a detected speech provider.
+func (c *PicoChannel) VoiceCapabilities() channels.VoiceCapabilities {
+	return channels.VoiceCapabilities{
+		ASR: c.config.Voice.Enabled,
+		TTS: c.config.Voice.Enabled && c.tts != nil,
+	}
+}
+
 // truncate truncates a string to maxLen runes.
 func truncate(s string, maxLen int) string {
 	runes := []rune(s)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 	"testing"
 
@@ -142,3 +144,54 @@ func (c *PicoChannel) addConnForTest(pc *picoConn) {
 	}
 	bySession[pc.id] = pc
 }
+
+func TestHandleMessageAction_PublishesChoice(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	cfg := config.PicoConfig{}
+	cfg.SetToken("test-token")
+	ch, err := NewPicoChannel(cfg, mb)
+	if err != nil {
+		t.Fatalf("NewPicoChannel: %v", err)
+	}
+	ch.ctx = context.Background()
+
+	pc := &picoConn{id: "c1", sessionID: "s1"}
+	ch.handleMessageAction(pc, newMessage(TypeMessageAction, map[string]any{
+		"prompt_id": "p1",
+		"action_id": "2",
+		"label":     "No",
+	}))
+
+	select {
+	case msg := <-mb.InboundChan():
+		if msg.ChatID != "pico:s1" || msg.PromptID != "p1" || msg.ActionID != "2" || msg.Content != "No" {
+			t.Fatalf("unexpected inbound message: %+v", msg)
+		}
+	default:
+		t.Fatal("expected an inbound message")
+	}
+}
+
+type stubTTS struct{}
+
+func (stubTTS) Name() string { return "stub" }
+
+func (stubTTS) Synthesize(context.Context, string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func TestVoiceCapabilities_TTSNeedsProvider(t *testing.T) {
+	ch := newTestPicoChannel(t)
+	ch.config.Voice.Enabled = true
+
+	caps := ch.VoiceCapabilities()
+	if !caps.ASR || caps.TTS {
+		t.Fatalf("caps without provider = %+v, want ASR only", caps)
+	}
+
+	ch.tts = stubTTS{}
+	if caps = ch.VoiceCapabilities(); !caps.ASR || !caps.TTS {
+		t.Fatalf("caps with provider = %+v, want ASR and TTS", caps)
+	}
+}
@@ -9,6 +9,17 @@ const (
 	TypeMediaSend   = "media.send"
 	TypePing        = "ping"
 
+	// TypeMessageAction is sent by the client when the user picks one of the
+	// actions of a message.create that carried an "actions" list.
+	TypeMessageAction = "message.action"
+
+	// WebRTC voice call signalling. Offers and hangups are sent by the client;
+	// answers by the server; ICE candidates flow in both directions.
+	TypeWebRTCOffer     = "webrtc.offer"
+	TypeWebRTCAnswer    = "webrtc.answer"
+	TypeWebRTCCandidate = "webrtc.candidate"
+	TypeWebRTCHangup    = "webrtc.hangup"
+
 	// TypeMessageCreate is sent from server to client.
 	TypeMessageCreate = "message.create"
 	TypeMessageUpdate = "message.update"
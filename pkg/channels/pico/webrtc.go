@@ -0,0 +1,456 @@
+package pico
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+
+	"github.com/sipeed/picoclaw/pkg/audio"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// opusFrameDuration is the frame size produced by browsers and by the
+	// Ogg/Opus TTS providers.
+	opusFrameDuration = 20 * time.Millisecond
+
+	// opusSilenceMaxBytes is the largest payload treated as silence. With DTX
+	// enabled browsers emit 1-3 byte comfort-noise frames between utterances;
+	// forwarding them would keep the ASR agent from ever detecting the end of
+	// speech.
+	opusSilenceMaxBytes = 10
+
+	voiceSessionPrefix = "pico_vc_"
+)
+
+// voiceCall is a WebRTC voice call bound to a single WebSocket connection.
+type voiceCall struct {
+	connID    string
+	sessionID string
+	peer      *webrtc.PeerConnection
+	track     *webrtc.TrackLocalStaticSample
+	closeOnce sync.Once
+
+	// TTS interruption: cancel active playback when the user speaks
+	ttsMu     sync.Mutex
+	cancelTTS context.CancelFunc
+	ttsPlayID uint64
+}
+
+// close tears down the peer connection and stops any playback.
+func (vc *voiceCall) close() {
+	vc.closeOnce.Do(func() {
+		vc.stopTTS()
+		if err := vc.peer.Close(); err != nil {
+			logger.DebugCF("pico", "Failed to close peer connection", map[string]any{
+				"conn_id": vc.connID,
+				"error":   err.Error(),
+			})
+		}
+	})
+}
+
+// stopTTS cancels the current TTS playback, if any.
+func (vc *voiceCall) stopTTS() bool {
+	vc.ttsMu.Lock()
+	defer vc.ttsMu.Unlock()
+	if vc.cancelTTS == nil {
+		return false
+	}
+	vc.cancelTTS()
+	vc.cancelTTS = nil
+	return true
+}
+
+// newPeerConnection builds a peer connection that only negotiates Opus audio.
+// DTX is requested so that silence produces (almost) no packets.
+func (c *PicoChannel) newPeerConnection() (*webrtc.PeerConnection, error) {
+	m := &webrtc.MediaEngine{}
+	if err := m.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    webrtc.MimeTypeOpus,
+			ClockRate:   48000,
+			Channels:    2,
+			SDPFmtpLine: "minptime=10;useinbandfec=1;usedtx=1",
+		},
+		PayloadType: 111,
+	}, webrtc.RTPCodecTypeAudio); err != nil {
+		return nil, fmt.Errorf("register opus codec: %w", err)
+	}
+
+	var iceServers []webrtc.ICEServer
+	for _, u := range c.config.Voice.ICEServers {
+		if u = strings.TrimSpace(u); u != "" {
+			iceServers = append(iceServers, webrtc.ICEServer{URLs: []string{u}})
+		}
+	}
+
+	api := webrtc.NewAPI(webrtc.WithMediaEngine(m))
+	return api.NewPeerConnection(webrtc.Configuration{ICEServers: iceServers})
+}
+
+// getCall returns the active voice call for a connection.
+func (c *PicoChannel) getCall(connID string) *voiceCall {
+	c.callsMu.Lock()
+	defer c.callsMu.Unlock()
+	return c.calls[connID]
+}
+
+// removeCall detaches the voice call for a connection and closes it.
+// It is a no-op when vc is no longer the active call of the connection.
+func (c *PicoChannel) removeCall(vc *voiceCall) {
+	c.callsMu.Lock()
+	if c.calls[vc.connID] == vc {
+		delete(c.calls, vc.connID)
+	}
+	c.callsMu.Unlock()
+	vc.close()
+}
+
+// hangupConn closes the voice call owned by connID, if any.
+func (c *PicoChannel) hangupConn(connID string) {
+	if vc := c.getCall(connID); vc != nil {
+		c.removeCall(vc)
+		logger.InfoCF("pico", "Voice call ended", map[string]any{
+			"conn_id":    vc.connID,
+			"session_id": vc.sessionID,
+		})
+	}
+}
+
+// sessionCalls returns the active voice calls for a session.
+func (c *PicoChannel) sessionCalls(sessionID string) []*voiceCall {
+	c.callsMu.Lock()
+	defer c.callsMu.Unlock()
+
+	var calls []*voiceCall
+	for _, vc := range c.calls {
+		if vc.sessionID == sessionID {
+			calls = append(calls, vc)
+		}
+	}
+	return calls
+}
+
+// handleWebRTCOffer answers a browser offer and starts a voice call on the
+// connection. Any previous call on the same connection is replaced.
+func (c *PicoChannel) handleWebRTCOffer(pc *picoConn, msg PicoMessage) {
+	if !c.config.Voice.Enabled {
+		pc.writeJSON(newError("voice_disabled", "voice calls are not enabled on this channel"))
+		return
+	}
+
+	sdp, _ := msg.Payload["sdp"].(string)
+	if strings.TrimSpace(sdp) == "" {
+		pc.writeJSON(newError("invalid_offer", "offer sdp is empty"))
+		return
+	}
+
+	senderID := "pico-user"
+	sender := bus.SenderInfo{
+		Platform:    "pico",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("pico", senderID),
+	}
+	if !c.IsAllowedSender(sender) {
+		pc.writeJSON(newError("forbidden", "sender is not allowed"))
+		return
+	}
+
+	c.hangupConn(pc.id)
+
+	peer, err := c.newPeerConnection()
+	if err != nil {
+		logger.ErrorCF("pico", "Failed to create peer connection", map[string]any{"error": err.Error()})
+		pc.writeJSON(newError("webrtc_failed", "failed to create peer connection"))
+		return
+	}
+
+	track, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2},
+		"audio", "picoclaw",
+	)
+	if err == nil {
+		var rtpSender *webrtc.RTPSender
+		if rtpSender, err = peer.AddTrack(track); err == nil {
+			// Drain RTCP so the interceptors keep running.
+			go func() {
+				buf := make([]byte, 1500)
+				for {
+					if _, _, rtcpErr := rtpSender.Read(buf); rtcpErr != nil {
+						return
+					}
+				}
+			}()
+		}
+	}
+	if err != nil {
+		_ = peer.Close()
+		logger.ErrorCF("pico", "Failed to add TTS track", map[string]any{"error": err.Error()})
+		pc.writeJSON(newError("webrtc_failed", "failed to add audio track"))
+		return
+	}
+
+	vc := &voiceCall{
+		connID:    pc.id,
+		sessionID: pc.sessionID,
+		peer:      peer,
+		track:     track,
+	}
+
+	peer.OnICECandidate(func(cand *webrtc.ICECandidate) {
+		if cand == nil {
+			return
+		}
+		init := cand.ToJSON()
+		payload := map[string]any{"candidate": init.Candidate}
+		if init.SDPMid != nil {
+			payload["sdp_mid"] = *init.SDPMid
+		}
+		if init.SDPMLineIndex != nil {
+			payload["sdp_mline_index"] = *init.SDPMLineIndex
+		}
+		pc.writeJSON(newMessage(TypeWebRTCCandidate, payload))
+	})
+
+	peer.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		logger.DebugCF("pico", "Voice call state changed", map[string]any{
+			"conn_id": pc.id,
+			"state":   state.String(),
+		})
+		if state == webrtc.PeerConnectionStateFailed || state == webrtc.PeerConnectionStateClosed {
+			c.removeCall(vc)
+		}
+	})
+
+	peer.OnTrack(func(remote *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+		if remote.Kind() != webrtc.RTPCodecTypeAudio {
+			return
+		}
+		go c.receiveVoice(vc, remote, senderID)
+	})
+
+	if err := peer.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: sdp}); err != nil {
+		_ = peer.Close()
+		pc.writeJSON(newError("invalid_offer", fmt.Sprintf("failed to apply offer: %v", err)))
+		return
+	}
+
+	answer, err := peer.CreateAnswer(nil)
+	if err == nil {
+		err = peer.SetLocalDescription(answer)
+	}
+	if err != nil {
+		_ = peer.Close()
+		logger.ErrorCF("pico", "Failed to create answer", map[string]any{"error": err.Error()})
+		pc.writeJSON(newError("webrtc_failed", "failed to create answer"))
+		return
+	}
+
+	c.callsMu.Lock()
+	c.calls[pc.id] = vc
+	c.callsMu.Unlock()
+
+	reply := newMessage(TypeWebRTCAnswer, map[string]any{
+		"type": answer.Type.String(),
+		"sdp":  answer.SDP,
+	})
+	reply.ID = msg.ID
+	reply.SessionID = pc.sessionID
+	if err := pc.writeJSON(reply); err != nil {
+		c.removeCall(vc)
+		return
+	}
+
+	logger.InfoCF("pico", "Voice call started", map[string]any{
+		"conn_id":    pc.id,
+		"session_id": pc.sessionID,
+	})
+}
+
+// handleWebRTCCandidate adds a trickled ICE candidate from the browser.
+func (c *PicoChannel) handleWebRTCCandidate(pc *picoConn, msg PicoMessage) {
+	vc := c.getCall(pc.id)
+	if vc == nil {
+		pc.writeJSON(newError("no_call", "no active voice call"))
+		return
+	}
+
+	candidate, _ := msg.Payload["candidate"].(string)
+	if candidate == "" {
+		// An empty candidate signals end-of-candidates; nothing to do.
+		return
+	}
+
+	init := webrtc.ICECandidateInit{Candidate: candidate}
+	if mid, ok := msg.Payload["sdp_mid"].(string); ok {
+		init.SDPMid = &mid
+	}
+	if idx, ok := msg.Payload["sdp_mline_index"].(float64); ok && idx >= 0 {
+		mline := uint16(idx)
+		init.SDPMLineIndex = &mline
+	}
+
+	if err := vc.peer.AddICECandidate(init); err != nil {
+		logger.DebugCF("pico", "Failed to add ICE candidate", map[string]any{
+			"conn_id": pc.id,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// receiveVoice forwards inbound Opus packets to the ASR agent as bus.AudioChunk.
+func (c *PicoChannel) receiveVoice(vc *voiceCall, remote *webrtc.TrackRemote, senderID string) {
+	logger.InfoCF("pico", "Started listening for voice", map[string]any{
+		"conn_id": vc.connID,
+		"codec":   remote.Codec().MimeType,
+	})
+
+	codec := remote.Codec()
+	sampleRate := int(codec.ClockRate)
+	if sampleRate == 0 {
+		sampleRate = 48000
+	}
+	channelCount := int(codec.Channels)
+	if channelCount == 0 {
+		channelCount = 2
+	}
+
+	var sequence uint64
+	var interruptCount int
+	var lastInterruptAt time.Time
+
+	for {
+		pkt, _, err := remote.ReadRTP()
+		if err != nil {
+			if err != io.EOF {
+				logger.DebugCF("pico", "Voice track closed", map[string]any{
+					"conn_id": vc.connID,
+					"error":   err.Error(),
+				})
+			}
+			return
+		}
+
+		if len(pkt.Payload) <= opusSilenceMaxBytes {
+			continue
+		}
+
+		// Interruption detection: if the user talks while TTS is playing,
+		// cancel TTS after a short debounce (3 packets in 500ms).
+		now := time.Now()
+		if now.Sub(lastInterruptAt) > 500*time.Millisecond {
+			interruptCount = 0
+		}
+		interruptCount++
+		lastInterruptAt = now
+		if interruptCount >= 3 {
+			if vc.stopTTS() {
+				logger.InfoCF("pico", "TTS interrupted by user voice", map[string]any{"conn_id": vc.connID})
+			}
+			interruptCount = 0
+		}
+
+		sequence++
+		chunk := bus.AudioChunk{
+			SessionID:  voiceSessionPrefix + vc.sessionID,
+			SpeakerID:  senderID,
+			ChatID:     "pico:" + vc.sessionID,
+			Channel:    "pico",
+			PeerKind:   "direct",
+			Sequence:   sequence,
+			Timestamp:  pkt.Timestamp,
+			SampleRate: sampleRate,
+			Channels:   channelCount,
+			Format:     "opus",
+			Data:       pkt.Payload,
+		}
+
+		ctx, cancel := context.WithTimeout(c.ctx, 100*time.Millisecond)
+		err = c.bus.PublishAudioChunk(ctx, chunk)
+		cancel()
+		if err != nil {
+			logger.ErrorCF("pico", "Failed to publish audio chunk", map[string]any{
+				"session_id": vc.sessionID,
+				"sequence":   sequence,
+				"error":      err.Error(),
+			})
+		}
+	}
+}
+
+// speak plays text as TTS audio on every voice call of the session,
+// interrupting any playback already in progress.
+func (c *PicoChannel) speak(sessionID, text string) {
+	if c.tts == nil {
+		return
+	}
+	for _, vc := range c.sessionCalls(sessionID) {
+		vc.ttsMu.Lock()
+		if vc.cancelTTS != nil {
+			vc.cancelTTS()
+		}
+		ttsCtx, ttsCancel := context.WithCancel(c.ctx)
+		vc.ttsPlayID++
+		playID := vc.ttsPlayID
+		vc.cancelTTS = ttsCancel
+		vc.ttsMu.Unlock()
+
+		go c.playTTS(ttsCtx, vc, text, playID)
+	}
+}
+
+// playTTS synthesizes text sentence by sentence and streams the Opus frames
+// onto the call's outbound track in real time.
+func (c *PicoChannel) playTTS(ctx context.Context, vc *voiceCall, text string, playID uint64) {
+	defer func() {
+		vc.ttsMu.Lock()
+		if vc.ttsPlayID == playID {
+			vc.cancelTTS = nil
+		}
+		vc.ttsMu.Unlock()
+	}()
+
+	sentences := audio.SplitSentences(text)
+	for i, sentence := range sentences {
+		if ctx.Err() != nil {
+			logger.InfoCF("pico", "TTS interrupted", map[string]any{"at_sentence": i})
+			return
+		}
+
+		stream, err := c.tts.Synthesize(ctx, sentence)
+		if err != nil {
+			logger.ErrorCF("pico", "TTS synthesize failed", map[string]any{"error": err.Error(), "sentence": i})
+			continue
+		}
+		err = streamOggOpusToTrack(ctx, vc.track, stream)
+		stream.Close()
+		if err != nil && ctx.Err() == nil {
+			logger.ErrorCF("pico", "TTS playback failed", map[string]any{"error": err.Error(), "sentence": i})
+		}
+	}
+}
+
+// streamOggOpusToTrack writes the Opus frames of an Ogg stream to a WebRTC
+// track, pacing them at the frame rate so the browser jitter buffer keeps up.
+func streamOggOpusToTrack(ctx context.Context, track *webrtc.TrackLocalStaticSample, r io.Reader) error {
+	ticker := time.NewTicker(opusFrameDuration)
+	defer ticker.Stop()
+
+	return audio.DecodeOggOpus(r, func(frame []byte) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		return track.WriteSample(media.Sample{Data: frame, Duration: opusFrameDuration})
+	})
+}
@@ -0,0 +1,155 @@
+package pico
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pion/webrtc/v3"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func startVoiceTestServer(t *testing.T, voiceEnabled bool) (*PicoChannel, *websocket.Conn) {
+	t.Helper()
+
+	cfg := config.PicoConfig{}
+	cfg.SetToken("test-token")
+	cfg.Voice.Enabled = voiceEnabled
+	ch, err := NewPicoChannel(cfg, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewPicoChannel: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+
+	srv := httptest.NewServer(ch)
+	t.Cleanup(srv.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/pico/ws?session_id=voice"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Authorization": {"Bearer test-token"}})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return ch, conn
+}
+
+func newBrowserOffer(t *testing.T) (*webrtc.PeerConnection, string) {
+	t.Helper()
+
+	peer, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatalf("NewPeerConnection: %v", err)
+	}
+	t.Cleanup(func() { _ = peer.Close() })
+
+	if _, err := peer.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio); err != nil {
+		t.Fatalf("AddTransceiverFromKind: %v", err)
+	}
+	offer, err := peer.CreateOffer(nil)
+	if err != nil {
+		t.Fatalf("CreateOffer: %v", err)
+	}
+	if err := peer.SetLocalDescription(offer); err != nil {
+		t.Fatalf("SetLocalDescription: %v", err)
+	}
+	return peer, offer.SDP
+}
+
+// readUntil reads messages until one of the wanted type arrives.
+func readUntil(t *testing.T, conn *websocket.Conn, msgType string) PicoMessage {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg PicoMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("waiting for %s: %v", msgType, err)
+		}
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestWebRTCOffer_VoiceDisabled(t *testing.T) {
+	_, conn := startVoiceTestServer(t, false)
+	_, sdp := newBrowserOffer(t)
+
+	if err := conn.WriteJSON(PicoMessage{Type: TypeWebRTCOffer, Payload: map[string]any{"sdp": sdp}}); err != nil {
+		t.Fatalf("write offer: %v", err)
+	}
+
+	msg := readUntil(t, conn, TypeError)
+	if code, _ := msg.Payload["code"].(string); code != "voice_disabled" {
+		t.Fatalf("error code = %q, want voice_disabled", code)
+	}
+}
+
+func TestWebRTCOffer_AnswersAndHangsUp(t *testing.T) {
+	ch, conn := startVoiceTestServer(t, true)
+	browser, sdp := newBrowserOffer(t)
+
+	if err := conn.WriteJSON(PicoMessage{
+		Type:    TypeWebRTCOffer,
+		ID:      "offer-1",
+		Payload: map[string]any{"sdp": sdp},
+	}); err != nil {
+		t.Fatalf("write offer: %v", err)
+	}
+
+	answer := readUntil(t, conn, TypeWebRTCAnswer)
+	if answer.ID != "offer-1" {
+		t.Fatalf("answer id = %q, want offer-1", answer.ID)
+	}
+	answerSDP, _ := answer.Payload["sdp"].(string)
+	if !strings.Contains(answerSDP, "opus/48000/2") {
+		t.Fatalf("answer does not negotiate opus:\n%s", answerSDP)
+	}
+	if err := browser.SetRemoteDescription(webrtc.SessionDescription{
+		Type: webrtc.SDPTypeAnswer,
+		SDP:  answerSDP,
+	}); err != nil {
+		t.Fatalf("browser rejected answer: %v", err)
+	}
+
+	if calls := ch.sessionCalls("voice"); len(calls) != 1 {
+		t.Fatalf("sessionCalls = %d, want 1", len(calls))
+	}
+
+	if err := conn.WriteJSON(PicoMessage{Type: TypeWebRTCHangup}); err != nil {
+		t.Fatalf("write hangup: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(ch.sessionCalls("voice")) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("call still active after hangup")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebRTCCandidate_WithoutCall(t *testing.T) {
+	_, conn := startVoiceTestServer(t, true)
+
+	if err := conn.WriteJSON(PicoMessage{
+		Type:    TypeWebRTCCandidate,
+		Payload: map[string]any{"candidate": "candidate:1 1 udp 1 127.0.0.1 9 typ host"},
+	}); err != nil {
+		t.Fatalf("write candidate: %v", err)
+	}
+
+	msg := readUntil(t, conn, TypeError)
+	if code, _ := msg.Payload["code"].(string); code != "no_call" {
+		t.Fatalf("error code = %q, want no_call", code)
+	}
+}
@@ -617,7 +617,7 @@ func (c *QQChannel) handleC2CMessage() event.C2CMessageEventHandler {
 			CanonicalID: identity.BuildCanonicalID("qq", data.Author.ID),
 		}
 
-		if !c.IsAllowedSender(sender) {
+		if !c.IsAllowedMessage(sender, data.Content) {
 			return nil
 		}
 
@@ -686,7 +686,7 @@ func (c *QQChannel) handleGroupATMessage() event.GroupATMessageEventHandler {
 			CanonicalID: identity.BuildCanonicalID("qq", data.Author.ID),
 		}
 
-		if !c.IsAllowedSender(sender) {
+		if !c.IsAllowedMessage(sender, data.Content) {
 			return nil
 		}
 
@@ -0,0 +1,113 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// SendInteractive implements channels.InteractiveCapable with a Block Kit
+// message: the question as a section and the actions as buttons.
+func (c *SlackChannel) SendInteractive(ctx context.Context, msg bus.InteractiveMessage) (string, error) {
+	if !c.IsRunning() {
+		return "", channels.ErrNotRunning
+	}
+	channelID, threadTS := parseSlackChatID(msg.ChatID)
+	if channelID == "" {
+		return "", fmt.Errorf("invalid slack chat ID: %s", msg.ChatID)
+	}
+
+	buttons := make([]slack.BlockElement, 0, len(msg.Actions))
+	for _, a := range msg.Actions {
+		data := channels.EncodeActionData(msg.PromptID, a.ID)
+		label := slack.NewTextBlockObject(slack.PlainTextType, a.Label, false, false)
+		buttons = append(buttons, slack.NewButtonBlockElement(data, data, label))
+	}
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(msg.Content, false),
+		slack.MsgOptionBlocks(
+			questionBlock(msg.Content),
+			slack.NewActionBlock("pc:"+msg.PromptID, buttons...),
+		),
+	}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+
+	_, ts, err := c.api.PostMessageContext(ctx, channelID, opts...)
+	if err != nil {
+		return "", fmt.Errorf("slack send: %w", channels.ErrTemporary)
+	}
+	return ts, nil
+}
+
+func questionBlock(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
+
+func (c *SlackChannel) handleInteractive(event socketmode.Event) {
+	if event.Request != nil {
+		c.socketClient.Ack(*event.Request)
+	}
+	callback, ok := event.Data.(slack.InteractionCallback)
+	if !ok || callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+	c.handleBlockActions(c.ctx, callback)
+}
+
+// handleBlockActions turns a press on a SendInteractive button into an
+// inbound message and replaces the buttons with the choice so it cannot be
+// made twice.
+func (c *SlackChannel) handleBlockActions(ctx context.Context, callback slack.InteractionCallback) {
+	if len(callback.ActionCallback.BlockActions) == 0 {
+		return
+	}
+	action := callback.ActionCallback.BlockActions[0]
+
+	channelID := callback.Channel.ID
+	if channelID == "" {
+		channelID = callback.Container.ChannelID
+	}
+	chatID := channelID
+	if callback.Container.ThreadTs != "" {
+		chatID = channelID + "/" + callback.Container.ThreadTs
+	}
+	senderID := callback.User.ID
+	sender := bus.SenderInfo{
+		Platform:    "slack",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("slack", senderID),
+		Username:    callback.User.Name,
+	}
+	peer := bus.Peer{Kind: "channel", ID: channelID}
+	if len(channelID) > 0 && channelID[0] == 'D' {
+		peer = bus.Peer{Kind: "direct", ID: senderID}
+	}
+
+	if !c.HandleAction(ctx, peer, senderID, chatID, action.Value, action.Text.Text, sender) {
+		return
+	}
+
+	messageTS := callback.Container.MessageTs
+	if messageTS == "" {
+		messageTS = callback.Message.Timestamp
+	}
+	choice := fmt.Sprintf("%s\n*%s*", callback.Message.Text, action.Text.Text)
+	_, _, _, err := c.api.UpdateMessageContext(ctx, channelID, messageTS,
+		slack.MsgOptionText(choice, false),
+		slack.MsgOptionBlocks(questionBlock(choice)),
+	)
+	if err != nil {
+		logger.DebugCF("slack", "Failed to remove buttons", map[string]any{
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestSlackChannel(t *testing.T, msgBus *bus.MessageBus) (*SlackChannel, map[string]string) {
+	t.Helper()
+	var mu sync.Mutex
+	calls := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		mu.Lock()
+		calls[strings.TrimPrefix(r.URL.Path, "/")] = r.Form.Get("blocks")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"channel":"C1","ts":"111.222"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := config.SlackConfig{}
+	cfg.BotToken = *config.NewSecureString("xoxb-test")
+	cfg.AppToken = *config.NewSecureString("xapp-test")
+	ch, err := NewSlackChannel(cfg, msgBus)
+	if err != nil {
+		t.Fatalf("NewSlackChannel: %v", err)
+	}
+	ch.api = slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"))
+	ch.SetRunning(true)
+	return ch, calls
+}
+
+func TestSlackSendInteractive(t *testing.T) {
+	ch, calls := newTestSlackChannel(t, bus.NewMessageBus())
+
+	ts, err := ch.SendInteractive(context.Background(), bus.InteractiveMessage{
+		ChatID:   "C1/100.1",
+		Content:  "Deploy now?",
+		PromptID: "ab12",
+		Actions:  []bus.Action{{ID: "1", Label: "Yes"}, {ID: "2", Label: "No"}},
+	})
+	if err != nil {
+		t.Fatalf("SendInteractive: %v", err)
+	}
+	if ts != "111.222" {
+		t.Errorf("ts = %q, want 111.222", ts)
+	}
+	blocks := calls["chat.postMessage"]
+	data := channels.EncodeActionData("ab12", "2")
+	if !strings.Contains(blocks, `"type":"actions"`) || !strings.Contains(blocks, data) {
+		t.Errorf("unexpected blocks: %s", blocks)
+	}
+}
+
+func TestSlackHandleBlockActions(t *testing.T) {
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	ch, calls := newTestSlackChannel(t, msgBus)
+
+	data := channels.EncodeActionData("ab12", "1")
+	var callback slack.InteractionCallback
+	callback.Type = slack.InteractionTypeBlockActions
+	callback.User.ID = "U1"
+	callback.Channel.ID = "C1"
+	callback.Container = slack.Container{ChannelID: "C1", MessageTs: "111.222", ThreadTs: "100.1"}
+	callback.Message.Text = "Deploy now?"
+	callback.ActionCallback.BlockActions = []*slack.BlockAction{{
+		ActionID: data,
+		Value:    data,
+		Text:     slack.TextBlockObject{Type: slack.PlainTextType, Text: "Yes"},
+	}}
+	ch.handleBlockActions(context.Background(), callback)
+
+	msg := <-msgBus.InboundChan()
+	if msg.ChatID != "C1/100.1" || msg.PromptID != "ab12" || msg.ActionID != "1" || msg.Content != "Yes" {
+		t.Errorf("unexpected inbound message: %+v", msg)
+	}
+	if blocks, ok := calls["chat.update"]; !ok || strings.Contains(blocks, `"actions"`) {
+		t.Errorf("expected the buttons to be removed, got %q", blocks)
+	}
+}
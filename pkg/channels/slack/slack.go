@@ -122,6 +122,9 @@ func (c *SlackChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]str
 		slack.MsgOptionText(msg.Content, false),
 	}
 
+	if threadTS == "" {
+		threadTS = msg.ThreadID
+	}
 	if msg.ReplyToMessageID != "" && threadTS == "" {
 		// Answer to the message by creating a Thread under it
 		opts = append(opts, slack.MsgOptionTS(msg.ReplyToMessageID))
@@ -151,6 +154,16 @@ func (c *SlackChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]str
 	return []string{ts}, nil
 }
 
+// StartThread implements channels.ThreadStarter. A Slack thread is named
+// after the timestamp of its first message and exists once a reply is
+// posted under it.
+func (c *SlackChannel) StartThread(ctx context.Context, chatID, messageID, title string) (string, error) {
+	if _, threadTS := parseSlackChatID(chatID); threadTS != "" {
+		return threadTS, nil
+	}
+	return messageID, nil
+}
+
 // SendMedia implements the channels.MediaSender interface.
 func (c *SlackChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessage) ([]string, error) {
 	if !c.IsRunning() {
@@ -244,9 +257,7 @@ func (c *SlackChannel) eventLoop() {
 			case socketmode.EventTypeSlashCommand:
 				c.handleSlashCommand(event)
 			case socketmode.EventTypeInteractive:
-				if event.Request != nil {
-					c.socketClient.Ack(*event.Request)
-				}
+				c.handleInteractive(event)
 			}
 		}
 	}
@@ -287,7 +298,7 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 		PlatformID:  ev.User,
 		CanonicalID: identity.BuildCanonicalID("slack", ev.User),
 	}
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, ev.Text) {
 		logger.DebugCF("slack", "Message rejected by allowlist", map[string]any{
 			"user_id": ev.User,
 		})
@@ -368,6 +379,7 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 		"message_ts": messageTS,
 		"channel_id": channelID,
 		"thread_ts":  threadTS,
+		"thread_id":  threadTS,
 		"platform":   "slack",
 		"team_id":    c.teamID,
 	}
@@ -408,6 +420,9 @@ func (c *SlackChannel) handleAppMention(ev *slackevents.AppMentionEvent) {
 	threadTS := ev.ThreadTimeStamp
 	messageTS := ev.TimeStamp
 
+	// Mentions are answered in a thread, started under the mention when it
+	// is not in one yet. Only a mention inside a thread reports it, so a new
+	// mention stays in the session of the channel.
 	var chatID string
 	if threadTS != "" {
 		chatID = channelID + "/" + threadTS
@@ -439,6 +454,7 @@ func (c *SlackChannel) handleAppMention(ev *slackevents.AppMentionEvent) {
 		"message_ts": messageTS,
 		"channel_id": channelID,
 		"thread_ts":  threadTS,
+		"thread_id":  threadTS,
 		"platform":   "slack",
 		"is_mention": "true",
 		"team_id":    c.teamID,
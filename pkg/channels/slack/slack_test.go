@@ -1,6 +1,7 @@
 package slack
 
 import (
+	"context"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
@@ -53,6 +54,18 @@ func TestParseSlackChatID(t *testing.T) {
 	}
 }
 
+func TestStartThread(t *testing.T) {
+	ch := &SlackChannel{}
+	got, err := ch.StartThread(context.Background(), "C123456", "1700000000.000100", "")
+	if err != nil || got != "1700000000.000100" {
+		t.Errorf("StartThread in channel = %q, %v", got, err)
+	}
+	got, err = ch.StartThread(context.Background(), "C123456/1600000000.000100", "1700000000.000100", "")
+	if err != nil || got != "1600000000.000100" {
+		t.Errorf("StartThread in thread = %q, %v", got, err)
+	}
+}
+
 func TestStripBotMention(t *testing.T) {
 	ch := &SlackChannel{botUserID: "U12345BOT"}
 
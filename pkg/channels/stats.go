@@ -0,0 +1,45 @@
+package channels
+
+import "sync/atomic"
+
+// SendStats counts the outbound deliveries of one channel.
+type SendStats struct {
+	Sent       int64 // messages and media delivered
+	Failed     int64 // deliveries given up after retries or a permanent error
+	Retries    int64 // extra attempts after a failed send
+	QueueDepth int   // messages and media waiting in the channel's worker
+}
+
+type sendCounters struct {
+	sent    atomic.Int64
+	failed  atomic.Int64
+	retries atomic.Int64
+}
+
+func (m *Manager) sendCounters(name string) *sendCounters {
+	c, _ := m.sendStats.LoadOrStore(name, &sendCounters{})
+	return c.(*sendCounters)
+}
+
+// SendStats returns delivery counters and queue depth per channel.
+func (m *Manager) SendStats() map[string]SendStats {
+	out := make(map[string]SendStats)
+	m.sendStats.Range(func(key, value any) bool {
+		c := value.(*sendCounters)
+		out[key.(string)] = SendStats{
+			Sent:    c.sent.Load(),
+			Failed:  c.failed.Load(),
+			Retries: c.retries.Load(),
+		}
+		return true
+	})
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for name, w := range m.workers {
+		s := out[name]
+		s.QueueDepth = len(w.queue) + len(w.mediaQueue)
+		out[name] = s
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// minStreamEditInterval keeps streaming edits well below the channel rate
+// limit so that regular sends in other chats are not starved.
+const minStreamEditInterval = time.Second
+
+// EditStreamer streams partial output to channels that can edit messages but
+// have no native streaming API. It keeps rewriting the same message with the
+// text received so far and continues in a new message when the text outgrows
+// the channel's maximum message length, splitting with SplitMessage so code
+// blocks stay intact.
+//
+// Like the native streamers, it degrades on the first failed edit: Update
+// becomes a no-op and Finalize still delivers the complete text.
+type EditStreamer struct {
+	ch      Channel
+	editor  MessageEditor
+	name    string
+	chatID  string
+	maxLen  int
+	limiter *rate.Limiter
+	// interval is the minimum time between two updates.
+	interval time.Duration
+
+	mu     sync.Mutex
+	ids    []string // messages showing shown[i], oldest first
+	shown  []string
+	lastAt time.Time
+	failed bool
+	done   bool
+}
+
+// NewEditStreamer returns a streamer for chatID on ch, which must implement
+// MessageEditor. messageID is an existing message (usually the "Thinking…"
+// placeholder) to take over; when empty the first update sends a new
+// message. maxLen is in runes, 0 meaning no limit. limiter, if not nil, is
+// the channel's rate limiter shared with regular sends.
+func NewEditStreamer(
+	ch Channel,
+	chatID, messageID string,
+	maxLen int,
+	limiter *rate.Limiter,
+) (*EditStreamer, error) {
+	editor, ok := ch.(MessageEditor)
+	if !ok {
+		return nil, errors.New("channel cannot edit messages")
+	}
+	s := &EditStreamer{
+		ch:      ch,
+		editor:  editor,
+		name:    ch.Name(),
+		chatID:  chatID,
+		maxLen:  maxLen,
+		limiter: limiter,
+
+		interval: minStreamEditInterval,
+	}
+	if messageID != "" {
+		s.ids = []string{messageID}
+		s.shown = []string{""}
+	}
+	return s, nil
+}
+
+// Update shows content, the full text so far. Updates that arrive faster
+// than the rate limit allows are dropped; the next one catches up.
+func (s *EditStreamer) Update(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done || s.failed || strings.TrimSpace(content) == "" {
+		return nil
+	}
+	if time.Since(s.lastAt) < s.interval {
+		return nil
+	}
+	if s.limiter != nil && s.limiter.Tokens() < 1 {
+		return nil
+	}
+	s.lastAt = time.Now()
+
+	if err := s.sync(ctx, content, false); err != nil {
+		logger.WarnCF("channels", "Streaming edit failed, disabling streaming", map[string]any{
+			"channel": s.name,
+			"chat_id": s.chatID,
+			"error":   err.Error(),
+		})
+		s.failed = true
+	}
+	return nil
+}
+
+// Finalize shows the complete content. A message whose edit fails is sent
+// again as a new message. If delivery fails, messages beyond the first are
+// deleted so the caller can deliver content another way.
+func (s *EditStreamer) Finalize(ctx context.Context, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done {
+		return nil
+	}
+	s.done = true
+	if err := s.sync(ctx, content, true); err != nil {
+		s.dropRollover(ctx)
+		return err
+	}
+	return nil
+}
+
+// Cancel stops the stream. Messages beyond the first are deleted when the
+// channel supports it; the first one is left for MessageID's caller.
+func (s *EditStreamer) Cancel(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.done = true
+	s.dropRollover(ctx)
+}
+
+func (s *EditStreamer) dropRollover(ctx context.Context) {
+	deleter, ok := s.ch.(MessageDeleter)
+	if !ok || len(s.ids) < 2 {
+		return
+	}
+	for _, id := range s.ids[1:] {
+		if id != "" {
+			_ = deleter.DeleteMessage(ctx, s.chatID, id) // best effort
+		}
+	}
+	s.ids, s.shown = s.ids[:1], s.shown[:1]
+}
+
+// MessageID returns the first message of the stream, or "" if nothing has
+// been shown yet.
+func (s *EditStreamer) MessageID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.ids) == 0 {
+		return ""
+	}
+	return s.ids[0]
+}
+
+// sync makes the stream's messages show content. Content only grows, so
+// earlier chunks rarely change and are edited only when they do.
+func (s *EditStreamer) sync(ctx context.Context, content string, final bool) error {
+	for i, chunk := range splitByLength(content, s.maxLen) {
+		if i < len(s.ids) {
+			if s.shown[i] == chunk {
+				continue
+			}
+			if s.ids[i] == "" {
+				// Sent, but the channel gave no ID to edit.
+				if !final {
+					return errors.New("channel did not return a message ID to edit")
+				}
+			} else {
+				if err := s.wait(ctx); err != nil {
+					return err
+				}
+				err := s.editor.EditMessage(ctx, s.chatID, s.ids[i], chunk)
+				if err == nil {
+					s.shown[i] = chunk
+					continue
+				}
+				if !final {
+					return err
+				}
+				logger.WarnCF("channels", "Streaming edit failed, sending as a new message", map[string]any{
+					"channel": s.name,
+					"chat_id": s.chatID,
+					"error":   err.Error(),
+				})
+			}
+		}
+
+		if err := s.wait(ctx); err != nil {
+			return err
+		}
+		ids, err := s.ch.Send(ctx, bus.OutboundMessage{Channel: s.name, ChatID: s.chatID, Content: chunk})
+		if err != nil {
+			return err
+		}
+		id := ""
+		if len(ids) > 0 {
+			id = ids[0]
+		}
+		if i < len(s.ids) {
+			s.ids[i], s.shown[i] = id, chunk
+		} else {
+			s.ids = append(s.ids, id)
+			s.shown = append(s.shown, chunk)
+		}
+	}
+	return nil
+}
+
+func (s *EditStreamer) wait(ctx context.Context) error {
+	if s.limiter == nil {
+		return nil
+	}
+	return s.limiter.Wait(ctx)
+}
@@ -0,0 +1,240 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// editingChannel keeps the messages it has sent so tests can inspect what a
+// chat would show.
+type editingChannel struct {
+	BaseChannel
+	order    []string
+	messages map[string]string
+	edits    int
+	editErr  error
+}
+
+func newEditingChannel(maxLen int) *editingChannel {
+	return &editingChannel{
+		BaseChannel: *NewBaseChannel("edit", nil, nil, []string{"*"}, WithMaxMessageLength(maxLen)),
+		messages:    map[string]string{},
+	}
+}
+
+func (c *editingChannel) Start(ctx context.Context) error { return nil }
+func (c *editingChannel) Stop(ctx context.Context) error  { return nil }
+
+func (c *editingChannel) Send(_ context.Context, msg bus.OutboundMessage) ([]string, error) {
+	id := fmt.Sprintf("m%d", len(c.order)+1)
+	c.order = append(c.order, id)
+	c.messages[id] = msg.Content
+	return []string{id}, nil
+}
+
+func (c *editingChannel) EditMessage(_ context.Context, _, messageID, content string) error {
+	if c.editErr != nil {
+		return c.editErr
+	}
+	c.edits++
+	c.messages[messageID] = content
+	return nil
+}
+
+func (c *editingChannel) DeleteMessage(_ context.Context, _, messageID string) error {
+	delete(c.messages, messageID)
+	for i, id := range c.order {
+		if id == messageID {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// shown returns the chat's messages in order.
+func (c *editingChannel) shown() []string {
+	out := make([]string, 0, len(c.order))
+	for _, id := range c.order {
+		out = append(out, c.messages[id])
+	}
+	return out
+}
+
+// sendOnlyChannel can neither edit nor stream.
+type sendOnlyChannel struct{ BaseChannel }
+
+func (c *sendOnlyChannel) Start(ctx context.Context) error { return nil }
+func (c *sendOnlyChannel) Stop(ctx context.Context) error  { return nil }
+
+func (c *sendOnlyChannel) Send(context.Context, bus.OutboundMessage) ([]string, error) {
+	return nil, nil
+}
+
+func newTestEditStreamer(t *testing.T, ch *editingChannel, placeholderID string) *EditStreamer {
+	t.Helper()
+	s, err := NewEditStreamer(ch, "chat", placeholderID, ch.MaxMessageLength(), rate.NewLimiter(rate.Inf, 1))
+	if err != nil {
+		t.Fatalf("NewEditStreamer: %v", err)
+	}
+	s.interval = 0
+	return s
+}
+
+func TestEditStreamer_EditsPlaceholder(t *testing.T) {
+	ch := newEditingChannel(0)
+	ch.order = []string{"ph"}
+	ch.messages["ph"] = "Thinking..."
+	s := newTestEditStreamer(t, ch, "ph")
+	ctx := context.Background()
+
+	s.Update(ctx, "Hello")
+	s.Update(ctx, "Hello")
+	s.Update(ctx, "Hello, wor")
+	if err := s.Finalize(ctx, "Hello, world"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	if got := ch.shown(); len(got) != 1 || got[0] != "Hello, world" {
+		t.Fatalf("shown = %q, want the placeholder edited to the final text", got)
+	}
+	if ch.edits != 3 {
+		t.Fatalf("edits = %d, want 3 (unchanged text is not re-sent)", ch.edits)
+	}
+	if s.MessageID() != "ph" {
+		t.Fatalf("MessageID = %q, want ph", s.MessageID())
+	}
+}
+
+func TestEditStreamer_RollsOverLongContent(t *testing.T) {
+	ch := newEditingChannel(60)
+	s := newTestEditStreamer(t, ch, "")
+	ctx := context.Background()
+
+	intro := strings.Repeat("intro ", 5)
+	code := "```go\n" + strings.Repeat("x := 1\n", 8) + "```"
+	s.Update(ctx, intro)
+	s.Update(ctx, intro+"\n"+code[:30])
+	full := intro + "\n" + code + "\nDone."
+	if err := s.Finalize(ctx, full); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	got := ch.shown()
+	want := SplitMessage(full, 60)
+	if len(got) < 2 || strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("shown = %q, want %q", got, want)
+	}
+	for _, msg := range got {
+		if strings.Count(msg, "```")%2 != 0 {
+			t.Fatalf("message %q leaves a code block open", msg)
+		}
+	}
+}
+
+func TestEditStreamer_ThrottlesUpdates(t *testing.T) {
+	ch := newEditingChannel(0)
+	s, err := NewEditStreamer(ch, "chat", "", 0, nil)
+	if err != nil {
+		t.Fatalf("NewEditStreamer: %v", err)
+	}
+	ctx := context.Background()
+
+	s.Update(ctx, "a")
+	s.Update(ctx, "ab")
+	s.Update(ctx, "abc")
+	if got := ch.shown(); len(got) != 1 || got[0] != "a" || ch.edits != 0 {
+		t.Fatalf("shown = %q with %d edits, want only the first update", got, ch.edits)
+	}
+	if err := s.Finalize(ctx, "abcd"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+	if got := ch.shown(); len(got) != 1 || got[0] != "abcd" {
+		t.Fatalf("shown = %q, want Finalize to bypass the throttle", got)
+	}
+}
+
+func TestEditStreamer_DegradesOnEditError(t *testing.T) {
+	ch := newEditingChannel(0)
+	ch.order = []string{"ph"}
+	ch.messages["ph"] = "Thinking..."
+	ch.editErr = errors.New("message is too old")
+	s := newTestEditStreamer(t, ch, "ph")
+	ctx := context.Background()
+
+	s.Update(ctx, "partial")
+	s.Update(ctx, "partial answer")
+	if err := s.Finalize(ctx, "full answer"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+	if got := ch.shown(); len(got) != 2 || got[1] != "full answer" {
+		t.Fatalf("shown = %q, want the final text sent as a new message", got)
+	}
+}
+
+func TestNewEditStreamer_RequiresEditor(t *testing.T) {
+	if _, err := NewEditStreamer(&sendOnlyChannel{}, "chat", "", 0, nil); err == nil {
+		t.Fatal("expected an error for a channel without EditMessage")
+	}
+}
+
+func TestGetStreamer_EditFallback(t *testing.T) {
+	m := newTestManager()
+	ch := newEditingChannel(0)
+	m.channels["edit"] = ch
+	ch.order = []string{"ph"}
+	ch.messages["ph"] = "Thinking..."
+	m.RecordPlaceholder("edit", "chat", "ph")
+	ctx := context.Background()
+
+	streamer, ok := m.GetStreamer(ctx, "edit", "chat")
+	if !ok {
+		t.Fatal("expected a streamer for an editor-capable channel")
+	}
+	if err := streamer.Finalize(ctx, "answer"); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+
+	msg := bus.OutboundMessage{Channel: "edit", ChatID: "chat", Content: "answer"}
+	if _, handled := m.preSend(ctx, "edit", msg, ch); !handled {
+		t.Fatal("expected preSend to skip the send after a finalized stream")
+	}
+	if got := ch.shown(); len(got) != 1 || got[0] != "answer" {
+		t.Fatalf("shown = %q, want the placeholder to hold the answer", got)
+	}
+}
+
+func TestGetStreamer_EditFallbackCancelHandsBackMessage(t *testing.T) {
+	m := newTestManager()
+	ch := newEditingChannel(0)
+	m.channels["edit"] = ch
+	m.RecordPlaceholder("edit", "chat", "ph")
+	ctx := context.Background()
+
+	streamer, ok := m.GetStreamer(ctx, "edit", "chat")
+	if !ok {
+		t.Fatal("expected a streamer for an editor-capable channel")
+	}
+	streamer.Cancel(ctx)
+
+	msg := bus.OutboundMessage{Channel: "edit", ChatID: "chat", Content: "answer"}
+	ids, handled := m.preSend(ctx, "edit", msg, ch)
+	if !handled || len(ids) != 1 || ids[0] != "ph" {
+		t.Fatalf("preSend = %v, %v; want the placeholder edited", ids, handled)
+	}
+}
+
+func TestGetStreamer_NoEditor(t *testing.T) {
+	m := newTestManager()
+	m.channels["plain"] = &sendOnlyChannel{}
+	if _, ok := m.GetStreamer(context.Background(), "plain", "chat"); ok {
+		t.Fatal("expected no streamer for a channel that cannot edit messages")
+	}
+}
@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxButtonsPerRow keeps short choices like "Yes / No / Later" on one row;
+// longer lists get one button per row.
+const maxButtonsPerRow = 3
+
+// SendInteractive implements channels.InteractiveCapable with an inline
+// keyboard.
+func (c *TelegramChannel) SendInteractive(ctx context.Context, msg bus.InteractiveMessage) (string, error) {
+	if !c.IsRunning() {
+		return "", channels.ErrNotRunning
+	}
+	chatID, threadID, err := parseTelegramChatID(msg.ChatID)
+	if err != nil {
+		return "", fmt.Errorf("invalid chat ID %s: %w", msg.ChatID, channels.ErrSendFailed)
+	}
+
+	buttons := make([]telego.InlineKeyboardButton, 0, len(msg.Actions))
+	for _, a := range msg.Actions {
+		buttons = append(buttons,
+			tu.InlineKeyboardButton(a.Label).WithCallbackData(channels.EncodeActionData(msg.PromptID, a.ID)))
+	}
+	var rows [][]telego.InlineKeyboardButton
+	if len(buttons) <= maxButtonsPerRow {
+		rows = [][]telego.InlineKeyboardButton{buttons}
+	} else {
+		rows = tu.InlineKeyboardCols(1, buttons...)
+	}
+
+	tgMsg := tu.Message(tu.ID(chatID), msg.Content).WithReplyMarkup(tu.InlineKeyboard(rows...))
+	tgMsg.MessageThreadID = threadID
+	sent, err := c.bot.SendMessage(ctx, tgMsg)
+	if err != nil {
+		return "", fmt.Errorf("telegram send: %w", channels.ErrTemporary)
+	}
+	return strconv.Itoa(sent.MessageID), nil
+}
+
+// handleCallbackQuery turns a press on a SendInteractive button into an
+// inbound message and removes the keyboard so the choice cannot be made
+// twice.
+func (c *TelegramChannel) handleCallbackQuery(ctx context.Context, query *telego.CallbackQuery) error {
+	// Always answer, or the client keeps showing a spinner on the button.
+	defer func() {
+		_ = c.bot.AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID))
+	}()
+
+	if query.Message == nil {
+		return nil
+	}
+	message := query.Message.Message()
+	if message == nil {
+		return nil
+	}
+
+	platformID := fmt.Sprintf("%d", query.From.ID)
+	sender := bus.SenderInfo{
+		Platform:    "telegram",
+		PlatformID:  platformID,
+		CanonicalID: identity.BuildCanonicalID("telegram", platformID),
+		Username:    query.From.Username,
+		DisplayName: query.From.FirstName,
+	}
+
+	compositeChatID := fmt.Sprintf("%d", message.Chat.ID)
+	if message.Chat.IsForum && message.MessageThreadID != 0 {
+		compositeChatID = fmt.Sprintf("%d/%d", message.Chat.ID, message.MessageThreadID)
+	}
+	peer := bus.Peer{Kind: "direct", ID: platformID}
+	if message.Chat.Type != "private" {
+		peer = bus.Peer{Kind: "group", ID: fmt.Sprintf("%d", message.Chat.ID)}
+	}
+
+	label := ""
+	if message.ReplyMarkup != nil {
+		for _, row := range message.ReplyMarkup.InlineKeyboard {
+			for _, button := range row {
+				if button.CallbackData == query.Data {
+					label = button.Text
+				}
+			}
+		}
+	}
+
+	if !c.HandleAction(ctx, peer, platformID, compositeChatID, query.Data, label, sender) {
+		return nil
+	}
+
+	_, err := c.bot.EditMessageReplyMarkup(ctx, &telego.EditMessageReplyMarkupParams{
+		ChatID:    tu.ID(message.Chat.ID),
+		MessageID: message.MessageID,
+	})
+	if err != nil {
+		logger.DebugCF("telegram", "Failed to remove inline keyboard", map[string]any{
+			"error": err.Error(),
+		})
+	}
+	return nil
+}
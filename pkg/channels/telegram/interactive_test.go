@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+func TestSendInteractive_InlineKeyboard(t *testing.T) {
+	caller := &stubCaller{
+		callFn: func(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			return successResponse(t), nil
+		},
+	}
+	ch := newTestChannel(t, caller)
+
+	id, err := ch.SendInteractive(context.Background(), bus.InteractiveMessage{
+		ChatID:   "-100/7",
+		Content:  "Deploy now?",
+		PromptID: "ab12",
+		Actions:  []bus.Action{{ID: "1", Label: "Yes"}, {ID: "2", Label: "No"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1", id)
+
+	require.Len(t, caller.calls, 1)
+	assert.Contains(t, caller.calls[0].URL, "sendMessage")
+	var sent struct {
+		Text            string                      `json:"text"`
+		MessageThreadID int                         `json:"message_thread_id"`
+		ReplyMarkup     telego.InlineKeyboardMarkup `json:"reply_markup"`
+	}
+	require.NoError(t, json.Unmarshal(caller.calls[0].Data.BodyRaw, &sent))
+	assert.Equal(t, "Deploy now?", sent.Text)
+	assert.Equal(t, 7, sent.MessageThreadID)
+	require.Len(t, sent.ReplyMarkup.InlineKeyboard, 1)
+	require.Len(t, sent.ReplyMarkup.InlineKeyboard[0], 2)
+	assert.Equal(t, channels.EncodeActionData("ab12", "2"), sent.ReplyMarkup.InlineKeyboard[0][1].CallbackData)
+}
+
+func TestHandleCallbackQuery_PublishesAction(t *testing.T) {
+	caller := &stubCaller{
+		callFn: func(ctx context.Context, url string, data *ta.RequestData) (*ta.Response, error) {
+			return &ta.Response{Ok: true, Result: json.RawMessage("true")}, nil
+		},
+	}
+	ch := newTestChannel(t, caller)
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch.BaseChannel = channels.NewBaseChannel("telegram", nil, mb, []string{"*"})
+
+	data := channels.EncodeActionData("ab12", "2")
+	err := ch.handleCallbackQuery(context.Background(), &telego.CallbackQuery{
+		ID:   "q1",
+		From: telego.User{ID: 42, FirstName: "Ann"},
+		Data: data,
+		Message: &telego.Message{
+			MessageID: 9,
+			Chat:      telego.Chat{ID: 42, Type: "private"},
+			ReplyMarkup: &telego.InlineKeyboardMarkup{InlineKeyboard: [][]telego.InlineKeyboardButton{{
+				{Text: "Yes", CallbackData: channels.EncodeActionData("ab12", "1")},
+				{Text: "No", CallbackData: data},
+			}}},
+		},
+	})
+	require.NoError(t, err)
+
+	msg := <-mb.InboundChan()
+	assert.Equal(t, "42", msg.ChatID)
+	assert.Equal(t, "ab12", msg.PromptID)
+	assert.Equal(t, "2", msg.ActionID)
+	assert.Equal(t, "No", msg.Content)
+	assert.Equal(t, "direct", msg.Peer.Kind)
+
+	var urls []string
+	for _, call := range caller.calls {
+		urls = append(urls, call.URL)
+	}
+	joined := strings.Join(urls, " ")
+	assert.Contains(t, joined, "editMessageReplyMarkup")
+	assert.Contains(t, joined, "answerCallbackQuery")
+}
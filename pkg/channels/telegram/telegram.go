@@ -130,6 +130,9 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 	bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
 		return c.handleMessage(ctx, &message)
 	}, th.AnyMessage())
+	bh.HandleCallbackQuery(func(ctx *th.Context, query telego.CallbackQuery) error {
+		return c.handleCallbackQuery(ctx, &query)
+	}, th.AnyCallbackQueryWithMessage())
 
 	c.SetRunning(true)
 	logger.InfoCF("telegram", "Telegram bot connected", map[string]any{
@@ -180,6 +183,11 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) ([]
 	if err != nil {
 		return nil, fmt.Errorf("invalid chat ID %s: %w", msg.ChatID, channels.ErrSendFailed)
 	}
+	if threadID == 0 && msg.ThreadID != "" {
+		if threadID, err = strconv.Atoi(msg.ThreadID); err != nil {
+			return nil, fmt.Errorf("invalid thread ID %s: %w", msg.ThreadID, channels.ErrSendFailed)
+		}
+	}
 
 	if msg.Content == "" {
 		return nil, nil
@@ -593,7 +601,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 	}
 
 	// check allowlist to avoid downloading attachments for rejected users
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, message.Text) {
 		logger.DebugCF("telegram", "Message rejected by allowlist", map[string]any{
 			"user_id": platformID,
 		})
@@ -721,9 +729,9 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 	}
 
 	// For forum topics, embed the thread ID as "chatID/threadID" so replies
-	// route to the correct topic and each topic gets its own session.
-	// Only forum groups (IsForum) are handled; regular group reply threads
-	// must share one session per group.
+	// route to the correct topic and each topic gets its own session, as
+	// the peer names it. Only forum groups (IsForum) are handled; regular
+	// group reply threads must share one session per group.
 	compositeChatID := fmt.Sprintf("%d", chatID)
 	threadID := message.MessageThreadID
 	if message.Chat.IsForum && threadID != 0 {
@@ -759,6 +767,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 
 	// Set parent_peer metadata for per-topic agent binding.
 	if message.Chat.IsForum && threadID != 0 {
+		metadata["thread_id"] = fmt.Sprintf("%d", threadID)
 		metadata["parent_peer_kind"] = "topic"
 		metadata["parent_peer_id"] = fmt.Sprintf("%d", threadID)
 	}
@@ -562,6 +562,7 @@ func TestHandleMessage_ForumTopic_SetsMetadata(t *testing.T) {
 	// Peer ID should include thread ID for session key isolation
 	assert.Equal(t, "group", inbound.Peer.Kind)
 	assert.Equal(t, "-1001234567890/42", inbound.Peer.ID)
+	assert.Equal(t, "42", inbound.ThreadID)
 
 	// Parent peer metadata should be set for agent binding
 	assert.Equal(t, "topic", inbound.Metadata["parent_peer_kind"])
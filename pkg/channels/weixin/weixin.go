@@ -327,7 +327,7 @@ func (c *WeixinChannel) handleInboundMessage(ctx context.Context, msg WeixinMess
 		DisplayName: fromUserID,
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		logger.DebugCF("weixin", "Message rejected by allowlist", map[string]any{
 			"from_user_id": fromUserID,
 		})
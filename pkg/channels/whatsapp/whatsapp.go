@@ -244,7 +244,7 @@ func (c *WhatsAppChannel) handleIncomingMessage(msg map[string]any) {
 		sender.DisplayName = display
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		return
 	}
 
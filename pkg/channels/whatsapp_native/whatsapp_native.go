@@ -384,7 +384,7 @@ func (c *WhatsAppNativeChannel) handleIncoming(evt *events.Message) {
 		DisplayName: evt.Info.PushName,
 	}
 
-	if !c.IsAllowedSender(sender) {
+	if !c.IsAllowedMessage(sender, content) {
 		return
 	}
 
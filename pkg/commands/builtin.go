@@ -11,10 +11,22 @@ func BuiltinDefinitions() []Definition {
 		showCommand(),
 		listCommand(),
 		useCommand(),
+		pinCommand(),
+		unuseCommand(),
 		switchCommand(),
 		checkCommand(),
 		clearCommand(),
+		rewindCommand(),
+		forkCommand(),
+		exportCommand(),
+		importCommand(),
+		memoryCommand(),
 		subagentsCommand(),
+		tasksCommand(),
+		backCommand(),
+		linkCommand(),
+		unlinkCommand(),
+		runCommand(),
 		reloadCommand(),
 	}
 }
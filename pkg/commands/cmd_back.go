@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// HandoffInfo describes a conversation handed from the routed agent to
+// another agent.
+type HandoffInfo struct {
+	From  string
+	To    string
+	Until time.Time // zero until /back
+}
+
+func (h HandoffInfo) String() string {
+	s := fmt.Sprintf("%s -> %s", h.From, h.To)
+	if h.Until.IsZero() {
+		return s + " (until /back)"
+	}
+	return s + " (until " + h.Until.Format("15:04") + ")"
+}
+
+func backCommand() Definition {
+	return Definition{
+		Name:        "back",
+		Description: "Return a handed-off conversation to the original agent (handoffs also end on a gateway restart)",
+		Usage:       "/back",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.EndHandoff == nil {
+				return req.Reply(unavailableMsg)
+			}
+			h, ok := rt.EndHandoff()
+			if !ok {
+				return req.Reply("No handoff is active in this chat.")
+			}
+			return req.Reply(fmt.Sprintf("You are back with agent '%s'.", h.From))
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LinkCodeLength is the number of characters in a /link code.
+const LinkCodeLength = 10
+
+// LinkCode returns the code of a "/link <code>" message, upper-cased.
+func LinkCode(input string) (string, bool) {
+	if name, ok := parseCommandName(input); !ok || name != "link" {
+		return "", false
+	}
+	code := nthToken(input, 1)
+	if len(code) != LinkCodeLength || nthToken(input, 2) != "" {
+		return "", false
+	}
+	for _, r := range code {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return "", false
+		}
+	}
+	return strings.ToUpper(code), true
+}
+
+func linkCommand() Definition {
+	return Definition{
+		Name:        "link",
+		Description: "Link your accounts on different channels",
+		Usage:       "/link [code | confirm <account>]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.IssueLinkCode == nil || rt.RedeemLinkCode == nil || rt.ConfirmLink == nil {
+				return req.Reply(unavailableMsg)
+			}
+			if strings.EqualFold(nthToken(req.Text, 1), "confirm") {
+				claimant := nthToken(req.Text, 2)
+				if claimant == "" {
+					return req.Reply("Usage: /link confirm <account>")
+				}
+				linked, err := rt.ConfirmLink(claimant)
+				if err != nil {
+					return req.Reply(fmt.Sprintf("Could not link: %v", err))
+				}
+				return req.Reply("Linked accounts:\n- " + strings.Join(linked, "\n- "))
+			}
+			if arg := nthToken(req.Text, 1); arg != "" {
+				code, ok := LinkCode(req.Text)
+				if !ok {
+					return req.Reply("Usage: /link [code | confirm <account>]")
+				}
+				issuer, err := rt.RedeemLinkCode(code)
+				if err != nil {
+					return req.Reply(fmt.Sprintf("Could not link: %v", err))
+				}
+				return req.Reply(fmt.Sprintf(
+					"To finish, send /link confirm %s from %s.", req.SenderID, issuer))
+			}
+
+			code, ttl, err := rt.IssueLinkCode()
+			if err != nil {
+				return req.Reply(fmt.Sprintf("Could not create a link code: %v", err))
+			}
+			return req.Reply(fmt.Sprintf(
+				"Send /link %s from your account on another channel within %d minutes, "+
+					"then confirm here with the command it gives you. Do not share the code.",
+				code, int(ttl/time.Minute)))
+		},
+	}
+}
+
+func unlinkCommand() Definition {
+	return Definition{
+		Name:        "unlink",
+		Description: "Unlink this account from your other channels",
+		Usage:       "/unlink",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.UnlinkIdentity == nil {
+				return req.Reply(unavailableMsg)
+			}
+			removed, err := rt.UnlinkIdentity()
+			switch {
+			case err != nil:
+				return req.Reply(fmt.Sprintf("Could not unlink: %v", err))
+			case !removed:
+				return req.Reply("This account is not linked.")
+			}
+			return req.Reply("This account is no longer linked to your other channels.")
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkCode(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+		ok    bool
+	}{
+		{"/link K7M2QX9WHP", "K7M2QX9WHP", true},
+		{"/link@picobot k7m2qx9whp", "K7M2QX9WHP", true},
+		{"!link K7M2QX9WHP", "K7M2QX9WHP", true},
+		{"/link", "", false},
+		{"/link K7M2QX9WH", "", false},
+		{"/link K7M2QX9WH!", "", false},
+		{"/link K7M2QX9WHP extra", "", false},
+		{"/link confirm", "", false},
+		{"/unlink K7M2QX9WHP", "", false},
+	}
+	for _, tt := range tests {
+		code, ok := LinkCode(tt.input)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("LinkCode(%q) = %q, %v; want %q, %v", tt.input, code, ok, tt.code, tt.ok)
+		}
+	}
+}
+
+func TestLinkCommand(t *testing.T) {
+	var redeemed, confirmed string
+	rt := &Runtime{
+		IssueLinkCode: func() (string, time.Duration, error) {
+			return "K7M2QX9WHP", 10 * time.Minute, nil
+		},
+		RedeemLinkCode: func(code string) (string, error) {
+			redeemed = code
+			return "telegram:123", nil
+		},
+		ConfirmLink: func(claimant string) ([]string, error) {
+			confirmed = claimant
+			return []string{"discord:456", "telegram:123"}, nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	run := func(sender, text string) string {
+		var reply string
+		ex.Execute(context.Background(), Request{
+			SenderID: sender,
+			Text:     text,
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		return reply
+	}
+
+	reply := run("telegram:123", "/link")
+	if !strings.Contains(reply, "/link K7M2QX9WHP") || !strings.Contains(reply, "10 minutes") {
+		t.Fatalf("/link reply = %q", reply)
+	}
+	reply = run("discord:456", "/link k7m2qx9whp")
+	if redeemed != "K7M2QX9WHP" || !strings.Contains(reply, "/link confirm discord:456 from telegram:123") {
+		t.Fatalf("/link <code> reply = %q, redeemed = %q", reply, redeemed)
+	}
+	reply = run("telegram:123", "/link confirm discord:456")
+	if confirmed != "discord:456" || !strings.Contains(reply, "discord:456") {
+		t.Fatalf("/link confirm reply = %q, confirmed = %q", reply, confirmed)
+	}
+	if reply := run("telegram:123", "/link abc"); !strings.HasPrefix(reply, "Usage:") {
+		t.Fatalf("/link abc reply = %q", reply)
+	}
+	if reply := run("telegram:123", "/link confirm"); !strings.HasPrefix(reply, "Usage:") {
+		t.Fatalf("/link confirm reply = %q", reply)
+	}
+}
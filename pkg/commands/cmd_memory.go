@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FactInfo is a mirrored view of a fact in long-term memory, to avoid a
+// dependency on the facts package.
+type FactInfo struct {
+	ID           string
+	Text         string
+	Key          string
+	Kind         string
+	Confidence   float64
+	Source       string
+	UpdatedAt    time.Time
+	SupersededBy string
+}
+
+const maxListedFacts = 30
+
+func memoryCommand() Definition {
+	return Definition{
+		Name:        "memory",
+		Description: "Review what the assistant remembers about you",
+		SubCommands: []SubCommand{
+			{
+				Name:        "list",
+				Description: "Remembered facts, newest first",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListFacts == nil {
+						return req.Reply(unavailableMsg)
+					}
+					facts := rt.ListFacts(false)
+					if len(facts) == 0 {
+						return req.Reply("Nothing remembered yet.")
+					}
+					return req.Reply(formatFacts(fmt.Sprintf("Remembered facts (%d):", len(facts)), facts))
+				},
+			},
+			{
+				Name:        "search",
+				Description: "Facts relevant to a query",
+				ArgsUsage:   "<query>",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.SearchFacts == nil {
+						return req.Reply(unavailableMsg)
+					}
+					query := commandArgsAfter(req.Text, 2)
+					if query == "" {
+						return req.Reply("Usage: /memory search <query>")
+					}
+					facts := rt.SearchFacts(query)
+					if len(facts) == 0 {
+						return req.Reply("No remembered facts match.")
+					}
+					return req.Reply(formatFacts("Matching facts:", facts))
+				},
+			},
+			{
+				Name:        "history",
+				Description: "Facts that were replaced by newer ones",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListFacts == nil {
+						return req.Reply(unavailableMsg)
+					}
+					var replaced []FactInfo
+					for _, f := range rt.ListFacts(true) {
+						if f.SupersededBy != "" {
+							replaced = append(replaced, f)
+						}
+					}
+					if len(replaced) == 0 {
+						return req.Reply("No facts have been replaced.")
+					}
+					return req.Reply(formatFacts("Replaced facts:", replaced))
+				},
+			},
+			{
+				Name:        "forget",
+				Description: "Delete a fact",
+				ArgsUsage:   "<id>",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ForgetFact == nil {
+						return req.Reply(unavailableMsg)
+					}
+					id := strings.Trim(nthToken(req.Text, 2), "[]")
+					if id == "" {
+						return req.Reply("Usage: /memory forget <id>")
+					}
+					f, ok, err := rt.ForgetFact(id)
+					switch {
+					case err != nil:
+						return req.Reply("Failed to forget: " + err.Error())
+					case !ok:
+						return req.Reply(fmt.Sprintf("No fact %s. Use /memory list to see fact IDs.", id))
+					}
+					return req.Reply(fmt.Sprintf("Forgot: %s", f.Text))
+				},
+			},
+		},
+	}
+}
+
+func formatFacts(title string, facts []FactInfo) string {
+	var sb strings.Builder
+	sb.WriteString(title)
+	for i, f := range facts {
+		if i == maxListedFacts {
+			fmt.Fprintf(&sb, "\n… and %d more", len(facts)-maxListedFacts)
+			break
+		}
+		fmt.Fprintf(&sb, "\n- [%s] %s (%s", f.ID, f.Text, f.Kind)
+		if f.Confidence < 1 {
+			fmt.Fprintf(&sb, ", %.0f%%", f.Confidence*100)
+		}
+		fmt.Fprintf(&sb, ", %s)", f.UpdatedAt.Format("2006-01-02"))
+		if f.SupersededBy != "" {
+			fmt.Fprintf(&sb, " → replaced by [%s]", f.SupersededBy)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryCommand(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	stored := []FactInfo{
+		{ID: "a1", Text: "The user lives in Porto.", Kind: "fact", Confidence: 1, UpdatedAt: updated},
+		{ID: "b2", Text: "The user likes jazz.", Kind: "preference", Confidence: 0.8, UpdatedAt: updated},
+		{
+			ID: "c3", Text: "The user lives in Lisbon.", Kind: "fact", Confidence: 1,
+			UpdatedAt: updated, SupersededBy: "a1",
+		},
+	}
+	var query string
+	rt := &Runtime{
+		ListFacts: func(all bool) []FactInfo {
+			if all {
+				return stored
+			}
+			return stored[:2]
+		},
+		SearchFacts: func(q string) []FactInfo {
+			query = q
+			return stored[1:2]
+		},
+		ForgetFact: func(id string) (FactInfo, bool, error) {
+			switch id {
+			case "a1":
+				return stored[0], true, nil
+			case "broken":
+				return FactInfo{}, false, errors.New("disk full")
+			}
+			return FactInfo{}, false, nil
+		},
+	}
+
+	reply := runSessionCommand(rt, "/memory list")
+	if !strings.HasPrefix(reply, "Remembered facts (2):") ||
+		!strings.Contains(reply, "[b2] The user likes jazz. (preference, 80%, 2026-03-01)") {
+		t.Fatalf("/memory list reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/memory search music I like"); query != "music I like" ||
+		!strings.Contains(reply, "[b2]") {
+		t.Fatalf("/memory search: query %q, reply %q", query, reply)
+	}
+	if reply := runSessionCommand(rt, "/memory history"); !strings.Contains(reply, "[c3]") ||
+		!strings.Contains(reply, "replaced by [a1]") || strings.Contains(reply, "[b2]") {
+		t.Fatalf("/memory history reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/memory forget a1"); reply != "Forgot: The user lives in Porto." {
+		t.Fatalf("/memory forget a1 reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/memory forget zz"); !strings.HasPrefix(reply, "No fact zz.") {
+		t.Fatalf("/memory forget zz reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/memory forget broken"); reply != "Failed to forget: disk full" {
+		t.Fatalf("/memory forget broken reply = %q", reply)
+	}
+	if reply := runSessionCommand(&Runtime{}, "/memory list"); reply != unavailableMsg {
+		t.Fatalf("/memory list without memory reply = %q", reply)
+	}
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func pinCommand() Definition {
+	return Definition{
+		Name:        "pin",
+		Description: "Keep a skill active for this session until /unuse or a gateway restart",
+		Usage:       "/pin [skill]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.PinSkill == nil || rt.ListPinnedSkills == nil {
+				return req.Reply(unavailableMsg)
+			}
+			name := nthToken(req.Text, 1)
+			if name == "" {
+				pinned := rt.ListPinnedSkills()
+				if len(pinned) == 0 {
+					return req.Reply("No skills are pinned. Use /pin <skill> to keep a skill active for this session.")
+				}
+				return req.Reply("Pinned skills:\n- " + strings.Join(pinned, "\n- "))
+			}
+			skill, err := rt.PinSkill(name)
+			if err != nil {
+				return req.Reply(err.Error())
+			}
+			return req.Reply(fmt.Sprintf(
+				"Skill %q is active for this session until /unuse %s. Pins are not kept across gateway restarts.",
+				skill, skill,
+			))
+		},
+	}
+}
+
+func unuseCommand() Definition {
+	return Definition{
+		Name:        "unuse",
+		Description: "Deactivate pinned skills",
+		Usage:       "/unuse [skill|all]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.UnpinSkills == nil {
+				return req.Reply(unavailableMsg)
+			}
+			name := nthToken(req.Text, 1)
+			if strings.EqualFold(name, "all") {
+				name = ""
+			}
+			removed := rt.UnpinSkills(name)
+			switch {
+			case len(removed) == 0 && name == "":
+				return req.Reply("No skills are pinned.")
+			case len(removed) == 0:
+				return req.Reply(fmt.Sprintf("Skill %q is not pinned.", name))
+			}
+			return req.Reply("Deactivated: " + strings.Join(removed, ", "))
+		},
+	}
+}
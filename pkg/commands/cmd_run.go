@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func runCommand() Definition {
+	return Definition{
+		Name:        "run",
+		Description: "Run a workflow from the workspace",
+		Usage:       "/run <workflow> [input]",
+		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.RunWorkflow == nil {
+				return req.Reply(unavailableMsg)
+			}
+			name := nthToken(req.Text, 1)
+			if name == "" {
+				var names []string
+				if rt.ListWorkflows != nil {
+					names = rt.ListWorkflows()
+				}
+				if len(names) == 0 {
+					return req.Reply("No workflows found. Add YAML files to the workspace 'workflows' directory.")
+				}
+				return req.Reply("Usage: /run <workflow> [input]\nWorkflows: " + strings.Join(names, ", "))
+			}
+			output, err := rt.RunWorkflow(ctx, name, commandArgsAfter(req.Text, 2))
+			if err != nil {
+				return req.Reply(fmt.Sprintf("Workflow %s failed: %v", name, err))
+			}
+			if strings.TrimSpace(output) == "" {
+				return req.Reply(fmt.Sprintf("Workflow %s finished without output.", name))
+			}
+			return req.Reply(output)
+		},
+	}
+}
+
+// commandArgsAfter returns input with its first n tokens removed, keeping
+// the spacing and line breaks of the rest.
+func commandArgsAfter(input string, n int) string {
+	rest := strings.TrimSpace(input)
+	for range n {
+		i := strings.IndexFunc(rest, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' || r == '\r' })
+		if i < 0 {
+			return ""
+		}
+		rest = strings.TrimLeft(rest[i:], " \t\r\n")
+	}
+	return rest
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	var gotName, gotInput string
+	rt := &Runtime{
+		ListWorkflows: func() []string { return []string{"digest", "review"} },
+		RunWorkflow: func(_ context.Context, name, input string) (string, error) {
+			gotName, gotInput = name, input
+			return "done", nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+	run := func(text string) string {
+		var reply string
+		ex.Execute(context.Background(), Request{
+			Text:  text,
+			Reply: func(s string) error { reply = s; return nil },
+		})
+		return reply
+	}
+
+	if got := run("/run"); got != "Usage: /run <workflow> [input]\nWorkflows: digest, review" {
+		t.Fatalf("/run = %q", got)
+	}
+	if got := run("/run review  check  this\nplease"); got != "done" {
+		t.Fatalf("/run review = %q", got)
+	}
+	if gotName != "review" || gotInput != "check  this\nplease" {
+		t.Fatalf("RunWorkflow(%q, %q)", gotName, gotInput)
+	}
+}
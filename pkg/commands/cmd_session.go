@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MainForkName selects the conversation a chat had before any /fork.
+const MainForkName = "main"
+
+var forkNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ValidForkName reports whether name can be used with /fork.
+func ValidForkName(name string) bool {
+	return forkNamePattern.MatchString(name)
+}
+
+func rewindCommand() Definition {
+	return Definition{
+		Name:        "rewind",
+		Description: "Drop the last turns of the conversation",
+		Usage:       "/rewind [n]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.RewindHistory == nil {
+				return req.Reply(unavailableMsg)
+			}
+			turns := 1
+			if arg := nthToken(req.Text, 1); arg != "" {
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 1 {
+					return req.Reply("Usage: /rewind [n]")
+				}
+				turns = n
+			}
+			removed, err := rt.RewindHistory(turns)
+			switch {
+			case err != nil:
+				return req.Reply("Failed to rewind: " + err.Error())
+			case removed == 0:
+				return req.Reply("Nothing to rewind.")
+			case removed == 1:
+				return req.Reply("Removed the last turn.")
+			}
+			return req.Reply(fmt.Sprintf("Removed the last %d turns.", removed))
+		},
+	}
+}
+
+func forkCommand() Definition {
+	return Definition{
+		Name:        "fork",
+		Description: "Branch the conversation or switch between branches",
+		Usage:       "/fork [name]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.ForkSession == nil || rt.ListForks == nil {
+				return req.Reply(unavailableMsg)
+			}
+			name := strings.ToLower(nthToken(req.Text, 1))
+			if name == "" {
+				current, forks := rt.ListForks()
+				var sb strings.Builder
+				fmt.Fprintf(&sb, "Current conversation: %s", current)
+				if len(forks) > 0 {
+					fmt.Fprintf(&sb, "\nForks: %s", strings.Join(forks, ", "))
+				}
+				sb.WriteString("\nUse /fork <name> to branch off or switch, /fork main to go back.")
+				return req.Reply(sb.String())
+			}
+			if !ValidForkName(name) {
+				return req.Reply("Fork names use up to 32 lowercase letters, digits, '-' and '_'.")
+			}
+
+			created, err := rt.ForkSession(name)
+			switch {
+			case err != nil:
+				return req.Reply("Failed to fork: " + err.Error())
+			case name == MainForkName:
+				return req.Reply("Switched back to the main conversation.")
+			case created:
+				return req.Reply(fmt.Sprintf("Forked this conversation as %q. Use /fork main to go back.", name))
+			}
+			return req.Reply(fmt.Sprintf("Switched to fork %q.", name))
+		},
+	}
+}
+
+func exportCommand() Definition {
+	return Definition{
+		Name:        "export",
+		Description: "Send the conversation as a Markdown or JSON file",
+		Usage:       "/export [md|json]",
+		Handler: func(ctx context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.ExportSession == nil {
+				return req.Reply(unavailableMsg)
+			}
+			format := strings.ToLower(nthToken(req.Text, 1))
+			switch format {
+			case "", "md", "markdown":
+				format = "md"
+			case "json":
+			default:
+				return req.Reply("Usage: /export [md|json]")
+			}
+			filename, err := rt.ExportSession(ctx, format)
+			if err != nil {
+				return req.Reply("Failed to export: " + err.Error())
+			}
+			reply := "Exported the conversation to " + filename + "."
+			if format == "json" {
+				reply += " Send it back with /import to restore it."
+			}
+			return req.Reply(reply)
+		},
+	}
+}
+
+func importCommand() Definition {
+	return Definition{
+		Name:        "import",
+		Description: "Replace the conversation with an exported JSON file",
+		Usage:       "/import [path]",
+		Handler: func(_ context.Context, req Request, rt *Runtime) error {
+			if rt == nil || rt.ImportSession == nil {
+				return req.Reply(unavailableMsg)
+			}
+			n, err := rt.ImportSession(nthToken(req.Text, 1))
+			if err != nil {
+				return req.Reply("Failed to import: " + err.Error())
+			}
+			return req.Reply(fmt.Sprintf("Imported %d messages into this conversation.", n))
+		},
+	}
+}
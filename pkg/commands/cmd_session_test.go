@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func runSessionCommand(rt *Runtime, text string) string {
+	var reply string
+	NewExecutor(NewRegistry(BuiltinDefinitions()), rt).Execute(context.Background(), Request{
+		Text: text,
+		Reply: func(s string) error {
+			reply = s
+			return nil
+		},
+	})
+	return reply
+}
+
+func TestRewindCommand(t *testing.T) {
+	var asked int
+	rt := &Runtime{RewindHistory: func(turns int) (int, error) {
+		asked = turns
+		return min(turns, 2), nil
+	}}
+
+	if reply := runSessionCommand(rt, "/rewind"); asked != 1 || reply != "Removed the last turn." {
+		t.Fatalf("/rewind: asked %d, reply %q", asked, reply)
+	}
+	if reply := runSessionCommand(rt, "/rewind 5"); asked != 5 || reply != "Removed the last 2 turns." {
+		t.Fatalf("/rewind 5: asked %d, reply %q", asked, reply)
+	}
+	if reply := runSessionCommand(rt, "/rewind 0"); !strings.HasPrefix(reply, "Usage:") {
+		t.Fatalf("/rewind 0 reply = %q", reply)
+	}
+}
+
+func TestForkCommand(t *testing.T) {
+	forks := map[string]bool{}
+	rt := &Runtime{
+		ForkSession: func(name string) (bool, error) {
+			created := name != MainForkName && !forks[name]
+			forks[name] = true
+			return created, nil
+		},
+		ListForks: func() (string, []string) { return "main", []string{"draft"} },
+	}
+
+	if reply := runSessionCommand(rt, "/fork Draft"); !strings.Contains(reply, `Forked this conversation as "draft"`) {
+		t.Fatalf("/fork Draft reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/fork draft"); reply != `Switched to fork "draft".` {
+		t.Fatalf("second /fork draft reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/fork main"); !strings.Contains(reply, "main conversation") {
+		t.Fatalf("/fork main reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/fork"); !strings.Contains(reply, "Forks: draft") {
+		t.Fatalf("/fork reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/fork ../x"); !strings.HasPrefix(reply, "Fork names") {
+		t.Fatalf("/fork ../x reply = %q", reply)
+	}
+}
+
+func TestExportImportCommands(t *testing.T) {
+	var format, path string
+	rt := &Runtime{
+		ExportSession: func(_ context.Context, f string) (string, error) {
+			format = f
+			return "conversation." + f, nil
+		},
+		ImportSession: func(p string) (int, error) {
+			path = p
+			if p == "missing.json" {
+				return 0, errors.New("file not found")
+			}
+			return 4, nil
+		},
+	}
+
+	if reply := runSessionCommand(rt, "/export"); format != "md" || !strings.Contains(reply, "conversation.md") {
+		t.Fatalf("/export: format %q, reply %q", format, reply)
+	}
+	if reply := runSessionCommand(rt, "/export JSON"); format != "json" || !strings.Contains(reply, "/import") {
+		t.Fatalf("/export JSON: format %q, reply %q", format, reply)
+	}
+	if reply := runSessionCommand(rt, "/export pdf"); !strings.HasPrefix(reply, "Usage:") {
+		t.Fatalf("/export pdf reply = %q", reply)
+	}
+	if reply := runSessionCommand(rt, "/import"); path != "" || reply != "Imported 4 messages into this conversation." {
+		t.Fatalf("/import: path %q, reply %q", path, reply)
+	}
+	if reply := runSessionCommand(rt, "/import missing.json"); path != "missing.json" ||
+		reply != "Failed to import: file not found" {
+		t.Fatalf("/import missing.json: path %q, reply %q", path, reply)
+	}
+}
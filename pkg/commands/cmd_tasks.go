@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskInfo is a mirrored view of a persisted subagent task, to avoid a
+// dependency on the tools package.
+type TaskInfo struct {
+	ID            string
+	Label         string
+	Task          string
+	Status        string
+	Priority      int
+	Output        string
+	Result        string
+	OriginChannel string
+	OriginChatID  string
+	Created       int64
+}
+
+const maxListedTasks = 20
+
+func tasksCommand() Definition {
+	return Definition{
+		Name:        "tasks",
+		Description: "Manage background subagent tasks",
+		SubCommands: []SubCommand{
+			{
+				Name:        "list",
+				Description: "Recent tasks of this chat",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListTasks == nil {
+						return req.Reply(unavailableMsg)
+					}
+					tasks := visibleTasks(req, rt.ListTasks())
+					if len(tasks) == 0 {
+						return req.Reply("No background tasks in this chat.")
+					}
+					if len(tasks) > maxListedTasks {
+						tasks = tasks[len(tasks)-maxListedTasks:]
+					}
+					var sb strings.Builder
+					sb.WriteString("Background tasks:\n")
+					for _, task := range tasks {
+						fmt.Fprintf(&sb, "- %s [%s] %s", task.ID, task.Status, truncateTaskText(taskTitle(task), 80))
+						if task.Created > 0 {
+							fmt.Fprintf(&sb, " (%s)", time.UnixMilli(task.Created).Format("01-02 15:04"))
+						}
+						sb.WriteString("\n")
+					}
+					return req.Reply(strings.TrimRight(sb.String(), "\n"))
+				},
+			},
+			{
+				Name:        "show",
+				Description: "Progress or result of a task",
+				ArgsUsage:   "<id>",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListTasks == nil {
+						return req.Reply(unavailableMsg)
+					}
+					task, ok := findVisibleTask(req, rt.ListTasks())
+					if !ok {
+						return req.Reply(taskNotFoundMsg(req))
+					}
+					var sb strings.Builder
+					fmt.Fprintf(&sb, "%s [%s]", task.ID, task.Status)
+					if task.Priority != 0 {
+						fmt.Fprintf(&sb, " priority %d", task.Priority)
+					}
+					fmt.Fprintf(&sb, "\nTask: %s", task.Task)
+					if task.Output != "" {
+						fmt.Fprintf(&sb, "\n\nProgress:\n%s", truncateTaskText(task.Output, 1000))
+					}
+					if task.Result != "" {
+						fmt.Fprintf(&sb, "\n\nResult:\n%s", truncateTaskText(task.Result, 1500))
+					}
+					return req.Reply(sb.String())
+				},
+			},
+			{
+				Name:        "cancel",
+				Description: "Cancel a queued or running task",
+				ArgsUsage:   "<id>",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListTasks == nil || rt.CancelTask == nil {
+						return req.Reply(unavailableMsg)
+					}
+					task, ok := findVisibleTask(req, rt.ListTasks())
+					if !ok {
+						return req.Reply(taskNotFoundMsg(req))
+					}
+					if err := rt.CancelTask(task.ID); err != nil {
+						return req.Reply("Failed to cancel task: " + err.Error())
+					}
+					return req.Reply(fmt.Sprintf("Canceled %s.", task.ID))
+				},
+			},
+			{
+				Name:        "rerun",
+				Description: "Queue a finished task again",
+				ArgsUsage:   "<id>",
+				Handler: func(_ context.Context, req Request, rt *Runtime) error {
+					if rt == nil || rt.ListTasks == nil || rt.RerunTask == nil {
+						return req.Reply(unavailableMsg)
+					}
+					task, ok := findVisibleTask(req, rt.ListTasks())
+					if !ok {
+						return req.Reply(taskNotFoundMsg(req))
+					}
+					newID, err := rt.RerunTask(task.ID)
+					if err != nil {
+						return req.Reply("Failed to re-run task: " + err.Error())
+					}
+					return req.Reply(fmt.Sprintf("Queued %s again as %s.", task.ID, newID))
+				},
+			},
+		},
+	}
+}
+
+// visibleTasks keeps the tasks spawned from the requesting chat, so tasks do
+// not leak between conversations.
+func visibleTasks(req Request, tasks []TaskInfo) []TaskInfo {
+	visible := make([]TaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		if req.Channel != "" && task.OriginChannel != "" && task.OriginChannel != req.Channel {
+			continue
+		}
+		if req.ChatID != "" && task.OriginChatID != "" && task.OriginChatID != req.ChatID {
+			continue
+		}
+		visible = append(visible, task)
+	}
+	return visible
+}
+
+func findVisibleTask(req Request, tasks []TaskInfo) (TaskInfo, bool) {
+	id := nthToken(req.Text, 2)
+	if id == "" {
+		return TaskInfo{}, false
+	}
+	for _, task := range visibleTasks(req, tasks) {
+		if task.ID == id {
+			return task, true
+		}
+	}
+	return TaskInfo{}, false
+}
+
+func taskNotFoundMsg(req Request) string {
+	id := nthToken(req.Text, 2)
+	if id == "" {
+		return "Usage: /tasks " + nthToken(req.Text, 1) + " <id>"
+	}
+	return fmt.Sprintf("No task %s in this chat. Use /tasks list to see task IDs.", id)
+}
+
+// taskTitle returns a one-line name for a task.
+func taskTitle(task TaskInfo) string {
+	if task.Label != "" {
+		return task.Label
+	}
+	return strings.Join(strings.Fields(task.Task), " ")
+}
+
+func truncateTaskText(text string, maxRunes int) string {
+	if runes := []rune(text); len(runes) > maxRunes {
+		return string(runes[:maxRunes]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTasksCommand_ScopedToChat(t *testing.T) {
+	var canceled string
+	rt := &Runtime{
+		ListTasks: func() []TaskInfo {
+			return []TaskInfo{
+				{ID: "subagent-1", Label: "mine", Status: "running", OriginChannel: "telegram", OriginChatID: "1"},
+				{ID: "subagent-2", Label: "theirs", Status: "queued", OriginChannel: "telegram", OriginChatID: "2"},
+			}
+		},
+		CancelTask: func(id string) error {
+			canceled = id
+			return nil
+		},
+	}
+	ex := NewExecutor(NewRegistry(BuiltinDefinitions()), rt)
+
+	run := func(text string) string {
+		var reply string
+		ex.Execute(context.Background(), Request{
+			Channel: "telegram",
+			ChatID:  "1",
+			Text:    text,
+			Reply: func(s string) error {
+				reply = s
+				return nil
+			},
+		})
+		return reply
+	}
+
+	list := run("/tasks list")
+	if !strings.Contains(list, "subagent-1 [running] mine") || strings.Contains(list, "subagent-2") {
+		t.Fatalf("/tasks list reply = %q", list)
+	}
+
+	if reply := run("/tasks cancel subagent-2"); !strings.Contains(reply, "No task subagent-2") || canceled != "" {
+		t.Fatalf("cancel of another chat's task: reply=%q canceled=%q", reply, canceled)
+	}
+	if reply := run("/tasks cancel subagent-1"); reply != "Canceled subagent-1." || canceled != "subagent-1" {
+		t.Fatalf("cancel reply=%q canceled=%q", reply, canceled)
+	}
+	if reply := run("/tasks show"); reply != "Usage: /tasks show <id>" {
+		t.Fatalf("/tasks show reply = %q", reply)
+	}
+}
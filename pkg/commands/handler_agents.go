@@ -16,6 +16,12 @@ func agentsHandler() Handler {
 		if len(ids) == 0 {
 			return req.Reply("No agents registered")
 		}
-		return req.Reply(fmt.Sprintf("Registered agents: %s", strings.Join(ids, ", ")))
+		reply := fmt.Sprintf("Registered agents: %s", strings.Join(ids, ", "))
+		if rt.GetHandoff != nil {
+			if h, ok := rt.GetHandoff(); ok {
+				reply += "\nHandoff: " + h.String()
+			}
+		}
+		return req.Reply(reply)
 	}
 }
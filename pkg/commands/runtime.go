@@ -1,6 +1,11 @@
 package commands
 
-import "github.com/sipeed/picoclaw/pkg/config"
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
 
 // Runtime provides runtime dependencies to command handlers. It is constructed
 // per-request by the agent loop so that per-request state (like session scope)
@@ -11,10 +16,32 @@ type Runtime struct {
 	ListAgentIDs       func() []string
 	ListDefinitions    func() []Definition
 	ListSkillNames     func() []string
+	PinSkill           func(name string) (skill string, err error)
+	UnpinSkills        func(name string) (removed []string) // "" unpins every skill
+	ListPinnedSkills   func() []string
 	GetEnabledChannels func() []string
 	GetActiveTurn      func() any // Returning any to avoid circular dependency with agent package
 	SwitchModel        func(value string) (oldModel string, err error)
 	SwitchChannel      func(value string) error
 	ClearHistory       func() error
+	RewindHistory      func(turns int) (removed int, err error)
+	ForkSession        func(name string) (created bool, err error) // MainForkName switches back
+	ListForks          func() (current string, forks []string)
+	ExportSession      func(ctx context.Context, format string) (filename string, err error)
+	ImportSession      func(path string) (messages int, err error) // "" reads the attached file
 	ReloadConfig       func() error
+	ListTasks          func() []TaskInfo
+	CancelTask         func(id string) error
+	RerunTask          func(id string) (newID string, err error)
+	GetHandoff         func() (HandoffInfo, bool)
+	EndHandoff         func() (HandoffInfo, bool)
+	ListWorkflows      func() []string
+	RunWorkflow        func(ctx context.Context, name, input string) (string, error)
+	IssueLinkCode      func() (code string, ttl time.Duration, err error)
+	RedeemLinkCode     func(code string) (issuer string, err error)
+	ConfirmLink        func(claimant string) (linked []string, err error)
+	UnlinkIdentity     func() (removed bool, err error)
+	ListFacts          func(all bool) []FactInfo // all includes replaced facts
+	SearchFacts        func(query string) []FactInfo
+	ForgetFact         func(id string) (FactInfo, bool, error)
 }
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -31,11 +32,13 @@ type Config struct {
 	Session   SessionConfig   `json:"session,omitempty"  yaml:"-"`
 	Channels  ChannelsConfig  `json:"channels"           yaml:"channels"`
 	ModelList SecureModelList `json:"model_list"         yaml:"model_list"` // New model-centric provider configuration
-	Gateway   GatewayConfig   `json:"gateway"            yaml:"-"`
+	Gateway   GatewayConfig   `json:"gateway"            yaml:"gateway,omitempty"`
 	Hooks     HooksConfig     `json:"hooks,omitempty"    yaml:"-"`
 	Tools     ToolsConfig     `json:"tools"              yaml:",inline"`
 	Heartbeat HeartbeatConfig `json:"heartbeat"          yaml:"-"`
 	Devices   DevicesConfig   `json:"devices"            yaml:"-"`
+	Triggers  TriggersConfig  `json:"triggers"           yaml:"triggers,omitempty"`
+	Swarm     SwarmConfig     `json:"swarm"              yaml:"swarm,omitempty"`
 	Voice     VoiceConfig     `json:"voice"              yaml:"-"`
 	// BuildInfo contains build-time version information
 	BuildInfo BuildInfo `json:"build_info,omitempty" yaml:"-"`
@@ -110,7 +113,8 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 	}
 
 	// Only include session if not empty
-	if c.Session.DMScope != "" || len(c.Session.IdentityLinks) > 0 {
+	if c.Session.DMScope != "" || len(c.Session.IdentityLinks) > 0 || c.Session.ThreadScope != "" ||
+		c.Session.ThreadRepliesOver > 0 {
 		aux.Session = &c.Session
 	}
 
@@ -197,6 +201,14 @@ type AgentBinding struct {
 type SessionConfig struct {
 	DMScope       string              `json:"dm_scope,omitempty"`
 	IdentityLinks map[string][]string `json:"identity_links,omitempty"`
+	// ThreadScope is "shared" (default) to keep threads in the session of
+	// their parent, or "per-thread" to give each thread of a group or
+	// channel its own session.
+	ThreadScope string `json:"thread_scope,omitempty"`
+	// ThreadRepliesOver moves replies longer than this many characters into
+	// a new thread under the message they answer, on channels that can
+	// start threads. 0 disables it.
+	ThreadRepliesOver int `json:"thread_replies_over,omitempty"`
 }
 
 // RoutingConfig controls the intelligent model routing feature.
@@ -225,6 +237,36 @@ type ToolFeedbackConfig struct {
 	MaxArgsLength int  `json:"max_args_length" env:"PICOCLAW_AGENTS_DEFAULTS_TOOL_FEEDBACK_MAX_ARGS_LENGTH"`
 }
 
+// SkillsActivation controls which installed skills the model is told about.
+// In "all" mode every skill is listed in the system prompt. In "auto" mode
+// only the TopK skills most relevant to each message that reach MinScore
+// are listed.
+type SkillsActivation struct {
+	Mode          string  `json:"mode,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_SKILLS_MODE"`           // "all" (default) or "auto"
+	TopK          int     `json:"top_k,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_SKILLS_TOP_K"`          // skills listed per message in auto mode
+	MinScore      float64 `json:"min_score,omitempty"      env:"PICOCLAW_AGENTS_DEFAULTS_SKILLS_MIN_SCORE"`      // BM25 score a skill needs in auto mode
+	BudgetPercent int     `json:"budget_percent,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_SKILLS_BUDGET_PERCENT"` // share of the context window for skill text
+}
+
+const (
+	SkillsModeAll  = "all"
+	SkillsModeAuto = "auto"
+)
+
+// MemoryConfig enables long-term memory of facts. After each turn the
+// extraction model pulls durable facts and preferences out of the exchange,
+// and the TopK facts most relevant to a message are recalled into context.
+type MemoryConfig struct {
+	Enabled       bool    `json:"enabled"                  env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_ENABLED"`
+	ExtractModel  string  `json:"extract_model,omitempty"  env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_EXTRACT_MODEL"`  // defaults to the routing light model, then the agent model
+	TopK          int     `json:"top_k,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_TOP_K"`          // facts recalled per message
+	MinConfidence float64 `json:"min_confidence,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_MIN_CONFIDENCE"` // confidence an extracted fact needs
+	MaxFacts      int     `json:"max_facts,omitempty"      env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_MAX_FACTS"`      // facts kept per agent
+	// EmbeddingModel names a model_list entry used to embed facts for
+	// semantic recall. Empty keeps recall keyword-only.
+	EmbeddingModel string `json:"embedding_model,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_MEMORY_EMBEDDING_MODEL"`
+}
+
 type AgentDefaults struct {
 	Workspace                 string             `json:"workspace"                        env:"PICOCLAW_AGENTS_DEFAULTS_WORKSPACE"`
 	RestrictToWorkspace       bool               `json:"restrict_to_workspace"            env:"PICOCLAW_AGENTS_DEFAULTS_RESTRICT_TO_WORKSPACE"`
@@ -245,6 +287,8 @@ type AgentDefaults struct {
 	SteeringMode              string             `json:"steering_mode,omitempty"          env:"PICOCLAW_AGENTS_DEFAULTS_STEERING_MODE"` // "one-at-a-time" (default) or "all"
 	SubTurn                   SubTurnConfig      `json:"subturn"                                                                                      envPrefix:"PICOCLAW_AGENTS_DEFAULTS_SUBTURN_"`
 	ToolFeedback              ToolFeedbackConfig `json:"tool_feedback,omitempty"`
+	Skills                    SkillsActivation   `json:"skills,omitempty"`
+	Memory                    MemoryConfig       `json:"memory,omitempty"`
 	SplitOnMarker             bool               `json:"split_on_marker"                  env:"PICOCLAW_AGENTS_DEFAULTS_SPLIT_ON_MARKER"` // split messages on <|[SPLIT]|> marker
 	ContextManager            string             `json:"context_manager,omitempty"        env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER"`
 	ContextManagerConfig      json.RawMessage    `json:"context_manager_config,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_CONTEXT_MANAGER_CONFIG"`
@@ -272,6 +316,59 @@ func (d *AgentDefaults) IsToolFeedbackEnabled() bool {
 	return d.ToolFeedback.Enabled
 }
 
+// IsSkillsAutoMode reports whether skills are selected per message.
+func (d *AgentDefaults) IsSkillsAutoMode() bool {
+	return strings.EqualFold(strings.TrimSpace(d.Skills.Mode), SkillsModeAuto)
+}
+
+// GetSkillsTopK returns how many skills auto mode lists per message.
+func (d *AgentDefaults) GetSkillsTopK() int {
+	if d.Skills.TopK > 0 {
+		return d.Skills.TopK
+	}
+	return 5
+}
+
+// GetSkillsMinScore returns the BM25 score a skill needs to be listed in
+// auto mode.
+func (d *AgentDefaults) GetSkillsMinScore() float64 {
+	if d.Skills.MinScore > 0 {
+		return d.Skills.MinScore
+	}
+	return 0.5
+}
+
+// GetSkillsBudgetPercent returns the share of the context window that skill
+// text may use.
+func (d *AgentDefaults) GetSkillsBudgetPercent() int {
+	if d.Skills.BudgetPercent > 0 && d.Skills.BudgetPercent <= 100 {
+		return d.Skills.BudgetPercent
+	}
+	return 25
+}
+
+// IsMemoryEnabled reports whether long-term memory of facts is on.
+func (d *AgentDefaults) IsMemoryEnabled() bool {
+	return d.Memory.Enabled
+}
+
+// GetMemoryTopK returns how many facts are recalled per message.
+func (d *AgentDefaults) GetMemoryTopK() int {
+	if d.Memory.TopK > 0 {
+		return d.Memory.TopK
+	}
+	return 5
+}
+
+// GetMemoryMinConfidence returns the confidence an extracted fact needs to
+// be stored.
+func (d *AgentDefaults) GetMemoryMinConfidence() float64 {
+	if d.Memory.MinConfidence > 0 {
+		return d.Memory.MinConfidence
+	}
+	return 0.6
+}
+
 // GetModelName returns the effective model name for the agent defaults.
 // It prefers the new "model_name" field but falls back to "model" for backward compatibility.
 func (d *AgentDefaults) GetModelName() string {
@@ -513,6 +610,14 @@ type PicoConfig struct {
 	MaxConnections  int                 `json:"max_connections,omitempty"   yaml:"-"`
 	AllowFrom       FlexibleStringSlice `json:"allow_from"                  yaml:"-"               env:"PICOCLAW_CHANNELS_PICO_ALLOW_FROM"`
 	Placeholder     PlaceholderConfig   `json:"placeholder,omitempty"       yaml:"-"`
+	Voice           PicoVoiceConfig     `json:"voice,omitempty"             yaml:"-"`
+}
+
+// PicoVoiceConfig controls browser voice calls negotiated over the Pico
+// WebSocket with WebRTC.
+type PicoVoiceConfig struct {
+	Enabled    bool                `json:"enabled"                env:"PICOCLAW_CHANNELS_PICO_VOICE_ENABLED"`
+	ICEServers FlexibleStringSlice `json:"ice_servers,omitempty"`
 }
 
 // SetToken sets the Pico token and marks it as dirty for security saving
@@ -772,9 +877,12 @@ type WebToolsConfig struct {
 }
 
 type CronToolsConfig struct {
-	ToolConfig         `     envPrefix:"PICOCLAW_TOOLS_CRON_"`
-	ExecTimeoutMinutes int  `                                 json:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
-	AllowCommand       bool `                                 json:"allow_command"        env:"PICOCLAW_TOOLS_CRON_ALLOW_COMMAND"`
+	ToolConfig           `     envPrefix:"PICOCLAW_TOOLS_CRON_"`
+	ExecTimeoutMinutes   int    `                                 json:"exec_timeout_minutes"             env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
+	AllowCommand         bool   `                                 json:"allow_command"                    env:"PICOCLAW_TOOLS_CRON_ALLOW_COMMAND"`
+	HistoryMaxRuns       int    `                                 json:"history_max_runs,omitempty"       env:"PICOCLAW_TOOLS_CRON_HISTORY_MAX_RUNS"`       // 0 means default (50)
+	HistoryRetentionDays int    `                                 json:"history_retention_days,omitempty" env:"PICOCLAW_TOOLS_CRON_HISTORY_RETENTION_DAYS"` // 0 means default (30)
+	Timezone             string `                                 json:"timezone,omitempty"               env:"PICOCLAW_TOOLS_CRON_TIMEZONE"`               // IANA zone for tool-created schedules, empty means system zone
 }
 
 type ExecConfig struct {
@@ -786,12 +894,53 @@ type ExecConfig struct {
 	TimeoutSeconds      int      `                                 json:"timeout_seconds"       env:"PICOCLAW_TOOLS_EXEC_TIMEOUT_SECONDS"` // 0 means use default (60s)
 }
 
+// SpawnToolConfig configures the spawn tool and its persistent task queue.
+type SpawnToolConfig struct {
+	ToolConfig        `     envPrefix:"PICOCLAW_TOOLS_SPAWN_"`
+	MaxConcurrent     int  `                                  json:"max_concurrent"     env:"PICOCLAW_TOOLS_SPAWN_MAX_CONCURRENT"`     // 0 means default (2)
+	ResumeInterrupted bool `                                  json:"resume_interrupted" env:"PICOCLAW_TOOLS_SPAWN_RESUME_INTERRUPTED"` // re-queue tasks interrupted by a restart instead of failing them
+	KeepFinished      int  `                                  json:"keep_finished"      env:"PICOCLAW_TOOLS_SPAWN_KEEP_FINISHED"`      // 0 means default (100)
+}
+
+// HandoffToolConfig configures the handoff tool, which passes a conversation
+// to another agent.
+type BrowserToolConfig struct {
+	ToolConfig         `                    envPrefix:"PICOCLAW_TOOLS_BROWSER_"`
+	CDPURL             string              `                                    json:"cdp_url,omitempty"              env:"PICOCLAW_TOOLS_BROWSER_CDP_URL"`              // attach to a running browser instead of launching one
+	ExecutablePath     string              `                                    json:"executable_path,omitempty"      env:"PICOCLAW_TOOLS_BROWSER_EXECUTABLE_PATH"`      // empty searches PATH for Chromium
+	AllowedDomains     FlexibleStringSlice `                                    json:"allowed_domains,omitempty"      env:"PICOCLAW_TOOLS_BROWSER_ALLOWED_DOMAINS"`      // empty allows every public domain
+	DeniedDomains      FlexibleStringSlice `                                    json:"denied_domains,omitempty"       env:"PICOCLAW_TOOLS_BROWSER_DENIED_DOMAINS"`       // checked before allowed_domains
+	TimeoutSeconds     int                 `                                    json:"timeout_seconds,omitempty"      env:"PICOCLAW_TOOLS_BROWSER_TIMEOUT_SECONDS"`      // 0 means default (30)
+	IdleTimeoutMinutes int                 `                                    json:"idle_timeout_minutes,omitempty" env:"PICOCLAW_TOOLS_BROWSER_IDLE_TIMEOUT_MINUTES"` // 0 means default (15)
+}
+
+type AskUserToolConfig struct {
+	ToolConfig     `    envPrefix:"PICOCLAW_TOOLS_ASK_USER_"`
+	TimeoutSeconds int `                                     json:"timeout_seconds" env:"PICOCLAW_TOOLS_ASK_USER_TIMEOUT_SECONDS"` // 0 means default (300)
+}
+
+type HandoffToolConfig struct {
+	ToolConfig     `    envPrefix:"PICOCLAW_TOOLS_HANDOFF_"`
+	DefaultMinutes int `                                    json:"default_minutes" env:"PICOCLAW_TOOLS_HANDOFF_DEFAULT_MINUTES"` // 0 means until /back
+}
+
 type SkillsToolsConfig struct {
 	ToolConfig            `                       yaml:"-"                 envPrefix:"PICOCLAW_TOOLS_SKILLS_"`
 	Registries            SkillsRegistriesConfig `yaml:",inline,omitempty"                                    json:"registries"`
 	Github                SkillsGithubConfig     `yaml:"github,omitempty"                                     json:"github"`
 	MaxConcurrentSearches int                    `yaml:"-"                                                    json:"max_concurrent_searches" env:"PICOCLAW_TOOLS_SKILLS_MAX_CONCURRENT_SEARCHES"`
 	SearchCache           SearchCacheConfig      `yaml:"-"                                                    json:"search_cache"`
+	Security              SkillsSecurityConfig   `yaml:"-"                                                    json:"security"`
+}
+
+// SkillsSecurityConfig is the policy skills are vetted against before they
+// are installed.
+type SkillsSecurityConfig struct {
+	TrustedKeys      []string `json:"trusted_keys"`                                                               // minisign public keys
+	RequireSignature bool     `json:"require_signature"  env:"PICOCLAW_TOOLS_SKILLS_SECURITY_REQUIRE_SIGNATURE"`  // block unsigned skills
+	BlockOn          string   `json:"block_on"           env:"PICOCLAW_TOOLS_SKILLS_SECURITY_BLOCK_ON"`           // "critical" (default), "warning" or "none"
+	MaxBinarySizeKB  int      `json:"max_binary_size_kb" env:"PICOCLAW_TOOLS_SKILLS_SECURITY_MAX_BINARY_SIZE_KB"` // 0 means default (1024)
+	AllowedHosts     []string `json:"allowed_hosts"`                                                              // hosts skills may send secrets to
 }
 
 type MediaCleanupConfig struct {
@@ -832,16 +981,21 @@ type ToolsConfig struct {
 	MediaCleanup    MediaCleanupConfig `json:"media_cleanup"     yaml:"-"`
 	MCP             MCPConfig          `json:"mcp"               yaml:"-"`
 	AppendFile      ToolConfig         `json:"append_file"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_APPEND_FILE_"`
+	AskUser         AskUserToolConfig  `json:"ask_user"          yaml:"-"`
+	Browser         BrowserToolConfig  `json:"browser"           yaml:"-"`
+	DelegateRemote  ToolConfig         `json:"delegate_remote"   yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_DELEGATE_REMOTE_"`
 	EditFile        ToolConfig         `json:"edit_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_EDIT_FILE_"`
 	FindSkills      ToolConfig         `json:"find_skills"       yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_FIND_SKILLS_"`
+	Handoff         HandoffToolConfig  `json:"handoff"           yaml:"-"`
 	I2C             ToolConfig         `json:"i2c"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_I2C_"`
 	InstallSkill    ToolConfig         `json:"install_skill"     yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_INSTALL_SKILL_"`
 	ListDir         ToolConfig         `json:"list_dir"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_LIST_DIR_"`
 	Message         ToolConfig         `json:"message"           yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_MESSAGE_"`
 	ReadFile        ReadFileToolConfig `json:"read_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_READ_FILE_"`
+	RunWorkflow     ToolConfig         `json:"run_workflow"      yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_RUN_WORKFLOW_"`
 	SendFile        ToolConfig         `json:"send_file"         yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_FILE_"`
 	SendTTS         ToolConfig         `json:"send_tts"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SEND_TTS_"`
-	Spawn           ToolConfig         `json:"spawn"             yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_"`
+	Spawn           SpawnToolConfig    `json:"spawn"             yaml:"-"`
 	SpawnStatus     ToolConfig         `json:"spawn_status"      yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPAWN_STATUS_"`
 	SPI             ToolConfig         `json:"spi"               yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SPI_"`
 	Subagent        ToolConfig         `json:"subagent"          yaml:"-"                                                       envPrefix:"PICOCLAW_TOOLS_SUBAGENT_"`
@@ -1268,10 +1422,18 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.MediaCleanup.Enabled
 	case "append_file":
 		return t.AppendFile.Enabled
+	case "ask_user":
+		return t.AskUser.Enabled
+	case "browser":
+		return t.Browser.Enabled
+	case "delegate_remote":
+		return t.DelegateRemote.Enabled
 	case "edit_file":
 		return t.EditFile.Enabled
 	case "find_skills":
 		return t.FindSkills.Enabled
+	case "handoff":
+		return t.Handoff.Enabled
 	case "i2c":
 		return t.I2C.Enabled
 	case "install_skill":
@@ -1282,6 +1444,8 @@ func (t *ToolsConfig) IsToolEnabled(name string) bool {
 		return t.Message.Enabled
 	case "read_file":
 		return t.ReadFile.Enabled
+	case "run_workflow":
+		return t.RunWorkflow.Enabled
 	case "spawn":
 		return t.Spawn.Enabled
 	case "spawn_status":
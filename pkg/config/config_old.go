@@ -720,7 +720,7 @@ func (c *configV0) Migrate() (*Config, error) {
 	cfg.Tools.Message = c.Tools.Message
 	cfg.Tools.ReadFile = c.Tools.ReadFile
 	cfg.Tools.SendFile = c.Tools.SendFile
-	cfg.Tools.Spawn = c.Tools.Spawn
+	cfg.Tools.Spawn.ToolConfig = c.Tools.Spawn
 	cfg.Tools.SpawnStatus = c.Tools.SpawnStatus
 	cfg.Tools.SPI = c.Tools.SPI
 	cfg.Tools.Subagent = c.Tools.Subagent
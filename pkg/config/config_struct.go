@@ -153,6 +153,25 @@ func (s *SecureStrings) UnmarshalJSON(value []byte) error {
 	return nil
 }
 
+// SecureHeaders are HTTP request headers whose values are secrets, such as
+// Authorization. Names stay in config.json; values are kept in
+// .security.yml.
+type SecureHeaders map[string]*SecureString
+
+// Values returns the headers with their decrypted/resolved values.
+func (h SecureHeaders) Values() map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if v != nil {
+			out[k] = v.String()
+		}
+	}
+	return out
+}
+
 // SecureString the string value that can be decrypted or resolved
 //
 //nolint:recvcheck
@@ -341,6 +341,81 @@ func TestSaveConfig_FilePermissions(t *testing.T) {
 	}
 }
 
+func TestSaveConfig_SwarmSecretInSecurityFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+
+	cfg := DefaultConfig()
+	cfg.Swarm.Enabled = true
+	cfg.Swarm.NodeName = "kitchen"
+	cfg.Swarm.Secret.Set("swarm-s3cret")
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(raw), "swarm-s3cret") {
+		t.Fatalf("config.json contains the swarm secret:\n%s", raw)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if got := loaded.Swarm.Secret.String(); got != "swarm-s3cret" {
+		t.Errorf("Swarm.Secret = %q, want %q", got, "swarm-s3cret")
+	}
+	if loaded.Swarm.NodeName != "kitchen" {
+		t.Errorf("Swarm.NodeName = %q, want %q", loaded.Swarm.NodeName, "kitchen")
+	}
+}
+
+func TestSaveConfig_GatewayHeadersInSecurityFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+
+	cfg := DefaultConfig()
+	cfg.Gateway.Log = &GatewayLogConfig{HTTP: &HTTPShipConfig{
+		Enabled: true,
+		URL:     "http://loki:3100/loki/api/v1/push",
+		Headers: SecureHeaders{"Authorization": NewSecureString("Bearer l0ki")},
+	}}
+	cfg.Gateway.Tracing = &GatewayTracingConfig{
+		Enabled:  true,
+		Endpoint: "http://otel:4318",
+		Headers:  SecureHeaders{"X-Api-Key": NewSecureString("otl-k3y")},
+	}
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	for name, value := range map[string]string{"Authorization": "l0ki", "X-Api-Key": "otl-k3y"} {
+		if strings.Contains(string(raw), value) || !strings.Contains(string(raw), name) {
+			t.Fatalf("config.json should name the %s header but not hold its value:\n%s", name, raw)
+		}
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	got := loaded.Gateway.Log.HTTP.Headers.Values()
+	if got["Authorization"] != "Bearer l0ki" || loaded.Gateway.Log.HTTP.URL != cfg.Gateway.Log.HTTP.URL {
+		t.Errorf("loaded http shipping = %+v, headers %v", loaded.Gateway.Log.HTTP, got)
+	}
+	got = loaded.Gateway.Tracing.Headers.Values()
+	if got["X-Api-Key"] != "otl-k3y" || loaded.Gateway.Tracing.Endpoint != cfg.Gateway.Tracing.Endpoint {
+		t.Errorf("loaded tracing = %+v, headers %v", loaded.Gateway.Tracing, got)
+	}
+}
+
 func TestSaveConfig_IncludesEmptyLegacyModelField(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "config.json")
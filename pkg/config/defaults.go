@@ -32,12 +32,19 @@ func DefaultConfig() *Config {
 					Enabled:       false,
 					MaxArgsLength: 300,
 				},
+				Skills: SkillsActivation{
+					Mode:          SkillsModeAll,
+					TopK:          5,
+					MinScore:      0.5,
+					BudgetPercent: 25,
+				},
 				SplitOnMarker: false,
 			},
 		},
 		Bindings: []AgentBinding{},
 		Session: SessionConfig{
-			DMScope: "per-channel-peer",
+			DMScope:     "per-channel-peer",
+			ThreadScope: "shared",
 		},
 		Channels: ChannelsConfig{
 			WhatsApp: WhatsAppConfig{
@@ -444,6 +451,10 @@ func DefaultConfig() *Config {
 					MaxSize:    50,
 					TTLSeconds: 300,
 				},
+				Security: SkillsSecurityConfig{
+					BlockOn:         "critical",
+					MaxBinarySizeKB: 1024,
+				},
 			},
 			SendFile: ToolConfig{
 				Enabled: true,
@@ -467,12 +478,34 @@ func DefaultConfig() *Config {
 			AppendFile: ToolConfig{
 				Enabled: true,
 			},
+			AskUser: AskUserToolConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+				TimeoutSeconds: 300,
+			},
+			Browser: BrowserToolConfig{
+				ToolConfig: ToolConfig{
+					Enabled: false, // needs a local Chromium
+				},
+				TimeoutSeconds:     30,
+				IdleTimeoutMinutes: 15,
+			},
+			DelegateRemote: ToolConfig{
+				Enabled: true,
+			},
 			EditFile: ToolConfig{
 				Enabled: true,
 			},
 			FindSkills: ToolConfig{
 				Enabled: true,
 			},
+			Handoff: HandoffToolConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+				DefaultMinutes: 30,
+			},
 			I2C: ToolConfig{
 				Enabled: false, // Hardware tool - Linux only
 			},
@@ -489,9 +522,16 @@ func DefaultConfig() *Config {
 				Enabled:         true,
 				MaxReadFileSize: 64 * 1024, // 64KB
 			},
-			Spawn: ToolConfig{
+			RunWorkflow: ToolConfig{
 				Enabled: true,
 			},
+			Spawn: SpawnToolConfig{
+				ToolConfig: ToolConfig{
+					Enabled: true,
+				},
+				MaxConcurrent:     2,
+				ResumeInterrupted: true,
+			},
 			SpawnStatus: ToolConfig{
 				Enabled: false,
 			},
@@ -516,6 +556,12 @@ func DefaultConfig() *Config {
 			Enabled:    false,
 			MonitorUSB: true,
 		},
+		Swarm: SwarmConfig{
+			Enabled:                 false,
+			DiscoveryPort:           18799,
+			AnnounceIntervalSeconds: 10,
+			TaskTimeoutSeconds:      300,
+		},
 		Voice: VoiceConfig{
 			ModelName:         "",
 			EchoTranscription: false,
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -10,10 +11,80 @@ import (
 const DefaultGatewayLogLevel = "warn"
 
 type GatewayConfig struct {
-	Host      string `json:"host"                env:"PICOCLAW_GATEWAY_HOST"`
-	Port      int    `json:"port"                env:"PICOCLAW_GATEWAY_PORT"`
-	HotReload bool   `json:"hot_reload"          env:"PICOCLAW_GATEWAY_HOT_RELOAD"`
-	LogLevel  string `json:"log_level,omitempty" env:"PICOCLAW_LOG_LEVEL"`
+	Host      string `json:"host"                yaml:"-" env:"PICOCLAW_GATEWAY_HOST"`
+	Port      int    `json:"port"                yaml:"-" env:"PICOCLAW_GATEWAY_PORT"`
+	HotReload bool   `json:"hot_reload"          yaml:"-" env:"PICOCLAW_GATEWAY_HOT_RELOAD"`
+	LogLevel  string `json:"log_level,omitempty" yaml:"-" env:"PICOCLAW_LOG_LEVEL"`
+
+	Log     *GatewayLogConfig     `json:"log,omitempty"     yaml:"log,omitempty"`
+	Tracing *GatewayTracingConfig `json:"tracing,omitempty" yaml:"tracing,omitempty"`
+}
+
+// GatewayTracingConfig exports OpenTelemetry spans of turns, LLM requests,
+// tool calls and sub-turns to an OTLP/HTTP collector.
+type GatewayTracingConfig struct {
+	Enabled bool `json:"enabled" yaml:"-"`
+	// Endpoint is the collector URL, e.g. http://localhost:4318. A URL
+	// without a path gets /v1/traces.
+	Endpoint string `json:"endpoint" yaml:"-"`
+	// Headers are sent with every export, e.g. Authorization. Their values
+	// are kept in .security.yml.
+	Headers     SecureHeaders `json:"headers,omitempty"      yaml:"headers,omitempty"`
+	ServiceName string        `json:"service_name,omitempty" yaml:"-"`
+	// SampleRatio is the fraction of turns traced; 0 or omitted traces all.
+	SampleRatio     float64 `json:"sample_ratio,omitempty"      yaml:"-"`
+	BatchSize       int     `json:"batch_size,omitempty"        yaml:"-"`
+	FlushIntervalMS int     `json:"flush_interval_ms,omitempty" yaml:"-"`
+}
+
+// GatewayLogConfig configures the log format, rotation of the log file and
+// shipping of logs to a remote collector.
+type GatewayLogConfig struct {
+	// Format of console output: "console" (default) or "json".
+	Format          string `json:"format,omitempty"             yaml:"-"`
+	MaxSizeMB       int    `json:"max_size_mb,omitempty"        yaml:"-"`
+	MaxFileAgeHours int    `json:"max_file_age_hours,omitempty" yaml:"-"`
+	MaxBackups      int    `json:"max_backups,omitempty"        yaml:"-"`
+	RetentionDays   int    `json:"retention_days,omitempty"     yaml:"-"`
+	Compress        bool   `json:"compress,omitempty"           yaml:"-"`
+
+	Syslog *SyslogShipConfig `json:"syslog,omitempty" yaml:"-"`
+	HTTP   *HTTPShipConfig   `json:"http,omitempty"   yaml:"http,omitempty"`
+}
+
+// SyslogShipConfig ships logs to a syslog server in RFC 5424 format.
+type SyslogShipConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Network  string `json:"network,omitempty"`
+	Address  string `json:"address"`
+	AppName  string `json:"app_name,omitempty"`
+	Facility string `json:"facility,omitempty"`
+}
+
+// HTTPShipConfig pushes batches of logs to a Loki push endpoint.
+type HTTPShipConfig struct {
+	Enabled bool              `json:"enabled"          yaml:"-"`
+	URL     string            `json:"url"              yaml:"-"`
+	Labels  map[string]string `json:"labels,omitempty" yaml:"-"`
+	// Headers are sent with every push, e.g. Authorization. Their values
+	// are kept in .security.yml.
+	Headers         SecureHeaders `json:"headers,omitempty"           yaml:"headers,omitempty"`
+	BatchSize       int           `json:"batch_size,omitempty"        yaml:"-"`
+	FlushIntervalMS int           `json:"flush_interval_ms,omitempty" yaml:"-"`
+}
+
+// Rotation returns the log file rotation described by c.
+func (c *GatewayLogConfig) Rotation() logger.Rotation {
+	if c == nil {
+		return logger.Rotation{}
+	}
+	return logger.Rotation{
+		MaxSize:    int64(c.MaxSizeMB) << 20,
+		MaxFileAge: time.Duration(c.MaxFileAgeHours) * time.Hour,
+		MaxBackups: c.MaxBackups,
+		Retention:  time.Duration(c.RetentionDays) * 24 * time.Hour,
+		Compress:   c.Compress,
+	}
 }
 
 func canonicalGatewayLogLevel(level logger.LogLevel) string {
@@ -0,0 +1,26 @@
+package config
+
+// SwarmConfig lets PicoClaw instances on the same network discover each
+// other and delegate tasks to each other's agents.
+type SwarmConfig struct {
+	Enabled bool `json:"enabled" yaml:"-" env:"PICOCLAW_SWARM_ENABLED"`
+	// NodeName identifies this instance to its peers. Defaults to the host
+	// name.
+	NodeName string `json:"node_name,omitempty" yaml:"-" env:"PICOCLAW_SWARM_NODE_NAME"`
+	// Secret signs announcements and authenticates peer connections. All
+	// instances of a swarm share it. Required.
+	Secret SecureString `json:"secret,omitzero" yaml:"secret,omitempty" env:"PICOCLAW_SWARM_SECRET"`
+	// DiscoveryPort is the UDP port announcements are received on.
+	DiscoveryPort int `json:"discovery_port,omitempty" yaml:"-" env:"PICOCLAW_SWARM_DISCOVERY_PORT"`
+	// Announce lists the UDP addresses announcements are sent to. Defaults
+	// to the broadcast address on DiscoveryPort.
+	Announce FlexibleStringSlice `json:"announce,omitempty" yaml:"-" env:"PICOCLAW_SWARM_ANNOUNCE"`
+	// AnnounceIntervalSeconds is how often this instance announces itself.
+	AnnounceIntervalSeconds int `json:"announce_interval_seconds,omitempty" yaml:"-"`
+	// AdvertiseURL is the WebSocket URL peers use to reach this instance.
+	// Defaults to the gateway port on the local address that reaches each
+	// announce address.
+	AdvertiseURL string `json:"advertise_url,omitempty" yaml:"-"`
+	// TaskTimeoutSeconds bounds a delegated task.
+	TaskTimeoutSeconds int `json:"task_timeout_seconds,omitempty" yaml:"-"`
+}
@@ -0,0 +1,145 @@
+package config
+
+import "gopkg.in/yaml.v3"
+
+// Trigger sources.
+const (
+	TriggerSourceFile     = "file"
+	TriggerSourceWebhook  = "webhook"
+	TriggerSourceDevice   = "device"
+	TriggerSourceMQTT     = "mqtt"
+	TriggerSourceHTTPPoll = "http_poll"
+)
+
+// TriggersConfig holds the event-driven triggers that start agent turns.
+type TriggersConfig struct {
+	Enabled bool         `json:"enabled"         yaml:"-"               env:"PICOCLAW_TRIGGERS_ENABLED"`
+	Rules   TriggerRules `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// TriggerRules is the list of triggers. In the security file only their
+// credentials are stored, keyed by trigger name.
+type TriggerRules []TriggerConfig
+
+type triggerSecrets struct {
+	Webhook  *WebhookTriggerConfig  `yaml:"webhook,omitempty"`
+	MQTT     *MQTTTriggerConfig     `yaml:"mqtt,omitempty"`
+	HTTPPoll *HTTPPollTriggerConfig `yaml:"http_poll,omitempty"`
+}
+
+func (r *TriggerRules) UnmarshalYAML(value *yaml.Node) error {
+	mm := make(map[string]triggerSecrets)
+	if err := value.Decode(&mm); err != nil {
+		return err
+	}
+	for i := range *r {
+		rule := &(*r)[i]
+		sec, ok := mm[rule.Name]
+		if !ok {
+			continue
+		}
+		if sec.Webhook != nil && rule.Webhook != nil {
+			rule.Webhook.Secret = sec.Webhook.Secret
+		}
+		if sec.MQTT != nil && rule.MQTT != nil {
+			rule.MQTT.Password = sec.MQTT.Password
+		}
+		if sec.HTTPPoll != nil && rule.HTTPPoll != nil {
+			if rule.HTTPPoll.Headers == nil {
+				rule.HTTPPoll.Headers = make(SecureHeaders, len(sec.HTTPPoll.Headers))
+			}
+			for name, value := range sec.HTTPPoll.Headers {
+				rule.HTTPPoll.Headers[name] = value
+			}
+		}
+	}
+	return nil
+}
+
+func (r TriggerRules) MarshalYAML() (any, error) {
+	mm := make(map[string]triggerSecrets)
+	for _, rule := range r {
+		var sec triggerSecrets
+		if rule.Webhook != nil && rule.Webhook.Secret.String() != "" {
+			sec.Webhook = &WebhookTriggerConfig{Secret: rule.Webhook.Secret}
+		}
+		if rule.MQTT != nil && rule.MQTT.Password.String() != "" {
+			sec.MQTT = &MQTTTriggerConfig{Password: rule.MQTT.Password}
+		}
+		if rule.HTTPPoll != nil && len(rule.HTTPPoll.Headers) > 0 {
+			sec.HTTPPoll = &HTTPPollTriggerConfig{Headers: rule.HTTPPoll.Headers}
+		}
+		if sec.Webhook != nil || sec.MQTT != nil || sec.HTTPPoll != nil {
+			mm[rule.Name] = sec
+		}
+	}
+	return mm, nil
+}
+
+// TriggerConfig maps events from one source to an agent turn.
+type TriggerConfig struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+	AgentID string `json:"agent_id,omitempty"`
+	// Prompt is a text/template rendered with the event fields, e.g.
+	// "{{.path}} was {{.op}}".
+	Prompt string `json:"prompt"`
+	// Channel and ChatID receive the agent's reply. When empty the last
+	// active channel is used.
+	Channel string `json:"channel,omitempty"`
+	ChatID  string `json:"chat_id,omitempty"`
+	// DebounceSeconds coalesces bursts of events into one turn.
+	DebounceSeconds int `json:"debounce_seconds,omitempty"`
+	// MaxPerHour caps how many turns the trigger may start per hour.
+	MaxPerHour int `json:"max_per_hour,omitempty"`
+
+	File     *FileTriggerConfig     `json:"file,omitempty"`
+	Webhook  *WebhookTriggerConfig  `json:"webhook,omitempty"`
+	Device   *DeviceTriggerConfig   `json:"device,omitempty"`
+	MQTT     *MQTTTriggerConfig     `json:"mqtt,omitempty"`
+	HTTPPoll *HTTPPollTriggerConfig `json:"http_poll,omitempty"`
+}
+
+type FileTriggerConfig struct {
+	Paths     []string `json:"paths"`
+	Recursive bool     `json:"recursive,omitempty"`
+	// Events filters on create, write, remove, rename or chmod. Empty means all.
+	Events []string `json:"events,omitempty"`
+	// Pattern is a glob matched against the file name, e.g. "*.csv".
+	Pattern string `json:"pattern,omitempty"`
+}
+
+type WebhookTriggerConfig struct {
+	// Secret signs requests with HMAC-SHA256. Required.
+	Secret SecureString `json:"secret,omitzero" yaml:"secret,omitempty"`
+}
+
+type DeviceTriggerConfig struct {
+	// Kind filters on the device kind, e.g. "usb". Empty means any.
+	Kind string `json:"kind,omitempty"`
+	// Actions filters on add, remove or change. Empty means all.
+	Actions []string `json:"actions,omitempty"`
+	// Match is a case-insensitive substring of the vendor or product name.
+	Match string `json:"match,omitempty"`
+}
+
+type MQTTTriggerConfig struct {
+	Broker   string       `json:"broker"              yaml:"-"`
+	Topic    string       `json:"topic"               yaml:"-"`
+	QoS      byte         `json:"qos,omitempty"       yaml:"-"`
+	ClientID string       `json:"client_id,omitempty" yaml:"-"`
+	Username string       `json:"username,omitempty"  yaml:"-"`
+	Password SecureString `json:"password,omitzero"   yaml:"password,omitempty"`
+}
+
+type HTTPPollTriggerConfig struct {
+	URL             string `json:"url"                        yaml:"-"`
+	IntervalSeconds int    `json:"interval_seconds,omitempty" yaml:"-"`
+	// Headers are sent with each request. Their values, such as tokens, are
+	// kept in the security file.
+	Headers SecureHeaders `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Always fires on every successful poll instead of only when the
+	// response body changes.
+	Always bool `json:"always,omitempty" yaml:"-"`
+}
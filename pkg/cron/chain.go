@@ -0,0 +1,506 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleManual marks a job without a schedule of its own. It only runs
+// when triggered, e.g. by another job's post-run action.
+const ScheduleManual = "manual"
+
+// MaxChainDepth limits how many jobs a single scheduled run may trigger in a
+// row through post-run actions.
+const MaxChainDepth = 5
+
+// Outcomes a post-run action can fire on.
+const (
+	ActionOnSuccess = "success"
+	ActionOnFailure = "failure"
+	ActionOnAlways  = "always"
+)
+
+// Condition kinds for post-run actions.
+const (
+	ConditionRegex = "regex"
+	ConditionJSON  = "json"
+	ConditionLLM   = "llm"
+)
+
+// conditionTimeout bounds the LLM call of a ConditionLLM check.
+const conditionTimeout = 60 * time.Second
+
+// CronAction triggers another job after a run.
+type CronAction struct {
+	// On is ActionOnSuccess (default), ActionOnFailure or ActionOnAlways.
+	On    string `json:"on,omitempty"`
+	JobID string `json:"jobId"`
+	// PassOutput hands this run's output (or error) to the triggered job.
+	PassOutput bool           `json:"passOutput,omitempty"`
+	If         *CronCondition `json:"if,omitempty"`
+}
+
+// CronCondition is evaluated against a run's output before an action fires.
+type CronCondition struct {
+	Kind string `json:"kind"`
+	// Pattern is the regular expression matched by ConditionRegex.
+	Pattern string `json:"pattern,omitempty"`
+	// Path selects a value from JSON output for ConditionJSON, e.g.
+	// "daily.precipitation[0]".
+	Path string `json:"path,omitempty"`
+	// Op compares the selected value with Value: ==, !=, >, >=, <, <= or
+	// contains. Without Op the value must be present and truthy.
+	Op    string `json:"op,omitempty"`
+	Value string `json:"value,omitempty"`
+	// Question is the yes/no question asked about the output by ConditionLLM.
+	Question string `json:"question,omitempty"`
+	// Negate inverts the result.
+	Negate bool `json:"negate,omitempty"`
+}
+
+// CompleteFunc sends a single prompt to a language model and returns its
+// reply. It backs ConditionLLM checks.
+type CompleteFunc func(ctx context.Context, prompt string) (string, error)
+
+// chainState tracks a run started by a post-run action.
+type chainState struct {
+	input   string
+	parent  string
+	depth   int
+	visited map[string]bool
+}
+
+// SetCompleteFunc installs the model used for ConditionLLM checks.
+func (cs *CronService) SetCompleteFunc(fn CompleteFunc) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.complete = fn
+}
+
+// ValidateAction reports whether an action is well formed. It does not check
+// that the target job exists.
+func ValidateAction(a CronAction) error {
+	switch a.On {
+	case "", ActionOnSuccess, ActionOnFailure, ActionOnAlways:
+	default:
+		return fmt.Errorf("unknown action outcome %q (want %s, %s or %s)",
+			a.On, ActionOnSuccess, ActionOnFailure, ActionOnAlways)
+	}
+	if a.JobID == "" {
+		return fmt.Errorf("action needs a target job")
+	}
+	if a.If == nil {
+		return nil
+	}
+
+	c := a.If
+	switch c.Kind {
+	case ConditionRegex:
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("invalid condition pattern: %w", err)
+		}
+	case ConditionJSON:
+		if _, err := parseJSONPath(c.Path); err != nil {
+			return err
+		}
+		switch c.Op {
+		case "", "==", "!=", ">", ">=", "<", "<=", "contains":
+		default:
+			return fmt.Errorf("unknown condition operator %q", c.Op)
+		}
+	case ConditionLLM:
+		if strings.TrimSpace(c.Question) == "" {
+			return fmt.Errorf("llm condition needs a question")
+		}
+	default:
+		return fmt.Errorf("unknown condition kind %q (want %s, %s or %s)",
+			c.Kind, ConditionRegex, ConditionJSON, ConditionLLM)
+	}
+	return nil
+}
+
+// SetActions replaces the post-run actions of a job. Targets must exist and
+// the resulting chain graph must not contain a cycle.
+func (cs *CronService) SetActions(jobID string, actions []CronAction) (*CronJob, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	graph := make(map[string][]string, len(cs.store.Jobs))
+	var job *CronJob
+	for i := range cs.store.Jobs {
+		j := &cs.store.Jobs[i]
+		if j.ID == jobID {
+			job = j
+			continue
+		}
+		for _, a := range j.Payload.Then {
+			graph[j.ID] = append(graph[j.ID], a.JobID)
+		}
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+
+	for _, a := range actions {
+		if err := ValidateAction(a); err != nil {
+			return nil, err
+		}
+		if !cs.hasJobUnsafe(a.JobID) {
+			return nil, fmt.Errorf("target job %s not found", a.JobID)
+		}
+		graph[jobID] = append(graph[jobID], a.JobID)
+	}
+	if path := findCycle(graph, jobID); path != nil {
+		return nil, fmt.Errorf("chain would loop: %s", strings.Join(path, " → "))
+	}
+
+	job.Payload.Then = actions
+	job.UpdatedAtMS = time.Now().UnixMilli()
+	if err := cs.saveStoreUnsafe(); err != nil {
+		return nil, err
+	}
+	jobCopy := *job
+	return &jobCopy, nil
+}
+
+func (cs *CronService) hasJobUnsafe(jobID string) bool {
+	for _, j := range cs.store.Jobs {
+		if j.ID == jobID {
+			return true
+		}
+	}
+	return false
+}
+
+// dropActionsTo removes actions targeting a deleted job. The caller holds
+// cs.mu and saves the store.
+func (cs *CronService) dropActionsTo(jobID string) {
+	for i := range cs.store.Jobs {
+		then := cs.store.Jobs[i].Payload.Then
+		kept := then[:0]
+		for _, a := range then {
+			if a.JobID != jobID {
+				kept = append(kept, a)
+			}
+		}
+		if len(kept) == 0 {
+			kept = nil
+		}
+		cs.store.Jobs[i].Payload.Then = kept
+	}
+}
+
+// findCycle returns a path from start back to start, or nil.
+func findCycle(graph map[string][]string, start string) []string {
+	var path []string
+	onPath := make(map[string]bool)
+	done := make(map[string]bool)
+
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		path = append(path, id)
+		onPath[id] = true
+		for _, next := range graph[id] {
+			if next == start {
+				path = append(path, next)
+				return true
+			}
+			if onPath[next] || done[next] {
+				continue
+			}
+			if visit(next) {
+				return true
+			}
+		}
+		onPath[id] = false
+		done[id] = true
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if visit(start) {
+		return path
+	}
+	return nil
+}
+
+// runActions fires the post-run actions of job that match the run outcome.
+func (cs *CronService) runActions(job *CronJob, actions []CronAction, output string, runErr error, chain chainState) {
+	if len(actions) == 0 {
+		return
+	}
+
+	visited := maps.Clone(chain.visited)
+	if visited == nil {
+		visited = make(map[string]bool)
+	}
+	visited[job.ID] = true
+
+	subject := output
+	if runErr != nil && strings.TrimSpace(subject) == "" {
+		subject = runErr.Error()
+	}
+
+	for _, a := range actions {
+		if !actionFires(a.On, runErr) {
+			continue
+		}
+		if chain.depth+1 > MaxChainDepth {
+			log.Printf("[cron] chain from job '%s' stopped: depth limit %d reached", job.Name, MaxChainDepth)
+			return
+		}
+		if visited[a.JobID] {
+			log.Printf("[cron] chain from job '%s' to %s skipped: job already ran in this chain", job.Name, a.JobID)
+			continue
+		}
+		if a.If != nil {
+			ok, err := cs.evalCondition(a.If, subject)
+			if err != nil {
+				log.Printf("[cron] chain condition of job '%s' failed: %v", job.Name, err)
+				continue
+			}
+			if !ok {
+				log.Printf("[cron] chain from job '%s' to %s skipped: condition not met", job.Name, a.JobID)
+				continue
+			}
+		}
+
+		next := chainState{parent: job.ID, depth: chain.depth + 1, visited: visited}
+		if a.PassOutput {
+			next.input = subject
+		}
+		cs.runJob(a.JobID, next)
+	}
+}
+
+// DescribeAction renders an action for listings, e.g.
+// "→ notify (id) on success if regex /rain/, passing output".
+func DescribeAction(a CronAction, targetName string) string {
+	var b strings.Builder
+	b.WriteString("→ ")
+	if targetName != "" {
+		fmt.Fprintf(&b, "%s (%s)", targetName, a.JobID)
+	} else {
+		b.WriteString(a.JobID)
+	}
+	on := a.On
+	if on == "" {
+		on = ActionOnSuccess
+	}
+	fmt.Fprintf(&b, " on %s", on)
+
+	if c := a.If; c != nil {
+		b.WriteString(" if ")
+		if c.Negate {
+			b.WriteString("not ")
+		}
+		switch c.Kind {
+		case ConditionRegex:
+			fmt.Fprintf(&b, "regex /%s/", c.Pattern)
+		case ConditionJSON:
+			fmt.Fprintf(&b, "json %s", c.Path)
+			if c.Op != "" {
+				fmt.Fprintf(&b, " %s %s", c.Op, c.Value)
+			}
+		case ConditionLLM:
+			fmt.Fprintf(&b, "llm %q", c.Question)
+		default:
+			b.WriteString(c.Kind)
+		}
+	}
+	if a.PassOutput {
+		b.WriteString(", passing output")
+	}
+	return b.String()
+}
+
+func actionFires(on string, runErr error) bool {
+	switch on {
+	case ActionOnAlways:
+		return true
+	case ActionOnFailure:
+		return runErr != nil
+	default:
+		return runErr == nil
+	}
+}
+
+func (cs *CronService) evalCondition(c *CronCondition, output string) (bool, error) {
+	var (
+		ok  bool
+		err error
+	)
+	switch c.Kind {
+	case ConditionRegex:
+		var re *regexp.Regexp
+		if re, err = regexp.Compile(c.Pattern); err == nil {
+			ok = re.MatchString(output)
+		}
+	case ConditionJSON:
+		ok, err = evalJSONCondition(c, output)
+	case ConditionLLM:
+		ok, err = cs.askModel(c.Question, output)
+	default:
+		err = fmt.Errorf("unknown condition kind %q", c.Kind)
+	}
+	if err != nil {
+		return false, err
+	}
+	return ok != c.Negate, nil
+}
+
+func (cs *CronService) askModel(question, output string) (bool, error) {
+	cs.mu.RLock()
+	complete := cs.complete
+	cs.mu.RUnlock()
+	if complete == nil {
+		return false, fmt.Errorf("no model available for llm conditions")
+	}
+
+	prompt := fmt.Sprintf("Answer the question about the text below with a single word, yes or no.\n\n"+
+		"Question: %s\n\nText:\n%s", question, truncateOutput(output))
+
+	ctx, cancel := context.WithTimeout(context.Background(), conditionTimeout)
+	defer cancel()
+	reply, err := complete(ctx, prompt)
+	if err != nil {
+		return false, err
+	}
+	return parseYesNo(reply)
+}
+
+func parseYesNo(reply string) (bool, error) {
+	word := strings.ToLower(strings.TrimSpace(reply))
+	word = strings.TrimLeft(word, "*\"'`")
+	switch {
+	case strings.HasPrefix(word, "yes"), strings.HasPrefix(word, "true"):
+		return true, nil
+	case strings.HasPrefix(word, "no"), strings.HasPrefix(word, "false"):
+		return false, nil
+	}
+	return false, fmt.Errorf("model gave no yes/no answer: %q", truncateOutput(reply))
+}
+
+// jsonPathStep is either an object key or, when key is empty, an array index.
+type jsonPathStep struct {
+	key   string
+	index int
+}
+
+var jsonIndexRe = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+
+// parseJSONPath parses dotted paths such as "$.daily.rain[0]".
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var steps []jsonPathStep
+	for part := range strings.SplitSeq(path, ".") {
+		m := jsonIndexRe.FindStringSubmatch(part)
+		if m == nil || (m[1] == "" && m[2] == "") {
+			return nil, fmt.Errorf("invalid JSON path %q", path)
+		}
+		if m[1] != "" {
+			steps = append(steps, jsonPathStep{key: m[1]})
+		}
+		for idx := range strings.SplitSeq(strings.Trim(m[2], "[]"), "][") {
+			if idx == "" {
+				continue
+			}
+			n, _ := strconv.Atoi(idx)
+			steps = append(steps, jsonPathStep{index: n})
+		}
+	}
+	return steps, nil
+}
+
+func evalJSONCondition(c *CronCondition, output string) (bool, error) {
+	steps, err := parseJSONPath(c.Path)
+	if err != nil {
+		return false, err
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &value); err != nil {
+		return false, fmt.Errorf("output is not JSON: %w", err)
+	}
+	for _, step := range steps {
+		switch v := value.(type) {
+		case map[string]any:
+			if step.key == "" {
+				return false, nil
+			}
+			value = v[step.key]
+		case []any:
+			if step.key != "" || step.index >= len(v) {
+				return false, nil
+			}
+			value = v[step.index]
+		default:
+			return false, nil
+		}
+	}
+	return compareJSONValue(value, c.Op, c.Value), nil
+}
+
+func compareJSONValue(value any, op, want string) bool {
+	if op == "" {
+		switch v := value.(type) {
+		case nil:
+			return false
+		case bool:
+			return v
+		case float64:
+			return v != 0
+		case string:
+			return v != ""
+		case []any:
+			return len(v) > 0
+		default:
+			return true
+		}
+	}
+
+	got := fmt.Sprint(value)
+	if s, ok := value.(string); ok {
+		got = s
+	}
+	if op == "contains" {
+		return strings.Contains(got, want)
+	}
+
+	if n, ok := value.(float64); ok {
+		if w, err := strconv.ParseFloat(want, 64); err == nil {
+			switch op {
+			case "==":
+				return n == w
+			case "!=":
+				return n != w
+			case ">":
+				return n > w
+			case ">=":
+				return n >= w
+			case "<":
+				return n < w
+			case "<=":
+				return n <= w
+			}
+		}
+	}
+	switch op {
+	case "==":
+		return got == want
+	case "!=":
+		return got != want
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// chainRecorder is a job handler that returns canned outputs per message and
+// records which jobs ran with which input.
+type chainRecorder struct {
+	mu      sync.Mutex
+	outputs map[string]string
+	fail    map[string]bool
+	ran     []string
+	inputs  map[string]string
+}
+
+func newChainRecorder() *chainRecorder {
+	return &chainRecorder{
+		outputs: make(map[string]string),
+		fail:    make(map[string]bool),
+		inputs:  make(map[string]string),
+	}
+}
+
+func (r *chainRecorder) handle(job *CronJob) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ran = append(r.ran, job.Payload.Message)
+	r.inputs[job.Payload.Message] = job.Input
+	if r.fail[job.Payload.Message] {
+		return "", errors.New(job.Payload.Message + " broke")
+	}
+	return r.outputs[job.Payload.Message], nil
+}
+
+func addChainJob(t *testing.T, cs *CronService, name string) *CronJob {
+	t.Helper()
+	job, err := cs.AddJob(name, CronSchedule{Kind: ScheduleManual}, name, "", "")
+	if err != nil {
+		t.Fatalf("AddJob(%s): %v", name, err)
+	}
+	return job
+}
+
+func setChain(t *testing.T, cs *CronService, from *CronJob, actions ...CronAction) {
+	t.Helper()
+	if _, err := cs.SetActions(from.ID, actions); err != nil {
+		t.Fatalf("SetActions: %v", err)
+	}
+}
+
+func TestChain_TriggersOnOutcome(t *testing.T) {
+	rec := newChainRecorder()
+	cs, _ := setupService(t, rec.handle)
+	rec.fail["check"] = true
+
+	check := addChainJob(t, cs, "check")
+	onOK := addChainJob(t, cs, "ok")
+	onFail := addChainJob(t, cs, "alert")
+	always := addChainJob(t, cs, "cleanup")
+	setChain(t, cs, check,
+		CronAction{JobID: onOK.ID},
+		CronAction{On: ActionOnFailure, JobID: onFail.ID, PassOutput: true},
+		CronAction{On: ActionOnAlways, JobID: always.ID},
+	)
+
+	cs.runJob(check.ID, chainState{})
+
+	if got := strings.Join(rec.ran, ","); got != "check,alert,cleanup" {
+		t.Fatalf("ran %s, want check,alert,cleanup", got)
+	}
+	if rec.inputs["alert"] != "check broke" {
+		t.Errorf("alert input = %q, want the error text", rec.inputs["alert"])
+	}
+	if rec.inputs["cleanup"] != "" {
+		t.Errorf("cleanup input = %q, want none without pass_output", rec.inputs["cleanup"])
+	}
+
+	runs, err := cs.History(onFail.ID, 0)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("History = %v, %v", runs, err)
+	}
+	if runs[0].Trigger != TriggerChain || runs[0].TriggeredBy != check.ID {
+		t.Errorf("chained run = %+v, want trigger chain from %s", runs[0], check.ID)
+	}
+}
+
+func TestChain_Conditions(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		cond   CronCondition
+		want   bool
+	}{
+		{"regex match", "Light rain expected", CronCondition{Kind: ConditionRegex, Pattern: `(?i)rain|snow`}, true},
+		{"regex miss", "Sunny", CronCondition{Kind: ConditionRegex, Pattern: `(?i)rain|snow`}, false},
+		{"regex negated", "Sunny", CronCondition{Kind: ConditionRegex, Pattern: `rain`, Negate: true}, true},
+		{"json truthy", `{"alert": true}`, CronCondition{Kind: ConditionJSON, Path: "$.alert"}, true},
+		{"json missing", `{"ok": 1}`, CronCondition{Kind: ConditionJSON, Path: "alert"}, false},
+		{
+			"json numeric compare", `{"daily": {"rain": [0.2, 7.5]}}`,
+			CronCondition{Kind: ConditionJSON, Path: "daily.rain[1]", Op: ">", Value: "5"}, true,
+		},
+		{
+			"json string equality", `{"status": "down"}`,
+			CronCondition{Kind: ConditionJSON, Path: "status", Op: "==", Value: "up"}, false,
+		},
+		{"json not json", "plain text", CronCondition{Kind: ConditionJSON, Path: "status"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newChainRecorder()
+			cs, _ := setupService(t, rec.handle)
+			rec.outputs["source"] = tt.output
+
+			source := addChainJob(t, cs, "source")
+			target := addChainJob(t, cs, "target")
+			cond := tt.cond
+			setChain(t, cs, source, CronAction{JobID: target.ID, If: &cond})
+
+			cs.runJob(source.ID, chainState{})
+
+			if fired := len(rec.ran) == 2; fired != tt.want {
+				t.Fatalf("fired = %v, want %v", fired, tt.want)
+			}
+		})
+	}
+}
+
+func TestChain_LLMCondition(t *testing.T) {
+	rec := newChainRecorder()
+	cs, _ := setupService(t, rec.handle)
+	rec.outputs["inbox"] = "Invoice from ACME overdue"
+
+	var prompt string
+	cs.SetCompleteFunc(func(_ context.Context, p string) (string, error) {
+		prompt = p
+		return "Yes.", nil
+	})
+
+	inbox := addChainJob(t, cs, "inbox")
+	notify := addChainJob(t, cs, "notify")
+	setChain(t, cs, inbox, CronAction{
+		JobID: notify.ID,
+		If:    &CronCondition{Kind: ConditionLLM, Question: "Is anything urgent?"},
+	})
+
+	cs.runJob(inbox.ID, chainState{})
+
+	if len(rec.ran) != 2 {
+		t.Fatalf("ran %v, want notify triggered", rec.ran)
+	}
+	if !strings.Contains(prompt, "Is anything urgent?") || !strings.Contains(prompt, "Invoice from ACME") {
+		t.Errorf("prompt missing question or output: %q", prompt)
+	}
+}
+
+func TestChain_PassOutput(t *testing.T) {
+	rec := newChainRecorder()
+	cs, _ := setupService(t, rec.handle)
+	rec.outputs["fetch"] = "42 new issues"
+
+	fetch := addChainJob(t, cs, "fetch")
+	summarize := addChainJob(t, cs, "summarize")
+	setChain(t, cs, fetch, CronAction{JobID: summarize.ID, PassOutput: true})
+
+	cs.runJob(fetch.ID, chainState{})
+
+	if rec.inputs["summarize"] != "42 new issues" {
+		t.Fatalf("summarize input = %q", rec.inputs["summarize"])
+	}
+}
+
+func TestSetActions_RejectsCyclesAndUnknownTargets(t *testing.T) {
+	cs, _ := setupService(t, nil)
+	a := addChainJob(t, cs, "a")
+	b := addChainJob(t, cs, "b")
+	c := addChainJob(t, cs, "c")
+
+	setChain(t, cs, a, CronAction{JobID: b.ID})
+	setChain(t, cs, b, CronAction{JobID: c.ID})
+
+	if _, err := cs.SetActions(c.ID, []CronAction{{JobID: a.ID}}); err == nil {
+		t.Error("expected a → b → c → a to be rejected")
+	}
+	if _, err := cs.SetActions(a.ID, []CronAction{{JobID: a.ID}}); err == nil {
+		t.Error("expected a self-loop to be rejected")
+	}
+	if _, err := cs.SetActions(a.ID, []CronAction{{JobID: "missing"}}); err == nil {
+		t.Error("expected an unknown target to be rejected")
+	}
+	if _, err := cs.SetActions(a.ID, []CronAction{{JobID: b.ID, On: "sometimes"}}); err == nil {
+		t.Error("expected an unknown outcome to be rejected")
+	}
+	if _, err := cs.SetActions(a.ID, []CronAction{{JobID: b.ID, If: &CronCondition{Kind: ConditionRegex, Pattern: "("}}}); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
+
+func TestChain_DepthLimit(t *testing.T) {
+	rec := newChainRecorder()
+	cs, _ := setupService(t, rec.handle)
+
+	jobs := make([]*CronJob, MaxChainDepth+2)
+	for i := range jobs {
+		jobs[i] = addChainJob(t, cs, string(rune('a'+i)))
+	}
+	for i := 0; i < len(jobs)-1; i++ {
+		setChain(t, cs, jobs[i], CronAction{JobID: jobs[i+1].ID})
+	}
+
+	cs.runJob(jobs[0].ID, chainState{})
+
+	if len(rec.ran) != MaxChainDepth+1 {
+		t.Fatalf("ran %d jobs, want %d (the first plus %d chained)", len(rec.ran), MaxChainDepth+1, MaxChainDepth)
+	}
+}
+
+func TestRemoveJob_DropsActionsToIt(t *testing.T) {
+	cs, _ := setupService(t, nil)
+	a := addChainJob(t, cs, "a")
+	b := addChainJob(t, cs, "b")
+	setChain(t, cs, a, CronAction{JobID: b.ID})
+
+	if !cs.RemoveJob(b.ID) {
+		t.Fatal("RemoveJob failed")
+	}
+	job, _ := cs.GetJob(a.ID)
+	if len(job.Payload.Then) != 0 {
+		t.Fatalf("actions = %+v, want none", job.Payload.Then)
+	}
+}
+
+func TestManualSchedule_NeverRunsOnItsOwn(t *testing.T) {
+	cs, _ := setupService(t, nil)
+	job := addChainJob(t, cs, "manual")
+
+	if job.State.NextRunAtMS != nil {
+		t.Fatalf("NextRunAtMS = %v, want nil", *job.State.NextRunAtMS)
+	}
+	if err := ValidateSchedule(CronSchedule{Kind: ScheduleManual}); err != nil {
+		t.Fatalf("ValidateSchedule: %v", err)
+	}
+}
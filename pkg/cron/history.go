@@ -0,0 +1,255 @@
+package cron
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	// DefaultHistoryMaxRuns is the number of runs kept per job when no limit is configured.
+	DefaultHistoryMaxRuns = 50
+	// DefaultHistoryMaxAge is how long run records are kept when no limit is configured.
+	DefaultHistoryMaxAge = 30 * 24 * time.Hour
+
+	maxRunOutputRunes = 500
+)
+
+// Run statuses recorded in the job history.
+const (
+	RunStatusOK     = "ok"
+	RunStatusError  = "error"
+	RunStatusMissed = "missed"
+)
+
+// Run triggers recorded in the job history.
+const (
+	TriggerSchedule = "schedule"
+	TriggerCatchUp  = "catchup"
+	TriggerRetry    = "retry"
+	TriggerChain    = "chain"
+)
+
+// CronRun is one entry of a job's run history.
+type CronRun struct {
+	JobID       string `json:"jobId"`
+	StartedAtMS int64  `json:"startedAtMs"`
+	DurationMS  int64  `json:"durationMs"`
+	Status      string `json:"status"`
+	Trigger     string `json:"trigger,omitempty"`
+	TriggeredBy string `json:"triggeredBy,omitempty"`
+	Attempt     int    `json:"attempt,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Output      string `json:"output,omitempty"`
+	DeliveredTo string `json:"deliveredTo,omitempty"`
+}
+
+// historyStore keeps one JSONL file per job next to the job store. Runs are
+// appended as single lines; a file is rewritten with the retention limits
+// applied once it holds twice as many runs as are kept.
+type historyStore struct {
+	dir string
+
+	mu      sync.Mutex // guards the limits, lines and the files
+	maxRuns int
+	maxAge  time.Duration
+	lines   map[string]int // runs in each job's file, once counted
+}
+
+func newHistoryStore(storePath string) *historyStore {
+	return &historyStore{
+		dir:     filepath.Join(filepath.Dir(storePath), "runs"),
+		maxRuns: DefaultHistoryMaxRuns,
+		maxAge:  DefaultHistoryMaxAge,
+		lines:   make(map[string]int),
+	}
+}
+
+// setLimits changes the retention limits. Non-positive values keep the
+// current ones.
+func (h *historyStore) setLimits(maxRuns int, maxAge time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if maxRuns > 0 {
+		h.maxRuns = maxRuns
+	}
+	if maxAge > 0 {
+		h.maxAge = maxAge
+	}
+}
+
+func (h *historyStore) path(jobID string) string {
+	return filepath.Join(h.dir, jobID+".jsonl")
+}
+
+// read returns all retained runs of a job, oldest first.
+func (h *historyStore) read(jobID string) ([]CronRun, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	runs, err := h.readAll(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return h.retain(runs, time.Now()), nil
+}
+
+// readAll returns every run in a job's file, oldest first. Caller must hold
+// h.mu.
+func (h *historyStore) readAll(jobID string) ([]CronRun, error) {
+	data, err := os.ReadFile(h.path(jobID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var runs []CronRun
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var run CronRun
+		if err := json.Unmarshal(line, &run); err != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, scanner.Err()
+}
+
+// append records a run at the end of the job's file and compacts the file
+// when it has grown past the retention limit.
+func (h *historyStore) append(run CronRun) error {
+	line, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(h.dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path(run.JobID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	n, ok := h.lines[run.JobID]
+	if ok {
+		n++
+	} else {
+		runs, err := h.readAll(run.JobID)
+		if err != nil {
+			return err
+		}
+		n = len(runs)
+	}
+	h.lines[run.JobID] = n
+	if h.maxRuns > 0 && n >= 2*h.maxRuns {
+		return h.compact(run.JobID)
+	}
+	return nil
+}
+
+// compact rewrites a job's file with only the retained runs. Caller must
+// hold h.mu.
+func (h *historyStore) compact(jobID string) error {
+	runs, err := h.readAll(jobID)
+	if err != nil {
+		return err
+	}
+	runs = h.retain(runs, time.Now())
+
+	var buf bytes.Buffer
+	for _, r := range runs {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if err := fileutil.WriteFileAtomic(h.path(jobID), buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	h.lines[jobID] = len(runs)
+	return nil
+}
+
+// retain drops runs older than maxAge and keeps at most maxRuns entries.
+// Caller must hold h.mu.
+func (h *historyStore) retain(runs []CronRun, now time.Time) []CronRun {
+	if h.maxAge > 0 {
+		cutoff := now.Add(-h.maxAge).UnixMilli()
+		runs = slices.DeleteFunc(runs, func(r CronRun) bool { return r.StartedAtMS < cutoff })
+	}
+	if h.maxRuns > 0 && len(runs) > h.maxRuns {
+		runs = runs[len(runs)-h.maxRuns:]
+	}
+	return runs
+}
+
+func (h *historyStore) remove(jobID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.lines, jobID)
+	if err := os.Remove(h.path(jobID)); err != nil && !os.IsNotExist(err) {
+		log.Printf("[cron] failed to remove history for job %s: %v", jobID, err)
+	}
+}
+
+// prune deletes history files of jobs that no longer exist once they have
+// aged out of the retention window.
+func (h *historyStore) prune(known map[string]bool, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		jobID, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if !ok || known[jobID] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if h.maxAge > 0 && now.Sub(info.ModTime()) > h.maxAge {
+			_ = os.Remove(filepath.Join(h.dir, entry.Name()))
+			delete(h.lines, jobID)
+		}
+	}
+}
+
+// truncateOutput shortens job output to an excerpt suitable for the history.
+func truncateOutput(s string) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= maxRunOutputRunes {
+		return s
+	}
+	return string(runes[:maxRunOutputRunes]) + "..."
+}
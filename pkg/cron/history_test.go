@@ -0,0 +1,192 @@
+package cron
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newHistoryTestService(t *testing.T, handler JobHandler) (*CronService, string) {
+	t.Helper()
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	return NewCronService(storePath, handler), storePath
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BackoffMS: 1000, MaxBackoffMS: 5000}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	if got := (RetryPolicy{}).Delay(1); got != defaultRetryBackoffMS*time.Millisecond {
+		t.Errorf("default Delay(1) = %v", got)
+	}
+}
+
+func TestHistory_RecordsRunsNewestFirst(t *testing.T) {
+	calls := 0
+	cs, _ := newHistoryTestService(t, func(job *CronJob) (string, error) {
+		calls++
+		if calls == 2 {
+			return "", errors.New("boom")
+		}
+		return "done", nil
+	})
+
+	job, err := cs.AddJob("t", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "m", "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	cs.executeJobByID(job.ID)
+	cs.executeJobByID(job.ID)
+
+	runs, err := cs.History(job.ID, 0)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	if runs[0].Status != RunStatusError || runs[0].Error != "boom" {
+		t.Errorf("latest run = %+v, want error boom", runs[0])
+	}
+	if runs[1].Status != RunStatusOK || runs[1].Output != "done" || runs[1].DeliveredTo != "cli:direct" {
+		t.Errorf("first run = %+v", runs[1])
+	}
+
+	if limited, _ := cs.History(job.ID, 1); len(limited) != 1 {
+		t.Errorf("History(limit=1) returned %d runs", len(limited))
+	}
+
+	cs.RemoveJob(job.ID)
+	if runs, _ := cs.History(job.ID, 0); len(runs) != 0 {
+		t.Errorf("history survived job removal: %+v", runs)
+	}
+}
+
+func TestHistory_Retention(t *testing.T) {
+	h := &historyStore{maxRuns: 2, maxAge: time.Hour}
+	now := time.Now()
+	runs := []CronRun{
+		{StartedAtMS: now.Add(-2 * time.Hour).UnixMilli()},
+		{StartedAtMS: now.Add(-3 * time.Minute).UnixMilli()},
+		{StartedAtMS: now.Add(-2 * time.Minute).UnixMilli()},
+		{StartedAtMS: now.Add(-1 * time.Minute).UnixMilli()},
+	}
+	newest := runs[3].StartedAtMS
+	kept := h.retain(runs, now)
+	if len(kept) != 2 || kept[1].StartedAtMS != newest {
+		t.Fatalf("retain kept %+v", kept)
+	}
+}
+
+func TestHistory_AppendCompactsFile(t *testing.T) {
+	h := newHistoryStore(filepath.Join(t.TempDir(), "jobs.json"))
+	h.setLimits(2, 0)
+	now := time.Now()
+	for i := range 5 {
+		run := CronRun{JobID: "j", StartedAtMS: now.Add(time.Duration(i) * time.Second).UnixMilli()}
+		if err := h.append(run); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+
+	h.mu.Lock()
+	all, err := h.readAll("j")
+	h.mu.Unlock()
+	if err != nil || len(all) != 3 {
+		t.Fatalf("file holds %d runs (%v), want 3 after compacting at 4", len(all), err)
+	}
+	runs, _ := h.read("j")
+	if len(runs) != 2 || runs[1].StartedAtMS != now.Add(4*time.Second).UnixMilli() {
+		t.Fatalf("read = %+v", runs)
+	}
+}
+
+func TestRetry_SchedulesBackoffThenResumes(t *testing.T) {
+	cs, _ := newHistoryTestService(t, func(job *CronJob) (string, error) {
+		return "", errors.New("offline")
+	})
+	job, err := cs.AddJob("t", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "m", "", "")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	cs.mu.Lock()
+	cs.store.Jobs[0].Retry = RetryPolicy{MaxRetries: 1, BackoffMS: 1000}
+	cs.mu.Unlock()
+
+	before := time.Now().UnixMilli()
+	cs.executeJobByID(job.ID)
+	got, _ := cs.GetJob(job.ID)
+	if got.State.RetryCount != 1 || got.State.NextTrigger != TriggerRetry {
+		t.Fatalf("after first failure state = %+v", got.State)
+	}
+	if delta := *got.State.NextRunAtMS - before; delta < 1000 || delta > 2000 {
+		t.Errorf("retry scheduled %dms out, want ~1000", delta)
+	}
+
+	cs.executeJobByID(job.ID)
+	got, _ = cs.GetJob(job.ID)
+	if got.State.RetryCount != 0 || got.State.NextTrigger != "" {
+		t.Fatalf("after exhausting retries state = %+v", got.State)
+	}
+	if delta := *got.State.NextRunAtMS - before; delta < 3600000 {
+		t.Errorf("next run %dms out, want regular schedule", delta)
+	}
+
+	runs, _ := cs.History(job.ID, 0)
+	if len(runs) != 2 || runs[0].Trigger != TriggerRetry || runs[0].Attempt != 2 {
+		t.Errorf("history = %+v", runs)
+	}
+}
+
+func TestMisfire_Policies(t *testing.T) {
+	tests := []struct {
+		mode        string
+		wantCatchUp bool
+		wantPending int
+	}{
+		{mode: MisfireSkip},
+		{mode: MisfireRunOnce, wantCatchUp: true},
+		{mode: MisfireRunAll, wantCatchUp: true, wantPending: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cs, storePath := newHistoryTestService(t, nil)
+			job, err := cs.AddJob("t", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "m", "", "")
+			if err != nil {
+				t.Fatalf("AddJob failed: %v", err)
+			}
+
+			// Pretend the service was down for three intervals.
+			cs.mu.Lock()
+			missedAt := time.Now().Add(-150 * time.Second).UnixMilli()
+			cs.store.Jobs[0].State.NextRunAtMS = &missedAt
+			cs.store.Jobs[0].Misfire = MisfirePolicy{Mode: tt.mode}
+			if err := cs.saveStoreUnsafe(); err != nil {
+				t.Fatalf("save failed: %v", err)
+			}
+			cs.mu.Unlock()
+
+			restarted := NewCronService(storePath, nil)
+			restarted.mu.Lock()
+			restarted.recoverMissedRuns()
+			restarted.mu.Unlock()
+
+			got, _ := restarted.GetJob(job.ID)
+			isCatchUp := got.State.NextTrigger == TriggerCatchUp
+			if isCatchUp != tt.wantCatchUp || got.State.PendingRuns != tt.wantPending {
+				t.Fatalf("state = %+v, want catch-up=%v pending=%d", got.State, tt.wantCatchUp, tt.wantPending)
+			}
+
+			runs, _ := restarted.History(job.ID, 0)
+			if !tt.wantCatchUp && (len(runs) != 1 || runs[0].Status != RunStatusMissed) {
+				t.Errorf("skip mode history = %+v, want one missed record", runs)
+			}
+		})
+	}
+}
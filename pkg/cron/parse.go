@@ -0,0 +1,456 @@
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHour is the time of day used by calendar schedules that do not name
+// one, e.g. "every monday" or "first friday of each month".
+const defaultHour = 9
+
+// ParseSchedule turns an English scheduling phrase into a CronSchedule.
+//
+// Supported forms include:
+//
+//	in 20 minutes, in 2h30m, in an hour
+//	at 17:30, today at 5pm, tomorrow at 8:30am, on friday at noon
+//	every 15 minutes, every hour, hourly
+//	every day at 7, daily at 21:00, every weekday at 8:30, weekends at 10am
+//	every monday and thursday at 18:00, weekly on sunday
+//	every month on the 15th, the 1st of each month at 9, monthly
+//	last day of the month at 18:00
+//	first monday of each month, last friday of every month at 16:00
+//
+// Relative and one-time phrases are resolved against now. Wall-clock phrases
+// are interpreted in loc (the local timezone when nil), and recurring ones keep
+// loc as the schedule timezone. Calendar schedules without a time of day run
+// at 09:00. Parsing is purely rule based: the same phrase, now and loc always
+// yield the same schedule.
+func ParseSchedule(phrase string, now time.Time, loc *time.Location) (CronSchedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+
+	text := normalizePhrase(phrase)
+	if text == "" {
+		return CronSchedule{}, fmt.Errorf("empty schedule")
+	}
+
+	body, clock, hasClock, err := splitClock(text)
+	if err != nil {
+		return CronSchedule{}, err
+	}
+	if !hasClock {
+		clock = timeOfDay{hour: defaultHour}
+	}
+
+	for _, rule := range scheduleRules {
+		m := rule.re.FindStringSubmatch(body)
+		if m == nil {
+			continue
+		}
+		if hasClock && !rule.clock {
+			return CronSchedule{}, fmt.Errorf("%q cannot be combined with a time of day", strings.TrimSpace(body))
+		}
+		s, err := rule.build(m, scheduleContext{now: now, loc: loc, clock: clock, hasClock: hasClock})
+		if err != nil {
+			return CronSchedule{}, err
+		}
+		return s, nil
+	}
+	return CronSchedule{}, fmt.Errorf("unrecognized schedule %q", phrase)
+}
+
+type timeOfDay struct {
+	hour, minute int
+}
+
+type scheduleContext struct {
+	now      time.Time
+	loc      *time.Location
+	clock    timeOfDay
+	hasClock bool
+}
+
+func (c scheduleContext) cron(dom, dow string) CronSchedule {
+	return CronSchedule{
+		Kind: "cron",
+		Expr: fmt.Sprintf("%d %d %s * %s", c.clock.minute, c.clock.hour, dom, dow),
+		TZ:   tzName(c.loc),
+	}
+}
+
+// at returns a one-time schedule for the clock time on the given day.
+func (c scheduleContext) at(day time.Time) CronSchedule {
+	t := time.Date(day.Year(), day.Month(), day.Day(), c.clock.hour, c.clock.minute, 0, 0, c.loc)
+	atMS := t.UnixMilli()
+	return CronSchedule{Kind: "at", AtMS: &atMS}
+}
+
+type scheduleRule struct {
+	re *regexp.Regexp
+	// clock reports whether the phrase may carry a time of day.
+	clock bool
+	build func(m []string, c scheduleContext) (CronSchedule, error)
+}
+
+const (
+	weekdayPattern = `(?:sun|mon|tue|tues|wed|thu|thur|thurs|fri|sat)(?:day)?s?|wednesdays?|saturdays?`
+	// pluralWeekdayPattern matches recurring forms such as "mondays".
+	pluralWeekdayPattern = `(?:sun|mon|tues|wednes|thurs|fri|satur)days`
+	ordinalPattern       = `first|second|third|fourth|fifth|last|1st|2nd|3rd|4th|5th`
+	unitPattern          = `seconds?|secs?|s|minutes?|mins?|m|hours?|hrs?|h|days?|d|weeks?|w`
+	monthPattern         = `(?:every|each|the|a) month`
+)
+
+var scheduleRules = []scheduleRule{
+	// in 20 minutes / in an hour / in 1h30m
+	{
+		re: regexp.MustCompile(`^in (.+)$`),
+		build: func(m []string, c scheduleContext) (CronSchedule, error) {
+			d, err := parseSpan(m[1])
+			if err != nil {
+				return CronSchedule{}, err
+			}
+			atMS := c.now.Add(d).UnixMilli()
+			return CronSchedule{Kind: "at", AtMS: &atMS}, nil
+		},
+	},
+	// every 15 minutes / every hour / hourly
+	{
+		re: regexp.MustCompile(`^(?:every (?:(\d+|an?|one|two|three|four|five|six|ten|twelve|fifteen|twenty|thirty) )?(` +
+			unitPattern + `)|(hourly))$`),
+		clock: true,
+		build: func(m []string, c scheduleContext) (CronSchedule, error) {
+			count, unit := m[1], m[2]
+			if m[3] != "" {
+				unit = "hour"
+			}
+			d, err := parseSpan(strings.TrimSpace(count + " " + unit))
+			if err != nil {
+				return CronSchedule{}, err
+			}
+			if d == 24*time.Hour && count == "" {
+				// "every day" and "every week" are calendar schedules.
+				return c.cron("*", "*"), nil
+			}
+			if d == 7*24*time.Hour && count == "" {
+				return c.cron("*", strconv.Itoa(int(c.now.Weekday()))), nil
+			}
+			if c.hasClock {
+				return CronSchedule{}, fmt.Errorf("intervals cannot be combined with a time of day")
+			}
+			if d < time.Second {
+				return CronSchedule{}, fmt.Errorf("interval must be at least one second")
+			}
+			everyMS := d.Milliseconds()
+			return CronSchedule{Kind: "every", EveryMS: &everyMS}, nil
+		},
+	},
+	// every day / daily
+	{
+		re:    regexp.MustCompile(`^(?:everyday|each day|daily)$`),
+		clock: true,
+		build: func(_ []string, c scheduleContext) (CronSchedule, error) {
+			return c.cron("*", "*"), nil
+		},
+	},
+	// every weekday / weekdays
+	{
+		re:    regexp.MustCompile(`^(?:(?:every|each|on) )?(?:weekday|weekdays|workday|workdays|work day|working day)s?$`),
+		clock: true,
+		build: func(_ []string, c scheduleContext) (CronSchedule, error) {
+			return c.cron("*", "1-5"), nil
+		},
+	},
+	// every weekend / weekends
+	{
+		re:    regexp.MustCompile(`^(?:(?:every|each|on) )?(?:the )?weekends?$`),
+		clock: true,
+		build: func(_ []string, c scheduleContext) (CronSchedule, error) {
+			return c.cron("*", "0,6"), nil
+		},
+	},
+	// weekly / every week
+	{
+		re:    regexp.MustCompile(`^(?:weekly|every week|each week)$`),
+		clock: true,
+		build: func(_ []string, c scheduleContext) (CronSchedule, error) {
+			return c.cron("*", strconv.Itoa(int(c.now.Weekday()))), nil
+		},
+	},
+	// every monday and thursday / mondays and fridays / weekly on friday
+	{
+		re: regexp.MustCompile(`^(?:(?:every|each|weekly on|every week on) (` + weekdayListPattern(weekdayPattern) +
+			`)|(` + weekdayListPattern(pluralWeekdayPattern) + `))$`),
+		clock: true,
+		build: func(m []string, c scheduleContext) (CronSchedule, error) {
+			days, err := parseWeekdayList(m[1] + m[2])
+			if err != nil {
+				return CronSchedule{}, err
+			}
+			return c.cron("*", days), nil
+		},
+	},
+	// first monday of each month / last friday of every month
+	{
+		re: regexp.MustCompile(`^(?:(?:every|each|on) )?(?:the )?(` + ordinalPattern + `) (` + weekdayPattern +
+			`) (?:of|in) ` + monthPattern + `$`),
+		clock: true,
+		build: func(m []string, c scheduleContext) (CronSchedule, error) {
+			wd, err := parseWeekday(m[2])
+			if err != nil {
+				return CronSchedule{}, err
+			}
+			if m[1] == "last" {
+				return c.cron("*", fmt.Sprintf("%dL", wd)), nil
+			}
+			n := ordinalValue(m[1])
+			return c.cron("*", fmt.Sprintf("%d#%d", wd, n)), nil
+		},
+	},
+	// last day of the month
+	{
+		re: regexp.MustCompile(`^(?:(?:every|each|on) )?(?:the )?last day (?:of|in) ` + monthPattern +
+			`$|^(?:every|each) month on the last day$|^monthly on the last day$`),
+		clock: true,
+		build: func(_ []string, c scheduleContext) (CronSchedule, error) {
+			return c.cron("L", "*"), nil
+		},
+	},
+	// every month on the 15th / the 1st of each month / monthly
+	{
+		re: regexp.MustCompile(`^(?:(?:every|each) month on (?:the )?(\d{1,2})(?:st|nd|rd|th)?|` +
+			`(?:monthly on )(?:the )?(\d{1,2})(?:st|nd|rd|th)?|` +
+			`(?:on )?(?:the )?(\d{1,2})(?:st|nd|rd|th)? (?:day )?(?:of|in) ` + monthPattern + `|` +
+			`(monthly|every month|each month))$`),
+		clock: true,
+		build: func(m []string, c scheduleContext) (CronSchedule, error) {
+			day := 1
+			for _, raw := range m[1:4] {
+				if raw != "" {
+					day, _ = strconv.Atoi(raw)
+				}
+			}
+			if day < 1 || day > 31 {
+				return CronSchedule{}, fmt.Errorf("day of month %d out of range", day)
+			}
+			return c.cron(strconv.Itoa(day), "*"), nil
+		},
+	},
+	// at 17:30 / today at 5pm / tomorrow / on friday at noon
+	{
+		re: regexp.MustCompile(`^(?:(today)|(tomorrow)|(?:(?:on|next|this) )?(` + weekdayPattern +
+			`))?$`),
+		clock: true,
+		build: func(m []string, c scheduleContext) (CronSchedule, error) {
+			today := c.now
+			switch {
+			case m[1] != "":
+				s := c.at(today)
+				if *s.AtMS <= c.now.UnixMilli() {
+					return CronSchedule{}, fmt.Errorf("%02d:%02d today has already passed", c.clock.hour, c.clock.minute)
+				}
+				return s, nil
+			case m[2] != "":
+				return c.at(today.AddDate(0, 0, 1)), nil
+			case m[3] != "":
+				wd, err := parseWeekday(m[3])
+				if err != nil {
+					return CronSchedule{}, err
+				}
+				ahead := (wd - int(today.Weekday()) + 7) % 7
+				s := c.at(today.AddDate(0, 0, ahead))
+				if *s.AtMS <= c.now.UnixMilli() {
+					s = c.at(today.AddDate(0, 0, ahead+7))
+				}
+				return s, nil
+			}
+			if !c.hasClock {
+				return CronSchedule{}, fmt.Errorf("schedule needs a date or time")
+			}
+			// A bare time means its next occurrence.
+			s := c.at(today)
+			if *s.AtMS <= c.now.UnixMilli() {
+				s = c.at(today.AddDate(0, 0, 1))
+			}
+			return s, nil
+		},
+	},
+}
+
+// weekdayListPattern matches one or more weekdays joined by commas or "and".
+func weekdayListPattern(day string) string {
+	return `(?:` + day + `)(?:(?:, ?| and | & |, and )(?:` + day + `))*`
+}
+
+var (
+	spaceRe  = regexp.MustCompile(`\s+`)
+	clockRe  = `(noon|midday|midnight|\d{1,2}(?::\d{2})?(?: ?[ap]\.?m\.?)?)`
+	atEndRe  = regexp.MustCompile(`^(.*?)\s*\bat ` + clockRe + `$`)
+	atHeadRe = regexp.MustCompile(`^at ` + clockRe + `(?: (.*))?$`)
+	timeRe   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?: ?([ap])\.?m\.?)?$`)
+	spanRe   = regexp.MustCompile(`^(\d+|an?|one|two|three|four|five|six|ten|twelve|fifteen|twenty|thirty|half an?) ?(` +
+		unitPattern + `)$`)
+)
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"ten": 10, "twelve": 12, "fifteen": 15, "twenty": 20, "thirty": 30,
+}
+
+func normalizePhrase(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimRight(s, ".!")
+	s = spaceRe.ReplaceAllString(s, " ")
+	s = strings.TrimPrefix(s, "run ")
+	return s
+}
+
+// splitClock separates a trailing or leading "at <time>" from the phrase.
+func splitClock(text string) (body string, clock timeOfDay, ok bool, err error) {
+	var raw string
+	if m := atHeadRe.FindStringSubmatch(text); m != nil {
+		raw, body = m[1], m[2]
+	} else if m := atEndRe.FindStringSubmatch(text); m != nil {
+		body, raw = m[1], m[2]
+	} else {
+		return text, timeOfDay{}, false, nil
+	}
+	clock, err = parseTimeOfDay(raw)
+	if err != nil {
+		return "", timeOfDay{}, false, err
+	}
+	return strings.TrimSpace(body), clock, true, nil
+}
+
+func parseTimeOfDay(s string) (timeOfDay, error) {
+	switch s {
+	case "noon", "midday":
+		return timeOfDay{hour: 12}, nil
+	case "midnight":
+		return timeOfDay{}, nil
+	}
+
+	m := timeRe.FindStringSubmatch(s)
+	if m == nil {
+		return timeOfDay{}, fmt.Errorf("invalid time of day %q", s)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "a", "p":
+		if hour < 1 || hour > 12 {
+			return timeOfDay{}, fmt.Errorf("invalid time of day %q", s)
+		}
+		hour %= 12
+		if m[3] == "p" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return timeOfDay{}, fmt.Errorf("invalid time of day %q", s)
+	}
+	return timeOfDay{hour: hour, minute: minute}, nil
+}
+
+// parseSpan parses durations like "20 minutes", "an hour", "half an hour"
+// or Go duration strings such as "1h30m".
+func parseSpan(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+
+	m := spanRe.FindStringSubmatch(s)
+	if m == nil {
+		// A bare unit ("every hour") counts once.
+		m = spanRe.FindStringSubmatch("1 " + s)
+		if m == nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+
+	var unit time.Duration
+	switch u := m[2]; {
+	case strings.HasPrefix(u, "s"):
+		unit = time.Second
+	case strings.HasPrefix(u, "mi") || u == "m":
+		unit = time.Minute
+	case strings.HasPrefix(u, "h"):
+		unit = time.Hour
+	case strings.HasPrefix(u, "d"):
+		unit = 24 * time.Hour
+	case strings.HasPrefix(u, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	if strings.HasPrefix(m[1], "half") {
+		return unit / 2, nil
+	}
+	n, ok := numberWords[m[1]]
+	if !ok {
+		var err error
+		if n, err = strconv.Atoi(m[1]); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	if n <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func parseWeekday(s string) (int, error) {
+	s = strings.TrimSuffix(s, "s")
+	for i, name := range []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"} {
+		if strings.HasPrefix(s, name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+// parseWeekdayList turns "monday, wednesday and friday" into "1,3,5".
+func parseWeekdayList(s string) (string, error) {
+	s = strings.NewReplacer(" and ", ",", " & ", ",", " ", "").Replace(s)
+	var days []string
+	seen := make(map[int]bool)
+	for part := range strings.SplitSeq(s, ",") {
+		if part == "" {
+			continue
+		}
+		wd, err := parseWeekday(part)
+		if err != nil {
+			return "", err
+		}
+		if !seen[wd] {
+			seen[wd] = true
+			days = append(days, strconv.Itoa(wd))
+		}
+	}
+	return strings.Join(days, ","), nil
+}
+
+func ordinalValue(s string) int {
+	switch s {
+	case "first", "1st":
+		return 1
+	case "second", "2nd":
+		return 2
+	case "third", "3rd":
+		return 3
+	case "fourth", "4th":
+		return 4
+	default:
+		return 5
+	}
+}
@@ -0,0 +1,131 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Recurring(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	// Saturday 2026-01-10 10:00 in Shanghai.
+	now := time.Date(2026, 1, 10, 10, 0, 0, 0, shanghai)
+
+	tests := []struct {
+		phrase string
+		expr   string
+	}{
+		{"every weekday at 8:30", "30 8 * * 1-5"},
+		{"Every weekday at 8:30.", "30 8 * * 1-5"},
+		{"at 8:30 every weekday", "30 8 * * 1-5"},
+		{"every day at 7pm", "0 19 * * *"},
+		{"daily at 21:00", "0 21 * * *"},
+		{"weekends at 10am", "0 10 * * 0,6"},
+		{"every monday and thursday at 18:00", "0 18 * * 1,4"},
+		{"every mon, wed & fri at noon", "0 12 * * 1,3,5"},
+		{"mondays", "0 9 * * 1"},
+		{"weekly on sunday at midnight", "0 0 * * 0"},
+		{"every week", "0 9 * * 6"},
+		{"first Monday of each month", "0 9 * * 1#1"},
+		{"the third friday of every month at 16:45", "45 16 * * 5#3"},
+		{"last friday of the month", "0 9 * * 5L"},
+		{"last day of the month at 18:00", "0 18 L * *"},
+		{"every month on the 15th", "0 9 15 * *"},
+		{"the 1st of each month at 9:15am", "15 9 1 * *"},
+		{"monthly", "0 9 1 * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			s, err := ParseSchedule(tt.phrase, now, shanghai)
+			if err != nil {
+				t.Fatalf("ParseSchedule: %v", err)
+			}
+			if s.Kind != "cron" || s.Expr != tt.expr || s.TZ != "Asia/Shanghai" {
+				t.Fatalf("got %+v, want cron %q in Asia/Shanghai", s, tt.expr)
+			}
+			if err := ValidateSchedule(s); err != nil {
+				t.Fatalf("ValidateSchedule: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseSchedule_Intervals(t *testing.T) {
+	now := time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		phrase string
+		every  time.Duration
+	}{
+		{"every 15 minutes", 15 * time.Minute},
+		{"every hour", time.Hour},
+		{"hourly", time.Hour},
+		{"every 2 hours", 2 * time.Hour},
+		{"every thirty seconds", 30 * time.Second},
+		{"every 3 days", 72 * time.Hour},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.phrase, now, time.UTC)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.phrase, err)
+		}
+		if s.Kind != "every" || s.EveryMS == nil || *s.EveryMS != tt.every.Milliseconds() {
+			t.Fatalf("%q: got %+v, want every %v", tt.phrase, s, tt.every)
+		}
+	}
+}
+
+func TestParseSchedule_OneTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	// Saturday 2026-01-10 10:00 in Shanghai.
+	now := time.Date(2026, 1, 10, 10, 0, 0, 0, shanghai)
+
+	tests := []struct {
+		phrase string
+		want   time.Time
+	}{
+		{"in 20 minutes", now.Add(20 * time.Minute)},
+		{"in an hour", now.Add(time.Hour)},
+		{"in half an hour", now.Add(30 * time.Minute)},
+		{"in 1h30m", now.Add(90 * time.Minute)},
+		{"at 17:30", time.Date(2026, 1, 10, 17, 30, 0, 0, shanghai)},
+		{"at 9am", time.Date(2026, 1, 11, 9, 0, 0, 0, shanghai)},
+		{"today at 5pm", time.Date(2026, 1, 10, 17, 0, 0, 0, shanghai)},
+		{"tomorrow", time.Date(2026, 1, 11, 9, 0, 0, 0, shanghai)},
+		{"tomorrow at 8:30am", time.Date(2026, 1, 11, 8, 30, 0, 0, shanghai)},
+		{"on friday at noon", time.Date(2026, 1, 16, 12, 0, 0, 0, shanghai)},
+		{"saturday at 11", time.Date(2026, 1, 10, 11, 0, 0, 0, shanghai)},
+		{"saturday at 9", time.Date(2026, 1, 17, 9, 0, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.phrase, now, shanghai)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.phrase, err)
+		}
+		if s.Kind != "at" || s.AtMS == nil || *s.AtMS != tt.want.UnixMilli() {
+			t.Fatalf("%q: got %+v, want at %v", tt.phrase, s, tt.want)
+		}
+	}
+}
+
+func TestParseSchedule_Errors(t *testing.T) {
+	now := time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC)
+	for _, phrase := range []string{
+		"",
+		"whenever you feel like it",
+		"every 5 minutes at 9:00",
+		"in 20 minutes at 9:00",
+		"today at 8am",
+		"at 25:00",
+		"every month on the 32nd",
+		"every 0 minutes",
+	} {
+		if s, err := ParseSchedule(phrase, now, time.UTC); err == nil {
+			t.Errorf("%q: expected error, got %+v", phrase, s)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -29,25 +30,104 @@ type CronPayload struct {
 	Command string `json:"command,omitempty"`
 	Channel string `json:"channel,omitempty"`
 	To      string `json:"to,omitempty"`
+	// Workflow names a workspace workflow to run instead of an agent turn;
+	// Message is its input.
+	Workflow string `json:"workflow,omitempty"`
+	// Silent keeps the output of a run to the job itself, e.g. for a job
+	// whose output only feeds a condition of its post-run actions.
+	Silent bool `json:"silent,omitempty"`
+	// Then lists actions evaluated after each run, such as triggering
+	// another job when this one succeeds.
+	Then []CronAction `json:"then,omitempty"`
 }
 
 type CronJobState struct {
-	NextRunAtMS *int64 `json:"nextRunAtMs,omitempty"`
-	LastRunAtMS *int64 `json:"lastRunAtMs,omitempty"`
-	LastStatus  string `json:"lastStatus,omitempty"`
-	LastError   string `json:"lastError,omitempty"`
+	NextRunAtMS    *int64 `json:"nextRunAtMs,omitempty"`
+	LastRunAtMS    *int64 `json:"lastRunAtMs,omitempty"`
+	LastStatus     string `json:"lastStatus,omitempty"`
+	LastError      string `json:"lastError,omitempty"`
+	LastDurationMS int64  `json:"lastDurationMs,omitempty"`
+	// NextTrigger records why NextRunAtMS was set when it is not a regular
+	// scheduled run (TriggerCatchUp or TriggerRetry).
+	NextTrigger string `json:"nextTrigger,omitempty"`
+	RetryCount  int    `json:"retryCount,omitempty"`
+	// PendingRuns counts catch-up runs still owed after the next one.
+	PendingRuns int `json:"pendingRuns,omitempty"`
+}
+
+// Misfire policy modes. A misfire is a scheduled run that was due while the
+// service was not running.
+const (
+	MisfireSkip    = "skip"
+	MisfireRunOnce = "run_once"
+	MisfireRunAll  = "run_all"
+)
+
+// maxCatchUpRuns caps how many missed runs MisfireRunAll replays.
+const maxCatchUpRuns = 24
+
+// MisfirePolicy decides what happens to runs missed while the device was off.
+type MisfirePolicy struct {
+	// Mode is one of MisfireSkip (default), MisfireRunOnce or MisfireRunAll.
+	Mode string `json:"mode,omitempty"`
+	// GraceMS limits catch-up to runs missed within this window; 0 means no limit.
+	GraceMS int64 `json:"graceMs,omitempty"`
+}
+
+// RetryPolicy reschedules failed runs with exponential backoff.
+type RetryPolicy struct {
+	MaxRetries   int   `json:"maxRetries,omitempty"`
+	BackoffMS    int64 `json:"backoffMs,omitempty"`
+	MaxBackoffMS int64 `json:"maxBackoffMs,omitempty"`
+}
+
+const (
+	defaultRetryBackoffMS    = 30 * 1000
+	defaultRetryMaxBackoffMS = 60 * 60 * 1000
+)
+
+// Delay returns the backoff before the given retry attempt (1-based).
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	backoff := p.BackoffMS
+	if backoff <= 0 {
+		backoff = defaultRetryBackoffMS
+	}
+	maxBackoff := p.MaxBackoffMS
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoffMS
+	}
+	delay := backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return time.Duration(min(delay, maxBackoff)) * time.Millisecond
+}
+
+// ValidateMisfireMode reports whether mode is a known misfire policy mode.
+func ValidateMisfireMode(mode string) error {
+	switch mode {
+	case "", MisfireSkip, MisfireRunOnce, MisfireRunAll:
+		return nil
+	default:
+		return fmt.Errorf("unknown misfire mode %q (want %s, %s or %s)", mode, MisfireSkip, MisfireRunOnce, MisfireRunAll)
+	}
 }
 
 type CronJob struct {
-	ID             string       `json:"id"`
-	Name           string       `json:"name"`
-	Enabled        bool         `json:"enabled"`
-	Schedule       CronSchedule `json:"schedule"`
-	Payload        CronPayload  `json:"payload"`
-	State          CronJobState `json:"state"`
-	CreatedAtMS    int64        `json:"createdAtMs"`
-	UpdatedAtMS    int64        `json:"updatedAtMs"`
-	DeleteAfterRun bool         `json:"deleteAfterRun"`
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Enabled        bool          `json:"enabled"`
+	Schedule       CronSchedule  `json:"schedule"`
+	Payload        CronPayload   `json:"payload"`
+	State          CronJobState  `json:"state"`
+	CreatedAtMS    int64         `json:"createdAtMs"`
+	UpdatedAtMS    int64         `json:"updatedAtMs"`
+	DeleteAfterRun bool          `json:"deleteAfterRun"`
+	Misfire        MisfirePolicy `json:"misfire,omitzero"`
+	Retry          RetryPolicy   `json:"retry,omitzero"`
+	// Input carries the output of the job that triggered this run through a
+	// post-run action. It is only set on the copy passed to the JobHandler.
+	Input string `json:"-"`
 }
 
 type CronStore struct {
@@ -60,7 +140,9 @@ type JobHandler func(job *CronJob) (string, error)
 type CronService struct {
 	storePath string
 	store     *CronStore
+	history   *historyStore
 	onJob     JobHandler
+	complete  CompleteFunc
 	mu        sync.RWMutex
 	running   bool
 	stopChan  chan struct{}
@@ -71,6 +153,7 @@ type CronService struct {
 func NewCronService(storePath string, onJob JobHandler) *CronService {
 	cs := &CronService{
 		storePath: storePath,
+		history:   newHistoryStore(storePath),
 		onJob:     onJob,
 		gronx:     gronx.New(),
 		wakeChan:  make(chan struct{}),
@@ -92,7 +175,7 @@ func (cs *CronService) Start() error {
 		return fmt.Errorf("failed to load store: %w", err)
 	}
 
-	cs.recomputeNextRuns()
+	cs.recoverMissedRuns()
 	if err := cs.saveStoreUnsafe(); err != nil {
 		return fmt.Errorf("failed to save store: %w", err)
 	}
@@ -212,6 +295,12 @@ func (cs *CronService) checkJobs() {
 }
 
 func (cs *CronService) executeJobByID(jobID string) {
+	cs.runJob(jobID, chainState{})
+}
+
+// runJob executes a job, records the run and updates its state. Runs started
+// by another job's post-run action leave the job's own schedule untouched.
+func (cs *CronService) runJob(jobID string, chain chainState) {
 	startTime := time.Now().UnixMilli()
 
 	cs.mu.RLock()
@@ -224,25 +313,74 @@ func (cs *CronService) executeJobByID(jobID string) {
 			break
 		}
 	}
+	onJob := cs.onJob
 	cs.mu.RUnlock()
 
 	if callbackJob == nil {
 		log.Printf("[cron] job %s not found, skipping", jobID)
 		return
 	}
+	if chain.parent != "" && !callbackJob.Enabled {
+		log.Printf("[cron] job '%s' is disabled, skipping chained run from %s", callbackJob.Name, chain.parent)
+		return
+	}
+	callbackJob.Input = chain.input
 
 	// Log job execution start
 	log.Printf("[cron] ▶ executing job '%s' (id: %s, schedule: %s, channel: %s)",
 		callbackJob.Name, jobID, callbackJob.Schedule.Kind, callbackJob.Payload.Channel)
 
-	var err error
-	if cs.onJob != nil {
-		_, err = cs.onJob(callbackJob)
+	var (
+		output string
+		err    error
+	)
+	if onJob != nil {
+		output, err = onJob(callbackJob)
 	}
 
 	execDuration := time.Now().UnixMilli() - startTime
 
-	// Now acquire lock to update state
+	trigger := callbackJob.State.NextTrigger
+	attempt := callbackJob.State.RetryCount + 1
+	if chain.parent != "" {
+		trigger, attempt = TriggerChain, 1
+	} else if trigger == "" {
+		trigger = TriggerSchedule
+	}
+	run := CronRun{
+		JobID:       jobID,
+		StartedAtMS: startTime,
+		DurationMS:  execDuration,
+		Status:      RunStatusOK,
+		Trigger:     trigger,
+		TriggeredBy: chain.parent,
+		Attempt:     attempt,
+		Output:      truncateOutput(output),
+		DeliveredTo: deliveryTarget(callbackJob.Payload),
+	}
+	if err != nil {
+		run.Status = RunStatusError
+		run.Error = err.Error()
+	}
+	// Update the job first, so recording the run does not delay it.
+	actions, retrying := cs.finishRun(jobID, startTime, execDuration, err, chain.parent != "")
+	if histErr := cs.history.append(run); histErr != nil {
+		log.Printf("[cron] failed to record run of job %s: %v", jobID, histErr)
+	}
+	if !retrying {
+		cs.runActions(callbackJob, actions, output, err, chain)
+	}
+}
+
+// finishRun updates a job's state after a run and schedules the next one. It
+// returns the job's post-run actions and whether a retry was scheduled, in
+// which case the actions wait for the final attempt.
+func (cs *CronService) finishRun(
+	jobID string,
+	startTime, execDuration int64,
+	err error,
+	chained bool,
+) (actions []CronAction, retrying bool) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
@@ -255,11 +393,14 @@ func (cs *CronService) executeJobByID(jobID string) {
 	}
 	if job == nil {
 		log.Printf("[cron] job %s disappeared before state update", jobID)
-		return
+		return nil, false
 	}
+	actions = slices.Clone(job.Payload.Then)
 
+	now := time.Now().UnixMilli()
 	job.State.LastRunAtMS = &startTime
-	job.UpdatedAtMS = time.Now().UnixMilli()
+	job.State.LastDurationMS = execDuration
+	job.UpdatedAtMS = now
 
 	if err != nil {
 		job.State.LastStatus = "error"
@@ -270,9 +411,36 @@ func (cs *CronService) executeJobByID(jobID string) {
 		job.State.LastError = ""
 	}
 
+	if chained {
+		if err == nil {
+			log.Printf("[cron] ✓ job '%s' completed in %dms (chained)", job.Name, execDuration)
+		}
+		if err := cs.saveStoreUnsafe(); err != nil {
+			log.Printf("[cron] failed to save store: %v", err)
+		}
+		return actions, false
+	}
+	job.State.NextTrigger = ""
+
 	// Compute next run time
 	var nextRunStr string
-	if job.Schedule.Kind == "at" {
+	switch {
+	case err != nil && job.State.RetryCount < job.Retry.MaxRetries:
+		job.State.RetryCount++
+		next := now + job.Retry.Delay(job.State.RetryCount).Milliseconds()
+		job.State.NextRunAtMS = &next
+		job.State.NextTrigger = TriggerRetry
+		retrying = true
+		log.Printf("[cron] ↻ job '%s' retry %d/%d scheduled at %s", job.Name,
+			job.State.RetryCount, job.Retry.MaxRetries, time.UnixMilli(next).Format("2006-01-02 15:04:05"))
+	case job.State.PendingRuns > 0:
+		job.State.RetryCount = 0
+		job.State.PendingRuns--
+		job.State.NextRunAtMS = &now
+		job.State.NextTrigger = TriggerCatchUp
+		nextRunStr = "(catch-up)"
+	case job.Schedule.Kind == "at":
+		job.State.RetryCount = 0
 		if job.DeleteAfterRun {
 			cs.removeJobUnsafe(job.ID)
 			nextRunStr = "(deleted)"
@@ -281,8 +449,9 @@ func (cs *CronService) executeJobByID(jobID string) {
 			job.State.NextRunAtMS = nil
 			nextRunStr = "(disabled)"
 		}
-	} else {
-		nextRun := cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
+	default:
+		job.State.RetryCount = 0
+		nextRun := cs.computeNextRun(&job.Schedule, now)
 		job.State.NextRunAtMS = nextRun
 		if nextRun != nil {
 			nextRunStr = time.UnixMilli(*nextRun).Format("2006-01-02 15:04:05")
@@ -298,6 +467,15 @@ func (cs *CronService) executeJobByID(jobID string) {
 	if err := cs.saveStoreUnsafe(); err != nil {
 		log.Printf("[cron] failed to save store: %v", err)
 	}
+	return actions, retrying
+}
+
+// deliveryTarget formats where a job's output is delivered.
+func deliveryTarget(p CronPayload) string {
+	if p.Silent || (p.Channel == "" && p.To == "") {
+		return ""
+	}
+	return p.Channel + ":" + p.To
 }
 
 func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int64 {
@@ -313,14 +491,19 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 		}
 		next := nowMS + *schedule.EveryMS
 		return &next
+	case ScheduleManual:
+		return nil
 	case "cron":
 		if schedule.Expr == "" {
 			return nil
 		}
 
-		// Use gronx to calculate next run time
-		now := time.UnixMilli(nowMS)
-		nextTime, err := gronx.NextTickAfter(schedule.Expr, now, false)
+		loc, err := LoadLocation(schedule.TZ)
+		if err != nil {
+			log.Printf("[cron] unknown timezone '%s' for expr '%s': %v", schedule.TZ, schedule.Expr, err)
+			return nil
+		}
+		nextTime, err := nextCronTick(schedule.Expr, loc, time.UnixMilli(nowMS))
 		if err != nil {
 			log.Printf("[cron] failed to compute next run for expr '%s': %v", schedule.Expr, err)
 			return nil
@@ -343,14 +526,101 @@ func (cs *CronService) notify() {
 	}
 }
 
-func (cs *CronService) recomputeNextRuns() {
+// recoverMissedRuns applies each job's misfire policy to runs that came due
+// while the service was stopped, then schedules the next run.
+func (cs *CronService) recoverMissedRuns() {
 	now := time.Now().UnixMilli()
+	known := make(map[string]bool, len(cs.store.Jobs))
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled {
+		known[job.ID] = true
+		if !job.Enabled {
+			continue
+		}
+
+		due, missed := cs.missedRuns(job, now)
+		if due+missed > 0 {
+			log.Printf("[cron] job '%s' missed %d run(s) while stopped (misfire: %s)",
+				job.Name, due+missed, misfireMode(job.Misfire))
+		}
+
+		switch {
+		case due > 0 && misfireMode(job.Misfire) == MisfireRunOnce:
+			missed += due - 1
+			job.State.NextRunAtMS = &now
+			job.State.NextTrigger = TriggerCatchUp
+			job.State.PendingRuns = 0
+		case due > 0 && misfireMode(job.Misfire) == MisfireRunAll:
+			runs := min(due, maxCatchUpRuns)
+			missed += due - runs
+			job.State.NextRunAtMS = &now
+			job.State.NextTrigger = TriggerCatchUp
+			job.State.PendingRuns = runs - 1
+		default:
+			missed += due
 			job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+			job.State.NextTrigger = ""
+			job.State.PendingRuns = 0
+		}
+		job.State.RetryCount = 0
+
+		if missed > 0 {
+			if err := cs.history.append(CronRun{
+				JobID:       job.ID,
+				StartedAtMS: now,
+				Status:      RunStatusMissed,
+				Error:       fmt.Sprintf("%d scheduled run(s) skipped while the service was stopped", missed),
+				DeliveredTo: deliveryTarget(job.Payload),
+			}); err != nil {
+				log.Printf("[cron] failed to record missed runs of job %s: %v", job.ID, err)
+			}
 		}
 	}
+	cs.history.prune(known, time.Now())
+}
+
+// missedRuns counts the runs of job that came due before nowMS. Runs inside
+// the misfire grace window are returned as due, older ones as missed.
+func (cs *CronService) missedRuns(job *CronJob, nowMS int64) (due, missed int) {
+	if job.State.NextRunAtMS == nil || *job.State.NextRunAtMS > nowMS {
+		return 0, 0
+	}
+
+	graceStart := int64(0)
+	if job.Misfire.GraceMS > 0 {
+		graceStart = nowMS - job.Misfire.GraceMS
+	}
+	count := func(at int64) {
+		if at >= graceStart {
+			due++
+		} else {
+			missed++
+		}
+	}
+
+	at := *job.State.NextRunAtMS
+	// A pending retry or catch-up run is a single owed run, whatever the schedule.
+	if job.State.NextTrigger != "" || job.Schedule.Kind == "at" {
+		count(at)
+		return due, missed
+	}
+
+	for i := 0; at <= nowMS && i < 10*maxCatchUpRuns; i++ {
+		count(at)
+		next := cs.computeNextRun(&job.Schedule, at)
+		if next == nil || *next <= at {
+			break
+		}
+		at = *next
+	}
+	return due, missed
+}
+
+func misfireMode(p MisfirePolicy) string {
+	if p.Mode == "" {
+		return MisfireSkip
+	}
+	return p.Mode
 }
 
 func (cs *CronService) getNextWakeMS() *int64 {
@@ -371,6 +641,38 @@ func (cs *CronService) Load() error {
 	return cs.loadStore()
 }
 
+// SetHistoryLimits configures run history retention. Non-positive values
+// keep the defaults.
+func (cs *CronService) SetHistoryLimits(maxRuns int, maxAge time.Duration) {
+	cs.history.setLimits(maxRuns, maxAge)
+}
+
+// History returns the recorded runs of a job, newest first. A limit of zero
+// or less returns every retained run.
+func (cs *CronService) History(jobID string, limit int) ([]CronRun, error) {
+	runs, err := cs.history.read(jobID)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(runs)
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+// GetJob returns a copy of the job with the given ID.
+func (cs *CronService) GetJob(jobID string) (*CronJob, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	for _, job := range cs.store.Jobs {
+		if job.ID == jobID {
+			return &job, true
+		}
+	}
+	return nil, false
+}
+
 func (cs *CronService) SetOnJob(handler JobHandler) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -410,6 +712,10 @@ func (cs *CronService) AddJob(
 	message string,
 	channel, to string,
 ) (*CronJob, error) {
+	if err := ValidateSchedule(schedule); err != nil {
+		return nil, err
+	}
+
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
@@ -468,7 +774,11 @@ func (cs *CronService) RemoveJob(jobID string) bool {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
-	return cs.removeJobUnsafe(jobID)
+	removed := cs.removeJobUnsafe(jobID)
+	if removed {
+		cs.history.remove(jobID)
+	}
+	return removed
 }
 
 func (cs *CronService) removeJobUnsafe(jobID string) bool {
@@ -483,6 +793,7 @@ func (cs *CronService) removeJobUnsafe(jobID string) bool {
 	removed := len(cs.store.Jobs) < before
 
 	if removed {
+		cs.dropActionsTo(jobID)
 		if err := cs.saveStoreUnsafe(); err != nil {
 			log.Printf("[cron] failed to save store after remove: %v", err)
 		}
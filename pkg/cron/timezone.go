@@ -0,0 +1,133 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adhocore/gronx"
+)
+
+// maxWallClockTicks bounds the search for a tick that maps to a real instant
+// after the reference time. Only ticks inside a DST gap can collapse onto the
+// same instant, so a handful of extra iterations is always enough.
+const maxWallClockTicks = 128
+
+// LoadLocation resolves a job timezone. An empty name means the local
+// timezone of the machine running the gateway.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// ValidateSchedule reports whether a schedule can be computed, including its
+// cron expression and timezone.
+func ValidateSchedule(s CronSchedule) error {
+	if _, err := LoadLocation(s.TZ); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", s.TZ, err)
+	}
+	switch s.Kind {
+	case "at":
+		if s.AtMS == nil {
+			return fmt.Errorf("one-time schedule needs a time")
+		}
+	case "every":
+		if s.EveryMS == nil || *s.EveryMS <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+	case ScheduleManual:
+	case "cron":
+		if !gronx.IsValid(s.Expr) {
+			return fmt.Errorf("invalid cron expression %q", s.Expr)
+		}
+	default:
+		return fmt.Errorf("unknown schedule kind %q", s.Kind)
+	}
+	return nil
+}
+
+// nextCronTick returns the first instant after ref at which expr is due on
+// the wall clock of loc.
+//
+// Cron fields are matched against local wall-clock time, so DST transitions
+// follow these rules:
+//   - a time skipped by a spring-forward transition fires once, at the
+//     moment the clock jumps;
+//   - a time repeated by a fall-back transition fires once, on its first
+//     occurrence.
+func nextCronTick(expr string, loc *time.Location, ref time.Time) (time.Time, error) {
+	// gronx walks calendar fields and assumes every hour exists exactly once,
+	// which holds in UTC. Search there on "naive" wall-clock values and map
+	// each tick back into loc.
+	wall := ref.In(loc)
+	naive := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, time.UTC)
+
+	for range maxWallClockTicks {
+		tick, err := gronx.NextTickAfter(expr, naive, false)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if at := wallClockInstant(tick, loc); at.After(ref) {
+			return at, nil
+		}
+		naive = tick
+	}
+	return time.Time{}, fmt.Errorf("no upcoming run for %q", expr)
+}
+
+// wallClockInstant maps a naive wall-clock time (expressed in UTC) to the
+// instant it denotes in loc.
+func wallClockInstant(naive time.Time, loc *time.Location) time.Time {
+	t := time.Date(naive.Year(), naive.Month(), naive.Day(), naive.Hour(), naive.Minute(), naive.Second(), 0, loc)
+	if sameWallClock(t, naive) {
+		return firstOccurrence(t)
+	}
+
+	// The wall-clock time falls into a spring-forward gap and time.Date moved
+	// it to one side of the gap. The clock jumps at the boundary of the zone
+	// period it landed in.
+	start, end := t.ZoneBounds()
+	landed := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	if landed.Before(naive) && !end.IsZero() {
+		return end
+	}
+	if !start.IsZero() {
+		return start
+	}
+	return t
+}
+
+// firstOccurrence returns the earlier instant when t's wall-clock time is
+// repeated by a fall-back transition, and t otherwise.
+func firstOccurrence(t time.Time) time.Time {
+	start, _ := t.ZoneBounds()
+	if start.IsZero() {
+		return t
+	}
+	_, offset := t.Zone()
+	_, prevOffset := start.Add(-time.Second).Zone()
+	if prevOffset <= offset {
+		return t
+	}
+	if earlier := t.Add(-time.Duration(prevOffset-offset) * time.Second); earlier.Before(start) &&
+		sameWallClock(earlier, t) {
+		return earlier
+	}
+	return t
+}
+
+func sameWallClock(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay() &&
+		a.Hour() == b.Hour() && a.Minute() == b.Minute() && a.Second() == b.Second()
+}
+
+// tzName returns the IANA name stored on a schedule for loc. The machine's
+// local zone is stored as empty so jobs follow it if it changes.
+func tzName(loc *time.Location) string {
+	if loc == nil || loc == time.Local || strings.EqualFold(loc.String(), "Local") {
+		return ""
+	}
+	return loc.String()
+}
@@ -0,0 +1,101 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestComputeNextRun_HonoursTimezone(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	cs := &CronService{}
+
+	// 2026-01-10 00:00 UTC is 08:00 in Shanghai; the 08:30 run is 30 minutes away.
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	next := cs.computeNextRun(&CronSchedule{Kind: "cron", Expr: "30 8 * * *", TZ: "Asia/Shanghai"}, now.UnixMilli())
+	if next == nil {
+		t.Fatal("expected a next run")
+	}
+	want := time.Date(2026, 1, 10, 8, 30, 0, 0, shanghai)
+	if got := time.UnixMilli(*next); !got.Equal(want) {
+		t.Fatalf("next run = %v, want %v", got.In(shanghai), want)
+	}
+}
+
+func TestNextCronTick_DST(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name string
+		expr string
+		ref  time.Time
+		want time.Time
+	}{
+		{
+			// 02:30 does not exist on 2026-03-08; it fires when the clock jumps to 03:00 EDT.
+			name: "spring forward gap",
+			expr: "30 2 * * *",
+			ref:  time.Date(2026, 3, 8, 0, 0, 0, 0, ny),
+			want: time.Date(2026, 3, 8, 3, 0, 0, 0, ny),
+		},
+		{
+			name: "day after gap",
+			expr: "30 2 * * *",
+			ref:  time.Date(2026, 3, 8, 3, 0, 0, 0, ny),
+			want: time.Date(2026, 3, 9, 2, 30, 0, 0, ny),
+		},
+		{
+			name: "regular time across spring forward",
+			expr: "0 9 * * *",
+			ref:  time.Date(2026, 3, 7, 10, 0, 0, 0, ny),
+			want: time.Date(2026, 3, 8, 9, 0, 0, 0, ny),
+		},
+		{
+			// 01:30 happens twice on 2026-11-01; only the first (EDT) occurrence fires.
+			name: "fall back first occurrence",
+			expr: "30 1 * * *",
+			ref:  time.Date(2026, 11, 1, 0, 0, 0, 0, ny),
+			want: time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "fall back does not repeat",
+			expr: "30 1 * * *",
+			ref:  time.Date(2026, 11, 1, 5, 31, 0, 0, time.UTC),
+			want: time.Date(2026, 11, 2, 1, 30, 0, 0, ny),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextCronTick(tt.expr, ny, tt.ref)
+			if err != nil {
+				t.Fatalf("nextCronTick: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("next = %v, want %v", got.In(ny), tt.want.In(ny))
+			}
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	if err := ValidateSchedule(CronSchedule{Kind: "cron", Expr: "0 9 * * *", TZ: "Mars/Olympus"}); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+	if err := ValidateSchedule(CronSchedule{Kind: "cron", Expr: "not a cron"}); err == nil {
+		t.Error("expected error for invalid expression")
+	}
+	if err := ValidateSchedule(CronSchedule{Kind: "every", EveryMS: int64Ptr(0)}); err == nil {
+		t.Error("expected error for zero interval")
+	}
+	if err := ValidateSchedule(CronSchedule{Kind: "cron", Expr: "0 9 * * 1#1", TZ: "Europe/Berlin"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -15,13 +15,14 @@ import (
 )
 
 type Service struct {
-	bus     *bus.MessageBus
-	state   *state.Manager
-	sources []events.EventSource
-	enabled bool
-	ctx     context.Context
-	cancel  context.CancelFunc
-	mu      sync.RWMutex
+	bus       *bus.MessageBus
+	state     *state.Manager
+	sources   []events.EventSource
+	listeners []func(*events.DeviceEvent)
+	enabled   bool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mu        sync.RWMutex
 }
 
 type Config struct {
@@ -50,6 +51,14 @@ func (s *Service) SetBus(msgBus *bus.MessageBus) {
 	s.bus = msgBus
 }
 
+// AddListener registers fn to receive every device event in addition to
+// the notification sent to the last active channel.
+func (s *Service) AddListener(fn func(*events.DeviceEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
 func (s *Service) Start(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -101,6 +110,12 @@ func (s *Service) handleEvents(kind events.Kind, eventCh <-chan *events.DeviceEv
 		if ev == nil {
 			continue
 		}
+		s.mu.RLock()
+		listeners := s.listeners
+		s.mu.RUnlock()
+		for _, fn := range listeners {
+			fn(ev)
+		}
 		s.sendNotification(ev)
 	}
 }
@@ -0,0 +1,253 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pdfBuilder writes a minimal PDF. It omits the cross-reference table,
+// which PDF does not read.
+type pdfBuilder struct{ buf bytes.Buffer }
+
+func newPDFBuilder() *pdfBuilder {
+	b := &pdfBuilder{}
+	b.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	return b
+}
+
+func (b *pdfBuilder) object(num int, body string) {
+	fmt.Fprintf(&b.buf, "%d 0 obj\n%s\nendobj\n", num, body)
+}
+
+func (b *pdfBuilder) stream(num int, dict string, data []byte, compress bool) {
+	if compress {
+		var z bytes.Buffer
+		w := zlib.NewWriter(&z)
+		w.Write(data)
+		w.Close()
+		data = z.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	fmt.Fprintf(&b.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n%s\nendstream\nendobj\n", num, dict, len(data), data)
+}
+
+func (b *pdfBuilder) finish(trailer string) []byte {
+	fmt.Fprintf(&b.buf, "trailer\n%s\n%%%%EOF\n", trailer)
+	return b.buf.Bytes()
+}
+
+func TestPDF(t *testing.T) {
+	b := newPDFBuilder()
+	b.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	b.object(2, "<< /Type /Pages /Kids [3 0 R 6 0 R] /Count 2 /Resources << /Font << /F1 4 0 R /F2 7 0 R >> >> >>")
+	b.object(3, "<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>")
+	b.object(4, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /Differences [39 /quoteright] >> >>")
+	b.stream(5, "", []byte("BT /F1 12 Tf 72 720 Td (Hello, PDF world) Tj\n"+
+		"0 -14 Td [(Second)-300(line)] TJ (It\\047s) ' ET\n"+
+		"BI /W 2 /H 1 /BPC 8 /CS /G ID \x00\xff EI\n"), true)
+
+	// Page 6 and its Type0 font live in an object stream.
+	objs := []string{
+		"<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Subset /Encoding /Identity-H /ToUnicode 9 0 R >>",
+	}
+	header := fmt.Sprintf("6 0 7 %d ", len(objs[0])+1)
+	b.stream(10, fmt.Sprintf("/Type /ObjStm /N 2 /First %d", len(header)), []byte(header+objs[0]+" "+objs[1]), true)
+	b.stream(8, "", []byte("BT /F2 10 Tf <000100020003> Tj ET"), false)
+	b.stream(9, "", []byte("/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n"+
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n"+
+		"1 beginbfchar <0001> <0048> endbfchar\n"+
+		"1 beginbfrange <0002> <0003> <0069> endbfrange\n"+
+		"endcmap CMapName currentdict /CMap defineresource pop end end"), true)
+	b.object(11, "<< /Title (Quarterly \\(draft\\)) /Author <FEFF0041006E006E>"+
+		" /CreationDate (D:20240131120000+01'00') >>")
+	data := b.finish("<< /Root 1 0 R /Info 11 0 R /Size 12 >>")
+
+	kind, ok := Detect("application/octet-stream", data, "https://example.com/report")
+	require.True(t, ok)
+	require.Equal(t, KindPDF, kind)
+
+	doc, err := Convert(kind, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, PDF world\nSecond line\nIt’s\n\nHij", doc.Text)
+	assert.Equal(t, Metadata{
+		Title:     "Quarterly (draft)",
+		Author:    "Ann",
+		Published: "2024-01-31T12:00:00+01:00",
+	}, doc.Metadata)
+}
+
+func TestPDFEncrypted(t *testing.T) {
+	b := newPDFBuilder()
+	b.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	b.object(2, "<< /Type /Pages /Kids [] /Count 0 >>")
+	_, err := PDF(b.finish("<< /Root 1 0 R /Encrypt 3 0 R >>"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "encrypted")
+}
+
+func TestPDFInflateLimits(t *testing.T) {
+	pdfWithPages := func(pages ...[]byte) []byte {
+		b := newPDFBuilder()
+		b.object(1, "<< /Type /Catalog /Pages 3 0 R >>")
+		b.object(2, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+		kids := make([]string, len(pages))
+		for i, content := range pages {
+			num := 10 + 2*i
+			kids[i] = fmt.Sprintf("%d 0 R", num)
+			b.object(num, fmt.Sprintf("<< /Type /Page /Parent 3 0 R /Contents %d 0 R >>", num+1))
+			b.stream(num+1, "", content, true)
+		}
+		b.object(3, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /Resources << /Font << /F1 2 0 R >> >> >>",
+			strings.Join(kids, " "), len(pages)))
+		return b.finish("<< /Root 1 0 R >>")
+	}
+	text := []byte("BT /F1 12 Tf (Still here) Tj ET")
+
+	// An oversized stream is skipped; the rest of the document is kept.
+	doc, err := PDF(pdfWithPages(make([]byte, maxPDFStream+1), text))
+	require.NoError(t, err)
+	assert.Equal(t, "Still here", doc.Text)
+
+	// Streams that together inflate past the document budget fail it.
+	big := make([]byte, maxPDFStream)
+	_, err = PDF(pdfWithPages(big, big, big, big, text))
+	assert.ErrorIs(t, err, errPDFTooLarge)
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+const coreXML = `<?xml version="1.0"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"
+ xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/">
+<dc:title>Budget</dc:title><dc:creator>Ann</dc:creator><dcterms:created>2024-01-01T00:00:00Z</dcterms:created>
+</cp:coreProperties>`
+
+func TestDOCX(t *testing.T) {
+	const w = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	data := buildZip(t, map[string]string{
+		"docProps/core.xml": coreXML,
+		"word/document.xml": `<w:document ` + w + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Plan</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Hello </w:t></w:r><w:r><w:t>world</w:t><w:tab/><w:t>again</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>first item</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>a</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>b</w:t></w:r></w:p>
+<w:p><w:r><w:t>c</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+</w:body></w:document>`,
+	})
+
+	kind, ok := Detect("application/octet-stream", data, "")
+	require.True(t, ok)
+	require.Equal(t, KindDOCX, kind)
+
+	doc, err := DOCX(data)
+	require.NoError(t, err)
+	assert.Equal(t, "# Plan\nHello world\tagain\n- first item\na\tb c", doc.Text)
+	assert.Equal(t, Metadata{Title: "Budget", Author: "Ann", Published: "2024-01-01T00:00:00Z"}, doc.Metadata)
+}
+
+func TestXLSX(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"docProps/core.xml": coreXML,
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+ xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Costs" sheetId="1" r:id="rId2"/>
+<sheet name="Notes" sheetId="2" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships
+ xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet2.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet1.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>Item</t></si><si><t>Cost</t></si>
+<si><r><t>Rent, </t></r><r><t>office</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>1200.5</v></c><c r="D2" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>draft</t></is></c></row>
+</sheetData></worksheet>`,
+	})
+
+	kind, ok := Detect("", data, "https://example.com/budget.xlsx")
+	require.True(t, ok)
+	require.Equal(t, KindXLSX, kind)
+
+	doc, err := XLSX(data)
+	require.NoError(t, err)
+	assert.Equal(t, "## Costs\n\nItem,Cost\n\"Rent, office\",,1200.5,TRUE\n\n## Notes\n\ndraft", doc.Text)
+	assert.Equal(t, "Budget", doc.Title)
+}
+
+func TestEPUB(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"mimetype": "application/epub+zip",
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"
+ media-type="application/oebps-package+xml"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<metadata><dc:title>Tales</dc:title><dc:creator>Ann</dc:creator><dc:creator>Bob</dc:creator>
+<dc:date>2020-05-01</dc:date></metadata>
+<manifest><item id="c1" href="text/one.xhtml"/><item id="c2" href="text/two.xhtml"/></manifest>
+<spine><itemref idref="c2"/><itemref idref="c1"/></spine></package>`,
+		"OEBPS/text/one.xhtml": `<html><body><h1>Chapter Two</h1><p>The end.</p></body></html>`,
+		"OEBPS/text/two.xhtml": `<html><head><title>x</title></head>
+<body><h1>Chapter One</h1><p>Once upon a time.</p></body></html>`,
+	})
+
+	kind, ok := Detect("application/epub+zip", data, "")
+	require.True(t, ok)
+	doc, err := Convert(kind, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Chapter One\n\nOnce upon a time.\n\nChapter Two\n\nThe end.", doc.Text)
+	assert.Equal(t, Metadata{Title: "Tales", Author: "Ann, Bob", Published: "2020-05-01"}, doc.Metadata)
+}
+
+func TestZipLimits(t *testing.T) {
+	_, err := DOCX(buildZip(t, map[string]string{
+		"word/document.xml": strings.Repeat(" ", maxZipEntry+1),
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "word/document.xml is larger than")
+
+	zr, err := openZip(buildZip(t, map[string]string{"a": strings.Repeat(" ", maxZipEntry)}))
+	require.NoError(t, err)
+	for range maxZipTotal / maxZipEntry {
+		_, err = readZipFile(zr, "a")
+		require.NoError(t, err)
+	}
+	_, err = readZipFile(zr, "a")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "archive is larger than")
+}
+
+func TestDetect(t *testing.T) {
+	_, ok := Detect("text/html", []byte("<html>"), "https://example.com/")
+	assert.False(t, ok)
+
+	kind, ok := Detect("text/plain", []byte("a,b\n1,2\n"), "https://example.com/data.csv?dl=1")
+	require.True(t, ok)
+	assert.Equal(t, KindCSV, kind)
+
+	_, err := Convert(KindPDF, []byte("not a pdf"))
+	assert.Error(t, err)
+	_, err = Convert(KindDOCX, []byte("PK garbage"))
+	assert.True(t, err != nil && strings.Contains(err.Error(), "zip"))
+}
@@ -0,0 +1,90 @@
+package extract
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// EPUB extracts the chapters of an e-book in reading (spine) order.
+func EPUB(body []byte) (*Document, error) {
+	zr, err := openZip(body)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readZipFile(zr, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(data, &container); err != nil || len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("invalid META-INF/container.xml")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	data, err = readZipFile(zr, opfPath)
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Title    []string `xml:"metadata>title"`
+		Creators []string `xml:"metadata>creator"`
+		Date     []string `xml:"metadata>date"`
+		Items    []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", opfPath, err)
+	}
+
+	hrefs := make(map[string]string, len(pkg.Items))
+	for _, item := range pkg.Items {
+		hrefs[item.ID] = item.Href
+	}
+	dir := path.Dir(opfPath)
+	var chapters []string
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		name := path.Join(dir, strings.SplitN(href, "#", 2)[0])
+		chapter, err := readZipFile(zr, name)
+		if err != nil {
+			return nil, err
+		}
+		root, err := html.Parse(strings.NewReader(string(chapter)))
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		body := findFirst(root, atom.Body)
+		if body == nil {
+			body = root
+		}
+		if text := nodesText([]*html.Node{body}); text != "" {
+			chapters = append(chapters, text)
+		}
+	}
+
+	doc := &Document{Text: strings.Join(chapters, "\n\n")}
+	if len(pkg.Title) > 0 {
+		doc.Title = strings.TrimSpace(pkg.Title[0])
+	}
+	doc.Author = strings.TrimSpace(strings.Join(pkg.Creators, ", "))
+	if len(pkg.Date) > 0 {
+		doc.Published = strings.TrimSpace(pkg.Date[0])
+	}
+	return doc, nil
+}
@@ -0,0 +1,180 @@
+// Package extract turns fetched documents into text for the model.
+//
+// HTML pages are reduced to their main content with readability-style
+// scoring and described by their metadata. PDF, DOCX, XLSX, CSV and EPUB
+// files are converted to plain text with pure Go parsers; layout, images
+// and styling are dropped.
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Kind is a document format Convert can read.
+type Kind string
+
+const (
+	KindPDF  Kind = "pdf"
+	KindDOCX Kind = "docx"
+	KindXLSX Kind = "xlsx"
+	KindCSV  Kind = "csv"
+	KindEPUB Kind = "epub"
+)
+
+// Metadata describes a document. Fields the document does not provide are
+// left empty.
+type Metadata struct {
+	Title        string `json:"title,omitempty"`
+	Author       string `json:"author,omitempty"`
+	Published    string `json:"published,omitempty"`
+	CanonicalURL string `json:"canonical_url,omitempty"`
+	SiteName     string `json:"site_name,omitempty"`
+}
+
+// Document is the text of a non-HTML document.
+type Document struct {
+	Metadata
+	Text string
+}
+
+// Detect returns the kind of document in body from its media type, its
+// content and the extension of the URL it came from. ok is false for
+// formats this package does not convert, including HTML.
+func Detect(mediaType string, body []byte, rawURL string) (Kind, bool) {
+	ext := strings.ToLower(path.Ext(strings.SplitN(strings.SplitN(rawURL, "?", 2)[0], "#", 2)[0]))
+	switch mediaType {
+	case "application/pdf", "application/x-pdf":
+		return KindPDF, true
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return KindDOCX, true
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return KindXLSX, true
+	case "text/csv", "application/csv", "text/tab-separated-values":
+		return KindCSV, true
+	case "application/epub+zip":
+		return KindEPUB, true
+	}
+
+	if bytes.HasPrefix(body, []byte("%PDF-")) {
+		return KindPDF, true
+	}
+	if bytes.HasPrefix(body, []byte("PK\x03\x04")) {
+		if kind, ok := detectZip(body); ok {
+			return kind, true
+		}
+	}
+	if ext == ".csv" || ext == ".tsv" {
+		return KindCSV, true
+	}
+	return "", false
+}
+
+func detectZip(body []byte) (Kind, bool) {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return "", false
+	}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			return KindDOCX, true
+		case "xl/workbook.xml":
+			return KindXLSX, true
+		case "META-INF/container.xml":
+			return KindEPUB, true
+		}
+	}
+	return "", false
+}
+
+// Convert extracts the text of a document of the given kind.
+func Convert(kind Kind, body []byte) (*Document, error) {
+	switch kind {
+	case KindPDF:
+		return PDF(body)
+	case KindDOCX:
+		return DOCX(body)
+	case KindXLSX:
+		return XLSX(body)
+	case KindCSV:
+		return &Document{Text: strings.TrimSpace(string(body))}, nil
+	case KindEPUB:
+		return EPUB(body)
+	default:
+		return nil, fmt.Errorf("unsupported document kind %q", kind)
+	}
+}
+
+// Decompression limits for archive members, so a small zip bomb cannot
+// exhaust memory.
+const (
+	maxZipEntry = 16 << 20 // one member
+	maxZipTotal = 48 << 20 // all members read from one archive
+)
+
+// zipArchive is a zip file whose members are read within maxZipTotal.
+type zipArchive struct {
+	*zip.Reader
+	remaining int64
+}
+
+func openZip(body []byte) (*zipArchive, error) {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	return &zipArchive{Reader: zr, remaining: maxZipTotal}, nil
+}
+
+func readZipFile(zr *zipArchive, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		limit := min(int64(maxZipEntry), zr.remaining)
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(io.LimitReader(rc, limit+1)); err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		zr.remaining -= int64(buf.Len())
+		if int64(buf.Len()) > limit {
+			if limit < maxZipEntry {
+				return nil, fmt.Errorf("archive is larger than %d MiB uncompressed", maxZipTotal>>20)
+			}
+			return nil, fmt.Errorf("%s is larger than %d MiB uncompressed", name, maxZipEntry>>20)
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// collapseBlankLines trims every line and keeps at most one blank line
+// between paragraphs.
+func collapseBlankLines(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	blank := true
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			if !blank {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
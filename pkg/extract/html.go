@@ -0,0 +1,572 @@
+package extract
+
+import (
+	"encoding/json"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// minArticleChars is the least amount of text the main content must have;
+// shorter candidates are usually a teaser or a caption, not the article.
+const minArticleChars = 250
+
+// Article is the main content of an HTML page.
+type Article struct {
+	Metadata
+	// HTML is the main content as an HTML fragment, or the whole cleaned
+	// body when MainContent is false.
+	HTML string
+	// Text is HTML as plain text with one paragraph per line.
+	Text string
+	// MainContent reports whether a content block was singled out from
+	// the page chrome.
+	MainContent bool
+}
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|` +
+		`disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|` +
+		`sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote|cookie|newsletter|subscribe|share`)
+	maybeCandidate = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveClass  = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|` +
+		`text|blog|story`)
+	negativeClass = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|` +
+		`foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|` +
+		`skyscraper|sponsor|shopping|tags|tool|widget`)
+	sentenceEnd = regexp.MustCompile(`\.( |$)`)
+)
+
+// removedTags never carry article text.
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true, atom.Svg: true,
+	atom.Canvas: true, atom.Template: true, atom.Nav: true, atom.Aside: true, atom.Footer: true,
+	atom.Header: true, atom.Button: true, atom.Select: true, atom.Input: true,
+	atom.Textarea: true, atom.Dialog: true, atom.Object: true, atom.Embed: true, atom.Link: true,
+	atom.Meta: true,
+}
+
+var chromeRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"dialog": true, "alertdialog": true, "menu": true, "menubar": true,
+}
+
+// blockTags start a new line when rendering text.
+var blockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Pre: true, atom.Blockquote: true, atom.Table: true, atom.Tr: true, atom.Figure: true,
+	atom.Figcaption: true, atom.Hr: true, atom.Br: true, atom.Address: true, atom.Details: true,
+	atom.Summary: true, atom.Body: true,
+}
+
+// paragraphTags are blocks separated from their neighbours by a blank line.
+var paragraphTags = map[atom.Atom]bool{
+	atom.P: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Pre: true, atom.Blockquote: true, atom.Table: true,
+	atom.Figure: true, atom.Hr: true, atom.Details: true,
+}
+
+// HTML extracts the metadata and main content of an HTML page. pageURL
+// resolves a relative canonical link. When no block of the page stands out
+// as the article, the cleaned body is returned with MainContent false.
+func HTML(page, pageURL string) (*Article, error) {
+	root, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+	article := &Article{Metadata: pageMetadata(root, pageURL)}
+
+	body := findFirst(root, atom.Body)
+	if body == nil {
+		body = root
+	}
+	clean(body)
+
+	if nodes := mainContent(body); nodes != nil {
+		text := nodesText(nodes)
+		if utf8.RuneCountInString(text) >= minArticleChars {
+			article.HTML = renderNodes(nodes)
+			article.Text = text
+			article.MainContent = true
+			return article, nil
+		}
+	}
+	article.HTML = renderNodes(children(body))
+	article.Text = nodesText([]*html.Node{body})
+	return article, nil
+}
+
+// clean removes page chrome and hidden elements under n.
+func clean(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.Type == html.CommentNode:
+			n.RemoveChild(c)
+		case c.Type == html.ElementNode && isChrome(c):
+			n.RemoveChild(c)
+		default:
+			clean(c)
+		}
+		c = next
+	}
+}
+
+func isChrome(n *html.Node) bool {
+	if removedTags[n.DataAtom] {
+		return true
+	}
+	if _, hidden := getAttr(n, "hidden"); hidden || attrValue(n, "aria-hidden") == "true" {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(attrValue(n, "style")), " ", "")
+	if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	if chromeRoles[attrValue(n, "role")] {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Body, atom.Article, atom.Main, atom.A, atom.Table, atom.Tbody, atom.Tr, atom.Td, atom.Th:
+		return false
+	}
+	match := attrValue(n, "class") + " " + attrValue(n, "id")
+	return unlikelyCandidates.MatchString(match) && !maybeCandidate.MatchString(match)
+}
+
+// mainContent scores the ancestors of every paragraph-like node by the
+// amount of prose below them and returns the best candidate together with
+// siblings that look like part of the same article.
+func mainContent(body *html.Node) []*html.Node {
+	scores := map[*html.Node]float64{}
+	var order []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			order = append(order, n)
+		}
+		scores[n] += score
+	}
+
+	walk(body, func(n *html.Node) {
+		if !isParagraph(n) {
+			return
+		}
+		text := strings.TrimSpace(innerText(n))
+		length := utf8.RuneCountInString(text)
+		if length < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，"))
+		score += math.Min(float64(length/100), 3)
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+	})
+
+	var top *html.Node
+	for _, n := range order {
+		scores[n] *= 1 - linkDensity(n)
+		if top == nil || scores[n] > scores[top] {
+			top = n
+		}
+	}
+	if top == nil {
+		return nil
+	}
+	if top == body || top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := math.Max(10, scores[top]*0.2)
+	var nodes []*html.Node
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s == top {
+			nodes = append(nodes, s)
+			continue
+		}
+		if s.Type != html.ElementNode {
+			continue
+		}
+		if score, ok := scores[s]; ok && score >= threshold {
+			nodes = append(nodes, s)
+			continue
+		}
+		if s.DataAtom == atom.P {
+			text := strings.TrimSpace(innerText(s))
+			length := utf8.RuneCountInString(text)
+			density := linkDensity(s)
+			if (length >= 80 && density < 0.25) ||
+				(length > 0 && density == 0 && sentenceEnd.MatchString(text)) {
+				nodes = append(nodes, s)
+			}
+		}
+	}
+	return nodes
+}
+
+// isParagraph reports whether n is a text block: a p, pre, td or
+// blockquote, or a div or section that holds no other blocks.
+func isParagraph(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		return true
+	case atom.Div, atom.Section:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && blockTags[c.DataAtom] && c.DataAtom != atom.Br {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div, atom.Main:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	for _, v := range []string{attrValue(n, "class"), attrValue(n, "id")} {
+		if v == "" {
+			continue
+		}
+		if negativeClass.MatchString(v) {
+			score -= 25
+		}
+		if positiveClass.MatchString(v) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity is the share of n's text that is inside links.
+func linkDensity(n *html.Node) float64 {
+	total := utf8.RuneCountInString(strings.TrimSpace(innerText(n)))
+	if total == 0 {
+		return 0
+	}
+	var linked int
+	walk(n, func(c *html.Node) {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A {
+			linked += utf8.RuneCountInString(strings.TrimSpace(innerText(c)))
+		}
+	})
+	return math.Min(float64(linked)/float64(total), 1)
+}
+
+// pageMetadata reads Open Graph, Dublin Core and article meta tags,
+// JSON-LD, <time> and the canonical link, preferring the more specific
+// sources.
+func pageMetadata(root *html.Node, pageURL string) Metadata {
+	var meta Metadata
+	metaTags := map[string]string{}
+	var docTitle, canonical, timeTag string
+	var ld []map[string]any
+
+	walk(root, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Title:
+			if docTitle == "" {
+				docTitle = innerText(n)
+			}
+		case atom.Meta:
+			key := strings.ToLower(attrValue(n, "property"))
+			if key == "" {
+				key = strings.ToLower(attrValue(n, "name"))
+			}
+			if key == "" {
+				key = strings.ToLower(attrValue(n, "itemprop"))
+			}
+			if content := strings.TrimSpace(attrValue(n, "content")); key != "" && content != "" {
+				if _, seen := metaTags[key]; !seen {
+					metaTags[key] = content
+				}
+			}
+		case atom.Link:
+			if canonical == "" && strings.EqualFold(attrValue(n, "rel"), "canonical") {
+				canonical = attrValue(n, "href")
+			}
+		case atom.Time:
+			if timeTag == "" {
+				timeTag = attrValue(n, "datetime")
+			}
+		case atom.Script:
+			if strings.EqualFold(attrValue(n, "type"), "application/ld+json") {
+				ld = append(ld, jsonLD(innerText(n))...)
+			}
+		}
+	})
+
+	first := func(values ...string) string {
+		for _, v := range values {
+			if v = strings.Join(strings.Fields(v), " "); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	ldArticle := ldArticle(ld)
+	meta.Title = first(metaTags["og:title"], metaTags["twitter:title"], ldString(ldArticle, "headline"),
+		metaTags["dc.title"], docTitle)
+	meta.Author = first(ldAuthor(ldArticle), metaTags["author"], metaTags["article:author"],
+		metaTags["dc.creator"], metaTags["parsely-author"])
+	if strings.HasPrefix(meta.Author, "http://") || strings.HasPrefix(meta.Author, "https://") {
+		meta.Author = "" // article:author is often a profile URL
+	}
+	meta.Published = first(metaTags["article:published_time"], ldString(ldArticle, "datePublished"),
+		metaTags["datepublished"], metaTags["dc.date"], metaTags["date"], metaTags["parsely-pub-date"], timeTag)
+	meta.SiteName = first(metaTags["og:site_name"], metaTags["application-name"])
+	meta.CanonicalURL = resolveURL(pageURL, first(canonical, metaTags["og:url"]))
+	return meta
+}
+
+// jsonLD flattens the objects of a JSON-LD script, including @graph lists.
+func jsonLD(raw string) []map[string]any {
+	var v any
+	if json.Unmarshal([]byte(strings.TrimSpace(raw)), &v) != nil {
+		return nil
+	}
+	var out []map[string]any
+	var visit func(any)
+	visit = func(v any) {
+		switch t := v.(type) {
+		case []any:
+			for _, item := range t {
+				visit(item)
+			}
+		case map[string]any:
+			out = append(out, t)
+			if graph, ok := t["@graph"]; ok {
+				visit(graph)
+			}
+		}
+	}
+	visit(v)
+	return out
+}
+
+// ldArticle picks the first JSON-LD object describing an article.
+func ldArticle(objs []map[string]any) map[string]any {
+	for _, obj := range objs {
+		types := obj["@type"]
+		names, ok := types.([]any)
+		if !ok {
+			names = []any{types}
+		}
+		for _, name := range names {
+			if s, ok := name.(string); ok && (strings.HasSuffix(s, "Article") || s == "BlogPosting" ||
+				s == "Report" || s == "WebPage") {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+func ldString(obj map[string]any, key string) string {
+	s, _ := obj[key].(string)
+	return s
+}
+
+func ldAuthor(obj map[string]any) string {
+	var names []string
+	var visit func(any)
+	visit = func(v any) {
+		switch t := v.(type) {
+		case string:
+			names = append(names, t)
+		case map[string]any:
+			if name, ok := t["name"].(string); ok {
+				names = append(names, name)
+			}
+		case []any:
+			for _, item := range t {
+				visit(item)
+			}
+		}
+	}
+	visit(obj["author"])
+	return strings.Join(names, ", ")
+}
+
+func resolveURL(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// nodesText renders nodes as plain text with one block per line.
+// Whitespace is collapsed except inside pre elements.
+func nodesText(nodes []*html.Node) string {
+	var sb strings.Builder
+	atLineStart := func() bool {
+		s := sb.String()
+		return s == "" || strings.HasSuffix(s, "\n")
+	}
+	space := func() {
+		if !atLineStart() && !strings.HasSuffix(sb.String(), " ") && !strings.HasSuffix(sb.String(), "\t") {
+			sb.WriteString(" ")
+		}
+	}
+
+	lineBreak := func(blank bool) {
+		if !atLineStart() {
+			sb.WriteString("\n")
+		}
+		if blank && sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	var render func(*html.Node)
+	render = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if hasAncestor(n, atom.Pre) {
+				sb.WriteString(n.Data)
+				return
+			}
+			if n.Data != "" && unicode.IsSpace(rune(n.Data[0])) {
+				space()
+			}
+			if text := strings.Join(strings.Fields(n.Data), " "); text != "" {
+				sb.WriteString(text)
+				if unicode.IsSpace(rune(n.Data[len(n.Data)-1])) {
+					space()
+				}
+			}
+			return
+		case html.ElementNode:
+			if removedTags[n.DataAtom] {
+				return
+			}
+		}
+		block := n.Type == html.ElementNode && blockTags[n.DataAtom]
+		if block {
+			lineBreak(paragraphTags[n.DataAtom])
+		}
+		if n.DataAtom == atom.Li {
+			sb.WriteString("- ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			render(c)
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+				sb.WriteString("\t")
+			}
+		}
+		if block {
+			lineBreak(paragraphTags[n.DataAtom])
+		}
+	}
+	for _, n := range nodes {
+		render(n)
+	}
+	return collapseBlankLines(sb.String())
+}
+
+func renderNodes(nodes []*html.Node) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		_ = html.Render(&sb, n)
+	}
+	return sb.String()
+}
+
+func children(n *html.Node) []*html.Node {
+	var out []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		out = append(out, c)
+	}
+	return out
+}
+
+func innerText(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(c *html.Node) {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	})
+	return sb.String()
+}
+
+// walk calls fn for n and all of its descendants in document order.
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findFirst(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func hasAncestor(n *html.Node, a atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+func getAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attrValue(n *html.Node, key string) string {
+	v, _ := getAttr(n, key)
+	return v
+}
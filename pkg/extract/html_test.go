@@ -0,0 +1,87 @@
+package extract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html><head>
+<title>Ignored Title | Example News</title>
+<meta property="og:title" content="Rivers of the North">
+<meta property="og:site_name" content="Example News">
+<meta name="author" content="Meta Author">
+<link rel="canonical" href="/2024/rivers">
+<script type="application/ld+json">
+{"@context":"https://schema.org","@graph":[
+ {"@type":"WebSite","name":"Example News"},
+ {"@type":"NewsArticle","headline":"Rivers","datePublished":"2024-03-05T08:00:00Z",
+  "author":[{"@type":"Person","name":"Ada Lovelace"},{"@type":"Person","name":"Alan Turing"}]}
+]}
+</script>
+</head><body>
+<header class="site-header"><a href="/">Home</a> <a href="/world">World</a></header>
+<nav><ul><li><a href="/a">Section A</a></li><li><a href="/b">Section B</a></li></ul></nav>
+<div class="layout">
+  <div class="sidebar-widget"><p>Sign up for our newsletter, it is great, really, honestly, truly.</p></div>
+  <div class="article-body" id="story">
+    <h1>Rivers of the North</h1>
+    <p>The northern rivers freeze for months at a time, and the towns along them have adapted in many ways,
+       building roads across the ice, storing food for the winter, and trading with their neighbours.</p>
+    <p>In spring the thaw brings floods, which are dangerous, but they also bring fertile silt to the fields,
+       which farmers have relied on for centuries, planting as soon as the water recedes.</p>
+    <p>Scientists now measure the ice every week, comparing it with records kept by monks, merchants, and
+       sailors, and the data shows that the frozen season is getting shorter every decade.</p>
+  </div>
+  <div class="related-links"><a href="/x">Another story about rivers and lakes</a></div>
+</div>
+<footer>Copyright Example News</footer>
+</body></html>`
+
+func TestHTMLMainContent(t *testing.T) {
+	article, err := HTML(articlePage, "https://news.example.com/articles/1")
+	require.NoError(t, err)
+
+	assert.True(t, article.MainContent)
+	assert.Contains(t, article.Text, "The northern rivers freeze")
+	assert.Contains(t, article.Text, "the frozen season is getting shorter")
+	for _, chrome := range []string{"Section A", "newsletter", "Another story", "Copyright", "Home"} {
+		assert.NotContains(t, article.Text, chrome)
+	}
+	assert.Contains(t, article.HTML, "<h1>Rivers of the North</h1>")
+
+	assert.Equal(t, Metadata{
+		Title:        "Rivers of the North",
+		Author:       "Ada Lovelace, Alan Turing",
+		Published:    "2024-03-05T08:00:00Z",
+		CanonicalURL: "https://news.example.com/2024/rivers",
+		SiteName:     "Example News",
+	}, article.Metadata)
+}
+
+func TestHTMLShortPageFallsBackToBody(t *testing.T) {
+	article, err := HTML(`<html><head><title>Tiny</title><meta name="author" content="Bob">
+<meta property="article:published_time" content="2023-01-02"></head>
+<body><h1>Tiny Page</h1><p>Just a line.</p><script>var x = 1;</script></body></html>`, "https://example.com/")
+	require.NoError(t, err)
+
+	assert.False(t, article.MainContent)
+	assert.Equal(t, "Tiny Page\n\nJust a line.", article.Text)
+	assert.NotContains(t, article.HTML, "var x")
+	assert.Equal(t, "Tiny", article.Title)
+	assert.Equal(t, "Bob", article.Author)
+	assert.Equal(t, "2023-01-02", article.Published)
+	assert.Empty(t, article.CanonicalURL)
+}
+
+func TestHTMLTextLayout(t *testing.T) {
+	article, err := HTML(`<body><ul><li>one</li><li>two <b>bold</b></li></ul>
+<pre>  indented
+    code</pre><table><tr><td>a</td><td>b</td></tr></table></body>`, "")
+	require.NoError(t, err)
+	assert.Equal(t, "- one\n- two bold\n\n  indented\n    code\n\na\tb", article.Text)
+	assert.False(t, strings.Contains(article.Text, "  \n"))
+}
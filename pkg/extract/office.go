@@ -0,0 +1,335 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DOCX extracts the paragraphs of a Word document. Headings are prefixed
+// with Markdown hashes and list items with a dash; each table row is one
+// line with tab-separated cells.
+func DOCX(body []byte) (*Document, error) {
+	zr, err := openZip(body)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readZipFile(zr, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	var out, para, cell strings.Builder
+	var prefix string
+	var row []string
+	inText := false
+	cellDepth := 0
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse word/document.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				prefix = ""
+			case "tc":
+				cellDepth++
+				cell.Reset()
+			case "pStyle":
+				if level, ok := headingLevel(attr(t, "val")); ok {
+					prefix = strings.Repeat("#", level) + " "
+				}
+			case "numPr":
+				if prefix == "" {
+					prefix = "- "
+				}
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteByte('\t')
+			case "br", "cr":
+				para.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(para.String())
+				switch {
+				case cellDepth > 0:
+					if text != "" && cell.Len() > 0 {
+						cell.WriteByte(' ')
+					}
+					cell.WriteString(text)
+				case text != "":
+					out.WriteString(prefix + text + "\n")
+				default:
+					out.WriteString("\n")
+				}
+			case "tc":
+				cellDepth--
+				row = append(row, cell.String())
+			case "tr":
+				out.WriteString(strings.Join(row, "\t") + "\n")
+				row = row[:0]
+			case "tbl":
+				out.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+
+	doc := &Document{Text: collapseBlankLines(out.String())}
+	doc.Metadata = coreProperties(zr)
+	return doc, nil
+}
+
+// headingLevel recognizes the built-in "Heading1".."Heading6" and "Title"
+// paragraph styles.
+func headingLevel(style string) (int, bool) {
+	if style == "Title" {
+		return 1, true
+	}
+	if n, ok := strings.CutPrefix(style, "Heading"); ok {
+		if level, err := strconv.Atoi(n); err == nil && level >= 1 && level <= 6 {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// XLSX renders every worksheet of a workbook as CSV under a "## <sheet>"
+// heading, in workbook order.
+func XLSX(body []byte) (*Document, error) {
+	zr, err := openZip(body)
+	if err != nil {
+		return nil, err
+	}
+	workbook, err := readZipFile(zr, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(workbook, &wb); err != nil {
+		return nil, fmt.Errorf("parse xl/workbook.xml: %w", err)
+	}
+	targets := relationships(zr, "xl/_rels/workbook.xml.rels", "xl")
+	shared, err := sharedStrings(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var out strings.Builder
+	for i, sheet := range wb.Sheets {
+		name := targets[sheet.RID]
+		if name == "" {
+			name = fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)
+		}
+		data, err := readZipFile(zr, name)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := sheetRows(data, shared)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "## %s\n\n", sheet.Name)
+		w := csv.NewWriter(&out)
+		if err := w.WriteAll(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := &Document{Text: strings.TrimSpace(out.String())}
+	doc.Metadata = coreProperties(zr)
+	return doc, nil
+}
+
+// relationships maps relationship IDs to archive paths, resolving targets
+// relative to dir.
+func relationships(zr *zipArchive, name, dir string) map[string]string {
+	targets := map[string]string{}
+	data, err := readZipFile(zr, name)
+	if err != nil {
+		return targets
+	}
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if xml.Unmarshal(data, &rels) != nil {
+		return targets
+	}
+	for _, r := range rels.Items {
+		if strings.HasPrefix(r.Target, "/") {
+			targets[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			targets[r.ID] = path.Join(dir, r.Target)
+		}
+	}
+	return targets
+}
+
+func sharedStrings(zr *zipArchive) ([]string, error) {
+	data, err := readZipFile(zr, "xl/sharedStrings.xml")
+	if err != nil {
+		return nil, nil // workbooks without text cells have none
+	}
+	var strs []string
+	var cur strings.Builder
+	inText := false
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return strs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse xl/sharedStrings.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				cur.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				// Phonetic hints repeat the text in another script.
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "si":
+				strs = append(strs, cur.String())
+			}
+		case xml.CharData:
+			if inText {
+				cur.Write(t)
+			}
+		}
+	}
+}
+
+func sheetRows(data []byte, shared []string) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, r := range sheet.Rows {
+		var row []string
+		for _, c := range r.Cells {
+			col := len(row)
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			for len(row) < col {
+				row = append(row, "")
+			}
+			value := c.Value
+			switch c.Type {
+			case "s":
+				if i, err := strconv.Atoi(c.Value); err == nil && i >= 0 && i < len(shared) {
+					value = shared[i]
+				}
+			case "inlineStr":
+				value = c.Inline
+			case "b":
+				value = map[string]string{"0": "FALSE", "1": "TRUE"}[c.Value]
+			}
+			row = append(row, value)
+		}
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		rows = append(rows, row)
+	}
+	for len(rows) > 0 && len(rows[len(rows)-1]) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+	return rows, nil
+}
+
+// columnIndex returns the zero-based column of a cell reference like "C7".
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+// coreProperties reads the title, creator and creation date shared by all
+// Office Open XML documents.
+func coreProperties(zr *zipArchive) Metadata {
+	var meta Metadata
+	data, err := readZipFile(zr, "docProps/core.xml")
+	if err != nil {
+		return meta
+	}
+	var core struct {
+		Title   string `xml:"title"`
+		Creator string `xml:"creator"`
+		Created string `xml:"created"`
+	}
+	if xml.Unmarshal(data, &core) == nil {
+		meta.Title = strings.TrimSpace(core.Title)
+		meta.Author = strings.TrimSpace(core.Creator)
+		meta.Published = strings.TrimSpace(core.Created)
+	}
+	return meta
+}
+
+func attr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
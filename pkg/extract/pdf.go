@@ -0,0 +1,948 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDF object model. Strings are raw bytes; their meaning depends on the
+// font they are shown with.
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfString  []byte
+	pdfArray   []any
+	pdfDict    map[pdfName]any
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		raw  []byte
+	}
+)
+
+// Limits for malformed or hostile files: the page tree walk, and how much
+// FlateDecode may inflate per stream and per document.
+const (
+	maxPDFPages    = 5000
+	maxPDFStream   = 16 << 20
+	maxPDFInflated = 64 << 20
+)
+
+var errPDFTooLarge = fmt.Errorf("PDF inflates to more than %d MiB", maxPDFInflated>>20)
+
+var pdfObjHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+type pdfFile struct {
+	objs     map[int]any
+	trailer  pdfDict
+	decoded  map[*pdfStream][]byte
+	inflated int64 // bytes FlateDecode produced so far
+}
+
+// PDF extracts the text of a PDF document page by page. It reads the
+// objects directly rather than through the cross-reference table, so
+// damaged files still yield text. Encrypted files are rejected; text in
+// fonts without a Unicode mapping is skipped.
+func PDF(body []byte) (*Document, error) {
+	if !bytes.HasPrefix(body, []byte("%PDF-")) {
+		return nil, errors.New("not a PDF file")
+	}
+	f := &pdfFile{objs: map[int]any{}, decoded: map[*pdfStream][]byte{}}
+	f.scanObjects(body)
+	f.expandObjectStreams()
+	f.findTrailer(body)
+	if f.trailer == nil {
+		return nil, errors.New("PDF has no trailer")
+	}
+	if _, ok := f.trailer["Encrypt"]; ok {
+		return nil, errors.New("encrypted PDFs are not supported")
+	}
+	catalog, _ := f.resolve(f.trailer["Root"]).(pdfDict)
+	if catalog == nil {
+		return nil, errors.New("PDF has no document catalog")
+	}
+
+	var pages []string
+	f.walkPages(f.resolve(catalog["Pages"]), nil, map[int]bool{}, func(page, resources pdfDict) {
+		if text := f.pageText(page, resources); text != "" {
+			pages = append(pages, text)
+		}
+	})
+
+	if f.inflated > maxPDFInflated {
+		return nil, errPDFTooLarge
+	}
+
+	doc := &Document{Text: strings.Join(pages, "\n\n")}
+	if info, ok := f.resolve(f.trailer["Info"]).(pdfDict); ok {
+		doc.Title = pdfTextString(f.resolve(info["Title"]))
+		doc.Author = pdfTextString(f.resolve(info["Author"]))
+		doc.Published = pdfDate(pdfTextString(f.resolve(info["CreationDate"])))
+	}
+	return doc, nil
+}
+
+// scanObjects parses every "N G obj" in file order, so objects rewritten
+// by incremental updates replace their earlier versions.
+func (f *pdfFile) scanObjects(body []byte) {
+	for _, m := range pdfObjHeader.FindAllSubmatchIndex(body, -1) {
+		if m[0] > 0 && !isPDFSpace(body[m[0]-1]) && !isPDFDelim(body[m[0]-1]) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(body[m[2]:m[3]]))
+		lex := &pdfLexer{data: body, pos: m[1]}
+		v, err := lex.value()
+		if err != nil {
+			continue
+		}
+		if dict, ok := v.(pdfDict); ok {
+			if stream, ok := lex.stream(dict); ok {
+				v = stream
+			}
+		}
+		f.objs[num] = v
+	}
+}
+
+// expandObjectStreams adds the objects packed into /Type /ObjStm streams.
+// Objects stored directly in the file take precedence.
+func (f *pdfFile) expandObjectStreams() {
+	for _, v := range f.objs {
+		s, ok := v.(*pdfStream)
+		if !ok || s.dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		data, err := f.decode(s)
+		if err != nil {
+			continue
+		}
+		n, _ := f.resolve(s.dict["N"]).(int)
+		first, _ := f.resolve(s.dict["First"]).(int)
+		if first <= 0 || first > len(data) {
+			continue
+		}
+		header := &pdfLexer{data: data[:first]}
+		for i := 0; i < n; i++ {
+			num, err1 := header.value()
+			off, err2 := header.value()
+			objNum, ok1 := num.(int)
+			objOff, ok2 := off.(int)
+			if err1 != nil || err2 != nil || !ok1 || !ok2 || first+objOff >= len(data) {
+				break
+			}
+			if _, exists := f.objs[objNum]; exists {
+				continue
+			}
+			v, err := (&pdfLexer{data: data, pos: first + objOff}).value()
+			if err == nil {
+				f.objs[objNum] = v
+			}
+		}
+	}
+}
+
+// findTrailer uses the last trailer dictionary, or the last
+// cross-reference stream for files without one.
+func (f *pdfFile) findTrailer(body []byte) {
+	if i := bytes.LastIndex(body, []byte("trailer")); i >= 0 {
+		if v, err := (&pdfLexer{data: body, pos: i + len("trailer")}).value(); err == nil {
+			if dict, ok := v.(pdfDict); ok && dict["Root"] != nil {
+				f.trailer = dict
+				return
+			}
+		}
+	}
+	best := -1
+	for num, v := range f.objs {
+		if s, ok := v.(*pdfStream); ok && s.dict["Type"] == pdfName("XRef") && s.dict["Root"] != nil && num > best {
+			best = num
+			f.trailer = s.dict
+		}
+	}
+}
+
+func (f *pdfFile) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objs[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) walkPages(node any, inherited pdfDict, seen map[int]bool, fn func(page, resources pdfDict)) {
+	dict, ok := node.(pdfDict)
+	if !ok || len(seen) > maxPDFPages {
+		return
+	}
+	resources := inherited
+	if r, ok := f.resolve(dict["Resources"]).(pdfDict); ok {
+		resources = r
+	}
+	if dict["Type"] == pdfName("Page") || dict["Kids"] == nil {
+		fn(dict, resources)
+		return
+	}
+	kids, _ := f.resolve(dict["Kids"]).(pdfArray)
+	for _, kid := range kids {
+		if ref, ok := kid.(pdfRef); ok {
+			if seen[ref.num] {
+				continue
+			}
+			seen[ref.num] = true
+		}
+		f.walkPages(f.resolve(kid), resources, seen, fn)
+	}
+}
+
+func (f *pdfFile) pageText(page, resources pdfDict) string {
+	var content []byte
+	contents := f.resolve(page["Contents"])
+	streams, ok := contents.(pdfArray)
+	if !ok {
+		streams = pdfArray{contents}
+	}
+	for _, s := range streams {
+		stream, ok := f.resolve(s).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := f.decode(stream)
+		if err != nil {
+			continue
+		}
+		content = append(content, data...)
+		content = append(content, '\n')
+	}
+
+	fonts := map[pdfName]*pdfFont{}
+	if fontDict, ok := f.resolve(resources["Font"]).(pdfDict); ok {
+		for name, ref := range fontDict {
+			if fd, ok := f.resolve(ref).(pdfDict); ok {
+				fonts[name] = f.loadFont(fd)
+			}
+		}
+	}
+	return showText(content, fonts)
+}
+
+// decode applies the stream's filters and caches the result, so streams
+// shared between pages count once against maxPDFInflated. Image filters are
+// not supported.
+func (f *pdfFile) decode(s *pdfStream) ([]byte, error) {
+	if data, ok := f.decoded[s]; ok {
+		return data, nil
+	}
+	data, err := f.applyFilters(s)
+	if err != nil {
+		return nil, err
+	}
+	f.decoded[s] = data
+	return data, nil
+}
+
+func (f *pdfFile) applyFilters(s *pdfStream) ([]byte, error) {
+	filters, ok := f.resolve(s.dict["Filter"]).(pdfArray)
+	if !ok {
+		if name, ok := f.resolve(s.dict["Filter"]).(pdfName); ok {
+			filters = pdfArray{name}
+		}
+	}
+	data := s.raw
+	for _, filter := range filters {
+		switch filter {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			if f.inflated > maxPDFInflated {
+				return nil, errPDFTooLarge
+			}
+			limit := min(int64(maxPDFStream), maxPDFInflated-f.inflated)
+			out, err := io.ReadAll(io.LimitReader(zr, limit+1))
+			f.inflated += int64(len(out))
+			if int64(len(out)) > limit {
+				if limit < maxPDFStream {
+					return nil, errPDFTooLarge
+				}
+				return nil, fmt.Errorf("PDF stream inflates to more than %d MiB", maxPDFStream>>20)
+			}
+			// Many writers leave a damaged checksum or tail; keep what inflated.
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			data = out
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			hexData := bytes.Map(func(r rune) rune {
+				if isPDFSpace(byte(r)) || r == '>' {
+					return -1
+				}
+				return r
+			}, data)
+			if len(hexData)%2 == 1 {
+				hexData = append(hexData, '0')
+			}
+			out := make([]byte, hex.DecodedLen(len(hexData)))
+			if _, err := hex.Decode(out, hexData); err != nil {
+				return nil, err
+			}
+			data = out
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data = bytes.TrimSuffix(bytes.TrimSpace(data), []byte("~>"))
+			out := make([]byte, 4*len(data)+4) // "z" expands one byte to four
+			n, _, err := ascii85.Decode(out, data, true)
+			if err != nil {
+				return nil, err
+			}
+			data = out[:n]
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+	}
+	return data, nil
+}
+
+// pdfFont maps the character codes of one font to text.
+type pdfFont struct {
+	twoByte bool              // Type0 fonts with two-byte codes
+	cmap    map[uint32]string // from ToUnicode
+	diffs   map[byte]rune     // from /Encoding /Differences
+}
+
+func (f *pdfFile) loadFont(fd pdfDict) *pdfFont {
+	font := &pdfFont{twoByte: fd["Subtype"] == pdfName("Type0")}
+	if s, ok := f.resolve(fd["ToUnicode"]).(*pdfStream); ok {
+		if data, err := f.decode(s); err == nil {
+			font.cmap = parseToUnicode(data)
+		}
+	}
+	if enc, ok := f.resolve(fd["Encoding"]).(pdfDict); ok {
+		diffs, _ := f.resolve(enc["Differences"]).(pdfArray)
+		code := 0
+		for _, d := range diffs {
+			switch v := d.(type) {
+			case int:
+				code = v
+			case pdfName:
+				if r, ok := glyphRune(string(v)); ok && code < 256 {
+					if font.diffs == nil {
+						font.diffs = map[byte]rune{}
+					}
+					font.diffs[byte(code)] = r
+				}
+				code++
+			}
+		}
+	}
+	return font
+}
+
+func (font *pdfFont) decode(s []byte) string {
+	var sb strings.Builder
+	if font == nil {
+		font = &pdfFont{}
+	}
+	if font.twoByte {
+		if font.cmap == nil {
+			return "" // Identity-encoded CIDs without a mapping
+		}
+		for i := 0; i+1 < len(s); i += 2 {
+			sb.WriteString(font.cmap[uint32(s[i])<<8|uint32(s[i+1])])
+		}
+		return sb.String()
+	}
+	for _, b := range s {
+		if text, ok := font.cmap[uint32(b)]; ok {
+			sb.WriteString(text)
+		} else if r, ok := font.diffs[b]; ok {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune(winAnsiRune(b))
+		}
+	}
+	return sb.String()
+}
+
+// parseToUnicode reads the bfchar and bfrange sections of a ToUnicode CMap.
+func parseToUnicode(data []byte) map[uint32]string {
+	cmap := map[uint32]string{}
+	lex := &pdfLexer{data: data}
+	var section pdfKeyword
+	var operands []any
+	for {
+		tok, err := lex.token()
+		if err != nil {
+			return cmap
+		}
+		kw, ok := tok.(pdfKeyword)
+		if !ok {
+			if section == "beginbfchar" || section == "beginbfrange" {
+				operands = append(operands, tok)
+			}
+			continue
+		}
+		switch kw {
+		case "beginbfchar", "beginbfrange":
+			section = kw
+			operands = operands[:0]
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					cmap[codeOf(src)] = utf16BE(dst)
+				}
+			}
+			section = ""
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				start, end := codeOf(lo), codeOf(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					base := []rune(utf16BE(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for c := start; c <= end; c++ {
+						r := append([]rune(nil), base...)
+						r[len(r)-1] += rune(c - start)
+						cmap[c] = string(r)
+					}
+				case pdfArray:
+					for j, d := range dst {
+						if s, ok := d.(pdfString); ok && start+uint32(j) <= end {
+							cmap[start+uint32(j)] = utf16BE(s)
+						}
+					}
+				}
+			}
+			section = ""
+		}
+	}
+}
+
+func codeOf(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+func utf16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// showText interprets the text operators of a content stream.
+func showText(content []byte, fonts map[pdfName]*pdfFont) string {
+	var sb strings.Builder
+	var font *pdfFont
+	var operands []any
+	lastY, haveY := 0.0, false
+
+	newline := func() {
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	space := func() {
+		s := sb.String()
+		if len(s) > 0 && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			sb.WriteString(" ")
+		}
+	}
+
+	lex := &pdfLexer{data: content}
+	for {
+		tok, err := lex.token()
+		if err != nil {
+			break
+		}
+		op, ok := tok.(pdfKeyword)
+		if !ok {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					font = fonts[name]
+				}
+			}
+		case "Tj":
+			if s, ok := lastString(operands); ok {
+				sb.WriteString(font.decode(s))
+			}
+		case "'", "\"":
+			newline()
+			if s, ok := lastString(operands); ok {
+				sb.WriteString(font.decode(s))
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				arr, _ := operands[len(operands)-1].(pdfArray)
+				for _, item := range arr {
+					switch v := item.(type) {
+					case pdfString:
+						sb.WriteString(font.decode(v))
+					case int:
+						if v < -200 {
+							space()
+						}
+					case float64:
+						if v < -200 {
+							space()
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if number(operands[len(operands)-1]) != 0 {
+					newline()
+				} else {
+					space()
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				y := number(operands[len(operands)-1])
+				if haveY && y != lastY {
+					newline()
+				} else {
+					space()
+				}
+				lastY, haveY = y, true
+			}
+		case "T*":
+			newline()
+		case "ET":
+			space()
+		case "BI":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	return collapseBlankLines(sb.String())
+}
+
+func lastString(operands []any) ([]byte, bool) {
+	if len(operands) == 0 {
+		return nil, false
+	}
+	s, ok := operands[len(operands)-1].(pdfString)
+	return s, ok
+}
+
+func number(v any) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// pdfTextString decodes a string outside content streams, which is either
+// UTF-16BE with a byte order mark or PDFDocEncoding.
+func pdfTextString(v any) string {
+	s, ok := v.(pdfString)
+	if !ok {
+		return ""
+	}
+	if bytes.HasPrefix(s, []byte{0xFE, 0xFF}) {
+		return strings.TrimSpace(utf16BE(s[2:]))
+	}
+	if bytes.HasPrefix(s, []byte{0xEF, 0xBB, 0xBF}) {
+		return strings.TrimSpace(string(s[3:]))
+	}
+	var sb strings.Builder
+	for _, b := range s {
+		sb.WriteRune(winAnsiRune(b))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// pdfDate turns "D:20240131120000+01'00'" into "2024-01-31T12:00:00+01:00",
+// keeping only the parts that are present.
+func pdfDate(s string) string {
+	s = strings.TrimPrefix(s, "D:")
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	d := s[:digits]
+	switch {
+	case digits >= 14:
+		out := fmt.Sprintf("%s-%s-%sT%s:%s:%s", d[0:4], d[4:6], d[6:8], d[8:10], d[10:12], d[12:14])
+		tz := strings.ReplaceAll(s[digits:], "'", "")
+		switch {
+		case tz == "Z":
+			out += "Z"
+		case len(tz) == 5 && (tz[0] == '+' || tz[0] == '-'):
+			out += tz[:3] + ":" + tz[3:]
+		}
+		return out
+	case digits >= 8:
+		return fmt.Sprintf("%s-%s-%s", d[0:4], d[4:6], d[6:8])
+	case digits >= 4:
+		return d[:4]
+	}
+	return ""
+}
+
+// winAnsiSpecials are the WinAnsiEncoding codes that differ from Latin-1.
+var winAnsiSpecials = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ',
+	0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“',
+	0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›',
+	0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+func winAnsiRune(b byte) rune {
+	if r, ok := winAnsiSpecials[b]; ok {
+		return r
+	}
+	return rune(b)
+}
+
+// glyphNames covers the punctuation and ligature names used in
+// /Differences arrays; single letters and uniXXXX are handled directly.
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$', "percent": '%',
+	"ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')', "asterisk": '*',
+	"plus": '+', "comma": ',', "hyphen": '-', "period": '.', "slash": '/', "colon": ':',
+	"semicolon": ';', "less": '<', "equal": '=', "greater": '>', "question": '?', "at": '@',
+	"bracketleft": '[', "backslash": '\\', "bracketright": ']', "underscore": '_', "braceleft": '{',
+	"bar": '|', "braceright": '}', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+	"quotedblright": '”', "endash": '–', "emdash": '—', "bullet": '•', "ellipsis": '…',
+	"fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "zero": '0', "one": '1', "two": '2', "three": '3', "four": '4',
+	"five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+}
+
+func glyphRune(name string) (rune, bool) {
+	if len(name) == 1 {
+		return rune(name[0]), true
+	}
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if hexCode, ok := strings.CutPrefix(name, "uni"); ok && len(hexCode) == 4 {
+		if code, err := strconv.ParseUint(hexCode, 16, 32); err == nil {
+			return rune(code), true
+		}
+	}
+	return 0, false
+}
+
+// pdfLexer reads PDF objects and content stream tokens.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+var errPDFSyntax = errors.New("PDF syntax error")
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelim(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		l.pos++
+	}
+}
+
+// value reads one object, folding "N G R" into a reference.
+func (l *pdfLexer) value() (any, error) {
+	tok, err := l.token()
+	if err != nil {
+		return nil, err
+	}
+	if n, ok := tok.(int); ok {
+		save := l.pos
+		if gen, err := l.token(); err == nil {
+			if g, ok := gen.(int); ok {
+				if r, err := l.token(); err == nil && r == pdfKeyword("R") {
+					return pdfRef{n, g}, nil
+				}
+			}
+		}
+		l.pos = save
+	}
+	return tok, nil
+}
+
+// token reads one token; arrays and dictionaries are read whole.
+func (l *pdfLexer) token() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(unescapeName(l.data[start:l.pos])), nil
+	case c == '(':
+		return l.literalString()
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		dict := pdfDict{}
+		for {
+			l.skipSpace()
+			if l.pos+1 < len(l.data) && l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+				l.pos += 2
+				return dict, nil
+			}
+			key, err := l.token()
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(pdfName)
+			if !ok {
+				return nil, errPDFSyntax
+			}
+			v, err := l.value()
+			if err != nil {
+				return nil, err
+			}
+			dict[name] = v
+		}
+	case c == '<':
+		end := bytes.IndexByte(l.data[l.pos:], '>')
+		if end < 0 {
+			return nil, errPDFSyntax
+		}
+		raw := bytes.Map(func(r rune) rune {
+			if isPDFSpace(byte(r)) {
+				return -1
+			}
+			return r
+		}, l.data[l.pos+1:l.pos+end])
+		l.pos += end + 1
+		if len(raw)%2 == 1 {
+			raw = append(raw, '0')
+		}
+		out := make([]byte, len(raw)/2)
+		if _, err := hex.Decode(out, raw); err != nil {
+			return nil, errPDFSyntax
+		}
+		return pdfString(out), nil
+	case c == '[':
+		l.pos++
+		var arr pdfArray
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return nil, errPDFSyntax
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, nil
+			}
+			v, err := l.value()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(c), nil
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if n, err := strconv.Atoi(word); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, nil
+	}
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(word), nil
+}
+
+func (l *pdfLexer) literalString() (any, error) {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(out), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return nil, errPDFSyntax
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return nil, errPDFSyntax
+}
+
+// stream reads the data following a stream dictionary, if there is any.
+func (l *pdfLexer) stream(dict pdfDict) (*pdfStream, bool) {
+	l.skipSpace()
+	if !bytes.HasPrefix(l.data[l.pos:], []byte("stream")) {
+		return nil, false
+	}
+	start := l.pos + len("stream")
+	if start < len(l.data) && l.data[start] == '\r' {
+		start++
+	}
+	if start < len(l.data) && l.data[start] == '\n' {
+		start++
+	}
+	if length, ok := dict["Length"].(int); ok && length >= 0 && start+length <= len(l.data) {
+		rest := bytes.TrimLeft(l.data[start+length:], " \r\n\t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return &pdfStream{dict: dict, raw: l.data[start : start+length]}, true
+		}
+	}
+	// /Length is indirect or wrong: fall back to the endstream marker.
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, false
+	}
+	raw := l.data[start : start+end]
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	raw = bytes.TrimSuffix(raw, []byte("\r"))
+	return &pdfStream{dict: dict, raw: raw}, true
+}
+
+// skipInlineImage moves past the binary data of a BI ... ID ... EI image.
+func (l *pdfLexer) skipInlineImage() {
+	id := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if id < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += id + 2
+	for l.pos < len(l.data) {
+		ei := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if ei < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		at := l.pos + ei
+		l.pos = at + 2
+		before := at == 0 || isPDFSpace(l.data[at-1])
+		after := l.pos >= len(l.data) || isPDFSpace(l.data[l.pos])
+		if before && after {
+			return
+		}
+	}
+}
+
+func unescapeName(b []byte) string {
+	if bytes.IndexByte(b, '#') < 0 {
+		return string(b)
+	}
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			if v, err := strconv.ParseUint(string(b[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
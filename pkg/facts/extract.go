@@ -0,0 +1,100 @@
+package facts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// Candidate is a fact proposed by the extraction model.
+type Candidate struct {
+	Text       string  `json:"text"`
+	Key        string  `json:"key"`
+	Kind       string  `json:"kind"`
+	Confidence float64 `json:"confidence"`
+}
+
+const extractionPrompt = `You maintain the long-term memory of a personal assistant.
+Read the exchange below and list durable facts about the user and their world that will
+still be useful in future conversations: who they are, people and things in their life,
+their projects, and their stated preferences. Skip small talk, one-off requests, facts
+about the current task only, and anything the assistant said without the user confirming it.
+
+Answer with a JSON array only, for example:
+[{"text": "The user lives in Lisbon.", "key": "user.home_city", "kind": "fact", "confidence": 0.9}]
+
+- text: one self-contained sentence in the third person.
+- key: a short dotted name for what the fact is about. Reuse the key of a known fact when
+  the new fact updates or contradicts it.
+- kind: "fact" or "preference".
+- confidence: 0 to 1, how sure you are the user meant it and that it will stay true.
+
+Answer [] when there is nothing worth remembering.`
+
+// Extract asks model for the facts worth remembering from one exchange.
+// known lists facts already stored, so the model reuses their keys.
+func Extract(
+	ctx context.Context,
+	provider providers.LLMProvider,
+	model string,
+	known []Fact,
+	userMessage, reply string,
+) ([]Candidate, error) {
+	var sb strings.Builder
+	if len(known) > 0 {
+		sb.WriteString("Known facts:\n")
+		for _, f := range known {
+			fmt.Fprintf(&sb, "- [%s] %s\n", f.Key, f.Text)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "User: %s\n\nAssistant: %s", userMessage, reply)
+
+	resp, err := provider.Chat(
+		ctx,
+		[]providers.Message{
+			{Role: "system", Content: extractionPrompt},
+			{Role: "user", Content: sb.String()},
+		},
+		nil,
+		model,
+		map[string]any{
+			"max_tokens":  1024,
+			"temperature": 0.0,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCandidates(resp.Content)
+}
+
+// ParseCandidates reads the JSON array of an extraction reply. Code fences
+// and text around the array are ignored, as are entries without text.
+func ParseCandidates(content string) ([]Candidate, error) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in extraction reply")
+	}
+	var raw []Candidate
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("parse extraction reply: %w", err)
+	}
+	out := raw[:0]
+	for _, c := range raw {
+		c.Text = strings.TrimSpace(c.Text)
+		if c.Text == "" {
+			continue
+		}
+		if c.Kind != KindPreference {
+			c.Kind = KindFact
+		}
+		c.Confidence = min(max(c.Confidence, 0), 1)
+		out = append(out, c)
+	}
+	return out, nil
+}
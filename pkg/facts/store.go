@@ -0,0 +1,716 @@
+// Package facts is the agent's long-term memory: durable facts and
+// preferences learned from conversations. Unlike MEMORY.md, which is put
+// into every prompt as a whole, facts are recalled by relevance to the
+// current message.
+package facts
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+	"github.com/sipeed/picoclaw/pkg/vectorindex"
+)
+
+const (
+	KindFact       = "fact"
+	KindPreference = "preference"
+)
+
+// DefaultMaxFacts is how many facts a store keeps when Options.MaxFacts is 0.
+const DefaultMaxFacts = 500
+
+// duplicateSimilarity is the word overlap above which two facts are taken
+// to say the same thing.
+const duplicateSimilarity = 0.8
+
+// minSemanticScore is the cosine similarity a fact needs to be recalled
+// when it shares no words with the query.
+const minSemanticScore = 0.5
+
+// embedTimeout bounds the embedding of the query made by Recall.
+const embedTimeout = 10 * time.Second
+
+// backgroundEmbedTimeout bounds the embedding of facts that have none yet.
+const backgroundEmbedTimeout = 2 * time.Minute
+
+// After a failed embedding call semantic recall is skipped for embedBackoff,
+// doubling with each further failure up to maxEmbedBackoff.
+const (
+	embedBackoff    = 30 * time.Second
+	maxEmbedBackoff = 10 * time.Minute
+)
+
+// Fact is one remembered statement about the user or their world.
+type Fact struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	// Key names what the fact is about, such as "user.home_city". A newer
+	// fact with the same key supersedes the older one.
+	Key        string    `json:"key,omitempty"`
+	Kind       string    `json:"kind"`
+	Confidence float64   `json:"confidence"`
+	Source     string    `json:"source,omitempty"` // session the fact was learned in
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// SupersededBy is the ID of the fact that replaced this one. Superseded
+	// facts are kept for review but never recalled.
+	SupersededBy string `json:"superseded_by,omitempty"`
+	// Scope is the sender the fact was learned from, so that it is recalled
+	// only in their conversations. Facts without a scope, such as ones
+	// written into the file by hand, are recalled for everyone.
+	Scope string `json:"scope,omitempty"`
+}
+
+// Active reports whether f has not been superseded.
+func (f Fact) Active() bool {
+	return f.SupersededBy == ""
+}
+
+// InScope reports whether f may be recalled for a sender known by any of
+// scopes, as returned by Store.Scopes.
+func (f Fact) InScope(scopes ...string) bool {
+	return f.Scope == "" || hasScope(scopes, f.Scope)
+}
+
+func hasScope(scopes []string, scope string) bool {
+	return slices.ContainsFunc(scopes, func(s string) bool { return strings.EqualFold(s, scope) })
+}
+
+// Outcome tells what Add did with a fact.
+type Outcome int
+
+const (
+	// Added means the fact was new.
+	Added Outcome = iota
+	// Merged means an active fact already said the same; it was refreshed.
+	Merged
+	// Replaced means the fact superseded an older fact with the same key.
+	Replaced
+)
+
+// Embedder turns texts into vectors for semantic recall.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Options configure a Store.
+type Options struct {
+	// MaxFacts caps the number of stored facts. Superseded facts are
+	// dropped first, then the least confident active ones.
+	MaxFacts int
+	// Embedder, when set, adds semantic similarity to keyword recall. The
+	// embeddings are kept in a vector index next to the fact file.
+	Embedder Embedder
+}
+
+// Store keeps facts in a JSON file. The file is re-read when it changes on
+// disk, so edits made while the gateway runs are picked up.
+type Store struct {
+	path     string
+	maxFacts int
+	embedder Embedder
+	vectors  *vectorindex.Index // nil without an embedder
+
+	mu      sync.Mutex
+	scopes  func(string) []string
+	facts   []Fact
+	modTime time.Time
+	engine  *utils.BM25Engine[Fact] // over active facts; nil when stale
+	now     func() time.Time
+
+	embedding     bool // facts are being embedded in the background
+	embedFailures int  // embedding calls failed in a row
+	embedRetryAt  time.Time
+}
+
+type factFile struct {
+	Facts []Fact `json:"facts"`
+}
+
+// StorePath returns where the facts of workspace are kept.
+func StorePath(workspace string) string {
+	return filepath.Join(workspace, "memory", "facts.json")
+}
+
+// VectorPath returns where the embeddings of the fact file at path are kept.
+func VectorPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".vec"
+}
+
+// NewStore opens the fact file at path. A missing file is an empty store.
+func NewStore(path string, opts Options) *Store {
+	maxFacts := opts.MaxFacts
+	if maxFacts <= 0 {
+		maxFacts = DefaultMaxFacts
+	}
+	s := &Store{
+		path:     path,
+		maxFacts: maxFacts,
+		embedder: opts.Embedder,
+		now:      time.Now,
+	}
+	if s.embedder != nil {
+		s.vectors = openVectors(VectorPath(path))
+	}
+	s.reload()
+	return s
+}
+
+// openVectors opens the vector index at path. An unreadable index is
+// discarded: the facts are embedded again on the next recall.
+func openVectors(path string) *vectorindex.Index {
+	vectors, err := vectorindex.Open(path)
+	if err == nil {
+		return vectors
+	}
+	logger.WarnCF("facts", "Discarding unreadable fact embeddings",
+		map[string]any{"path": path, "error": err.Error()})
+	os.Remove(path)
+	vectors, _ = vectorindex.Open(path)
+	return vectors
+}
+
+// reload re-reads the file if it changed since the last read. Caller must
+// hold s.mu.
+func (s *Store) reload() {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if !s.modTime.IsZero() {
+			s.facts = nil
+			s.modTime = time.Time{}
+			s.engine = nil
+		}
+		return
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	var f factFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		logger.WarnCF("facts", "Ignoring unreadable fact file",
+			map[string]any{"path": s.path, "error": err.Error()})
+		return
+	}
+	s.facts = f.Facts
+	s.modTime = info.ModTime()
+	s.engine = nil
+}
+
+// save writes the facts to disk. Caller must hold s.mu.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(factFile{Facts: s.facts}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(s.path, data, 0o600); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	s.engine = nil
+	return nil
+}
+
+// Add stores f under the first of Scopes(f.Scope). A fact that repeats an
+// active one of the same scopes refreshes it instead, and a fact whose key
+// matches an active fact of the same scopes with different content
+// supersedes it. The stored fact is returned.
+func (s *Store) Add(f Fact) (Fact, Outcome, error) {
+	f.Text = strings.TrimSpace(f.Text)
+	if f.Text == "" {
+		return Fact{}, Added, fmt.Errorf("fact text is empty")
+	}
+	f.Key = normalizeKey(f.Key)
+	if f.Kind != KindPreference {
+		f.Kind = KindFact
+	}
+	if f.Confidence <= 0 || f.Confidence > 1 {
+		f.Confidence = 1
+	}
+	scopes := s.Scopes(f.Scope)
+	if len(scopes) > 0 {
+		f.Scope = scopes[0]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	now := s.now()
+
+	words := wordSet(f.Text)
+	for i := range s.facts {
+		old := &s.facts[i]
+		sameScope := old.Scope == f.Scope || hasScope(scopes, old.Scope)
+		if !old.Active() || !sameScope || !duplicates(f.Key, words, old.Key, wordSet(old.Text)) {
+			continue
+		}
+		old.UpdatedAt = now
+		old.Confidence = max(old.Confidence, f.Confidence)
+		if old.Key == "" {
+			old.Key = f.Key
+		}
+		merged := *old
+		return merged, Merged, s.save()
+	}
+
+	f.ID = newID()
+	f.CreatedAt = now
+	f.UpdatedAt = now
+	f.SupersededBy = ""
+
+	outcome := Added
+	var stale []string
+	if f.Key != "" {
+		for i := range s.facts {
+			old := s.facts[i]
+			if old.Active() && (old.Scope == f.Scope || hasScope(scopes, old.Scope)) && old.Key == f.Key {
+				s.facts[i].SupersededBy = f.ID
+				s.facts[i].UpdatedAt = now
+				stale = append(stale, s.facts[i].ID)
+				outcome = Replaced
+			}
+		}
+	}
+	s.facts = append(s.facts, f)
+	stale = append(stale, s.prune()...)
+	s.dropVectors(stale)
+	return f, outcome, s.save()
+}
+
+// duplicates reports whether a fact with key and words says the same as an
+// older one. Facts about different keys never do. Under the same key only
+// the same words do, so a changed value, even a negation, supersedes the
+// older fact. Otherwise the texts need to overlap and not differ by a
+// negation.
+func duplicates(key string, words map[string]struct{}, oldKey string, oldWords map[string]struct{}) bool {
+	sim := similarity(words, oldWords)
+	if key != "" && oldKey != "" {
+		return key == oldKey && sim == 1
+	}
+	return sim >= duplicateSimilarity && !differInNegation(words, oldWords)
+}
+
+// negations are words that turn a statement into its opposite. "t" is
+// what remains of "isn't" or "doesn't" once words are split.
+var negations = map[string]struct{}{
+	"not": {}, "no": {}, "never": {}, "t": {}, "none": {}, "nor": {}, "without": {},
+}
+
+// differInNegation reports whether a negation occurs in only one of the word
+// sets.
+func differInNegation(a, b map[string]struct{}) bool {
+	for w := range negations {
+		_, inA := a[w]
+		_, inB := b[w]
+		if inA != inB {
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops facts beyond the cap: superseded ones first, then active ones
+// with the lowest confidence, oldest first. It returns the IDs of the
+// dropped facts. Caller must hold s.mu.
+func (s *Store) prune() []string {
+	excess := len(s.facts) - s.maxFacts
+	if excess <= 0 {
+		return nil
+	}
+	order := make([]int, len(s.facts))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		fa, fb := s.facts[a], s.facts[b]
+		if fa.Active() != fb.Active() {
+			if fa.Active() {
+				return 1
+			}
+			return -1
+		}
+		if fa.Confidence != fb.Confidence {
+			if fa.Confidence < fb.Confidence {
+				return -1
+			}
+			return 1
+		}
+		return fa.UpdatedAt.Compare(fb.UpdatedAt)
+	})
+	drop := make(map[int]bool, excess)
+	dropped := make([]string, 0, excess)
+	for _, i := range order[:excess] {
+		drop[i] = true
+		dropped = append(dropped, s.facts[i].ID)
+	}
+	kept := s.facts[:0]
+	for i, f := range s.facts {
+		if !drop[i] {
+			kept = append(kept, f)
+		}
+	}
+	s.facts = kept
+	return dropped
+}
+
+// dropVectors deletes the embeddings of facts that are no longer recalled.
+func (s *Store) dropVectors(ids []string) {
+	if s.vectors == nil || len(ids) == 0 {
+		return
+	}
+	if _, err := s.vectors.Delete(ids...); err != nil {
+		logger.WarnCF("facts", "Failed to delete fact embeddings", map[string]any{"error": err.Error()})
+	}
+}
+
+// Forget deletes the fact with id and returns it.
+func (s *Store) Forget(id string) (Fact, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	for i, f := range s.facts {
+		if f.ID == id {
+			s.facts = slices.Delete(s.facts, i, i+1)
+			s.dropVectors([]string{id})
+			return f, true, s.save()
+		}
+	}
+	return Fact{}, false, nil
+}
+
+// Get returns the fact with id.
+func (s *Store) Get(id string) (Fact, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	for _, f := range s.facts {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return Fact{}, false
+}
+
+// List returns the active facts, most recently updated first. With all set
+// superseded facts are included as well.
+func (s *Store) List(all bool) []Fact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+	out := make([]Fact, 0, len(s.facts))
+	for _, f := range s.facts {
+		if all || f.Active() {
+			out = append(out, f)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Fact) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	return out
+}
+
+// Scopes returns the sender scopes whose facts are recalled for scope: scope
+// itself or, with SetScopes, every scope sharing its memory, the one
+// facts are stored under first.
+func (s *Store) Scopes(scope string) []string {
+	if scope == "" {
+		return nil
+	}
+	s.mu.Lock()
+	scopes := s.scopes
+	s.mu.Unlock()
+	if scopes != nil {
+		if shared := scopes(scope); len(shared) > 0 {
+			return shared
+		}
+	}
+	return []string{scope}
+}
+
+// SetScopes sets the function returning the sender scopes that share the
+// memory of a sender, such as accounts linked across channels, with the one
+// facts are stored under first. It returns nil for a sender that shares
+// with nobody.
+func (s *Store) SetScopes(scopes func(scope string) []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scopes = scopes
+}
+
+// Recall returns up to k active facts in scope that are relevant to query,
+// best first. Facts are ranked by BM25 and, when the store has an embedder,
+// by the cosine similarity of their embeddings to the query's. If embedding
+// fails recall falls back to keywords.
+func (s *Store) Recall(ctx context.Context, scope, query string, k int) []Fact {
+	if k <= 0 || strings.TrimSpace(query) == "" {
+		return nil
+	}
+	scopes := s.Scopes(scope)
+
+	s.mu.Lock()
+	s.reload()
+	active := make([]Fact, 0, len(s.facts))
+	for _, f := range s.facts {
+		if f.Active() {
+			active = append(active, f)
+		}
+	}
+	if s.engine == nil {
+		s.engine = utils.NewBM25Engine(active, func(f Fact) string {
+			return f.Text + " " + strings.ReplaceAll(f.Key, ".", " ")
+		})
+	}
+	engine := s.engine
+	s.mu.Unlock()
+	var visible []Fact
+	for _, f := range active {
+		if f.InScope(scopes...) {
+			visible = append(visible, f)
+		}
+	}
+	if len(visible) == 0 {
+		return nil
+	}
+
+	scores := make(map[string]float64, len(visible))
+	var maxBM25 float32
+	results := engine.Search(query, len(active))
+	results = slices.DeleteFunc(results, func(r utils.BM25Result[Fact]) bool { return !r.Document.InScope(scopes...) })
+	for _, r := range results {
+		maxBM25 = max(maxBM25, r.Score)
+	}
+	for _, r := range results {
+		if maxBM25 > 0 {
+			scores[r.Document.ID] = float64(r.Score / maxBM25)
+		}
+	}
+
+	if s.vectors != nil && s.semanticAllowed() {
+		similarities, err := s.semanticScores(ctx, query, visible)
+		if ctx.Err() == nil {
+			s.mu.Lock()
+			s.recordEmbedLocked(err)
+			s.mu.Unlock()
+		}
+		if err != nil {
+			logger.WarnCF("facts", "Semantic recall failed; using keywords only",
+				map[string]any{"error": err.Error()})
+		} else {
+			for id, sim := range similarities {
+				if keyword, ok := scores[id]; ok {
+					scores[id] = (keyword + sim) / 2
+				} else if sim >= minSemanticScore {
+					scores[id] = sim / 2
+				}
+			}
+		}
+	}
+
+	out := make([]Fact, 0, len(scores))
+	for _, f := range visible {
+		if _, ok := scores[f.ID]; ok {
+			out = append(out, f)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Fact) int {
+		sa, sb := scores[a.ID]*a.Confidence, scores[b.ID]*b.Confidence
+		switch {
+		case sa > sb:
+			return -1
+		case sa < sb:
+			return 1
+		}
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	if len(out) > k {
+		out = out[:k]
+	}
+	return out
+}
+
+// semanticScores embeds query and returns the cosine similarity to it of
+// every fact in active with an up-to-date embedding, by fact ID. Facts
+// without one are embedded in the background and take part from a later
+// recall on.
+func (s *Store) semanticScores(ctx context.Context, query string, active []Fact) (map[string]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, embedTimeout)
+	defer cancel()
+
+	queryVecs, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(queryVecs) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 text", len(queryVecs))
+	}
+	queryVec := queryVecs[0]
+	if dim := s.vectors.Dim(); dim != 0 && dim != len(queryVec) {
+		// The embedding model changed; embed every fact again.
+		if err := s.vectors.Clear(); err != nil {
+			return nil, err
+		}
+	}
+
+	current := make(map[string]string, len(active))
+	var missing []Fact
+	for _, f := range active {
+		hash := textHash(f.Text)
+		current[f.ID] = hash
+		if meta, ok := s.vectors.Metadata(f.ID); !ok || meta["text"] != hash {
+			missing = append(missing, f)
+		}
+	}
+	s.embedInBackground(missing)
+	if s.vectors.Len() == 0 {
+		return nil, nil
+	}
+
+	results, err := s.vectors.Search(queryVec, s.vectors.Len(), nil)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]float64, len(results))
+	for _, r := range results {
+		if hash, ok := current[r.ID]; ok && r.Metadata["text"] == hash {
+			scores[r.ID] = float64(r.Score)
+		}
+	}
+	return scores, nil
+}
+
+// embedInBackground embeds facts unless an earlier run is still going.
+// Facts it misses are picked up by a later recall.
+func (s *Store) embedInBackground(facts []Fact) {
+	if len(facts) == 0 {
+		return
+	}
+	s.mu.Lock()
+	if s.embedding {
+		s.mu.Unlock()
+		return
+	}
+	s.embedding = true
+	s.mu.Unlock()
+
+	go func() {
+		err := s.embedFacts(facts)
+		if err != nil {
+			logger.WarnCF("facts", "Failed to embed facts",
+				map[string]any{"facts": len(facts), "error": err.Error()})
+		}
+		s.mu.Lock()
+		s.embedding = false
+		s.recordEmbedLocked(err)
+		s.mu.Unlock()
+	}()
+}
+
+// embedFacts stores the embeddings of facts in the vector index.
+func (s *Store) embedFacts(facts []Fact) error {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundEmbedTimeout)
+	defer cancel()
+
+	texts := make([]string, len(facts))
+	for i, f := range facts {
+		texts[i] = f.Text
+	}
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	items := make([]vectorindex.Item, len(facts))
+	for i, f := range facts {
+		items[i] = vectorindex.Item{
+			ID:       f.ID,
+			Vector:   vectors[i],
+			Metadata: map[string]string{"text": textHash(f.Text), "kind": f.Kind},
+		}
+	}
+	return s.vectors.Upsert(items...)
+}
+
+// recordEmbedLocked records the outcome of an embedding call and, after a
+// failure, backs off semantic recall so an unreachable model does not slow
+// down every turn. Caller must hold s.mu.
+func (s *Store) recordEmbedLocked(err error) {
+	if err == nil {
+		s.embedFailures = 0
+		s.embedRetryAt = time.Time{}
+		return
+	}
+	backoff := min(embedBackoff<<min(s.embedFailures, 10), maxEmbedBackoff)
+	s.embedFailures++
+	s.embedRetryAt = s.now().Add(backoff)
+}
+
+// semanticAllowed reports whether semantic recall is not backing off.
+func (s *Store) semanticAllowed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.embedRetryAt.IsZero() || !s.now().Before(s.embedRetryAt)
+}
+
+// textHash identifies the text an embedding was computed from, so facts
+// edited in the file are embedded again.
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizeKey lowercases key and joins its words with underscores.
+func normalizeKey(key string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimSpace(key))), "_")
+}
+
+func wordSet(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+// similarity is the Jaccard index of two word sets.
+func similarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if _, ok := b[w]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func newID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
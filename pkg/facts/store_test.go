@@ -0,0 +1,321 @@
+package facts
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, opts Options) *Store {
+	t.Helper()
+	s := NewStore(filepath.Join(t.TempDir(), "memory", "facts.json"), opts)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return s
+}
+
+func TestStore_AddMergesAndSupersedes(t *testing.T) {
+	s := newTestStore(t, Options{})
+
+	first, outcome, err := s.Add(Fact{Text: "The user lives in Lisbon.", Key: "User.Home City", Confidence: 0.7})
+	if err != nil || outcome != Added {
+		t.Fatalf("Add() = %v, %v", outcome, err)
+	}
+	if first.Key != "user.home_city" || first.Kind != KindFact || first.ID == "" {
+		t.Fatalf("stored fact = %+v", first)
+	}
+
+	dup, outcome, _ := s.Add(Fact{Text: "the user lives in Lisbon", Confidence: 0.9})
+	if outcome != Merged || dup.ID != first.ID || dup.Confidence != 0.9 {
+		t.Fatalf("duplicate: outcome %v, fact %+v", outcome, dup)
+	}
+
+	moved, outcome, _ := s.Add(Fact{Text: "The user moved to Porto.", Key: "user.home_city"})
+	if outcome != Replaced {
+		t.Fatalf("contradiction outcome = %v", outcome)
+	}
+	if active := s.List(false); len(active) != 1 || active[0].ID != moved.ID {
+		t.Fatalf("active facts = %+v", active)
+	}
+	old, _ := s.Get(first.ID)
+	if old.SupersededBy != moved.ID {
+		t.Fatalf("old fact superseded by %q, want %q", old.SupersededBy, moved.ID)
+	}
+
+	if _, _, err := s.Add(Fact{Text: "  "}); err == nil {
+		t.Fatal("Add() of empty text succeeded")
+	}
+}
+
+func TestStore_AddChecksKeysBeforeMerging(t *testing.T) {
+	s := newTestStore(t, Options{})
+
+	veg, _, _ := s.Add(Fact{Text: "The user is vegetarian.", Key: "user.diet"})
+	notVeg, outcome, _ := s.Add(Fact{Text: "The user is not vegetarian.", Key: "user.diet"})
+	if outcome != Replaced || notVeg.ID == veg.ID {
+		t.Fatalf("negation under the same key: outcome %v, fact %+v", outcome, notVeg)
+	}
+
+	s.Add(Fact{Text: "The user isn't a smoker."})
+	if _, outcome, _ = s.Add(Fact{Text: "The user is a smoker."}); outcome != Added {
+		t.Fatalf("negation without keys: outcome %v", outcome)
+	}
+
+	blue := "The user's favourite colour is blue."
+	s.Add(Fact{Text: blue, Key: "user.favourite_colour"})
+	if _, outcome, _ = s.Add(Fact{Text: blue, Key: "user.car_colour"}); outcome != Added {
+		t.Fatalf("same text under another key: outcome %v", outcome)
+	}
+	if _, outcome, _ = s.Add(Fact{Text: strings.ToLower(blue), Key: "user.favourite_colour"}); outcome != Merged {
+		t.Fatalf("repeat under the same key: outcome %v", outcome)
+	}
+}
+
+func TestStore_ScopesFacts(t *testing.T) {
+	s := newTestStore(t, Options{})
+	alice, _, _ := s.Add(Fact{Text: "The user is allergic to peanuts.", Key: "user.allergies", Scope: "telegram:1"})
+	bob, outcome, _ := s.Add(Fact{Text: "The user is allergic to peanuts.", Key: "user.allergies", Scope: "telegram:2"})
+	if outcome != Added || bob.ID == alice.ID {
+		t.Fatalf("another sender's fact: outcome %v", outcome)
+	}
+	s.Add(Fact{Text: "The office has peanuts in the kitchen."})
+
+	got := s.Recall(context.Background(), "telegram:1", "peanuts", 5)
+	if len(got) != 2 {
+		t.Fatalf("Recall() = %+v", got)
+	}
+	for _, f := range got {
+		if f.Scope == "telegram:2" {
+			t.Fatalf("recalled a fact of another sender: %+v", f)
+		}
+	}
+	if got := s.Recall(context.Background(), "discord:3", "allergic peanuts", 5); len(got) != 1 || got[0].Scope != "" {
+		t.Fatalf("Recall() for a new sender = %+v", got)
+	}
+}
+
+func TestStore_SharedScopes(t *testing.T) {
+	s := newTestStore(t, Options{})
+	// A fact stored before the identities were linked stays with them.
+	s.Add(Fact{Text: "The user lives in Oslo.", Key: "user.home_city", Scope: "discord:2"})
+	s.SetScopes(func(scope string) []string {
+		if scope == "telegram:1" || scope == "discord:2" {
+			return []string{"telegram:1", "discord:2"}
+		}
+		return nil
+	})
+
+	f, _, _ := s.Add(Fact{Text: "The user is allergic to peanuts.", Key: "user.allergies", Scope: "discord:2"})
+	if f.Scope != "telegram:1" {
+		t.Fatalf("stored scope = %q, want the first shared scope", f.Scope)
+	}
+	if _, outcome, _ := s.Add(Fact{Text: "The user is allergic to peanuts.", Scope: "telegram:1"}); outcome != Merged {
+		t.Fatalf("repeat by a linked identity: outcome %v", outcome)
+	}
+	for _, query := range []string{"allergic peanuts", "lives in Oslo"} {
+		if got := s.Recall(context.Background(), "telegram:1", query, 5); len(got) != 1 {
+			t.Fatalf("Recall(%q) for a linked identity = %+v", query, got)
+		}
+	}
+	if got := s.Recall(context.Background(), "telegram:3", "allergic peanuts", 5); len(got) != 0 {
+		t.Fatalf("Recall() for another sender = %+v", got)
+	}
+}
+
+func TestStore_PersistsAndForgets(t *testing.T) {
+	s := newTestStore(t, Options{})
+	f, _, _ := s.Add(Fact{Text: "The user prefers tea.", Kind: KindPreference})
+
+	reopened := NewStore(s.path, Options{})
+	got, ok := reopened.Get(f.ID)
+	if !ok || got.Kind != KindPreference {
+		t.Fatalf("reopened store Get() = %+v, %v", got, ok)
+	}
+
+	if _, ok, err := reopened.Forget(f.ID); !ok || err != nil {
+		t.Fatalf("Forget() = %v, %v", ok, err)
+	}
+	if _, ok := s.Get(f.ID); ok {
+		t.Fatal("forgotten fact still visible to the first store")
+	}
+}
+
+func TestStore_PruneDropsSupersededFirst(t *testing.T) {
+	s := newTestStore(t, Options{MaxFacts: 2})
+	s.Add(Fact{Text: "The user drives a Fiat.", Key: "user.car"})
+	s.Add(Fact{Text: "The user drives a Volvo.", Key: "user.car"})
+	s.Add(Fact{Text: "The user has a cat.", Confidence: 0.5})
+
+	all := s.List(true)
+	if len(all) != 2 {
+		t.Fatalf("kept %d facts, want 2", len(all))
+	}
+	for _, f := range all {
+		if !f.Active() {
+			t.Fatalf("superseded fact survived pruning: %+v", f)
+		}
+	}
+}
+
+func TestStore_RecallKeywords(t *testing.T) {
+	s := newTestStore(t, Options{})
+	s.Add(Fact{Text: "The user is allergic to peanuts.", Key: "user.allergies"})
+	s.Add(Fact{Text: "The user's sister is called Ana.", Key: "user.family.sister"})
+	s.Add(Fact{Text: "The user works as a nurse.", Key: "user.job"})
+
+	got := s.Recall(context.Background(), "", "any peanuts in this recipe?", 2)
+	if len(got) != 1 || !strings.Contains(got[0].Text, "peanuts") {
+		t.Fatalf("Recall() = %+v", got)
+	}
+	if got := s.Recall(context.Background(), "", "weather tomorrow", 2); len(got) != 0 {
+		t.Fatalf("unrelated Recall() = %+v", got)
+	}
+}
+
+// topicEmbedder maps texts to fixed vectors by topic words.
+type topicEmbedder struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (e *topicEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		text = strings.ToLower(text)
+		switch {
+		case strings.Contains(text, "peanut"), strings.Contains(text, "snack"):
+			out[i] = []float32{1, 0}
+		default:
+			out[i] = []float32{0, 1}
+		}
+	}
+	return out, nil
+}
+
+func (e *topicEmbedder) callCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
+
+func (e *topicEmbedder) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.err = err
+}
+
+// waitForEmbeddings waits until the store has embedded n facts.
+func waitForEmbeddings(t *testing.T, s *Store, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		embedding := s.embedding
+		s.mu.Unlock()
+		if !embedding && s.vectors.Len() == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%d of %d facts embedded", s.vectors.Len(), n)
+}
+
+func TestStore_RecallSemantic(t *testing.T) {
+	embedder := &topicEmbedder{}
+	s := newTestStore(t, Options{Embedder: embedder})
+	s.Add(Fact{Text: "The user is allergic to peanuts."})
+	s.Add(Fact{Text: "The user works as a nurse."})
+
+	// The first recall embeds the facts in the background.
+	if got := s.Recall(context.Background(), "", "what snack should I buy?", 5); len(got) != 0 {
+		t.Fatalf("Recall() before the facts were embedded = %+v", got)
+	}
+	waitForEmbeddings(t, s, 2)
+	got := s.Recall(context.Background(), "", "what snack should I buy?", 5)
+	if len(got) != 1 || !strings.Contains(got[0].Text, "peanuts") {
+		t.Fatalf("semantic Recall() = %+v", got)
+	}
+
+	calls := embedder.callCount()
+	reopened := NewStore(s.path, Options{Embedder: embedder})
+	if got := reopened.Recall(context.Background(), "", "snack", 5); len(got) != 1 {
+		t.Fatalf("reopened Recall() = %+v", got)
+	}
+	if embedder.callCount() != calls+1 {
+		t.Fatalf("reopened store made %d embedding calls, want only the query", embedder.callCount()-calls)
+	}
+
+	embedder.fail(errors.New("offline"))
+	if got := s.Recall(context.Background(), "", "nurse", 5); len(got) != 1 {
+		t.Fatalf("Recall() without embeddings = %+v", got)
+	}
+}
+
+func TestStore_RecallBacksOffAfterEmbeddingFailure(t *testing.T) {
+	embedder := &topicEmbedder{err: errors.New("offline")}
+	s := newTestStore(t, Options{Embedder: embedder})
+	s.Add(Fact{Text: "The user works as a nurse."})
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.Recall(context.Background(), "", "nurse", 5)
+	if embedder.callCount() != 1 {
+		t.Fatalf("first recall made %d embedding calls", embedder.callCount())
+	}
+	if got := s.Recall(context.Background(), "", "nurse", 5); len(got) != 1 || embedder.callCount() != 1 {
+		t.Fatalf("recall while backing off = %+v after %d embedding calls", got, embedder.callCount())
+	}
+
+	now = now.Add(embedBackoff)
+	embedder.fail(nil)
+	s.Recall(context.Background(), "", "nurse", 5)
+	if embedder.callCount() != 2 {
+		t.Fatalf("recall after the backoff made %d embedding calls in total", embedder.callCount())
+	}
+	waitForEmbeddings(t, s, 1)
+}
+
+func TestStore_RecallDoesNotBackOffWhenCancelled(t *testing.T) {
+	embedder := &topicEmbedder{err: context.Canceled}
+	s := newTestStore(t, Options{Embedder: embedder})
+	s.Add(Fact{Text: "The user works as a nurse."})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Recall(ctx, "", "nurse", 5)
+	if !s.semanticAllowed() {
+		t.Fatal("a cancelled recall started a backoff")
+	}
+}
+
+func TestParseCandidates(t *testing.T) {
+	got, err := ParseCandidates("Here you go:\n```json\n" +
+		`[{"text":"The user likes jazz.","key":"user.music","kind":"preference","confidence":0.8},` +
+		`{"text":" "},{"text":"The user is 40.","kind":"other","confidence":3}]` + "\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Kind != KindPreference || got[1].Kind != KindFact || got[1].Confidence != 1 {
+		t.Fatalf("ParseCandidates() = %+v", got)
+	}
+	if got, err := ParseCandidates("[]"); err != nil || len(got) != 0 {
+		t.Fatalf("ParseCandidates([]) = %+v, %v", got, err)
+	}
+	if _, err := ParseCandidates("nothing to remember"); err == nil {
+		t.Fatal("ParseCandidates() of prose succeeded")
+	}
+}
@@ -34,6 +34,7 @@ import (
 	_ "github.com/sipeed/picoclaw/pkg/channels/whatsapp"
 	_ "github.com/sipeed/picoclaw/pkg/channels/whatsapp_native"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/cron"
 	"github.com/sipeed/picoclaw/pkg/devices"
 	"github.com/sipeed/picoclaw/pkg/health"
@@ -43,7 +44,9 @@ import (
 	"github.com/sipeed/picoclaw/pkg/pid"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/swarm"
 	"github.com/sipeed/picoclaw/pkg/tools"
+	"github.com/sipeed/picoclaw/pkg/triggers"
 )
 
 const (
@@ -62,6 +65,8 @@ type services struct {
 	MediaStore       media.MediaStore
 	ChannelManager   *channels.Manager
 	DeviceService    *devices.Service
+	TriggerService   *triggers.Service
+	SwarmNode        *swarm.Node
 	HealthServer     *health.Server
 	VoiceAgentCancel context.CancelFunc
 	manualReloadChan chan struct{}
@@ -137,6 +142,11 @@ func Run(debug bool, homePath, configPath string, allowEmptyStartup bool) error
 		logger.Fatalf("config pre-check failed: %v", err)
 	}
 
+	applyLogConfig(cfg)
+	defer logger.CloseShippers()
+	applyTracingConfig(cfg)
+	defer shutdownTracing()
+
 	// Debug mode permanently overrides the config log level to DEBUG.
 	if debug {
 		fmt.Println("🔍 Debug mode enabled")
@@ -381,6 +391,11 @@ func setupAndStartServices(
 	runningServices.authToken = authToken
 	runningServices.HealthServer = health.NewServer(cfg.Gateway.Host, cfg.Gateway.Port, authToken)
 	runningServices.ChannelManager.SetupHTTPServer(addr, runningServices.HealthServer)
+	runningServices.HealthServer.SetMetricsHandler(
+		newGatewayMetrics(agentLoop, runningServices.ChannelManager, msgBus))
+	tasksHandler := newTasksHandler(agentLoop, authToken)
+	runningServices.ChannelManager.RegisterHTTPHandler(tasksPath, tasksHandler)
+	runningServices.ChannelManager.RegisterHTTPHandler(tasksPath+"/", tasksHandler)
 
 	if err = runningServices.ChannelManager.StartAll(context.Background()); err != nil {
 		return nil, fmt.Errorf("error starting channels: %w", err)
@@ -397,7 +412,7 @@ func setupAndStartServices(
 	}
 
 	fmt.Printf(
-		"✓ Health endpoints available at http://%s:%d/health, /ready and /reload (POST)\n",
+		"✓ Health endpoints available at http://%s:%d/health, /ready, /metrics and /reload (POST)\n",
 		cfg.Gateway.Host,
 		cfg.Gateway.Port,
 	)
@@ -414,6 +429,18 @@ func setupAndStartServices(
 		fmt.Println("✓ Device event service started")
 	}
 
+	runningServices.TriggerService = setupTriggerService(cfg, agentLoop, stateManager, runningServices)
+	if err = runningServices.TriggerService.Start(context.Background()); err != nil {
+		logger.ErrorCF("triggers", "Error starting trigger service", map[string]any{"error": err.Error()})
+	} else if cfg.Triggers.Enabled {
+		fmt.Println("✓ Trigger service started")
+	}
+
+	runningServices.SwarmNode = setupSwarm(cfg, agentLoop, runningServices)
+	if runningServices.SwarmNode != nil {
+		fmt.Printf("✓ Swarm node %q started\n", runningServices.SwarmNode.Name())
+	}
+
 	return runningServices, nil
 }
 
@@ -428,6 +455,13 @@ func stopAndCleanupServices(runningServices *services, shutdownTimeout time.Dura
 	if runningServices.VoiceAgentCancel != nil {
 		runningServices.VoiceAgentCancel()
 	}
+	if runningServices.SwarmNode != nil {
+		runningServices.SwarmNode.Stop()
+		runningServices.SwarmNode = nil
+	}
+	if runningServices.TriggerService != nil {
+		runningServices.TriggerService.Stop()
+	}
 	if runningServices.DeviceService != nil {
 		runningServices.DeviceService.Stop()
 	}
@@ -520,6 +554,9 @@ func handleConfigReload(
 
 	logger.Info("  ✓ Provider, configuration, and services reloaded successfully (thread-safe)")
 
+	applyLogConfig(newCfg)
+	applyTracingConfig(newCfg)
+
 	// Debug mode permanently overrides the config log level to DEBUG.
 	if !debug {
 		// Update log level last so that reload-related info/warn logs above are not suppressed.
@@ -604,6 +641,18 @@ func restartServices(
 		fmt.Println("  ✓ Device event service restarted")
 	}
 
+	runningServices.TriggerService = setupTriggerService(cfg, al, stateManager, runningServices)
+	if err := runningServices.TriggerService.Start(context.Background()); err != nil {
+		logger.WarnCF("triggers", "Failed to restart trigger service", map[string]any{"error": err.Error()})
+	} else if cfg.Triggers.Enabled {
+		fmt.Println("  ✓ Trigger service restarted")
+	}
+
+	runningServices.SwarmNode = setupSwarm(cfg, al, runningServices)
+	if runningServices.SwarmNode != nil {
+		fmt.Println("  ✓ Swarm node restarted")
+	}
+
 	transcriber := asr.DetectTranscriber(cfg)
 	al.SetTranscriber(transcriber)
 	if transcriber != nil {
@@ -719,6 +768,11 @@ func setupCronTool(
 	cronStorePath := filepath.Join(workspace, "cron", "jobs.json")
 
 	cronService := cron.NewCronService(cronStorePath, nil)
+	cronService.SetHistoryLimits(
+		cfg.Tools.Cron.HistoryMaxRuns,
+		time.Duration(cfg.Tools.Cron.HistoryRetentionDays)*24*time.Hour,
+	)
+	cronService.SetCompleteFunc(agentLoop.Complete)
 
 	var cronTool *tools.CronTool
 	if cfg.Tools.IsToolEnabled("cron") {
@@ -733,14 +787,104 @@ func setupCronTool(
 
 	if cronTool != nil {
 		cronService.SetOnJob(func(job *cron.CronJob) (string, error) {
-			result := cronTool.ExecuteJob(context.Background(), job)
-			return result, nil
+			return cronTool.ExecuteJob(context.Background(), job)
 		})
 	}
 
 	return cronService, nil
 }
 
+// setupTriggerService creates the event trigger service, feeds it device
+// events and serves its webhooks on the shared HTTP server.
+func setupTriggerService(
+	cfg *config.Config,
+	agentLoop *agent.AgentLoop,
+	stateManager *state.Manager,
+	runningServices *services,
+) *triggers.Service {
+	svc := triggers.NewService(cfg.Triggers, createTriggerHandler(agentLoop, stateManager))
+	if runningServices.DeviceService != nil {
+		runningServices.DeviceService.AddListener(svc.HandleDeviceEvent)
+	}
+	if runningServices.ChannelManager != nil {
+		runningServices.ChannelManager.RegisterHTTPHandler(triggers.WebhookPathPrefix, svc)
+	}
+	return svc
+}
+
+// setupSwarm joins the LAN swarm when enabled: it starts peer discovery,
+// serves the peer WebSocket on the shared HTTP server and gives the agents
+// the delegate_remote tool. It returns nil when the swarm is disabled or
+// could not be started.
+func setupSwarm(cfg *config.Config, agentLoop *agent.AgentLoop, runningServices *services) *swarm.Node {
+	if !cfg.Swarm.Enabled {
+		return nil
+	}
+	node, err := swarm.NewNode(swarm.Options{
+		Name:             cfg.Swarm.NodeName,
+		Secret:           cfg.Swarm.Secret.String(),
+		DiscoveryPort:    cfg.Swarm.DiscoveryPort,
+		Announce:         cfg.Swarm.Announce,
+		AnnounceInterval: time.Duration(cfg.Swarm.AnnounceIntervalSeconds) * time.Second,
+		GatewayPort:      cfg.Gateway.Port,
+		AdvertiseURL:     cfg.Swarm.AdvertiseURL,
+		TaskTimeout:      time.Duration(cfg.Swarm.TaskTimeoutSeconds) * time.Second,
+		Version:          config.GetVersion(),
+		StatePath:        filepath.Join(cfg.WorkspacePath(), "state", swarm.StateFile),
+		Info: func() ([]string, []string) {
+			registry := agentLoop.GetRegistry()
+			agentIDs := registry.ListAgentIDs()
+			sort.Strings(agentIDs)
+			var toolNames []string
+			if agent := registry.GetDefaultAgent(); agent != nil {
+				toolNames = agent.Tools.List()
+				sort.Strings(toolNames)
+			}
+			return agentIDs, toolNames
+		},
+		Run: func(ctx context.Context, task swarm.Task) (string, error) {
+			return agentLoop.ProcessRemoteTask(ctx, task.AgentID, task.Content, task.Peer)
+		},
+	})
+	if err != nil {
+		logger.ErrorCF("swarm", "Invalid swarm configuration", map[string]any{"error": err.Error()})
+		return nil
+	}
+	if err := node.Start(context.Background()); err != nil {
+		logger.ErrorCF("swarm", "Error starting swarm node", map[string]any{"error": err.Error()})
+		return nil
+	}
+	if runningServices.ChannelManager != nil {
+		runningServices.ChannelManager.RegisterHTTPHandler(swarm.WSPath, node)
+	}
+	if cfg.Tools.IsToolEnabled("delegate_remote") {
+		agentLoop.RegisterTool(tools.NewDelegateRemoteTool(node))
+	}
+	return node
+}
+
+func createTriggerHandler(agentLoop *agent.AgentLoop, stateManager *state.Manager) triggers.Handler {
+	return func(ctx context.Context, turn triggers.Turn) error {
+		channel, chatID := turn.Channel, turn.ChatID
+		if channel == "" {
+			channel, chatID, _ = strings.Cut(stateManager.GetLastChannel(), ":")
+		}
+		deliver := channel != "" && chatID != "" && !constants.IsInternalChannel(channel)
+		if !deliver {
+			channel, chatID = "cli", "direct"
+		}
+
+		response, err := agentLoop.ProcessTrigger(ctx, turn.AgentID, turn.Prompt, turn.SessionKey, channel, chatID)
+		if err != nil {
+			return err
+		}
+		if deliver {
+			agentLoop.PublishResponseIfNeeded(ctx, channel, chatID, response)
+		}
+		return nil
+	}
+}
+
 // overridePicoToken replaces the pico channel token with the one from the PID file.
 // The PID file is the single source of truth for the pico auth token;
 // it is generated once at gateway startup and remains unchanged across reloads.
@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// applyLogConfig sets the log format and file rotation from cfg and replaces
+// the log shippers. It runs at startup and on every config reload.
+func applyLogConfig(cfg *config.Config) {
+	logCfg := cfg.Gateway.Log
+	if logCfg == nil {
+		logCfg = &config.GatewayLogConfig{}
+	}
+
+	if err := logger.SetFormat(logCfg.Format); err != nil {
+		logger.WarnCF("gateway", "Invalid log format, using console", map[string]any{"error": err.Error()})
+		_ = logger.SetFormat("console")
+	}
+	logger.SetRotation(logCfg.Rotation())
+
+	logger.CloseShippers()
+	if s := logCfg.Syslog; s != nil && s.Enabled {
+		w, err := logger.NewSyslogWriter(logger.SyslogOptions{
+			Network:  s.Network,
+			Address:  s.Address,
+			AppName:  s.AppName,
+			Facility: s.Facility,
+		})
+		if err != nil {
+			logger.WarnCF("gateway", "Syslog shipping disabled", map[string]any{"error": err.Error()})
+		} else {
+			logger.AddShipper(w)
+		}
+	}
+	if h := logCfg.HTTP; h != nil && h.Enabled {
+		w, err := logger.NewHTTPWriter(logger.HTTPOptions{
+			URL:           h.URL,
+			Labels:        h.Labels,
+			Headers:       h.Headers.Values(),
+			BatchSize:     h.BatchSize,
+			FlushInterval: time.Duration(h.FlushIntervalMS) * time.Millisecond,
+		})
+		if err != nil {
+			logger.WarnCF("gateway", "HTTP log shipping disabled", map[string]any{"error": err.Error()})
+		} else {
+			logger.AddShipper(w)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package gateway
+
+import (
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/metrics"
+)
+
+// metricsEventBuffer is the event subscription buffer of the metrics
+// collector. Events that do not fit are counted in
+// picoclaw_agent_events_dropped_total.
+const metricsEventBuffer = 256
+
+// newGatewayMetrics builds the registry served at /metrics. Turn, LLM and
+// tool metrics are fed by the agent event bus until the agent loop closes;
+// provider, channel, bus and MCP state is read at scrape time.
+func newGatewayMetrics(al *agent.AgentLoop, cm *channels.Manager, msgBus *bus.MessageBus) *metrics.Registry {
+	reg := metrics.NewRegistry()
+
+	turns := reg.NewCounter("picoclaw_turns_total",
+		"Agent turns by final status.", "agent_id", "status")
+	turnDuration := reg.NewHistogram("picoclaw_turn_duration_seconds",
+		"Duration of agent turns.", metrics.DefaultDurationBuckets, "agent_id")
+	llmRequests := reg.NewCounter("picoclaw_llm_requests_total",
+		"LLM requests by model.", "agent_id", "model")
+	llmRetries := reg.NewCounter("picoclaw_llm_retries_total",
+		"LLM request retries by reason.", "agent_id", "reason")
+	toolCalls := reg.NewCounter("picoclaw_tool_calls_total",
+		"Tool executions by result.", "tool", "status")
+	toolDuration := reg.NewHistogram("picoclaw_tool_duration_seconds",
+		"Duration of tool executions.", metrics.DefaultDurationBuckets, "tool")
+	agentErrors := reg.NewCounter("picoclaw_agent_errors_total",
+		"Errors raised while running turns, by stage.", "agent_id", "stage")
+
+	reg.NewCounterFunc("picoclaw_agent_events_dropped_total",
+		"Agent events dropped because a subscriber was too slow.",
+		[]string{"kind"}, func(emit func(float64, ...string)) {
+			for _, kind := range agent.EventKinds() {
+				emit(float64(al.EventDrops(kind)), kind.String())
+			}
+		})
+
+	reg.NewCounterFunc("picoclaw_provider_failures_total",
+		"Provider failures by failover reason. Resets when the config is reloaded.",
+		[]string{"provider", "reason"}, func(emit func(float64, ...string)) {
+			for _, p := range al.ProviderStatus() {
+				for reason, n := range p.TotalFailures {
+					emit(float64(n), p.Provider, string(reason))
+				}
+			}
+		})
+	reg.NewGaugeFunc("picoclaw_provider_cooldown_seconds",
+		"Time until a provider in cooldown is tried again.",
+		[]string{"provider"}, func(emit func(float64, ...string)) {
+			for _, p := range al.ProviderStatus() {
+				emit(p.CooldownRemaining.Seconds(), p.Provider)
+			}
+		})
+
+	for _, m := range []struct {
+		name, help string
+		counter    bool
+		value      func(channels.SendStats) float64
+	}{
+		{"picoclaw_channel_messages_sent_total", "Outbound messages and media delivered per channel.", true,
+			func(s channels.SendStats) float64 { return float64(s.Sent) }},
+		{"picoclaw_channel_send_failures_total", "Outbound deliveries that failed after retries.", true,
+			func(s channels.SendStats) float64 { return float64(s.Failed) }},
+		{"picoclaw_channel_send_retries_total", "Retried outbound send attempts per channel.", true,
+			func(s channels.SendStats) float64 { return float64(s.Retries) }},
+		{"picoclaw_channel_queue_depth", "Outbound messages waiting in a channel worker.", false,
+			func(s channels.SendStats) float64 { return float64(s.QueueDepth) }},
+	} {
+		collect := func(emit func(float64, ...string)) {
+			for channel, s := range cm.SendStats() {
+				emit(m.value(s), channel)
+			}
+		}
+		if m.counter {
+			reg.NewCounterFunc(m.name, m.help, []string{"channel"}, collect)
+		} else {
+			reg.NewGaugeFunc(m.name, m.help, []string{"channel"}, collect)
+		}
+	}
+
+	reg.NewGaugeFunc("picoclaw_bus_queue_depth",
+		"Messages waiting in the message bus.",
+		[]string{"queue"}, func(emit func(float64, ...string)) {
+			for queue, n := range msgBus.QueueDepths() {
+				emit(float64(n), queue)
+			}
+		})
+
+	reg.NewGaugeFunc("picoclaw_mcp_server_up",
+		"Whether an enabled MCP server is connected (1) or not (0).",
+		[]string{"server"}, func(emit func(float64, ...string)) {
+			for server, up := range al.MCPServerStatus() {
+				v := 0.0
+				if up {
+					v = 1
+				}
+				emit(v, server)
+			}
+		})
+
+	sub := al.SubscribeEvents(metricsEventBuffer)
+	go func() {
+		for evt := range sub.C {
+			agentID := evt.Meta.AgentID
+			switch p := evt.Payload.(type) {
+			case agent.TurnEndPayload:
+				turns.Inc(agentID, string(p.Status))
+				turnDuration.Observe(p.Duration.Seconds(), agentID)
+			case agent.LLMRequestPayload:
+				llmRequests.Inc(agentID, p.Model)
+			case agent.LLMRetryPayload:
+				llmRetries.Inc(agentID, p.Reason)
+			case agent.ToolExecEndPayload:
+				status := "ok"
+				if p.IsError {
+					status = "error"
+				}
+				toolCalls.Inc(p.Tool, status)
+				toolDuration.Observe(p.Duration.Seconds(), p.Tool)
+			case agent.ErrorPayload:
+				agentErrors.Inc(agentID, p.Stage)
+			}
+		}
+	}()
+
+	return reg
+}
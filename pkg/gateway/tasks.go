@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+)
+
+// tasksPath serves the subagent task queue: GET /tasks lists every agent's
+// tasks, POST /tasks/{owner}/{id}/cancel and POST /tasks/{owner}/{id}/rerun
+// act on one of them.
+const tasksPath = "/tasks"
+
+// newTasksHandler exposes the agent loop's subagent task queues over HTTP.
+// When authToken is set, requests must carry it as a bearer token.
+func newTasksHandler(agentLoop *agent.AgentLoop, authToken string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authToken != "" {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(authToken)) != 1 {
+				writeTasksJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+		}
+
+		rest := strings.Trim(strings.TrimPrefix(r.URL.Path, tasksPath), "/")
+		if rest == "" {
+			if r.Method != http.MethodGet {
+				writeTasksJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed, use GET"})
+				return
+			}
+			tasks := agentLoop.ListSubagentTasks()
+			if tasks == nil {
+				tasks = []agent.SubagentTaskEntry{}
+			}
+			writeTasksJSON(w, http.StatusOK, map[string]any{"tasks": tasks})
+			return
+		}
+
+		parts := strings.Split(rest, "/")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			writeTasksJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeTasksJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed, use POST"})
+			return
+		}
+
+		owner, id := parts[0], parts[1]
+		switch parts[2] {
+		case "cancel":
+			if err := agentLoop.CancelSubagentTask(owner, id); err != nil {
+				writeTasksJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+				return
+			}
+			writeTasksJSON(w, http.StatusOK, map[string]string{"status": "canceled", "id": id})
+		case "rerun":
+			task, err := agentLoop.RerunSubagentTask(owner, id)
+			if err != nil {
+				writeTasksJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+				return
+			}
+			writeTasksJSON(w, http.StatusOK, agent.SubagentTaskEntry{Owner: owner, SubagentTask: task})
+		default:
+			writeTasksJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+	})
+}
+
+func writeTasksJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
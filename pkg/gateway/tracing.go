@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tracing"
+)
+
+// applyTracingConfig installs a tracer exporting to the configured OTLP
+// collector, or disables tracing, and flushes the previous tracer. It runs at
+// startup and on every config reload.
+func applyTracingConfig(cfg *config.Config) {
+	var tracer *tracing.Tracer
+	if t := cfg.Gateway.Tracing; t != nil && t.Enabled {
+		exporter, err := tracing.NewOTLPExporter(tracing.OTLPOptions{
+			Endpoint:      t.Endpoint,
+			Headers:       t.Headers.Values(),
+			ServiceName:   t.ServiceName,
+			BatchSize:     t.BatchSize,
+			FlushInterval: time.Duration(t.FlushIntervalMS) * time.Millisecond,
+		})
+		if err != nil {
+			logger.WarnCF("gateway", "Tracing disabled", map[string]any{"error": err.Error()})
+		} else {
+			ratio := t.SampleRatio
+			if ratio <= 0 {
+				ratio = 1
+			}
+			tracer = tracing.NewTracer(tracing.Options{SampleRatio: ratio, Exporter: exporter})
+			logger.InfoCF("gateway", "Tracing enabled", map[string]any{
+				"endpoint":     t.Endpoint,
+				"sample_ratio": ratio,
+			})
+		}
+	}
+	if prev := tracing.SetTracer(tracer); prev != nil {
+		_ = prev.Shutdown()
+	}
+}
+
+// shutdownTracing flushes pending spans and disables tracing.
+func shutdownTracing() {
+	if prev := tracing.SetTracer(nil); prev != nil {
+		_ = prev.Shutdown()
+	}
+}
@@ -215,6 +215,34 @@ func TestReloadHandler_Error(t *testing.T) {
 	}
 }
 
+func TestMetricsHandler(t *testing.T) {
+	s := newTestServer()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer test")
+	w := httptest.NewRecorder()
+	s.metricsHandler(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("metrics without handler = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	s.SetMetricsHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("picoclaw_up 1\n"))
+	}))
+
+	w = httptest.NewRecorder()
+	s.metricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("metrics without token = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	w = httptest.NewRecorder()
+	s.metricsHandler(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "picoclaw_up 1\n" {
+		t.Errorf("metrics = %d %q", w.Code, w.Body.String())
+	}
+}
+
 func TestSetReady_Toggle(t *testing.T) {
 	s := newTestServer()
 
@@ -0,0 +1,404 @@
+package identity
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// LinkCodeTTL is how long a code from /link can be redeemed and the link
+// confirmed.
+const LinkCodeTTL = 10 * time.Minute
+
+const (
+	// linkCodeAlphabet leaves out characters that are easily confused.
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	linkCodeLength   = 10
+
+	// maxCodeMisses is how many failed redemptions, by anyone, a pending
+	// code survives. Failures cannot be tied to the code they aimed at, so
+	// each one counts against every pending code.
+	maxCodeMisses = 20
+	// maxSenderMisses is how many failed redemptions a sender may make
+	// within LinkCodeTTL.
+	maxSenderMisses = 5
+)
+
+var (
+	ErrInvalidLinkCode = errors.New("invalid or expired link code")
+	ErrSameIdentity    = errors.New("the code was issued to this identity")
+	ErrTooManyAttempts = errors.New("too many invalid link codes, try again later")
+	ErrNoLinkRequest   = errors.New("no pending link request from that identity")
+)
+
+// LinkStore persists canonical identities that users linked with /link, so
+// that one person on several platforms is treated as one user. Groups are
+// named after the identity that issued the first code and have the same
+// shape as SessionConfig.IdentityLinks.
+//
+// Linking takes two steps: another identity redeems a code, then the
+// identity that issued it confirms. A leaked or guessed code alone links
+// nothing.
+//
+// The file is re-read when it changes on disk, so links removed by the web
+// UI apply to a running gateway. Pending codes are kept in memory only.
+type LinkStore struct {
+	path string
+
+	mu      sync.Mutex
+	groups  map[string][]string
+	modTime time.Time
+	codes   map[string]*pendingLink
+	misses  map[string]*senderMisses
+	now     func() time.Time
+}
+
+type pendingLink struct {
+	id       string
+	claimant string // identity that redeemed the code, awaiting confirmation
+	expires  time.Time
+	misses   int
+}
+
+type senderMisses struct {
+	count int
+	since time.Time
+}
+
+type linkFile struct {
+	Groups map[string][]string `json:"groups"`
+}
+
+// LinkStorePath returns where the links of workspace are kept.
+func LinkStorePath(workspace string) string {
+	return filepath.Join(workspace, "state", "identity_links.json")
+}
+
+// NewLinkStore opens the link file at path. A missing file is an empty store.
+func NewLinkStore(path string) *LinkStore {
+	s := &LinkStore{
+		path:   path,
+		groups: make(map[string][]string),
+		codes:  make(map[string]*pendingLink),
+		misses: make(map[string]*senderMisses),
+		now:    time.Now,
+	}
+	s.reload()
+	return s
+}
+
+// reload re-reads the file if it changed since the last read. Caller must
+// hold s.mu.
+func (s *LinkStore) reload() {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if !s.modTime.IsZero() {
+			s.groups = make(map[string][]string)
+			s.modTime = time.Time{}
+		}
+		return
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	var f linkFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return
+	}
+	s.groups = f.Groups
+	if s.groups == nil {
+		s.groups = make(map[string][]string)
+	}
+	s.modTime = info.ModTime()
+}
+
+// save writes the groups to disk. Caller must hold s.mu.
+func (s *LinkStore) save() error {
+	data, err := json.MarshalIndent(linkFile{Groups: s.groups}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(s.path, data, 0o600); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// groupOf returns the name of the group id belongs to. Caller must hold s.mu.
+func (s *LinkStore) groupOf(id string) (string, bool) {
+	for name, members := range s.groups {
+		for _, m := range members {
+			if strings.EqualFold(m, id) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IssueCode returns a one-time code that another identity redeems to ask
+// to be linked to id. Earlier codes of id are replaced.
+func (s *LinkStore) IssueCode(id string) (string, error) {
+	if _, _, ok := ParseCanonicalID(id); !ok {
+		return "", fmt.Errorf("not a canonical identity: %q", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	for code, p := range s.codes {
+		if p.id == id {
+			delete(s.codes, code)
+		}
+	}
+	for {
+		code, err := newLinkCode()
+		if err != nil {
+			return "", err
+		}
+		if _, taken := s.codes[code]; !taken {
+			s.codes[code] = &pendingLink{id: id, expires: now.Add(LinkCodeTTL)}
+			return code, nil
+		}
+	}
+}
+
+func newLinkCode() (string, error) {
+	b := make([]byte, linkCodeLength)
+	size := big.NewInt(int64(len(linkCodeAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		b[i] = linkCodeAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// prune drops expired codes and miss counts. Caller must hold s.mu.
+func (s *LinkStore) prune(now time.Time) {
+	for code, p := range s.codes {
+		if now.After(p.expires) {
+			delete(s.codes, code)
+		}
+	}
+	for id, m := range s.misses {
+		if now.Sub(m.since) > LinkCodeTTL {
+			delete(s.misses, id)
+		}
+	}
+}
+
+// Pending reports whether a code is waiting to be redeemed.
+func (s *LinkStore) Pending() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, p := range s.codes {
+		if p.claimant == "" && !now.After(p.expires) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redeem records that id asks to be linked to the identity that issued
+// code, and returns that identity. The link takes effect once the issuer
+// confirms it with Confirm. Invalid codes count against id and against
+// every pending code; too many of them lock id out or void the codes.
+func (s *LinkStore) Redeem(code, id string) (string, error) {
+	if _, _, ok := ParseCanonicalID(id); !ok {
+		return "", fmt.Errorf("not a canonical identity: %q", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	key := strings.ToLower(id)
+	if m := s.misses[key]; m != nil && m.count >= maxSenderMisses {
+		return "", ErrTooManyAttempts
+	}
+
+	p, ok := s.codes[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok || (p.claimant != "" && !strings.EqualFold(p.claimant, id)) {
+		s.miss(key, now)
+		return "", ErrInvalidLinkCode
+	}
+	if strings.EqualFold(p.id, id) {
+		return "", ErrSameIdentity
+	}
+	p.claimant = id
+	return p.id, nil
+}
+
+// miss records a failed redemption by the sender with the lower-cased
+// identity key. Caller must hold s.mu.
+func (s *LinkStore) miss(key string, now time.Time) {
+	m := s.misses[key]
+	if m == nil {
+		m = &senderMisses{since: now}
+		s.misses[key] = m
+	}
+	m.count++
+	for code, p := range s.codes {
+		if p.misses++; p.misses >= maxCodeMisses {
+			delete(s.codes, code)
+		}
+	}
+}
+
+// Confirm links claimant, which redeemed a code issued to issuer, to issuer
+// and returns every identity in the resulting group. Groups of both sides
+// are merged.
+func (s *LinkStore) Confirm(issuer, claimant string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	for code, p := range s.codes {
+		if !strings.EqualFold(p.id, issuer) || p.claimant == "" || !strings.EqualFold(p.claimant, claimant) {
+			continue
+		}
+		delete(s.codes, code)
+		return s.link(p.id, p.claimant)
+	}
+	return nil, ErrNoLinkRequest
+}
+
+// link merges the groups of id and other and saves them. Caller must hold
+// s.mu.
+func (s *LinkStore) link(id, other string) ([]string, error) {
+	s.reload()
+
+	name, ok := s.groupOf(id)
+	if !ok {
+		name = id
+		s.groups[name] = []string{id}
+	}
+	members := s.groups[name]
+	add := []string{other}
+	if otherGroup, ok := s.groupOf(other); ok && otherGroup != name {
+		add = s.groups[otherGroup]
+		delete(s.groups, otherGroup)
+	}
+	for _, a := range add {
+		if !containsFold(members, a) {
+			members = append(members, a)
+		}
+	}
+	sort.Strings(members)
+	s.groups[name] = members
+
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return append([]string(nil), members...), nil
+}
+
+// Unlink removes id from its group. A group left with one identity is
+// dropped. It reports whether id was linked.
+func (s *LinkStore) Unlink(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+
+	name, ok := s.groupOf(id)
+	if !ok {
+		return false, nil
+	}
+	var rest []string
+	for _, m := range s.groups[name] {
+		if !strings.EqualFold(m, id) {
+			rest = append(rest, m)
+		}
+	}
+	if len(rest) < 2 {
+		delete(s.groups, name)
+	} else {
+		s.groups[name] = rest
+	}
+	return true, s.save()
+}
+
+// Linked returns the identities linked to id, including id itself, or nil
+// if id is not linked.
+func (s *LinkStore) Linked(id string) []string {
+	if s == nil || id == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+
+	name, ok := s.groupOf(id)
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), s.groups[name]...)
+}
+
+// Group returns the name of the group id is linked in and its members, or
+// ok false if id is not linked.
+func (s *LinkStore) Group(id string) (name string, members []string, ok bool) {
+	if s == nil || id == "" {
+		return "", nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+
+	name, ok = s.groupOf(id)
+	if !ok {
+		return "", nil, false
+	}
+	return name, append([]string(nil), s.groups[name]...), true
+}
+
+// Groups returns a copy of all link groups, keyed by group name.
+func (s *LinkStore) Groups() map[string][]string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reload()
+
+	out := make(map[string][]string, len(s.groups))
+	for name, members := range s.groups {
+		out[name] = append([]string(nil), members...)
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkStore_IssueRedeemUnlink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "identity_links.json")
+	s := NewLinkStore(path)
+
+	code, err := s.IssueCode("telegram:123")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	if len(code) != linkCodeLength || !s.Pending() {
+		t.Fatalf("code = %q, Pending() = %v", code, s.Pending())
+	}
+	if _, err := s.Redeem(code, "telegram:123"); !errors.Is(err, ErrSameIdentity) {
+		t.Fatalf("Redeem() by issuer error = %v, want ErrSameIdentity", err)
+	}
+
+	issuer, err := s.Redeem(strings.ToLower(code), "discord:456")
+	if err != nil || issuer != "telegram:123" {
+		t.Fatalf("Redeem() = %q, %v", issuer, err)
+	}
+	if s.Pending() || s.Linked("discord:456") != nil {
+		t.Fatal("a redeemed code should wait for confirmation and stop admitting senders")
+	}
+	if _, err := s.Redeem(code, "slack:U1"); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Fatalf("Redeem() of a claimed code error = %v, want ErrInvalidLinkCode", err)
+	}
+	if _, err := s.Confirm("telegram:123", "slack:U1"); !errors.Is(err, ErrNoLinkRequest) {
+		t.Fatalf("Confirm() of another identity error = %v, want ErrNoLinkRequest", err)
+	}
+	if _, err := s.Confirm("discord:456", "telegram:123"); !errors.Is(err, ErrNoLinkRequest) {
+		t.Fatalf("Confirm() by the claimant error = %v, want ErrNoLinkRequest", err)
+	}
+
+	linked, err := s.Confirm("telegram:123", "discord:456")
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	want := []string{"discord:456", "telegram:123"}
+	if !reflect.DeepEqual(linked, want) {
+		t.Fatalf("linked = %v, want %v", linked, want)
+	}
+	if _, err := s.Confirm("telegram:123", "discord:456"); !errors.Is(err, ErrNoLinkRequest) {
+		t.Fatalf("second Confirm() error = %v, want ErrNoLinkRequest", err)
+	}
+
+	// A second store sees the persisted links.
+	if got := NewLinkStore(path).Linked("discord:456"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("reloaded Linked() = %v, want %v", got, want)
+	}
+	if name, members, ok := s.Group("discord:456"); !ok || name != "telegram:123" || !reflect.DeepEqual(members, want) {
+		t.Fatalf("Group() = %q, %v, %v", name, members, ok)
+	}
+
+	removed, err := s.Unlink("discord:456")
+	if err != nil || !removed {
+		t.Fatalf("Unlink() = %v, %v", removed, err)
+	}
+	if got := s.Groups(); len(got) != 0 {
+		t.Fatalf("Groups() after unlink = %v, want none", got)
+	}
+}
+
+func TestLinkStore_CodeExpires(t *testing.T) {
+	s := NewLinkStore(filepath.Join(t.TempDir(), "links.json"))
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	code, err := s.IssueCode("telegram:123")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	now = now.Add(LinkCodeTTL + time.Second)
+	if s.Pending() {
+		t.Fatal("Pending() = true after expiry")
+	}
+	if _, err := s.Redeem(code, "discord:456"); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Fatalf("Redeem() error = %v, want ErrInvalidLinkCode", err)
+	}
+}
+
+func TestLinkStore_LimitsFailedAttempts(t *testing.T) {
+	s := NewLinkStore(filepath.Join(t.TempDir(), "links.json"))
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	code, err := s.IssueCode("telegram:123")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+
+	// A sender is locked out after a few misses, even with the right code.
+	for range maxSenderMisses {
+		if _, err := s.Redeem("AAAAAAAAAA", "discord:456"); !errors.Is(err, ErrInvalidLinkCode) {
+			t.Fatalf("Redeem() error = %v, want ErrInvalidLinkCode", err)
+		}
+	}
+	if _, err := s.Redeem(code, "discord:456"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("Redeem() after misses error = %v, want ErrTooManyAttempts", err)
+	}
+	now = now.Add(LinkCodeTTL + time.Second)
+	if _, err := s.Redeem("AAAAAAAAAA", "discord:456"); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Fatalf("Redeem() after the lockout error = %v, want ErrInvalidLinkCode", err)
+	}
+
+	// Misses spread over many senders void the pending codes.
+	code, err = s.IssueCode("telegram:123")
+	if err != nil {
+		t.Fatalf("IssueCode() error = %v", err)
+	}
+	for i := range maxCodeMisses {
+		_, _ = s.Redeem("AAAAAAAAAA", fmt.Sprintf("discord:%d", i))
+	}
+	if s.Pending() {
+		t.Fatal("Pending() = true after too many misses")
+	}
+	if _, err := s.Redeem(code, "discord:456"); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Fatalf("Redeem() of a voided code error = %v, want ErrInvalidLinkCode", err)
+	}
+}
+
+func TestLinkStore_RedeemMergesGroups(t *testing.T) {
+	s := NewLinkStore(filepath.Join(t.TempDir(), "links.json"))
+	link := func(from, to string) {
+		t.Helper()
+		code, err := s.IssueCode(from)
+		if err != nil {
+			t.Fatalf("IssueCode() error = %v", err)
+		}
+		if _, err := s.Redeem(code, to); err != nil {
+			t.Fatalf("Redeem() error = %v", err)
+		}
+		if _, err := s.Confirm(from, to); err != nil {
+			t.Fatalf("Confirm() error = %v", err)
+		}
+	}
+	link("telegram:1", "discord:2")
+	link("slack:U3", "matrix:@u:4")
+	link("discord:2", "slack:U3")
+
+	groups := s.Groups()
+	want := map[string][]string{"telegram:1": {"discord:2", "matrix:@u:4", "slack:U3", "telegram:1"}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("Groups() = %v, want %v", groups, want)
+	}
+}
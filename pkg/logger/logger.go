@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -24,6 +25,12 @@ const (
 	FATAL = zerolog.FatalLevel
 
 	Component = "component"
+
+	// Field names that JSON consumers can rely on. Use them for the
+	// conversation a log line belongs to.
+	FieldSessionKey = "session_key"
+	FieldAgentID    = "agent_id"
+	FieldTurnID     = "turn_id"
 )
 
 var (
@@ -35,13 +42,16 @@ var (
 		FATAL: "FATAL",
 	}
 
-	currentLevel  = INFO
-	logger        zerolog.Logger
-	logFile       *os.File
-	once          sync.Once
-	mu            sync.RWMutex
-	writers       []io.Writer
-	consoleWriter zerolog.ConsoleWriter
+	currentLevel   = INFO
+	logger         zerolog.Logger
+	logFile        *rotatingFile
+	rotation       Rotation
+	once           sync.Once
+	mu             sync.RWMutex
+	consoleEnabled = true
+	jsonConsole    bool
+	shippers       []io.WriteCloser
+	consoleWriter  zerolog.ConsoleWriter
 )
 
 func init() {
@@ -73,9 +83,7 @@ func init() {
 			NoColor: !isTTY,
 		}
 
-		writers = append(writers, consoleWriter)
-
-		logger = zerolog.New(io.MultiWriter(writers...)).With().Timestamp().Caller().Logger()
+		logger = zerolog.New(consoleWriter).With().Timestamp().Caller().Logger()
 	})
 }
 
@@ -123,18 +131,110 @@ func SetConsoleLevel(level LogLevel) {
 	logger = logger.Level(level)
 }
 
+// rebuildOutput points the logger at the console, the log file and the
+// shippers. Caller must hold mu.
+func rebuildOutput() {
+	var console io.Writer = io.Discard
+	if consoleEnabled {
+		console = consoleWriter
+		if jsonConsole {
+			console = os.Stdout
+		}
+	}
+	writers := []io.Writer{console}
+	if logFile != nil {
+		writers = append(writers, logFile)
+	}
+	for _, s := range shippers {
+		writers = append(writers, s)
+	}
+	logger = logger.Output(io.MultiWriter(writers...))
+}
+
 func DisableConsole() {
 	mu.Lock()
 	defer mu.Unlock()
-	writers[0] = io.Discard
-	logger = logger.Output(io.MultiWriter(writers...))
+	consoleEnabled = false
+	rebuildOutput()
 }
 
 func EnableConsole() {
 	mu.Lock()
 	defer mu.Unlock()
-	writers[0] = consoleWriter
-	logger = logger.Output(io.MultiWriter(writers...))
+	consoleEnabled = true
+	rebuildOutput()
+}
+
+// SetFormat selects the console format: "console" (default) for
+// human-readable lines or "json" for one JSON object per line, as written
+// to the log file and shippers.
+func SetFormat(format string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "console", "text":
+		jsonConsole = false
+	case "json":
+		jsonConsole = true
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	rebuildOutput()
+	return nil
+}
+
+// ConsoleLine renders a line written in the "json" format the way the
+// "console" format prints it, without colors, so tools that show the
+// gateway's output read the same text in both formats. Other lines are
+// returned unchanged.
+func ConsoleLine(line string) string {
+	if !strings.HasPrefix(line, "{") {
+		return line
+	}
+	var buf bytes.Buffer
+	mu.RLock()
+	w := consoleWriter
+	mu.RUnlock()
+	w.Out = &buf
+	w.NoColor = true
+	w.FormatPrepare = nil
+	if _, err := w.Write([]byte(line)); err != nil {
+		return line
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// SetRotation sets the rotation of the log file, including one that is
+// already open.
+func SetRotation(r Rotation) {
+	mu.Lock()
+	defer mu.Unlock()
+	rotation = r
+	if logFile != nil {
+		logFile.SetRotation(r)
+	}
+}
+
+// AddShipper sends every log event, as JSON, to w as well, for example a
+// writer from NewSyslogWriter or NewHTTPWriter.
+func AddShipper(w io.WriteCloser) {
+	mu.Lock()
+	defer mu.Unlock()
+	shippers = append(shippers, w)
+	rebuildOutput()
+}
+
+// CloseShippers flushes and removes all shippers.
+func CloseShippers() {
+	mu.Lock()
+	closing := shippers
+	shippers = nil
+	rebuildOutput()
+	mu.Unlock()
+
+	for _, s := range closing {
+		s.Close()
+	}
 }
 
 func GetLevel() LogLevel {
@@ -177,13 +277,9 @@ func EnableFileLogging(filePath string) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
-	}
-
-	newFile, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	newFile, err := openRotatingFile(filePath, rotation)
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		return err
 	}
 
 	// Close old file if exists
@@ -192,13 +288,7 @@ func EnableFileLogging(filePath string) error {
 	}
 
 	logFile = newFile
-
-	if len(writers) != 1 {
-		return fmt.Errorf("failed to configure file logging: %w", err)
-	}
-
-	writers = append(writers, logFile)
-	logger = logger.Output(io.MultiWriter(writers...))
+	rebuildOutput()
 
 	return nil
 }
@@ -210,10 +300,7 @@ func DisableFileLogging() {
 	if logFile != nil {
 		logFile.Close()
 		logFile = nil
-	}
-	if len(writers) > 1 {
-		writers = writers[:1]
-		logger = logger.Output(io.MultiWriter(writers...))
+		rebuildOutput()
 	}
 }
 
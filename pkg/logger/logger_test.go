@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -431,3 +432,19 @@ func TestGetPackageNameFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestConsoleLine(t *testing.T) {
+	line := `{"level":"info","component":"agent","session_key":"s1","time":"2026-03-01T12:00:00Z",` +
+		`"message":"Turn started"}`
+	got := ConsoleLine(line)
+	if strings.HasPrefix(got, "{") || !strings.Contains(got, "INF") || !strings.Contains(got, "agent") ||
+		!strings.Contains(got, "Turn started") || !strings.Contains(got, "session_key=s1") {
+		t.Fatalf("ConsoleLine() = %q", got)
+	}
+	if got := ConsoleLine("12:00:00 INF plain line"); got != "12:00:00 INF plain line" {
+		t.Fatalf("ConsoleLine() of a text line = %q", got)
+	}
+	if got := ConsoleLine("{not json"); got != "{not json" {
+		t.Fatalf("ConsoleLine() of a broken line = %q", got)
+	}
+}
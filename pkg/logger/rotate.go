@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation configures when the log file is rotated and how many rotated
+// files are kept. The zero value never rotates.
+type Rotation struct {
+	MaxSize    int64         // rotate before the file grows past this many bytes
+	MaxFileAge time.Duration // rotate once the current file is this old
+	MaxBackups int           // rotated files to keep; 0 keeps all
+	Retention  time.Duration // delete rotated files older than this; 0 keeps all
+	Compress   bool          // gzip rotated files
+}
+
+// backupTimeFormat is the timestamp in rotated file names,
+// e.g. "picoclaw-20261019T101500.000.log".
+const backupTimeFormat = "20060102T150405.000"
+
+// rotatingFile is an append-only log file that rotates itself. Rotated files
+// are renamed with a timestamp, then compressed and pruned in the
+// background.
+type rotatingFile struct {
+	path string
+
+	mu      sync.Mutex
+	rot     Rotation
+	file    *os.File
+	size    int64
+	opened  time.Time
+	now     func() time.Time
+	cleanup sync.WaitGroup
+}
+
+func openRotatingFile(path string, rot Rotation) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &rotatingFile{path: path, rot: rot, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the file for appending. Caller must hold r.mu unless r is not
+// shared yet.
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	r.file = f
+	r.size = 0
+	r.opened = r.now()
+	if info, err := f.Stat(); err == nil {
+		r.size = info.Size()
+		if r.size > 0 {
+			r.opened = info.ModTime()
+		}
+	}
+	return nil
+}
+
+// SetRotation changes the rotation of an open file.
+func (r *rotatingFile) SetRotation(rot Rotation) {
+	r.mu.Lock()
+	r.rot = rot
+	r.mu.Unlock()
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	tooBig := r.rot.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.rot.MaxSize
+	tooOld := r.rot.MaxFileAge > 0 && r.size > 0 && r.now().Sub(r.opened) >= r.rot.MaxFileAge
+	if tooBig || tooOld {
+		if err := r.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate renames the current file and opens a new one. Caller must hold r.mu.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	ext := filepath.Ext(r.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(r.path, ext), r.now().Format(backupTimeFormat), ext)
+	renameErr := os.Rename(r.path, backup)
+	if err := r.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	rot := r.rot
+	r.cleanup.Add(1)
+	go func() {
+		defer r.cleanup.Done()
+		if rot.Compress {
+			if err := gzipFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "log compression failed: %v\n", err)
+			}
+		}
+		r.prune(rot)
+	}()
+	return nil
+}
+
+type backupFile struct {
+	name    string
+	rotated time.Time
+}
+
+// backups returns rotated files, newest first.
+func (r *rotatingFile) backups() []backupFile {
+	ext := filepath.Ext(r.path)
+	prefix := filepath.Base(strings.TrimSuffix(r.path, ext)) + "-"
+	entries, err := os.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		return nil
+	}
+	var files []backupFile
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		stamp = strings.TrimSuffix(strings.TrimSuffix(stamp, ".gz"), ext)
+		if t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local); err == nil {
+			files = append(files, backupFile{name: e.Name(), rotated: t})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].rotated.After(files[j].rotated) })
+	return files
+}
+
+// prune removes rotated files beyond MaxBackups or older than Retention.
+func (r *rotatingFile) prune(rot Rotation) {
+	dir := filepath.Dir(r.path)
+	for i, b := range r.backups() {
+		if (rot.MaxBackups > 0 && i >= rot.MaxBackups) ||
+			(rot.Retention > 0 && r.now().Sub(b.rotated) > rot.Retention) {
+			_ = os.Remove(filepath.Join(dir, b.name))
+		}
+	}
+}
+
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err = io.Copy(zw, in); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
+
+// Close closes the file and waits for background compression.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mu.Unlock()
+	r.cleanup.Wait()
+	return err
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "picoclaw.log")
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.Local)
+	r, err := openRotatingFile(path, Rotation{MaxSize: 10, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.now = func() time.Time { return now }
+
+	if _, err := r.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write([]byte("next\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	current, _ := os.ReadFile(path)
+	if string(current) != "next\n" {
+		t.Fatalf("current file = %q, want %q", current, "next\n")
+	}
+	backups := r.backups()
+	if len(backups) != 1 || !strings.HasSuffix(backups[0].name, ".log.gz") {
+		t.Fatalf("backups = %+v, want one compressed file", backups)
+	}
+
+	f, err := os.Open(filepath.Join(filepath.Dir(path), backups[0].name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if string(data) != "0123456789" {
+		t.Fatalf("backup = %q", data)
+	}
+}
+
+func TestRotatingFileRotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "picoclaw.log")
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.Local)
+	r, err := openRotatingFile(path, Rotation{MaxFileAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.now = func() time.Time { return now }
+	r.opened = now
+
+	r.Write([]byte("old\n"))
+	now = now.Add(2 * time.Hour)
+	r.Write([]byte("new\n"))
+	r.Close()
+
+	if backups := r.backups(); len(backups) != 1 {
+		t.Fatalf("backups = %+v, want 1", backups)
+	}
+	current, _ := os.ReadFile(path)
+	if string(current) != "new\n" {
+		t.Fatalf("current file = %q", current)
+	}
+}
+
+func TestRotatingFilePrunesBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "picoclaw.log")
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.Local)
+	for _, age := range []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 10 * 24 * time.Hour} {
+		name := "picoclaw-" + now.Add(-age).Format(backupTimeFormat) + ".log"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "unrelated.log"), []byte("x"), 0o644)
+
+	r := &rotatingFile{path: path, now: func() time.Time { return now }}
+	r.prune(Rotation{MaxBackups: 2, Retention: 7 * 24 * time.Hour})
+
+	backups := r.backups()
+	if len(backups) != 2 {
+		t.Fatalf("backups = %+v, want 2", backups)
+	}
+	if !backups[0].rotated.Equal(now.Add(-time.Hour)) {
+		t.Fatalf("newest backup = %v, want the one rotated an hour ago", backups[0].rotated)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "unrelated.log")); err != nil {
+		t.Fatal("prune removed a file that is not a backup")
+	}
+}
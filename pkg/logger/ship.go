@@ -0,0 +1,301 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shipQueueSize bounds the events waiting to be shipped. When a remote end
+// is slow or down, newer events are dropped instead of blocking logging.
+const shipQueueSize = 1024
+
+// SyslogOptions configures shipping to a syslog server in RFC 5424 format.
+type SyslogOptions struct {
+	Network  string // "udp" (default) or "tcp"
+	Address  string // host:port
+	AppName  string // APP-NAME field, "picoclaw" by default
+	Facility string // "user" (default), "daemon" or "local0".."local7"
+}
+
+// HTTPOptions configures batched shipping to a Loki push endpoint.
+type HTTPOptions struct {
+	URL           string            // e.g. http://localhost:3100/loki/api/v1/push
+	Labels        map[string]string // stream labels; "level" is added per event
+	Headers       map[string]string // extra request headers, e.g. Authorization
+	BatchSize     int               // events per push, 100 by default
+	FlushInterval time.Duration     // longest wait before a partial batch is pushed, 1s by default
+}
+
+// shipErrorInterval is the shortest time between two reports of failed
+// pushes.
+const shipErrorInterval = time.Minute
+
+// failureReporter writes failures to stderr at most once per interval and
+// counts the ones in between. Failures are not logged: a logged failure
+// would be shipped and fail again. Not safe for concurrent use.
+type failureReporter struct {
+	what     string
+	interval time.Duration
+	out      io.Writer
+	now      func() time.Time
+
+	last       time.Time
+	suppressed int
+}
+
+func newFailureReporter(what string) *failureReporter {
+	return &failureReporter{what: what, interval: shipErrorInterval, out: os.Stderr, now: time.Now}
+}
+
+func (r *failureReporter) report(reason string) {
+	now := r.now()
+	if !r.last.IsZero() && now.Sub(r.last) < r.interval {
+		r.suppressed++
+		return
+	}
+	if r.suppressed > 0 {
+		fmt.Fprintf(r.out, "%s failed: %s (%d more failures not shown)\n", r.what, reason, r.suppressed)
+	} else {
+		fmt.Fprintf(r.out, "%s failed: %s\n", r.what, reason)
+	}
+	r.last = now
+	r.suppressed = 0
+}
+
+// shipper hands events from the log path to a background sender.
+type shipper struct {
+	queue chan []byte
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newShipper(run func(queue <-chan []byte)) *shipper {
+	s := &shipper{queue: make(chan []byte, shipQueueSize), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		run(s.queue)
+	}()
+	return s
+}
+
+func (s *shipper) Write(p []byte) (int, error) {
+	event := append([]byte(nil), p...)
+	select {
+	case s.queue <- event:
+	default:
+	}
+	return len(p), nil
+}
+
+// Close flushes queued events and stops the sender.
+func (s *shipper) Close() error {
+	s.once.Do(func() { close(s.queue) })
+	<-s.done
+	return nil
+}
+
+// shippedEvent holds the fields a shipper needs from a zerolog JSON event.
+type shippedEvent struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func parseEvent(p []byte) shippedEvent {
+	var e shippedEvent
+	_ = json.Unmarshal(p, &e)
+	if e.Level == "" {
+		e.Level = "info"
+	}
+	return e
+}
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "daemon": 3, "auth": 4, "syslog": 5,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var syslogSeverities = map[string]int{
+	"panic": 0, "fatal": 2, "error": 3, "warn": 4, "info": 6, "debug": 7, "trace": 7,
+}
+
+// NewSyslogWriter returns a writer that ships each event to a syslog
+// server as an RFC 5424 message whose MSG is the JSON event. TCP uses
+// octet-counting framing (RFC 6587). The connection is redialed after
+// errors.
+func NewSyslogWriter(opts SyslogOptions) (io.WriteCloser, error) {
+	network := strings.ToLower(opts.Network)
+	if network == "" {
+		network = "udp"
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("syslog network must be udp or tcp, got %q", opts.Network)
+	}
+	if opts.Address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+	facility, ok := syslogFacilities[strings.ToLower(opts.Facility)]
+	if opts.Facility == "" {
+		facility, ok = 1, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", opts.Facility)
+	}
+	appName := opts.AppName
+	if appName == "" {
+		appName = "picoclaw"
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	procID := strconv.Itoa(os.Getpid())
+
+	return newShipper(func(queue <-chan []byte) {
+		var conn net.Conn
+		defer func() {
+			if conn != nil {
+				conn.Close()
+			}
+		}()
+		for p := range queue {
+			e := parseEvent(p)
+			severity, ok := syslogSeverities[e.Level]
+			if !ok {
+				severity = 6
+			}
+			timestamp := e.Time
+			if timestamp == "" {
+				timestamp = time.Now().Format(time.RFC3339)
+			}
+			msg := fmt.Sprintf("<%d>1 %s %s %s %s - - %s",
+				facility*8+severity, timestamp, hostname, appName, procID, bytes.TrimSpace(p))
+			if network == "tcp" {
+				msg = strconv.Itoa(len(msg)) + " " + msg
+			}
+
+			if conn == nil {
+				var err error
+				if conn, err = net.DialTimeout(network, opts.Address, 5*time.Second); err != nil {
+					conn = nil
+					continue
+				}
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if _, err := conn.Write([]byte(msg)); err != nil {
+				conn.Close()
+				conn = nil
+			}
+		}
+	}), nil
+}
+
+// NewHTTPWriter returns a writer that pushes events to a Loki push API in
+// batches. Each event's JSON is the log line; events are grouped into
+// streams by level.
+func NewHTTPWriter(opts HTTPOptions) (io.WriteCloser, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("log http url is required")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	failures := newFailureReporter("log push")
+
+	type entry struct {
+		level string
+		ts    string
+		line  string
+	}
+	push := func(batch []entry) {
+		streams := map[string][][2]string{}
+		for _, e := range batch {
+			streams[e.level] = append(streams[e.level], [2]string{e.ts, e.line})
+		}
+		type stream struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		}
+		var body struct {
+			Streams []stream `json:"streams"`
+		}
+		for level, values := range streams {
+			labels := map[string]string{"level": level}
+			for k, v := range opts.Labels {
+				labels[k] = v
+			}
+			body.Streams = append(body.Streams, stream{Stream: labels, Values: values})
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return
+		}
+		req, err := http.NewRequest(http.MethodPost, opts.URL, bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range opts.Headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			failures.report(err.Error())
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			failures.report(resp.Status)
+		}
+	}
+
+	return newShipper(func(queue <-chan []byte) {
+		ticker := time.NewTicker(opts.FlushInterval)
+		defer ticker.Stop()
+		var batch []entry
+		flush := func() {
+			if len(batch) > 0 {
+				push(batch)
+				batch = nil
+			}
+		}
+		for {
+			select {
+			case p, ok := <-queue:
+				if !ok {
+					flush()
+					return
+				}
+				e := parseEvent(p)
+				ts := time.Now()
+				if t, err := time.Parse(time.RFC3339, e.Time); err == nil {
+					ts = t
+				}
+				batch = append(batch, entry{
+					level: e.Level,
+					ts:    strconv.FormatInt(ts.UnixNano(), 10),
+					line:  string(bytes.TrimSpace(p)),
+				})
+				if len(batch) >= opts.BatchSize {
+					flush()
+				}
+			case <-ticker.C:
+				flush()
+			}
+		}
+	}), nil
+}
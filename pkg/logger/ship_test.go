@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testEvent = `{"level":"warn","component":"agent","session_key":"s1",` +
+	`"time":"2026-10-19T10:00:00Z","message":"hello"}` + "\n"
+
+func TestSyslogWriterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := NewSyslogWriter(SyslogOptions{Address: conn.LocalAddr().String(), Facility: "local0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(testEvent))
+	w.Close()
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// local0 (16) * 8 + warning (4)
+	if !strings.HasPrefix(msg, "<132>1 2026-10-19T10:00:00Z ") {
+		t.Fatalf("unexpected header: %q", msg)
+	}
+	if !strings.Contains(msg, " picoclaw ") || !strings.HasSuffix(msg, `"message":"hello"}`) {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+}
+
+func TestSyslogWriterTCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		r := bufio.NewReader(c)
+		for {
+			prefix, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, err := strconv.Atoi(strings.TrimSpace(prefix))
+			if err != nil {
+				return
+			}
+			msg := make([]byte, size)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	w, err := NewSyslogWriter(SyslogOptions{Network: "tcp", Address: ln.Addr().String(), AppName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(testEvent))
+	w.Write([]byte(testEvent))
+	w.Close()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-received:
+			if !strings.HasPrefix(msg, "<12>1 ") || !strings.Contains(msg, " test ") {
+				t.Fatalf("unexpected message: %q", msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d messages, want 2", i)
+		}
+	}
+}
+
+func TestHTTPWriterBatchesLokiPushes(t *testing.T) {
+	type push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	var (
+		mu     sync.Mutex
+		pushes []push
+		auth   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p push
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("decode push: %v", err)
+		}
+		mu.Lock()
+		pushes = append(pushes, p)
+		auth = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	w, err := NewHTTPWriter(HTTPOptions{
+		URL:           srv.URL,
+		Labels:        map[string]string{"app": "picoclaw"},
+		Headers:       map[string]string{"Authorization": "Bearer t"},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		w.Write([]byte(testEvent))
+	}
+	w.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushes) != 2 {
+		t.Fatalf("pushes = %d, want a full batch and the rest on close", len(pushes))
+	}
+	if auth != "Bearer t" {
+		t.Fatalf("Authorization = %q", auth)
+	}
+	s := pushes[0].Streams[0]
+	if s.Stream["app"] != "picoclaw" || s.Stream["level"] != "warn" || len(s.Values) != 2 {
+		t.Fatalf("unexpected stream: %+v", s)
+	}
+	if s.Values[0][0] != "1792404000000000000" {
+		t.Fatalf("timestamp = %s", s.Values[0][0])
+	}
+	var event map[string]any
+	if err := json.Unmarshal([]byte(s.Values[0][1]), &event); err != nil || event[FieldSessionKey] != "s1" {
+		t.Fatalf("line = %q", s.Values[0][1])
+	}
+}
+
+func TestFailureReporterRateLimits(t *testing.T) {
+	var out strings.Builder
+	now := time.Now()
+	r := newFailureReporter("log push")
+	r.out = &out
+	r.now = func() time.Time { return now }
+
+	r.report("503 Service Unavailable")
+	for range 5 {
+		now = now.Add(time.Second)
+		r.report("503 Service Unavailable")
+	}
+	now = now.Add(shipErrorInterval)
+	r.report("connection refused")
+
+	want := "log push failed: 503 Service Unavailable\n" +
+		"log push failed: connection refused (5 more failures not shown)\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+}
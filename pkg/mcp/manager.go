@@ -17,6 +17,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/tracing"
 )
 
 // headerTransport is an http.RoundTripper that adds custom headers to requests
@@ -297,12 +298,16 @@ func (m *Manager) ConnectServer(
 			DisableStandaloneSSE: disableStandaloneSSE,
 		}
 
+		// Propagate the trace of the calling turn to the server.
+		var base http.RoundTripper = &tracing.Transport{Base: http.DefaultTransport}
+		sseTransport.HTTPClient = &http.Client{Transport: base}
+
 		// Add custom headers if provided
 		if len(cfg.Headers) > 0 {
 			// Create a custom HTTP client with header-injecting transport
 			sseTransport.HTTPClient = &http.Client{
 				Transport: &headerTransport{
-					base:    http.DefaultTransport,
+					base:    base,
 					headers: cfg.Headers,
 				},
 			}
@@ -0,0 +1,319 @@
+// Package metrics is a small, dependency-free metrics registry that renders
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDurationBuckets are histogram buckets in seconds suited to turn,
+// provider and tool latencies.
+var DefaultDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Registry holds metric families and writes them in registration order.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]bool
+}
+
+type family interface {
+	write(w *bufio.Writer)
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = true
+	r.families = append(r.families, f)
+}
+
+// WriteText writes all metrics in the Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+// desc is the name, help and label names shared by all metric kinds.
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (d desc) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, kind)
+}
+
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// series writes one sample. extra is an additional label pair such as le.
+func (d desc) series(w *bufio.Writer, suffix string, values []string, extraName, extraValue string, v float64) {
+	w.WriteString(d.name)
+	w.WriteString(suffix)
+	if len(d.labels) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, l := range d.labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", l, escapeLabel(values[i]))
+		}
+		if extraName != "" {
+			if len(d.labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+// valueVec stores one float per label combination; it backs counters and
+// gauges.
+type valueVec struct {
+	desc
+	kind string
+
+	mu        sync.Mutex
+	values    map[string]float64
+	labelSets map[string][]string
+}
+
+func newValueVec(kind, name, help string, labels []string) *valueVec {
+	return &valueVec{
+		desc:      desc{name: name, help: help, labels: labels},
+		kind:      kind,
+		values:    make(map[string]float64),
+		labelSets: make(map[string][]string),
+	}
+}
+
+func (v *valueVec) update(values []string, fn func(float64) float64) {
+	key := v.key(values)
+	v.mu.Lock()
+	if _, ok := v.labelSets[key]; !ok {
+		v.labelSets[key] = append([]string(nil), values...)
+	}
+	v.values[key] = fn(v.values[key])
+	v.mu.Unlock()
+}
+
+func (v *valueVec) write(w *bufio.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.header(w, v.kind)
+	for _, key := range sortedKeys(v.values) {
+		v.series(w, "", v.labelSets[key], "", "", v.values[key])
+	}
+}
+
+// Counter is a monotonically increasing value per label combination.
+type Counter struct{ vec *valueVec }
+
+// NewCounter registers a counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newValueVec("counter", name, help, labels)}
+	r.register(name, c.vec)
+	return c
+}
+
+// Inc adds one to the counter for the label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the counter.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.vec.update(labelValues, func(v float64) float64 { return v + delta })
+}
+
+// Gauge is a value per label combination that can go up and down.
+type Gauge struct{ vec *valueVec }
+
+// NewGauge registers a gauge with the given label names.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{vec: newValueVec("gauge", name, help, labels)}
+	r.register(name, g.vec)
+	return g
+}
+
+// Set sets the gauge for the label values.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.vec.update(labelValues, func(float64) float64 { return v })
+}
+
+// Histogram counts observations into cumulative buckets per label
+// combination.
+type Histogram struct {
+	desc
+	buckets []float64
+
+	mu      sync.Mutex
+	entries map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram. Buckets are upper bounds in increasing
+// order; the +Inf bucket is implied.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		desc:    desc{name: name, help: help, labels: labels},
+		buckets: append([]float64(nil), buckets...),
+		entries: make(map[string]*histogramSeries),
+	}
+	sort.Float64s(h.buckets)
+	r.register(name, h)
+	return h
+}
+
+// Observe records v for the label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.entries[key]
+	if s == nil {
+		s = &histogramSeries{labels: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.entries[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+	for _, key := range sortedKeys(h.entries) {
+		s := h.entries[key]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += s.counts[i]
+			h.series(w, "_bucket", s.labels, "le", formatFloat(le), float64(cumulative))
+		}
+		h.series(w, "_bucket", s.labels, "le", "+Inf", float64(s.count))
+		h.series(w, "_sum", s.labels, "", "", s.sum)
+		h.series(w, "_count", s.labels, "", "", float64(s.count))
+	}
+}
+
+// funcFamily reads its samples when metrics are scraped.
+type funcFamily struct {
+	desc
+	kind    string
+	collect func(emit func(v float64, labelValues ...string))
+}
+
+func (f *funcFamily) write(w *bufio.Writer) {
+	type sample struct {
+		labels []string
+		value  float64
+	}
+	samples := make(map[string]sample)
+	f.collect(func(v float64, labelValues ...string) {
+		samples[f.key(labelValues)] = sample{labels: append([]string(nil), labelValues...), value: v}
+	})
+	f.header(w, f.kind)
+	for _, key := range sortedKeys(samples) {
+		f.series(w, "", samples[key].labels, "", "", samples[key].value)
+	}
+}
+
+// NewGaugeFunc registers a gauge whose values are produced by collect at
+// scrape time, e.g. a queue length.
+func (r *Registry) NewGaugeFunc(
+	name, help string,
+	labels []string,
+	collect func(emit func(v float64, labelValues ...string)),
+) {
+	r.register(name, &funcFamily{desc: desc{name: name, help: help, labels: labels}, kind: "gauge", collect: collect})
+}
+
+// NewCounterFunc registers a counter whose values are read at scrape time
+// from a component that already counts, e.g. dropped events.
+func (r *Registry) NewCounterFunc(
+	name, help string,
+	labels []string,
+	collect func(emit func(v float64, labelValues ...string)),
+) {
+	r.register(name, &funcFamily{desc: desc{name: name, help: help, labels: labels}, kind: "counter", collect: collect})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
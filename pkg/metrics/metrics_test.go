@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	reg := NewRegistry()
+	turns := reg.NewCounter("test_turns_total", "Turns.", "status")
+	depth := reg.NewGauge("test_depth", "Queue depth.")
+	latency := reg.NewHistogram("test_seconds", "Latency.", []float64{1, 0.1}, "tool")
+	reg.NewGaugeFunc("test_up", "Up.", []string{"server"}, func(emit func(float64, ...string)) {
+		emit(1, "b")
+		emit(0, `a"1`)
+	})
+
+	turns.Inc("ok")
+	turns.Add(2, "ok")
+	turns.Inc("error")
+	turns.Add(-1, "error")
+	depth.Set(3)
+	depth.Set(2)
+	latency.Observe(0.05, "exec")
+	latency.Observe(0.5, "exec")
+	latency.Observe(7, "exec")
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_turns_total Turns.
+# TYPE test_turns_total counter
+test_turns_total{status="error"} 1
+test_turns_total{status="ok"} 3
+# HELP test_depth Queue depth.
+# TYPE test_depth gauge
+test_depth 2
+# HELP test_seconds Latency.
+# TYPE test_seconds histogram
+test_seconds_bucket{tool="exec",le="0.1"} 1
+test_seconds_bucket{tool="exec",le="1"} 2
+test_seconds_bucket{tool="exec",le="+Inf"} 3
+test_seconds_sum{tool="exec"} 7.55
+test_seconds_count{tool="exec"} 3
+# HELP test_up Up.
+# TYPE test_up gauge
+test_up{server="a\"1"} 0
+test_up{server="b"} 1
+`
+	if b.String() != want {
+		t.Fatalf("WriteText =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("test_total", "Test.").Inc()
+
+	w := httptest.NewRecorder()
+	reg.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "test_total 1\n") {
+		t.Errorf("body = %q", w.Body.String())
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("test_total", "Test.")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a metric twice should panic")
+		}
+	}()
+	reg.NewGauge("test_total", "Test.")
+}
@@ -1,7 +1,9 @@
 package providers
 
 import (
+	"maps"
 	"math"
+	"sort"
 	"sync"
 	"time"
 )
@@ -26,6 +28,17 @@ type cooldownEntry struct {
 	DisabledUntil  time.Time      // billing-specific disable expiry
 	DisabledReason FailoverReason // reason for disable (billing)
 	LastFailure    time.Time
+	TotalFailures  map[FailoverReason]int64 // never reset, for metrics
+}
+
+// ProviderStatus is a point-in-time view of one provider's cooldown state.
+type ProviderStatus struct {
+	Provider          string
+	Available         bool
+	CooldownRemaining time.Duration
+	// TotalFailures counts every failure per reason since the tracker was
+	// created; unlike FailureCount it is not reset by MarkSuccess.
+	TotalFailures map[FailoverReason]int64
 }
 
 // NewCooldownTracker creates a tracker with default 24h failure window.
@@ -54,6 +67,7 @@ func (ct *CooldownTracker) MarkFailure(provider string, reason FailoverReason) {
 
 	entry.ErrorCount++
 	entry.FailureCounts[reason]++
+	entry.TotalFailures[reason]++
 	entry.LastFailure = now
 
 	if reason == FailoverBilling {
@@ -117,8 +131,11 @@ func (ct *CooldownTracker) CooldownRemaining(provider string) time.Duration {
 	if entry == nil {
 		return 0
 	}
+	return entry.remaining(ct.nowFunc())
+}
 
-	now := ct.nowFunc()
+// remaining returns how long until the entry's cooldowns expire.
+func (entry *cooldownEntry) remaining(now time.Time) time.Duration {
 	var remaining time.Duration
 
 	if !entry.DisabledUntil.IsZero() && now.Before(entry.DisabledUntil) {
@@ -162,11 +179,33 @@ func (ct *CooldownTracker) FailureCount(provider string, reason FailoverReason)
 	return entry.FailureCounts[reason]
 }
 
+// Snapshot returns the state of every provider that has failed at least
+// once, sorted by provider.
+func (ct *CooldownTracker) Snapshot() []ProviderStatus {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	now := ct.nowFunc()
+	out := make([]ProviderStatus, 0, len(ct.entries))
+	for name, entry := range ct.entries {
+		remaining := entry.remaining(now)
+		out = append(out, ProviderStatus{
+			Provider:          name,
+			Available:         remaining == 0,
+			CooldownRemaining: remaining,
+			TotalFailures:     maps.Clone(entry.TotalFailures),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
 func (ct *CooldownTracker) getOrCreate(provider string) *cooldownEntry {
 	entry := ct.entries[provider]
 	if entry == nil {
 		entry = &cooldownEntry{
 			FailureCounts: make(map[FailoverReason]int),
+			TotalFailures: make(map[FailoverReason]int64),
 		}
 		ct.entries[provider] = entry
 	}
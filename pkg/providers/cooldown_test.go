@@ -267,3 +267,27 @@ func TestCooldown_MultipleProviders(t *testing.T) {
 		t.Error("groq should be available")
 	}
 }
+
+func TestCooldown_SnapshotKeepsTotalsAfterSuccess(t *testing.T) {
+	now := time.Now()
+	ct, _ := newTestTracker(now)
+
+	ct.MarkFailure("openai", FailoverRateLimit)
+	ct.MarkFailure("openai", FailoverRateLimit)
+	ct.MarkFailure("anthropic", FailoverBilling)
+	ct.MarkSuccess("openai")
+
+	snap := ct.Snapshot()
+	if len(snap) != 2 || snap[0].Provider != "anthropic" || snap[1].Provider != "openai" {
+		t.Fatalf("Snapshot = %+v, want anthropic and openai in order", snap)
+	}
+	if snap[0].Available || snap[0].CooldownRemaining <= 0 {
+		t.Errorf("anthropic should be in cooldown: %+v", snap[0])
+	}
+	if !snap[1].Available || snap[1].TotalFailures[FailoverRateLimit] != 2 {
+		t.Errorf("openai = %+v, want available with 2 rate limit failures", snap[1])
+	}
+	if ct.FailureCount("openai", FailoverRateLimit) != 0 {
+		t.Error("MarkSuccess should still reset FailureCount")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	anthropicmessages "github.com/sipeed/picoclaw/pkg/providers/anthropic_messages"
 	"github.com/sipeed/picoclaw/pkg/providers/azure"
 	"github.com/sipeed/picoclaw/pkg/providers/bedrock"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
 )
 
 type protocolMeta struct {
@@ -223,14 +224,21 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
-		return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
+		provider := NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
 			cfg.APIKey(),
 			apiBase,
 			cfg.Proxy,
 			cfg.MaxTokensField,
 			cfg.RequestTimeout,
 			cfg.ExtraBody,
-		), modelID, nil
+		)
+		switch protocol {
+		case "ollama":
+			provider.withEmbeddingAPI(openai_compat.EmbeddingAPIOllama)
+		case "gemini":
+			provider.withEmbeddingAPI(openai_compat.EmbeddingAPIGemini)
+		}
+		return provider, modelID, nil
 
 	case "minimax":
 		// Minimax requires reasoning_split: true in the request body
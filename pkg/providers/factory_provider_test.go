@@ -920,3 +920,44 @@ func TestCreateProviderFromConfig_BedrockWithEndpointURL(t *testing.T) {
 	// Unexpected error - fail the test
 	t.Errorf("unexpected error from bedrock provider: %v", err)
 }
+
+func TestCreateProviderFromConfig_EmbeddingAPIs(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/api/embed"):
+			w.Write([]byte(`{"embeddings":[[1,0]]}`))
+		case strings.HasSuffix(r.URL.Path, ":batchEmbedContents"):
+			w.Write([]byte(`{"embeddings":[{"values":[1,0]}]}`))
+		default:
+			w.Write([]byte(`{"data":[{"index":0,"embedding":[1,0]}]}`))
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		model, apiBase, wantPath string
+	}{
+		{"openai/text-embedding-3-small", server.URL + "/v1", "/v1/embeddings"},
+		{"ollama/nomic-embed-text", server.URL + "/v1", "/api/embed"},
+		{"gemini/text-embedding-004", server.URL + "/v1beta", "/v1beta/models/text-embedding-004:batchEmbedContents"},
+	} {
+		cfg := &config.ModelConfig{ModelName: "embed", Model: tt.model, APIBase: tt.apiBase}
+		cfg.SetAPIKey("key")
+		provider, modelID, err := CreateProviderFromConfig(cfg)
+		if err != nil {
+			t.Fatalf("%s: CreateProviderFromConfig() error = %v", tt.model, err)
+		}
+		embedder, ok := provider.(EmbeddingProvider)
+		if !ok {
+			t.Fatalf("%s: %T does not implement EmbeddingProvider", tt.model, provider)
+		}
+		if _, err := embedder.Embed(t.Context(), []string{"hi"}, modelID); err != nil {
+			t.Fatalf("%s: Embed() error = %v", tt.model, err)
+		}
+		if got := paths[len(paths)-1]; got != tt.wantPath {
+			t.Fatalf("%s: request path = %q, want %q", tt.model, got, tt.wantPath)
+		}
+	}
+}
@@ -41,6 +41,11 @@ func NewFallbackChain(cooldown *CooldownTracker) *FallbackChain {
 	return &FallbackChain{cooldown: cooldown}
 }
 
+// Cooldowns returns the tracker that records provider failures.
+func (fc *FallbackChain) Cooldowns() *CooldownTracker {
+	return fc.cooldown
+}
+
 // ResolveCandidates parses model config into a deduplicated candidate list.
 func ResolveCandidates(cfg ModelConfig, defaultProvider string) []FallbackCandidate {
 	return ResolveCandidatesWithLookup(cfg, defaultProvider, nil)
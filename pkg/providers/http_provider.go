@@ -67,6 +67,16 @@ func (p *HTTPProvider) ChatStream(
 	return p.delegate.ChatStream(ctx, messages, tools, model, options, onChunk)
 }
 
+// Embed implements providers.EmbeddingProvider.
+func (p *HTTPProvider) Embed(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	return p.delegate.Embed(ctx, texts, model)
+}
+
+// withEmbeddingAPI selects the embedding endpoint of the server behind p.
+func (p *HTTPProvider) withEmbeddingAPI(api openai_compat.EmbeddingAPI) {
+	openai_compat.WithEmbeddingAPI(api)(p.delegate)
+}
+
 func (p *HTTPProvider) GetDefaultModel() string {
 	return ""
 }
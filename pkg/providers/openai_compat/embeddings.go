@@ -0,0 +1,194 @@
+package openai_compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers/common"
+)
+
+// EmbeddingAPI selects the endpoint Embed calls. Ollama and Gemini serve
+// chat over the OpenAI protocol, but their native embedding endpoints are
+// the ones every model supports.
+type EmbeddingAPI string
+
+const (
+	EmbeddingAPIOpenAI EmbeddingAPI = "openai" // POST {api_base}/embeddings
+	EmbeddingAPIOllama EmbeddingAPI = "ollama" // POST {host}/api/embed
+	EmbeddingAPIGemini EmbeddingAPI = "gemini" // POST {api_base}/models/{model}:batchEmbedContents
+)
+
+// maxEmbeddingBatch is the most texts sent in one embedding request. Gemini
+// rejects batches of more than 100, and OpenAI-compatible servers set
+// similar limits.
+const maxEmbeddingBatch = 100
+
+func WithEmbeddingAPI(api EmbeddingAPI) Option {
+	return func(p *Provider) {
+		p.embeddingAPI = api
+	}
+}
+
+// Embed returns one vector per text, in order. Texts are sent in batches of
+// at most maxEmbeddingBatch.
+func (p *Provider) Embed(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	if p.apiBase == "" {
+		return nil, fmt.Errorf("API base not configured")
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for batch := range slices.Chunk(texts, maxEmbeddingBatch) {
+		batchVectors, err := p.embedBatch(ctx, batch, model)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batchVectors...)
+	}
+	return vectors, nil
+}
+
+func (p *Provider) embedBatch(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	var vectors [][]float32
+	var err error
+	switch p.embeddingAPI {
+	case EmbeddingAPIOllama:
+		vectors, err = p.embedOllama(ctx, texts, model)
+	case EmbeddingAPIGemini:
+		vectors, err = p.embedGemini(ctx, texts, model)
+	default:
+		vectors, err = p.embedOpenAI(ctx, texts, model)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedding API returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+func (p *Provider) embedOpenAI(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	body := map[string]any{
+		"model":           normalizeModel(model, p.apiBase),
+		"input":           texts,
+		"encoding_format": "float",
+	}
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	if err := p.postJSON(ctx, p.apiBase+"/embeddings", headers, body, &out); err != nil {
+		return nil, err
+	}
+
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding API returned %d vectors for %d texts", len(out.Data), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding API returned index %d for %d texts", d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+func (p *Provider) embedOllama(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	var out struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	// api_base points at the OpenAI-compatible API under /v1; the native
+	// API is on the same host.
+	endpoint := strings.TrimSuffix(p.apiBase, "/v1") + "/api/embed"
+	body := map[string]any{
+		"model": normalizeModel(model, p.apiBase),
+		"input": texts,
+	}
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	if err := p.postJSON(ctx, endpoint, headers, body, &out); err != nil {
+		return nil, err
+	}
+	return out.Embeddings, nil
+}
+
+func (p *Provider) embedGemini(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	var out struct {
+		Embeddings []struct {
+			Values []float32 `json:"values"`
+		} `json:"embeddings"`
+	}
+	// The OpenAI-compatible API lives under .../v1beta/openai; the native
+	// API is its parent.
+	base := strings.TrimSuffix(p.apiBase, "/openai")
+	name := strings.TrimPrefix(model, "models/")
+	model = "models/" + name
+	requests := make([]map[string]any, len(texts))
+	for i, text := range texts {
+		requests[i] = map[string]any{
+			"model":   model,
+			"content": map[string]any{"parts": []map[string]string{{"text": text}}},
+		}
+	}
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["x-goog-api-key"] = p.apiKey
+	}
+	endpoint := base + "/models/" + url.PathEscape(name) + ":batchEmbedContents"
+	if err := p.postJSON(ctx, endpoint, headers, map[string]any{"requests": requests}, &out); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(out.Embeddings))
+	for i, e := range out.Embeddings {
+		vectors[i] = e.Values
+	}
+	return vectors, nil
+}
+
+func (p *Provider) postJSON(ctx context.Context, endpoint string, headers map[string]string, body, out any) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return common.HandleErrorResponse(resp, p.apiBase)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return nil
+}
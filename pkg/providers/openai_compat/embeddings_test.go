@@ -0,0 +1,152 @@
+package openai_compat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestProviderEmbed_OpenAI(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("request %s with auth %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		// Out of order on purpose: vectors are matched to texts by index.
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL+"/v1", "")
+	got, err := p.Embed(context.Background(), []string{"a", "b"}, "text-embedding-3-small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0][0] != 1 || got[1][1] != 1 {
+		t.Fatalf("Embed() = %v", got)
+	}
+	if body["model"] != "text-embedding-3-small" || len(body["input"].([]any)) != 2 {
+		t.Fatalf("request body = %v", body)
+	}
+}
+
+func TestProviderEmbed_Ollama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/api/embed" || body.Model != "nomic-embed-text" {
+			t.Errorf("request %s for model %q", r.URL.Path, body.Model)
+		}
+		w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.5,0.5]]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", "", WithEmbeddingAPI(EmbeddingAPIOllama))
+	got, err := p.Embed(context.Background(), []string{"hello"}, "nomic-embed-text")
+	if err != nil || len(got) != 1 || len(got[0]) != 2 {
+		t.Fatalf("Embed() = %v, %v", got, err)
+	}
+}
+
+func TestProviderEmbed_Gemini(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Requests []struct {
+				Model string `json:"model"`
+			} `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1beta/models/text-embedding-004:batchEmbedContents" ||
+			r.Header.Get("x-goog-api-key") != "gkey" || len(body.Requests) != 2 ||
+			body.Requests[0].Model != "models/text-embedding-004" {
+			t.Errorf("request %s with key %q and body %+v", r.URL.Path, r.Header.Get("x-goog-api-key"), body)
+		}
+		w.Write([]byte(`{"embeddings":[{"values":[1,2,3]},{"values":[4,5,6]}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("gkey", server.URL+"/v1beta/openai", "", WithEmbeddingAPI(EmbeddingAPIGemini))
+	got, err := p.Embed(context.Background(), []string{"a", "b"}, "text-embedding-004")
+	if err != nil || len(got) != 2 || got[1][2] != 6 {
+		t.Fatalf("Embed() = %v, %v", got, err)
+	}
+}
+
+func TestProviderEmbed_SplitsBatches(t *testing.T) {
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Requests []struct {
+				Content struct {
+					Parts []struct {
+						Text string `json:"text"`
+					} `json:"parts"`
+				} `json:"content"`
+			} `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sizes = append(sizes, len(body.Requests))
+		if len(body.Requests) > maxEmbeddingBatch {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Each vector holds the number of its text, so the order can be
+		// checked.
+		values := make([]string, len(body.Requests))
+		for i, req := range body.Requests {
+			values[i] = `{"values":[` + req.Content.Parts[0].Text + `]}`
+		}
+		w.Write([]byte(`{"embeddings":[` + strings.Join(values, ",") + `]}`))
+	}))
+	defer server.Close()
+
+	texts := make([]string, 250)
+	for i := range texts {
+		texts[i] = strconv.Itoa(i)
+	}
+	p := NewProvider("gkey", server.URL+"/v1beta/openai", "", WithEmbeddingAPI(EmbeddingAPIGemini))
+	got, err := p.Embed(context.Background(), texts, "text-embedding-004")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 3 || sizes[0] != 100 || sizes[2] != 50 {
+		t.Fatalf("batch sizes = %v", sizes)
+	}
+	if len(got) != len(texts) {
+		t.Fatalf("Embed() returned %d vectors for %d texts", len(got), len(texts))
+	}
+	for i, v := range got {
+		if int(v[0]) != i {
+			t.Fatalf("vector %d = %v", i, v)
+		}
+	}
+}
+
+func TestProviderEmbed_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "embeddings") {
+			w.Write([]byte(`{"data":[{"index":0,"embedding":[1]}]}`))
+			return
+		}
+		http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "")
+	if _, err := p.Embed(context.Background(), []string{"a", "b"}, "m"); err == nil {
+		t.Fatal("Embed() accepted one vector for two texts")
+	}
+	ollama := NewProvider("", server.URL, "", WithEmbeddingAPI(EmbeddingAPIOllama))
+	if _, err := ollama.Embed(context.Background(), []string{"a"}, "m"); err == nil ||
+		!strings.Contains(err.Error(), "404") {
+		t.Fatalf("Embed() error = %v, want the 404", err)
+	}
+}
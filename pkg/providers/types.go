@@ -67,6 +67,14 @@ type NativeSearchCapable interface {
 	SupportsNativeSearch() bool
 }
 
+// EmbeddingProvider is an optional interface for providers whose API turns
+// texts into embedding vectors, returned one per text and in order. The
+// OpenAI-compatible providers made by CreateProviderFromConfig implement it,
+// using the native endpoints of Ollama and Gemini.
+type EmbeddingProvider interface {
+	Embed(ctx context.Context, texts []string, model string) ([][]float32, error)
+}
+
 // FailoverReason classifies why an LLM request failed for fallback decisions.
 type FailoverReason string
 
@@ -14,6 +14,11 @@ type RouteInput struct {
 	ParentPeer *RoutePeer
 	GuildID    string
 	TeamID     string
+	ThreadID   string
+	// IdentityLinks are identities linked with /link. Unlike
+	// session.identity_links, they share one DM session across channels
+	// whatever the dm_scope.
+	IdentityLinks map[string][]string
 }
 
 // ResolvedRoute is the result of agent routing.
@@ -26,6 +31,23 @@ type ResolvedRoute struct {
 	MatchedBy      string // "binding.peer", "binding.peer.parent", "binding.guild", "binding.team", "binding.account", "binding.channel", "default"
 }
 
+// WithAgent returns the route rebound to agentID, keeping the peer scope of
+// its session key. It is used to hand an ongoing conversation to another
+// agent without changing how the chat maps to a session.
+func (r ResolvedRoute) WithAgent(agentID string, matchedBy string) ResolvedRoute {
+	agentID = NormalizeAgentID(agentID)
+	rebound := r
+	rebound.AgentID = agentID
+	rebound.MainSessionKey = strings.ToLower(BuildAgentMainSessionKey(agentID))
+	rebound.MatchedBy = matchedBy
+	if parsed := ParseAgentSessionKey(r.SessionKey); parsed != nil {
+		rebound.SessionKey = strings.ToLower("agent:" + agentID + ":" + parsed.Rest)
+	} else {
+		rebound.SessionKey = rebound.MainSessionKey
+	}
+	return rebound
+}
+
 // RouteResolver determines which agent handles a message based on config bindings.
 type RouteResolver struct {
 	cfg *config.Config
@@ -49,6 +71,18 @@ func (r *RouteResolver) ResolveRoute(input RouteInput) ResolvedRoute {
 		dmScope = DMScopeMain
 	}
 	identityLinks := r.cfg.Session.IdentityLinks
+	threadScope := ThreadScope(r.cfg.Session.ThreadScope)
+	if threadScope == "" {
+		threadScope = ThreadScopeShared
+	}
+
+	if peer != nil && strings.EqualFold(peer.Kind, "direct") && len(input.IdentityLinks) > 0 &&
+		resolveLinkedPeerID(input.IdentityLinks, channel, strings.TrimSpace(peer.ID)) != "" {
+		identityLinks = input.IdentityLinks
+		if dmScope != DMScopeMain {
+			dmScope = DMScopePerPeer
+		}
+	}
 
 	bindings := r.filterBindings(channel, accountID)
 
@@ -61,6 +95,8 @@ func (r *RouteResolver) ResolveRoute(input RouteInput) ResolvedRoute {
 			Peer:          peer,
 			DMScope:       dmScope,
 			IdentityLinks: identityLinks,
+			ThreadID:      input.ThreadID,
+			ThreadScope:   threadScope,
 		}))
 		mainSessionKey := strings.ToLower(BuildAgentMainSessionKey(resolvedAgentID))
 		return ResolvedRoute{
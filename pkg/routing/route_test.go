@@ -295,3 +295,60 @@ func TestResolveRoute_NoDefaultUsesFirst(t *testing.T) {
 		t.Errorf("AgentID = %q, want 'alpha' (first in list)", route.AgentID)
 	}
 }
+
+func TestResolvedRoute_WithAgent(t *testing.T) {
+	cfg := testConfig([]config.AgentConfig{{ID: "front", Default: true}, {ID: "billing"}}, nil)
+	r := NewRouteResolver(cfg)
+
+	route := r.ResolveRoute(RouteInput{
+		Channel: "telegram",
+		Peer:    &RoutePeer{Kind: "direct", ID: "User1"},
+	})
+	rebound := route.WithAgent("Billing", "handoff")
+
+	if rebound.AgentID != "billing" {
+		t.Errorf("AgentID = %q, want billing", rebound.AgentID)
+	}
+	if rebound.SessionKey != "agent:billing:direct:user1" {
+		t.Errorf("SessionKey = %q, want agent:billing:direct:user1", rebound.SessionKey)
+	}
+	if rebound.MainSessionKey != "agent:billing:main" {
+		t.Errorf("MainSessionKey = %q", rebound.MainSessionKey)
+	}
+	if rebound.MatchedBy != "handoff" || rebound.Channel != route.Channel {
+		t.Errorf("rebound = %+v", rebound)
+	}
+	if route.AgentID != "front" {
+		t.Errorf("original route changed: %+v", route)
+	}
+}
+
+func TestResolveRoute_LinkedIdentitiesShareDMSession(t *testing.T) {
+	cfg := testConfig(nil, nil)
+	cfg.Session.DMScope = "per-channel-peer"
+	r := NewRouteResolver(cfg)
+	links := map[string][]string{"telegram:123": {"discord:456", "telegram:123"}}
+
+	tg := r.ResolveRoute(RouteInput{
+		Channel:       "telegram",
+		Peer:          &RoutePeer{Kind: "direct", ID: "123"},
+		IdentityLinks: links,
+	})
+	dc := r.ResolveRoute(RouteInput{
+		Channel:       "discord",
+		Peer:          &RoutePeer{Kind: "direct", ID: "456"},
+		IdentityLinks: links,
+	})
+	if tg.SessionKey != dc.SessionKey || tg.SessionKey != "agent:main:direct:telegram:123" {
+		t.Errorf("SessionKey = %q / %q, want both agent:main:direct:telegram:123", tg.SessionKey, dc.SessionKey)
+	}
+
+	other := r.ResolveRoute(RouteInput{
+		Channel:       "discord",
+		Peer:          &RoutePeer{Kind: "direct", ID: "789"},
+		IdentityLinks: links,
+	})
+	if other.SessionKey != "agent:main:discord:direct:789" {
+		t.Errorf("unlinked SessionKey = %q", other.SessionKey)
+	}
+}
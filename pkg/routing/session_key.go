@@ -15,6 +15,15 @@ const (
 	DMScopePerAccountChannelPeer DMScope = "per-account-channel-peer"
 )
 
+// ThreadScope controls whether threads of a group or channel get their own
+// session.
+type ThreadScope string
+
+const (
+	ThreadScopePerThread ThreadScope = "per-thread"
+	ThreadScopeShared    ThreadScope = "shared"
+)
+
 // RoutePeer represents a chat peer with kind and ID.
 type RoutePeer struct {
 	Kind string // "direct", "group", "channel"
@@ -29,6 +38,11 @@ type SessionKeyParams struct {
 	Peer          *RoutePeer
 	DMScope       DMScope
 	IdentityLinks map[string][]string
+	// ThreadID is the thread or forum topic the message was posted in.
+	ThreadID string
+	// ThreadScope is ThreadScopePerThread to give threads their own
+	// session; anything else shares the session of the parent.
+	ThreadScope ThreadScope
 }
 
 // ParsedSessionKey is the result of parsing an agent-scoped session key.
@@ -90,12 +104,19 @@ func BuildAgentPeerSessionKey(params SessionKeyParams) string {
 		return BuildAgentMainSessionKey(agentID)
 	}
 
-	// Group/channel peers always get per-peer sessions
+	// Group/channel peers always get per-peer sessions, and per-thread ones
+	// when configured. Peers that already name the thread, such as a
+	// Discord thread or a Telegram forum topic, keep their key.
 	channel := normalizeChannel(params.Channel)
 	peerID := strings.ToLower(strings.TrimSpace(peer.ID))
 	if peerID == "" {
 		peerID = "unknown"
 	}
+	threadID := strings.ToLower(strings.TrimSpace(params.ThreadID))
+	if threadID != "" && params.ThreadScope == ThreadScopePerThread &&
+		peerID != threadID && !strings.HasSuffix(peerID, "/"+threadID) {
+		peerID += "/" + threadID
+	}
 	return fmt.Sprintf("agent:%s:%s:%s:%s", agentID, channel, peerKind, peerID)
 }
 
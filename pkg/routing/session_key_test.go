@@ -1,6 +1,9 @@
 package routing
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestBuildAgentMainSessionKey(t *testing.T) {
 	got := BuildAgentMainSessionKey("sales")
@@ -84,6 +87,39 @@ func TestBuildAgentPeerSessionKey_GroupPeer(t *testing.T) {
 	}
 }
 
+func TestBuildAgentPeerSessionKey_Thread(t *testing.T) {
+	params := SessionKeyParams{
+		AgentID:  "main",
+		Channel:  "slack",
+		Peer:     &RoutePeer{Kind: "channel", ID: "C123"},
+		ThreadID: "1700000000.000100",
+	}
+	got := BuildAgentPeerSessionKey(params)
+	want := "agent:main:slack:channel:c123"
+	if got != want {
+		t.Errorf("default = %q, want %q", got, want)
+	}
+
+	params.ThreadScope = ThreadScopePerThread
+	got = BuildAgentPeerSessionKey(params)
+	want = "agent:main:slack:channel:c123/1700000000.000100"
+	if got != want {
+		t.Errorf("per-thread = %q, want %q", got, want)
+	}
+
+	// Discord threads and Telegram topics are peers of their own and keep
+	// the keys they had before threads were reported.
+	for peerID, threadID := range map[string]string{"T456": "T456", "-100123/42": "42"} {
+		params.Peer = &RoutePeer{Kind: "channel", ID: peerID}
+		params.ThreadID = threadID
+		got = BuildAgentPeerSessionKey(params)
+		want = "agent:main:slack:channel:" + strings.ToLower(peerID)
+		if got != want {
+			t.Errorf("thread peer %s = %q, want %q", peerID, got, want)
+		}
+	}
+}
+
 func TestBuildAgentPeerSessionKey_NilPeer(t *testing.T) {
 	got := BuildAgentPeerSessionKey(SessionKeyParams{
 		AgentID: "main",
@@ -0,0 +1,135 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// TranscriptVersion is the version of the transcript format written by
+// NewTranscript.
+const TranscriptVersion = 1
+
+// Transcript is a portable copy of a session, written by /export and read
+// back by /import.
+type Transcript struct {
+	Version    int                 `json:"version"`
+	SessionKey string              `json:"session_key,omitempty"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Summary    string              `json:"summary,omitempty"`
+	Messages   []providers.Message `json:"messages"`
+}
+
+// NewTranscript copies a session's summary and history into a transcript.
+// Media references point into the local media store and are not portable,
+// so they are left out.
+func NewTranscript(sessionKey, summary string, history []providers.Message) Transcript {
+	messages := make([]providers.Message, len(history))
+	for i, msg := range history {
+		msg.Media = nil
+		messages[i] = msg
+	}
+	return Transcript{
+		Version:    TranscriptVersion,
+		SessionKey: sessionKey,
+		ExportedAt: time.Now().UTC(),
+		Summary:    summary,
+		Messages:   messages,
+	}
+}
+
+// JSON encodes the transcript for /import.
+func (t Transcript) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// Markdown renders the transcript for reading. It cannot be imported.
+func (t Transcript) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# Conversation\n\n")
+	if t.SessionKey != "" {
+		fmt.Fprintf(&sb, "- Session: `%s`\n", t.SessionKey)
+	}
+	fmt.Fprintf(&sb, "- Exported: %s\n", t.ExportedAt.Format(time.RFC3339))
+	if t.Summary != "" {
+		fmt.Fprintf(&sb, "\n## Summary of earlier messages\n\n%s\n", t.Summary)
+	}
+
+	for _, msg := range t.Messages {
+		switch msg.Role {
+		case "user":
+			fmt.Fprintf(&sb, "\n## User\n\n%s\n", msg.Content)
+		case "assistant":
+			sb.WriteString("\n## Assistant\n")
+			if msg.Content != "" {
+				fmt.Fprintf(&sb, "\n%s\n", msg.Content)
+			}
+			for _, tc := range msg.ToolCalls {
+				name, args := toolCallNameArgs(tc)
+				fmt.Fprintf(&sb, "\n**Tool call** `%s`\n\n```json\n%s\n```\n", name, args)
+			}
+		case "tool":
+			fmt.Fprintf(&sb, "\n**Tool result**\n\n```\n%s\n```\n", msg.Content)
+		default:
+			fmt.Fprintf(&sb, "\n## %s\n\n%s\n", msg.Role, msg.Content)
+		}
+	}
+	return sb.String()
+}
+
+func toolCallNameArgs(tc providers.ToolCall) (string, string) {
+	name, args := tc.Name, ""
+	if tc.Function != nil {
+		if name == "" {
+			name = tc.Function.Name
+		}
+		args = tc.Function.Arguments
+	}
+	if args == "" && tc.Arguments != nil {
+		if data, err := json.Marshal(tc.Arguments); err == nil {
+			args = string(data)
+		}
+	}
+	return name, args
+}
+
+// ParseTranscript decodes a JSON transcript and checks that its history can
+// be replayed: user, assistant and tool messages only, and every tool result
+// answers a tool call of an earlier assistant message. System messages are
+// rejected so that an imported file cannot add instructions to the prompt.
+func ParseTranscript(data []byte) (Transcript, error) {
+	var t Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Transcript{}, fmt.Errorf("not a JSON transcript: %w", err)
+	}
+	if t.Version < 1 || t.Version > TranscriptVersion {
+		return Transcript{}, fmt.Errorf("unsupported transcript version %d", t.Version)
+	}
+	if len(t.Messages) == 0 && t.Summary == "" {
+		return Transcript{}, errors.New("transcript is empty")
+	}
+
+	callIDs := make(map[string]bool)
+	for i, msg := range t.Messages {
+		switch msg.Role {
+		case "user":
+		case "assistant":
+			for _, tc := range msg.ToolCalls {
+				callIDs[tc.ID] = true
+			}
+		case "tool":
+			if !callIDs[msg.ToolCallID] {
+				return Transcript{}, fmt.Errorf("message %d: tool result without a matching tool call", i+1)
+			}
+		case "system":
+			return Transcript{}, fmt.Errorf("message %d: system messages cannot be imported", i+1)
+		default:
+			return Transcript{}, fmt.Errorf("message %d: unknown role %q", i+1, msg.Role)
+		}
+	}
+	return t, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -32,11 +33,20 @@ type GitHubRef struct {
 	SubPath  string // Path within the repository
 }
 
+const (
+	githubAPIBaseURL = "https://api.github.com"
+	githubRawBaseURL = "https://raw.githubusercontent.com"
+)
+
 type SkillInstaller struct {
 	workspace   string
 	client      *http.Client
 	githubToken string
 	proxy       string
+	apiBaseURL  string
+	rawBaseURL  string
+	policy      SecurityPolicy
+	review      func(*SecurityReport) bool
 }
 
 // NewSkillInstaller creates a new skill installer.
@@ -52,9 +62,20 @@ func NewSkillInstaller(workspace, githubToken, proxy string) (*SkillInstaller, e
 		client:      client,
 		githubToken: githubToken,
 		proxy:       proxy,
+		apiBaseURL:  githubAPIBaseURL,
+		rawBaseURL:  githubRawBaseURL,
 	}, nil
 }
 
+// SetSecurity sets the policy downloaded skills are vetted against before
+// they are installed. review is shown the report of every skill that is not
+// blocked and returns whether to install it; without it, flagged skills are
+// rejected.
+func (si *SkillInstaller) SetSecurity(policy SecurityPolicy, review func(*SecurityReport) bool) {
+	si.policy = policy
+	si.review = review
+}
+
 // parseGitHubRef parses a GitHub reference.
 // Supports: "owner/repo", "owner/repo/path", or full URL like "https://github.com/owner/repo/tree/ref/path"
 func parseGitHubRef(repo string) (GitHubRef, error) {
@@ -104,38 +125,123 @@ func parseGitHubRef(repo string) (GitHubRef, error) {
 	return ref, nil
 }
 
+// skillName is the directory a skill from this reference is installed to.
+func (ref GitHubRef) skillName() string {
+	if ref.SubPath != "" {
+		return path.Base(ref.SubPath)
+	}
+	return ref.RepoName
+}
+
+// repo returns the reference without its git ref, as "owner/repo[/path]".
+func (ref GitHubRef) repo() string {
+	return path.Join(ref.Owner, ref.RepoName, ref.SubPath)
+}
+
 func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) error {
 	ref, err := parseGitHubRef(repo)
 	if err != nil {
 		return err
 	}
 
-	skillName := ref.RepoName
-	if ref.SubPath != "" {
-		skillName = filepath.Base(ref.SubPath)
-	}
+	skillName := ref.skillName()
 	skillDirectory := filepath.Join(si.workspace, "skills", skillName)
 
 	if _, err := os.Stat(skillDirectory); err == nil {
 		return fmt.Errorf("skill '%s' already exists", skillName)
 	}
 
+	// Download next to the skills, where the loader does not look, and
+	// move the skill into place once it is vetted.
+	skillsDir := filepath.Dir(skillDirectory)
+	if err := os.MkdirAll(skillsDir, 0o755); err != nil {
+		return err
+	}
+	stageRoot, err := os.MkdirTemp(skillsDir, "."+skillName+"-install-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageRoot)
+	staged := filepath.Join(stageRoot, skillName)
+
+	commit, err := si.downloadGitHub(ctx, ref, staged)
+	if err != nil {
+		return err
+	}
+	if err := si.Vet(staged, skillName); err != nil {
+		return err
+	}
+	if err := os.Rename(staged, skillDirectory); err != nil {
+		return fmt.Errorf("failed to activate skill: %w", err)
+	}
+
+	if err := RecordInstall(si.workspace, skillName, LockEntry{
+		Source: SourceGitHub,
+		Repo:   ref.repo(),
+		Ref:    ref.Ref,
+		Commit: commit,
+	}); err != nil {
+		return fmt.Errorf("skill installed but not recorded in %s: %w", LockFileName, err)
+	}
+	return nil
+}
+
+// downloadGitHub downloads the skill at ref into dir and returns the commit
+// ref resolved to, or "" when it could not be resolved.
+func (si *SkillInstaller) downloadGitHub(ctx context.Context, ref GitHubRef, dir string) (string, error) {
+	commit, _ := si.resolveCommit(ctx, ref)
+	if commit != "" {
+		ref.Ref = commit
+	}
+
 	// Build GitHub API URL
 	apiPath := path.Join(ref.Owner, ref.RepoName, "contents")
 	if ref.SubPath != "" {
 		apiPath = path.Join(apiPath, ref.SubPath)
 	}
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s?ref=%s", apiPath, ref.Ref)
+	apiURL := fmt.Sprintf("%s/repos/%s?ref=%s", si.apiBaseURL, apiPath, ref.Ref)
 
-	if err := si.getGithubDirAllFiles(ctx, apiURL, skillDirectory, true); err != nil {
+	if err := si.getGithubDirAllFiles(ctx, apiURL, dir, true); err != nil {
 		// Fallback to raw download
-		return si.downloadRaw(ctx, ref.Owner, ref.RepoName, ref.Ref, ref.SubPath, skillDirectory)
+		return commit, si.downloadRaw(ctx, ref.Owner, ref.RepoName, ref.Ref, ref.SubPath, dir)
 	}
 
-	if _, err := os.Stat(filepath.Join(skillDirectory, "SKILL.md")); err != nil {
-		return fmt.Errorf("SKILL.md not found in repository")
+	if _, err := os.Stat(filepath.Join(dir, "SKILL.md")); err != nil {
+		return "", fmt.Errorf("SKILL.md not found in repository")
 	}
-	return nil
+	return commit, nil
+}
+
+// resolveCommit returns the commit SHA ref.Ref currently points to.
+func (si *SkillInstaller) resolveCommit(ctx context.Context, ref GitHubRef) (string, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s",
+		si.apiBaseURL, ref.Owner, ref.RepoName, url.PathEscape(ref.Ref))
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if si.githubToken != "" {
+		req.Header.Set("Authorization", "Bearer "+si.githubToken)
+	}
+
+	resp, err := si.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 128))
+	if err != nil {
+		return "", err
+	}
+	sha := strings.TrimSpace(string(body))
+	if len(sha) != 40 {
+		return "", fmt.Errorf("unexpected commit %q", sha)
+	}
+	return sha, nil
 }
 
 // downloadDir recursively downloads a directory from GitHub API
@@ -193,7 +299,7 @@ func (si *SkillInstaller) downloadRaw(ctx context.Context, owner, repo, ref, sub
 	if subPath != "" {
 		urlPath = path.Join(urlPath, subPath)
 	}
-	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/SKILL.md", urlPath)
+	url := fmt.Sprintf("%s/%s/SKILL.md", si.rawBaseURL, urlPath)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -250,7 +356,7 @@ func (si *SkillInstaller) downloadFile(ctx context.Context, url, localPath strin
 // root: true if we're at the skill root directory
 func shouldDownload(name string, root bool) bool {
 	if root {
-		return name == "SKILL.md"
+		return name == "SKILL.md" || name == SignatureFileName
 	}
 	return true
 }
@@ -287,5 +393,5 @@ func (si *SkillInstaller) Uninstall(skillName string) error {
 		return fmt.Errorf("failed to remove skill '%s': %w", finalSkillName, err)
 	}
 
-	return nil
+	return ForgetInstall(si.workspace, finalSkillName)
 }
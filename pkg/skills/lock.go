@@ -0,0 +1,200 @@
+package skills
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	// LockFileName is the lock file in the workspace root. It records where
+	// every installed skill came from so the skills can be updated and the
+	// workspace reproduced on another machine.
+	LockFileName = "skills.lock"
+
+	// SourceGitHub marks skills installed from a GitHub repository. Other
+	// sources are registry names such as "clawhub".
+	SourceGitHub = "github"
+
+	lockFileVersion = 1
+
+	// originMetaFile is written next to SKILL.md by the install_skill tool
+	// and the web UI. It changes on every install, so it is not hashed.
+	originMetaFile = ".skill-origin.json"
+)
+
+// LockEntry records the origin and content of one installed skill.
+type LockEntry struct {
+	Source string `json:"source"`
+	// Repo and Ref locate GitHub skills; Commit is the commit Ref pointed to
+	// at install time, when it could be resolved.
+	Repo   string `json:"repo,omitempty"`
+	Ref    string `json:"ref,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	// Slug and Version locate registry skills.
+	Slug    string `json:"slug,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Hash is the content hash of the skill directory as installed. A
+	// different hash on disk means the skill was edited locally.
+	Hash        string    `json:"hash"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Revision returns the version, commit or ref the entry is pinned to.
+func (e LockEntry) Revision() string {
+	switch {
+	case e.Version != "":
+		return e.Version
+	case len(e.Commit) > 12:
+		return e.Commit[:12]
+	case e.Commit != "":
+		return e.Commit
+	}
+	return e.Ref
+}
+
+// Lock is the content of skills.lock, keyed by skill directory name.
+type Lock struct {
+	Version int                  `json:"version"`
+	Skills  map[string]LockEntry `json:"skills"`
+}
+
+// lockMu serializes read-modify-write cycles of lock files in this process.
+var lockMu sync.Mutex
+
+// LockPath returns the path of the lock file of workspace.
+func LockPath(workspace string) string {
+	return filepath.Join(workspace, LockFileName)
+}
+
+// LoadLock reads the lock file of workspace. A missing file is an empty lock.
+func LoadLock(workspace string) (*Lock, error) {
+	lock := &Lock{Version: lockFileVersion, Skills: map[string]LockEntry{}}
+	data, err := os.ReadFile(LockPath(workspace))
+	if errors.Is(err, fs.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", LockFileName, err)
+	}
+	if lock.Skills == nil {
+		lock.Skills = map[string]LockEntry{}
+	}
+	return lock, nil
+}
+
+// Save writes the lock file of workspace.
+func (l *Lock) Save(workspace string) error {
+	l.Version = lockFileVersion
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(LockPath(workspace), append(data, '\n'), 0o644)
+}
+
+// Names returns the locked skill names in order.
+func (l *Lock) Names() []string {
+	names := make([]string, 0, len(l.Skills))
+	for name := range l.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RecordInstall hashes the installed skill <workspace>/skills/<name> and
+// records it in the lock file.
+func RecordInstall(workspace, name string, entry LockEntry) error {
+	hash, err := HashDir(filepath.Join(workspace, "skills", name))
+	if err != nil {
+		return fmt.Errorf("hash skill %s: %w", name, err)
+	}
+	entry.Hash = hash
+	if entry.InstalledAt.IsZero() {
+		entry.InstalledAt = time.Now().UTC()
+	}
+	return updateLock(workspace, func(l *Lock) bool {
+		l.Skills[name] = entry
+		return true
+	})
+}
+
+// ForgetInstall removes a skill from the lock file.
+func ForgetInstall(workspace, name string) error {
+	return updateLock(workspace, func(l *Lock) bool {
+		if _, ok := l.Skills[name]; !ok {
+			return false
+		}
+		delete(l.Skills, name)
+		return true
+	})
+}
+
+// updateLock applies fn to the lock file and saves it if fn reports a change.
+func updateLock(workspace string, fn func(*Lock) bool) error {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	lock, err := LoadLock(workspace)
+	if err != nil {
+		return err
+	}
+	if !fn(lock) {
+		return nil
+	}
+	return lock.Save(workspace)
+}
+
+// HashDir returns the content hash of a skill directory: a SHA-256 over the
+// relative path, size and content of every regular file, in path order. The
+// origin metadata and the signature file, which signs this hash, are left out.
+func HashDir(dir string) (string, error) {
+	dir = filepath.Clean(dir)
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || (isUnhashedFile(d.Name()) && filepath.Dir(path) == dir) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), info.Size())
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func isUnhashedFile(name string) bool {
+	return name == originMetaFile || name == SignatureFileName
+}
@@ -0,0 +1,197 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedRegistry serves one skill whose SKILL.md depends on the version.
+type versionedRegistry struct {
+	latest    string
+	versions  map[string]string
+	downloads int
+}
+
+func (r *versionedRegistry) Name() string { return "hub" }
+
+func (r *versionedRegistry) Search(context.Context, string, int) ([]SearchResult, error) {
+	return nil, nil
+}
+
+func (r *versionedRegistry) GetSkillMeta(_ context.Context, slug string) (*SkillMeta, error) {
+	return &SkillMeta{Slug: slug, LatestVersion: r.latest}, nil
+}
+
+func (r *versionedRegistry) DownloadAndInstall(_ context.Context, _, version, targetDir string) (*InstallResult, error) {
+	r.downloads++
+	if version == "" {
+		version = r.latest
+	}
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		return nil, err
+	}
+	err := os.WriteFile(filepath.Join(targetDir, "SKILL.md"), []byte(r.versions[version]), 0o644)
+	return &InstallResult{Version: version}, err
+}
+
+func installFromRegistry(t *testing.T, workspace string, reg *versionedRegistry) {
+	t.Helper()
+	_, err := reg.DownloadAndInstall(context.Background(), "weather", "", filepath.Join(workspace, "skills", "weather"))
+	require.NoError(t, err)
+	require.NoError(t, RecordInstall(workspace, "weather", LockEntry{Source: "hub", Slug: "weather", Version: reg.latest}))
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "scripts"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("skill"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scripts", "run.sh"), []byte("echo"), 0o644))
+
+	first, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, "sha256:"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, originMetaFile), []byte("{}"), 0o644))
+	withMeta, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, first, withMeta, "origin metadata must not change the hash")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scripts", "run.sh"), []byte("echo hi"), 0o644))
+	edited, err := HashDir(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, edited)
+}
+
+func TestUpdateFromRegistry(t *testing.T) {
+	workspace := t.TempDir()
+	reg := &versionedRegistry{latest: "1.0.0", versions: map[string]string{"1.0.0": "v1", "1.1.0": "v2"}}
+	registries := NewRegistryManager()
+	registries.AddRegistry(reg)
+	installer, err := NewSkillInstaller(workspace, "", "")
+	require.NoError(t, err)
+	installFromRegistry(t, workspace, reg)
+	skillFile := filepath.Join(workspace, "skills", "weather", "SKILL.md")
+
+	results, err := installer.Update(context.Background(), registries, "", false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, UpdateUpToDate, results[0].Status)
+	assert.Equal(t, 1, reg.downloads, "an up-to-date registry skill must not be downloaded")
+
+	reg.latest = "1.1.0"
+	results, err = installer.Update(context.Background(), registries, "weather", false)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateResult{Name: "weather", Status: UpdateUpdated, From: "1.0.0", To: "1.1.0"}, results[0])
+	data, _ := os.ReadFile(skillFile)
+	assert.Equal(t, "v2", string(data))
+
+	lock, err := LoadLock(workspace)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", lock.Skills["weather"].Version)
+
+	entries, _ := os.ReadDir(filepath.Join(workspace, "skills"))
+	assert.Len(t, entries, 1, "staging directories must be removed")
+
+	// Local edits are kept unless forced.
+	reg.versions["1.2.0"], reg.latest = "v3", "1.2.0"
+	require.NoError(t, os.WriteFile(skillFile, []byte("my edits"), 0o644))
+	results, err = installer.Update(context.Background(), registries, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateModified, results[0].Status)
+	data, _ = os.ReadFile(skillFile)
+	assert.Equal(t, "my edits", string(data))
+
+	results, err = installer.Update(context.Background(), registries, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateUpdated, results[0].Status)
+	data, _ = os.ReadFile(skillFile)
+	assert.Equal(t, "v3", string(data))
+
+	_, err = installer.Update(context.Background(), registries, "unknown", false)
+	assert.Error(t, err)
+}
+
+func TestSyncRestoresLockedVersion(t *testing.T) {
+	workspace := t.TempDir()
+	reg := &versionedRegistry{latest: "1.0.0", versions: map[string]string{"1.0.0": "v1", "2.0.0": "v2"}}
+	registries := NewRegistryManager()
+	registries.AddRegistry(reg)
+	installer, err := NewSkillInstaller(workspace, "", "")
+	require.NoError(t, err)
+	installFromRegistry(t, workspace, reg)
+
+	// A fresh board with only the lock file gets the locked version back,
+	// not the latest one.
+	require.NoError(t, os.RemoveAll(filepath.Join(workspace, "skills", "weather")))
+	reg.latest = "2.0.0"
+	results, err := installer.Sync(context.Background(), registries, false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, UpdateInstalled, results[0].Status)
+	assert.False(t, results[0].HashMismatch)
+	data, _ := os.ReadFile(filepath.Join(workspace, "skills", "weather", "SKILL.md"))
+	assert.Equal(t, "v1", string(data))
+
+	results, err = installer.Sync(context.Background(), registries, false)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateUpToDate, results[0].Status)
+
+	require.NoError(t, installer.Uninstall("weather"))
+	lock, err := LoadLock(workspace)
+	require.NoError(t, err)
+	assert.Empty(t, lock.Skills)
+}
+
+func TestInstallFromGitHubPinsCommit(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	var server *httptest.Server
+	var contentsRef string
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/owner/skills/commits/main":
+			w.Write([]byte(sha))
+		case r.URL.Path == "/repos/owner/skills/contents/weather":
+			contentsRef = r.URL.Query().Get("ref")
+			json.NewEncoder(w).Encode([]GitHubContent{
+				{Name: "SKILL.md", Type: "file", DownloadURL: server.URL + "/raw/SKILL.md"},
+			})
+		case r.URL.Path == "/raw/SKILL.md":
+			w.Write([]byte("weather skill"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	workspace := t.TempDir()
+	installer, err := NewSkillInstaller(workspace, "", "")
+	require.NoError(t, err)
+	installer.apiBaseURL = server.URL
+
+	require.NoError(t, installer.InstallFromGitHub(context.Background(), "owner/skills/weather"))
+	assert.Equal(t, sha, contentsRef)
+
+	lock, err := LoadLock(workspace)
+	require.NoError(t, err)
+	entry := lock.Skills["weather"]
+	assert.Equal(t, SourceGitHub, entry.Source)
+	assert.Equal(t, "owner/skills/weather", entry.Repo)
+	assert.Equal(t, "main", entry.Ref)
+	assert.Equal(t, sha, entry.Commit)
+	assert.NotEmpty(t, entry.Hash)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(workspace, "skills", "weather")))
+	results, err := installer.Sync(context.Background(), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateInstalled, results[0].Status)
+	assert.False(t, results[0].HashMismatch)
+}
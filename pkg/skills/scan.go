@@ -0,0 +1,268 @@
+package skills
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Severity ranks scan findings.
+type Severity string
+
+const (
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+// Finding is one issue the static scan found in a skill.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+type lineRule struct {
+	rule     string
+	severity Severity
+	detail   string
+	patterns []*regexp.Regexp
+}
+
+var lineRules = []lineRule{
+	{
+		rule:     "prompt-injection",
+		severity: SeverityWarning,
+		detail:   "text that tries to override the agent's instructions",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+)?` +
+				`(previous|prior|above|earlier|system|original)\s+(instructions|prompts?|rules|messages)`),
+			regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(in\s+)?(dan|developer\s+mode|jailbroken|unrestricted|unfiltered)`),
+			regexp.MustCompile(`(?i)\b(do\s+not|don't|never)\s+(tell|inform|mention\s+(this\s+)?to|reveal\s+(this\s+)?to|ask)` +
+				`\s+the\s+user`),
+			regexp.MustCompile(`(?i)\b(reveal|print|show|output|repeat)\s+(your\s+|the\s+)?(full\s+)?` +
+				`(system\s+prompt|hidden\s+instructions)`),
+		},
+	},
+	{
+		rule:     "obfuscated-shell",
+		severity: SeverityCritical,
+		detail:   "decodes or downloads code and executes it",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)\b(base64|xxd|openssl)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|da|k)?sh\b`),
+			regexp.MustCompile(`(?i)\b(curl|wget)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|da|k)?sh\b`),
+			regexp.MustCompile(`(?i)\beval\b.*\b(base64|xxd|rev)\b`),
+			regexp.MustCompile(`(?i)\bexec\s*\(\s*(base64|codecs|zlib|marshal|bytes\.fromhex)`),
+			regexp.MustCompile(`(?i)frombase64string.*\b(iex|invoke-expression)\b|` +
+				`\b(iex|invoke-expression)\b.*frombase64string`),
+		},
+	},
+	{
+		rule:     "encoded-payload",
+		severity: SeverityWarning,
+		detail:   "long encoded blob",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`[A-Za-z0-9+/]{400,}={0,2}`),
+			regexp.MustCompile(`(\\x[0-9a-fA-F]{2}){40,}`),
+		},
+	},
+}
+
+var (
+	networkPattern = regexp.MustCompile(`(?i)\b(curl|wget|nc|ncat|netcat|invoke-webrequest|invoke-restmethod|iwr|irm)\b|` +
+		`\brequests\.(get|post|put)\b|\burllib\.request\b|\bfetch\s*\(|\bhttp\.(get|post)\b`)
+	envDumpPattern = regexp.MustCompile(`(?i)\bprintenv\b|(^|[\s;|&(])env\s*($|[|>;)])|` +
+		`\bos\.environ\b\s*($|[^.\[]|\.copy|\.items)|` +
+		`\bprocess\.env\b\s*($|[^.\[])|\bget-childitem\s+env:|` +
+		`(~|\$home|\$\{?home\}?)/\.(ssh|aws|gnupg|picoclaw|docker|kube)\b|/etc/(shadow|passwd)\b`)
+	secretVarPattern = regexp.MustCompile(`(?i)(\$\{?|\$env:|getenv\(\s*["']|os\.environ\[\s*["']|process\.env\.)` +
+		`[a-z0-9_]*(key|token|secret|passw(or)?d|credential|auth|cookie|session)[a-z0-9_]*`)
+	urlHostPattern = regexp.MustCompile(`(?i)https?://([a-z0-9.-]+)`)
+)
+
+var binaryMagic = [][]byte{
+	[]byte("\x7fELF"),
+	[]byte("MZ"),
+	{0xfe, 0xed, 0xfa, 0xce}, {0xfe, 0xed, 0xfa, 0xcf},
+	{0xce, 0xfa, 0xed, 0xfe}, {0xcf, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+}
+
+// scanSkill runs the static checks over every file of the skill in dir.
+func scanSkill(dir string, policy SecurityPolicy) ([]Finding, error) {
+	var findings []Finding
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == originMetaFile || rel == SignatureFileName {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if isBinary(data) {
+			findings = append(findings, scanBinary(rel, data, policy)...)
+			return nil
+		}
+		findings = append(findings, scanText(rel, data, policy)...)
+		return nil
+	})
+	return findings, err
+}
+
+func isBinary(data []byte) bool {
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0
+}
+
+func scanBinary(file string, data []byte, policy SecurityPolicy) []Finding {
+	var findings []Finding
+	if int64(len(data)) > policy.maxBinarySize() {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Rule:     "oversized-binary",
+			File:     file,
+			Detail:   "binary file of " + formatSize(int64(len(data))),
+		})
+	}
+	for _, magic := range binaryMagic {
+		if bytes.HasPrefix(data, magic) {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Rule:     "executable-binary",
+				File:     file,
+				Detail:   "bundled executable; its behavior cannot be reviewed",
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// maxScanLine is the longest line scanText reads. Anything longer cannot be
+// matched against the rules, so it is reported instead of skipped.
+const maxScanLine = 4 << 20
+
+func scanText(file string, data []byte, policy SecurityPolicy) []Finding {
+	var findings []Finding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScanLine)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		for _, rule := range lineRules {
+			for _, pattern := range rule.patterns {
+				if pattern.MatchString(line) {
+					findings = append(findings, Finding{
+						Severity: rule.severity,
+						Rule:     rule.rule,
+						File:     file,
+						Line:     lineNo,
+						Detail:   rule.detail + ": " + excerpt(line),
+					})
+					break
+				}
+			}
+		}
+		if f, ok := scanExfiltration(line, policy); ok {
+			f.File, f.Line = file, lineNo
+			findings = append(findings, f)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// The rest of the file went unread; a payload hidden behind an
+		// oversized line must not pass as clean.
+		findings = append(findings, Finding{
+			Severity: SeverityCritical,
+			Rule:     "unscannable-text",
+			File:     file,
+			Line:     lineNo + 1,
+			Detail:   "line longer than " + formatSize(maxScanLine) + "; the rest of the file was not scanned",
+		})
+	}
+	return findings
+}
+
+// scanExfiltration flags network calls that carry the environment, credential
+// files or secret-looking variables to hosts that are not allowed.
+func scanExfiltration(line string, policy SecurityPolicy) (Finding, bool) {
+	if !networkPattern.MatchString(line) {
+		return Finding{}, false
+	}
+	if envDumpPattern.MatchString(line) {
+		return Finding{
+			Severity: SeverityCritical,
+			Rule:     "secret-exfiltration",
+			Detail:   "sends the environment or credential files over the network: " + excerpt(line),
+		}, true
+	}
+	if secretVarPattern.MatchString(line) && !policy.hostsAllowed(line) {
+		return Finding{
+			Severity: SeverityWarning,
+			Rule:     "secret-to-unknown-host",
+			Detail:   "sends a secret from the environment to a host that is not allowed: " + excerpt(line),
+		}, true
+	}
+	return Finding{}, false
+}
+
+// hostsAllowed reports whether every URL host in line is an allowed host or
+// a subdomain of one. Lines without a literal URL are not allowed.
+func (p SecurityPolicy) hostsAllowed(line string) bool {
+	matches := urlHostPattern.FindAllStringSubmatch(line, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	for _, m := range matches {
+		host := strings.ToLower(m[1])
+		allowed := false
+		for _, h := range p.AllowedHosts {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if h != "" && (host == h || strings.HasSuffix(host, "."+h)) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+func excerpt(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > 120 {
+		return line[:117] + "..."
+	}
+	return line
+}
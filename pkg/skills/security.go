@@ -0,0 +1,183 @@
+package skills
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultMaxBinarySize = 1 << 20
+
+// Verdict is what the security policy decides for a skill install.
+type Verdict string
+
+const (
+	VerdictPass  Verdict = "pass"
+	VerdictFlag  Verdict = "flag"  // install only after the user confirms
+	VerdictBlock Verdict = "block" // never install
+)
+
+// SecurityPolicy decides which skills may be installed.
+type SecurityPolicy struct {
+	// TrustedKeys are minisign public keys whose signatures are trusted.
+	TrustedKeys []string
+	// RequireSignature blocks skills without a valid signature by a trusted key.
+	RequireSignature bool
+	// BlockOn is the lowest finding severity that blocks an install:
+	// "critical" (default), "warning", or "none" to only flag.
+	BlockOn string
+	// MaxBinarySize is the size in bytes above which bundled binaries are
+	// reported. Zero means 1 MiB.
+	MaxBinarySize int64
+	// AllowedHosts may receive secrets from the environment without a
+	// finding, e.g. "api.openweathermap.org". Subdomains are included.
+	AllowedHosts []string
+}
+
+func (p SecurityPolicy) maxBinarySize() int64 {
+	if p.MaxBinarySize > 0 {
+		return p.MaxBinarySize
+	}
+	return defaultMaxBinarySize
+}
+
+func (p SecurityPolicy) blockRank() int {
+	switch strings.ToLower(strings.TrimSpace(p.BlockOn)) {
+	case "none", "off":
+		return SeverityCritical.rank() + 1
+	case string(SeverityWarning):
+		return SeverityWarning.rank()
+	}
+	return SeverityCritical.rank()
+}
+
+// SecurityReport is the result of vetting a skill before it is installed.
+type SecurityReport struct {
+	Skill     string          `json:"skill"`
+	Hash      string          `json:"hash"`
+	Signature SignatureStatus `json:"signature"`
+	SignedBy  string          `json:"signed_by,omitempty"` // minisign key ID
+	Findings  []Finding       `json:"findings"`
+	Verdict   Verdict         `json:"verdict"`
+	Reason    string          `json:"reason,omitempty"` // why the skill is blocked
+}
+
+// VetSkill checks the signature of the skill in dir and scans its files,
+// then applies policy to the result.
+func VetSkill(dir, name string, policy SecurityPolicy) (*SecurityReport, error) {
+	hash, err := HashDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	report := &SecurityReport{Skill: name, Hash: hash, Findings: []Finding{}}
+	report.Signature, report.SignedBy, err = checkSignature(dir, hash, policy.TrustedKeys)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := scanSkill(dir, policy)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity.rank() > findings[j].Severity.rank()
+	})
+	report.Findings = append(report.Findings, findings...)
+	report.Verdict, report.Reason = policy.decide(report)
+	return report, nil
+}
+
+func (p SecurityPolicy) decide(r *SecurityReport) (Verdict, string) {
+	switch {
+	case r.Signature == SignatureInvalid:
+		return VerdictBlock, "the signature does not match the skill content"
+	case p.RequireSignature && r.Signature != SignatureTrusted:
+		return VerdictBlock, "a signature by a trusted key is required"
+	}
+	for _, f := range r.Findings {
+		if f.Severity.rank() >= p.blockRank() {
+			return VerdictBlock, fmt.Sprintf("%s finding: %s", f.Severity, f.Rule)
+		}
+	}
+	if len(r.Findings) > 0 {
+		return VerdictFlag, ""
+	}
+	return VerdictPass, ""
+}
+
+// Format renders the report for terminals and tool results.
+func (r *SecurityReport) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Security report for %s\n", r.Skill)
+	fmt.Fprintf(&b, "  Content hash: %s\n", r.Hash)
+	switch r.Signature {
+	case SignatureTrusted:
+		fmt.Fprintf(&b, "  Signature:    valid, trusted key %s\n", r.SignedBy)
+	case SignatureUntrusted:
+		fmt.Fprintf(&b, "  Signature:    by untrusted key %s\n", r.SignedBy)
+	case SignatureInvalid:
+		b.WriteString("  Signature:    INVALID\n")
+	default:
+		b.WriteString("  Signature:    none\n")
+	}
+	if len(r.Findings) == 0 {
+		b.WriteString("  Findings:     none\n")
+	} else {
+		fmt.Fprintf(&b, "  Findings:     %d\n", len(r.Findings))
+		for _, f := range r.Findings {
+			location := f.File
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s:%d", f.File, f.Line)
+			}
+			fmt.Fprintf(&b, "    [%s] %s %s: %s\n", f.Severity, f.Rule, location, f.Detail)
+		}
+	}
+	fmt.Fprintf(&b, "  Verdict:      %s", r.Verdict)
+	if r.Reason != "" {
+		fmt.Fprintf(&b, " (%s)", r.Reason)
+	}
+	return b.String()
+}
+
+// SecurityError is returned when a skill is blocked by the security policy
+// or its installation was not confirmed.
+type SecurityError struct {
+	Report *SecurityReport
+}
+
+func (e *SecurityError) Error() string {
+	if e.Report.Verdict == VerdictBlock {
+		return fmt.Sprintf("skill '%s' blocked by security policy: %s", e.Report.Skill, e.Report.Reason)
+	}
+	return fmt.Sprintf("installation of skill '%s' was not confirmed", e.Report.Skill)
+}
+
+// Vet vets a skill downloaded to dir before it is installed. Blocked skills
+// return a *SecurityError. Otherwise the installer's review callback sees
+// the report and decides; without one, only skills that pass are accepted.
+func (si *SkillInstaller) Vet(dir, name string) error {
+	report, err := VetSkill(dir, name, si.policy)
+	if err != nil {
+		return fmt.Errorf("vet skill %s: %w", name, err)
+	}
+	switch {
+	case report.Verdict == VerdictBlock:
+		return &SecurityError{Report: report}
+	case si.review != nil:
+		if !si.review(report) {
+			return &SecurityError{Report: report}
+		}
+	case report.Verdict == VerdictFlag:
+		return &SecurityError{Report: report}
+	}
+	return nil
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
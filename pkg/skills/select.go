@@ -0,0 +1,143 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// skillDoc is a skill as indexed for relevance selection.
+type skillDoc struct {
+	info SkillInfo
+	body string
+}
+
+// skillIndex is a BM25 index over the installed skills, rebuilt when a
+// SKILL.md file is added, removed or modified.
+type skillIndex struct {
+	mu          sync.Mutex
+	fingerprint string
+	engine      *utils.BM25Engine[skillDoc]
+}
+
+// SelectSkills ranks the installed skills against query with BM25 over
+// their name, description and body and returns at most topK skills that
+// score at least minScore, best first.
+func (sl *SkillsLoader) SelectSkills(query string, topK int, minScore float64) []SkillInfo {
+	if topK <= 0 || strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	query = stripStopWords(query)
+	if query == "" {
+		return nil
+	}
+	engine := sl.selectionEngine()
+	if engine == nil {
+		return nil
+	}
+
+	var selected []SkillInfo
+	for _, r := range engine.Search(query, topK) {
+		if float64(r.Score) < minScore {
+			break
+		}
+		selected = append(selected, r.Document.info)
+	}
+	return selected
+}
+
+func (sl *SkillsLoader) selectionEngine() *utils.BM25Engine[skillDoc] {
+	all := sl.ListSkills()
+	fingerprint := skillsFingerprint(all)
+
+	sl.index.mu.Lock()
+	defer sl.index.mu.Unlock()
+	if sl.index.engine != nil && sl.index.fingerprint == fingerprint {
+		return sl.index.engine
+	}
+	if len(all) == 0 {
+		sl.index.engine = nil
+		sl.index.fingerprint = fingerprint
+		return nil
+	}
+
+	docs := make([]skillDoc, 0, len(all))
+	for _, info := range all {
+		doc := skillDoc{info: info}
+		if content, err := os.ReadFile(info.Path); err == nil {
+			doc.body = sl.stripFrontmatter(string(content))
+		}
+		docs = append(docs, doc)
+	}
+	// Name and description say what a skill is for, so they count more
+	// than a mention in the body.
+	sl.index.engine = utils.NewBM25Engine(docs, func(d skillDoc) string {
+		name := strings.NewReplacer("-", " ", "_", " ").Replace(d.info.Name)
+		return strings.Repeat(name+" ", 3) + strings.Repeat(d.info.Description+" ", 2) + d.body
+	})
+	sl.index.fingerprint = fingerprint
+	return sl.index.engine
+}
+
+// stopWords are left out of selection queries. With the handful of skills
+// a workspace has, BM25 gives words like "the" enough weight to pull in
+// unrelated skills.
+var stopWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`a an the and or but if then of to in on at by for with from about as into
+		is are was were be been am do does did have has had can could will would should may might must
+		i me my we our you your he she it its they them their this that these those what which who how why when where
+		there here not no yes so just please also some any all very too up out over again`) {
+		stopWords[w] = struct{}{}
+	}
+}
+
+func stripStopWords(query string) string {
+	var kept []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if _, ok := stopWords[strings.Trim(word, ".,;:!?\"'()")]; !ok {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// skillsFingerprint identifies the current set of skill files and versions.
+func skillsFingerprint(all []SkillInfo) string {
+	var b strings.Builder
+	for _, info := range all {
+		b.WriteString(info.Path)
+		if st, err := os.Stat(info.Path); err == nil {
+			fmt.Fprintf(&b, ":%d:%d", st.ModTime().UnixNano(), st.Size())
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// BuildSkillsSummaryFor renders the summary of the given skills in the same
+// format as BuildSkillsSummary.
+func BuildSkillsSummaryFor(infos []SkillInfo) string {
+	if len(infos) == 0 {
+		return ""
+	}
+
+	lines := []string{"<skills>"}
+	for _, s := range infos {
+		lines = append(lines,
+			"  <skill>",
+			fmt.Sprintf("    <name>%s</name>", escapeXML(s.Name)),
+			fmt.Sprintf("    <description>%s</description>", escapeXML(s.Description)),
+			fmt.Sprintf("    <location>%s</location>", escapeXML(s.Path)),
+			fmt.Sprintf("    <source>%s</source>", s.Source),
+			"  </skill>",
+		)
+	}
+	lines = append(lines, "</skills>")
+	return strings.Join(lines, "\n")
+}
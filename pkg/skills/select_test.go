@@ -0,0 +1,55 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skillNames(infos []SkillInfo) []string {
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestSelectSkills(t *testing.T) {
+	workspace := t.TempDir()
+	root := filepath.Join(workspace, "skills")
+	writeSkill(t, root, "weather", "---\nname: weather\ndescription: Weather forecasts for any city\n---\n\n"+
+		"# Weather\n\nUse the forecast API to get temperature and rain for a city.\n")
+	writeSkill(t, root, "git-helper", "---\nname: git-helper\ndescription: Git branches, rebases and commits\n---\n\n"+
+		"# Git\n\nExplain how to rebase a branch and write commit messages.\n")
+	writeSkill(t, root, "pdf", "---\nname: pdf\ndescription: Read and fill PDF documents\n---\n\n"+
+		"# PDF\n\nExtract text and tables from PDF files.\n")
+	sl := NewSkillsLoader(workspace, "", "")
+
+	assert.Equal(t, []string{"weather"}, skillNames(sl.SelectSkills("will it rain in Berlin tomorrow", 3, 0.5)))
+	assert.Equal(t, []string{"git-helper"}, skillNames(sl.SelectSkills("how do I rebase my branch?", 3, 0.5)))
+	assert.Empty(t, sl.SelectSkills("tell me a joke", 3, 0.5))
+	assert.Empty(t, sl.SelectSkills("", 3, 0))
+	assert.Empty(t, sl.SelectSkills("weather", 0, 0))
+
+	// The index follows edits to the skills.
+	path := filepath.Join(root, "pdf", "SKILL.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\nname: pdf\ndescription: Read PDF documents\n---\n\n"+
+		"Also works for scanned rain gauge reports.\n"), 0o644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	assert.Contains(t, skillNames(sl.SelectSkills("rain gauge", 3, 0.5)), "pdf")
+}
+
+func TestBuildSkillsSummaryFor(t *testing.T) {
+	assert.Empty(t, BuildSkillsSummaryFor(nil))
+	summary := BuildSkillsSummaryFor([]SkillInfo{
+		{Name: "a&b", Description: "x < y", Path: "/s/SKILL.md", Source: "workspace"},
+	})
+	assert.Contains(t, summary, "<name>a&amp;b</name>")
+	assert.Contains(t, summary, "<description>x &lt; y</description>")
+	assert.Contains(t, summary, "<source>workspace</source>")
+}
@@ -0,0 +1,126 @@
+package skills
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// SignatureFileName is the minisign signature shipped next to SKILL.md. It
+// signs the skill's content hash as printed by `picoclaw skills vet`.
+const SignatureFileName = "skill.minisig"
+
+// SignatureStatus is the result of checking a skill's signature.
+type SignatureStatus string
+
+const (
+	SignatureNone      SignatureStatus = "unsigned"
+	SignatureTrusted   SignatureStatus = "trusted"   // valid, by a trusted key
+	SignatureUntrusted SignatureStatus = "untrusted" // by a key that is not trusted
+	SignatureInvalid   SignatureStatus = "invalid"   // malformed or not matching the content
+)
+
+// minisignKey is a minisign (ed25519) public key.
+type minisignKey struct {
+	id  [8]byte
+	key ed25519.PublicKey
+}
+
+// keyID formats a key ID the way minisign prints it.
+func keyID(id [8]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+// parseMinisignKey parses a public key: the base64 line of a minisign .pub
+// file, optionally with its comment line.
+func parseMinisignKey(text string) (minisignKey, error) {
+	var k minisignKey
+	line := lastLine(text)
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return k, fmt.Errorf("invalid minisign public key %q", line)
+	}
+	copy(k.id[:], raw[2:10])
+	k.key = ed25519.PublicKey(raw[10:])
+	return k, nil
+}
+
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// verifyMinisign checks a minisign signature of message against keys and
+// returns the status and the ID of the signing key.
+func verifyMinisign(message []byte, signature string, keys []minisignKey) (SignatureStatus, string) {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(signature), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return SignatureInvalid, ""
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return SignatureInvalid, ""
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return SignatureInvalid, ""
+	}
+
+	algorithm := string(raw[:2])
+	var id [8]byte
+	copy(id[:], raw[2:10])
+	sig := raw[10:]
+
+	switch algorithm {
+	case "Ed":
+	case "ED": // prehashed, the default since minisign 0.10
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	default:
+		return SignatureInvalid, keyID(id)
+	}
+
+	for _, k := range keys {
+		if k.id != id {
+			continue
+		}
+		trustedComment := strings.TrimPrefix(lines[2], "trusted comment: ")
+		if !ed25519.Verify(k.key, message, sig) ||
+			!ed25519.Verify(k.key, append(bytes.Clone(sig), trustedComment...), globalSig) {
+			return SignatureInvalid, keyID(id)
+		}
+		return SignatureTrusted, keyID(id)
+	}
+	return SignatureUntrusted, keyID(id)
+}
+
+// checkSignature verifies the signature file of the skill in dir, if any,
+// against the content hash of the skill.
+func checkSignature(dir, contentHash string, trustedKeys []string) (SignatureStatus, string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, SignatureFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return SignatureNone, "", nil
+	}
+	if err != nil {
+		return SignatureNone, "", err
+	}
+
+	keys := make([]minisignKey, 0, len(trustedKeys))
+	for _, text := range trustedKeys {
+		k, err := parseMinisignKey(text)
+		if err != nil {
+			return SignatureNone, "", err
+		}
+		keys = append(keys, k)
+	}
+	status, signer := verifyMinisign([]byte(contentHash), string(data), keys)
+	return status, signer, nil
+}
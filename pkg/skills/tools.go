@@ -0,0 +1,113 @@
+package skills
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ToolSpec is a tool declared in the `tools` list of a skill's frontmatter.
+// While the skill is active, the agent offers it to the model as a function
+// and runs Command in the skill directory when it is called.
+type ToolSpec struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Parameters  map[string]any `yaml:"parameters"` // JSON schema of the arguments
+	// Command is a shell command run in the skill directory, e.g.
+	// "python3 scripts/lookup.py". The arguments arrive as JSON on stdin.
+	Command        string   `yaml:"command"`
+	TimeoutSeconds int      `yaml:"timeout"`
+	Env            []string `yaml:"env"` // environment variables that must be set
+
+	Skill string `yaml:"-"` // name of the declaring skill
+	Dir   string `yaml:"-"` // skill directory
+}
+
+func (spec ToolSpec) validate() error {
+	var errs error
+	if !toolNamePattern.MatchString(spec.Name) {
+		errs = errors.Join(errs, fmt.Errorf("tool name %q must be 1-64 letters, digits, '_' or '-'", spec.Name))
+	}
+	if strings.TrimSpace(spec.Command) == "" {
+		errs = errors.Join(errs, fmt.Errorf("tool %q has no command", spec.Name))
+	}
+	if spec.TimeoutSeconds < 0 {
+		errs = errors.Join(errs, fmt.Errorf("tool %q has a negative timeout", spec.Name))
+	}
+	if spec.Parameters != nil {
+		if t, ok := spec.Parameters["type"]; ok && t != "object" {
+			errs = errors.Join(errs, fmt.Errorf("tool %q parameters must be an object schema", spec.Name))
+		}
+	}
+	return errs
+}
+
+// LoadSkillTools returns the tools declared by the named skill, resolved
+// with the same priority as ListSkills. Unknown skills and skills without
+// tools return nil.
+func (sl *SkillsLoader) LoadSkillTools(name string) ([]ToolSpec, error) {
+	for _, info := range sl.ListSkills() {
+		if info.Name != name {
+			continue
+		}
+		content, err := os.ReadFile(info.Path)
+		if err != nil {
+			return nil, err
+		}
+		frontmatter, _ := splitFrontmatter(string(content))
+		specs, err := parseToolSpecs(frontmatter)
+		if err != nil {
+			return nil, fmt.Errorf("skill %s: %w", name, err)
+		}
+		for i := range specs {
+			specs[i].Skill = info.Name
+			specs[i].Dir = filepath.Dir(info.Path)
+		}
+		return specs, nil
+	}
+	return nil, nil
+}
+
+// parseToolSpecs reads the `tools` list of a frontmatter block. JSON
+// frontmatter is valid YAML, so both forms go through the YAML decoder.
+func parseToolSpecs(frontmatter string) ([]ToolSpec, error) {
+	if strings.TrimSpace(frontmatter) == "" {
+		return nil, nil
+	}
+	var meta struct {
+		Tools []ToolSpec `yaml:"tools"`
+	}
+	if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	seen := make(map[string]bool, len(meta.Tools))
+	var errs error
+	for i := range meta.Tools {
+		spec := &meta.Tools[i]
+		if err := spec.validate(); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if seen[spec.Name] {
+			errs = errors.Join(errs, fmt.Errorf("tool %q is declared twice", spec.Name))
+		}
+		seen[spec.Name] = true
+		if spec.Parameters == nil {
+			spec.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+		} else if _, ok := spec.Parameters["type"]; !ok {
+			spec.Parameters["type"] = "object"
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return meta.Tools, nil
+}
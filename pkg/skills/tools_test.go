@@ -0,0 +1,100 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSkill(t *testing.T, root, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644))
+	return dir
+}
+
+func TestLoadSkillTools(t *testing.T) {
+	workspace := t.TempDir()
+	dir := writeSkill(t, filepath.Join(workspace, "skills"), "weather", `---
+name: weather
+description: Weather lookups
+tools:
+  - name: get_forecast
+    description: Forecast for a city
+    command: python3 scripts/forecast.py
+    timeout: 20
+    env: [WEATHER_API_KEY]
+    parameters:
+      type: object
+      properties:
+        city: {type: string}
+        days: {type: integer, minimum: 1}
+      required: [city]
+  - name: list_stations
+    command: ./stations.sh
+---
+# Weather
+`)
+	writeSkill(t, filepath.Join(workspace, "skills"), "plain", "---\nname: plain\ndescription: No tools\n---\n")
+
+	loader := NewSkillsLoader(workspace, "", "")
+	specs, err := loader.LoadSkillTools("weather")
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+
+	forecast := specs[0]
+	assert.Equal(t, "get_forecast", forecast.Name)
+	assert.Equal(t, "python3 scripts/forecast.py", forecast.Command)
+	assert.Equal(t, 20, forecast.TimeoutSeconds)
+	assert.Equal(t, []string{"WEATHER_API_KEY"}, forecast.Env)
+	assert.Equal(t, "weather", forecast.Skill)
+	assert.Equal(t, dir, forecast.Dir)
+	props := forecast.Parameters["properties"].(map[string]any)
+	assert.Contains(t, props, "city")
+	assert.Equal(t, []any{"city"}, forecast.Parameters["required"])
+
+	assert.Equal(t, map[string]any{"type": "object", "properties": map[string]any{}}, specs[1].Parameters)
+
+	specs, err = loader.LoadSkillTools("plain")
+	require.NoError(t, err)
+	assert.Empty(t, specs)
+
+	specs, err = loader.LoadSkillTools("missing")
+	require.NoError(t, err)
+	assert.Empty(t, specs)
+}
+
+func TestLoadSkillToolsJSONFrontmatter(t *testing.T) {
+	workspace := t.TempDir()
+	writeSkill(t, filepath.Join(workspace, "skills"), "echo", `---
+{"name": "echo", "description": "Echo", "tools": [{"name": "echo_args", "command": "cat"}]}
+---
+`)
+	specs, err := NewSkillsLoader(workspace, "", "").LoadSkillTools("echo")
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+	assert.Equal(t, "echo_args", specs[0].Name)
+}
+
+func TestParseToolSpecsRejectsInvalid(t *testing.T) {
+	testcases := []struct {
+		name        string
+		frontmatter string
+	}{
+		{"bad-name", "tools:\n  - name: get forecast\n    command: x"},
+		{"no-command", "tools:\n  - name: get_forecast"},
+		{"negative-timeout", "tools:\n  - name: a\n    command: x\n    timeout: -1"},
+		{"non-object-schema", "tools:\n  - name: a\n    command: x\n    parameters: {type: string}"},
+		{"duplicate", "tools:\n  - name: a\n    command: x\n  - name: a\n    command: y"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseToolSpecs(tc.frontmatter)
+			assert.Error(t, err)
+		})
+	}
+}
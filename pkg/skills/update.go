@@ -0,0 +1,334 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// UpdateStatus is the outcome of updating or syncing one skill.
+type UpdateStatus string
+
+const (
+	UpdateUpToDate  UpdateStatus = "up-to-date"
+	UpdateUpdated   UpdateStatus = "updated"
+	UpdateInstalled UpdateStatus = "installed" // restored a missing skill
+	UpdateModified  UpdateStatus = "modified"  // skipped because of local edits
+	UpdateFailed    UpdateStatus = "failed"
+)
+
+// UpdateResult describes what Update or Sync did to one skill.
+type UpdateResult struct {
+	Name   string
+	Status UpdateStatus
+	From   string // revision before
+	To     string // revision after
+	// HashMismatch is set by Sync when the restored content differs from the
+	// hash in the lock file, e.g. because a registry republished a version.
+	HashMismatch bool
+	Err          error
+}
+
+// Update reinstalls locked skills from their source at the latest version
+// (registries) or the current commit of the locked ref (GitHub). name
+// selects one skill; empty updates all of them. Skills whose content no
+// longer matches the lock file were edited locally and are skipped unless
+// force is set.
+func (si *SkillInstaller) Update(
+	ctx context.Context,
+	registries *RegistryManager,
+	name string,
+	force bool,
+) ([]UpdateResult, error) {
+	lock, err := LoadLock(si.workspace)
+	if err != nil {
+		return nil, err
+	}
+	names := lock.Names()
+	if name != "" {
+		if _, ok := lock.Skills[name]; !ok {
+			return nil, fmt.Errorf("skill '%s' is not in %s", name, LockFileName)
+		}
+		names = []string{name}
+	}
+
+	results := make([]UpdateResult, 0, len(names))
+	for _, n := range names {
+		results = append(results, si.updateSkill(ctx, registries, n, lock.Skills[n], force))
+	}
+	return results, nil
+}
+
+func (si *SkillInstaller) updateSkill(
+	ctx context.Context,
+	registries *RegistryManager,
+	name string,
+	entry LockEntry,
+	force bool,
+) UpdateResult {
+	result := UpdateResult{Name: name, From: entry.Revision(), To: entry.Revision()}
+	present, modified, err := si.checkSkill(name, entry)
+	if err != nil {
+		return failed(result, err)
+	}
+	if modified && !force {
+		result.Status = UpdateModified
+		return result
+	}
+
+	// Registries tell the latest version without a download.
+	if present && !modified && registries != nil && entry.Source != SourceGitHub && entry.Version != "" {
+		if registry := registries.GetRegistry(entry.Source); registry != nil {
+			if meta, err := registry.GetSkillMeta(ctx, entry.Slug); err == nil && meta.LatestVersion == entry.Version {
+				result.Status = UpdateUpToDate
+				return result
+			}
+		}
+	}
+
+	fetched, stageRoot, err := si.fetchSkill(ctx, registries, name, entry, false)
+	if err != nil {
+		return failed(result, err)
+	}
+	defer os.RemoveAll(stageRoot)
+	result.To = fetched.Revision()
+
+	if present && !modified && fetched.Hash == entry.Hash {
+		// Same content under a new revision: only the lock entry changes.
+		result.Status = UpdateUpToDate
+		if fetched.Revision() != entry.Revision() {
+			fetched.InstalledAt = entry.InstalledAt
+			err := updateLock(si.workspace, func(l *Lock) bool {
+				l.Skills[name] = fetched
+				return true
+			})
+			if err != nil {
+				return failed(result, err)
+			}
+		}
+		return result
+	}
+
+	if err := si.commitSkill(stageRoot, name, fetched); err != nil {
+		return failed(result, err)
+	}
+	result.Status = UpdateUpdated
+	if !present {
+		result.Status = UpdateInstalled
+	}
+	return result
+}
+
+// Sync makes the workspace match the lock file: missing skills are
+// installed at their locked revision. Skills that were edited locally are
+// reported and left alone unless force is set, which restores them too.
+func (si *SkillInstaller) Sync(ctx context.Context, registries *RegistryManager, force bool) ([]UpdateResult, error) {
+	lock, err := LoadLock(si.workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]UpdateResult, 0, len(lock.Skills))
+	for _, name := range lock.Names() {
+		entry := lock.Skills[name]
+		result := UpdateResult{Name: name, From: entry.Revision(), To: entry.Revision()}
+
+		present, modified, err := si.checkSkill(name, entry)
+		switch {
+		case err != nil:
+			results = append(results, failed(result, err))
+			continue
+		case present && !modified:
+			result.Status = UpdateUpToDate
+			results = append(results, result)
+			continue
+		case modified && !force:
+			result.Status = UpdateModified
+			results = append(results, result)
+			continue
+		}
+
+		fetched, stageRoot, err := si.fetchSkill(ctx, registries, name, entry, true)
+		if err != nil {
+			results = append(results, failed(result, err))
+			continue
+		}
+		result.HashMismatch = fetched.Hash != entry.Hash
+		if err := si.commitSkill(stageRoot, name, fetched); err != nil {
+			result = failed(result, err)
+		} else if present {
+			result.Status = UpdateUpdated
+		} else {
+			result.Status = UpdateInstalled
+		}
+		os.RemoveAll(stageRoot)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func failed(result UpdateResult, err error) UpdateResult {
+	result.Status = UpdateFailed
+	result.Err = err
+	return result
+}
+
+// checkSkill reports whether the skill directory exists and whether its
+// content differs from the lock entry.
+func (si *SkillInstaller) checkSkill(name string, entry LockEntry) (present, modified bool, err error) {
+	hash, err := HashDir(filepath.Join(si.workspace, "skills", name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, hash != entry.Hash, nil
+}
+
+// fetchSkill downloads a locked skill into a staging directory next to the
+// skills and returns the new lock entry and the staging root. pinned
+// fetches the locked revision instead of the latest one.
+func (si *SkillInstaller) fetchSkill(
+	ctx context.Context,
+	registries *RegistryManager,
+	name string,
+	entry LockEntry,
+	pinned bool,
+) (LockEntry, string, error) {
+	skillsDir := filepath.Join(si.workspace, "skills")
+	if err := os.MkdirAll(skillsDir, 0o755); err != nil {
+		return entry, "", err
+	}
+	// The skill sits one level below the dot directory, where the skills
+	// loader does not look.
+	stageRoot, err := os.MkdirTemp(skillsDir, "."+name+"-update-*")
+	if err != nil {
+		return entry, "", err
+	}
+	target := filepath.Join(stageRoot, name)
+
+	fetched := entry
+	fetched.InstalledAt = time.Now().UTC()
+	if err := si.download(ctx, registries, &fetched, target, pinned); err != nil {
+		os.RemoveAll(stageRoot)
+		return entry, "", err
+	}
+	if fetched.Hash, err = HashDir(target); err != nil {
+		os.RemoveAll(stageRoot)
+		return entry, "", err
+	}
+	if fetched.Hash != entry.Hash {
+		if err := si.Vet(target, name); err != nil {
+			os.RemoveAll(stageRoot)
+			return entry, "", err
+		}
+	}
+	return fetched, stageRoot, nil
+}
+
+func (si *SkillInstaller) download(
+	ctx context.Context,
+	registries *RegistryManager,
+	entry *LockEntry,
+	target string,
+	pinned bool,
+) error {
+	if entry.Source == SourceGitHub {
+		ref, err := parseGitHubRef(entry.Repo)
+		if err != nil {
+			return err
+		}
+		if entry.Ref != "" {
+			ref.Ref = entry.Ref
+		}
+		if pinned && entry.Commit != "" {
+			ref.Ref = entry.Commit
+		}
+		commit, err := si.downloadGitHub(ctx, ref, target)
+		if err != nil {
+			return err
+		}
+		if commit != "" || !pinned {
+			entry.Commit = commit
+		}
+		return nil
+	}
+
+	var registry SkillRegistry
+	if registries != nil {
+		registry = registries.GetRegistry(entry.Source)
+	}
+	if registry == nil {
+		return fmt.Errorf("registry '%s' not found or not enabled", entry.Source)
+	}
+	version := ""
+	if pinned && entry.Version != "latest" {
+		version = entry.Version
+	}
+	result, err := registry.DownloadAndInstall(ctx, entry.Slug, version, target)
+	if err != nil {
+		return err
+	}
+	if result.IsMalwareBlocked {
+		return fmt.Errorf("skill '%s' is flagged as malicious", entry.Slug)
+	}
+	entry.Version = result.Version
+	return nil
+}
+
+// commitSkill replaces the installed skill with the staged one and records
+// it in the lock file. The origin metadata of the old install is carried
+// over so the web UI keeps showing where the skill came from.
+func (si *SkillInstaller) commitSkill(stageRoot, name string, entry LockEntry) error {
+	staged := filepath.Join(stageRoot, name)
+	target := filepath.Join(si.workspace, "skills", name)
+	carryOriginMeta(target, staged, entry)
+
+	backup := filepath.Join(stageRoot, ".previous")
+	_, statErr := os.Stat(target)
+	present := statErr == nil
+	if present {
+		if err := os.Rename(target, backup); err != nil {
+			return fmt.Errorf("failed to move existing skill aside: %w", err)
+		}
+	}
+	if err := os.Rename(staged, target); err != nil {
+		if present {
+			if rollbackErr := os.Rename(backup, target); rollbackErr != nil {
+				return fmt.Errorf("failed to activate update: %w (rollback failed: %v)", err, rollbackErr)
+			}
+		}
+		return fmt.Errorf("failed to activate update: %w", err)
+	}
+
+	return updateLock(si.workspace, func(l *Lock) bool {
+		l.Skills[name] = entry
+		return true
+	})
+}
+
+func carryOriginMeta(oldDir, newDir string, entry LockEntry) {
+	data, err := os.ReadFile(filepath.Join(oldDir, originMetaFile))
+	if err != nil {
+		return
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return
+	}
+	if entry.Version != "" {
+		meta["installed_version"] = entry.Version
+	}
+	meta["installed_at"] = entry.InstalledAt.UnixMilli()
+	if data, err = json.MarshalIndent(meta, "", "  "); err == nil {
+		_ = fileutil.WriteFileAtomic(filepath.Join(newDir, originMetaFile), data, 0o600)
+	}
+}
@@ -0,0 +1,280 @@
+package swarm
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxClockSkew is how far the timestamp of an announcement or a peer
+// connection may be from the local clock.
+const maxClockSkew = 2 * time.Minute
+
+// nonceTTL is how long a nonce is remembered: past it, the timestamp signed
+// along with the nonce is too old to be accepted anyway.
+const nonceTTL = 2 * maxClockSkew
+
+// announcement is what a node tells its peers about itself. The URL is part
+// of the signed data, so a copied announcement cannot point peers at
+// another host.
+type announcement struct {
+	NodeID  string   `json:"node_id"`
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Version string   `json:"version,omitempty"`
+	Agents  []string `json:"agents,omitempty"`
+	Tools   []string `json:"tools,omitempty"`
+	Time    int64    `json:"time"`
+	Nonce   string   `json:"nonce"`
+}
+
+// packet is the UDP datagram. The signature covers the raw announcement
+// bytes, so receivers verify exactly what was signed.
+type packet struct {
+	Announcement json.RawMessage `json:"announcement"`
+	Signature    string          `json:"signature"`
+}
+
+func sign(secret string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verify(secret string, data []byte, signature string) bool {
+	want, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+func encodeAnnouncement(secret string, a announcement) ([]byte, error) {
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(packet{Announcement: raw, Signature: sign(secret, raw)})
+}
+
+func decodeAnnouncement(secret string, data []byte, now time.Time) (announcement, error) {
+	var p packet
+	if err := json.Unmarshal(data, &p); err != nil {
+		return announcement{}, err
+	}
+	if !verify(secret, p.Announcement, p.Signature) {
+		return announcement{}, errors.New("bad signature")
+	}
+	var a announcement
+	if err := json.Unmarshal(p.Announcement, &a); err != nil {
+		return announcement{}, err
+	}
+	if a.NodeID == "" || a.Name == "" || a.URL == "" || a.Nonce == "" {
+		return announcement{}, errors.New("incomplete announcement")
+	}
+	if d := now.Sub(time.Unix(a.Time, 0)); d > maxClockSkew || d < -maxClockSkew {
+		return announcement{}, errors.New("stale announcement")
+	}
+	return a, nil
+}
+
+// Start begins announcing this node and listening for peers.
+func (n *Node) Start(ctx context.Context) error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: n.opts.DiscoveryPort})
+	if err != nil {
+		return fmt.Errorf("swarm discovery: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	n.cancel = cancel
+	n.wg.Add(2)
+	go func() {
+		defer n.wg.Done()
+		n.receiveLoop(conn)
+	}()
+	go func() {
+		defer n.wg.Done()
+		n.announceLoop(ctx, conn)
+	}()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	logger.InfoCF("swarm", "Swarm node started", map[string]any{
+		"node":           n.opts.Name,
+		"discovery_port": n.opts.DiscoveryPort,
+		"announce":       n.opts.Announce,
+	})
+	return nil
+}
+
+// Stop stops announcing and listening.
+func (n *Node) Stop() {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	n.wg.Wait()
+}
+
+func (n *Node) announcement(url string, now time.Time) announcement {
+	a := announcement{
+		NodeID:  n.nodeID,
+		Name:    n.opts.Name,
+		URL:     url,
+		Version: n.opts.Version,
+		Time:    now.Unix(),
+		Nonce:   uuid.NewString(),
+	}
+	if n.opts.Info != nil {
+		a.Agents, a.Tools = n.opts.Info()
+	}
+	return a
+}
+
+func (n *Node) announceLoop(ctx context.Context, conn *net.UDPConn) {
+	ticker := time.NewTicker(n.opts.AnnounceInterval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		n.announce(conn, now)
+		n.prune(now)
+		n.writeState(now)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Node) announce(conn *net.UDPConn, now time.Time) {
+	for _, target := range n.opts.Announce {
+		addr, err := net.ResolveUDPAddr("udp4", target)
+		if err != nil {
+			logger.WarnCF("swarm", "Invalid announce address", map[string]any{"address": target, "error": err.Error()})
+			continue
+		}
+		url := n.advertisedURL(addr.IP)
+		if url == "" {
+			logger.DebugCF("swarm", "No local address to announce", map[string]any{"address": target})
+			continue
+		}
+		data, err := encodeAnnouncement(n.opts.Secret, n.announcement(url, now))
+		if err != nil {
+			continue
+		}
+		if _, err := conn.WriteToUDP(data, addr); err != nil {
+			logger.DebugCF("swarm", "Announcement failed", map[string]any{"address": target, "error": err.Error()})
+		}
+	}
+}
+
+func (n *Node) receiveLoop(conn *net.UDPConn) {
+	buf := make([]byte, 64*1024)
+	for {
+		size, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		now := time.Now()
+		a, err := decodeAnnouncement(n.opts.Secret, buf[:size], now)
+		if err != nil {
+			logger.DebugCF("swarm", "Ignoring announcement", map[string]any{"from": src.String(), "error": err.Error()})
+			continue
+		}
+		if a.NodeID == n.nodeID {
+			continue
+		}
+		if !n.firstUse("announce\n"+a.NodeID+"\n"+a.Nonce, now) {
+			logger.DebugCF("swarm", "Ignoring replayed announcement", map[string]any{"from": src.String()})
+			continue
+		}
+		n.upsertPeer(Peer{
+			Name:     a.Name,
+			NodeID:   a.NodeID,
+			URL:      a.URL,
+			Version:  a.Version,
+			Agents:   a.Agents,
+			Tools:    a.Tools,
+			LastSeen: now,
+		})
+	}
+}
+
+// advertisedURL returns the URL announced to peers at dst: AdvertiseURL when
+// set, else the gateway port on the local address that reaches dst.
+func (n *Node) advertisedURL(dst net.IP) string {
+	if n.opts.AdvertiseURL != "" {
+		return n.opts.AdvertiseURL
+	}
+	ip := localAddrFor(dst)
+	if ip == nil {
+		return ""
+	}
+	return "ws://" + net.JoinHostPort(ip.String(), strconv.Itoa(n.opts.GatewayPort)) + WSPath
+}
+
+// localAddrFor returns the local IPv4 address peers at dst can reach: the
+// address of the interface whose subnet holds dst (which covers directed
+// broadcasts), else the source address the routing table picks, else the
+// first non-loopback address.
+func localAddrFor(dst net.IP) net.IP {
+	var first net.IP
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || ipnet.IP.To4() == nil || ipnet.IP.IsLoopback() {
+				continue
+			}
+			if ipnet.Contains(dst) {
+				return ipnet.IP
+			}
+			if first == nil {
+				first = ipnet.IP
+			}
+		}
+	}
+	if !dst.Equal(net.IPv4bcast) {
+		// Connecting a UDP socket sends nothing; it only picks a route.
+		if conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: dst, Port: 9}); err == nil {
+			defer conn.Close()
+			return conn.LocalAddr().(*net.UDPAddr).IP
+		}
+	}
+	return first
+}
+
+// firstUse records key, a signed nonce, and reports whether it was new.
+// Keys are forgotten after nonceTTL.
+func (n *Node) firstUse(key string, now time.Time) bool {
+	n.nonceMu.Lock()
+	defer n.nonceMu.Unlock()
+	for k, seen := range n.nonces {
+		if now.Sub(seen) > nonceTTL {
+			delete(n.nonces, k)
+		}
+	}
+	if _, ok := n.nonces[key]; ok {
+		return false
+	}
+	n.nonces[key] = now
+	return true
+}
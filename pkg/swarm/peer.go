@@ -0,0 +1,275 @@
+package swarm
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/sipeed/picoclaw/pkg/channels/pico"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Peer connections authenticate both ways with these headers, keyed with the
+// swarm secret so the secret itself never crosses the network. The caller
+// signs its node name, the Unix time and a fresh nonce; the peer answers the
+// upgrade with its own name signed over the same nonce, so the caller knows
+// it reached a member of the swarm before it sends the task. Every reply on
+// the connection is signed over the nonce as well.
+const (
+	headerNode      = "X-Swarm-Node"
+	headerTime      = "X-Swarm-Time"
+	headerNonce     = "X-Swarm-Nonce"
+	headerSignature = "X-Swarm-Signature"
+)
+
+// signFields returns the HMAC of fields, keyed with secret. Every field is
+// length-prefixed, so no two field lists sign the same bytes.
+func signFields(secret string, fields ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("picoclaw-swarm"))
+	for _, f := range fields {
+		mac.Write([]byte("\n" + strconv.Itoa(len(f)) + ":" + f))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(signature, want string) bool {
+	return signature != "" && hmac.Equal([]byte(signature), []byte(want))
+}
+
+func authHeader(secret, node, nonce string, now time.Time) http.Header {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	h := http.Header{}
+	h.Set(headerNode, node)
+	h.Set(headerTime, ts)
+	h.Set(headerNonce, nonce)
+	h.Set(headerSignature, signFields(secret, "connect", node, ts, nonce))
+	return h
+}
+
+// acceptHeader proves to the caller that node holds the swarm secret.
+func acceptHeader(secret, node, nonce string) http.Header {
+	h := http.Header{}
+	h.Set(headerNode, node)
+	h.Set(headerSignature, signFields(secret, "accept", node, nonce))
+	return h
+}
+
+func replySignature(secret, nonce string, msg pico.PicoMessage) string {
+	content, _ := msg.Payload["content"].(string)
+	code, _ := msg.Payload["code"].(string)
+	message, _ := msg.Payload["message"].(string)
+	return signFields(secret, "reply", nonce, msg.ID, msg.Type, content, code, message)
+}
+
+// authenticate returns the name of the peer that signed r and the nonce of
+// the connection. A nonce is accepted only once.
+func (n *Node) authenticate(r *http.Request, now time.Time) (peer, nonce string, ok bool) {
+	node := r.Header.Get(headerNode)
+	nonce = r.Header.Get(headerNonce)
+	raw := r.Header.Get(headerTime)
+	ts, err := strconv.ParseInt(raw, 10, 64)
+	if node == "" || nonce == "" || err != nil {
+		return "", "", false
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > maxClockSkew || d < -maxClockSkew {
+		return "", "", false
+	}
+	want := signFields(n.opts.Secret, "connect", node, raw, nonce)
+	if !validSignature(r.Header.Get(headerSignature), want) {
+		return "", "", false
+	}
+	if !n.firstUse("connect\n"+nonce, now) {
+		return "", "", false
+	}
+	return node, nonce, true
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// Peers are not browsers; authentication is by signature.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// ServeHTTP serves the peer WebSocket on WSPath.
+func (n *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	peer, nonce, ok := n.authenticate(r, time.Now())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if n.opts.Run == nil {
+		http.Error(w, "swarm tasks are not available", http.StatusServiceUnavailable)
+		return
+	}
+	ws, err := upgrader.Upgrade(w, r, acceptHeader(n.opts.Secret, n.opts.Name, nonce))
+	if err != nil {
+		logger.WarnCF("swarm", "Peer WebSocket upgrade failed", map[string]any{"peer": peer, "error": err.Error()})
+		return
+	}
+	n.serveConn(r.Context(), peer, nonce, ws)
+}
+
+// serveConn runs the tasks a peer sends until it disconnects. Running tasks
+// are canceled when the connection drops. Replies are signed over nonce.
+func (n *Node) serveConn(ctx context.Context, peer, nonce string, ws *websocket.Conn) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	var writeMu sync.Mutex
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+		ws.Close()
+	}()
+
+	write := func(msg pico.PicoMessage) {
+		if msg.Payload == nil {
+			msg.Payload = map[string]any{}
+		}
+		msg.Payload["signature"] = replySignature(n.opts.Secret, nonce, msg)
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = ws.WriteJSON(msg)
+	}
+
+	for {
+		var msg pico.PicoMessage
+		if err := ws.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case pico.TypePing:
+			write(pico.PicoMessage{Type: pico.TypePong, ID: msg.ID, Timestamp: time.Now().UnixMilli()})
+		case pico.TypeMessageSend:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				write(n.runTask(ctx, peer, msg))
+			}()
+		default:
+			write(errorMessage(msg.ID, "unsupported_type", "unsupported message type "+msg.Type))
+		}
+	}
+}
+
+func (n *Node) runTask(ctx context.Context, peer string, msg pico.PicoMessage) pico.PicoMessage {
+	content, _ := msg.Payload["content"].(string)
+	agentID, _ := msg.Payload["agent_id"].(string)
+	hops, _ := msg.Payload["hops"].(float64)
+	if strings.TrimSpace(content) == "" {
+		return errorMessage(msg.ID, "invalid_task", "task content is empty")
+	}
+	if int(hops) > maxHops {
+		return errorMessage(msg.ID, "too_many_hops", "task was delegated too many times")
+	}
+
+	ctx, cancel := context.WithTimeout(withHops(ctx, int(hops)), n.opts.TaskTimeout)
+	defer cancel()
+
+	logger.InfoCF("swarm", "Running task from peer", map[string]any{"peer": peer, "agent_id": agentID})
+	output, err := n.opts.Run(ctx, Task{Peer: peer, AgentID: agentID, Content: content})
+	if err != nil {
+		return errorMessage(msg.ID, "task_failed", err.Error())
+	}
+	return pico.PicoMessage{
+		Type:      pico.TypeMessageCreate,
+		ID:        msg.ID,
+		Timestamp: time.Now().UnixMilli(),
+		Payload:   map[string]any{"content": output},
+	}
+}
+
+func errorMessage(id, code, message string) pico.PicoMessage {
+	return pico.PicoMessage{
+		Type:      pico.TypeError,
+		ID:        id,
+		Timestamp: time.Now().UnixMilli(),
+		Payload:   map[string]any{"code": code, "message": message},
+	}
+}
+
+// Delegate runs task on the agent agentID (empty for its default agent) of
+// the online peer called peerName and returns the reply.
+func (n *Node) Delegate(ctx context.Context, peerName, agentID, task string) (string, error) {
+	peer, ok := n.Peer(peerName)
+	if !ok {
+		return "", fmt.Errorf("peer %q is not online", peerName)
+	}
+	hops := hopsFromContext(ctx) + 1
+	if hops > maxHops {
+		return "", errors.New("task was delegated too many times")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.opts.TaskTimeout)
+	defer cancel()
+
+	nonce := uuid.NewString()
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+	ws, resp, err := dialer.DialContext(ctx, peer.URL, authHeader(n.opts.Secret, n.opts.Name, nonce, time.Now()))
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		if resp != nil {
+			return "", fmt.Errorf("connect to peer %s: %w (HTTP %d)", peer.Name, err, resp.StatusCode)
+		}
+		return "", fmt.Errorf("connect to peer %s: %w", peer.Name, err)
+	}
+	defer ws.Close()
+	// Make sure the other end holds the swarm secret before the task is
+	// sent: an announcement may have been replayed by someone else.
+	if resp.Header.Get(headerNode) != peer.Name ||
+		!validSignature(resp.Header.Get(headerSignature), signFields(n.opts.Secret, "accept", peer.Name, nonce)) {
+		return "", fmt.Errorf("peer %s at %s could not prove it belongs to the swarm", peer.Name, peer.URL)
+	}
+	stop := context.AfterFunc(ctx, func() { ws.Close() })
+	defer stop()
+
+	id := uuid.NewString()
+	err = ws.WriteJSON(pico.PicoMessage{
+		Type:      pico.TypeMessageSend,
+		ID:        id,
+		Timestamp: time.Now().UnixMilli(),
+		Payload:   map[string]any{"content": task, "agent_id": agentID, "hops": hops},
+	})
+	if err != nil {
+		return "", fmt.Errorf("send task to peer %s: %w", peer.Name, err)
+	}
+
+	for {
+		var msg pico.PicoMessage
+		if err := ws.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("peer %s: %w", peer.Name, ctx.Err())
+			}
+			return "", fmt.Errorf("peer %s: %w", peer.Name, err)
+		}
+		if msg.ID != id {
+			continue
+		}
+		signature, _ := msg.Payload["signature"].(string)
+		if !validSignature(signature, replySignature(n.opts.Secret, nonce, msg)) {
+			return "", fmt.Errorf("peer %s sent an unsigned reply", peer.Name)
+		}
+		switch msg.Type {
+		case pico.TypeMessageCreate:
+			content, _ := msg.Payload["content"].(string)
+			return content, nil
+		case pico.TypeError:
+			message, _ := msg.Payload["message"].(string)
+			return "", fmt.Errorf("peer %s: %s", peer.Name, message)
+		}
+	}
+}
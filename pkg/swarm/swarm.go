@@ -0,0 +1,278 @@
+// Package swarm lets PicoClaw instances on the same network find each other
+// and run tasks on each other's agents.
+//
+// Every node periodically announces itself over UDP (broadcast by default)
+// with its agents, tools and URL. Announcements are signed with a shared
+// secret and carry a nonce, so only nodes of the same swarm are listed and a
+// copied announcement is ignored. Peer connections are authenticated in
+// both directions. Tasks are delegated over a
+// WebSocket speaking the Pico Protocol: the caller sends message.send and
+// the peer answers with message.create or error.
+//
+// The WebSocket is served on its own path rather than by the pico channel,
+// and dialed per task rather than through pico_client. Those carry chat:
+// one configured URL, a static bearer token, and messages that join a
+// session. Peers instead come and go with discovery, prove to each other
+// that they hold the swarm secret, and run each task as a fresh turn that
+// answers one request. Only the message format is shared.
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// WSPath is where the gateway serves the peer WebSocket.
+	WSPath = "/swarm/ws"
+	// StateFile is the file, in the workspace state directory, where the
+	// gateway records the peer table for `picoclaw status`.
+	StateFile = "swarm.json"
+
+	DefaultDiscoveryPort    = 18799
+	DefaultAnnounceInterval = 10 * time.Second
+	DefaultTaskTimeout      = 5 * time.Minute
+
+	// maxHops bounds how often a task may be passed on to another peer.
+	maxHops = 3
+	// forgetAfter drops peers that have been silent this long.
+	forgetAfter = 10 * time.Minute
+)
+
+// Task is a task delegated by a peer.
+type Task struct {
+	Peer    string // name of the node that sent the task
+	AgentID string // empty means the default agent
+	Content string
+}
+
+// RunFunc runs a delegated task and returns the agent's reply.
+type RunFunc func(ctx context.Context, task Task) (string, error)
+
+// Options configures a Node.
+type Options struct {
+	Name             string
+	Secret           string
+	DiscoveryPort    int
+	Announce         []string // UDP addresses announcements are sent to
+	AnnounceInterval time.Duration
+	GatewayPort      int    // port peers connect to, unless AdvertiseURL is set
+	AdvertiseURL     string // WebSocket URL peers use to reach this node
+	TaskTimeout      time.Duration
+	Version          string
+	// StatePath is where the peer table is written; empty disables it.
+	StatePath string
+	// Info returns the agents and tools advertised to peers.
+	Info func() (agents, tools []string)
+	// Run executes tasks delegated by peers.
+	Run RunFunc
+}
+
+// Peer is another node of the swarm.
+type Peer struct {
+	Name     string    `json:"name"`
+	NodeID   string    `json:"node_id"`
+	URL      string    `json:"url"`
+	Version  string    `json:"version,omitempty"`
+	Agents   []string  `json:"agents,omitempty"`
+	Tools    []string  `json:"tools,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Online reports whether the peer announced itself within ttl of now.
+func (p Peer) Online(now time.Time, ttl time.Duration) bool {
+	return now.Sub(p.LastSeen) <= ttl
+}
+
+// State is the content of the state file.
+type State struct {
+	Node      string        `json:"node"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	PeerTTL   time.Duration `json:"peer_ttl"`
+	Peers     []Peer        `json:"peers"`
+}
+
+// Node is this instance's membership in the swarm.
+type Node struct {
+	opts   Options
+	nodeID string
+
+	mu    sync.RWMutex
+	peers map[string]*Peer // by node ID
+
+	nonceMu sync.Mutex
+	nonces  map[string]time.Time // signed nonces seen, by first use
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewNode validates opts and fills in defaults.
+func NewNode(opts Options) (*Node, error) {
+	if strings.TrimSpace(opts.Secret) == "" {
+		return nil, errors.New("swarm secret is required")
+	}
+	if opts.Name == "" {
+		host, err := os.Hostname()
+		if err != nil || host == "" {
+			host = "picoclaw"
+		}
+		opts.Name = host
+	}
+	if opts.DiscoveryPort <= 0 {
+		opts.DiscoveryPort = DefaultDiscoveryPort
+	}
+	if len(opts.Announce) == 0 {
+		opts.Announce = []string{fmt.Sprintf("255.255.255.255:%d", opts.DiscoveryPort)}
+	}
+	if opts.AnnounceInterval <= 0 {
+		opts.AnnounceInterval = DefaultAnnounceInterval
+	}
+	if opts.TaskTimeout <= 0 {
+		opts.TaskTimeout = DefaultTaskTimeout
+	}
+	if opts.GatewayPort <= 0 && opts.AdvertiseURL == "" {
+		return nil, errors.New("swarm needs the gateway port or an advertise URL")
+	}
+	return &Node{
+		opts:   opts,
+		nodeID: uuid.NewString(),
+		peers:  make(map[string]*Peer),
+		nonces: make(map[string]time.Time),
+	}, nil
+}
+
+// Name returns the node's name.
+func (n *Node) Name() string {
+	return n.opts.Name
+}
+
+// peerTTL is how long a peer counts as online after its last announcement.
+func (n *Node) peerTTL() time.Duration {
+	return 3 * n.opts.AnnounceInterval
+}
+
+// Peers returns the online peers sorted by name.
+func (n *Node) Peers() []Peer {
+	now := time.Now()
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	peers := make([]Peer, 0, len(n.peers))
+	for _, p := range n.peers {
+		if p.Online(now, n.peerTTL()) {
+			peers = append(peers, *p)
+		}
+	}
+	sortPeers(peers)
+	return peers
+}
+
+// Peer returns the online peer called name, matched case-insensitively
+// against the node name or ID.
+func (n *Node) Peer(name string) (Peer, bool) {
+	var found *Peer
+	for _, p := range n.Peers() {
+		if strings.EqualFold(p.Name, name) || p.NodeID == name {
+			if found == nil || p.LastSeen.After(found.LastSeen) {
+				found = &p
+			}
+		}
+	}
+	if found == nil {
+		return Peer{}, false
+	}
+	return *found, true
+}
+
+func (n *Node) upsertPeer(p Peer) {
+	n.mu.Lock()
+	_, known := n.peers[p.NodeID]
+	n.peers[p.NodeID] = &p
+	n.mu.Unlock()
+	if !known {
+		logger.InfoCF("swarm", "Peer discovered",
+			map[string]any{"peer": p.Name, "url": p.URL, "agents": p.Agents})
+	}
+}
+
+// prune forgets peers that have been silent for a long time.
+func (n *Node) prune(now time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for id, p := range n.peers {
+		if now.Sub(p.LastSeen) > forgetAfter {
+			delete(n.peers, id)
+		}
+	}
+}
+
+// writeState records every known peer, online or not, for `picoclaw status`.
+func (n *Node) writeState(now time.Time) {
+	if n.opts.StatePath == "" {
+		return
+	}
+	n.mu.RLock()
+	st := State{Node: n.opts.Name, UpdatedAt: now, PeerTTL: n.peerTTL(), Peers: make([]Peer, 0, len(n.peers))}
+	for _, p := range n.peers {
+		st.Peers = append(st.Peers, *p)
+	}
+	n.mu.RUnlock()
+	sortPeers(st.Peers)
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := fileutil.WriteFileAtomic(n.opts.StatePath, data, 0o600); err != nil {
+		logger.WarnCF("swarm", "Failed to write swarm state",
+			map[string]any{"path": n.opts.StatePath, "error": err.Error()})
+	}
+}
+
+// LoadState reads the state file written by a running gateway.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("invalid swarm state %s: %w", filepath.Base(path), err)
+	}
+	return &st, nil
+}
+
+func sortPeers(peers []Peer) {
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Name != peers[j].Name {
+			return peers[i].Name < peers[j].Name
+		}
+		return peers[i].NodeID < peers[j].NodeID
+	})
+}
+
+type hopKey struct{}
+
+// withHops marks ctx as running a task that has already been delegated
+// hops times.
+func withHops(ctx context.Context, hops int) context.Context {
+	return context.WithValue(ctx, hopKey{}, hops)
+}
+
+func hopsFromContext(ctx context.Context) int {
+	hops, _ := ctx.Value(hopKey{}).(int)
+	return hops
+}
@@ -0,0 +1,242 @@
+package swarm
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/channels/pico"
+)
+
+func freeUDPPort(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// startNode starts a node whose peer WebSocket is served by an httptest
+// server and whose announcements go to every port in announce.
+func startNode(t *testing.T, name string, port int, announce []string, run RunFunc) *Node {
+	t.Helper()
+	var node *Node
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	node, err := NewNode(Options{
+		Name:             name,
+		Secret:           "s3cret",
+		DiscoveryPort:    port,
+		Announce:         announce,
+		AnnounceInterval: 50 * time.Millisecond,
+		AdvertiseURL:     "ws" + strings.TrimPrefix(srv.URL, "http") + WSPath,
+		StatePath:        filepath.Join(t.TempDir(), StateFile),
+		Info:             func() ([]string, []string) { return []string{"main"}, []string{"read_file"} },
+		Run:              run,
+	})
+	if err != nil {
+		t.Fatalf("NewNode() error = %v", err)
+	}
+	if err := node.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(node.Stop)
+	return node
+}
+
+func waitForPeer(t *testing.T, n *Node, name string) Peer {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if p, ok := n.Peer(name); ok {
+			return p
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("%s never discovered peer %s", n.Name(), name)
+	return Peer{}
+}
+
+func TestNodesDiscoverAndDelegate(t *testing.T) {
+	portA, portB := freeUDPPort(t), freeUDPPort(t)
+	announce := []string{
+		"127.0.0.1:" + strconv.Itoa(portA),
+		"127.0.0.1:" + strconv.Itoa(portB),
+	}
+	a := startNode(t, "server", portA, announce, nil)
+	startNode(t, "camera", portB, announce, func(ctx context.Context, task Task) (string, error) {
+		return task.Peer + " asked " + task.AgentID + ": " + task.Content, nil
+	})
+
+	peer := waitForPeer(t, a, "Camera")
+	if len(peer.Agents) != 1 || peer.Agents[0] != "main" || peer.Tools[0] != "read_file" {
+		t.Fatalf("peer = %+v", peer)
+	}
+	if _, ok := a.Peer("server"); ok {
+		t.Fatal("node listed itself as a peer")
+	}
+
+	got, err := a.Delegate(context.Background(), "camera", "vision", "take a photo")
+	if err != nil {
+		t.Fatalf("Delegate() error = %v", err)
+	}
+	if got != "server asked vision: take a photo" {
+		t.Fatalf("Delegate() = %q", got)
+	}
+
+	if _, err := a.Delegate(withHops(context.Background(), maxHops), "camera", "", "loop"); err == nil {
+		t.Fatal("Delegate() beyond the hop limit succeeded")
+	}
+	if _, err := a.Delegate(context.Background(), "nobody", "", "hi"); err == nil {
+		t.Fatal("Delegate() to an unknown peer succeeded")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	st, err := LoadState(a.opts.StatePath)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if st.Node != "server" || len(st.Peers) != 1 || st.Peers[0].Name != "camera" {
+		t.Fatalf("state = %+v", st)
+	}
+}
+
+func TestDelegateReportsPeerError(t *testing.T) {
+	portA, portB := freeUDPPort(t), freeUDPPort(t)
+	announce := []string{"127.0.0.1:" + strconv.Itoa(portA), "127.0.0.1:" + strconv.Itoa(portB)}
+	a := startNode(t, "a", portA, announce, nil)
+	startNode(t, "b", portB, announce, func(ctx context.Context, task Task) (string, error) {
+		return "", context.DeadlineExceeded
+	})
+	waitForPeer(t, a, "b")
+
+	_, err := a.Delegate(context.Background(), "b", "", "slow task")
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Fatalf("Delegate() error = %v", err)
+	}
+}
+
+func TestDecodeAnnouncement(t *testing.T) {
+	now := time.Now()
+	a := announcement{NodeID: "id", Name: "n", URL: "ws://10.0.0.2:18790" + WSPath, Time: now.Unix(), Nonce: "1"}
+	data, err := encodeAnnouncement("secret", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeAnnouncement("secret", data, now); err != nil {
+		t.Fatalf("decodeAnnouncement() error = %v", err)
+	}
+	if _, err := decodeAnnouncement("other", data, now); err == nil {
+		t.Fatal("announcement with another swarm's secret accepted")
+	}
+	if _, err := decodeAnnouncement("secret", data, now.Add(time.Hour)); err == nil {
+		t.Fatal("stale announcement accepted")
+	}
+
+	// The receiver no longer guesses the URL from the sender's address.
+	a.URL = ""
+	data, _ = encodeAnnouncement("secret", a)
+	if _, err := decodeAnnouncement("secret", data, now); err == nil {
+		t.Fatal("announcement without a URL accepted")
+	}
+}
+
+func TestReceiveIgnoresReplayedAnnouncements(t *testing.T) {
+	node, err := NewNode(Options{Secret: "secret", GatewayPort: 18790})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if !node.firstUse("announce\nid\n1", now) {
+		t.Fatal("first use of a nonce rejected")
+	}
+	if node.firstUse("announce\nid\n1", now.Add(time.Minute)) {
+		t.Fatal("replayed nonce accepted")
+	}
+	if !node.firstUse("announce\nid\n1", now.Add(nonceTTL+time.Minute)) {
+		t.Fatal("nonce not forgotten after its TTL")
+	}
+}
+
+func TestServeHTTPRequiresSignature(t *testing.T) {
+	node, err := NewNode(Options{Secret: "secret", GatewayPort: 18790, Run: func(context.Context, Task) (string, error) {
+		return "", nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, WSPath, nil)
+	rec := httptest.NewRecorder()
+	node.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned request: status = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, WSPath, nil)
+	req.Header = authHeader("wrong", "intruder", "n1", time.Now())
+	rec = httptest.NewRecorder()
+	node.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong secret: status = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, WSPath, nil)
+	req.Header = authHeader("secret", "peer", "n2", time.Now())
+	if peer, nonce, ok := node.authenticate(req, time.Now()); !ok || peer != "peer" || nonce != "n2" {
+		t.Fatalf("authenticate() = %q, %q, %v", peer, nonce, ok)
+	}
+	if _, _, ok := node.authenticate(req, time.Now()); ok {
+		t.Fatal("replayed connection headers accepted")
+	}
+}
+
+func TestDelegateRequiresPeerProof(t *testing.T) {
+	// An impostor at a replayed URL accepts the connection without the
+	// swarm secret; the task must never reach it.
+	received := make(chan string, 1)
+	impostor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, http.Header{headerNode: {"camera"}})
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		var msg pico.PicoMessage
+		if ws.ReadJSON(&msg) == nil {
+			received <- msg.Payload["content"].(string)
+		}
+	}))
+	defer impostor.Close()
+
+	node, err := NewNode(Options{Secret: "secret", GatewayPort: 18790})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.upsertPeer(Peer{
+		Name:     "camera",
+		NodeID:   "id",
+		URL:      "ws" + strings.TrimPrefix(impostor.URL, "http") + WSPath,
+		LastSeen: time.Now(),
+	})
+
+	_, err = node.Delegate(context.Background(), "camera", "", "the door code is 1234")
+	if err == nil || !strings.Contains(err.Error(), "could not prove") {
+		t.Fatalf("Delegate() error = %v", err)
+	}
+	select {
+	case task := <-received:
+		t.Fatalf("impostor received the task %q", task)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
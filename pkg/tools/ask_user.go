@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+const (
+	maxAskUserOptions = 10
+	maxAskUserTimeout = time.Hour
+)
+
+// AskUserSender delivers a question with its choices to a chat, as buttons
+// where the channel supports them and as a numbered list otherwise.
+type AskUserSender func(ctx context.Context, msg bus.InteractiveMessage) error
+
+// AskUserTool asks the user a multiple-choice question and blocks the turn
+// until they answer or the question times out. Answers arrive as inbound
+// messages, either a button press carrying the prompt's ID or a typed
+// number or label, and are taken off the bus before the agent loop sees
+// them. Only the sender whose message started the turn can answer; turns
+// without a sender, such as scheduled ones, accept an answer from the chat.
+type AskUserTool struct {
+	bus     *bus.MessageBus
+	send    AskUserSender
+	timeout time.Duration
+}
+
+func NewAskUserTool(msgBus *bus.MessageBus, send AskUserSender, timeout time.Duration) *AskUserTool {
+	return &AskUserTool{bus: msgBus, send: send, timeout: timeout}
+}
+
+func (t *AskUserTool) Name() string {
+	return "ask_user"
+}
+
+func (t *AskUserTool) Description() string {
+	return "Ask the user a question with a fixed set of answers (e.g. Yes / No / Later) and wait for their choice. " +
+		"The options are shown as buttons where the chat supports them. Use this for confirmations and short " +
+		"decisions; ask open questions in your reply instead."
+}
+
+func (t *AskUserTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"question": map[string]any{
+				"type":        "string",
+				"description": "The question to ask",
+			},
+			"options": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": fmt.Sprintf("The answers to choose from, 2 to %d short labels", maxAskUserOptions),
+			},
+			"timeout_seconds": map[string]any{
+				"type":        "integer",
+				"description": "Optional: how long to wait for an answer",
+			},
+		},
+		"required": []string{"question", "options"},
+	}
+}
+
+func (t *AskUserTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if t.bus == nil || t.send == nil {
+		return ErrorResult("ask_user is not available")
+	}
+
+	question, _ := args["question"].(string)
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return ErrorResult("question is required")
+	}
+	rawOptions, _ := args["options"].([]any)
+	var actions []bus.Action
+	for _, o := range rawOptions {
+		label, _ := o.(string)
+		if label = strings.TrimSpace(label); label != "" {
+			actions = append(actions, bus.Action{ID: strconv.Itoa(len(actions) + 1), Label: label})
+		}
+	}
+	if len(actions) < 2 || len(actions) > maxAskUserOptions {
+		return ErrorResult(fmt.Sprintf("options must contain 2 to %d labels", maxAskUserOptions))
+	}
+
+	timeout := t.timeout
+	if secs, ok := args["timeout_seconds"].(float64); ok && secs > 0 {
+		timeout = min(time.Duration(secs)*time.Second, maxAskUserTimeout)
+	}
+
+	channel, chatID, sender := ToolChannel(ctx), ToolChatID(ctx), ToolSenderID(ctx)
+	if channel == "" || chatID == "" {
+		return ErrorResult("no chat to ask in")
+	}
+
+	prompt := bus.InteractiveMessage{
+		Channel:  channel,
+		ChatID:   chatID,
+		Content:  question,
+		PromptID: newPromptID(),
+		Actions:  actions,
+	}
+
+	answers := make(chan bus.Action, 1)
+	var answered atomic.Bool
+	remove := t.bus.AddInboundInterceptor(func(msg bus.InboundMessage) bool {
+		// Only the user who was asked may answer; in a group, anyone else's
+		// message goes to the agent as usual.
+		if msg.Channel != channel || msg.ChatID != chatID || (sender != "" && msg.SenderID != sender) {
+			return false
+		}
+		var action bus.Action
+		switch {
+		case msg.PromptID == prompt.PromptID:
+			for _, a := range actions {
+				if a.ID == msg.ActionID {
+					action = a
+				}
+			}
+			if action.ID == "" || !answered.CompareAndSwap(false, true) {
+				return true // stale or repeated press
+			}
+		case msg.PromptID == "":
+			a, ok := prompt.MatchReply(msg.Content)
+			if !ok || !answered.CompareAndSwap(false, true) {
+				return false
+			}
+			action = a
+		default:
+			return false
+		}
+		answers <- action
+		return true
+	})
+	defer remove()
+
+	if err := t.send(ctx, prompt); err != nil {
+		return ErrorResult(fmt.Sprintf("sending question: %v", err)).WithError(err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case action := <-answers:
+		return NewToolResult(fmt.Sprintf("The user chose %q.", action.Label))
+	case <-timer.C:
+		return NewToolResult(fmt.Sprintf("The user did not answer within %s.", timeout))
+	case <-ctx.Done():
+		return ErrorResult("question canceled").WithError(ctx.Err())
+	}
+}
+
+func newPromptID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
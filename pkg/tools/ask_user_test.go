@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func askUserArgs() map[string]any {
+	return map[string]any{"question": "Deploy now?", "options": []any{"Yes", "No", "Later"}}
+}
+
+func TestAskUserTool_ButtonAnswer(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	tool := NewAskUserTool(mb, func(ctx context.Context, msg bus.InteractiveMessage) error {
+		if msg.Content != "Deploy now?" || len(msg.Actions) != 3 || msg.Actions[2].Label != "Later" {
+			t.Errorf("unexpected prompt: %+v", msg)
+		}
+		go func() {
+			// Another chat's button press and a stale prompt are not answers.
+			mb.PublishInbound(ctx, bus.InboundMessage{Channel: "telegram", ChatID: "other", PromptID: msg.PromptID})
+			mb.PublishInbound(ctx, bus.InboundMessage{
+				Channel: "telegram", ChatID: "chat-1", PromptID: "stale", ActionID: "1",
+			})
+			mb.PublishInbound(ctx, bus.InboundMessage{
+				Channel: "telegram", ChatID: "chat-1", PromptID: msg.PromptID, ActionID: "2", Content: "No",
+			})
+		}()
+		return nil
+	}, time.Minute)
+
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+	result := tool.Execute(ctx, askUserArgs())
+	if result.IsError || !strings.Contains(result.ForLLM, `"No"`) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// Messages that were not the answer still reach the agent loop.
+	for range 2 {
+		select {
+		case <-mb.InboundChan():
+		case <-time.After(time.Second):
+			t.Fatal("expected unrelated messages to pass through")
+		}
+	}
+}
+
+func TestAskUserTool_TypedAnswer(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	tool := NewAskUserTool(mb, func(ctx context.Context, msg bus.InteractiveMessage) error {
+		go mb.PublishInbound(ctx, bus.InboundMessage{Channel: "irc", ChatID: "#ops", Content: " 3 "})
+		return nil
+	}, time.Minute)
+
+	ctx := WithToolContext(context.Background(), "irc", "#ops")
+	result := tool.Execute(ctx, askUserArgs())
+	if result.IsError || !strings.Contains(result.ForLLM, `"Later"`) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestAskUserTool_OnlyAskedSenderAnswers(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	tool := NewAskUserTool(mb, func(ctx context.Context, msg bus.InteractiveMessage) error {
+		go func() {
+			// Another member of the group can neither press nor type an answer.
+			mb.PublishInbound(ctx, bus.InboundMessage{
+				Channel: "telegram", ChatID: "group", SenderID: "bob", PromptID: msg.PromptID, ActionID: "1",
+			})
+			mb.PublishInbound(ctx, bus.InboundMessage{
+				Channel: "telegram", ChatID: "group", SenderID: "bob", Content: "Yes",
+			})
+			mb.PublishInbound(ctx, bus.InboundMessage{
+				Channel: "telegram", ChatID: "group", SenderID: "alice", Content: "2",
+			})
+		}()
+		return nil
+	}, time.Minute)
+
+	ctx := WithToolSender(WithToolContext(context.Background(), "telegram", "group"), "alice")
+	result := tool.Execute(ctx, askUserArgs())
+	if result.IsError || !strings.Contains(result.ForLLM, `"No"`) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for range 2 {
+		select {
+		case msg := <-mb.InboundChan():
+			if msg.SenderID != "bob" {
+				t.Fatalf("unexpected message passed through: %+v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected bob's messages to pass through")
+		}
+	}
+}
+
+func TestAskUserTool_Timeout(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	tool := NewAskUserTool(mb, func(context.Context, bus.InteractiveMessage) error { return nil }, 20*time.Millisecond)
+	ctx := WithToolContext(context.Background(), "irc", "#ops")
+	result := tool.Execute(ctx, askUserArgs())
+	if result.IsError || !strings.Contains(result.ForLLM, "did not answer") {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestAskUserTool_InvalidArgs(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	tool := NewAskUserTool(mb, func(context.Context, bus.InteractiveMessage) error { return nil }, time.Minute)
+	ctx := WithToolContext(context.Background(), "irc", "#ops")
+
+	if r := tool.Execute(ctx, map[string]any{"question": "Ok?", "options": []any{"Yes"}}); !r.IsError {
+		t.Fatal("expected an error for a single option")
+	}
+	if r := tool.Execute(context.Background(), askUserArgs()); !r.IsError {
+		t.Fatal("expected an error without a chat")
+	}
+}
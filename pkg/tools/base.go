@@ -25,6 +25,7 @@ var (
 	ctxKeyChatID           = &toolCtxKey{"chatID"}
 	ctxKeyMessageID        = &toolCtxKey{"messageID"}
 	ctxKeyReplyToMessageID = &toolCtxKey{"replyToMessageID"}
+	ctxKeySenderID         = &toolCtxKey{"senderID"}
 )
 
 // WithToolContext returns a child context carrying channel and chatID.
@@ -41,6 +42,12 @@ func WithToolMessageContext(ctx context.Context, messageID, replyToMessageID str
 	return ctx
 }
 
+// WithToolSender returns a child context carrying the ID of the user whose
+// message started the turn.
+func WithToolSender(ctx context.Context, senderID string) context.Context {
+	return context.WithValue(ctx, ctxKeySenderID, senderID)
+}
+
 // WithToolInboundContext returns a child context carrying channel/chat and inbound IDs.
 func WithToolInboundContext(
 	ctx context.Context,
@@ -75,6 +82,13 @@ func ToolReplyToMessageID(ctx context.Context) string {
 	return v
 }
 
+// ToolSenderID extracts the sender of the current inbound message from ctx,
+// or "" if unset.
+func ToolSenderID(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKeySenderID).(string)
+	return v
+}
+
 // AsyncCallback is a function type that async tools use to notify completion.
 // When an async tool finishes its work, it calls this callback with the result.
 //
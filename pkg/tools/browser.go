@@ -0,0 +1,398 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/browser"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const browserMaxChars = 50000
+
+// BrowserOptions configures the shared browser behind BrowserTool.
+type BrowserOptions struct {
+	CDPURL         string
+	ExecutablePath string
+	Proxy          string
+	IdleTimeout    time.Duration
+	// AllowedDomains limits pages to these domains and their subdomains;
+	// empty allows every public domain. DeniedDomains always win.
+	AllowedDomains []string
+	DeniedDomains  []string
+	// PrivateHostWhitelist is shared with web_fetch.
+	PrivateHostWhitelist []string
+}
+
+// NewBrowser returns a browser whose pages may only reach URLs allowed by
+// the web_fetch private host rules and the configured domain lists. Every
+// request a page makes is checked, not just the URLs the model navigates to.
+func NewBrowser(opts BrowserOptions) (*browser.Browser, error) {
+	whitelist, err := newPrivateHostWhitelist(opts.PrivateHostWhitelist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse web private host whitelist: %w", err)
+	}
+	policy := &browserPolicy{
+		allowed:   normalizeDomains(opts.AllowedDomains),
+		denied:    normalizeDomains(opts.DeniedDomains),
+		whitelist: whitelist,
+	}
+	return browser.New(browser.Options{
+		CDPURL:         opts.CDPURL,
+		ExecutablePath: opts.ExecutablePath,
+		Proxy:          opts.Proxy,
+		IdleTimeout:    opts.IdleTimeout,
+		AllowURL:       policy.check,
+	}), nil
+}
+
+type browserPolicy struct {
+	allowed   []string
+	denied    []string
+	whitelist *privateHostWhitelist
+	lookup    func(ctx context.Context, host string) ([]net.IPAddr, error) // nil uses the default resolver
+}
+
+func (p *browserPolicy) check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	switch u.Scheme {
+	case "about", "data", "blob":
+		return nil
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("scheme %q is not allowed", u.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if matchDomain(host, p.denied) {
+		return fmt.Errorf("domain %s is denied", host)
+	}
+	if len(p.allowed) > 0 && !matchDomain(host, p.allowed) {
+		return fmt.Errorf("domain %s is not in allowed_domains", host)
+	}
+	if isObviousPrivateHost(host, p.whitelist) {
+		return fmt.Errorf("private or local network host %s is not allowed", host)
+	}
+	if allowPrivateWebFetchHosts.Load() || net.ParseIP(host) != nil {
+		return nil
+	}
+
+	// Chromium resolves and connects on its own, so unlike web_fetch the
+	// address cannot be pinned; refuse hosts with any private address.
+	lookup := p.lookup
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupIPAddr
+	}
+	addrs, err := lookup(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if shouldBlockPrivateIP(addr.IP, p.whitelist) {
+			return fmt.Errorf("%s resolves to private or local address %s", host, addr.IP)
+		}
+	}
+	return nil
+}
+
+func normalizeDomains(domains []string) []string {
+	out := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		d = strings.TrimPrefix(strings.TrimPrefix(d, "*"), ".")
+		if d != "" {
+			out = append(out, strings.TrimSuffix(d, "."))
+		}
+	}
+	return out
+}
+
+// matchDomain reports whether host is one of domains or a subdomain of one.
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// BrowserTool drives a headless browser for pages web_fetch cannot handle:
+// JavaScript-rendered content, logins and forms. Each chat keeps its own
+// page, so cookies survive between calls until the page is closed or idle.
+type BrowserTool struct {
+	browser    *browser.Browser
+	timeout    time.Duration
+	mediaStore media.MediaStore
+}
+
+func NewBrowserTool(b *browser.Browser, timeout time.Duration) *BrowserTool {
+	if timeout <= 0 {
+		timeout = browser.DefaultTimeout
+	}
+	return &BrowserTool{browser: b, timeout: timeout}
+}
+
+func (t *BrowserTool) Name() string { return "browser" }
+
+func (t *BrowserTool) Description() string {
+	return "Control a headless web browser for JavaScript-heavy pages, logins and forms. " +
+		"Typical flow: navigate, snapshot to get element refs, then click or type using a ref, " +
+		"and snapshot again to see the result. The page and its cookies persist for this chat until closed. " +
+		"Prefer web_fetch for static pages."
+}
+
+func (t *BrowserTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"navigate", "snapshot", "click", "type", "screenshot", "wait", "close"},
+				"description": "What to do on the chat's page",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "URL to open (navigate)",
+			},
+			"format": map[string]any{
+				"type":        "string",
+				"enum":        []string{"accessibility", "markdown"},
+				"description": "Snapshot format: accessibility tree with element refs (default) or page text as markdown",
+			},
+			"ref": map[string]any{
+				"type":        "string",
+				"description": "Element ref from the latest accessibility snapshot, e.g. e12 (click, type)",
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "Text to enter (type), or text to wait for (wait)",
+			},
+			"submit": map[string]any{
+				"type":        "boolean",
+				"description": "Press Enter after typing",
+			},
+			"selector": map[string]any{
+				"type":        "string",
+				"description": "CSS selector to wait for (wait)",
+			},
+			"url_contains": map[string]any{
+				"type":        "string",
+				"description": "Wait until the page URL contains this (wait)",
+			},
+			"full_page": map[string]any{
+				"type":        "boolean",
+				"description": "Capture the whole page instead of the viewport (screenshot)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *BrowserTool) SetMediaStore(store media.MediaStore) {
+	t.mediaStore = store
+}
+
+func (t *BrowserTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, _ := args["action"].(string)
+	channel, chatID := ToolChannel(ctx), ToolChatID(ctx)
+	if channel == "" || chatID == "" {
+		return ErrorResult("no target channel/chat available")
+	}
+	key := channel + ":" + chatID
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	if action == "close" {
+		if t.browser.ClosePage(ctx, key) {
+			return SilentResult("Browser page closed; cookies and storage for this chat were discarded.")
+		}
+		return SilentResult("No browser page was open for this chat.")
+	}
+
+	var target string
+	if action == "navigate" {
+		target, _ = args["url"].(string)
+		target = strings.TrimSpace(target)
+		if target == "" {
+			return ErrorResult("url is required for navigate")
+		}
+		if !strings.Contains(target, "://") && !strings.HasPrefix(target, "about:") && !strings.HasPrefix(target, "data:") {
+			target = "https://" + target
+		}
+		if err := t.browser.CheckURL(ctx, target); err != nil {
+			return ErrorResult(fmt.Sprintf("navigation blocked: %v", err))
+		}
+	}
+
+	page, err := t.browser.Page(ctx, key)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("browser unavailable: %v", err)).WithError(err)
+	}
+	page.Lock()
+	defer page.Unlock()
+	page.TakeBlocked()
+
+	var result *ToolResult
+	switch action {
+	case "navigate":
+		result = t.navigate(ctx, page, target)
+	case "snapshot":
+		result = t.snapshot(ctx, page, args)
+	case "click":
+		ref, _ := args["ref"].(string)
+		if err := page.Click(ctx, ref); err != nil {
+			return ErrorResult(fmt.Sprintf("click failed: %v", err))
+		}
+		result = t.afterAction(ctx, page, "Clicked "+ref)
+	case "type":
+		ref, _ := args["ref"].(string)
+		text, _ := args["text"].(string)
+		submit, _ := args["submit"].(bool)
+		if err := page.Type(ctx, ref, text, submit); err != nil {
+			return ErrorResult(fmt.Sprintf("type failed: %v", err))
+		}
+		result = t.afterAction(ctx, page, fmt.Sprintf("Typed into %s", ref))
+	case "screenshot":
+		fullPage, _ := args["full_page"].(bool)
+		result = t.screenshot(ctx, page, channel, chatID, fullPage)
+	case "wait":
+		cond := browser.WaitCondition{}
+		cond.Selector, _ = args["selector"].(string)
+		cond.Text, _ = args["text"].(string)
+		cond.URL, _ = args["url_contains"].(string)
+		if err := page.Wait(ctx, cond); err != nil {
+			return ErrorResult(fmt.Sprintf("condition not met: %v", err))
+		}
+		result = t.afterAction(ctx, page, "Condition met")
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action %q", action))
+	}
+
+	if blocked := page.TakeBlocked(); len(blocked) > 0 && !result.IsError {
+		result.ForLLM += fmt.Sprintf("\n\nBlocked by egress policy (%d requests): %s",
+			len(blocked), strings.Join(firstN(blocked, 5), ", "))
+	}
+	return result
+}
+
+func (t *BrowserTool) navigate(ctx context.Context, page *browser.Page, target string) *ToolResult {
+	info, err := page.Navigate(ctx, target)
+	if err != nil {
+		if blocked := page.TakeBlocked(); len(blocked) > 0 {
+			return ErrorResult(fmt.Sprintf("navigation to %s blocked by egress policy: %s", target, blocked[0]))
+		}
+		return ErrorResult(fmt.Sprintf("navigation failed: %v", err))
+	}
+	return SilentResult(fmt.Sprintf("Opened %s\nTitle: %s\nUse snapshot to read the page and get element refs.",
+		info.URL, info.Title))
+}
+
+func (t *BrowserTool) snapshot(ctx context.Context, page *browser.Page, args map[string]any) *ToolResult {
+	info, err := page.Info(ctx)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("snapshot failed: %v", err))
+	}
+
+	var body string
+	format, _ := args["format"].(string)
+	switch format {
+	case "markdown":
+		html, err := page.HTML(ctx)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("snapshot failed: %v", err))
+		}
+		if body, err = utils.HtmlToMarkdown(html); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to convert page to markdown: %v", err))
+		}
+	case "", "accessibility":
+		if body, err = page.Snapshot(ctx); err != nil {
+			return ErrorResult(fmt.Sprintf("snapshot failed: %v", err))
+		}
+	default:
+		return ErrorResult(fmt.Sprintf("unknown snapshot format %q", format))
+	}
+
+	if len(body) > browserMaxChars {
+		body = body[:browserMaxChars] + "\n[truncated]"
+	}
+	return SilentResult(fmt.Sprintf("URL: %s\nTitle: %s\n\n%s", info.URL, info.Title, body))
+}
+
+func (t *BrowserTool) screenshot(
+	ctx context.Context,
+	page *browser.Page,
+	channel, chatID string,
+	fullPage bool,
+) *ToolResult {
+	if t.mediaStore == nil {
+		return ErrorResult("media store not configured")
+	}
+	data, err := page.Screenshot(ctx, fullPage)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("screenshot failed: %v", err))
+	}
+
+	if err := os.MkdirAll(media.TempDir(), 0o700); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create media temp dir: %v", err))
+	}
+	file, err := os.CreateTemp(media.TempDir(), "browser-*.png")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to create screenshot file: %v", err))
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return ErrorResult(fmt.Sprintf("failed to write screenshot: %v", err))
+	}
+
+	filename := fmt.Sprintf("screenshot-%d.png", time.Now().Unix())
+	ref, err := t.mediaStore.Store(file.Name(), media.MediaMeta{
+		Filename:      filename,
+		ContentType:   "image/png",
+		Source:        "tool:browser",
+		CleanupPolicy: media.CleanupPolicyDeleteOnCleanup,
+	}, fmt.Sprintf("tool:browser:%s:%s", channel, chatID))
+	if err != nil {
+		os.Remove(file.Name())
+		return ErrorResult(fmt.Sprintf("failed to register screenshot in media store: %v", err))
+	}
+
+	info, _ := page.Info(ctx)
+	return &ToolResult{
+		ForLLM: fmt.Sprintf("Screenshot of %s\n[image: %s]", info.URL, ref),
+		Silent: true,
+		Media:  []string{ref},
+	}
+}
+
+// afterAction reports where the page is after an interaction, since clicks
+// and submits often navigate.
+func (t *BrowserTool) afterAction(ctx context.Context, page *browser.Page, done string) *ToolResult {
+	info, err := page.Info(ctx)
+	if err != nil {
+		return SilentResult(done)
+	}
+	return SilentResult(fmt.Sprintf("%s. Page is now %s (%s). Take a snapshot to see the result.",
+		done, info.URL, info.Title))
+}
+
+func firstN(items []string, n int) []string {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
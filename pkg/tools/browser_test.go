@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+func TestBrowserPolicy(t *testing.T) {
+	whitelist, err := newPrivateHostWhitelist([]string{"10.0.0.5"})
+	require.NoError(t, err)
+	lookup := func(_ context.Context, host string) ([]net.IPAddr, error) {
+		if host == "intranet.example.com" {
+			return []net.IPAddr{{IP: net.ParseIP("192.168.1.10")}}, nil
+		}
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	}
+	policy := &browserPolicy{
+		allowed:   normalizeDomains([]string{"*.example.com", "10.0.0.5"}),
+		denied:    normalizeDomains([]string{"ads.example.com"}),
+		whitelist: whitelist,
+		lookup:    lookup,
+	}
+
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://example.com/", true},
+		{"https://shop.example.com/cart", true},
+		{"https://intranet.example.com/", false},
+		{"https://ads.example.com/pixel", false},
+		{"https://cdn.ads.example.com/x.js", false},
+		{"https://evil.example/", false},
+		{"https://notexample.com/", false},
+		{"http://10.0.0.5/", true},
+		{"file:///etc/passwd", false},
+		{"about:blank", true},
+		{"data:text/html,hi", true},
+	}
+	for _, tt := range tests {
+		err := policy.check(context.Background(), tt.url)
+		assert.Equal(t, tt.allowed, err == nil, "%s: %v", tt.url, err)
+	}
+
+	open := &browserPolicy{lookup: lookup}
+	for _, u := range []string{"http://localhost:8080/", "http://127.0.0.1/", "http://169.254.169.254/latest"} {
+		assert.Error(t, open.check(context.Background(), u), u)
+	}
+	assert.NoError(t, open.check(context.Background(), "https://93.184.216.34/"))
+}
+
+func TestBrowserToolValidation(t *testing.T) {
+	b, err := NewBrowser(BrowserOptions{
+		CDPURL:         "ws://127.0.0.1:1/unused",
+		AllowedDomains: []string{"example.com"},
+	})
+	require.NoError(t, err)
+	defer b.Close()
+	tool := NewBrowserTool(b, 0)
+
+	result := tool.Execute(context.Background(), map[string]any{"action": "snapshot"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "no target channel/chat")
+
+	ctx := WithToolContext(context.Background(), "telegram", "42")
+	result = tool.Execute(ctx, map[string]any{"action": "navigate"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "url is required")
+
+	result = tool.Execute(ctx, map[string]any{"action": "navigate", "url": "evil.example/login"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "navigation blocked: domain evil.example is not in allowed_domains")
+
+	result = tool.Execute(ctx, map[string]any{"action": "close"})
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "No browser page was open")
+}
+
+func TestBrowserToolScreenshot(t *testing.T) {
+	b, err := NewBrowser(BrowserOptions{CDPURL: newScreenshotCDP(t)})
+	require.NoError(t, err)
+	defer b.Close()
+	tool := NewBrowserTool(b, 0)
+	store := media.NewFileMediaStore()
+	tool.SetMediaStore(store)
+
+	ctx := WithToolContext(context.Background(), "telegram", "42")
+	result := tool.Execute(ctx, map[string]any{"action": "screenshot"})
+	require.False(t, result.IsError, result.ForLLM)
+	require.Len(t, result.Media, 1)
+	assert.Contains(t, result.ForLLM, "[image: "+result.Media[0]+"]")
+
+	path, meta, err := store.ResolveWithMeta(result.Media[0])
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", meta.ContentType)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "\x89PNG", string(data))
+	require.NoError(t, store.ReleaseAll("tool:browser:telegram:42"))
+}
+
+// newScreenshotCDP serves a DevTools WebSocket whose only page shows
+// https://example.com/ and returns a tiny PNG for screenshots.
+func newScreenshotCDP(t *testing.T) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		var writeMu sync.Mutex
+		for {
+			var msg struct {
+				ID        int64          `json:"id"`
+				Method    string         `json:"method"`
+				Params    map[string]any `json:"params"`
+				SessionID string         `json:"sessionId"`
+			}
+			if err := ws.ReadJSON(&msg); err != nil {
+				return
+			}
+			result := map[string]any{}
+			switch msg.Method {
+			case "Target.createBrowserContext":
+				result["browserContextId"] = "ctx"
+			case "Target.createTarget":
+				result["targetId"] = "target"
+			case "Target.attachToTarget":
+				result["sessionId"] = "session"
+			case "Runtime.evaluate":
+				var value any = true
+				if strings.Contains(msg.Params["expression"].(string), "title") {
+					value = map[string]any{"url": "https://example.com/", "title": "Example"}
+				}
+				result["result"] = map[string]any{"value": value}
+			case "Page.captureScreenshot":
+				result["data"] = base64.StdEncoding.EncodeToString([]byte("\x89PNG"))
+			}
+			raw, _ := json.Marshal(map[string]any{"id": msg.ID, "sessionId": msg.SessionID, "result": result})
+			writeMu.Lock()
+			_ = ws.WriteMessage(websocket.TextMessage, raw)
+			writeMu.Unlock()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
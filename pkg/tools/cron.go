@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,6 +22,12 @@ type JobExecutor interface {
 	PublishResponseIfNeeded(ctx context.Context, channel, chatID, response string)
 }
 
+// WorkflowRunner is implemented by executors that can run workspace
+// workflows for cron jobs.
+type WorkflowRunner interface {
+	RunWorkflowDirect(ctx context.Context, name, input, channel, chatID string) (string, error)
+}
+
 // CronTool provides scheduling capabilities for the agent
 type CronTool struct {
 	cronService  *cron.CronService
@@ -29,6 +36,7 @@ type CronTool struct {
 	execTool     *ExecTool
 	allowCommand bool
 	execEnabled  bool
+	timezone     string
 }
 
 // NewCronTool creates a new CronTool
@@ -39,9 +47,14 @@ func NewCronTool(
 ) (*CronTool, error) {
 	allowCommand := true
 	execEnabled := true
+	timezone := ""
 	if config != nil {
 		allowCommand = config.Tools.Cron.AllowCommand
 		execEnabled = config.Tools.Exec.Enabled
+		timezone = config.Tools.Cron.Timezone
+	}
+	if _, err := cron.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid cron timezone %q: %w", timezone, err)
 	}
 
 	var execTool *ExecTool
@@ -63,6 +76,7 @@ func NewCronTool(
 		execTool:     execTool,
 		allowCommand: allowCommand,
 		execEnabled:  execEnabled,
+		timezone:     timezone,
 	}, nil
 }
 
@@ -73,7 +87,7 @@ func (t *CronTool) Name() string {
 
 // Description returns the tool description
 func (t *CronTool) Description() string {
-	return "Schedule reminders, tasks, or system commands. IMPORTANT: When user asks to be reminded or scheduled, you MUST call this tool. Use 'at_seconds' for one-time reminders (e.g., 'remind me in 10 minutes' → at_seconds=600). Use 'every_seconds' ONLY for recurring tasks (e.g., 'every 2 hours' → every_seconds=7200). Use 'cron_expr' for complex recurring schedules. Use 'command' to execute shell commands directly."
+	return "Schedule reminders, tasks, or system commands. IMPORTANT: When user asks to be reminded or scheduled, you MUST call this tool. Prefer 'schedule' with the user's wording in English (e.g., 'in 10 minutes', 'every weekday at 8:30', 'first monday of each month at 9am'). Otherwise use 'at_seconds' for one-time reminders, 'every_seconds' for fixed intervals, or 'cron_expr' for anything 'schedule' cannot express. Use 'command' to execute shell commands directly."
 }
 
 // Parameters returns the tool parameters schema
@@ -83,8 +97,8 @@ func (t *CronTool) Parameters() map[string]any {
 		"properties": map[string]any{
 			"action": map[string]any{
 				"type":        "string",
-				"enum":        []string{"add", "list", "remove", "enable", "disable"},
-				"description": "Action to perform. Use 'add' when user wants to schedule a reminder or task.",
+				"enum":        []string{"add", "list", "remove", "enable", "disable", "history", "chain"},
+				"description": "Action to perform. Use 'add' when user wants to schedule a reminder or task. Use 'history' to see past runs of a job. Use 'chain' to run target_job_id after job_id finishes, optionally only when a condition on its output holds.",
 			},
 			"message": map[string]any{
 				"type":        "string",
@@ -94,10 +108,22 @@ func (t *CronTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Optional: Shell command to execute directly (e.g., 'df -h'). If set, the agent will run this command and report output instead of just showing the message.",
 			},
+			"workflow": map[string]any{
+				"type":        "string",
+				"description": "Optional for add: name of a workflow from the workspace 'workflows' directory to run; 'message' becomes the workflow input.",
+			},
 			"command_confirm": map[string]any{
 				"type":        "boolean",
 				"description": "Optional explicit confirmation flag for scheduling a shell command. Command execution must also be enabled via tools.cron.allow_command.",
 			},
+			"schedule": map[string]any{
+				"type":        "string",
+				"description": "When to run, in plain English: 'in 20 minutes', 'tomorrow at 9am', 'every 2 hours', 'every weekday at 8:30', 'every monday and friday at 18:00', 'the 15th of each month', 'last day of the month', 'first monday of each month'. Takes precedence over at_seconds, every_seconds and cron_expr.",
+			},
+			"timezone": map[string]any{
+				"type":        "string",
+				"description": "Optional IANA timezone of the user (e.g. 'Asia/Shanghai') used for wall-clock times in 'schedule' and 'cron_expr'. Defaults to the configured timezone.",
+			},
 			"at_seconds": map[string]any{
 				"type":        "integer",
 				"description": "One-time reminder: seconds from now when to trigger (e.g., 600 for 10 minutes later). Use this for one-time reminders like 'remind me in 10 minutes'.",
@@ -110,9 +136,57 @@ func (t *CronTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Cron expression for complex recurring schedules (e.g., '0 9 * * *' for daily at 9am). Use this for complex recurring schedules.",
 			},
+			"misfire": map[string]any{
+				"type":        "string",
+				"enum":        []string{cron.MisfireSkip, cron.MisfireRunOnce, cron.MisfireRunAll},
+				"description": "Optional: what to do with runs missed while the device was off. 'skip' (default), 'run_once' catches up with a single run, 'run_all' replays every missed run.",
+			},
+			"retries": map[string]any{
+				"type":        "integer",
+				"description": "Optional: how many times to retry a failed run, with exponential backoff.",
+			},
+			"triggered_only": map[string]any{
+				"type":        "boolean",
+				"description": "Optional for add: the job has no schedule and only runs when chained from another job.",
+			},
+			"silent": map[string]any{
+				"type":        "boolean",
+				"description": "Optional for add: do not send the job's output to the chat; use for jobs that only feed a chain condition.",
+			},
 			"job_id": map[string]any{
 				"type":        "string",
-				"description": "Job ID (for remove/enable/disable)",
+				"description": "Job ID (for remove/enable/disable/history, or the source job for chain)",
+			},
+			"target_job_id": map[string]any{
+				"type":        "string",
+				"description": "For chain: the job to run after job_id finishes.",
+			},
+			"on": map[string]any{
+				"type":        "string",
+				"enum":        []string{cron.ActionOnSuccess, cron.ActionOnFailure, cron.ActionOnAlways},
+				"description": "For chain: which outcome of job_id triggers the target (default success).",
+			},
+			"pass_output": map[string]any{
+				"type":        "boolean",
+				"description": "For chain: give job_id's output to the target job as input.",
+			},
+			"condition": map[string]any{
+				"type":        "object",
+				"description": "For chain: only trigger when this holds for job_id's output. kind 'regex' uses pattern; kind 'json' uses path (e.g. 'daily.rain[0]') with optional op (==, !=, >, >=, <, <=, contains) and value; kind 'llm' asks a yes/no question.",
+				"properties": map[string]any{
+					"kind":     map[string]any{"type": "string", "enum": []string{cron.ConditionRegex, cron.ConditionJSON, cron.ConditionLLM}},
+					"pattern":  map[string]any{"type": "string"},
+					"path":     map[string]any{"type": "string"},
+					"op":       map[string]any{"type": "string"},
+					"value":    map[string]any{"type": "string"},
+					"question": map[string]any{"type": "string"},
+					"negate":   map[string]any{"type": "boolean"},
+				},
+				"required": []string{"kind"},
+			},
+			"clear": map[string]any{
+				"type":        "boolean",
+				"description": "For chain: remove all chained jobs from job_id instead of adding one.",
 			},
 		},
 		"required": []string{"action"},
@@ -137,6 +211,10 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 		return t.enableJob(args, true)
 	case "disable":
 		return t.enableJob(args, false)
+	case "history":
+		return t.jobHistory(args)
+	case "chain":
+		return t.chainJob(args)
 	default:
 		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
 	}
@@ -155,21 +233,42 @@ func (t *CronTool) addJob(ctx context.Context, args map[string]any) *ToolResult
 		return ErrorResult("message is required for add")
 	}
 
+	timezone, _ := args["timezone"].(string)
+	if timezone == "" {
+		timezone = t.timezone
+	}
+	loc, err := cron.LoadLocation(timezone)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("unknown timezone %q", timezone))
+	}
+
 	var schedule cron.CronSchedule
 
-	// Check for at_seconds (one-time), every_seconds (recurring), or cron_expr
+	// Check for schedule (natural language), at_seconds (one-time), every_seconds (recurring), or cron_expr
+	phrase, hasPhrase := args["schedule"].(string)
 	atSeconds, hasAt := args["at_seconds"].(float64)
 	everySeconds, hasEvery := args["every_seconds"].(float64)
 	cronExpr, hasCron := args["cron_expr"].(string)
 
 	// Fix: type assertions return true for zero values, need additional validity checks
 	// This prevents LLMs that fill unused optional parameters with defaults (0) from triggering wrong type
+	hasPhrase = hasPhrase && strings.TrimSpace(phrase) != ""
 	hasAt = hasAt && atSeconds > 0
 	hasEvery = hasEvery && everySeconds > 0
 	hasCron = hasCron && cronExpr != ""
 
-	// Priority: at_seconds > every_seconds > cron_expr
-	if hasAt {
+	triggeredOnly, _ := args["triggered_only"].(bool)
+
+	// Priority: triggered_only > schedule > at_seconds > every_seconds > cron_expr
+	if triggeredOnly {
+		schedule = cron.CronSchedule{Kind: cron.ScheduleManual}
+	} else if hasPhrase {
+		schedule, err = cron.ParseSchedule(phrase, time.Now(), loc)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf(
+				"cannot understand schedule: %v. Use at_seconds, every_seconds or cron_expr instead.", err))
+		}
+	} else if hasAt {
 		atMS := time.Now().UnixMilli() + int64(atSeconds)*1000
 		schedule = cron.CronSchedule{
 			Kind: "at",
@@ -185,9 +284,10 @@ func (t *CronTool) addJob(ctx context.Context, args map[string]any) *ToolResult
 		schedule = cron.CronSchedule{
 			Kind: "cron",
 			Expr: cronExpr,
+			TZ:   timezone,
 		}
 	} else {
-		return ErrorResult("one of at_seconds, every_seconds, or cron_expr is required")
+		return ErrorResult("one of schedule, at_seconds, every_seconds, cron_expr or triggered_only is required")
 	}
 
 	// GHSA-pv8c-p6jf-3fpp: command scheduling requires internal channel. When
@@ -207,6 +307,21 @@ func (t *CronTool) addJob(ctx context.Context, args map[string]any) *ToolResult
 		}
 	}
 
+	workflowName, _ := args["workflow"].(string)
+	workflowName = strings.TrimSpace(workflowName)
+	if workflowName != "" && command != "" {
+		return ErrorResult("set either command or workflow, not both")
+	}
+
+	misfire, _ := args["misfire"].(string)
+	if err := cron.ValidateMisfireMode(misfire); err != nil {
+		return ErrorResult(err.Error())
+	}
+	retries, _ := args["retries"].(float64)
+	if retries < 0 {
+		return ErrorResult("retries must not be negative")
+	}
+
 	// Truncate message for job name (max 30 chars)
 	messagePreview := utils.Truncate(message, 30)
 
@@ -227,11 +342,31 @@ func (t *CronTool) addJob(ctx context.Context, args map[string]any) *ToolResult
 		job.Payload.Command = command
 		needsUpdate = true
 	}
+	if workflowName != "" {
+		job.Payload.Workflow = workflowName
+		needsUpdate = true
+	}
+	if misfire != "" {
+		job.Misfire.Mode = misfire
+		needsUpdate = true
+	}
+	if retries > 0 {
+		job.Retry.MaxRetries = int(retries)
+		needsUpdate = true
+	}
+	if silent, _ := args["silent"].(bool); silent {
+		job.Payload.Silent = true
+		needsUpdate = true
+	}
 	if needsUpdate {
 		t.cronService.UpdateJob(job)
 	}
 
-	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s)", job.Name, job.ID))
+	nextRun := "none"
+	if job.State.NextRunAtMS != nil {
+		nextRun = time.UnixMilli(*job.State.NextRunAtMS).In(loc).Format("2006-01-02 15:04 MST")
+	}
+	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s, next run: %s)", job.Name, job.ID, nextRun))
 }
 
 func (t *CronTool) listJobs() *ToolResult {
@@ -249,12 +384,23 @@ func (t *CronTool) listJobs() *ToolResult {
 			scheduleInfo = fmt.Sprintf("every %ds", *j.Schedule.EveryMS/1000)
 		} else if j.Schedule.Kind == "cron" {
 			scheduleInfo = j.Schedule.Expr
+			if j.Schedule.TZ != "" {
+				scheduleInfo += " " + j.Schedule.TZ
+			}
 		} else if j.Schedule.Kind == "at" {
 			scheduleInfo = "one-time"
+		} else if j.Schedule.Kind == cron.ScheduleManual {
+			scheduleInfo = "triggered only"
 		} else {
 			scheduleInfo = "unknown"
 		}
 		result.WriteString(fmt.Sprintf("- %s (id: %s, %s)\n", j.Name, j.ID, scheduleInfo))
+		if j.Payload.Workflow != "" {
+			result.WriteString(fmt.Sprintf("  runs workflow %s\n", j.Payload.Workflow))
+		}
+		for _, a := range j.Payload.Then {
+			result.WriteString(fmt.Sprintf("  %s\n", cron.DescribeAction(a, "")))
+		}
 	}
 
 	return SilentResult(result.String())
@@ -290,8 +436,93 @@ func (t *CronTool) enableJob(args map[string]any, enable bool) *ToolResult {
 	return SilentResult(fmt.Sprintf("Cron job '%s' %s", job.Name, status))
 }
 
-// ExecuteJob executes a cron job through the agent
-func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
+func (t *CronTool) chainJob(args map[string]any) *ToolResult {
+	jobID, ok := args["job_id"].(string)
+	if !ok || jobID == "" {
+		return ErrorResult("job_id is required for chain")
+	}
+	job, ok := t.cronService.GetJob(jobID)
+	if !ok {
+		return ErrorResult(fmt.Sprintf("Job %s not found", jobID))
+	}
+
+	if clear, _ := args["clear"].(bool); clear {
+		if _, err := t.cronService.SetActions(jobID, nil); err != nil {
+			return ErrorResult(err.Error())
+		}
+		return SilentResult(fmt.Sprintf("Removed chained jobs from '%s'", job.Name))
+	}
+
+	targetID, _ := args["target_job_id"].(string)
+	on, _ := args["on"].(string)
+	passOutput, _ := args["pass_output"].(bool)
+	action := cron.CronAction{On: on, JobID: targetID, PassOutput: passOutput}
+
+	if raw, ok := args["condition"].(map[string]any); ok {
+		cond := &cron.CronCondition{}
+		cond.Kind, _ = raw["kind"].(string)
+		cond.Pattern, _ = raw["pattern"].(string)
+		cond.Path, _ = raw["path"].(string)
+		cond.Op, _ = raw["op"].(string)
+		cond.Question, _ = raw["question"].(string)
+		cond.Negate, _ = raw["negate"].(bool)
+		switch v := raw["value"].(type) {
+		case string:
+			cond.Value = v
+		case nil:
+		default:
+			cond.Value = fmt.Sprint(v)
+		}
+		action.If = cond
+	}
+
+	updated, err := t.cronService.SetActions(jobID, append(slices.Clone(job.Payload.Then), action))
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error chaining job: %v", err))
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Chain of '%s':\n", updated.Name)
+	for _, a := range updated.Payload.Then {
+		fmt.Fprintf(&result, "  %s\n", cron.DescribeAction(a, ""))
+	}
+	return SilentResult(result.String())
+}
+
+func (t *CronTool) jobHistory(args map[string]any) *ToolResult {
+	jobID, ok := args["job_id"].(string)
+	if !ok || jobID == "" {
+		return ErrorResult("job_id is required for history")
+	}
+	if _, ok := t.cronService.GetJob(jobID); !ok {
+		return ErrorResult(fmt.Sprintf("Job %s not found", jobID))
+	}
+
+	runs, err := t.cronService.History(jobID, 10)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error reading history: %v", err))
+	}
+	if len(runs) == 0 {
+		return SilentResult(fmt.Sprintf("Job %s has not run yet", jobID))
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Recent runs of %s:\n", jobID))
+	for _, r := range runs {
+		result.WriteString(fmt.Sprintf("- %s %s (%s, %dms)",
+			time.UnixMilli(r.StartedAtMS).Format("2006-01-02 15:04"), r.Status, r.Trigger, r.DurationMS))
+		if r.Error != "" {
+			result.WriteString(": " + r.Error)
+		}
+		result.WriteString("\n")
+	}
+	return SilentResult(result.String())
+}
+
+// ExecuteJob executes a cron job through the agent. It returns the output
+// delivered to the job's target, which the cron service keeps in the run
+// history, and an error when the run failed.
+func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) (string, error) {
 	// Get channel/chatID from job payload
 	channel := job.Payload.Channel
 	chatID := job.Payload.To
@@ -308,14 +539,10 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 	if job.Payload.Command != "" {
 		if !t.execEnabled || t.execTool == nil {
 			output := "Error executing scheduled command: command execution is disabled"
-			pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer pubCancel()
-			t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-				Channel: channel,
-				ChatID:  chatID,
-				Content: output,
-			})
-			return "ok"
+			if !job.Payload.Silent {
+				t.publishJobOutput(channel, chatID, output)
+			}
+			return output, fmt.Errorf("command execution is disabled")
 		}
 
 		args := map[string]any{
@@ -325,39 +552,80 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 		}
 
 		result := t.execTool.Execute(ctx, args)
-		var output string
 		if result.IsError {
-			output = fmt.Sprintf("Error executing scheduled command: %s", result.ForLLM)
-		} else {
-			output = fmt.Sprintf("Scheduled command '%s' executed:\n%s", job.Payload.Command, result.ForLLM)
+			if !job.Payload.Silent {
+				t.publishJobOutput(channel, chatID, fmt.Sprintf("Error executing scheduled command: %s", result.ForLLM))
+			}
+			return result.ForLLM, fmt.Errorf("scheduled command failed: %s", result.ForLLM)
 		}
 
-		pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer pubCancel()
-		t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-			Channel: channel,
-			ChatID:  chatID,
-			Content: output,
-		})
-		return "ok"
+		if !job.Payload.Silent {
+			t.publishJobOutput(channel, chatID,
+				fmt.Sprintf("Scheduled command '%s' executed:\n%s", job.Payload.Command, result.ForLLM))
+		}
+		// The raw command output is what post-run conditions are evaluated on.
+		return result.ForLLM, nil
 	}
 
 	sessionKey := fmt.Sprintf("cron-%s", job.ID)
 
+	message := job.Payload.Message
+	if job.Input != "" {
+		message += "\n\nOutput of the previous job:\n" + job.Input
+	}
+
+	if job.Payload.Workflow != "" {
+		return t.executeWorkflowJob(ctx, job, message, channel, chatID)
+	}
+
 	// Call agent with the job message
 	response, err := t.executor.ProcessDirectWithChannel(
 		ctx,
-		job.Payload.Message,
+		message,
 		sessionKey,
 		channel,
 		chatID,
 	)
 	if err != nil {
-		return fmt.Sprintf("Error: %v", err)
+		return "", err
 	}
 
-	if response != "" {
+	if response != "" && !job.Payload.Silent {
 		t.executor.PublishResponseIfNeeded(ctx, channel, chatID, response)
 	}
-	return "ok"
+	return response, nil
+}
+
+// executeWorkflowJob runs the job's workflow with message as its input.
+func (t *CronTool) executeWorkflowJob(
+	ctx context.Context,
+	job *cron.CronJob,
+	message, channel, chatID string,
+) (string, error) {
+	runner, ok := t.executor.(WorkflowRunner)
+	if !ok {
+		return "", fmt.Errorf("workflows are not available")
+	}
+	output, err := runner.RunWorkflowDirect(ctx, job.Payload.Workflow, message, channel, chatID)
+	if err != nil {
+		if !job.Payload.Silent {
+			t.publishJobOutput(channel, chatID,
+				fmt.Sprintf("Scheduled workflow '%s' failed: %v", job.Payload.Workflow, err))
+		}
+		return "", err
+	}
+	if output != "" && !job.Payload.Silent {
+		t.executor.PublishResponseIfNeeded(ctx, channel, chatID, output)
+	}
+	return output, nil
+}
+
+func (t *CronTool) publishJobOutput(channel, chatID, output string) {
+	pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer pubCancel()
+	t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
+		Channel: channel,
+		ChatID:  chatID,
+		Content: output,
+	})
 }
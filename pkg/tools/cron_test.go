@@ -229,6 +229,53 @@ func TestCronTool_NonCommandJobAllowedFromRemoteChannel(t *testing.T) {
 	}
 }
 
+func TestCronTool_NaturalLanguageScheduleUsesTimezone(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Tools.Cron.Timezone = "Asia/Shanghai"
+
+	tool := newTestCronToolWithConfig(t, cfg)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+	result := tool.Execute(ctx, map[string]any{
+		"action":   "add",
+		"message":  "stand-up",
+		"schedule": "every weekday at 8:30",
+	})
+	if result.IsError {
+		t.Fatalf("expected schedule to be accepted, got: %s", result.ForLLM)
+	}
+
+	jobs := tool.cronService.ListJobs(true)
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	if got := jobs[0].Schedule; got.Kind != "cron" || got.Expr != "30 8 * * 1-5" || got.TZ != "Asia/Shanghai" {
+		t.Fatalf("unexpected schedule: %+v", got)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action":   "add",
+		"message":  "stand-up",
+		"schedule": "every weekday at 8:30",
+		"timezone": "Not/AZone",
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "unknown timezone") {
+		t.Fatalf("expected unknown timezone error, got: %s", result.ForLLM)
+	}
+}
+
+func TestCronTool_UnparseableScheduleIsRejected(t *testing.T) {
+	tool := newTestCronTool(t)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+	result := tool.Execute(ctx, map[string]any{
+		"action":   "add",
+		"message":  "stand-up",
+		"schedule": "whenever it rains",
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "cannot understand schedule") {
+		t.Fatalf("expected parse error, got: %s", result.ForLLM)
+	}
+}
+
 func TestCronTool_ExecuteJobPublishesErrorWhenExecDisabled(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Tools.Exec.Enabled = false
@@ -239,8 +286,8 @@ func TestCronTool_ExecuteJobPublishesErrorWhenExecDisabled(t *testing.T) {
 	job.Payload.To = "direct"
 	job.Payload.Command = "df -h"
 
-	if got := tool.ExecuteJob(context.Background(), job); got != "ok" {
-		t.Fatalf("ExecuteJob() = %q, want ok", got)
+	if _, err := tool.ExecuteJob(context.Background(), job); err == nil {
+		t.Fatal("ExecuteJob() error = nil, want command execution disabled error")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -267,8 +314,8 @@ func TestCronTool_ExecuteJobPublishesAgentResponse(t *testing.T) {
 	job.Payload.To = "chat-1"
 	job.Payload.Message = "send me a poem"
 
-	if got := tool.ExecuteJob(context.Background(), job); got != "ok" {
-		t.Fatalf("ExecuteJob() = %q, want ok", got)
+	if _, err := tool.ExecuteJob(context.Background(), job); err != nil {
+		t.Fatalf("ExecuteJob() error = %v", err)
 	}
 
 	if executor.lastKey != "cron-job-1" {
@@ -297,8 +344,8 @@ func TestCronTool_ExecuteJobSkipsEmptyAgentResponse(t *testing.T) {
 	job.Payload.To = "chat-1"
 	job.Payload.Message = "say nothing"
 
-	if got := tool.ExecuteJob(context.Background(), job); got != "ok" {
-		t.Fatalf("ExecuteJob() = %q, want ok", got)
+	if _, err := tool.ExecuteJob(context.Background(), job); err != nil {
+		t.Fatalf("ExecuteJob() error = %v", err)
 	}
 
 	if executor.publishedResp != "" {
@@ -315,8 +362,8 @@ func TestCronTool_ExecuteJobSkipsWhenMessageToolAlreadySent(t *testing.T) {
 	job.Payload.To = "chat-1"
 	job.Payload.Message = "send weather"
 
-	if got := tool.ExecuteJob(context.Background(), job); got != "ok" {
-		t.Fatalf("ExecuteJob() = %q, want ok", got)
+	if _, err := tool.ExecuteJob(context.Background(), job); err != nil {
+		t.Fatalf("ExecuteJob() error = %v", err)
 	}
 
 	if executor.publishedResp != "" {
@@ -336,12 +383,124 @@ func TestCronTool_ExecuteJobReturnsErrorWithoutPublish(t *testing.T) {
 	job.Payload.To = "chat-1"
 	job.Payload.Message = "do something"
 
-	got := tool.ExecuteJob(context.Background(), job)
-	if !strings.Contains(got, "agent failure") {
-		t.Fatalf("ExecuteJob() = %q, want error message", got)
+	_, err := tool.ExecuteJob(context.Background(), job)
+	if err == nil || !strings.Contains(err.Error(), "agent failure") {
+		t.Fatalf("ExecuteJob() error = %v, want agent failure", err)
 	}
 
 	if executor.publishedResp != "" {
 		t.Fatalf("unexpected publish on error path: %q", executor.publishedResp)
 	}
 }
+
+func TestCronTool_ChainTriggeredJob(t *testing.T) {
+	tool := newTestCronTool(t)
+	ctx := WithToolContext(context.Background(), "telegram", "chat-1")
+
+	result := tool.Execute(ctx, map[string]any{
+		"action":        "add",
+		"message":       "check the weather",
+		"every_seconds": float64(3600),
+		"silent":        true,
+	})
+	if result.IsError {
+		t.Fatalf("add source: %s", result.ForLLM)
+	}
+	result = tool.Execute(ctx, map[string]any{
+		"action":         "add",
+		"message":        "tell me to take an umbrella",
+		"triggered_only": true,
+	})
+	if result.IsError {
+		t.Fatalf("add target: %s", result.ForLLM)
+	}
+
+	jobs := tool.cronService.ListJobs(true)
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	source, target := jobs[0], jobs[1]
+	if !source.Payload.Silent {
+		t.Error("expected source job to be silent")
+	}
+	if target.Schedule.Kind != cron.ScheduleManual || target.State.NextRunAtMS != nil {
+		t.Errorf("expected target to run only when triggered, got %+v", target.Schedule)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action":        "chain",
+		"job_id":        source.ID,
+		"target_job_id": target.ID,
+		"pass_output":   true,
+		"condition":     map[string]any{"kind": "regex", "pattern": "(?i)rain"},
+	})
+	if result.IsError {
+		t.Fatalf("chain: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, "if regex /(?i)rain/") {
+		t.Errorf("unexpected chain summary: %s", result.ForLLM)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action":        "chain",
+		"job_id":        target.ID,
+		"target_job_id": source.ID,
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "loop") {
+		t.Fatalf("expected loop to be rejected, got: %s", result.ForLLM)
+	}
+}
+
+type stubWorkflowExecutor struct {
+	stubJobExecutor
+	workflow string
+	input    string
+}
+
+func (s *stubWorkflowExecutor) RunWorkflowDirect(
+	_ context.Context,
+	name, input, channel, chatID string,
+) (string, error) {
+	s.workflow = name
+	s.input = input
+	s.lastChan = channel
+	s.lastChatID = chatID
+	return "workflow output", nil
+}
+
+func TestCronTool_ExecuteJobRunsWorkflow(t *testing.T) {
+	executor := &stubWorkflowExecutor{}
+	tool := newTestCronToolWithExecutorAndConfig(t, executor, config.DefaultConfig())
+
+	job := &cron.CronJob{ID: "job-1", Input: "previous"}
+	job.Payload.Channel = "telegram"
+	job.Payload.To = "chat-1"
+	job.Payload.Message = "today's news"
+	job.Payload.Workflow = "digest"
+
+	output, err := tool.ExecuteJob(context.Background(), job)
+	if err != nil {
+		t.Fatalf("ExecuteJob() error = %v", err)
+	}
+	if output != "workflow output" || executor.workflow != "digest" {
+		t.Fatalf("ExecuteJob() = %q, ran workflow %q", output, executor.workflow)
+	}
+	if executor.input != "today's news\n\nOutput of the previous job:\nprevious" {
+		t.Fatalf("workflow input = %q", executor.input)
+	}
+	if executor.lastKey != "" {
+		t.Fatal("workflow job must not start an agent turn")
+	}
+	if executor.publishedResp != "workflow output" || executor.publishedChatID != "chat-1" {
+		t.Fatalf("published %q to %s", executor.publishedResp, executor.publishedChatID)
+	}
+}
+
+func TestCronTool_ExecuteJobWorkflowUnsupportedExecutor(t *testing.T) {
+	tool := newTestCronToolWithExecutorAndConfig(t, &stubJobExecutor{}, config.DefaultConfig())
+	job := &cron.CronJob{ID: "job-1"}
+	job.Payload.Workflow = "digest"
+	if _, err := tool.ExecuteJob(context.Background(), job); err == nil {
+		t.Fatal("ExecuteJob() error = nil, want workflows unavailable")
+	}
+}
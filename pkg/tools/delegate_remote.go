@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/swarm"
+)
+
+// SwarmPeers is the part of a swarm node the delegate_remote tool uses.
+type SwarmPeers interface {
+	Peers() []swarm.Peer
+	Delegate(ctx context.Context, peer, agentID, task string) (string, error)
+}
+
+// DelegateRemoteTool runs a task on another PicoClaw instance of the swarm.
+type DelegateRemoteTool struct {
+	swarm SwarmPeers
+}
+
+func NewDelegateRemoteTool(s SwarmPeers) *DelegateRemoteTool {
+	return &DelegateRemoteTool{swarm: s}
+}
+
+func (t *DelegateRemoteTool) Name() string {
+	return "delegate_remote"
+}
+
+func (t *DelegateRemoteTool) Description() string {
+	return "Run a task on another PicoClaw instance (peer) on the local network and return its result. Use action 'list' to see the online peers with their agents and tools, then 'run' with the peer name and a self-contained task. Use it when a peer has hardware or tools this instance lacks, e.g. a camera board."
+}
+
+func (t *DelegateRemoteTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "run"},
+				"description": "'list' shows the online peers; 'run' (default) delegates the task",
+			},
+			"peer": map[string]any{
+				"type":        "string",
+				"description": "Name of the peer to run the task on",
+			},
+			"agent_id": map[string]any{
+				"type":        "string",
+				"description": "Optional agent of the peer; defaults to its default agent",
+			},
+			"task": map[string]any{
+				"type":        "string",
+				"description": "Self-contained task description; the peer does not see this conversation",
+			},
+		},
+	}
+}
+
+func (t *DelegateRemoteTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if t.swarm == nil {
+		return ErrorResult("swarm is not available")
+	}
+	action, _ := args["action"].(string)
+	if action == "list" {
+		return SilentResult(t.listPeers())
+	}
+	if action != "" && action != "run" {
+		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
+	}
+
+	peer, _ := args["peer"].(string)
+	peer = strings.TrimSpace(peer)
+	task, _ := args["task"].(string)
+	task = strings.TrimSpace(task)
+	if peer == "" || task == "" {
+		return ErrorResult("peer and task are required")
+	}
+	agentID, _ := args["agent_id"].(string)
+
+	output, err := t.swarm.Delegate(ctx, peer, strings.TrimSpace(agentID), task)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("delegation to %s failed: %v\n%s", peer, err, t.listPeers()))
+	}
+	return &ToolResult{ForLLM: fmt.Sprintf("Result from peer '%s':\n%s", peer, output)}
+}
+
+func (t *DelegateRemoteTool) listPeers() string {
+	peers := t.swarm.Peers()
+	if len(peers) == 0 {
+		return "No peers are online."
+	}
+	var sb strings.Builder
+	sb.WriteString("Online peers:\n")
+	for _, p := range peers {
+		fmt.Fprintf(&sb, "- %s (agents: %s; tools: %s)\n",
+			p.Name, strings.Join(p.Agents, ", "), strings.Join(p.Tools, ", "))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
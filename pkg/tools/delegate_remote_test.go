@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/swarm"
+)
+
+type fakeSwarm struct {
+	peers   []swarm.Peer
+	gotPeer string
+	gotTask string
+	err     error
+}
+
+func (f *fakeSwarm) Peers() []swarm.Peer { return f.peers }
+
+func (f *fakeSwarm) Delegate(_ context.Context, peer, _, task string) (string, error) {
+	f.gotPeer, f.gotTask = peer, task
+	if f.err != nil {
+		return "", f.err
+	}
+	return "photo saved", nil
+}
+
+func TestDelegateRemoteTool(t *testing.T) {
+	s := &fakeSwarm{peers: []swarm.Peer{{Name: "camera", Agents: []string{"main"}, Tools: []string{"i2c"}}}}
+	tool := NewDelegateRemoteTool(s)
+
+	res := tool.Execute(context.Background(), map[string]any{"action": "list"})
+	if res.IsError || !strings.Contains(res.ForLLM, "camera (agents: main; tools: i2c)") {
+		t.Fatalf("list = %+v", res)
+	}
+
+	res = tool.Execute(context.Background(), map[string]any{"peer": "camera", "task": "take a photo"})
+	if res.IsError || !strings.Contains(res.ForLLM, "photo saved") {
+		t.Fatalf("run = %+v", res)
+	}
+	if s.gotPeer != "camera" || s.gotTask != "take a photo" {
+		t.Fatalf("delegated %q to %q", s.gotTask, s.gotPeer)
+	}
+
+	if res := tool.Execute(context.Background(), map[string]any{"peer": "camera"}); !res.IsError {
+		t.Fatal("run without a task succeeded")
+	}
+
+	s.err = errors.New(`peer "printer" is not online`)
+	res = tool.Execute(context.Background(), map[string]any{"peer": "printer", "task": "print"})
+	if !res.IsError || !strings.Contains(res.ForLLM, "Online peers:") {
+		t.Fatalf("failed run = %+v", res)
+	}
+}
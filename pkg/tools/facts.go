@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/facts"
+)
+
+// RememberTool stores a fact in long-term memory.
+type RememberTool struct {
+	store *facts.Store
+}
+
+// NewRememberTool creates a RememberTool backed by store.
+func NewRememberTool(store *facts.Store) *RememberTool {
+	return &RememberTool{store: store}
+}
+
+func (t *RememberTool) Name() string {
+	return "remember"
+}
+
+func (t *RememberTool) Description() string {
+	return "Store a durable fact or preference about the user in long-term memory so it is recalled " +
+		"in future conversations. Give a key naming what the fact is about (e.g. \"user.home_city\"); " +
+		"a new fact with the same key replaces the old one."
+}
+
+func (t *RememberTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text": map[string]any{
+				"type":        "string",
+				"description": "The fact as one self-contained sentence, e.g. \"The user is vegetarian.\"",
+			},
+			"key": map[string]any{
+				"type":        "string",
+				"description": "Optional short dotted name of what the fact is about, e.g. \"user.diet\"",
+			},
+			"kind": map[string]any{
+				"type":        "string",
+				"enum":        []string{facts.KindFact, facts.KindPreference},
+				"description": "Whether this is a fact or a preference (default: fact)",
+			},
+		},
+		"required": []string{"text"},
+	}
+}
+
+func (t *RememberTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	text, _ := args["text"].(string)
+	if strings.TrimSpace(text) == "" {
+		return ErrorResult("text is required")
+	}
+	key, _ := args["key"].(string)
+	kind, _ := args["kind"].(string)
+
+	var source string
+	if channel, chatID := ToolChannel(ctx), ToolChatID(ctx); channel != "" {
+		source = channel + ":" + chatID
+	}
+	f, outcome, err := t.store.Add(facts.Fact{
+		Text:   text,
+		Key:    key,
+		Kind:   kind,
+		Source: source,
+		Scope:  ToolSenderID(ctx),
+	})
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("remembering fact: %v", err)).WithError(err)
+	}
+	switch outcome {
+	case facts.Merged:
+		return SilentResult(fmt.Sprintf("Already remembered as [%s] %s", f.ID, f.Text))
+	case facts.Replaced:
+		return SilentResult(fmt.Sprintf("Remembered [%s], replacing the earlier fact about %s", f.ID, f.Key))
+	}
+	return SilentResult(fmt.Sprintf("Remembered [%s]", f.ID))
+}
+
+// ForgetTool deletes a fact from long-term memory.
+type ForgetTool struct {
+	store *facts.Store
+}
+
+// NewForgetTool creates a ForgetTool backed by store.
+func NewForgetTool(store *facts.Store) *ForgetTool {
+	return &ForgetTool{store: store}
+}
+
+func (t *ForgetTool) Name() string {
+	return "forget"
+}
+
+func (t *ForgetTool) Description() string {
+	return "Delete a fact from long-term memory by its ID, for example when the user asks you to forget " +
+		"something or a remembered fact is wrong. Use recall to find the ID."
+}
+
+func (t *ForgetTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id": map[string]any{
+				"type":        "string",
+				"description": "ID of the fact, as shown in brackets by recall",
+			},
+		},
+		"required": []string{"id"},
+	}
+}
+
+func (t *ForgetTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	id, _ := args["id"].(string)
+	id = strings.Trim(strings.TrimSpace(id), "[]")
+	if id == "" {
+		return ErrorResult("id is required")
+	}
+	if f, ok := t.store.Get(id); ok && !f.InScope(t.store.Scopes(ToolSenderID(ctx))...) {
+		return ErrorResult(fmt.Sprintf("No fact with ID %s", id))
+	}
+	f, ok, err := t.store.Forget(id)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("forgetting fact: %v", err)).WithError(err)
+	}
+	if !ok {
+		return ErrorResult(fmt.Sprintf("No fact with ID %s", id))
+	}
+	return SilentResult(fmt.Sprintf("Forgot [%s] %s", f.ID, f.Text))
+}
+
+// RecallTool searches long-term memory.
+type RecallTool struct {
+	store *facts.Store
+}
+
+// NewRecallTool creates a RecallTool backed by store.
+func NewRecallTool(store *facts.Store) *RecallTool {
+	return &RecallTool{store: store}
+}
+
+func (t *RecallTool) Name() string {
+	return "recall"
+}
+
+func (t *RecallTool) Description() string {
+	return "Search long-term memory for facts and preferences remembered from earlier conversations. " +
+		"The most relevant facts are already in your context; use this to look further."
+}
+
+func (t *RecallTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "What to look for, e.g. \"food preferences\"",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of facts to return (default 10)",
+			},
+		},
+		"required": []string{"query"},
+	}
+}
+
+func (t *RecallTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return ErrorResult("query is required")
+	}
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l >= 1 {
+		limit = int(l)
+	}
+
+	recalled := t.store.Recall(ctx, ToolSenderID(ctx), query, limit)
+	if len(recalled) == 0 {
+		return SilentResult("No matching facts remembered.")
+	}
+	var sb strings.Builder
+	for _, f := range recalled {
+		fmt.Fprintf(&sb, "[%s] %s", f.ID, f.Text)
+		if f.Key != "" {
+			fmt.Fprintf(&sb, " (key: %s)", f.Key)
+		}
+		fmt.Fprintf(&sb, " — %s, confidence %.2f\n", f.UpdatedAt.Format("2006-01-02"), f.Confidence)
+	}
+	return SilentResult(strings.TrimRight(sb.String(), "\n"))
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/facts"
+)
+
+func TestFactTools_RememberRecallForget(t *testing.T) {
+	store := facts.NewStore(filepath.Join(t.TempDir(), "facts.json"), facts.Options{})
+	ctx := WithToolSender(WithToolContext(context.Background(), "telegram", "42"), "telegram:7")
+
+	result := NewRememberTool(store).Execute(ctx, map[string]any{
+		"text": "The user is vegetarian.",
+		"key":  "user.diet",
+		"kind": "preference",
+	})
+	if result.IsError || !strings.HasPrefix(result.ForLLM, "Remembered [") {
+		t.Fatalf("remember result = %+v", result)
+	}
+	stored := store.List(false)
+	if len(stored) != 1 || stored[0].Source != "telegram:42" || stored[0].Scope != "telegram:7" ||
+		stored[0].Kind != facts.KindPreference {
+		t.Fatalf("stored facts = %+v", stored)
+	}
+
+	result = NewRememberTool(store).Execute(ctx, map[string]any{
+		"text": "The user eats fish now.",
+		"key":  "user.diet",
+	})
+	if !strings.Contains(result.ForLLM, "replacing the earlier fact about user.diet") {
+		t.Fatalf("replacing remember result = %q", result.ForLLM)
+	}
+
+	result = NewRecallTool(store).Execute(ctx, map[string]any{"query": "does the user eat fish?"})
+	if result.IsError || !strings.Contains(result.ForLLM, "eats fish") ||
+		strings.Contains(result.ForLLM, "vegetarian") {
+		t.Fatalf("recall result = %q", result.ForLLM)
+	}
+
+	id := store.List(false)[0].ID
+	other := WithToolSender(ctx, "telegram:8")
+	result = NewRecallTool(store).Execute(other, map[string]any{"query": "does the user eat fish?"})
+	if strings.Contains(result.ForLLM, "eats fish") {
+		t.Fatalf("recall of another sender = %q", result.ForLLM)
+	}
+	if result := NewForgetTool(store).Execute(other, map[string]any{"id": id}); !result.IsError {
+		t.Fatal("another sender forgot the fact")
+	}
+	result = NewForgetTool(store).Execute(ctx, map[string]any{"id": "[" + id + "]"})
+	if result.IsError || len(store.List(false)) != 0 {
+		t.Fatalf("forget result = %+v", result)
+	}
+	if result := NewForgetTool(store).Execute(ctx, map[string]any{"id": id}); !result.IsError {
+		t.Fatal("forgetting an unknown fact succeeded")
+	}
+	if result := NewRememberTool(store).Execute(ctx, map[string]any{}); !result.IsError {
+		t.Fatal("remember without text succeeded")
+	}
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HandoffRequest describes a transfer of the current conversation to another
+// agent.
+type HandoffRequest struct {
+	AgentID  string
+	Summary  string
+	Duration time.Duration // 0 means until /back
+}
+
+// HandoffFunc performs a handoff for the conversation in ctx and returns the
+// time it ends (zero when it lasts until /back).
+type HandoffFunc func(ctx context.Context, req HandoffRequest) (time.Time, error)
+
+// HandoffTool lets an agent pass the whole conversation to a specialist
+// agent. Unlike spawn, the user keeps talking to the target agent directly
+// until the handoff expires or they send /back.
+type HandoffTool struct {
+	handoff         HandoffFunc
+	defaultDuration time.Duration
+	allowlistCheck  func(targetAgentID string) bool
+}
+
+func NewHandoffTool(handoff HandoffFunc, defaultDuration time.Duration) *HandoffTool {
+	return &HandoffTool{handoff: handoff, defaultDuration: defaultDuration}
+}
+
+func (t *HandoffTool) SetAllowlistChecker(check func(targetAgentID string) bool) {
+	t.allowlistCheck = check
+}
+
+func (t *HandoffTool) Name() string {
+	return "handoff"
+}
+
+func (t *HandoffTool) Description() string {
+	return "Hand the current conversation over to another agent. The user's next messages go to that agent until the handoff expires or the user sends /back. Use this when a specialist agent should take over the conversation, not for one-off tasks (use spawn or subagent for those)."
+}
+
+func (t *HandoffTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"agent_id": map[string]any{
+				"type":        "string",
+				"description": "ID of the agent that takes over the conversation",
+			},
+			"summary": map[string]any{
+				"type":        "string",
+				"description": "Summary of the conversation so far and what the user needs, passed to the target agent",
+			},
+			"duration_minutes": map[string]any{
+				"type":        "integer",
+				"description": "Optional handoff duration in minutes; 0 lasts until the user sends /back",
+			},
+		},
+		"required": []string{"agent_id", "summary"},
+	}
+}
+
+func (t *HandoffTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if t.handoff == nil {
+		return ErrorResult("handoff is not available")
+	}
+
+	agentID, _ := args["agent_id"].(string)
+	agentID = strings.TrimSpace(agentID)
+	if agentID == "" {
+		return ErrorResult("agent_id is required")
+	}
+	summary, _ := args["summary"].(string)
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return ErrorResult("summary is required")
+	}
+	if t.allowlistCheck != nil && !t.allowlistCheck(agentID) {
+		return ErrorResult(fmt.Sprintf("not allowed to hand off to agent '%s'", agentID))
+	}
+
+	duration := t.defaultDuration
+	if _, ok := args["duration_minutes"]; ok {
+		minutes, err := getInt64Arg(args, "duration_minutes", 0)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		if minutes < 0 {
+			return ErrorResult("duration_minutes must not be negative")
+		}
+		duration = time.Duration(minutes) * time.Minute
+	}
+
+	until, err := t.handoff(ctx, HandoffRequest{AgentID: agentID, Summary: summary, Duration: duration})
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("handoff failed: %v", err))
+	}
+
+	end := "until you send /back"
+	if !until.IsZero() {
+		end = "until " + until.Format("15:04") + " or until you send /back"
+	}
+	// Handoffs are not persisted, so the user is told a restart ends them.
+	end += " (a gateway restart also ends the handoff)"
+	// The announcement is the turn's reply; the target agent answers the
+	// user's next message.
+	return &ToolResult{
+		ForLLM:          fmt.Sprintf("Conversation handed off to agent '%s'; the user has been told.", agentID),
+		ForUser:         fmt.Sprintf("You are now talking to agent '%s' %s.", agentID, end),
+		ResponseHandled: true,
+	}
+}
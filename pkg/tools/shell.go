@@ -266,17 +266,8 @@ func (t *ExecTool) executeRun(ctx context.Context, args map[string]any) *ToolRes
 		return ErrorResult("command is required")
 	}
 
-	// GHSA-pv8c-p6jf-3fpp: block exec from remote channels (e.g. Telegram webhooks)
-	// unless explicitly opted-in via config. Fail-closed: empty channel = blocked.
-	if !t.allowRemote {
-		channel := ToolChannel(ctx)
-		if channel == "" {
-			channel, _ = args["__channel"].(string)
-		}
-		channel = strings.TrimSpace(channel)
-		if channel == "" || !constants.IsInternalChannel(channel) {
-			return ErrorResult("exec is restricted to internal channels")
-		}
+	if !t.channelAllowed(ctx, args) {
+		return ErrorResult("exec is restricted to internal channels")
 	}
 
 	getBoolArg := func(key string) bool {
@@ -352,11 +343,23 @@ func (t *ExecTool) executeRun(ctx context.Context, args map[string]any) *ToolRes
 }
 
 func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult {
-	// timeout == 0 means no timeout
+	return t.runCommand(ctx, syncCommand{command: command, cwd: cwd, timeout: t.timeout})
+}
+
+// syncCommand describes a foreground command run by runCommand.
+type syncCommand struct {
+	command string
+	cwd     string
+	timeout time.Duration // 0 means no timeout
+	env     []string      // appended to the process environment
+	stdin   io.Reader
+}
+
+func (t *ExecTool) runCommand(ctx context.Context, c syncCommand) *ToolResult {
 	var cmdCtx context.Context
 	var cancel context.CancelFunc
-	if t.timeout > 0 {
-		cmdCtx, cancel = context.WithTimeout(ctx, t.timeout)
+	if c.timeout > 0 {
+		cmdCtx, cancel = context.WithTimeout(ctx, c.timeout)
 	} else {
 		cmdCtx, cancel = context.WithCancel(ctx)
 	}
@@ -364,13 +367,17 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(cmdCtx, "powershell", "-NoProfile", "-NonInteractive", "-Command", command)
+		cmd = exec.CommandContext(cmdCtx, "powershell", "-NoProfile", "-NonInteractive", "-Command", c.command)
 	} else {
-		cmd = exec.CommandContext(cmdCtx, "sh", "-c", command)
+		cmd = exec.CommandContext(cmdCtx, "sh", "-c", c.command)
 	}
-	if cwd != "" {
-		cmd.Dir = cwd
+	if c.cwd != "" {
+		cmd.Dir = c.cwd
+	}
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
 	}
+	cmd.Stdin = c.stdin
 
 	prepareCommandForTermination(cmd)
 
@@ -409,7 +416,7 @@ func (t *ExecTool) runSync(ctx context.Context, command, cwd string) *ToolResult
 
 	if err != nil {
 		if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
-			msg := fmt.Sprintf("Command timed out after %v", t.timeout)
+			msg := fmt.Sprintf("Command timed out after %v", c.timeout)
 			if output != "" {
 				msg += "\n\nPartial output before timeout:\n" + output
 			}
@@ -1014,6 +1021,21 @@ func (t *ExecTool) executeSendKeys(args map[string]any) *ToolResult {
 	}
 }
 
+// channelAllowed reports whether commands may run for the calling channel.
+// GHSA-pv8c-p6jf-3fpp: exec from remote channels (e.g. Telegram webhooks) is
+// blocked unless explicitly opted-in via config. Fail-closed: empty channel = blocked.
+func (t *ExecTool) channelAllowed(ctx context.Context, args map[string]any) bool {
+	if t.allowRemote {
+		return true
+	}
+	channel := ToolChannel(ctx)
+	if channel == "" {
+		channel, _ = args["__channel"].(string)
+	}
+	channel = strings.TrimSpace(channel)
+	return channel != "" && constants.IsInternalChannel(channel)
+}
+
 func (t *ExecTool) guardCommand(command, cwd string) string {
 	cmd := strings.TrimSpace(command)
 	lower := strings.ToLower(cmd)
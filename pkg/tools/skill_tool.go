@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+const defaultSkillToolTimeout = 60 * time.Second
+
+// SkillTool is a tool declared in the frontmatter of a skill. Its command
+// runs in the skill directory through the guards of the exec tool: the
+// remote channel check, deny/allow patterns and workspace restriction. The
+// arguments are passed as JSON on stdin and in PICOCLAW_TOOL_ARGS, never
+// interpolated into the command line.
+type SkillTool struct {
+	spec skills.ToolSpec
+	exec *ExecTool
+}
+
+func NewSkillTool(spec skills.ToolSpec, exec *ExecTool) *SkillTool {
+	return &SkillTool{spec: spec, exec: exec}
+}
+
+func (t *SkillTool) Name() string {
+	return t.spec.Name
+}
+
+func (t *SkillTool) Description() string {
+	if t.spec.Description == "" {
+		return fmt.Sprintf("Tool of the %s skill.", t.spec.Skill)
+	}
+	return t.spec.Description
+}
+
+func (t *SkillTool) Parameters() map[string]any {
+	return t.spec.Parameters
+}
+
+// Skill returns the name of the skill that declares the tool.
+func (t *SkillTool) Skill() string {
+	return t.spec.Skill
+}
+
+func (t *SkillTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if t.exec == nil {
+		return ErrorResult("skill tools need the exec tool, which is disabled")
+	}
+	if !t.exec.channelAllowed(ctx, args) {
+		return ErrorResult("skill tools are restricted to internal channels")
+	}
+
+	var missing []string
+	for _, name := range t.spec.Env {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return ErrorResult(fmt.Sprintf("tool %s needs environment variables that are not set: %s",
+			t.spec.Name, strings.Join(missing, ", ")))
+	}
+
+	if guardError := t.exec.guardCommand(t.spec.Command, t.spec.Dir); guardError != "" {
+		return ErrorResult(guardError)
+	}
+
+	if args == nil {
+		args = map[string]any{}
+	}
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode arguments: %v", err)).WithError(err)
+	}
+
+	timeout := time.Duration(t.spec.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = t.exec.timeout
+	}
+	if timeout == 0 {
+		timeout = defaultSkillToolTimeout
+	}
+
+	return t.exec.runCommand(ctx, syncCommand{
+		command: t.spec.Command,
+		cwd:     t.spec.Dir,
+		timeout: timeout,
+		env: []string{
+			"PICOCLAW_TOOL_ARGS=" + string(payload),
+			"PICOCLAW_SKILL_DIR=" + t.spec.Dir,
+			"PICOCLAW_WORKSPACE=" + t.exec.workingDir,
+		},
+		stdin: bytes.NewReader(payload),
+	})
+}
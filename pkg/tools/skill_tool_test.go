@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newTestSkillTool(t *testing.T, spec skills.ToolSpec) *SkillTool {
+	t.Helper()
+	exec, err := NewExecTool(t.TempDir(), false)
+	require.NoError(t, err)
+	if spec.Dir == "" {
+		spec.Dir = t.TempDir()
+	}
+	if spec.Parameters == nil {
+		spec.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return NewSkillTool(spec, exec)
+}
+
+func TestSkillTool_PassesArgumentsAsJSON(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "marker"), []byte("here"), 0o644))
+
+	tool := newTestSkillTool(t, skills.ToolSpec{
+		Name:    "echo_args",
+		Skill:   "echo",
+		Dir:     dir,
+		Command: `cat; echo; echo "$PICOCLAW_TOOL_ARGS"; cat marker`,
+	})
+	assert.Equal(t, "Tool of the echo skill.", tool.Description())
+
+	result := tool.Execute(context.Background(), map[string]any{"city": "Berlin; rm -rf /"})
+	require.False(t, result.IsError, result.ForLLM)
+	lines := strings.Split(strings.TrimSpace(result.ForLLM), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, `{"city":"Berlin; rm -rf /"}`, lines[0])
+	assert.Equal(t, lines[0], lines[1])
+	assert.Equal(t, "here", lines[2])
+}
+
+func TestSkillTool_RequiresEnv(t *testing.T) {
+	tool := newTestSkillTool(t, skills.ToolSpec{
+		Name:    "needs_key",
+		Command: "echo ok",
+		Env:     []string{"PICOCLAW_TEST_SKILL_TOOL_KEY"},
+	})
+	result := tool.Execute(context.Background(), nil)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "PICOCLAW_TEST_SKILL_TOOL_KEY")
+
+	t.Setenv("PICOCLAW_TEST_SKILL_TOOL_KEY", "secret")
+	result = tool.Execute(context.Background(), nil)
+	assert.False(t, result.IsError, result.ForLLM)
+}
+
+func TestSkillTool_UsesExecGuards(t *testing.T) {
+	tool := newTestSkillTool(t, skills.ToolSpec{Name: "wipe", Command: "rm -rf /tmp/x"})
+	result := tool.Execute(context.Background(), nil)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "safety guard")
+
+	cfg := config.DefaultConfig()
+	cfg.Tools.Exec.AllowRemote = false
+	exec, err := NewExecToolWithConfig(t.TempDir(), false, cfg)
+	require.NoError(t, err)
+	remote := NewSkillTool(skills.ToolSpec{Name: "hello", Command: "echo hi", Dir: t.TempDir()}, exec)
+	ctx := WithToolContext(context.Background(), "telegram", "123")
+	result = remote.Execute(ctx, nil)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "internal channels")
+}
+
+func TestSkillTool_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	tool := newTestSkillTool(t, skills.ToolSpec{Name: "slow", Command: "sleep 5", TimeoutSeconds: 1})
+	result := tool.Execute(context.Background(), nil)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "timed out after 1s")
+}
+
+func TestSkillTool_ValidatedByRegistry(t *testing.T) {
+	tool := newTestSkillTool(t, skills.ToolSpec{
+		Name:    "forecast",
+		Command: "echo ok",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}},
+			"required":   []any{"city"},
+		},
+	})
+	registry := NewToolRegistry()
+	registry.Register(tool)
+
+	result := registry.Execute(context.Background(), "forecast", map[string]any{})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.ForLLM, "city")
+
+	result = registry.Execute(context.Background(), "forecast", map[string]any{"city": "Oslo"})
+	assert.False(t, result.IsError, result.ForLLM)
+}
@@ -21,6 +21,7 @@ import (
 type InstallSkillTool struct {
 	registryMgr *skills.RegistryManager
 	workspace   string
+	policy      skills.SecurityPolicy
 	mu          sync.Mutex
 }
 
@@ -35,6 +36,13 @@ func NewInstallSkillTool(registryMgr *skills.RegistryManager, workspace string)
 	}
 }
 
+// SetSecurityPolicy sets the policy downloaded skills are vetted against.
+// The agent cannot confirm flagged skills on the user's behalf, so only
+// skills that pass are installed.
+func (t *InstallSkillTool) SetSecurityPolicy(policy skills.SecurityPolicy) {
+	t.policy = policy
+}
+
 func (t *InstallSkillTool) Name() string {
 	return "install_skill"
 }
@@ -93,15 +101,11 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 	skillsDir := filepath.Join(t.workspace, "skills")
 	targetDir := filepath.Join(skillsDir, slug)
 
-	if !force {
-		if _, err := os.Stat(targetDir); err == nil {
-			return ErrorResult(
-				fmt.Sprintf("skill %q already installed at %s. Use force=true to reinstall.", slug, targetDir),
-			)
-		}
-	} else {
-		// Force: remove existing if present.
-		os.RemoveAll(targetDir)
+	_, statErr := os.Stat(targetDir)
+	if !force && statErr == nil {
+		return ErrorResult(
+			fmt.Sprintf("skill %q already installed at %s. Use force=true to reinstall.", slug, targetDir),
+		)
 	}
 
 	// Resolve which registry to use.
@@ -115,36 +119,48 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 		return ErrorResult(fmt.Sprintf("failed to create skills directory: %v", err))
 	}
 
+	// Download next to the skills, where the loader does not look, and move
+	// the skill into place once it is vetted.
+	stageRoot, err := os.MkdirTemp(skillsDir, "."+slug+"-install-*")
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to prepare install: %v", err))
+	}
+	defer os.RemoveAll(stageRoot)
+	stagedDir := filepath.Join(stageRoot, slug)
+
 	// Download and install (handles metadata, version resolution, extraction).
-	result, err := registry.DownloadAndInstall(ctx, slug, version, targetDir)
+	result, err := registry.DownloadAndInstall(ctx, slug, version, stagedDir)
 	if err != nil {
-		// Clean up partial install.
-		rmErr := os.RemoveAll(targetDir)
-		if rmErr != nil {
-			logger.ErrorCF("tool", "Failed to remove partial install",
-				map[string]any{
-					"tool":       "install_skill",
-					"target_dir": targetDir,
-					"error":      rmErr.Error(),
-				})
-		}
 		return ErrorResult(fmt.Sprintf("failed to install %q: %v", slug, err))
 	}
 
 	// Moderation: block malware.
 	if result.IsMalwareBlocked {
-		rmErr := os.RemoveAll(targetDir)
-		if rmErr != nil {
-			logger.ErrorCF("tool", "Failed to remove partial install",
-				map[string]any{
-					"tool":       "install_skill",
-					"target_dir": targetDir,
-					"error":      rmErr.Error(),
-				})
-		}
 		return ErrorResult(fmt.Sprintf("skill %q is flagged as malicious and cannot be installed", slug))
 	}
 
+	report, err := skills.VetSkill(stagedDir, slug, t.policy)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to vet %q: %v", slug, err))
+	}
+	switch report.Verdict {
+	case skills.VerdictBlock:
+		return ErrorResult(fmt.Sprintf("skill %q is blocked by the security policy.\n\n%s", slug, report.Format()))
+	case skills.VerdictFlag:
+		return ErrorResult(fmt.Sprintf(
+			"skill %q was flagged by the security scan and needs the user's confirmation. "+
+				"Show the user this report; they can install it with `picoclaw skills install --registry %s %s` "+
+				"or from the web UI.\n\n%s",
+			slug, registry.Name(), slug, report.Format()))
+	}
+
+	if force && statErr == nil {
+		os.RemoveAll(targetDir)
+	}
+	if err := os.Rename(stagedDir, targetDir); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to activate %q: %v", slug, err))
+	}
+
 	// Write origin metadata.
 	if err := writeOriginMeta(targetDir, registry.Name(), slug, result.Version); err != nil {
 		logger.ErrorCF("tool", "Failed to write origin metadata",
@@ -159,6 +175,20 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 		_ = err
 	}
 
+	err = skills.RecordInstall(t.workspace, slug, skills.LockEntry{
+		Source:  registry.Name(),
+		Slug:    slug,
+		Version: result.Version,
+	})
+	if err != nil {
+		logger.ErrorCF("tool", "Failed to record skill in lock file",
+			map[string]any{
+				"tool":  "install_skill",
+				"error": err.Error(),
+				"slug":  slug,
+			})
+	}
+
 	// Build result with moderation warning if suspicious.
 	var output string
 	if result.IsSuspicious {
@@ -166,6 +196,7 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 	}
 	output += fmt.Sprintf("Successfully installed skill %q v%s from %s registry.\nLocation: %s\n",
 		slug, result.Version, registry.Name(), targetDir)
+	output += fmt.Sprintf("Security scan: passed (signature: %s)\n", report.Signature)
 
 	if result.Summary != "" {
 		output += fmt.Sprintf("Description: %s\n", result.Summary)
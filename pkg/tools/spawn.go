@@ -7,6 +7,7 @@ import (
 )
 
 type SpawnTool struct {
+	manager        *SubagentManager
 	spawner        SubTurnSpawner
 	defaultModel   string
 	maxTokens      int
@@ -22,6 +23,7 @@ func NewSpawnTool(manager *SubagentManager) *SpawnTool {
 		return &SpawnTool{}
 	}
 	return &SpawnTool{
+		manager:      manager,
 		defaultModel: manager.defaultModel,
 		maxTokens:    manager.maxTokens,
 		temperature:  manager.temperature,
@@ -57,6 +59,10 @@ func (t *SpawnTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Optional target agent ID to delegate the task to",
 			},
+			"priority": map[string]any{
+				"type":        "integer",
+				"description": "Optional queue priority; higher runs first when other tasks are waiting (default 0)",
+			},
 		},
 		"required": []string{"task"},
 	}
@@ -100,6 +106,29 @@ func (t *SpawnTool) execute(
 		}
 	}
 
+	// With a task queue the task is persisted and survives restarts.
+	if t.manager != nil && t.manager.QueueEnabled() {
+		priority, err := getInt64Arg(args, "priority", 0)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		queued, err := t.manager.Enqueue(TaskRequest{
+			Task:          task,
+			Label:         label,
+			AgentID:       agentID,
+			OriginChannel: ToolChannel(ctx),
+			OriginChatID:  ToolChatID(ctx),
+			Priority:      int(priority),
+		}, cb)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Failed to queue task: %v", err)).WithError(err)
+		}
+		if label != "" {
+			return AsyncResult(fmt.Sprintf("Spawned subagent '%s' as %s for task: %s", label, queued.ID, task))
+		}
+		return AsyncResult(fmt.Sprintf("Spawned subagent %s for task: %s", queued.ID, task))
+	}
+
 	// Build system prompt for spawned subagent
 	systemPrompt := fmt.Sprintf(
 		`You are a spawned subagent running in the background. Complete the given task independently and report back when done.
@@ -27,7 +27,7 @@ func (t *SpawnStatusTool) Name() string {
 func (t *SpawnStatusTool) Description() string {
 	return "Get the status of spawned subagents. " +
 		"Returns a list of all subagents and their current state " +
-		"(running, completed, failed, or canceled), or retrieves details " +
+		"(queued, running, completed, failed, or canceled), or retrieves details " +
 		"for a specific subagent task when task_id is provided. " +
 		"Results are scoped to the current conversation's channel and chat ID; " +
 		"all tasks are listed only when no channel/chat context is injected " +
@@ -128,7 +128,7 @@ func (t *SpawnStatusTool) Execute(ctx context.Context, args map[string]any) *Too
 
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Subagent status report (%d total):\n", len(tasks)))
-	for _, status := range []string{"running", "completed", "failed", "canceled"} {
+	for _, status := range []string{TaskQueued, TaskRunning, TaskCompleted, TaskFailed, TaskCanceled} {
 		if n := counts[status]; n > 0 {
 			label := strings.ToUpper(status[:1]) + status[1:] + ":"
 			sb.WriteString(fmt.Sprintf("  %-10s %d\n", label, n))
@@ -155,6 +155,12 @@ func spawnStatusFormatTask(task *SubagentTask) string {
 	if task.AgentID != "" {
 		header += fmt.Sprintf("  agent=%s", task.AgentID)
 	}
+	if task.Priority != 0 {
+		header += fmt.Sprintf("  priority=%d", task.Priority)
+	}
+	if task.Attempts > 1 {
+		header += fmt.Sprintf("  attempts=%d", task.Attempts)
+	}
 	if task.Created > 0 {
 		created := time.UnixMilli(task.Created).UTC().Format("2006-01-02 15:04:05 UTC")
 		header += fmt.Sprintf("  created=%s", created)
@@ -164,15 +170,21 @@ func spawnStatusFormatTask(task *SubagentTask) string {
 	if task.Task != "" {
 		sb.WriteString(fmt.Sprintf("\n  task:   %s", task.Task))
 	}
+	if task.Output != "" {
+		sb.WriteString(fmt.Sprintf("\n  output: %s", spawnStatusTruncate(task.Output)))
+	}
 	if task.Result != "" {
-		result := task.Result
-		const maxResultLen = 300
-		runes := []rune(result)
-		if len(runes) > maxResultLen {
-			result = string(runes[:maxResultLen]) + "…"
-		}
-		sb.WriteString(fmt.Sprintf("\n  result: %s", result))
+		sb.WriteString(fmt.Sprintf("\n  result: %s", spawnStatusTruncate(task.Result)))
 	}
 
 	return sb.String()
 }
+
+func spawnStatusTruncate(text string) string {
+	const maxLen = 300
+	runes := []rune(text)
+	if len(runes) > maxLen {
+		return string(runes[:maxLen]) + "…"
+	}
+	return text
+}
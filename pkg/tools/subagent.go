@@ -32,16 +32,25 @@ type SubTurnConfig struct {
 	InitialTokenBudget *atomic.Int64 // Shared token budget for team members; nil if no budget
 }
 
+// SubagentTask is a spawned subagent task. With a task queue enabled it is
+// persisted, so its inputs, progress and result survive restarts.
 type SubagentTask struct {
-	ID            string
-	Task          string
-	Label         string
-	AgentID       string
-	OriginChannel string
-	OriginChatID  string
-	Status        string
-	Result        string
-	Created       int64
+	ID            string `json:"id"`
+	Task          string `json:"task"`
+	Label         string `json:"label,omitempty"`
+	AgentID       string `json:"agent_id,omitempty"`
+	OriginChannel string `json:"origin_channel,omitempty"`
+	OriginChatID  string `json:"origin_chat_id,omitempty"`
+	Priority      int    `json:"priority,omitempty"`
+	Status        string `json:"status"`
+	Output        string `json:"output,omitempty"` // partial output while running
+	Result        string `json:"result,omitempty"`
+	Attempts      int    `json:"attempts,omitempty"`
+	RerunOf       string `json:"rerun_of,omitempty"`
+	Delivered     bool   `json:"delivered,omitempty"`
+	Created       int64  `json:"created"`
+	Started       int64  `json:"started,omitempty"`
+	Finished      int64  `json:"finished,omitempty"`
 }
 
 type SpawnSubTurnFunc func(
@@ -73,6 +82,16 @@ type SubagentManager struct {
 	// This lets subagents reuse the same media handling behavior as the
 	// main agent loop without importing pkg/agent and creating a cycle.
 	mediaResolver func([]providers.Message) []providers.Message
+
+	// Task queue state, set up by EnableQueue.
+	storePath     string
+	queueOpts     TaskQueueOptions
+	queueCtx      context.Context
+	stopQueue     context.CancelFunc
+	running       int
+	cancels       map[string]context.CancelFunc
+	callbacks     map[string]AsyncCallback
+	resultHandler TaskResultHandler
 }
 
 func NewSubagentManager(
@@ -96,6 +115,15 @@ func (sm *SubagentManager) SetSpawner(spawner SpawnSubTurnFunc) {
 	sm.spawner = spawner
 }
 
+// SetProvider replaces the provider and default model, e.g. after a config
+// reload. Queued and running tasks are kept.
+func (sm *SubagentManager) SetProvider(provider providers.LLMProvider, defaultModel string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.provider = provider
+	sm.defaultModel = defaultModel
+}
+
 // SetMediaResolver injects a message preprocessor that resolves media:// refs
 // into LLM-ready content before each tool-loop iteration.
 // This is only used by the legacy RunToolLoop fallback path.
@@ -150,7 +178,7 @@ func (sm *SubagentManager) Spawn(
 		AgentID:       agentID,
 		OriginChannel: originChannel,
 		OriginChatID:  originChatID,
-		Status:        "running",
+		Status:        TaskRunning,
 		Created:       time.Now().UnixMilli(),
 	}
 	sm.tasks[taskID] = subagentTask
@@ -169,7 +197,7 @@ func (sm *SubagentManager) runTask(
 	task *SubagentTask,
 	callback AsyncCallback,
 ) {
-	task.Status = "running"
+	task.Status = TaskRunning
 	task.Created = time.Now().UnixMilli()
 	// TODO(eventbus): once subagents are modeled as child turns inside
 	// pkg/agent, emit SubTurnEnd and SubTurnResultDelivered from the parent
@@ -179,13 +207,49 @@ func (sm *SubagentManager) runTask(
 	select {
 	case <-ctx.Done():
 		sm.mu.Lock()
-		task.Status = "canceled"
+		task.Status = TaskCanceled
 		task.Result = "Task canceled before execution"
 		sm.mu.Unlock()
 		return
 	default:
 	}
 
+	result, err := sm.execute(ctx, task)
+
+	sm.mu.Lock()
+	defer func() {
+		sm.mu.Unlock()
+		// Call callback if provided and result is set
+		if callback != nil && result != nil {
+			callback(ctx, result)
+		}
+	}()
+
+	if err != nil {
+		task.Status = TaskFailed
+		task.Result = fmt.Sprintf("Error: %v", err)
+		// Check if it was canceled
+		if ctx.Err() != nil {
+			task.Status = TaskCanceled
+			task.Result = "Task canceled during execution"
+		}
+		result = &ToolResult{
+			ForLLM:  task.Result,
+			ForUser: "",
+			Silent:  false,
+			IsError: true,
+			Async:   false,
+			Err:     err,
+		}
+	} else {
+		task.Status = TaskCompleted
+		task.Result = result.ForLLM
+	}
+}
+
+// execute runs a task through the spawner, or through RunToolLoop when no
+// spawner is set.
+func (sm *SubagentManager) execute(ctx context.Context, task *SubagentTask) (*ToolResult, error) {
 	sm.mu.RLock()
 	spawner := sm.spawner
 	tools := sm.tools
@@ -195,13 +259,12 @@ func (sm *SubagentManager) runTask(
 	hasMaxTokens := sm.hasMaxTokens
 	hasTemperature := sm.hasTemperature
 	mediaResolver := sm.mediaResolver
+	provider := sm.provider
+	defaultModel := sm.defaultModel
 	sm.mu.RUnlock()
 
-	var result *ToolResult
-	var err error
-
 	if spawner != nil {
-		result, err = spawner(
+		return spawner(
 			ctx,
 			task.Task,
 			task.Label,
@@ -212,83 +275,53 @@ func (sm *SubagentManager) runTask(
 			hasMaxTokens,
 			hasTemperature,
 		)
-	} else {
-		// Fallback to legacy RunToolLoop
-		systemPrompt := `You are a subagent. Complete the given task independently and report the result.
+	}
+
+	// Fallback to legacy RunToolLoop
+	systemPrompt := `You are a subagent. Complete the given task independently and report the result.
 You have access to tools - use them as needed to complete your task.
 After completing the task, provide a clear summary of what was done.`
 
-		messages := []providers.Message{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: task.Task},
-		}
+	messages := []providers.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: task.Task},
+	}
 
-		var llmOptions map[string]any
-		if hasMaxTokens || hasTemperature {
-			llmOptions = map[string]any{}
-			if hasMaxTokens {
-				llmOptions["max_tokens"] = maxTokens
-			}
-			if hasTemperature {
-				llmOptions["temperature"] = temperature
-			}
+	var llmOptions map[string]any
+	if hasMaxTokens || hasTemperature {
+		llmOptions = map[string]any{}
+		if hasMaxTokens {
+			llmOptions["max_tokens"] = maxTokens
 		}
-
-		var loopResult *ToolLoopResult
-		loopResult, err = RunToolLoop(ctx, ToolLoopConfig{
-			Provider:      sm.provider,
-			Model:         sm.defaultModel,
-			Tools:         tools,
-			MaxIterations: maxIter,
-			LLMOptions:    llmOptions,
-			MediaResolver: mediaResolver,
-		}, messages, task.OriginChannel, task.OriginChatID)
-
-		if err == nil {
-			result = &ToolResult{
-				ForLLM: fmt.Sprintf(
-					"Subagent '%s' completed (iterations: %d): %s",
-					task.Label,
-					loopResult.Iterations,
-					loopResult.Content,
-				),
-				ForUser: loopResult.Content,
-				Silent:  false,
-				IsError: false,
-				Async:   false,
-			}
+		if hasTemperature {
+			llmOptions["temperature"] = temperature
 		}
 	}
 
-	sm.mu.Lock()
-	defer func() {
-		sm.mu.Unlock()
-		// Call callback if provided and result is set
-		if callback != nil && result != nil {
-			callback(ctx, result)
-		}
-	}()
-
+	loopResult, err := RunToolLoop(ctx, ToolLoopConfig{
+		Provider:      provider,
+		Model:         defaultModel,
+		Tools:         tools,
+		MaxIterations: maxIter,
+		LLMOptions:    llmOptions,
+		MediaResolver: mediaResolver,
+	}, messages, task.OriginChannel, task.OriginChatID)
 	if err != nil {
-		task.Status = "failed"
-		task.Result = fmt.Sprintf("Error: %v", err)
-		// Check if it was canceled
-		if ctx.Err() != nil {
-			task.Status = "canceled"
-			task.Result = "Task canceled during execution"
-		}
-		result = &ToolResult{
-			ForLLM:  task.Result,
-			ForUser: "",
-			Silent:  false,
-			IsError: true,
-			Async:   false,
-			Err:     err,
-		}
-	} else {
-		task.Status = "completed"
-		task.Result = result.ForLLM
+		return nil, err
 	}
+
+	return &ToolResult{
+		ForLLM: fmt.Sprintf(
+			"Subagent '%s' completed (iterations: %d): %s",
+			task.Label,
+			loopResult.Iterations,
+			loopResult.Content,
+		),
+		ForUser: loopResult.Content,
+		Silent:  false,
+		IsError: false,
+		Async:   false,
+	}, nil
 }
 
 func (sm *SubagentManager) GetTask(taskID string) (*SubagentTask, bool) {
@@ -0,0 +1,506 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Subagent task states. Queued tasks wait for a free slot; completed, failed
+// and canceled tasks are finished.
+const (
+	TaskQueued    = "queued"
+	TaskRunning   = "running"
+	TaskCompleted = "completed"
+	TaskFailed    = "failed"
+	TaskCanceled  = "canceled"
+)
+
+const (
+	defaultTaskConcurrency = 2
+	defaultKeepFinished    = 100
+	// maxTaskOutputRunes bounds the partial output kept for a running task.
+	maxTaskOutputRunes = 4000
+)
+
+// ErrQueueDisabled is returned by queue operations on a manager without a
+// task store.
+var ErrQueueDisabled = errors.New("task queue not enabled")
+
+// TaskQueueOptions controls how queued subagent tasks are scheduled.
+type TaskQueueOptions struct {
+	// MaxConcurrent caps the number of tasks running at once. Default: 2.
+	MaxConcurrent int
+	// ResumeInterrupted re-queues tasks that were running when the process
+	// stopped. When false they are marked failed instead.
+	ResumeInterrupted bool
+	// KeepFinished is the number of finished tasks kept in the store.
+	// Default: 100.
+	KeepFinished int
+}
+
+func (o TaskQueueOptions) withDefaults() TaskQueueOptions {
+	if o.MaxConcurrent <= 0 {
+		o.MaxConcurrent = defaultTaskConcurrency
+	}
+	if o.KeepFinished <= 0 {
+		o.KeepFinished = defaultKeepFinished
+	}
+	return o
+}
+
+// TaskRequest describes a task to enqueue.
+type TaskRequest struct {
+	Task          string
+	Label         string
+	AgentID       string
+	OriginChannel string
+	OriginChatID  string
+	// Priority orders queued tasks; higher runs first.
+	Priority int
+}
+
+// TaskResultHandler delivers the result of a task whose spawning turn is
+// gone, e.g. after a restart or for a task re-run via /tasks.
+type TaskResultHandler func(task SubagentTask, result *ToolResult)
+
+type taskStore struct {
+	NextID int             `json:"next_id"`
+	Tasks  []*SubagentTask `json:"tasks"`
+}
+
+var ctxKeyTaskProgress = &toolCtxKey{"taskProgress"}
+
+// WithTaskProgress returns a child context carrying a reporter for partial
+// task output.
+func WithTaskProgress(ctx context.Context, report func(output string)) context.Context {
+	return context.WithValue(ctx, ctxKeyTaskProgress, report)
+}
+
+// TaskProgress extracts the partial output reporter from ctx, or nil if unset.
+func TaskProgress(ctx context.Context) func(output string) {
+	report, _ := ctx.Value(ctxKeyTaskProgress).(func(string))
+	return report
+}
+
+// EnableQueue makes the manager persist its tasks to path and run them through
+// a queue. Tasks that were running when the store was last written are
+// re-queued or marked failed, depending on opts.ResumeInterrupted. Queued tasks
+// start once Start is called or another task is enqueued.
+func (sm *SubagentManager) EnableQueue(path string, opts TaskQueueOptions) error {
+	var store taskStore
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read task store: %w", err)
+	default:
+		if err = json.Unmarshal(data, &store); err != nil {
+			return fmt.Errorf("parse task store: %w", err)
+		}
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.storePath = path
+	sm.queueOpts = opts.withDefaults()
+	sm.cancels = make(map[string]context.CancelFunc)
+	sm.callbacks = make(map[string]AsyncCallback)
+	if store.NextID > sm.nextID {
+		sm.nextID = store.NextID
+	}
+
+	interrupted := 0
+	now := time.Now().UnixMilli()
+	for _, task := range store.Tasks {
+		if task == nil || task.ID == "" {
+			continue
+		}
+		if task.Status == TaskRunning {
+			interrupted++
+			if sm.queueOpts.ResumeInterrupted {
+				task.Status = TaskQueued
+			} else {
+				task.Status = TaskFailed
+				task.Result = "Interrupted by restart"
+				task.Finished = now
+			}
+		}
+		sm.tasks[task.ID] = task
+	}
+
+	if interrupted > 0 {
+		logger.InfoCF("subagent", "Recovered interrupted tasks", map[string]any{
+			"count":  interrupted,
+			"resume": sm.queueOpts.ResumeInterrupted,
+		})
+		return sm.saveLocked()
+	}
+	return nil
+}
+
+// SetQueueOptions updates the scheduling options of an enabled queue.
+func (sm *SubagentManager) SetQueueOptions(opts TaskQueueOptions) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.queueOpts = opts.withDefaults()
+	if sm.queueCtx != nil {
+		sm.dispatchLocked()
+	}
+}
+
+// SetResultHandler sets how results are delivered when the spawning turn's
+// callback is not available.
+func (sm *SubagentManager) SetResultHandler(handler TaskResultHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.resultHandler = handler
+}
+
+// QueueEnabled reports whether tasks are persisted and queued.
+func (sm *SubagentManager) QueueEnabled() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.storePath != ""
+}
+
+// Start runs queued tasks and delivers results that were finished but not yet
+// delivered before the last shutdown.
+func (sm *SubagentManager) Start() {
+	sm.mu.Lock()
+	if sm.storePath == "" || sm.queueCtx != nil {
+		sm.mu.Unlock()
+		return
+	}
+	sm.queueCtx, sm.stopQueue = context.WithCancel(context.Background())
+
+	var undelivered []SubagentTask
+	for _, task := range sm.tasks {
+		if (task.Status == TaskCompleted || task.Status == TaskFailed) && !task.Delivered {
+			undelivered = append(undelivered, *task)
+		}
+	}
+	sm.dispatchLocked()
+	sm.mu.Unlock()
+
+	for _, task := range undelivered {
+		sm.deliver(task, nil, &ToolResult{ForLLM: task.Result, IsError: task.Status == TaskFailed})
+	}
+}
+
+// Stop stops running tasks without finishing them, so they resume (or fail,
+// depending on the options) when the queue is enabled again.
+func (sm *SubagentManager) Stop() {
+	sm.mu.Lock()
+	stop := sm.stopQueue
+	sm.queueCtx, sm.stopQueue = nil, nil
+	sm.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// Enqueue records a task and runs it when a slot is free. callback receives
+// the result if the process is still running when the task finishes; the
+// result handler is used otherwise.
+func (sm *SubagentManager) Enqueue(req TaskRequest, callback AsyncCallback) (SubagentTask, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.storePath == "" {
+		return SubagentTask{}, ErrQueueDisabled
+	}
+	task := sm.enqueueLocked(req, callback)
+	return *task, nil
+}
+
+func (sm *SubagentManager) enqueueLocked(req TaskRequest, callback AsyncCallback) *SubagentTask {
+	task := &SubagentTask{
+		ID:            fmt.Sprintf("subagent-%d", sm.nextID),
+		Task:          req.Task,
+		Label:         req.Label,
+		AgentID:       req.AgentID,
+		OriginChannel: req.OriginChannel,
+		OriginChatID:  req.OriginChatID,
+		Priority:      req.Priority,
+		Status:        TaskQueued,
+		Created:       time.Now().UnixMilli(),
+	}
+	sm.nextID++
+	sm.tasks[task.ID] = task
+	if callback != nil {
+		sm.callbacks[task.ID] = callback
+	}
+
+	// Tasks enqueued before Start (e.g. in one-shot CLI mode) run right away.
+	if sm.queueCtx == nil {
+		sm.queueCtx, sm.stopQueue = context.WithCancel(context.Background())
+	}
+	sm.dispatchLocked()
+	sm.saveOrWarnLocked()
+	return task
+}
+
+// CancelTask cancels a queued or running task.
+func (sm *SubagentManager) CancelTask(taskID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.storePath == "" {
+		return ErrQueueDisabled
+	}
+	task, ok := sm.tasks[taskID]
+	if !ok {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	switch task.Status {
+	case TaskQueued:
+		task.Result = "Task canceled before execution"
+		delete(sm.callbacks, taskID)
+	case TaskRunning:
+		task.Result = "Task canceled during execution"
+		if cancel := sm.cancels[taskID]; cancel != nil {
+			cancel()
+		}
+	default:
+		return fmt.Errorf("task %s is already %s", taskID, task.Status)
+	}
+	task.Status = TaskCanceled
+	task.Finished = time.Now().UnixMilli()
+	sm.saveOrWarnLocked()
+	return nil
+}
+
+// RerunTask enqueues a finished task again as a new task. Its result goes to
+// the result handler.
+func (sm *SubagentManager) RerunTask(taskID string) (SubagentTask, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.storePath == "" {
+		return SubagentTask{}, ErrQueueDisabled
+	}
+	old, ok := sm.tasks[taskID]
+	if !ok {
+		return SubagentTask{}, fmt.Errorf("task %s not found", taskID)
+	}
+	if old.Status == TaskQueued || old.Status == TaskRunning {
+		return SubagentTask{}, fmt.Errorf("task %s is still %s", taskID, old.Status)
+	}
+
+	task := sm.enqueueLocked(TaskRequest{
+		Task:          old.Task,
+		Label:         old.Label,
+		AgentID:       old.AgentID,
+		OriginChannel: old.OriginChannel,
+		OriginChatID:  old.OriginChatID,
+		Priority:      old.Priority,
+	}, nil)
+	task.RerunOf = old.ID
+	sm.saveOrWarnLocked()
+	return *task, nil
+}
+
+// dispatchLocked starts queued tasks, highest priority first, until the
+// concurrency limit is reached.
+func (sm *SubagentManager) dispatchLocked() {
+	if sm.queueCtx == nil {
+		return
+	}
+	started := false
+	for sm.running < sm.queueOpts.MaxConcurrent {
+		task := sm.nextQueuedLocked()
+		if task == nil {
+			break
+		}
+		ctx, cancel := context.WithCancel(sm.queueCtx)
+		sm.cancels[task.ID] = cancel
+		sm.running++
+		task.Status = TaskRunning
+		task.Started = time.Now().UnixMilli()
+		task.Attempts++
+		started = true
+		go sm.runQueued(ctx, sm.queueCtx, *task)
+	}
+	if started {
+		sm.saveOrWarnLocked()
+	}
+}
+
+func (sm *SubagentManager) nextQueuedLocked() *SubagentTask {
+	var next *SubagentTask
+	for _, task := range sm.tasks {
+		if task.Status != TaskQueued {
+			continue
+		}
+		if next == nil || task.Priority > next.Priority ||
+			(task.Priority == next.Priority && taskLess(task, next)) {
+			next = task
+		}
+	}
+	return next
+}
+
+// taskLess orders tasks by creation time, then by their numeric ID suffix.
+func taskLess(a, b *SubagentTask) bool {
+	if a.Created != b.Created {
+		return a.Created < b.Created
+	}
+	return taskNumber(a.ID) < taskNumber(b.ID)
+}
+
+func taskNumber(id string) int {
+	n, _ := strconv.Atoi(id[strings.LastIndexByte(id, '-')+1:])
+	return n
+}
+
+func (sm *SubagentManager) runQueued(ctx, queueCtx context.Context, task SubagentTask) {
+	ctx = WithToolContext(ctx, task.OriginChannel, task.OriginChatID)
+	ctx = WithTaskProgress(ctx, func(output string) { sm.recordOutput(task.ID, output) })
+
+	result, err := sm.execute(ctx, &task)
+
+	sm.mu.Lock()
+	current, ok := sm.tasks[task.ID]
+	callback := sm.callbacks[task.ID]
+	delete(sm.cancels, task.ID)
+	delete(sm.callbacks, task.ID)
+	sm.running--
+	if !ok || current.Status == TaskCanceled {
+		sm.dispatchLocked()
+		sm.mu.Unlock()
+		return
+	}
+	if err != nil && queueCtx.Err() != nil {
+		// The queue was stopped; leave the task running so it is recovered
+		// on the next start.
+		sm.mu.Unlock()
+		return
+	}
+
+	if err != nil {
+		current.Status = TaskFailed
+		current.Result = fmt.Sprintf("Error: %v", err)
+		result = &ToolResult{ForLLM: current.Result, IsError: true, Err: err}
+	} else {
+		if result == nil {
+			result = &ToolResult{}
+		}
+		current.Status = TaskCompleted
+		current.Result = result.ForLLM
+	}
+	current.Output = ""
+	current.Finished = time.Now().UnixMilli()
+	finished := *current
+	sm.pruneLocked()
+	sm.dispatchLocked()
+	sm.saveOrWarnLocked()
+	sm.mu.Unlock()
+
+	sm.deliver(finished, callback, result)
+}
+
+// deliver hands a result to the spawning turn's callback, or to the result
+// handler when the callback is gone, and records the delivery.
+func (sm *SubagentManager) deliver(task SubagentTask, callback AsyncCallback, result *ToolResult) {
+	sm.mu.RLock()
+	handler := sm.resultHandler
+	sm.mu.RUnlock()
+
+	switch {
+	case callback != nil:
+		callback(context.Background(), result)
+	case handler != nil:
+		handler(task, result)
+	default:
+		return
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if current, ok := sm.tasks[task.ID]; ok {
+		current.Delivered = true
+		sm.saveOrWarnLocked()
+	}
+}
+
+// recordOutput keeps the latest partial output of a running task in memory.
+// It is not written to the store: progress can be reported many times a
+// second, and rewriting the store each time wears out the flash of small
+// boards. The output is saved with the next status change instead.
+func (sm *SubagentManager) recordOutput(taskID, output string) {
+	if runes := []rune(output); len(runes) > maxTaskOutputRunes {
+		output = "…" + string(runes[len(runes)-maxTaskOutputRunes:])
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if task, ok := sm.tasks[taskID]; ok && task.Status == TaskRunning {
+		task.Output = output
+	}
+}
+
+// pruneLocked drops the oldest finished tasks beyond KeepFinished. Results
+// that still await delivery are kept.
+func (sm *SubagentManager) pruneLocked() {
+	var finished []*SubagentTask
+	for _, task := range sm.tasks {
+		switch task.Status {
+		case TaskCanceled:
+			finished = append(finished, task)
+		case TaskCompleted, TaskFailed:
+			if task.Delivered {
+				finished = append(finished, task)
+			}
+		}
+	}
+	excess := len(finished) - sm.queueOpts.KeepFinished
+	if excess <= 0 {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].Finished < finished[j].Finished })
+	for _, task := range finished[:excess] {
+		delete(sm.tasks, task.ID)
+	}
+}
+
+func (sm *SubagentManager) saveOrWarnLocked() {
+	if err := sm.saveLocked(); err != nil {
+		logger.WarnCF("subagent", "Failed to save task store", map[string]any{
+			"path":  sm.storePath,
+			"error": err.Error(),
+		})
+	}
+}
+
+func (sm *SubagentManager) saveLocked() error {
+	if sm.storePath == "" {
+		return nil
+	}
+	store := taskStore{NextID: sm.nextID, Tasks: make([]*SubagentTask, 0, len(sm.tasks))}
+	for _, task := range sm.tasks {
+		store.Tasks = append(store.Tasks, task)
+	}
+	sort.Slice(store.Tasks, func(i, j int) bool { return taskLess(store.Tasks[i], store.Tasks[j]) })
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(sm.storePath), 0o755); err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(sm.storePath, data, 0o600)
+}
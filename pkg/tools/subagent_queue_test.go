@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// queueSpawner is a controllable SpawnSubTurnFunc. Tasks named "block" wait
+// until released or canceled; others finish immediately.
+type queueSpawner struct {
+	mu      sync.Mutex
+	order   []string
+	release chan struct{}
+}
+
+func newQueueSpawner() *queueSpawner {
+	return &queueSpawner{release: make(chan struct{})}
+}
+
+func (s *queueSpawner) spawn(
+	ctx context.Context,
+	task, label, agentID string,
+	_ *ToolRegistry,
+	_ int,
+	_ float64,
+	_, _ bool,
+) (*ToolResult, error) {
+	s.mu.Lock()
+	s.order = append(s.order, label)
+	s.mu.Unlock()
+
+	if task == "block" {
+		if report := TaskProgress(ctx); report != nil {
+			report("halfway there")
+		}
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &ToolResult{ForLLM: "done: " + label, ForUser: "done"}, nil
+}
+
+func (s *queueSpawner) labels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.order...)
+}
+
+func newQueueManager(t *testing.T, path string, opts TaskQueueOptions) (*SubagentManager, *queueSpawner) {
+	t.Helper()
+	sm := NewSubagentManager(&MockLLMProvider{}, "test-model", t.TempDir())
+	spawner := newQueueSpawner()
+	sm.SetSpawner(spawner.spawn)
+	if err := sm.EnableQueue(path, opts); err != nil {
+		t.Fatalf("EnableQueue: %v", err)
+	}
+	t.Cleanup(sm.Stop)
+	return sm, spawner
+}
+
+func waitForTask(t *testing.T, sm *SubagentManager, id string, cond func(SubagentTask) bool) SubagentTask {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if task, ok := sm.GetTaskCopy(id); ok && cond(task) {
+			return task
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	task, _ := sm.GetTaskCopy(id)
+	t.Fatalf("task %s never reached the expected state: %+v", id, task)
+	return task
+}
+
+func waitForStatus(t *testing.T, sm *SubagentManager, id, status string) SubagentTask {
+	t.Helper()
+	return waitForTask(t, sm, id, func(task SubagentTask) bool { return task.Status == status })
+}
+
+// waitForOutput waits until a blocking task has reported its partial output.
+func waitForOutput(t *testing.T, sm *SubagentManager, id string) SubagentTask {
+	t.Helper()
+	return waitForTask(t, sm, id, func(task SubagentTask) bool { return task.Output == "halfway there" })
+}
+
+func TestSubagentQueue_ResumesInterruptedTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	first, _ := newQueueManager(t, path, TaskQueueOptions{ResumeInterrupted: true})
+
+	queued, err := first.Enqueue(TaskRequest{
+		Task:          "block",
+		Label:         "research",
+		OriginChannel: "telegram",
+		OriginChatID:  "42",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	running := waitForOutput(t, first, queued.ID)
+	if running.Status != TaskRunning || running.Attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", running.Attempts)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "halfway there") {
+		t.Fatal("partial output was written to the task store")
+	}
+	// Simulate a shutdown while the task runs.
+	first.Stop()
+
+	second, spawner := newQueueManager(t, path, TaskQueueOptions{ResumeInterrupted: true})
+	task, ok := second.GetTaskCopy(queued.ID)
+	if !ok || task.Status != TaskQueued {
+		t.Fatalf("recovered task = %+v, want queued", task)
+	}
+
+	delivered := make(chan SubagentTask, 1)
+	second.SetResultHandler(func(task SubagentTask, result *ToolResult) {
+		if result.ForLLM != "done: research" {
+			t.Errorf("result = %q", result.ForLLM)
+		}
+		delivered <- task
+	})
+	close(spawner.release)
+	second.Start()
+
+	select {
+	case got := <-delivered:
+		if got.OriginChannel != "telegram" || got.OriginChatID != "42" || got.Attempts != 2 {
+			t.Fatalf("delivered task = %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("result was not delivered")
+	}
+
+	waitForTask(t, second, queued.ID, func(task SubagentTask) bool { return task.Delivered })
+
+	// A later start sees the delivered result and does not deliver it again.
+	third, _ := newQueueManager(t, path, TaskQueueOptions{})
+	task, _ = third.GetTaskCopy(queued.ID)
+	if task.Status != TaskCompleted || !task.Delivered {
+		t.Fatalf("persisted task = %+v, want completed and delivered", task)
+	}
+}
+
+func TestSubagentQueue_FailsInterruptedTasksWithoutResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	first, _ := newQueueManager(t, path, TaskQueueOptions{})
+	queued, _ := first.Enqueue(TaskRequest{Task: "block", OriginChannel: "slack", OriginChatID: "C1"}, nil)
+	waitForOutput(t, first, queued.ID)
+	first.Stop()
+
+	second, _ := newQueueManager(t, path, TaskQueueOptions{})
+	delivered := make(chan *ToolResult, 1)
+	second.SetResultHandler(func(_ SubagentTask, result *ToolResult) { delivered <- result })
+	second.Start()
+
+	select {
+	case result := <-delivered:
+		if !result.IsError || result.ForLLM != "Interrupted by restart" {
+			t.Fatalf("result = %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("failure was not delivered")
+	}
+}
+
+func TestSubagentQueue_PriorityAndConcurrency(t *testing.T) {
+	sm, spawner := newQueueManager(t, filepath.Join(t.TempDir(), "tasks.json"), TaskQueueOptions{MaxConcurrent: 1})
+
+	blocker, _ := sm.Enqueue(TaskRequest{Task: "block", Label: "blocker"}, nil)
+	waitForStatus(t, sm, blocker.ID, TaskRunning)
+	low, _ := sm.Enqueue(TaskRequest{Task: "quick", Label: "low"}, nil)
+	high, _ := sm.Enqueue(TaskRequest{Task: "quick", Label: "high", Priority: 5}, nil)
+	if task, _ := sm.GetTaskCopy(low.ID); task.Status != TaskQueued {
+		t.Fatalf("low priority task status = %q, want queued", task.Status)
+	}
+
+	close(spawner.release)
+	waitForStatus(t, sm, low.ID, TaskCompleted)
+	waitForStatus(t, sm, high.ID, TaskCompleted)
+
+	got := spawner.labels()
+	want := []string{"blocker", "high", "low"}
+	if len(got) != len(want) {
+		t.Fatalf("run order = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("run order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSubagentQueue_CancelAndRerun(t *testing.T) {
+	sm, spawner := newQueueManager(t, filepath.Join(t.TempDir(), "tasks.json"), TaskQueueOptions{MaxConcurrent: 1})
+
+	running, _ := sm.Enqueue(TaskRequest{Task: "block", Label: "long"}, nil)
+	waitForOutput(t, sm, running.ID)
+	queued, _ := sm.Enqueue(TaskRequest{Task: "quick", Label: "next"}, nil)
+
+	if err := sm.CancelTask(queued.ID); err != nil {
+		t.Fatalf("cancel queued: %v", err)
+	}
+	if err := sm.CancelTask(running.ID); err != nil {
+		t.Fatalf("cancel running: %v", err)
+	}
+	waitForStatus(t, sm, running.ID, TaskCanceled)
+	if err := sm.CancelTask(running.ID); err == nil {
+		t.Fatal("expected an error when canceling a finished task")
+	}
+	if got := spawner.labels(); len(got) != 1 {
+		t.Fatalf("canceled queued task ran: %v", got)
+	}
+
+	close(spawner.release)
+	rerun, err := sm.RerunTask(queued.ID)
+	if err != nil {
+		t.Fatalf("RerunTask: %v", err)
+	}
+	if rerun.ID == queued.ID || rerun.RerunOf != queued.ID || rerun.Label != "next" {
+		t.Fatalf("rerun = %+v", rerun)
+	}
+	waitForStatus(t, sm, rerun.ID, TaskCompleted)
+
+	if _, err := sm.RerunTask("subagent-999"); err == nil {
+		t.Fatal("expected an error for an unknown task")
+	}
+}
+
+func TestSpawnTool_QueuesTaskWithOrigin(t *testing.T) {
+	sm, _ := newQueueManager(t, filepath.Join(t.TempDir(), "tasks.json"), TaskQueueOptions{})
+	tool := NewSpawnTool(sm)
+
+	done := make(chan *ToolResult, 1)
+	ctx := WithToolContext(context.Background(), "discord", "chan-1")
+	result := tool.ExecuteAsync(ctx, map[string]any{"task": "quick", "label": "summary", "priority": float64(3)},
+		func(_ context.Context, result *ToolResult) { done <- result })
+	if result.IsError || !result.Async {
+		t.Fatalf("result = %+v, want async success", result)
+	}
+
+	select {
+	case res := <-done:
+		if res.ForLLM != "done: summary" {
+			t.Fatalf("callback result = %q", res.ForLLM)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not called")
+	}
+
+	tasks := sm.ListTaskCopies()
+	if len(tasks) != 1 {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	task := tasks[0]
+	if task.OriginChannel != "discord" || task.OriginChatID != "chan-1" || task.Priority != 3 {
+		t.Fatalf("task = %+v", task)
+	}
+}
+
+func TestSubagentQueue_DisabledWithoutStore(t *testing.T) {
+	sm := NewSubagentManager(&MockLLMProvider{}, "test-model", t.TempDir())
+	if _, err := sm.Enqueue(TaskRequest{Task: "x"}, nil); !errors.Is(err, ErrQueueDisabled) {
+		t.Fatalf("err = %v, want ErrQueueDisabled", err)
+	}
+}
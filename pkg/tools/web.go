@@ -15,8 +15,10 @@ import (
 	"strings"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/extract"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
@@ -1095,6 +1097,7 @@ type WebFetchTool struct {
 	format          string
 	fetchLimitBytes int64
 	whitelist       *privateHostWhitelist
+	pages           fetchedPages
 }
 
 type privateHostWhitelist struct {
@@ -1173,7 +1176,9 @@ func (t *WebFetchTool) Name() string {
 }
 
 func (t *WebFetchTool) Description() string {
-	return "Fetch a URL and extract readable content (HTML to text). Use this to get weather info, news, articles, or any web content."
+	return "Fetch a URL and extract readable content: the main text of web pages with title, author and date, " +
+		"and the text of PDF, DOCX, XLSX, CSV and EPUB files. Long content is returned in pages; pass the returned " +
+		"continuation token to read the next page. Use this to get weather info, news, articles, or any web content."
 }
 
 func (t *WebFetchTool) Parameters() map[string]any {
@@ -1189,14 +1194,33 @@ func (t *WebFetchTool) Parameters() map[string]any {
 				"description": "Maximum characters to extract",
 				"minimum":     100.0,
 			},
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "Character offset to start reading the extracted content from",
+				"minimum":     0.0,
+			},
+			"continuation": map[string]any{
+				"type":        "string",
+				"description": "Token from a truncated result to fetch its next page; replaces url and offset",
+			},
 		},
-		"required": []string{"url"},
 	}
 }
 
 func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
-	urlStr, ok := args["url"].(string)
-	if !ok {
+	urlStr, _ := args["url"].(string)
+	maxChars := t.maxChars
+	offset := 0
+	if token, ok := args["continuation"].(string); ok && token != "" {
+		cont, err := decodeFetchContinuation(token)
+		if err != nil {
+			return ErrorResult(err.Error())
+		}
+		urlStr, offset, maxChars = cont.URL, cont.Offset, cont.MaxChars
+	} else if o, ok := args["offset"].(float64); ok && o > 0 {
+		offset = int(o)
+	}
+	if urlStr == "" {
 		return ErrorResult("url is required")
 	}
 
@@ -1220,13 +1244,81 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		return ErrorResult("fetching private or local network hosts is not allowed")
 	}
 
-	maxChars := t.maxChars
 	if mc, ok := args["maxChars"].(float64); ok {
 		if int(mc) > 100 {
 			maxChars = int(mc)
 		}
 	}
+	if maxChars <= 0 {
+		maxChars = t.maxChars
+	}
+
+	page, ok := t.pages.get(urlStr)
+	if !ok || offset == 0 {
+		var errResult *ToolResult
+		if page, errResult = t.fetch(ctx, urlStr); errResult != nil {
+			return errResult
+		}
+		t.pages.put(urlStr, page)
+	}
+	total := utf8.RuneCountInString(page.text)
+	if offset > total {
+		return ErrorResult(
+			fmt.Sprintf("offset %d is past the end of the content (%d characters)", offset, total),
+		)
+	}
+
+	text, next := pageSlice(page.text, offset, maxChars)
+	truncated := next > 0
+	if truncated {
+		text += truncationNotice
+	}
+
+	result := map[string]any{
+		"url":       urlStr,
+		"status":    page.status,
+		"extractor": page.extractor,
+		"truncated": truncated,
+		"length":    len(text),
+		"text":      text,
+	}
+	for key, value := range map[string]string{
+		"title":         page.meta.Title,
+		"author":        page.meta.Author,
+		"published":     page.meta.Published,
+		"canonical_url": page.meta.CanonicalURL,
+		"site_name":     page.meta.SiteName,
+	} {
+		if value != "" {
+			result[key] = value
+		}
+	}
+	if offset > 0 || truncated {
+		result["offset"] = offset
+		result["total_length"] = total
+	}
+	if truncated {
+		result["next_offset"] = next
+		result["continuation"] = fetchContinuation{URL: urlStr, Offset: next, MaxChars: maxChars}.encode()
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+
+	return &ToolResult{
+		ForLLM: string(resultJSON),
+		ForUser: fmt.Sprintf(
+			"Fetched %d bytes from %s (extractor: %s, truncated: %v)",
+			len(text),
+			urlStr,
+			page.extractor,
+			truncated,
+		),
+	}
+}
 
+// fetch downloads urlStr and extracts its text. A non-nil *ToolResult is
+// the error to return to the model.
+func (t *WebFetchTool) fetch(ctx context.Context, urlStr string) (*fetchedPage, *ToolResult) {
 	doFetch := func(ua string) (*http.Response, []byte, error) {
 		req, reqErr := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 		if reqErr != nil {
@@ -1251,14 +1343,14 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	if err != nil {
 		var maxBytesErr *http.MaxBytesError
 		if errors.As(err, &maxBytesErr) {
-			return ErrorResult(
+			return nil, ErrorResult(
 				fmt.Sprintf(
 					"failed to read response: size exceeded %d bytes limit",
 					t.fetchLimitBytes,
 				),
 			)
 		}
-		return ErrorResult(err.Error())
+		return nil, ErrorResult(err.Error())
 	}
 
 	// Cloudflare (and similar WAFs) signal bot challenges with 403 + cf-mitigated: challenge.
@@ -1278,11 +1370,11 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		} else {
 			var maxBytesErr *http.MaxBytesError
 			if errors.As(err2, &maxBytesErr) {
-				return ErrorResult(
+				return nil, ErrorResult(
 					fmt.Sprintf("failed to read response: size exceeded %d bytes limit", t.fetchLimitBytes),
 				)
 			}
-			return ErrorResult(err2.Error())
+			return nil, ErrorResult(err2.Error())
 		}
 	}
 
@@ -1315,6 +1407,8 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 	}
 
 	var text, extractor string
+	var meta extract.Metadata
+	kind, isDocument := extract.Detect(mediaType, body, urlStr)
 
 	switch {
 	case mediaType == "application/json":
@@ -1335,52 +1429,61 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		text = string(formatted)
 		extractor = "json"
 
-	case mediaType == "text/html" || looksLikeHTML(bodyStr):
+	case mediaType == "text/html" || (!isDocument && looksLikeHTML(bodyStr)):
+		pageURL := urlStr
+		if resp.Request != nil && resp.Request.URL != nil {
+			pageURL = resp.Request.URL.String()
+		}
+		article, err := extract.HTML(bodyStr, pageURL)
+		if err == nil {
+			meta = article.Metadata
+		}
+		// Pages without a clear article body are converted whole.
+		mainContent := err == nil && article.MainContent
+
 		switch strings.ToLower(t.format) {
 		case "markdown":
+			source := bodyStr
+			if mainContent {
+				source = article.HTML
+			}
 			var err error
-			text, err = utils.HtmlToMarkdown(bodyStr)
+			text, err = utils.HtmlToMarkdown(source)
 			if err != nil {
-				return ErrorResult(fmt.Sprintf("failed to HTML to markdown: %v", err))
+				return nil, ErrorResult(fmt.Sprintf("failed to HTML to markdown: %v", err))
 			}
 			extractor = "markdown"
 
 		default:
-			text = t.extractText(bodyStr)
+			if mainContent {
+				text = article.Text
+			} else {
+				text = t.extractText(bodyStr)
+			}
 			extractor = "text"
 		}
 
+	case isDocument:
+		doc, err := extract.Convert(kind, body)
+		if err != nil {
+			return nil, ErrorResult(fmt.Sprintf("failed to extract text from %s: %v", kind, err))
+		}
+		text = doc.Text
+		meta = doc.Metadata
+		extractor = string(kind)
+
 	default:
 		text = bodyStr
 		extractor = "raw"
 	}
 
-	truncated := len(text) > maxChars
-	if truncated {
-		text = text[:maxChars] + "\n[Content truncated due to size limit]"
-	}
-
-	result := map[string]any{
-		"url":       urlStr,
-		"status":    resp.StatusCode,
-		"extractor": extractor,
-		"truncated": truncated,
-		"length":    len(text),
-		"text":      text,
-	}
-
-	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-
-	return &ToolResult{
-		ForLLM: string(resultJSON),
-		ForUser: fmt.Sprintf(
-			"Fetched %d bytes from %s (extractor: %s, truncated: %v)",
-			len(text),
-			urlStr,
-			extractor,
-			truncated,
-		),
-	}
+	return &fetchedPage{
+		status:    resp.StatusCode,
+		extractor: extractor,
+		text:      text,
+		meta:      meta,
+		fetchedAt: time.Now(),
+	}, nil
 }
 
 func looksLikeHTML(body string) bool {
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/extract"
+)
+
+const (
+	fetchedPageTTL   = 10 * time.Minute
+	maxFetchedPages  = 32
+	truncationNotice = "\n[Content truncated due to size limit]"
+)
+
+// fetchedPage is the extracted text of one URL. It is kept for a while so
+// that later pages of a long document are cut from the same text instead
+// of a fresh fetch that may have changed in between.
+type fetchedPage struct {
+	status    int
+	extractor string
+	text      string
+	meta      extract.Metadata
+	fetchedAt time.Time
+}
+
+// fetchedPages is a small cache of fetchedPage by URL.
+type fetchedPages struct {
+	mu    sync.Mutex
+	pages map[string]*fetchedPage
+}
+
+func (c *fetchedPages) get(url string) (*fetchedPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page, ok := c.pages[url]
+	if !ok || time.Since(page.fetchedAt) > fetchedPageTTL {
+		return nil, false
+	}
+	return page, true
+}
+
+func (c *fetchedPages) put(url string, page *fetchedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pages == nil {
+		c.pages = make(map[string]*fetchedPage)
+	}
+	for key, p := range c.pages {
+		if time.Since(p.fetchedAt) > fetchedPageTTL {
+			delete(c.pages, key)
+		}
+	}
+	if len(c.pages) >= maxFetchedPages {
+		var oldest string
+		for key, p := range c.pages {
+			if oldest == "" || p.fetchedAt.Before(c.pages[oldest].fetchedAt) {
+				oldest = key
+			}
+		}
+		delete(c.pages, oldest)
+	}
+	c.pages[url] = page
+}
+
+// fetchContinuation is the state behind a continuation token: where the
+// next page of a document starts and how long pages are.
+type fetchContinuation struct {
+	URL      string `json:"u"`
+	Offset   int    `json:"o"`
+	MaxChars int    `json:"m"`
+}
+
+func (c fetchContinuation) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeFetchContinuation(token string) (fetchContinuation, error) {
+	var c fetchContinuation
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || json.Unmarshal(raw, &c) != nil || c.URL == "" || c.Offset < 0 {
+		return c, errors.New("invalid continuation token")
+	}
+	return c, nil
+}
+
+// pageSlice cuts up to maxChars characters of text starting at the
+// character offset. next is the offset of the following page, or 0 if this
+// is the last one.
+func pageSlice(text string, offset, maxChars int) (page string, next int) {
+	rest := text[runeIndex(text, offset):]
+	end := runeIndex(rest, maxChars)
+	if end >= len(rest) {
+		return rest, 0
+	}
+	return rest[:end], offset + maxChars
+}
+
+// runeIndex returns the byte index of the n-th character of text, or
+// len(text) if it has fewer characters.
+func runeIndex(text string, n int) int {
+	for i := range text {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(text)
+}
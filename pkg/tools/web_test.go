@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -559,6 +561,185 @@ func TestWebTool_WebFetch_HTMLExtraction(t *testing.T) {
 	}
 }
 
+// TestWebTool_WebFetch_MainContent verifies that page chrome is dropped and
+// metadata is reported for article pages.
+func TestWebTool_WebFetch_MainContent(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	paragraph := "<p>The harbour was quiet that morning, and the boats, painted red and blue, waited for the tide.</p>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>Harbour</title><meta name="author" content="Jo Doe">
+<meta property="article:published_time" content="2024-06-01"><link rel="canonical" href="/harbour"></head>
+<body><nav><a href="/">Home</a> <a href="/news">News</a></nav>
+<div class="content">` + strings.Repeat(paragraph, 4) + `</div>
+<div class="sidebar">Trending now everywhere</div></body></html>`))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("NewWebFetchTool() error: %v", err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/story?id=1"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+
+	var resultMap map[string]any
+	if err := json.Unmarshal([]byte(result.ForLLM), &resultMap); err != nil {
+		t.Fatalf("failed to unmarshal result JSON: %v", err)
+	}
+	text, _ := resultMap["text"].(string)
+	if !strings.Contains(text, "The harbour was quiet") {
+		t.Errorf("expected article text, got: %q", text)
+	}
+	if strings.Contains(text, "Home") || strings.Contains(text, "Trending") {
+		t.Errorf("expected navigation and sidebar to be dropped, got: %q", text)
+	}
+	want := map[string]string{
+		"title":         "Harbour",
+		"author":        "Jo Doe",
+		"published":     "2024-06-01",
+		"canonical_url": server.URL + "/harbour",
+	}
+	for key, value := range want {
+		if resultMap[key] != value {
+			t.Errorf("%s = %v, want %q", key, resultMap[key], value)
+		}
+	}
+}
+
+// TestWebTool_WebFetch_Pagination verifies that a continuation token returns
+// the next page of the same extracted text without fetching again.
+func TestWebTool_WebFetch_Pagination(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	content := strings.Repeat("0123456789", 25) // 250 bytes
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(100, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("NewWebFetchTool() error: %v", err)
+	}
+
+	var pages []string
+	args := map[string]any{"url": server.URL}
+	for i := 0; i < 5; i++ {
+		result := tool.Execute(context.Background(), args)
+		if result.IsError {
+			t.Fatalf("page %d: unexpected error: %s", i, result.ForLLM)
+		}
+		var resultMap map[string]any
+		if err := json.Unmarshal([]byte(result.ForLLM), &resultMap); err != nil {
+			t.Fatalf("failed to unmarshal result JSON: %v", err)
+		}
+		text, _ := resultMap["text"].(string)
+		pages = append(pages, strings.TrimSuffix(text, "\n[Content truncated due to size limit]"))
+		token, ok := resultMap["continuation"].(string)
+		if !ok {
+			if truncated, _ := resultMap["truncated"].(bool); truncated {
+				t.Fatalf("page %d: truncated result without continuation", i)
+			}
+			break
+		}
+		args = map[string]any{"continuation": token}
+	}
+
+	if got := strings.Join(pages, ""); got != content {
+		t.Errorf("pages do not add up to the content: %d pages, %q", len(pages), got)
+	}
+	if len(pages) != 3 {
+		t.Errorf("expected 3 pages, got %d", len(pages))
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected later pages to come from the cache, got %d requests", n)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL, "offset": 200.0})
+	if result.IsError || !strings.Contains(result.ForLLM, `"text": "`+content[200:]+`"`) {
+		t.Errorf("expected offset to select the last page, got: %s", result.ForLLM)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"continuation": "not-a-token"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "invalid continuation token") {
+		t.Errorf("expected invalid token error, got: %s", result.ForLLM)
+	}
+}
+
+// TestWebTool_WebFetch_PaginationCountsCharacters verifies that offsets and
+// page sizes count characters rather than bytes.
+func TestWebTool_WebFetch_PaginationCountsCharacters(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	content := strings.Repeat("日本語", 100) // 300 characters, 900 bytes
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(120, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("NewWebFetchTool() error: %v", err)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL})
+	var resultMap map[string]any
+	if err := json.Unmarshal([]byte(result.ForLLM), &resultMap); err != nil {
+		t.Fatalf("failed to unmarshal result JSON: %v", err)
+	}
+	if next, _ := resultMap["next_offset"].(float64); next != 120 {
+		t.Errorf("next_offset = %v, want 120", resultMap["next_offset"])
+	}
+	if total, _ := resultMap["total_length"].(float64); total != 300 {
+		t.Errorf("total_length = %v, want 300", resultMap["total_length"])
+	}
+
+	runes := []rune(content)
+	result = tool.Execute(context.Background(), map[string]any{"url": server.URL, "offset": 250.0})
+	if result.IsError || !strings.Contains(result.ForLLM, `"text": "`+string(runes[250:])+`"`) {
+		t.Errorf("expected offset 250 to select the last 50 characters, got: %s", result.ForLLM)
+	}
+}
+
+// TestWebTool_WebFetch_Document verifies that non-HTML documents are
+// converted to text.
+func TestWebTool_WebFetch_Document(t *testing.T) {
+	withPrivateWebFetchHostsAllowed(t)
+
+	var docx bytes.Buffer
+	zw := zip.NewWriter(&docx)
+	f, _ := zw.Create("word/document.xml")
+	f.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Minutes of the meeting</w:t></w:r></w:p></w:body></w:document>`))
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(docx.Bytes())
+	}))
+	defer server.Close()
+
+	tool, err := NewWebFetchTool(50000, format, testFetchLimit)
+	if err != nil {
+		t.Fatalf("NewWebFetchTool() error: %v", err)
+	}
+	result := tool.Execute(context.Background(), map[string]any{"url": server.URL + "/minutes.docx"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	if !strings.Contains(result.ForLLM, `"text": "Minutes of the meeting"`) ||
+		!strings.Contains(result.ForLLM, `"extractor": "docx"`) {
+		t.Errorf("expected DOCX text, got: %s", result.ForLLM)
+	}
+}
+
 // TestWebFetchTool_extractText verifies text extraction preserves newlines
 func TestWebFetchTool_extractText(t *testing.T) {
 	tool := &WebFetchTool{}
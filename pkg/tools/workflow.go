@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RunWorkflowFunc runs the named workflow with input and returns its output.
+type RunWorkflowFunc func(ctx context.Context, name, input string) (string, error)
+
+// RunWorkflowTool runs a declarative workflow from the workspace's workflows
+// directory. Each step of the workflow runs as a sub-turn of the caller.
+type RunWorkflowTool struct {
+	run RunWorkflowFunc
+}
+
+func NewRunWorkflowTool(run RunWorkflowFunc) *RunWorkflowTool {
+	return &RunWorkflowTool{run: run}
+}
+
+func (t *RunWorkflowTool) Name() string {
+	return "run_workflow"
+}
+
+func (t *RunWorkflowTool) Description() string {
+	return "Run a predefined multi-agent workflow from the workspace 'workflows' directory and return its final output. Use it when the user asks for a task that an existing workflow covers."
+}
+
+func (t *RunWorkflowTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"workflow": map[string]any{
+				"type":        "string",
+				"description": "Name of the workflow to run",
+			},
+			"input": map[string]any{
+				"type":        "string",
+				"description": "Input passed to the workflow's first step",
+			},
+		},
+		"required": []string{"workflow"},
+	}
+}
+
+func (t *RunWorkflowTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if t.run == nil {
+		return ErrorResult("workflows are not available")
+	}
+	name, _ := args["workflow"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrorResult("workflow is required")
+	}
+	input, _ := args["input"].(string)
+
+	output, err := t.run(ctx, name, input)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("workflow %s failed: %v", name, err))
+	}
+	return &ToolResult{ForLLM: output}
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporter_SendsBatchesOnClose(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []otlpRequest
+		paths    []string
+		auth     string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		paths = append(paths, r.URL.Path)
+		auth = r.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	exp, err := NewOTLPExporter(OTLPOptions{
+		Endpoint:      srv.URL,
+		Headers:       map[string]string{"Authorization": "Bearer x"},
+		ServiceName:   "test",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTracer(Options{SampleRatio: 1, Exporter: exp})
+
+	ctx, root := tr.Start(context.Background(), "turn", SpanKindInternal, Bool("ok", true))
+	_, child := tr.Start(ctx, "chat", SpanKindClient, Int("n", 7))
+	child.SetError("failed")
+	child.End()
+	_, tool := tr.Start(ctx, "execute_tool", SpanKindInternal, Float64("f", 0.5))
+	tool.End()
+	root.End()
+	if err := exp.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Spans ended after Close are dropped rather than panicking.
+	_, late := tr.Start(context.Background(), "late", SpanKindInternal)
+	late.End()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2 (batch of 2, then the rest on Close)", len(requests))
+	}
+	if paths[0] != "/v1/traces" || auth != "Bearer x" {
+		t.Errorf("path = %q, auth = %q", paths[0], auth)
+	}
+
+	var spans []otlpSpan
+	for _, req := range requests {
+		rs := req.ResourceSpans[0]
+		if v := rs.Resource.Attributes[0]; v.Key != "service.name" || v.Value["stringValue"] != "test" {
+			t.Errorf("resource attribute = %+v", v)
+		}
+		spans = append(spans, rs.ScopeSpans[0].Spans...)
+	}
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+	chat, rootSpan := spans[0], spans[2]
+	if chat.TraceID != root.SpanContext().TraceID.String() || len(chat.TraceID) != 32 {
+		t.Errorf("chat traceId = %q", chat.TraceID)
+	}
+	if chat.ParentSpanID != rootSpan.SpanID || rootSpan.ParentSpanID != "" {
+		t.Errorf("parent ids = %q, %q", chat.ParentSpanID, rootSpan.ParentSpanID)
+	}
+	if chat.Status.Code != 2 || chat.Status.Message != "failed" {
+		t.Errorf("chat status = %+v", chat.Status)
+	}
+	if chat.Kind != SpanKindClient {
+		t.Errorf("chat kind = %d", chat.Kind)
+	}
+	if v := chat.Attributes[0].Value["intValue"]; v != "7" {
+		t.Errorf("int attribute = %v, want \"7\"", v)
+	}
+	if v := spans[1].Attributes[0].Value["doubleValue"]; v != 0.5 {
+		t.Errorf("double attribute = %v", v)
+	}
+	if v := rootSpan.Attributes[0].Value["boolValue"]; v != true {
+		t.Errorf("bool attribute = %v", v)
+	}
+}
+
+func TestNewOTLPExporter_RejectsBadEndpoint(t *testing.T) {
+	for _, ep := range []string{"", "localhost:4318", "://x"} {
+		if _, err := NewOTLPExporter(OTLPOptions{Endpoint: ep}); err == nil {
+			t.Errorf("NewOTLPExporter(%q) succeeded", ep)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package tracing records spans of agent turns and exports them over
+// OTLP/HTTP. It is a small, dependency-free subset of OpenTelemetry: spans
+// live in a context.Context, trace context is propagated with the W3C
+// traceparent format, and sampling is decided once per trace.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID and SpanID identify traces and spans as in OpenTelemetry.
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// SpanContext is the part of a span that is propagated to children and
+// remote services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether sc identifies a span.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats sc as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses a W3C traceparent header value.
+func ParseTraceparent(s string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+// SpanKind is the OTLP span kind.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindClient   SpanKind = 3
+)
+
+// Attr is a span attribute. Value is a string, bool, int, int64 or float64.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attr          { return Attr{Key: key, Value: value} }
+func Int(key string, value int) Attr         { return Attr{Key: key, Value: int64(value)} }
+func Bool(key string, value bool) Attr       { return Attr{Key: key, Value: value} }
+func Float64(key string, value float64) Attr { return Attr{Key: key, Value: value} }
+
+// SpanData is a finished span handed to an Exporter.
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attr
+	Error         bool
+	StatusMessage string
+}
+
+// Exporter receives finished, sampled spans. Export must not block.
+type Exporter interface {
+	Export(span SpanData)
+	Close() error
+}
+
+// Options configures a Tracer.
+type Options struct {
+	// SampleRatio is the fraction of traces recorded, from 0 to 1. Child
+	// spans follow the decision of their trace's root span.
+	SampleRatio float64
+	Exporter    Exporter
+}
+
+// Tracer starts spans and hands finished ones to its exporter.
+type Tracer struct {
+	threshold uint64
+	exporter  Exporter
+}
+
+// NewTracer creates a tracer.
+func NewTracer(opts Options) *Tracer {
+	ratio := math.Max(0, math.Min(1, opts.SampleRatio))
+	threshold := uint64(ratio * math.MaxUint64)
+	if ratio >= 1 {
+		threshold = math.MaxUint64
+	}
+	return &Tracer{threshold: threshold, exporter: opts.Exporter}
+}
+
+// Shutdown flushes and closes the exporter.
+func (t *Tracer) Shutdown() error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+	return t.exporter.Close()
+}
+
+// Start starts a span that is a child of the span in ctx, or the root of a
+// new trace. The returned context carries the new span.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, attrs ...Attr) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.threshold > 0 && binary.BigEndian.Uint64(sc.TraceID[8:]) <= t.threshold
+	}
+
+	s := &Span{tracer: t, sc: sc}
+	if sc.Sampled {
+		s.data = SpanData{
+			Name:        name,
+			Kind:        kind,
+			SpanContext: sc,
+			Parent:      parent.SpanID,
+			Start:       time.Now(),
+			Attributes:  append([]Attr(nil), attrs...),
+		}
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Span is an operation in a trace. All methods are safe on a nil span and
+// do nothing for spans that are not sampled.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span's context.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttributes adds or replaces attributes.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil || !s.sc.Sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range attrs {
+		replaced := false
+		for i := range s.data.Attributes {
+			if s.data.Attributes[i].Key == a.Key {
+				s.data.Attributes[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.data.Attributes = append(s.data.Attributes, a)
+		}
+	}
+}
+
+// SetError marks the span as failed.
+func (s *Span) SetError(msg string) {
+	if s == nil || !s.sc.Sampled {
+		return
+	}
+	s.mu.Lock()
+	s.data.Error = true
+	s.data.StatusMessage = msg
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed with err, if err is not nil.
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetError(err.Error())
+	}
+}
+
+// End finishes the span and exports it. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil || !s.sc.Sampled {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.tracer.exporter != nil {
+		s.tracer.exporter.Export(data)
+	}
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the span in ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SpanContextFromContext returns the context of the span in ctx.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanFromContext(ctx).SpanContext()
+}
+
+// ContextWithSpan returns a copy of ctx that carries s. Use it to continue
+// a trace in a context that is not derived from the span's own context.
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// Traceparent returns the W3C traceparent of the span in ctx, or "".
+func Traceparent(ctx context.Context) string {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.Traceparent()
+}
+
+// Transport is an http.RoundTripper that adds the traceparent header of the
+// request context's span.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if tp := Traceparent(req.Context()); tp != "" && req.Header.Get("traceparent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("traceparent", tp)
+	}
+	return base.RoundTrip(req)
+}
+
+var global atomic.Pointer[Tracer]
+
+// SetTracer installs the tracer used by Start and returns the previous one.
+// Nil disables tracing.
+func SetTracer(t *Tracer) *Tracer {
+	return global.Swap(t)
+}
+
+// Start starts a span with the tracer installed by SetTracer. Without one it
+// returns ctx and a nil span.
+func Start(ctx context.Context, name string, kind SpanKind, attrs ...Attr) (context.Context, *Span) {
+	return global.Load().Start(ctx, name, kind, attrs...)
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	randomFill(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	randomFill(id[:])
+	return id
+}
+
+func randomFill(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("tracing: random source failed: %v", err))
+	}
+}
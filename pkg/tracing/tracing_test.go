@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(span SpanData) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	e.mu.Unlock()
+}
+
+func (e *recordingExporter) Close() error { return nil }
+
+func (e *recordingExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+func TestTracer_ChildSpansShareTrace(t *testing.T) {
+	exp := &recordingExporter{}
+	tr := NewTracer(Options{SampleRatio: 1, Exporter: exp})
+
+	ctx, root := tr.Start(context.Background(), "turn", SpanKindInternal, String("agent", "main"))
+	_, child := tr.Start(ctx, "chat", SpanKindClient)
+	child.SetAttributes(Int("tokens", 3), Int("tokens", 5))
+	child.SetError("boom")
+	child.End()
+	child.End()
+	root.End()
+
+	spans := exp.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	c, r := spans[0], spans[1]
+	if c.SpanContext.TraceID != r.SpanContext.TraceID {
+		t.Error("child span has a different trace ID")
+	}
+	if c.Parent != r.SpanContext.SpanID {
+		t.Errorf("child parent = %s, want %s", c.Parent, r.SpanContext.SpanID)
+	}
+	if r.Parent != (SpanID{}) {
+		t.Errorf("root parent = %s, want none", r.Parent)
+	}
+	if len(c.Attributes) != 1 || c.Attributes[0].Value != int64(5) {
+		t.Errorf("child attributes = %v, want tokens=5", c.Attributes)
+	}
+	if !c.Error || c.StatusMessage != "boom" {
+		t.Errorf("child status = %v %q, want error boom", c.Error, c.StatusMessage)
+	}
+	if c.Kind != SpanKindClient || r.Kind != SpanKindInternal {
+		t.Errorf("kinds = %d, %d", c.Kind, r.Kind)
+	}
+}
+
+func TestTracer_SampleRatio(t *testing.T) {
+	exp := &recordingExporter{}
+	tr := NewTracer(Options{SampleRatio: 0, Exporter: exp})
+
+	ctx, root := tr.Start(context.Background(), "turn", SpanKindInternal)
+	_, child := tr.Start(ctx, "chat", SpanKindClient)
+	child.End()
+	root.End()
+
+	if got := len(exp.Spans()); got != 0 {
+		t.Errorf("exported %d spans with ratio 0, want 0", got)
+	}
+	if !root.SpanContext().IsValid() || root.SpanContext().Sampled {
+		t.Errorf("unsampled root context = %+v, want valid and not sampled", root.SpanContext())
+	}
+	if child.SpanContext().TraceID != root.SpanContext().TraceID {
+		t.Error("unsampled child left the trace")
+	}
+}
+
+func TestTracer_NilIsNoop(t *testing.T) {
+	var tr *Tracer
+	ctx, span := tr.Start(context.Background(), "turn", SpanKindInternal)
+	span.SetAttributes(String("k", "v"))
+	span.RecordError(context.Canceled)
+	span.End()
+	if SpanFromContext(ctx) != nil || Traceparent(ctx) != "" {
+		t.Error("nil tracer put a span in the context")
+	}
+}
+
+func TestTraceparent_RoundTrip(t *testing.T) {
+	tr := NewTracer(Options{SampleRatio: 1})
+	ctx, span := tr.Start(context.Background(), "turn", SpanKindInternal)
+
+	tp := Traceparent(ctx)
+	sc, ok := ParseTraceparent(tp)
+	if !ok {
+		t.Fatalf("ParseTraceparent(%q) failed", tp)
+	}
+	if sc != span.SpanContext() {
+		t.Errorf("parsed %+v, want %+v", sc, span.SpanContext())
+	}
+
+	for _, bad := range []string{
+		"",
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331",
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01",
+		"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"00-0af7651916cd43dd8448eb211c80319c-zzad6b7169203331-01",
+	} {
+		if _, ok := ParseTraceparent(bad); ok {
+			t.Errorf("ParseTraceparent(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestTransport_InjectsTraceparent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("traceparent")
+	}))
+	defer srv.Close()
+
+	tr := NewTracer(Options{SampleRatio: 1})
+	ctx, span := tr.Start(context.Background(), "tool", SpanKindClient)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if want := span.SpanContext().Traceparent(); got != want {
+		t.Errorf("traceparent = %q, want %q", got, want)
+	}
+}
+
+func TestSetTracer_ReturnsPrevious(t *testing.T) {
+	a := NewTracer(Options{SampleRatio: 1})
+	b := NewTracer(Options{SampleRatio: 1})
+	defer SetTracer(nil)
+
+	SetTracer(a)
+	if prev := SetTracer(b); prev != a {
+		t.Error("SetTracer did not return the previous tracer")
+	}
+	if _, span := Start(context.Background(), "turn", SpanKindInternal); span == nil || span.tracer != b {
+		t.Error("Start did not use the installed tracer")
+	}
+}
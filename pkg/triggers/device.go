@@ -0,0 +1,41 @@
+package triggers
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+func deviceMatches(cfg *config.DeviceTriggerConfig, ev *events.DeviceEvent) bool {
+	if cfg == nil {
+		return true
+	}
+	if cfg.Kind != "" && cfg.Kind != string(ev.Kind) {
+		return false
+	}
+	if len(cfg.Actions) > 0 && !slices.Contains(cfg.Actions, string(ev.Action)) {
+		return false
+	}
+	if cfg.Match != "" {
+		match := strings.ToLower(cfg.Match)
+		if !strings.Contains(strings.ToLower(ev.Vendor+" "+ev.Product), match) {
+			return false
+		}
+	}
+	return true
+}
+
+func deviceEvent(ev *events.DeviceEvent) Event {
+	return Event{Fields: map[string]string{
+		"event":        string(ev.Action),
+		"kind":         string(ev.Kind),
+		"device_id":    ev.DeviceID,
+		"vendor":       ev.Vendor,
+		"product":      ev.Product,
+		"serial":       ev.Serial,
+		"capabilities": ev.Capabilities,
+		"message":      ev.FormatMessage(),
+	}}
+}
@@ -0,0 +1,142 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// fileOps maps the configured event names to fsnotify operations.
+var fileOps = map[string]fsnotify.Op{
+	"create": fsnotify.Create,
+	"write":  fsnotify.Write,
+	"remove": fsnotify.Remove,
+	"rename": fsnotify.Rename,
+	"chmod":  fsnotify.Chmod,
+}
+
+// fileOpOrder decides which name an event with several operations reports.
+var fileOpOrder = []string{"create", "remove", "rename", "write", "chmod"}
+
+// fileSource watches paths for changes. Directories are watched for changes
+// to their entries; with Recursive, subdirectories (including ones created
+// later) are watched as well.
+type fileSource struct {
+	cfg  config.FileTriggerConfig
+	mask fsnotify.Op
+}
+
+func newFileSource(cfg config.FileTriggerConfig) *fileSource {
+	s := &fileSource{cfg: cfg}
+	for _, ev := range cfg.Events {
+		s.mask |= fileOps[ev]
+	}
+	if s.mask == 0 {
+		s.mask = fsnotify.Create | fsnotify.Write | fsnotify.Remove | fsnotify.Rename | fsnotify.Chmod
+	}
+	return s
+}
+
+func (s *fileSource) start(ctx context.Context, emit func(Event)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+
+	for _, p := range s.cfg.Paths {
+		if err := s.add(w, expandHome(p)); err != nil {
+			w.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer w.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if s.cfg.Recursive && ev.Has(fsnotify.Create) {
+					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+						_ = s.add(w, ev.Name)
+					}
+				}
+				if fields, ok := s.match(ev); ok {
+					emit(Event{Fields: fields})
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				logger.WarnCF("triggers", "File watcher error", map[string]any{"error": err.Error()})
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *fileSource) add(w *fsnotify.Watcher, path string) error {
+	if !s.cfg.Recursive {
+		if err := w.Add(path); err != nil {
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+		return nil
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && p != path {
+			return nil
+		}
+		if err := w.Add(p); err != nil {
+			return fmt.Errorf("watch %s: %w", p, err)
+		}
+		return nil
+	})
+}
+
+func (s *fileSource) match(ev fsnotify.Event) (map[string]string, bool) {
+	var op string
+	for _, name := range fileOpOrder {
+		bit := fileOps[name]
+		if ev.Has(bit) && s.mask.Has(bit) {
+			op = name
+			break
+		}
+	}
+	if op == "" {
+		return nil, false
+	}
+	name := filepath.Base(ev.Name)
+	if s.cfg.Pattern != "" {
+		if ok, _ := filepath.Match(s.cfg.Pattern, name); !ok {
+			return nil, false
+		}
+	}
+	return map[string]string{
+		"event": op,
+		"path":  ev.Name,
+		"name":  name,
+	}, true
+}
+
+func expandHome(path string) string {
+	if len(path) > 0 && path[0] == '~' {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,74 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// mqttSource subscribes to a topic and reports every message. The client
+// reconnects and resubscribes on its own.
+type mqttSource struct {
+	name string
+	cfg  config.MQTTTriggerConfig
+}
+
+func newMQTTSource(name string, cfg config.MQTTTriggerConfig) *mqttSource {
+	return &mqttSource{name: name, cfg: cfg}
+}
+
+func (s *mqttSource) start(ctx context.Context, emit func(Event)) error {
+	clientID := s.cfg.ClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("picoclaw-%s-%d", s.name, time.Now().UnixNano()%100000)
+	}
+
+	onMessage := func(_ mqtt.Client, msg mqtt.Message) {
+		emit(Event{Fields: map[string]string{
+			"event":   "message",
+			"topic":   msg.Topic(),
+			"payload": string(msg.Payload()),
+		}})
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.cfg.Broker).
+		SetClientID(clientID).
+		SetUsername(s.cfg.Username).
+		SetPassword(s.cfg.Password.String()).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(10 * time.Second).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			token := c.Subscribe(s.cfg.Topic, s.cfg.QoS, onMessage)
+			if token.WaitTimeout(10*time.Second) && token.Error() != nil {
+				logger.ErrorCF("triggers", "MQTT subscribe failed", map[string]any{
+					"trigger": s.name,
+					"topic":   s.cfg.Topic,
+					"error":   token.Error().Error(),
+				})
+			}
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.WarnCF("triggers", "MQTT connection lost", map[string]any{
+				"trigger": s.name,
+				"error":   err.Error(),
+			})
+		})
+
+	client := mqtt.NewClient(opts)
+	// With ConnectRetry the token completes only once connected; do not
+	// block startup on an unreachable broker.
+	client.Connect()
+
+	go func() {
+		<-ctx.Done()
+		client.Disconnect(250)
+	}()
+	return nil
+}
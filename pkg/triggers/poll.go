@@ -0,0 +1,113 @@
+package triggers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	defaultPollInterval = 5 * time.Minute
+	minPollInterval     = 10 * time.Second
+	maxPollBody         = 64 << 10
+)
+
+// pollSource fetches a URL periodically and reports changes to the body.
+// The first successful poll only records a baseline.
+type pollSource struct {
+	cfg      config.HTTPPollTriggerConfig
+	interval time.Duration
+	client   *http.Client
+	last     [sha256.Size]byte
+	seeded   bool
+}
+
+func newPollSource(cfg config.HTTPPollTriggerConfig) *pollSource {
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	interval = max(interval, minPollInterval)
+	return &pollSource{
+		cfg:      cfg,
+		interval: interval,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *pollSource) start(ctx context.Context, emit func(Event)) error {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.poll(ctx, emit)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *pollSource) poll(ctx context.Context, emit func(Event)) {
+	status, body, err := s.fetch(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.WarnCF("triggers", "HTTP poll failed", map[string]any{
+				"url":   s.cfg.URL,
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	changed := s.seeded && sum != s.last
+	s.last, s.seeded = sum, true
+	if !changed && !s.cfg.Always {
+		return
+	}
+
+	event := "unchanged"
+	if changed {
+		event = "changed"
+	}
+	emit(Event{Fields: map[string]string{
+		"event":  event,
+		"url":    s.cfg.URL,
+		"status": strconv.Itoa(status),
+		"body":   string(body),
+	}})
+}
+
+func (s *pollSource) fetch(ctx context.Context) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.URL, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	for k, v := range s.cfg.Headers.Values() {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPollBody))
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
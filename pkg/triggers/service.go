@@ -0,0 +1,367 @@
+// Package triggers starts agent turns from external events: file changes,
+// signed webhooks, device hotplug, MQTT messages and polled HTTP endpoints.
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// DefaultMaxPerHour applies when a trigger sets no rate limit.
+	DefaultMaxPerHour = 30
+	// defaultFileDebounce coalesces the write bursts editors produce.
+	defaultFileDebounce = 2 * time.Second
+)
+
+// Event is a single occurrence reported by a source. Fields are exposed to
+// the prompt template, e.g. {{.path}} or {{.payload}}.
+type Event struct {
+	Fields map[string]string
+}
+
+// Turn is an agent turn requested by a trigger.
+type Turn struct {
+	Trigger    string
+	AgentID    string
+	SessionKey string
+	Prompt     string
+	Channel    string
+	ChatID     string
+}
+
+// Handler runs a turn. It is called from its own goroutine.
+type Handler func(ctx context.Context, turn Turn) error
+
+// source delivers events for one trigger until ctx is cancelled.
+type source interface {
+	start(ctx context.Context, emit func(Event)) error
+}
+
+// Service owns the configured triggers and their sources.
+type Service struct {
+	handler Handler
+
+	mu       sync.RWMutex
+	rules    []*rule
+	webhooks map[string]*rule
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type rule struct {
+	cfg      config.TriggerConfig
+	tmpl     *template.Template
+	limiter  *rate.Limiter
+	debounce time.Duration
+	src      source
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending *Event
+	merged  int
+	seen    map[string]time.Time // webhook signatures accepted recently
+}
+
+// NewService validates the enabled triggers in cfg. Invalid triggers are
+// logged and skipped so one bad rule does not disable the rest.
+func NewService(cfg config.TriggersConfig, handler Handler) *Service {
+	s := &Service{
+		handler:  handler,
+		webhooks: make(map[string]*rule),
+	}
+	if !cfg.Enabled {
+		return s
+	}
+
+	for _, tc := range cfg.Rules {
+		if !tc.Enabled {
+			continue
+		}
+		r, err := newRule(tc)
+		if err != nil {
+			logger.ErrorCF("triggers", "Invalid trigger, skipping", map[string]any{
+				"trigger": tc.Name,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		s.rules = append(s.rules, r)
+		if tc.Source == config.TriggerSourceWebhook {
+			s.webhooks[tc.Name] = r
+		}
+	}
+	return s
+}
+
+func newRule(tc config.TriggerConfig) (*rule, error) {
+	if err := Validate(tc); err != nil {
+		return nil, err
+	}
+
+	tmpl, _ := parsePrompt(tc.Prompt)
+	maxPerHour := tc.MaxPerHour
+	if maxPerHour <= 0 {
+		maxPerHour = DefaultMaxPerHour
+	}
+	r := &rule{
+		cfg:      tc,
+		tmpl:     tmpl,
+		limiter:  rate.NewLimiter(rate.Every(time.Hour/time.Duration(maxPerHour)), maxPerHour),
+		debounce: time.Duration(tc.DebounceSeconds) * time.Second,
+	}
+
+	switch tc.Source {
+	case config.TriggerSourceFile:
+		if r.debounce == 0 {
+			r.debounce = defaultFileDebounce
+		}
+		r.src = newFileSource(*tc.File)
+	case config.TriggerSourceMQTT:
+		r.src = newMQTTSource(tc.Name, *tc.MQTT)
+	case config.TriggerSourceHTTPPoll:
+		r.src = newPollSource(*tc.HTTPPoll)
+	}
+	return r, nil
+}
+
+// Validate reports whether a trigger is complete enough to run.
+func Validate(tc config.TriggerConfig) error {
+	if tc.Name == "" || strings.ContainsAny(tc.Name, "/ ") {
+		return fmt.Errorf("trigger name must be non-empty and contain no spaces or slashes")
+	}
+	if strings.TrimSpace(tc.Prompt) == "" {
+		return fmt.Errorf("trigger %s: prompt is required", tc.Name)
+	}
+	if _, err := parsePrompt(tc.Prompt); err != nil {
+		return fmt.Errorf("trigger %s: invalid prompt template: %w", tc.Name, err)
+	}
+	if tc.DebounceSeconds < 0 || tc.MaxPerHour < 0 {
+		return fmt.Errorf("trigger %s: debounce_seconds and max_per_hour must not be negative", tc.Name)
+	}
+	if (tc.Channel == "") != (tc.ChatID == "") {
+		return fmt.Errorf("trigger %s: channel and chat_id must be set together", tc.Name)
+	}
+
+	switch tc.Source {
+	case config.TriggerSourceFile:
+		if tc.File == nil || len(tc.File.Paths) == 0 {
+			return fmt.Errorf("trigger %s: file.paths is required", tc.Name)
+		}
+		for _, ev := range tc.File.Events {
+			if _, ok := fileOps[ev]; !ok {
+				return fmt.Errorf("trigger %s: unknown file event %q", tc.Name, ev)
+			}
+		}
+	case config.TriggerSourceWebhook:
+		if tc.Webhook == nil || tc.Webhook.Secret.String() == "" {
+			return fmt.Errorf("trigger %s: webhook.secret is required", tc.Name)
+		}
+	case config.TriggerSourceDevice:
+		if tc.Device == nil {
+			return nil
+		}
+		for _, a := range tc.Device.Actions {
+			switch events.Action(a) {
+			case events.ActionAdd, events.ActionRemove, events.ActionChange:
+			default:
+				return fmt.Errorf("trigger %s: unknown device action %q", tc.Name, a)
+			}
+		}
+	case config.TriggerSourceMQTT:
+		if tc.MQTT == nil || tc.MQTT.Broker == "" || tc.MQTT.Topic == "" {
+			return fmt.Errorf("trigger %s: mqtt.broker and mqtt.topic are required", tc.Name)
+		}
+		if tc.MQTT.QoS > 2 {
+			return fmt.Errorf("trigger %s: mqtt.qos must be 0, 1 or 2", tc.Name)
+		}
+	case config.TriggerSourceHTTPPoll:
+		if tc.HTTPPoll == nil || tc.HTTPPoll.URL == "" {
+			return fmt.Errorf("trigger %s: http_poll.url is required", tc.Name)
+		}
+		if !strings.HasPrefix(tc.HTTPPoll.URL, "http://") && !strings.HasPrefix(tc.HTTPPoll.URL, "https://") {
+			return fmt.Errorf("trigger %s: http_poll.url must be an http(s) URL", tc.Name)
+		}
+	default:
+		return fmt.Errorf("trigger %s: unknown source %q", tc.Name, tc.Source)
+	}
+	return nil
+}
+
+func parsePrompt(text string) (*template.Template, error) {
+	return template.New("prompt").Option("missingkey=zero").Parse(text)
+}
+
+// Start starts every trigger source. Sources that fail to start are logged.
+func (s *Service) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rules) == 0 {
+		logger.InfoC("triggers", "Trigger service disabled or no triggers")
+		return nil
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, r := range s.rules {
+		if r.src == nil {
+			continue
+		}
+		if err := r.src.start(s.ctx, func(ev Event) { s.emit(r, ev) }); err != nil {
+			logger.ErrorCF("triggers", "Failed to start trigger source", map[string]any{
+				"trigger": r.cfg.Name,
+				"source":  r.cfg.Source,
+				"error":   err.Error(),
+			})
+			continue
+		}
+	}
+
+	logger.InfoCF("triggers", "Trigger service started", map[string]any{"triggers": len(s.rules)})
+	return nil
+}
+
+// Stop stops all sources and waits for running turns to finish.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	rules := s.rules
+	s.mu.Unlock()
+
+	for _, r := range rules {
+		r.mu.Lock()
+		if r.timer != nil {
+			r.timer.Stop()
+			r.timer = nil
+		}
+		r.pending = nil
+		r.mu.Unlock()
+	}
+	s.wg.Wait()
+	logger.InfoC("triggers", "Trigger service stopped")
+}
+
+// HandleDeviceEvent feeds a device hotplug event to the device triggers.
+func (s *Service) HandleDeviceEvent(ev *events.DeviceEvent) {
+	if ev == nil {
+		return
+	}
+	s.mu.RLock()
+	rules := s.rules
+	s.mu.RUnlock()
+
+	for _, r := range rules {
+		if r.cfg.Source == config.TriggerSourceDevice && deviceMatches(r.cfg.Device, ev) {
+			s.emit(r, deviceEvent(ev))
+		}
+	}
+}
+
+// emit debounces an event and fires it once the trigger has been quiet for
+// its debounce window.
+func (s *Service) emit(r *rule, ev Event) {
+	ev.Fields = maps.Clone(ev.Fields)
+	if ev.Fields == nil {
+		ev.Fields = make(map[string]string)
+	}
+	ev.Fields["trigger"] = r.cfg.Name
+	ev.Fields["source"] = r.cfg.Source
+	ev.Fields["time"] = time.Now().Format(time.RFC3339)
+
+	if r.debounce <= 0 {
+		s.fire(r, ev, 1)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = &ev
+	r.merged++
+	if r.timer != nil {
+		r.timer.Reset(r.debounce)
+		return
+	}
+	r.timer = time.AfterFunc(r.debounce, func() {
+		r.mu.Lock()
+		pending, merged := r.pending, r.merged
+		r.pending, r.merged, r.timer = nil, 0, nil
+		r.mu.Unlock()
+		if pending != nil {
+			s.fire(r, *pending, merged)
+		}
+	})
+}
+
+func (s *Service) fire(r *rule, ev Event, merged int) {
+	if !r.limiter.Allow() {
+		logger.WarnCF("triggers", "Trigger rate limit reached, dropping event", map[string]any{
+			"trigger": r.cfg.Name,
+		})
+		return
+	}
+
+	ev.Fields["count"] = fmt.Sprint(merged)
+	prompt, err := render(r.tmpl, ev)
+	if err != nil {
+		logger.ErrorCF("triggers", "Failed to render trigger prompt", map[string]any{
+			"trigger": r.cfg.Name,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	s.mu.RLock()
+	ctx := s.ctx
+	s.mu.RUnlock()
+	if ctx == nil || ctx.Err() != nil || s.handler == nil {
+		return
+	}
+
+	turn := Turn{
+		Trigger:    r.cfg.Name,
+		AgentID:    r.cfg.AgentID,
+		SessionKey: "trigger:" + r.cfg.Name,
+		Prompt:     prompt,
+		Channel:    r.cfg.Channel,
+		ChatID:     r.cfg.ChatID,
+	}
+	logger.InfoCF("triggers", "Trigger fired", map[string]any{
+		"trigger": r.cfg.Name,
+		"source":  r.cfg.Source,
+		"merged":  merged,
+	})
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.handler(ctx, turn); err != nil {
+			logger.ErrorCF("triggers", "Trigger turn failed", map[string]any{
+				"trigger": turn.Trigger,
+				"error":   err.Error(),
+			})
+		}
+	}()
+}
+
+func render(tmpl *template.Template, ev Event) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, ev.Fields); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,332 @@
+package triggers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+// turnRecorder collects the turns started by a service.
+type turnRecorder struct {
+	mu    sync.Mutex
+	turns []Turn
+	got   chan struct{}
+}
+
+func newTurnRecorder() *turnRecorder {
+	return &turnRecorder{got: make(chan struct{}, 64)}
+}
+
+func (r *turnRecorder) handle(_ context.Context, turn Turn) error {
+	r.mu.Lock()
+	r.turns = append(r.turns, turn)
+	r.mu.Unlock()
+	r.got <- struct{}{}
+	return nil
+}
+
+func (r *turnRecorder) wait(t *testing.T, n int) []Turn {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for turn %d", i+1)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Turn(nil), r.turns...)
+}
+
+func startService(t *testing.T, rules ...config.TriggerConfig) (*Service, *turnRecorder) {
+	t.Helper()
+	rec := newTurnRecorder()
+	s := NewService(config.TriggersConfig{Enabled: true, Rules: rules}, rec.handle)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(s.Stop)
+	return s, rec
+}
+
+func TestValidate(t *testing.T) {
+	valid := config.TriggerConfig{
+		Name:    "deploy",
+		Source:  config.TriggerSourceWebhook,
+		Prompt:  "Deployment finished: {{.body}}",
+		Webhook: &config.WebhookTriggerConfig{Secret: *config.NewSecureString("s3cret")},
+	}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := map[string]func(*config.TriggerConfig){
+		"missing name":      func(tc *config.TriggerConfig) { tc.Name = "" },
+		"name with slash":   func(tc *config.TriggerConfig) { tc.Name = "a/b" },
+		"missing prompt":    func(tc *config.TriggerConfig) { tc.Prompt = " " },
+		"broken template":   func(tc *config.TriggerConfig) { tc.Prompt = "{{.body" },
+		"unknown source":    func(tc *config.TriggerConfig) { tc.Source = "carrier-pigeon" },
+		"webhook secret":    func(tc *config.TriggerConfig) { tc.Webhook = nil },
+		"half a target":     func(tc *config.TriggerConfig) { tc.Channel = "telegram" },
+		"negative limit":    func(tc *config.TriggerConfig) { tc.MaxPerHour = -1 },
+		"file without path": func(tc *config.TriggerConfig) { tc.Source = config.TriggerSourceFile },
+		"bad file event": func(tc *config.TriggerConfig) {
+			tc.Source = config.TriggerSourceFile
+			tc.File = &config.FileTriggerConfig{Paths: []string{"/tmp"}, Events: []string{"explode"}}
+		},
+		"mqtt without topic": func(tc *config.TriggerConfig) {
+			tc.Source = config.TriggerSourceMQTT
+			tc.MQTT = &config.MQTTTriggerConfig{Broker: "tcp://localhost:1883"}
+		},
+		"poll non-http url": func(tc *config.TriggerConfig) {
+			tc.Source = config.TriggerSourceHTTPPoll
+			tc.HTTPPoll = &config.HTTPPollTriggerConfig{URL: "file:///etc/passwd"}
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc := valid
+			mutate(&tc)
+			if err := Validate(tc); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewService_SkipsDisabledAndInvalid(t *testing.T) {
+	s := NewService(config.TriggersConfig{Enabled: true, Rules: []config.TriggerConfig{
+		{Name: "off", Source: config.TriggerSourceDevice, Prompt: "x"},
+		{Name: "bad", Enabled: true, Source: config.TriggerSourceWebhook, Prompt: "x"},
+		{Name: "usb", Enabled: true, Source: config.TriggerSourceDevice, Prompt: "x"},
+	}}, nil)
+	if len(s.rules) != 1 || s.rules[0].cfg.Name != "usb" {
+		t.Fatalf("rules = %+v, want only usb", s.rules)
+	}
+
+	s = NewService(config.TriggersConfig{Rules: []config.TriggerConfig{
+		{Name: "usb", Enabled: true, Source: config.TriggerSourceDevice, Prompt: "x"},
+	}}, nil)
+	if len(s.rules) != 0 {
+		t.Fatal("expected no rules when triggers are disabled")
+	}
+}
+
+func TestDeviceTrigger_RendersPromptForMatchingEvents(t *testing.T) {
+	s, rec := startService(t, config.TriggerConfig{
+		Name:    "camera",
+		Enabled: true,
+		Source:  config.TriggerSourceDevice,
+		AgentID: "ops",
+		Prompt:  "{{.vendor}} {{.product}} was {{.event}}ed{{.missing}}",
+		Channel: "telegram",
+		ChatID:  "42",
+		Device:  &config.DeviceTriggerConfig{Kind: "usb", Actions: []string{"add"}, Match: "webcam"},
+	})
+
+	s.HandleDeviceEvent(&events.DeviceEvent{Action: events.ActionRemove, Kind: events.KindUSB, Product: "Webcam"})
+	s.HandleDeviceEvent(&events.DeviceEvent{Action: events.ActionAdd, Kind: events.KindUSB, Product: "Keyboard"})
+	s.HandleDeviceEvent(&events.DeviceEvent{Action: events.ActionAdd, Kind: events.KindUSB, Vendor: "Logi", Product: "Webcam C920"})
+
+	turns := rec.wait(t, 1)
+	want := Turn{
+		Trigger:    "camera",
+		AgentID:    "ops",
+		SessionKey: "trigger:camera",
+		Prompt:     "Logi Webcam C920 was added",
+		Channel:    "telegram",
+		ChatID:     "42",
+	}
+	if len(turns) != 1 || turns[0] != want {
+		t.Fatalf("turns = %+v, want %+v", turns, want)
+	}
+}
+
+func TestDebounce_CoalescesBursts(t *testing.T) {
+	s, rec := startService(t, config.TriggerConfig{
+		Name:            "usb",
+		Enabled:         true,
+		Source:          config.TriggerSourceDevice,
+		Prompt:          "{{.count}} events, last {{.product}}",
+		DebounceSeconds: 1,
+	})
+
+	for _, p := range []string{"a", "b", "c"} {
+		s.HandleDeviceEvent(&events.DeviceEvent{Action: events.ActionAdd, Kind: events.KindUSB, Product: p})
+	}
+
+	turns := rec.wait(t, 1)
+	if turns[0].Prompt != "3 events, last c" {
+		t.Fatalf("prompt = %q", turns[0].Prompt)
+	}
+}
+
+func TestRateLimit_DropsExcessEvents(t *testing.T) {
+	s, rec := startService(t, config.TriggerConfig{
+		Name:       "usb",
+		Enabled:    true,
+		Source:     config.TriggerSourceDevice,
+		Prompt:     "plugged",
+		MaxPerHour: 2,
+	})
+
+	for i := 0; i < 5; i++ {
+		s.HandleDeviceEvent(&events.DeviceEvent{Action: events.ActionAdd, Kind: events.KindUSB})
+	}
+	rec.wait(t, 2)
+	select {
+	case <-rec.got:
+		t.Fatal("expected events beyond the hourly limit to be dropped")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWebhook_RequiresValidSignature(t *testing.T) {
+	s, rec := startService(t, config.TriggerConfig{
+		Name:    "deploy",
+		Enabled: true,
+		Source:  config.TriggerSourceWebhook,
+		Prompt:  "Deploy hook: {{.body}}",
+		Webhook: &config.WebhookTriggerConfig{Secret: *config.NewSecureString("s3cret")},
+	})
+	body := []byte(`{"status":"ok"}`)
+
+	post := func(path, timestamp, signature string) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		if timestamp != "" {
+			req.Header.Set(TimestampHeader, timestamp)
+		}
+		if signature != "" {
+			req.Header.Set(SignatureHeader, signature)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w.Code
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	if code := post("/triggers/deploy", now, ""); code != http.StatusUnauthorized {
+		t.Errorf("unsigned request: status %d", code)
+	}
+	if code := post("/triggers/deploy", now, Sign("wrong", now, body)); code != http.StatusUnauthorized {
+		t.Errorf("wrongly signed request: status %d", code)
+	}
+	if code := post("/triggers/deploy", "", Sign("s3cret", "", body)); code != http.StatusUnauthorized {
+		t.Errorf("request without a timestamp: status %d", code)
+	}
+	if code := post("/triggers/other", now, Sign("s3cret", now, body)); code != http.StatusNotFound {
+		t.Errorf("unknown trigger: status %d", code)
+	}
+	if code := post("/triggers/deploy", now, Sign("s3cret", now, body)); code != http.StatusAccepted {
+		t.Fatalf("signed request: status %d", code)
+	}
+
+	turns := rec.wait(t, 1)
+	if turns[0].Prompt != `Deploy hook: {"status":"ok"}` {
+		t.Fatalf("prompt = %q", turns[0].Prompt)
+	}
+}
+
+func TestWebhook_RejectsReplayedRequests(t *testing.T) {
+	s, rec := startService(t, config.TriggerConfig{
+		Name:    "deploy",
+		Enabled: true,
+		Source:  config.TriggerSourceWebhook,
+		Prompt:  "{{.body}}",
+		Webhook: &config.WebhookTriggerConfig{Secret: *config.NewSecureString("s3cret")},
+	})
+	body := []byte(`{"status":"ok"}`)
+
+	post := func(sent time.Time) int {
+		timestamp := strconv.FormatInt(sent.Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/triggers/deploy", bytes.NewReader(body))
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign("s3cret", timestamp, body))
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post(time.Now().Add(-time.Hour)); code != http.StatusUnauthorized {
+		t.Errorf("request signed an hour ago: status %d", code)
+	}
+	if code := post(time.Now().Add(time.Hour)); code != http.StatusUnauthorized {
+		t.Errorf("request signed an hour ahead: status %d", code)
+	}
+	sent := time.Now()
+	if code := post(sent); code != http.StatusAccepted {
+		t.Fatalf("fresh request: status %d", code)
+	}
+	if code := post(sent); code != http.StatusUnauthorized {
+		t.Errorf("replayed request: status %d", code)
+	}
+	rec.wait(t, 1)
+}
+
+func TestHTTPPoll_FiresOnChange(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		body = "v1"
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	src := newPollSource(config.HTTPPollTriggerConfig{URL: srv.URL})
+	var got []Event
+	emit := func(ev Event) { got = append(got, ev) }
+
+	ctx := context.Background()
+	src.poll(ctx, emit)
+	src.poll(ctx, emit)
+	if len(got) != 0 {
+		t.Fatalf("expected no events before a change, got %+v", got)
+	}
+
+	mu.Lock()
+	body = "v2"
+	mu.Unlock()
+	src.poll(ctx, emit)
+	if len(got) != 1 || got[0].Fields["body"] != "v2" || got[0].Fields["event"] != "changed" {
+		t.Fatalf("events = %+v, want one change to v2", got)
+	}
+}
+
+func TestFileTrigger_WatchesMatchingFiles(t *testing.T) {
+	dir := t.TempDir()
+	_, rec := startService(t, config.TriggerConfig{
+		Name:            "inbox",
+		Enabled:         true,
+		Source:          config.TriggerSourceFile,
+		Prompt:          "{{.name}} {{.event}}",
+		DebounceSeconds: 1,
+		File:            &config.FileTriggerConfig{Paths: []string{dir}, Events: []string{"create"}, Pattern: "*.csv"},
+	})
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.csv"), []byte("a,b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	turns := rec.wait(t, 1)
+	if !strings.HasPrefix(turns[0].Prompt, "report.csv create") {
+		t.Fatalf("prompt = %q", turns[0].Prompt)
+	}
+}
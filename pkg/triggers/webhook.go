@@ -0,0 +1,130 @@
+package triggers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// WebhookPathPrefix is where webhook triggers are served: a trigger named
+// "deploy" receives POST requests at /triggers/deploy.
+const WebhookPathPrefix = "/triggers/"
+
+// maxWebhookBody caps the accepted request body.
+const maxWebhookBody = 1 << 20
+
+// Webhook requests carry the Unix time they were sent in TimestampHeader and
+// "sha256=<hex HMAC of timestamp.body>" in SignatureHeader.
+const (
+	SignatureHeader = "X-Picoclaw-Signature"
+	TimestampHeader = "X-Picoclaw-Timestamp"
+)
+
+// maxWebhookSkew is how far the timestamp of a webhook request may be from
+// the gateway's clock. A signature is accepted once within it, so a captured
+// request cannot be replayed.
+const maxWebhookSkew = 5 * time.Minute
+
+// ServeHTTP receives webhook trigger requests.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, WebhookPathPrefix)
+	s.mu.RLock()
+	rule, ok := s.webhooks[name]
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBody {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	now := time.Now()
+	if !validSignature(rule.cfg.Webhook.Secret.String(), body, r.Header, now) {
+		logger.WarnCF("triggers", "Rejected webhook with invalid signature", map[string]any{
+			"trigger": name,
+			"remote":  r.RemoteAddr,
+		})
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if !rule.firstDelivery(r.Header.Get(SignatureHeader), now) {
+		logger.WarnCF("triggers", "Rejected replayed webhook", map[string]any{
+			"trigger": name,
+			"remote":  r.RemoteAddr,
+		})
+		http.Error(w, "replayed request", http.StatusUnauthorized)
+		return
+	}
+
+	s.emit(rule, Event{Fields: map[string]string{
+		"event":        "request",
+		"body":         string(body),
+		"content_type": r.Header.Get("Content-Type"),
+		"query":        r.URL.RawQuery,
+	}})
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Sign returns the SignatureHeader value for body sent with timestamp in
+// TimestampHeader, as expected by webhook triggers.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(secret string, body []byte, header http.Header, now time.Time) bool {
+	timestamp := header.Get(TimestampHeader)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(sent, 0)); skew > maxWebhookSkew || skew < -maxWebhookSkew {
+		return false
+	}
+	want := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(header.Get(SignatureHeader)), []byte(want))
+}
+
+// firstDelivery records a valid signature and reports whether it was not
+// seen before. A signature is forgotten after twice the skew window, when
+// its timestamp can no longer pass validSignature.
+func (r *rule) firstDelivery(signature string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for sig, seen := range r.seen {
+		if now.Sub(seen) > 2*maxWebhookSkew {
+			delete(r.seen, sig)
+		}
+	}
+	if _, ok := r.seen[signature]; ok {
+		return false
+	}
+	if r.seen == nil {
+		r.seen = make(map[string]time.Time)
+	}
+	r.seen[signature] = now
+	return true
+}
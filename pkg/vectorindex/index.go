@@ -0,0 +1,439 @@
+// Package vectorindex is a small on-disk index of embedding vectors with
+// cosine similarity search. It is a flat index: a search scores every
+// vector, which for the few thousand entries a personal agent keeps is
+// faster than maintaining a graph and needs no memory beyond the vectors.
+//
+// Vectors are normalized on insert and kept as float32 in one contiguous
+// slice, so 1,000 vectors of 768 dimensions take about 3 MB. The file is
+// read and written as a stream, without a second copy in memory.
+package vectorindex
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const (
+	fileMagic   = "PCVI"
+	fileVersion = 1
+
+	maxDim      = 1 << 16
+	maxIDLen    = math.MaxUint16
+	maxMetaSize = 1 << 20
+)
+
+var (
+	// ErrDimension is returned when a vector's length differs from the
+	// vectors already in the index.
+	ErrDimension = errors.New("vector dimension does not match the index")
+	// ErrZeroVector is returned for vectors without a direction.
+	ErrZeroVector = errors.New("vector has zero length")
+)
+
+// Item is a vector stored under an ID, with optional metadata to filter on.
+type Item struct {
+	ID       string
+	Vector   []float32
+	Metadata map[string]string
+}
+
+// Result is a search hit. Score is the cosine similarity to the query.
+type Result struct {
+	ID       string
+	Score    float32
+	Metadata map[string]string
+}
+
+// Filter restricts a search to items whose metadata has every key with the
+// given value. A nil filter matches every item.
+type Filter map[string]string
+
+func (f Filter) match(meta map[string]string) bool {
+	for k, v := range f {
+		if got, ok := meta[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Index is a flat cosine vector index. It is safe for concurrent use.
+type Index struct {
+	path string
+
+	mu      sync.RWMutex
+	dim     int
+	ids     []string
+	meta    []map[string]string
+	vectors []float32 // len(ids)*dim, each vector normalized
+	pos     map[string]int
+}
+
+// Open loads the index stored at path. A missing file is an empty index. An
+// empty path gives an index that is never written to disk.
+func Open(path string) (*Index, error) {
+	x := &Index{path: path, pos: make(map[string]int)}
+	if path == "" {
+		return x, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return x, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := x.read(bufio.NewReader(f)); err != nil {
+		return nil, fmt.Errorf("read vector index %s: %w", path, err)
+	}
+	return x, nil
+}
+
+// Len returns the number of stored vectors.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.ids)
+}
+
+// Dim returns the dimension of the stored vectors, 0 while the index is
+// empty.
+func (x *Index) Dim() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.dim
+}
+
+// Has reports whether a vector is stored under id.
+func (x *Index) Has(id string) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	_, ok := x.pos[id]
+	return ok
+}
+
+// Metadata returns the metadata stored with id.
+func (x *Index) Metadata(id string) (map[string]string, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	i, ok := x.pos[id]
+	if !ok {
+		return nil, false
+	}
+	return cloneMeta(x.meta[i]), true
+}
+
+// Upsert adds items, replacing the vectors and metadata of existing IDs, and
+// saves the index. Items are checked before any is stored, so an invalid
+// item leaves the index unchanged.
+func (x *Index) Upsert(items ...Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	dim := x.dim
+	if len(x.ids) == 0 {
+		dim = len(items[0].Vector)
+	}
+	if dim == 0 || dim > maxDim {
+		return fmt.Errorf("%w: %d", ErrDimension, dim)
+	}
+	for _, it := range items {
+		if it.ID == "" || len(it.ID) > maxIDLen {
+			return fmt.Errorf("invalid vector ID %q", it.ID)
+		}
+		if len(it.Vector) != dim {
+			return fmt.Errorf("%w: %s has %d, want %d", ErrDimension, it.ID, len(it.Vector), dim)
+		}
+		if norm(it.Vector) == 0 {
+			return fmt.Errorf("%w: %s", ErrZeroVector, it.ID)
+		}
+	}
+
+	x.dim = dim
+	for _, it := range items {
+		i, ok := x.pos[it.ID]
+		if !ok {
+			i = len(x.ids)
+			x.pos[it.ID] = i
+			x.ids = append(x.ids, it.ID)
+			x.meta = append(x.meta, nil)
+			x.vectors = append(x.vectors, make([]float32, dim)...)
+		}
+		x.meta[i] = cloneMeta(it.Metadata)
+		normalizeInto(x.vectors[i*dim:(i+1)*dim], it.Vector)
+	}
+	return x.save()
+}
+
+// Delete removes the vectors stored under ids, saves the index and returns
+// how many were found.
+func (x *Index) Delete(ids ...string) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	removed := 0
+	for _, id := range ids {
+		i, ok := x.pos[id]
+		if !ok {
+			continue
+		}
+		// Move the last vector into the hole.
+		last := len(x.ids) - 1
+		if i != last {
+			x.ids[i] = x.ids[last]
+			x.meta[i] = x.meta[last]
+			copy(x.vectors[i*x.dim:(i+1)*x.dim], x.vectors[last*x.dim:])
+			x.pos[x.ids[i]] = i
+		}
+		x.ids = x.ids[:last]
+		x.meta = x.meta[:last]
+		x.vectors = x.vectors[:last*x.dim]
+		delete(x.pos, id)
+		removed++
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if len(x.ids) == 0 {
+		x.dim = 0
+	}
+	return removed, x.save()
+}
+
+// Clear removes every vector, for example after switching to an embedding
+// model with another dimension.
+func (x *Index) Clear() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.dim = 0
+	x.ids = nil
+	x.meta = nil
+	x.vectors = nil
+	x.pos = make(map[string]int)
+	return x.save()
+}
+
+// Search returns the k items most similar to query that match filter, best
+// first.
+func (x *Index) Search(query []float32, k int, filter Filter) ([]Result, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if k <= 0 || len(x.ids) == 0 {
+		return nil, nil
+	}
+	if len(query) != x.dim {
+		return nil, fmt.Errorf("%w: query has %d, want %d", ErrDimension, len(query), x.dim)
+	}
+	qn := norm(query)
+	if qn == 0 {
+		return nil, ErrZeroVector
+	}
+
+	type hit struct {
+		i     int
+		score float32
+	}
+	hits := make([]hit, 0, min(len(x.ids), 4*k))
+	for i := range x.ids {
+		if filter != nil && !filter.match(x.meta[i]) {
+			continue
+		}
+		var dot float32
+		for j, v := range x.vectors[i*x.dim : (i+1)*x.dim] {
+			dot += v * query[j]
+		}
+		hits = append(hits, hit{i: i, score: dot / qn})
+	}
+	slices.SortFunc(hits, func(a, b hit) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+
+	out := make([]Result, len(hits))
+	for n, h := range hits {
+		out[n] = Result{ID: x.ids[h.i], Score: h.score, Metadata: cloneMeta(x.meta[h.i])}
+	}
+	return out, nil
+}
+
+// read loads the index from r. The format is a header of magic, version,
+// dimension and count, followed for each item by its ID, its metadata as
+// JSON and its vector, all little-endian.
+func (x *Index) read(r io.Reader) error {
+	magic := make([]byte, len(fileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return err
+	}
+	if string(magic) != fileMagic {
+		return fmt.Errorf("not a vector index file")
+	}
+	var header struct{ Version, Dim, Count uint32 }
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	if header.Version != fileVersion {
+		return fmt.Errorf("unsupported version %d", header.Version)
+	}
+	if header.Dim > maxDim || (header.Dim == 0) != (header.Count == 0) {
+		return fmt.Errorf("invalid dimension %d for %d vectors", header.Dim, header.Count)
+	}
+
+	dim := int(header.Dim)
+	for range header.Count {
+		var idLen uint16
+		if err := binary.Read(r, binary.LittleEndian, &idLen); err != nil {
+			return err
+		}
+		id := make([]byte, idLen)
+		if _, err := io.ReadFull(r, id); err != nil {
+			return err
+		}
+		var metaLen uint32
+		if err := binary.Read(r, binary.LittleEndian, &metaLen); err != nil {
+			return err
+		}
+		if metaLen > maxMetaSize {
+			return fmt.Errorf("metadata of %q too large: %d bytes", id, metaLen)
+		}
+		var meta map[string]string
+		if metaLen > 0 {
+			data := make([]byte, metaLen)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &meta); err != nil {
+				return fmt.Errorf("metadata of %q: %w", id, err)
+			}
+		}
+		start := len(x.vectors)
+		x.vectors = append(x.vectors, make([]float32, dim)...)
+		if err := binary.Read(r, binary.LittleEndian, x.vectors[start:]); err != nil {
+			return err
+		}
+		x.pos[string(id)] = len(x.ids)
+		x.ids = append(x.ids, string(id))
+		x.meta = append(x.meta, meta)
+	}
+	x.dim = dim
+	return nil
+}
+
+// save writes the index to a temporary file and renames it over the old
+// one. Caller must hold x.mu.
+func (x *Index) save() error {
+	if x.path == "" {
+		return nil
+	}
+	dir := filepath.Dir(x.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(x.path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := x.write(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), x.path)
+}
+
+func (x *Index) write(w io.Writer) error {
+	if _, err := io.WriteString(w, fileMagic); err != nil {
+		return err
+	}
+	header := struct{ Version, Dim, Count uint32 }{fileVersion, uint32(x.dim), uint32(len(x.ids))}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	for i, id := range x.ids {
+		if err := binary.Write(w, binary.LittleEndian, uint16(len(id))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, id); err != nil {
+			return err
+		}
+		var meta []byte
+		if len(x.meta[i]) > 0 {
+			var err error
+			if meta, err = json.Marshal(x.meta[i]); err != nil {
+				return err
+			}
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(meta))); err != nil {
+			return err
+		}
+		if _, err := w.Write(meta); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, x.vectors[i*x.dim:(i+1)*x.dim]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func norm(v []float32) float32 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	return float32(math.Sqrt(sum))
+}
+
+func normalizeInto(dst, src []float32) {
+	n := norm(src)
+	for i, f := range src {
+		dst[i] = f / n
+	}
+}
+
+func cloneMeta(meta map[string]string) map[string]string {
+	if len(meta) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(meta))
+	for k, v := range meta {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package vectorindex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndex_UpsertSearchDelete(t *testing.T) {
+	x, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = x.Upsert(
+		Item{ID: "north", Vector: []float32{0, 2}, Metadata: map[string]string{"kind": "fact"}},
+		Item{ID: "east", Vector: []float32{3, 0}, Metadata: map[string]string{"kind": "fact"}},
+		Item{ID: "northeast", Vector: []float32{1, 1}, Metadata: map[string]string{"kind": "skill"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := x.Search([]float32{0.1, 1}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "north" || got[1].ID != "northeast" || got[0].Score < 0.99 {
+		t.Fatalf("Search() = %+v", got)
+	}
+	if got, _ := x.Search([]float32{0.1, 1}, 5, Filter{"kind": "fact"}); len(got) != 2 || got[1].ID != "east" {
+		t.Fatalf("filtered Search() = %+v", got)
+	}
+
+	// Upserting an existing ID replaces its vector and metadata.
+	if err := x.Upsert(Item{ID: "east", Vector: []float32{0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := x.Search([]float32{0, 1}, 5, Filter{"kind": "fact"}); len(got) != 1 {
+		t.Fatalf("metadata not replaced: %+v", got)
+	}
+
+	if n, err := x.Delete("north", "missing"); n != 1 || err != nil {
+		t.Fatalf("Delete() = %d, %v", n, err)
+	}
+	if x.Len() != 2 || x.Has("north") || !x.Has("northeast") {
+		t.Fatalf("after delete: len %d", x.Len())
+	}
+	if got, _ := x.Search([]float32{1, 1}, 1, nil); got[0].ID != "northeast" {
+		t.Fatalf("Search() after delete = %+v", got)
+	}
+}
+
+func TestIndex_Rejects(t *testing.T) {
+	x, _ := Open("")
+	if err := x.Upsert(Item{ID: "a", Vector: []float32{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	err := x.Upsert(Item{ID: "b", Vector: []float32{1, 0}}, Item{ID: "c", Vector: []float32{1, 0, 0}})
+	if !errors.Is(err, ErrDimension) {
+		t.Fatalf("mixed dimensions error = %v", err)
+	}
+	if x.Has("b") {
+		t.Fatal("partial upsert stored an item")
+	}
+	if err := x.Upsert(Item{ID: "z", Vector: []float32{0, 0}}); !errors.Is(err, ErrZeroVector) {
+		t.Fatalf("zero vector error = %v", err)
+	}
+	if _, err := x.Search([]float32{1}, 1, nil); !errors.Is(err, ErrDimension) {
+		t.Fatalf("query dimension error = %v", err)
+	}
+
+	if err := x.Clear(); err != nil || x.Len() != 0 || x.Dim() != 0 {
+		t.Fatalf("Clear() = %v, len %d, dim %d", err, x.Len(), x.Dim())
+	}
+	if err := x.Upsert(Item{ID: "c", Vector: []float32{1, 0, 0}}); err != nil {
+		t.Fatalf("new dimension after Clear() = %v", err)
+	}
+}
+
+func TestIndex_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors", "facts.vec")
+	x, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Upsert(
+		Item{ID: "a", Vector: []float32{1, 0, 0}, Metadata: map[string]string{"source": "telegram"}},
+		Item{ID: "b", Vector: []float32{0, 1, 0}},
+		Item{ID: "c", Vector: []float32{0, 0, 1}},
+	)
+	x.Delete("b")
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 2 || reopened.Dim() != 3 || reopened.Has("b") {
+		t.Fatalf("reopened: len %d, dim %d", reopened.Len(), reopened.Dim())
+	}
+	got, _ := reopened.Search([]float32{1, 0, 0}, 1, Filter{"source": "telegram"})
+	if len(got) != 1 || got[0].ID != "a" || got[0].Metadata["source"] != "telegram" {
+		t.Fatalf("reopened Search() = %+v", got)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+
+	os.WriteFile(path, []byte("garbage"), 0o600)
+	if _, err := Open(path); err == nil {
+		t.Fatal("Open() of a corrupt file succeeded")
+	}
+}
@@ -0,0 +1,255 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// Call is one agent invocation requested by a workflow step.
+type Call struct {
+	Workflow string
+	Step     string
+	AgentID  string // empty means the agent running the workflow
+	Prompt   string
+	Timeout  time.Duration // 0 means the runner's default
+}
+
+// StepFunc runs one agent invocation and returns its reply.
+type StepFunc func(ctx context.Context, call Call) (string, error)
+
+// Data is what prompt templates see.
+type Data struct {
+	Input     string            // workflow input
+	Steps     map[string]string // output of every finished step by ID
+	Previous  string            // output of the previous step; the input for the first one
+	Draft     string            // loop steps: the latest draft
+	Feedback  string            // loop steps: the evaluator's latest reply
+	Iteration int               // loop steps: current iteration, starting at 1
+}
+
+// StepResult records one finished agent invocation.
+type StepResult struct {
+	ID        string
+	AgentID   string
+	Output    string
+	Duration  time.Duration
+	Iteration int  // loop steps only
+	Accepted  bool // loop evaluators only
+}
+
+// Result is the outcome of a workflow run.
+type Result struct {
+	Workflow string
+	Output   string // output of the last step
+	Steps    []StepResult
+}
+
+type runner struct {
+	def  *Definition
+	run  StepFunc
+	data Data
+
+	mu      sync.Mutex
+	results []StepResult
+}
+
+// Run executes def with input, calling run for every agent invocation.
+// Steps run in order; a failing step stops the workflow.
+func Run(ctx context.Context, def *Definition, input string, run StepFunc) (*Result, error) {
+	r := &runner{
+		def:  def,
+		run:  run,
+		data: Data{Input: input, Steps: make(map[string]string), Previous: input},
+	}
+
+	for i := range def.Steps {
+		if err := ctx.Err(); err != nil {
+			return r.result(), err
+		}
+		step := &def.Steps[i]
+		var output string
+		var err error
+		switch step.kind() {
+		case "parallel":
+			output, err = r.runParallel(ctx, step)
+		case "loop":
+			output, err = r.runLoop(ctx, step)
+		default:
+			output, err = r.runAgent(ctx, step, r.data)
+		}
+		if err != nil {
+			return r.result(), fmt.Errorf("step %s: %w", step.ID, err)
+		}
+		r.data.Steps[step.ID] = output
+		r.data.Previous = output
+	}
+	return r.result(), nil
+}
+
+func (r *runner) result() *Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Result{
+		Workflow: r.def.Name,
+		Output:   r.data.Previous,
+		Steps:    append([]StepResult(nil), r.results...),
+	}
+}
+
+func (r *runner) record(res StepResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, res)
+}
+
+func (r *runner) runAgent(ctx context.Context, step *Step, data Data) (string, error) {
+	prompt, err := render(step, data)
+	if err != nil {
+		return "", err
+	}
+	start := time.Now()
+	output, err := r.run(ctx, Call{
+		Workflow: r.def.Name,
+		Step:     step.ID,
+		AgentID:  step.Agent,
+		Prompt:   prompt,
+		Timeout:  step.timeout(),
+	})
+	if err != nil {
+		return "", err
+	}
+	r.record(StepResult{ID: step.ID, AgentID: step.Agent, Output: output, Duration: time.Since(start)})
+	return output, nil
+}
+
+// runParallel fans the branches out and, when the step has one, feeds
+// their outputs to the merge step. Without a merge step the branch outputs
+// are concatenated.
+func (r *runner) runParallel(ctx context.Context, step *Step) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outputs := make([]string, len(step.Parallel))
+	errs := make([]error, len(step.Parallel))
+	var wg sync.WaitGroup
+	for i := range step.Parallel {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			branch := &step.Parallel[i]
+			outputs[i], errs[i] = r.runAgent(ctx, branch, r.data)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", step.Parallel[i].ID, err)
+		}
+	}
+	for i := range step.Parallel {
+		r.data.Steps[step.Parallel[i].ID] = outputs[i]
+	}
+
+	if step.Merge == nil {
+		var sb strings.Builder
+		for i := range step.Parallel {
+			if i > 0 {
+				sb.WriteString("\n\n")
+			}
+			fmt.Fprintf(&sb, "## %s\n%s", step.Parallel[i].ID, outputs[i])
+		}
+		return sb.String(), nil
+	}
+	output, err := r.runAgent(ctx, step.Merge, r.data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", step.Merge.ID, err)
+	}
+	r.data.Steps[step.Merge.ID] = output
+	return output, nil
+}
+
+// runLoop alternates generator and evaluator until the evaluator's reply
+// starts with the accept word or the iteration limit is reached. The last
+// draft is the step's output either way.
+func (r *runner) runLoop(ctx context.Context, step *Step) (string, error) {
+	l := step.Loop
+	data := r.data
+
+	for i := 1; i <= l.MaxIterations; i++ {
+		data.Iteration = i
+		draft, err := r.runAgent(ctx, &l.Generate, data)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", l.Generate.ID, err)
+		}
+		data.Draft = draft
+		r.data.Steps[l.Generate.ID] = draft
+
+		prompt, err := render(&l.Evaluate, data)
+		if err != nil {
+			return "", err
+		}
+		start := time.Now()
+		verdict, err := r.run(ctx, Call{
+			Workflow: r.def.Name,
+			Step:     l.Evaluate.ID,
+			AgentID:  l.Evaluate.Agent,
+			Prompt:   prompt,
+			Timeout:  l.Evaluate.timeout(),
+		})
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", l.Evaluate.ID, err)
+		}
+		accepted := isAccepted(verdict, l.Accept)
+		r.record(StepResult{
+			ID:        l.Evaluate.ID,
+			AgentID:   l.Evaluate.Agent,
+			Output:    verdict,
+			Duration:  time.Since(start),
+			Iteration: i,
+			Accepted:  accepted,
+		})
+		r.data.Steps[l.Evaluate.ID] = verdict
+		if accepted {
+			break
+		}
+		data.Feedback = verdict
+	}
+	return data.Draft, nil
+}
+
+// isAccepted reports whether verdict starts with the words of accept,
+// ignoring case, punctuation and markdown. Only a leading match counts, so
+// "not acceptable" or "Unacceptable" does not accept a draft.
+func isAccepted(verdict, accept string) bool {
+	want := words(accept)
+	got := words(verdict)
+	return len(want) > 0 && len(got) >= len(want) && slices.Equal(got[:len(want)], want)
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+	})
+}
+
+func render(step *Step, data Data) (string, error) {
+	tmpl, err := template.New(step.ID).Option("missingkey=zero").Parse(step.Prompt)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid prompt template: %w", step.ID, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("%s: %w", step.ID, err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,299 @@
+// Package workflow runs declarative multi-agent workflows defined as YAML
+// files in the workspace.
+//
+// A workflow is a list of steps run in order. A step is one of:
+//
+//   - an agent step: one prompt sent to one agent;
+//   - a parallel step: several agent steps run at the same time, optionally
+//     combined by a merge step;
+//   - a loop step: a generator and an evaluator alternate until the
+//     evaluator accepts the draft or max_iterations is reached.
+//
+// Prompts are Go text/templates; see Data for the fields they can use.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirName is the workspace directory holding workflow definitions.
+const DirName = "workflows"
+
+const (
+	defaultMaxIterations = 3
+	maxMaxIterations     = 10
+	defaultAccept        = "ACCEPT"
+)
+
+var validID = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// Definition is a parsed workflow file.
+type Definition struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Steps       []Step `yaml:"steps"`
+
+	// Path is the file the workflow was loaded from.
+	Path string `yaml:"-"`
+}
+
+// Step is one step of a workflow. Exactly one of Prompt, Parallel or Loop
+// selects its type.
+type Step struct {
+	ID      string `yaml:"id"`
+	Agent   string `yaml:"agent"`
+	Prompt  string `yaml:"prompt"`
+	Timeout int    `yaml:"timeout_seconds"`
+
+	Parallel []Step `yaml:"parallel"`
+	Merge    *Step  `yaml:"merge"`
+
+	Loop *Loop `yaml:"loop"`
+}
+
+// Loop is an evaluator/optimizer step.
+type Loop struct {
+	MaxIterations int  `yaml:"max_iterations"`
+	Generate      Step `yaml:"generate"`
+	Evaluate      Step `yaml:"evaluate"`
+	// Accept is the word the evaluator's reply must start with to accept
+	// the draft (case-insensitive). Anything else is treated as feedback.
+	Accept string `yaml:"accept"`
+}
+
+func (s *Step) kind() string {
+	switch {
+	case s.Loop != nil:
+		return "loop"
+	case len(s.Parallel) > 0:
+		return "parallel"
+	default:
+		return "agent"
+	}
+}
+
+func (s *Step) timeout() time.Duration {
+	return time.Duration(s.Timeout) * time.Second
+}
+
+// Dir returns the workflow directory of a workspace.
+func Dir(workspace string) string {
+	return filepath.Join(workspace, DirName)
+}
+
+// Parse parses and validates a workflow definition. name is used when the
+// file does not set one.
+func Parse(data []byte, name string) (*Definition, error) {
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("invalid workflow YAML: %w", err)
+	}
+	if def.Name == "" {
+		def.Name = name
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// LoadFile reads a workflow definition from path.
+func LoadFile(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	def, err := Parse(data, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	def.Path = path
+	return def, nil
+}
+
+// LoadAll loads every *.yaml and *.yml file of dir, sorted by name. Files
+// that fail to load are skipped and reported in the returned error; a
+// missing directory is not an error.
+func LoadAll(dir string) ([]*Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var defs []*Definition
+	var errs []error
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		def, err := LoadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := seen[def.Name]; ok {
+			errs = append(errs, fmt.Errorf("%s: workflow %q is already defined in %s", entry.Name(), def.Name, other))
+			continue
+		}
+		seen[def.Name] = entry.Name()
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, errors.Join(errs...)
+}
+
+// Find returns the workflow called name from dir.
+func Find(dir, name string) (*Definition, error) {
+	defs, loadErr := LoadAll(dir)
+	for _, def := range defs {
+		if strings.EqualFold(def.Name, name) {
+			return def, nil
+		}
+	}
+	if loadErr != nil {
+		return nil, fmt.Errorf("workflow %q not found (%v)", name, loadErr)
+	}
+	return nil, fmt.Errorf("workflow %q not found", name)
+}
+
+// Agents returns the IDs of the agents the steps of d name, in order of
+// first use. Steps without an agent run as the caller and are not listed.
+func (d *Definition) Agents() []string {
+	var ids []string
+	var visit func(s *Step)
+	visit = func(s *Step) {
+		if s.Agent != "" && !slices.Contains(ids, s.Agent) {
+			ids = append(ids, s.Agent)
+		}
+		for i := range s.Parallel {
+			visit(&s.Parallel[i])
+		}
+		if s.Merge != nil {
+			visit(s.Merge)
+		}
+		if s.Loop != nil {
+			visit(&s.Loop.Generate)
+			visit(&s.Loop.Evaluate)
+		}
+	}
+	for i := range d.Steps {
+		visit(&d.Steps[i])
+	}
+	return ids
+}
+
+// Validate checks the step structure and compiles every prompt template.
+func (d *Definition) Validate() error {
+	if d.Name == "" {
+		return errors.New("workflow name is required")
+	}
+	if len(d.Steps) == 0 {
+		return errors.New("workflow has no steps")
+	}
+	ids := make(map[string]bool)
+	for i := range d.Steps {
+		if err := validateStep(&d.Steps[i], ids, true); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateStep(s *Step, ids map[string]bool, topLevel bool) error {
+	if s.ID == "" {
+		return errors.New("id is required")
+	}
+	if !validID.MatchString(s.ID) {
+		return fmt.Errorf("invalid id %q (use letters, digits, '-' and '_')", s.ID)
+	}
+	if ids[s.ID] {
+		return fmt.Errorf("duplicate step id %q", s.ID)
+	}
+	ids[s.ID] = true
+	if s.Timeout < 0 {
+		return fmt.Errorf("%s: timeout_seconds must not be negative", s.ID)
+	}
+
+	set := 0
+	if s.Prompt != "" {
+		set++
+	}
+	if len(s.Parallel) > 0 {
+		set++
+	}
+	if s.Loop != nil {
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("%s: set exactly one of prompt, parallel or loop", s.ID)
+	}
+	if !topLevel && s.kind() != "agent" {
+		return fmt.Errorf("%s: nested %s steps are not supported", s.ID, s.kind())
+	}
+	if s.Merge != nil && s.kind() != "parallel" {
+		return fmt.Errorf("%s: merge is only valid on parallel steps", s.ID)
+	}
+
+	switch s.kind() {
+	case "agent":
+		return checkTemplate(s.ID, s.Prompt)
+	case "parallel":
+		for i := range s.Parallel {
+			if err := validateStep(&s.Parallel[i], ids, false); err != nil {
+				return err
+			}
+		}
+		if s.Merge != nil {
+			if s.Merge.ID == "" {
+				s.Merge.ID = s.ID + "-merge"
+			}
+			return validateStep(s.Merge, ids, false)
+		}
+	case "loop":
+		l := s.Loop
+		if l.MaxIterations == 0 {
+			l.MaxIterations = defaultMaxIterations
+		}
+		if l.MaxIterations < 1 || l.MaxIterations > maxMaxIterations {
+			return fmt.Errorf("%s: max_iterations must be between 1 and %d", s.ID, maxMaxIterations)
+		}
+		if l.Accept == "" {
+			l.Accept = defaultAccept
+		}
+		if l.Generate.ID == "" {
+			l.Generate.ID = s.ID + "-generate"
+		}
+		if l.Evaluate.ID == "" {
+			l.Evaluate.ID = s.ID + "-evaluate"
+		}
+		if err := validateStep(&l.Generate, ids, false); err != nil {
+			return err
+		}
+		return validateStep(&l.Evaluate, ids, false)
+	}
+	return nil
+}
+
+func checkTemplate(id, text string) error {
+	if _, err := template.New(id).Option("missingkey=zero").Parse(text); err != nil {
+		return fmt.Errorf("%s: invalid prompt template: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const reviewYAML = `
+name: review
+description: Research, write and polish
+steps:
+  - id: research
+    parallel:
+      - id: pros
+        agent: researcher
+        prompt: "Pros of {{.Input}}"
+      - id: cons
+        agent: researcher
+        prompt: "Cons of {{.Input}}"
+    merge:
+      agent: writer
+      prompt: "Combine: {{index .Steps \"pros\"}} / {{index .Steps \"cons\"}}"
+  - id: polish
+    loop:
+      max_iterations: 3
+      generate:
+        agent: writer
+        prompt: "Draft from {{.Previous}} (try {{.Iteration}}, feedback: {{.Feedback}})"
+      evaluate:
+        agent: critic
+        prompt: "Judge: {{.Draft}}"
+`
+
+func TestParse_FillsDefaults(t *testing.T) {
+	def, err := Parse([]byte(reviewYAML), "file")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if def.Name != "review" {
+		t.Errorf("Name = %q, want review", def.Name)
+	}
+	if got := def.Steps[0].Merge.ID; got != "research-merge" {
+		t.Errorf("merge id = %q, want research-merge", got)
+	}
+	loop := def.Steps[1].Loop
+	if loop.Generate.ID != "polish-generate" || loop.Evaluate.ID != "polish-evaluate" {
+		t.Errorf("loop ids = %q, %q", loop.Generate.ID, loop.Evaluate.ID)
+	}
+	if loop.Accept != "ACCEPT" {
+		t.Errorf("Accept = %q, want ACCEPT", loop.Accept)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no steps":      "name: x\n",
+		"no id":         "steps:\n  - prompt: hi\n",
+		"duplicate id":  "steps:\n  - id: a\n    prompt: hi\n  - id: a\n    prompt: ho\n",
+		"two kinds":     "steps:\n  - id: a\n    prompt: hi\n    loop:\n      generate: {prompt: x}\n      evaluate: {prompt: y}\n",
+		"nested":        "steps:\n  - id: a\n    parallel:\n      - id: b\n        parallel:\n          - id: c\n            prompt: hi\n",
+		"merge no par":  "steps:\n  - id: a\n    prompt: hi\n    merge: {prompt: x}\n",
+		"bad template":  "steps:\n  - id: a\n    prompt: \"{{.Input\"\n",
+		"too many iter": "steps:\n  - id: a\n    loop:\n      max_iterations: 50\n      generate: {prompt: x}\n      evaluate: {prompt: y}\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(data), "wf"); err == nil {
+				t.Fatal("Parse() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLoadAllAndFind(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("b.yaml", "steps:\n  - id: a\n    prompt: hi\n")
+	write("a.yml", reviewYAML)
+	write("broken.yaml", "steps: [")
+	write("notes.txt", "ignored")
+
+	defs, err := LoadAll(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Fatalf("LoadAll() error = %v, want broken.yaml reported", err)
+	}
+	if len(defs) != 2 || defs[0].Name != "b" || defs[1].Name != "review" {
+		t.Fatalf("LoadAll() = %v", defs)
+	}
+
+	def, err := Find(dir, "Review")
+	if err != nil || def.Name != "review" {
+		t.Fatalf("Find() = %v, %v", def, err)
+	}
+	if _, err := Find(dir, "missing"); err == nil {
+		t.Fatal("Find(missing) error = nil")
+	}
+	if defs, err := LoadAll(filepath.Join(dir, "none")); err != nil || defs != nil {
+		t.Fatalf("LoadAll(missing dir) = %v, %v", defs, err)
+	}
+}
+
+func TestRun_ParallelMergeAndLoop(t *testing.T) {
+	def, err := Parse([]byte(reviewYAML), "review")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var calls []Call
+	judged := 0
+	run := func(ctx context.Context, call Call) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call)
+		switch call.Step {
+		case "pros":
+			return "fast", nil
+		case "cons":
+			return "costly", nil
+		case "research-merge":
+			return "merged(" + call.Prompt + ")", nil
+		case "polish-generate":
+			return "draft: " + call.Prompt, nil
+		case "polish-evaluate":
+			judged++
+			if judged == 2 {
+				return "Accept", nil
+			}
+			return "too long", nil
+		}
+		return "", errors.New("unexpected step " + call.Step)
+	}
+
+	res, err := Run(context.Background(), def, "go", run)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(calls) != 7 {
+		t.Fatalf("calls = %d, want 7", len(calls))
+	}
+	merge := calls[2]
+	if merge.AgentID != "writer" || merge.Prompt != "Combine: fast / costly" {
+		t.Fatalf("merge call = %+v", merge)
+	}
+	second := calls[5]
+	want := "Draft from merged(Combine: fast / costly) (try 2, feedback: too long)"
+	if second.Prompt != want {
+		t.Fatalf("second draft prompt = %q, want %q", second.Prompt, want)
+	}
+	if res.Output != "draft: "+want {
+		t.Fatalf("Output = %q", res.Output)
+	}
+	last := res.Steps[len(res.Steps)-1]
+	if !last.Accepted || last.Iteration != 2 {
+		t.Fatalf("last step = %+v, want accepted on iteration 2", last)
+	}
+}
+
+func TestIsAccepted(t *testing.T) {
+	tests := []struct {
+		verdict string
+		accept  string
+		want    bool
+	}{
+		{"ACCEPT", "ACCEPT", true},
+		{"accept. Looks good to me.", "ACCEPT", true},
+		{"**APPROVED**\nNice work.", "APPROVED", true},
+		{"Looks good, no changes needed.", "LOOKS GOOD", true},
+		{"not acceptable: too long", "ACCEPT", false},
+		{"Unacceptable.", "ACCEPT", false},
+		{"I cannot accept this draft.", "ACCEPT", false},
+		{"Not approved, fix the intro.", "APPROVED", false},
+		{"", "ACCEPT", false},
+	}
+	for _, tt := range tests {
+		if got := isAccepted(tt.verdict, tt.accept); got != tt.want {
+			t.Errorf("isAccepted(%q, %q) = %v, want %v", tt.verdict, tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestRun_StopsOnError(t *testing.T) {
+	def, err := Parse([]byte("steps:\n  - id: a\n    prompt: one\n  - id: b\n    prompt: two\n"), "wf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	_, err = Run(context.Background(), def, "", func(ctx context.Context, call Call) (string, error) {
+		calls++
+		return "", errors.New("boom")
+	})
+	if err == nil || !strings.Contains(err.Error(), "step a") {
+		t.Fatalf("Run() error = %v, want step a failure", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/triggers"
 )
 
 // registerConfigRoutes binds configuration management endpoints to the ServeMux.
@@ -315,6 +316,17 @@ func validateConfig(cfg *config.Config) []string {
 			validateRegexPatterns("tools.exec.custom_allow_patterns", cfg.Tools.Exec.CustomAllowPatterns)...)
 	}
 
+	seenTriggers := make(map[string]bool)
+	for index, rule := range cfg.Triggers.Rules {
+		if err := triggers.Validate(rule); err != nil {
+			errs = append(errs, fmt.Sprintf("triggers.rules[%d]: %v", index, err))
+		}
+		if seenTriggers[rule.Name] {
+			errs = append(errs, fmt.Sprintf("triggers.rules[%d]: duplicate trigger name %q", index, rule.Name))
+		}
+		seenTriggers[rule.Name] = true
+	}
+
 	return errs
 }
 
@@ -373,6 +385,72 @@ func getSecretString(m map[string]any, key string) (string, bool) {
 	return "", false
 }
 
+// applyGatewaySecrets applies the header values of gateway.log.http and
+// gateway.tracing from a request. Reloading .security.yml fills in headers,
+// and the sections that hold them, that the request no longer has; those are
+// dropped again.
+func applyGatewaySecrets(cfg *config.Config, raw map[string]any) {
+	gateway, _ := asMapField(raw, "gateway")
+	if tracingRaw, hasTracing := asMapField(gateway, "tracing"); !hasTracing {
+		cfg.Gateway.Tracing = nil
+	} else if cfg.Gateway.Tracing != nil {
+		cfg.Gateway.Tracing.Headers = applySecretHeaders(cfg.Gateway.Tracing.Headers, tracingRaw)
+	}
+
+	logRaw, hasLog := asMapField(gateway, "log")
+	if !hasLog {
+		cfg.Gateway.Log = nil
+		return
+	}
+	if cfg.Gateway.Log == nil {
+		return
+	}
+	if httpRaw, hasHTTP := asMapField(logRaw, "http"); !hasHTTP {
+		cfg.Gateway.Log.HTTP = nil
+	} else if cfg.Gateway.Log.HTTP != nil {
+		cfg.Gateway.Log.HTTP.Headers = applySecretHeaders(cfg.Gateway.Log.HTTP.Headers, httpRaw)
+	}
+}
+
+// applyTriggerSecrets applies the header values of http_poll triggers from a
+// request, like applyGatewaySecrets.
+func applyTriggerSecrets(cfg *config.Config, raw map[string]any) {
+	triggersRaw, _ := asMapField(raw, "triggers")
+	rulesRaw, _ := triggersRaw["rules"].([]any)
+	for i := range cfg.Triggers.Rules {
+		rule := &cfg.Triggers.Rules[i]
+		if rule.HTTPPoll == nil || i >= len(rulesRaw) {
+			continue
+		}
+		ruleRaw, _ := rulesRaw[i].(map[string]any)
+		pollRaw, _ := asMapField(ruleRaw, "http_poll")
+		rule.HTTPPoll.Headers = applySecretHeaders(rule.HTTPPoll.Headers, pollRaw)
+	}
+}
+
+// applySecretHeaders makes headers match the "headers" object in raw. Values
+// sent back as "[NOT_HERE]" keep their stored value.
+func applySecretHeaders(headers config.SecureHeaders, raw map[string]any) config.SecureHeaders {
+	rawHeaders, _ := asMapField(raw, "headers")
+	for name := range headers {
+		if _, ok := rawHeaders[name]; !ok {
+			delete(headers, name)
+		}
+	}
+	for name, value := range rawHeaders {
+		if v, ok := value.(string); ok && v != "[NOT_HERE]" {
+			if headers == nil {
+				headers = config.SecureHeaders{}
+			}
+			headers[name] = config.NewSecureString(v)
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
 func applyConfigSecretsFromMap(cfg *config.Config, raw map[string]any) {
 	channels, hasChannels := asMapField(raw, "channels")
 	if hasChannels {
@@ -467,6 +545,15 @@ func applyConfigSecretsFromMap(cfg *config.Config, raw map[string]any) {
 		}
 	}
 
+	applyGatewaySecrets(cfg, raw)
+	applyTriggerSecrets(cfg, raw)
+
+	if swarm, hasSwarm := asMapField(raw, "swarm"); hasSwarm {
+		if secret, hasSecret := getSecretString(swarm, "secret"); hasSecret {
+			cfg.Swarm.Secret.Set(secret)
+		}
+	}
+
 	tools, hasTools := asMapField(raw, "tools")
 	if !hasTools {
 		return
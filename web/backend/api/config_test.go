@@ -379,6 +379,104 @@ func TestHandlePatchConfig_SavesDiscordTokenFromPayload(t *testing.T) {
 	}
 }
 
+func TestHandlePatchConfig_KeepsGatewayHeadersSecret(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	patch := func(body string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPatch, "/api/config", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("PATCH /api/config status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+	}
+	patch(`{"gateway": {
+		"log": {"http": {
+			"enabled": true,
+			"url": "http://loki:3100/loki/api/v1/push",
+			"headers": {"Authorization": "Bearer l0ki"}
+		}},
+		"tracing": {
+			"enabled": true,
+			"endpoint": "http://otel:4318",
+			"headers": {"X-Api-Key": "otl-k3y"}
+		}
+	}}`)
+	// Saving an unrelated change sends the placeholders back.
+	patch(`{"gateway": {
+		"log": {"http": {"headers": {"Authorization": "[NOT_HERE]"}, "batch_size": 50}},
+		"tracing": {"headers": {"X-Api-Key": "[NOT_HERE]"}, "batch_size": 64}
+	}}`)
+
+	raw, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if bytes.Contains(raw, []byte("l0ki")) || bytes.Contains(raw, []byte("otl-k3y")) {
+		t.Fatalf("config.json holds a header value:\n%s", raw)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	ship := cfg.Gateway.Log.HTTP
+	if got := ship.Headers.Values()["Authorization"]; got != "Bearer l0ki" || ship.BatchSize != 50 {
+		t.Fatalf("Authorization = %q, batch size = %d", got, ship.BatchSize)
+	}
+	tracing := cfg.Gateway.Tracing
+	if got := tracing.Headers.Values()["X-Api-Key"]; got != "otl-k3y" || tracing.BatchSize != 64 {
+		t.Fatalf("X-Api-Key = %q, batch size = %d", got, tracing.BatchSize)
+	}
+}
+
+func TestHandlePatchConfig_KeepsPollTriggerHeadersSecret(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	patch := func(headers string) {
+		t.Helper()
+		body := `{"triggers": {"rules": [{"name": "status", "enabled": true, "source": "http_poll",
+			"prompt": "{{.body}}", "http_poll": {"url": "https://example.com/status", "headers": ` + headers + `}}]}}`
+		req := httptest.NewRequest(http.MethodPatch, "/api/config", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("PATCH /api/config status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+	}
+	patch(`{"Authorization": "Bearer t0k3n", "X-Trace": "on"}`)
+	// The placeholder keeps the stored value; a header left out is dropped.
+	patch(`{"Authorization": "[NOT_HERE]"}`)
+
+	raw, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if bytes.Contains(raw, []byte("t0k3n")) {
+		t.Fatalf("config.json holds a header value:\n%s", raw)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	headers := cfg.Triggers.Rules[0].HTTPPoll.Headers.Values()
+	if len(headers) != 1 || headers["Authorization"] != "Bearer t0k3n" {
+		t.Fatalf("headers = %v", headers)
+	}
+}
+
 func TestHandlePatchConfig_AllowsInvalidDenyRegexPatternsWhenDenyPatternsDisabled(t *testing.T) {
 	configPath, cleanup := setupOAuthTestEnv(t)
 	defer cleanup()
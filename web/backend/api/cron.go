@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+// registerCronRoutes binds scheduled job endpoints to the ServeMux.
+func (h *Handler) registerCronRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/cron", h.handleListCronJobs)
+	mux.HandleFunc("GET /api/cron/{id}", h.handleGetCronJob)
+	mux.HandleFunc("GET /api/cron/{id}/history", h.handleCronJobHistory)
+}
+
+// cronService opens the gateway's cron store for inspection.
+func (h *Handler) cronService() (*cron.CronService, error) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		return nil, err
+	}
+	return cron.NewCronService(filepath.Join(cfg.WorkspacePath(), "cron", "jobs.json"), nil), nil
+}
+
+// handleListCronJobs returns every scheduled job with its state and policies.
+//
+//	GET /api/cron
+func (h *Handler) handleListCronJobs(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jobs := cs.ListJobs(true)
+	if jobs == nil {
+		jobs = []cron.CronJob{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"jobs":   jobs,
+		"status": cs.Status(),
+	})
+}
+
+// handleGetCronJob returns a single job.
+//
+//	GET /api/cron/{id}
+func (h *Handler) handleGetCronJob(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	job, ok := cs.GetJob(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleCronJobHistory returns the recorded runs of a job, newest first.
+//
+//	GET /api/cron/{id}/history?limit=N
+func (h *Handler) handleCronJobHistory(w http.ResponseWriter, r *http.Request) {
+	cs, err := h.cronService()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jobID := r.PathValue("id")
+	if _, ok := cs.GetJob(jobID); !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	runs, err := cs.History(jobID, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if runs == nil {
+		runs = []cron.CronRun{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"job_id": jobID,
+		"runs":   runs,
+	})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+func TestHandleCronJobHistory(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	workspace := filepath.Join(t.TempDir(), "workspace")
+	cfg.Agents.Defaults.Workspace = workspace
+	if err = config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	cronDir := filepath.Join(workspace, "cron")
+	cs := cron.NewCronService(filepath.Join(cronDir, "jobs.json"), nil)
+	everyMS := int64(60000)
+	job, err := cs.AddJob("ping", cron.CronSchedule{Kind: "every", EveryMS: &everyMS}, "ping", "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+
+	if err = os.MkdirAll(filepath.Join(cronDir, "runs"), 0o755); err != nil {
+		t.Fatalf("MkdirAll(runs) error = %v", err)
+	}
+	now := time.Now().UnixMilli()
+	history := `{"jobId":"` + job.ID + `","startedAtMs":` + strconv.FormatInt(now-2000, 10) +
+		`,"durationMs":5,"status":"ok"}` + "\n" +
+		`{"jobId":"` + job.ID + `","startedAtMs":` + strconv.FormatInt(now-1000, 10) +
+		`,"durationMs":7,"status":"error","error":"boom"}` + "\n"
+	if err = os.WriteFile(filepath.Join(cronDir, "runs", job.ID+".jsonl"), []byte(history), 0o644); err != nil {
+		t.Fatalf("WriteFile(history) error = %v", err)
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cron", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Jobs []cron.CronJob `json:"jobs"`
+	}
+	if err = json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal(list) error = %v", err)
+	}
+	if len(list.Jobs) != 1 || list.Jobs[0].ID != job.ID {
+		t.Fatalf("jobs = %+v, want single job %s", list.Jobs, job.ID)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cron/"+job.ID+"/history?limit=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("history status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Runs []cron.CronRun `json:"runs"`
+	}
+	if err = json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal(history) error = %v", err)
+	}
+	if len(resp.Runs) != 1 || resp.Runs[0].Status != cron.RunStatusError {
+		t.Fatalf("runs = %+v, want the latest error run", resp.Runs)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cron/missing/history", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing job status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -111,7 +111,12 @@ var gatewayHealthGet = func(url string, timeout time.Duration) (*http.Response,
 // getGatewayHealth checks the gateway health endpoint and returns the status response.
 // Returns (*health.StatusResponse, statusCode, error). If error is not nil, the other values are not valid.
 func (h *Handler) getGatewayHealth(cfg *config.Config, timeout time.Duration) (*health.StatusResponse, int, error) {
-	// Prefer port/host from pidData when available.
+	return getGatewayHealthByURL(h.gatewayBaseURL(cfg)+"/health", timeout)
+}
+
+// gatewayBaseURL returns the gateway's HTTP address, preferring the port and
+// host recorded in pidData over the configured ones.
+func (h *Handler) gatewayBaseURL(cfg *config.Config) string {
 	var port int
 	var host string
 	gateway.mu.Lock()
@@ -130,9 +135,7 @@ func (h *Handler) getGatewayHealth(cfg *config.Config, timeout time.Duration) (*
 		host = gatewayProbeHost(h.effectiveGatewayBindHost(cfg))
 	}
 
-	url := "http://" + net.JoinHostPort(host, strconv.Itoa(port)) + "/health"
-
-	return getGatewayHealthByURL(url, timeout)
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
 }
 
 func getGatewayHealthByURL(url string, timeout time.Duration) (*health.StatusResponse, int, error) {
@@ -1012,10 +1015,11 @@ func gatewayLogsData(r *http.Request) map[string]any {
 }
 
 // scanPipe reads lines from r and appends them to buf. Returns when r reaches EOF.
+// Lines of a gateway logging in the JSON format are kept as console text.
 func scanPipe(r io.Reader, buf *LogBuffer) {
 	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 	for scanner.Scan() {
-		buf.Append(scanner.Text())
+		buf.Append(logger.ConsoleLine(scanner.Text()))
 	}
 }
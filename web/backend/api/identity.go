@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+)
+
+// registerIdentityRoutes binds identity link endpoints to the ServeMux.
+func (h *Handler) registerIdentityRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/identity/links", h.handleListIdentityLinks)
+	mux.HandleFunc("DELETE /api/identity/links/{id}", h.handleUnlinkIdentity)
+}
+
+type identityLinkGroup struct {
+	Name       string   `json:"name"`
+	Identities []string `json:"identities"`
+}
+
+// identityLinks opens the store of identities users linked with /link.
+func (h *Handler) identityLinks() (*identity.LinkStore, *config.Config, error) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identity.NewLinkStore(identity.LinkStorePath(cfg.WorkspacePath())), cfg, nil
+}
+
+func sortedLinkGroups(groups map[string][]string) []identityLinkGroup {
+	out := make([]identityLinkGroup, 0, len(groups))
+	for name, ids := range groups {
+		out = append(out, identityLinkGroup{Name: name, Identities: ids})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// handleListIdentityLinks returns identities linked with /link and those
+// linked in session.identity_links.
+//
+//	GET /api/identity/links
+func (h *Handler) handleListIdentityLinks(w http.ResponseWriter, r *http.Request) {
+	links, cfg, err := h.identityLinks()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"links":      sortedLinkGroups(links.Groups()),
+		"configured": sortedLinkGroups(cfg.Session.IdentityLinks),
+	})
+}
+
+// handleUnlinkIdentity removes an identity from its /link group. The
+// gateway picks the change up on the next message.
+//
+//	DELETE /api/identity/links/{id}
+func (h *Handler) handleUnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	links, _, err := h.identityLinks()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	removed, err := links.Unlink(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to unlink: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "identity not linked", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
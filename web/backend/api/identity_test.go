@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/identity"
+)
+
+func TestHandleIdentityLinks(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	workspace := filepath.Join(t.TempDir(), "workspace")
+	cfg.Agents.Defaults.Workspace = workspace
+	if err = config.SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	links := identity.NewLinkStore(identity.LinkStorePath(workspace))
+	for _, id := range []string{"discord:456", "slack:U1"} {
+		code, issueErr := links.IssueCode("telegram:123")
+		if issueErr != nil {
+			t.Fatalf("IssueCode() error = %v", issueErr)
+		}
+		if _, err = links.Redeem(code, id); err != nil {
+			t.Fatalf("Redeem() error = %v", err)
+		}
+		if _, err = links.Confirm("telegram:123", id); err != nil {
+			t.Fatalf("Confirm() error = %v", err)
+		}
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/identity/links/slack:U1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unlink status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/identity/links", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Links []identityLinkGroup `json:"links"`
+	}
+	if err = json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(list.Links) != 1 || list.Links[0].Name != "telegram:123" || len(list.Links[0].Identities) != 2 {
+		t.Fatalf("links = %+v", list.Links)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/identity/links/slack:U1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("second unlink status = %d, want 404", rec.Code)
+	}
+}
@@ -75,6 +75,18 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	h.registerSkillRoutes(mux)
 	h.registerToolRoutes(mux)
 
+	// Scheduled jobs and their run history
+	h.registerCronRoutes(mux)
+
+	// Event triggers
+	h.registerTriggerRoutes(mux)
+
+	// Identities linked with /link
+	h.registerIdentityRoutes(mux)
+
+	// Background subagent tasks (proxied to the running gateway)
+	h.registerTaskRoutes(mux)
+
 	// OS startup / launch-at-login
 	h.registerStartupRoutes(mux)
 
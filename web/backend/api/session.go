@@ -2,8 +2,11 @@ package api
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -12,8 +15,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/memory"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
 )
 
 // registerSessionRoutes binds session list and detail endpoints to the ServeMux.
@@ -21,6 +28,10 @@ func (h *Handler) registerSessionRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/sessions", h.handleListSessions)
 	mux.HandleFunc("GET /api/sessions/{id}", h.handleGetSession)
 	mux.HandleFunc("DELETE /api/sessions/{id}", h.handleDeleteSession)
+	mux.HandleFunc("POST /api/sessions/{id}/rewind", h.handleRewindSession)
+	mux.HandleFunc("POST /api/sessions/{id}/fork", h.handleForkSession)
+	mux.HandleFunc("GET /api/sessions/{id}/export", h.handleExportSession)
+	mux.HandleFunc("POST /api/sessions/import", h.handleImportSession)
 }
 
 // sessionFile mirrors the on-disk session JSON structure from pkg/session.
@@ -400,6 +411,37 @@ func (h *Handler) handleListSessions(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(items)
 }
 
+// readSession reads a Pico session from JSONL storage, falling back to the
+// legacy JSON file. Empty sessions are reported as os.ErrNotExist.
+func (h *Handler) readSession(dir, sessionID string) (sessionFile, error) {
+	sess, err := h.readJSONLSession(dir, sessionID)
+	if err == nil && isEmptySession(sess) {
+		err = os.ErrNotExist
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		sess, err = h.readLegacySession(dir, sessionID)
+		if err == nil && isEmptySession(sess) {
+			err = os.ErrNotExist
+		}
+	}
+	return sess, err
+}
+
+// loadSession reads a session for a handler and writes the error response
+// when it cannot.
+func (h *Handler) loadSession(w http.ResponseWriter, dir, sessionID string) (sessionFile, bool) {
+	sess, err := h.readSession(dir, sessionID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "session not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "failed to parse session", http.StatusInternalServerError)
+		}
+		return sessionFile{}, false
+	}
+	return sess, true
+}
+
 // handleGetSession returns the full message history for a specific session.
 //
 //	GET /api/sessions/{id}
@@ -416,25 +458,9 @@ func (h *Handler) handleGetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sess, err := h.readJSONLSession(dir, sessionID)
-	if err == nil && isEmptySession(sess) {
-		err = os.ErrNotExist
-	}
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			sess, err = h.readLegacySession(dir, sessionID)
-			if err == nil && isEmptySession(sess) {
-				err = os.ErrNotExist
-			}
-		}
-		if err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				http.Error(w, "session not found", http.StatusNotFound)
-			} else {
-				http.Error(w, "failed to parse session", http.StatusInternalServerError)
-			}
-			return
-		}
+	sess, ok := h.loadSession(w, dir, sessionID)
+	if !ok {
+		return
 	}
 
 	// Convert to a simpler format for the frontend
@@ -504,3 +530,204 @@ func (h *Handler) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// maxSessionImportSize bounds the transcript accepted by POST
+// /api/sessions/import.
+const maxSessionImportSize = 32 << 20
+
+// writeSession replaces the history and summary of a Pico session.
+func writeSession(dir, sessionID, summary string, messages []providers.Message) error {
+	store, err := memory.NewJSONLStore(dir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	key := picoSessionPrefix + sessionID
+	if err := store.SetHistory(context.Background(), key, messages); err != nil {
+		return err
+	}
+	if err := store.SetSummary(context.Background(), key, summary); err != nil {
+		return err
+	}
+	// The JSONL files now hold the session; drop a legacy copy so it cannot
+	// resurface when the session is empty.
+	legacyPath := filepath.Join(dir, sanitizeSessionKey(key)+".json")
+	if err := os.Remove(legacyPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// rewindTurns drops the last n turns of messages, cutting only where a user
+// message starts a turn like the /rewind command does.
+func rewindTurns(messages []providers.Message, n int) ([]providers.Message, int) {
+	var starts []int
+	for i, msg := range messages {
+		if msg.Role == "user" {
+			starts = append(starts, i)
+		}
+	}
+	n = min(n, len(starts))
+	if n <= 0 {
+		return messages, 0
+	}
+	return messages[:starts[len(starts)-n]], n
+}
+
+// handleRewindSession drops the last turns of a session. The body is
+// optional and defaults to one turn.
+//
+//	POST /api/sessions/{id}/rewind  {"turns": 1}
+func (h *Handler) handleRewindSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	var req struct {
+		Turns int `json:"turns"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Turns == 0 {
+		req.Turns = 1
+	}
+	if req.Turns < 0 {
+		http.Error(w, "turns must be positive", http.StatusBadRequest)
+		return
+	}
+
+	dir, err := h.sessionsDir()
+	if err != nil {
+		http.Error(w, "failed to resolve sessions directory", http.StatusInternalServerError)
+		return
+	}
+	sess, ok := h.loadSession(w, dir, sessionID)
+	if !ok {
+		return
+	}
+
+	messages, removed := rewindTurns(sess.Messages, req.Turns)
+	if removed > 0 {
+		if err := writeSession(dir, sessionID, sess.Summary, messages); err != nil {
+			http.Error(w, "failed to save session", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":            sessionID,
+		"removed_turns": removed,
+	})
+}
+
+// handleForkSession copies a session into a new one and returns its id.
+//
+//	POST /api/sessions/{id}/fork
+func (h *Handler) handleForkSession(w http.ResponseWriter, r *http.Request) {
+	dir, err := h.sessionsDir()
+	if err != nil {
+		http.Error(w, "failed to resolve sessions directory", http.StatusInternalServerError)
+		return
+	}
+	sess, ok := h.loadSession(w, dir, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	forkID := uuid.New().String()
+	if err := writeSession(dir, forkID, sess.Summary, sess.Messages); err != nil {
+		http.Error(w, "failed to save session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": forkID})
+}
+
+// handleExportSession downloads a session as a JSON transcript, which
+// POST /api/sessions/import accepts, or as Markdown.
+//
+//	GET /api/sessions/{id}/export?format=json|md
+func (h *Handler) handleExportSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "md" {
+		http.Error(w, "format must be json or md", http.StatusBadRequest)
+		return
+	}
+
+	dir, err := h.sessionsDir()
+	if err != nil {
+		http.Error(w, "failed to resolve sessions directory", http.StatusInternalServerError)
+		return
+	}
+	sess, ok := h.loadSession(w, dir, sessionID)
+	if !ok {
+		return
+	}
+
+	transcript := session.NewTranscript(sess.Key, sess.Summary, sess.Messages)
+	var data []byte
+	contentType := "text/markdown; charset=utf-8"
+	if format == "json" {
+		if data, err = transcript.JSON(); err != nil {
+			http.Error(w, "failed to encode session", http.StatusInternalServerError)
+			return
+		}
+		contentType = "application/json"
+	} else {
+		data = []byte(transcript.Markdown())
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", "conversation-"+sessionID+"."+format))
+	w.Write(data)
+}
+
+// handleImportSession restores a JSON transcript as a new session and
+// returns its id.
+//
+//	POST /api/sessions/import
+func (h *Handler) handleImportSession(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSessionImportSize))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	transcript, err := session.ParseTranscript(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dir, err := h.sessionsDir()
+	if err != nil {
+		http.Error(w, "failed to resolve sessions directory", http.StatusInternalServerError)
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		http.Error(w, "failed to create sessions directory", http.StatusInternalServerError)
+		return
+	}
+
+	sessionID := uuid.New().String()
+	if err := writeSession(dir, sessionID, transcript.Summary, transcript.Messages); err != nil {
+		http.Error(w, "failed to save session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":            sessionID,
+		"message_count": len(transcript.Messages),
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -320,3 +321,76 @@ func TestHandleSessions_FiltersEmptyJSONLFiles(t *testing.T) {
 		t.Fatalf("detail status = %d, want %d, body=%s", detailRec.Code, http.StatusNotFound, detailRec.Body.String())
 	}
 }
+
+func TestHandleSessions_RewindForkExportImport(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	dir := sessionsTestDir(t, configPath)
+	store, err := memory.NewJSONLStore(dir)
+	if err != nil {
+		t.Fatalf("NewJSONLStore() error = %v", err)
+	}
+	sessionKey := picoSessionPrefix + "ops"
+	for _, msg := range []providers.Message{
+		{Role: "user", Content: "one"},
+		{Role: "assistant", Content: "first answer"},
+		{Role: "user", Content: "two"},
+		{Role: "assistant", Content: "second answer"},
+	} {
+		if err := store.AddFullMessage(nil, sessionKey, msg); err != nil {
+			t.Fatalf("AddFullMessage() error = %v", err)
+		}
+	}
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/sessions/ops/fork", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("fork status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var fork struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &fork); err != nil || fork.ID == "" {
+		t.Fatalf("fork response = %s", rec.Body.String())
+	}
+
+	rec = do(http.MethodPost, "/api/sessions/ops/rewind", `{"turns":1}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"removed_turns":1`) {
+		t.Fatalf("rewind status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if msgs, _ := store.GetHistory(nil, sessionKey); len(msgs) != 2 {
+		t.Fatalf("after rewind history has %d messages, want 2", len(msgs))
+	}
+	if msgs, _ := store.GetHistory(nil, picoSessionPrefix+fork.ID); len(msgs) != 4 {
+		t.Fatalf("fork has %d messages, want 4", len(msgs))
+	}
+
+	rec = do(http.MethodGet, "/api/sessions/"+fork.ID+"/export?format=md", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "second answer") {
+		t.Fatalf("markdown export status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/api/sessions/"+fork.ID+"/export", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("json export status = %d, headers=%v", rec.Code, rec.Header())
+	}
+
+	rec = do(http.MethodPost, "/api/sessions/import", rec.Body.String())
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"message_count":4`) {
+		t.Fatalf("import status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPost, "/api/sessions/import", `{"version":1}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty import status = %d, want 400", rec.Code)
+	}
+	if rec = do(http.MethodPost, "/api/sessions/missing/rewind", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("rewind of missing session status = %d, want 404", rec.Code)
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
@@ -69,16 +70,20 @@ type installSkillRequest struct {
 	Registry string `json:"registry"`
 	Version  string `json:"version,omitempty"`
 	Force    bool   `json:"force,omitempty"`
+	// Confirm installs a skill the security scan flagged, after the user
+	// has seen the report.
+	Confirm bool `json:"confirm,omitempty"`
 }
 
 type installSkillResponse struct {
-	Status         string            `json:"status"`
-	Slug           string            `json:"slug"`
-	Registry       string            `json:"registry"`
-	Version        string            `json:"version"`
-	Summary        string            `json:"summary,omitempty"`
-	IsSuspicious   bool              `json:"is_suspicious,omitempty"`
-	InstalledSkill *skillSupportItem `json:"skill,omitempty"`
+	Status         string                 `json:"status"`
+	Slug           string                 `json:"slug"`
+	Registry       string                 `json:"registry"`
+	Version        string                 `json:"version"`
+	Summary        string                 `json:"summary,omitempty"`
+	IsSuspicious   bool                   `json:"is_suspicious,omitempty"`
+	SecurityReport *skills.SecurityReport `json:"security_report,omitempty"`
+	InstalledSkill *skillSupportItem      `json:"skill,omitempty"`
 }
 
 type installedSkillOriginMeta struct {
@@ -370,6 +375,12 @@ func (h *Handler) handleInstallSkill(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	report, statusCode, err := vetSkill(cfg, stagedTargetDir, req.Slug, req.Confirm)
+	if err != nil {
+		writeSkillError(w, err, statusCode)
+		return
+	}
+
 	installedAt := time.Now().UnixMilli()
 	if err := persistSkillOriginMeta(stagedTargetDir, installedSkillOriginMeta{
 		Version:          1,
@@ -393,6 +404,14 @@ func (h *Handler) handleInstallSkill(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Failed to activate installed skill: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if err := skills.RecordInstall(workspace, req.Slug, skills.LockEntry{
+		Source:      registry.Name(),
+		Slug:        req.Slug,
+		Version:     result.Version,
+		InstalledAt: time.UnixMilli(installedAt).UTC(),
+	}); err != nil {
+		logger.Warnf("Failed to record skill %q in %s: %v", req.Slug, skills.LockFileName, err)
+	}
 
 	validatedSkill := findWorkspaceSkillByDirectory(cfg, req.Slug)
 	if validatedSkill == nil {
@@ -424,6 +443,7 @@ func (h *Handler) handleInstallSkill(w http.ResponseWriter, r *http.Request) {
 		Version:        result.Version,
 		Summary:        result.Summary,
 		IsSuspicious:   result.IsSuspicious,
+		SecurityReport: report,
 		InstalledSkill: installedSkill,
 	})
 }
@@ -460,9 +480,10 @@ func (h *Handler) handleImportSkill(w http.ResponseWriter, r *http.Request) {
 	workspaceSkillWriteMu.Lock()
 	defer workspaceSkillWriteMu.Unlock()
 
-	importedSkill, statusCode, err := importUploadedSkill(cfg, fileHeader.Filename, content)
+	confirmed := r.FormValue("confirm") == "true"
+	importedSkill, statusCode, err := importUploadedSkill(cfg, fileHeader.Filename, content, confirmed)
 	if err != nil {
-		http.Error(w, err.Error(), statusCode)
+		writeSkillError(w, err, statusCode)
 		return
 	}
 
@@ -494,6 +515,9 @@ func (h *Handler) handleDeleteSkill(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, fmt.Sprintf("Failed to delete skill: %v", err), http.StatusInternalServerError)
 			return
 		}
+		if err := skills.ForgetInstall(cfg.WorkspacePath(), filepath.Base(filepath.Dir(skill.Path))); err != nil {
+			logger.Warnf("Failed to remove skill %q from %s: %v", name, skills.LockFileName, err)
+		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 		return
@@ -834,19 +858,41 @@ func normalizeImportedSkillContent(content []byte, skillName string) []byte {
 	return []byte(builder.String())
 }
 
-func importUploadedSkill(cfg *config.Config, filename string, content []byte) (*skillSupportItem, int, error) {
+func importUploadedSkill(
+	cfg *config.Config,
+	filename string,
+	content []byte,
+	confirmed bool,
+) (*skillSupportItem, int, error) {
 	if isImportedSkillArchive(filename, content) {
-		return importUploadedSkillArchive(cfg, filename, content)
+		return importUploadedSkillArchive(cfg, filename, content, confirmed)
 	}
-	return importUploadedMarkdownSkill(cfg, filename, content)
+	return importUploadedMarkdownSkill(cfg, filename, content, confirmed)
 }
 
-func importUploadedMarkdownSkill(cfg *config.Config, filename string, content []byte) (*skillSupportItem, int, error) {
+func importUploadedMarkdownSkill(
+	cfg *config.Config,
+	filename string,
+	content []byte,
+	confirmed bool,
+) (*skillSupportItem, int, error) {
 	skillName, err := normalizeImportedSkillName(filename, content)
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
 
+	vetDir, err := os.MkdirTemp("", "picoclaw-skill-vet-*")
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(vetDir)
+	if err := fileutil.WriteFileAtomic(filepath.Join(vetDir, "SKILL.md"), content, 0o600); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to stage skill: %v", err)
+	}
+	if _, statusCode, err := vetSkill(cfg, vetDir, skillName, confirmed); err != nil {
+		return nil, statusCode, err
+	}
+
 	normalizedContent := normalizeImportedSkillContent(content, skillName)
 	workspace := cfg.WorkspacePath()
 	skillDir := filepath.Join(workspace, "skills", skillName)
@@ -866,7 +912,12 @@ func importUploadedMarkdownSkill(cfg *config.Config, filename string, content []
 	return finalizeImportedSkill(cfg, skillDir, skillName, false)
 }
 
-func importUploadedSkillArchive(cfg *config.Config, filename string, content []byte) (*skillSupportItem, int, error) {
+func importUploadedSkillArchive(
+	cfg *config.Config,
+	filename string,
+	content []byte,
+	confirmed bool,
+) (*skillSupportItem, int, error) {
 	tmpDir, tempDirErr := os.MkdirTemp("", "picoclaw-skill-import-*")
 	if tempDirErr != nil {
 		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to create temp directory: %v", tempDirErr)
@@ -903,6 +954,11 @@ func importUploadedSkillArchive(cfg *config.Config, filename string, content []b
 		return nil, http.StatusBadRequest, err
 	}
 
+	// Vet the archive as uploaded: normalizing SKILL.md would break its signature.
+	if _, statusCode, err := vetSkill(cfg, skillRoot, skillName, confirmed); err != nil {
+		return nil, statusCode, err
+	}
+
 	workspace := cfg.WorkspacePath()
 	skillDir := filepath.Join(workspace, "skills", skillName)
 	if err := ensureWorkspaceSkillDoesNotExist(skillDir); err != nil {
@@ -922,6 +978,50 @@ func importUploadedSkillArchive(cfg *config.Config, filename string, content []b
 	return finalizeImportedSkill(cfg, skillDir, skillName, true)
 }
 
+func newSkillsSecurityPolicy(cfg *config.Config) skills.SecurityPolicy {
+	security := cfg.Tools.Skills.Security
+	return skills.SecurityPolicy{
+		TrustedKeys:      security.TrustedKeys,
+		RequireSignature: security.RequireSignature,
+		BlockOn:          security.BlockOn,
+		MaxBinarySize:    int64(security.MaxBinarySizeKB) * 1024,
+		AllowedHosts:     security.AllowedHosts,
+	}
+}
+
+// vetSkill vets a skill before it is installed. Skills the policy blocks,
+// and flagged skills the user has not confirmed, return a
+// *skills.SecurityError with the status to answer with.
+func vetSkill(cfg *config.Config, dir, name string, confirmed bool) (*skills.SecurityReport, int, error) {
+	report, err := skills.VetSkill(dir, name, newSkillsSecurityPolicy(cfg))
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to vet skill: %v", err)
+	}
+	switch {
+	case report.Verdict == skills.VerdictBlock:
+		return report, http.StatusForbidden, &skills.SecurityError{Report: report}
+	case report.Verdict == skills.VerdictFlag && !confirmed:
+		return report, http.StatusPreconditionRequired, &skills.SecurityError{Report: report}
+	}
+	return report, http.StatusOK, nil
+}
+
+// writeSkillError answers with err. Security errors carry their report so
+// the UI can show it and ask for confirmation.
+func writeSkillError(w http.ResponseWriter, err error, statusCode int) {
+	var securityErr *skills.SecurityError
+	if !errors.As(err, &securityErr) {
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":           err.Error(),
+		"security_report": securityErr.Report,
+	})
+}
+
 func isImportedSkillArchive(filename string, content []byte) bool {
 	if strings.EqualFold(filepath.Ext(filename), ".zip") {
 		return true
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	ppid "github.com/sipeed/picoclaw/pkg/pid"
+)
+
+const gatewayTasksTimeout = 10 * time.Second
+
+// registerTaskRoutes binds the subagent task queue endpoints to the ServeMux.
+// The queue lives in the gateway process, so requests are forwarded there.
+func (h *Handler) registerTaskRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/tasks", h.handleListTasks)
+	mux.HandleFunc("POST /api/tasks/{owner}/{id}/cancel", h.handleTaskAction("cancel"))
+	mux.HandleFunc("POST /api/tasks/{owner}/{id}/rerun", h.handleTaskAction("rerun"))
+}
+
+// handleListTasks returns the tasks of every agent's queue.
+//
+//	GET /api/tasks
+func (h *Handler) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	h.proxyGatewayTasks(w, r, http.MethodGet, "/tasks")
+}
+
+// handleTaskAction cancels or reruns one task.
+//
+//	POST /api/tasks/{owner}/{id}/cancel
+//	POST /api/tasks/{owner}/{id}/rerun
+func (h *Handler) handleTaskAction(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := "/tasks/" + url.PathEscape(r.PathValue("owner")) + "/" + url.PathEscape(r.PathValue("id")) + "/" + action
+		h.proxyGatewayTasks(w, r, http.MethodPost, path)
+	}
+}
+
+func (h *Handler) proxyGatewayTasks(w http.ResponseWriter, r *http.Request, method, path string) {
+	pidData := ppid.ReadPidFileWithCheck(globalConfigDir())
+	if pidData == nil {
+		http.Error(w, "Gateway is not running", http.StatusServiceUnavailable)
+		return
+	}
+	gateway.mu.Lock()
+	gateway.pidData = pidData
+	gateway.mu.Unlock()
+
+	cfg, _ := config.LoadConfig(h.configPath)
+	req, err := http.NewRequestWithContext(r.Context(), method, h.gatewayBaseURL(cfg)+path, nil)
+	if err != nil {
+		http.Error(w, "Failed to build gateway request", http.StatusInternalServerError)
+		return
+	}
+	if pidData.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+pidData.Token)
+	}
+
+	client := http.Client{Timeout: gatewayTasksTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		http.Error(w, "Gateway unreachable: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	ppid "github.com/sipeed/picoclaw/pkg/pid"
+)
+
+func TestHandleTasksProxiesToGateway(t *testing.T) {
+	resetGatewayTestState(t)
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	var gotAuth, gotPath, gotMethod string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		gotMethod = r.Method
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/tasks" {
+			w.Write([]byte(`{"tasks":[{"owner":"main","id":"subagent-1","status":"running"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":"task subagent-1 is completed"}`))
+	}))
+	defer upstream.Close()
+
+	host, portStr, _ := net.SplitHostPort(upstream.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status without gateway = %d, want 503", rec.Code)
+	}
+
+	pidData, err := ppid.WritePidFile(globalConfigDir(), host, port)
+	if err != nil {
+		t.Fatalf("WritePidFile: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if gotAuth != "Bearer "+pidData.Token || gotPath != "/tasks" || gotMethod != http.MethodGet {
+		t.Fatalf("upstream got %s %s auth=%q", gotMethod, gotPath, gotAuth)
+	}
+	if want := `{"tasks":[{"owner":"main","id":"subagent-1","status":"running"}]}`; rec.Body.String() != want {
+		t.Fatalf("body = %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/tasks/main/subagent-1/rerun", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("rerun status = %d, want upstream 409", rec.Code)
+	}
+	if gotPath != "/tasks/main/subagent-1/rerun" || gotMethod != http.MethodPost {
+		t.Fatalf("upstream got %s %s", gotMethod, gotPath)
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/triggers"
+)
+
+// registerTriggerRoutes binds event trigger endpoints to the ServeMux.
+func (h *Handler) registerTriggerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/triggers", h.handleListTriggers)
+	mux.HandleFunc("POST /api/triggers", h.handleCreateTrigger)
+	mux.HandleFunc("PUT /api/triggers/{name}", h.handleUpdateTrigger)
+	mux.HandleFunc("DELETE /api/triggers/{name}", h.handleDeleteTrigger)
+}
+
+// keepTriggerSecrets keeps the stored credentials of an updated trigger
+// when the update leaves them empty. Credentials are never sent to the
+// client, so a client editing a trigger does not have them.
+func keepTriggerSecrets(rule *config.TriggerConfig, old config.TriggerConfig) {
+	if rule.Webhook != nil && rule.Webhook.Secret.String() == "" && old.Webhook != nil {
+		rule.Webhook.Secret = old.Webhook.Secret
+	}
+	if rule.MQTT != nil && rule.MQTT.Password.String() == "" && old.MQTT != nil {
+		rule.MQTT.Password = old.MQTT.Password
+	}
+	if rule.HTTPPoll != nil && old.HTTPPoll != nil {
+		for name, value := range rule.HTTPPoll.Headers {
+			if (value == nil || value.String() == "") && old.HTTPPoll.Headers[name] != nil {
+				rule.HTTPPoll.Headers[name] = old.HTTPPoll.Headers[name]
+			}
+		}
+	}
+}
+
+func findTriggerRule(cfg *config.Config, name string) int {
+	return slices.IndexFunc(cfg.Triggers.Rules, func(r config.TriggerConfig) bool { return r.Name == name })
+}
+
+func decodeTrigger(r *http.Request) (config.TriggerConfig, error) {
+	var rule config.TriggerConfig
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return rule, fmt.Errorf("failed to read request body")
+	}
+	if err := json.Unmarshal(body, &rule); err != nil {
+		return rule, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return rule, nil
+}
+
+// handleListTriggers returns the configured triggers without credentials.
+//
+//	GET /api/triggers
+func (h *Handler) handleListTriggers(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Credentials are SecureStrings, which are never encoded to JSON.
+	rules := append(make([]config.TriggerConfig, 0, len(cfg.Triggers.Rules)), cfg.Triggers.Rules...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"enabled":      cfg.Triggers.Enabled,
+		"triggers":     rules,
+		"webhook_path": triggers.WebhookPathPrefix,
+	})
+}
+
+// handleCreateTrigger adds a trigger.
+//
+//	POST /api/triggers
+func (h *Handler) handleCreateTrigger(w http.ResponseWriter, r *http.Request) {
+	rule, err := decodeTrigger(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = triggers.Validate(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if findTriggerRule(cfg, rule.Name) >= 0 {
+		http.Error(w, fmt.Sprintf("trigger %s already exists", rule.Name), http.StatusConflict)
+		return
+	}
+
+	cfg.Triggers.Rules = append(cfg.Triggers.Rules, rule)
+	if err = config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// handleUpdateTrigger replaces a trigger. Empty credentials keep the stored
+// values.
+//
+//	PUT /api/triggers/{name}
+func (h *Handler) handleUpdateTrigger(w http.ResponseWriter, r *http.Request) {
+	rule, err := decodeTrigger(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	name := r.PathValue("name")
+	idx := findTriggerRule(cfg, name)
+	if idx < 0 {
+		http.Error(w, "trigger not found", http.StatusNotFound)
+		return
+	}
+	if rule.Name == "" {
+		rule.Name = name
+	}
+	if rule.Name != name && findTriggerRule(cfg, rule.Name) >= 0 {
+		http.Error(w, fmt.Sprintf("trigger %s already exists", rule.Name), http.StatusConflict)
+		return
+	}
+
+	keepTriggerSecrets(&rule, cfg.Triggers.Rules[idx])
+	if err = triggers.Validate(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cfg.Triggers.Rules[idx] = rule
+	if err = config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// handleDeleteTrigger removes a trigger.
+//
+//	DELETE /api/triggers/{name}
+func (h *Handler) handleDeleteTrigger(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig(h.configPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	idx := findTriggerRule(cfg, r.PathValue("name"))
+	if idx < 0 {
+		http.Error(w, "trigger not found", http.StatusNotFound)
+		return
+	}
+
+	cfg.Triggers.Rules = slices.Delete(cfg.Triggers.Rules, idx, idx+1)
+	if err = config.SaveConfig(h.configPath, cfg); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
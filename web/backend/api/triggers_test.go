@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestHandleTriggersCRUD(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/triggers",
+		`{"name":"deploy","enabled":true,"source":"webhook","prompt":"{{.body}}","webhook":{"secret":"s3cret"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "s3cret") {
+		t.Fatalf("create response leaks the secret: %s", rec.Body.String())
+	}
+
+	if rec = do(http.MethodPost, "/api/triggers",
+		`{"name":"deploy","source":"webhook","prompt":"x","webhook":{"secret":"y"}}`); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate status = %d", rec.Code)
+	}
+	if rec = do(http.MethodPost, "/api/triggers", `{"name":"bad","source":"mqtt","prompt":"x"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid status = %d", rec.Code)
+	}
+
+	// Updating without a secret keeps the stored one.
+	rec = do(http.MethodPut, "/api/triggers/deploy",
+		`{"enabled":false,"source":"webhook","prompt":"Deployed: {{.body}}","webhook":{}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Triggers.Rules) != 1 {
+		t.Fatalf("rules = %+v", cfg.Triggers.Rules)
+	}
+	got := cfg.Triggers.Rules[0]
+	if got.Enabled || got.Prompt != "Deployed: {{.body}}" || got.Webhook.Secret.String() != "s3cret" {
+		t.Fatalf("updated rule = %+v", got)
+	}
+
+	if data, _ := os.ReadFile(configPath); strings.Contains(string(data), "s3cret") {
+		t.Fatal("config.json stores the webhook secret in plain text")
+	}
+
+	rec = do(http.MethodGet, "/api/triggers", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d", rec.Code)
+	}
+	var list struct {
+		Triggers []config.TriggerConfig `json:"triggers"`
+	}
+	if err = json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Unmarshal(list) error = %v", err)
+	}
+	if len(list.Triggers) != 1 || list.Triggers[0].Webhook.Secret.String() != "" {
+		t.Fatalf("list = %+v", list.Triggers)
+	}
+
+	if rec = do(http.MethodDelete, "/api/triggers/deploy", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d", rec.Code)
+	}
+	if rec = do(http.MethodDelete, "/api/triggers/deploy", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d", rec.Code)
+	}
+}
+
+func TestHandleTriggers_KeepsPollHeadersSecret(t *testing.T) {
+	configPath, cleanup := setupOAuthTestEnv(t)
+	defer cleanup()
+
+	h := NewHandler(configPath)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/triggers", `{"name":"status","enabled":true,"source":"http_poll",`+
+		`"prompt":"{{.body}}","http_poll":{"url":"https://example.com/status",`+
+		`"headers":{"Authorization":"Bearer t0k3n","X-Trace":"on"}}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "t0k3n") {
+		t.Fatalf("create response leaks the header: %s", rec.Body.String())
+	}
+	if data, _ := os.ReadFile(configPath); strings.Contains(string(data), "t0k3n") {
+		t.Fatal("config.json stores the poll header in plain text")
+	}
+
+	// Headers sent back masked keep their value; headers left out are dropped.
+	rec = do(http.MethodPut, "/api/triggers/status", `{"enabled":true,"source":"http_poll","prompt":"{{.body}}",`+
+		`"http_poll":{"url":"https://example.com/status","headers":{"Authorization":"[NOT_HERE]"}}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	headers := cfg.Triggers.Rules[0].HTTPPoll.Headers.Values()
+	if len(headers) != 1 || headers["Authorization"] != "Bearer t0k3n" {
+		t.Fatalf("headers after update = %v", headers)
+	}
+}
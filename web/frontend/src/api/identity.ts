@@ -0,0 +1,31 @@
+import { launcherFetch } from "@/api/http"
+
+export interface IdentityLinkGroup {
+  name: string
+  identities: string[]
+}
+
+interface IdentityLinksResponse {
+  links: IdentityLinkGroup[]
+  configured: IdentityLinkGroup[]
+}
+
+async function request<T>(path: string, options?: RequestInit): Promise<T> {
+  const res = await launcherFetch(path, options)
+  if (!res.ok) {
+    const message = (await res.text()).trim()
+    throw new Error(message || `API error: ${res.status} ${res.statusText}`)
+  }
+  return res.json() as Promise<T>
+}
+
+export async function getIdentityLinks(): Promise<IdentityLinksResponse> {
+  return request<IdentityLinksResponse>("/api/identity/links")
+}
+
+export async function unlinkIdentity(id: string): Promise<void> {
+  await request<{ status: string }>(
+    `/api/identity/links/${encodeURIComponent(id)}`,
+    { method: "DELETE" },
+  )
+}
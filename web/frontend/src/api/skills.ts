@@ -49,6 +49,7 @@ export interface InstallSkillRequest {
   registry: string
   version?: string
   force?: boolean
+  confirm?: boolean
 }
 
 export interface InstallSkillResponse {
@@ -61,10 +62,40 @@ export interface InstallSkillResponse {
   skill?: SkillSupportItem
 }
 
+export interface SkillSecurityFinding {
+  severity: "warning" | "critical" | string
+  rule: string
+  file: string
+  line?: number
+  detail: string
+}
+
+export interface SkillSecurityReport {
+  skill: string
+  hash: string
+  signature: "unsigned" | "trusted" | "untrusted" | "invalid" | string
+  signed_by?: string
+  findings: SkillSecurityFinding[]
+  verdict: "pass" | "flag" | "block" | string
+  reason?: string
+}
+
+// SkillSecurityError is thrown when the security scan blocks a skill or
+// needs the user to confirm it before it is installed.
+export class SkillSecurityError extends Error {
+  readonly report: SkillSecurityReport
+
+  constructor(message: string, report: SkillSecurityReport) {
+    super(message)
+    this.name = "SkillSecurityError"
+    this.report = report
+  }
+}
+
 async function request<T>(path: string, options?: RequestInit): Promise<T> {
   const res = await launcherFetch(path, options)
   if (!res.ok) {
-    throw new Error(await extractErrorMessage(res))
+    throw await responseError(res)
   }
   return res.json() as Promise<T>
 }
@@ -100,16 +131,22 @@ export async function installSkill(
   })
 }
 
-export async function importSkill(file: File): Promise<SkillActionResponse> {
+export async function importSkill(
+  file: File,
+  confirm = false,
+): Promise<SkillActionResponse> {
   const formData = new FormData()
   formData.set("file", file)
+  if (confirm) {
+    formData.set("confirm", "true")
+  }
 
   const res = await launcherFetch("/api/skills/import", {
     method: "POST",
     body: formData,
   })
   if (!res.ok) {
-    throw new Error(await extractErrorMessage(res))
+    throw await responseError(res)
   }
   return res.json() as Promise<SkillActionResponse>
 }
@@ -123,6 +160,21 @@ export async function deleteSkill(name: string): Promise<SkillActionResponse> {
   )
 }
 
+async function responseError(res: Response): Promise<Error> {
+  const message = await extractErrorMessage(res.clone())
+  try {
+    const body = (await res.json()) as {
+      security_report?: SkillSecurityReport
+    }
+    if (body.security_report) {
+      return new SkillSecurityError(message, body.security_report)
+    }
+  } catch {
+    // not a JSON error body
+  }
+  return new Error(message)
+}
+
 async function extractErrorMessage(res: Response): Promise<string> {
   try {
     const raw = await res.text()
@@ -1,5 +1,6 @@
 import { useTranslation } from "react-i18next"
 
+import { SecurityReportDialog } from "@/components/agent/skills/security-report-dialog"
 import { PageHeader } from "@/components/page-header"
 
 import { ResultsPanel } from "./results-panel"
@@ -46,6 +47,13 @@ export function HubPage() {
           </section>
         </div>
       </div>
+
+      <SecurityReportDialog
+        report={hub.securityReport}
+        isPending={hub.isInstallConfirmPending}
+        onOpenChange={hub.handleSecurityDialogOpenChange}
+        onConfirm={hub.handleConfirmInstall}
+      />
     </div>
   )
 }
@@ -13,7 +13,10 @@ import {
   getSkills,
   installSkill,
   searchSkills,
+  SkillSecurityError,
+  type InstallSkillRequest,
   type SkillSearchResponse,
+  type SkillSecurityReport,
   type SkillRegistrySearchResult,
   type SkillSupportItem,
 } from "@/api/skills"
@@ -31,6 +34,10 @@ export function useHubMarketplace() {
 
   const [marketQuery, setMarketQuery] = useState("")
   const [submittedMarketQuery, setSubmittedMarketQuery] = useState("")
+  const [pendingReview, setPendingReview] = useState<{
+    report: SkillSecurityReport
+    input: InstallSkillRequest
+  } | null>(null)
 
   const { data: skillsData } = useQuery({
     queryKey: ["skills"],
@@ -81,6 +88,7 @@ export function useHubMarketplace() {
   const installMutation = useMutation({
     mutationFn: installSkill,
     onSuccess: (response) => {
+      setPendingReview(null)
       toast.success(
         t("pages.agent.skills.install_success", {
           name: response.skill?.name ?? response.slug,
@@ -89,7 +97,11 @@ export function useHubMarketplace() {
       void queryClient.invalidateQueries({ queryKey: ["skills"] })
       void queryClient.invalidateQueries({ queryKey: ["skills-marketplace"] })
     },
-    onError: (err) => {
+    onError: (err, input) => {
+      if (err instanceof SkillSecurityError) {
+        setPendingReview({ report: err.report, input })
+        return
+      }
       toast.error(
         err instanceof Error
           ? err.message
@@ -155,6 +167,18 @@ export function useHubMarketplace() {
     })
   }
 
+  const handleConfirmInstall = () => {
+    if (pendingReview) {
+      installMutation.mutate({ ...pendingReview.input, confirm: true })
+    }
+  }
+
+  const handleSecurityDialogOpenChange = (open: boolean) => {
+    if (!open && !installMutation.isPending) {
+      setPendingReview(null)
+    }
+  }
+
   const handleViewInstalled = () => {
     void navigate({ to: "/agent/skills" })
   }
@@ -200,9 +224,13 @@ export function useHubMarketplace() {
     hasSubmittedQuery,
     isMarketSearchInitialLoading,
     isMarketSearchLoadingMore,
+    securityReport: pendingReview?.report ?? null,
+    isInstallConfirmPending: installMutation.isPending,
     setMarketQuery,
     handleSearchSubmit,
     handleInstall,
+    handleConfirmInstall,
+    handleSecurityDialogOpenChange,
     handleViewInstalled,
     handleScroll,
     getInstalledSkill,
@@ -0,0 +1,176 @@
+import { IconLink, IconX } from "@tabler/icons-react"
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query"
+import { useTranslation } from "react-i18next"
+import { toast } from "sonner"
+
+import {
+  type IdentityLinkGroup,
+  getIdentityLinks,
+  unlinkIdentity,
+} from "@/api/identity"
+import { PageHeader } from "@/components/page-header"
+import { Button } from "@/components/ui/button"
+import {
+  Card,
+  CardContent,
+  CardDescription,
+  CardHeader,
+  CardTitle,
+} from "@/components/ui/card"
+import { Skeleton } from "@/components/ui/skeleton"
+
+export function IdentitiesPage() {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const { data, isLoading, error } = useQuery({
+    queryKey: ["identity-links"],
+    queryFn: getIdentityLinks,
+  })
+
+  const unlinkMutation = useMutation({
+    mutationFn: unlinkIdentity,
+    onSuccess: () => {
+      toast.success(t("pages.agent.identities.unlink_success"))
+      void queryClient.invalidateQueries({ queryKey: ["identity-links"] })
+    },
+    onError: (err) => {
+      toast.error(
+        err instanceof Error
+          ? err.message
+          : t("pages.agent.identities.unlink_error"),
+      )
+    },
+  })
+
+  const links = data?.links ?? []
+  const configured = data?.configured ?? []
+
+  return (
+    <div className="bg-background flex h-full flex-col">
+      <PageHeader title={t("navigation.identities")} />
+
+      <div className="flex-1 overflow-auto px-6 py-6">
+        <div className="mx-auto w-full max-w-6xl space-y-8">
+          <p className="text-muted-foreground text-sm">
+            {t("pages.agent.identities.description")}
+          </p>
+
+          {error ? (
+            <Card className="border-destructive/50 bg-destructive/10 cursor-default">
+              <CardContent className="py-10 text-center">
+                <p className="text-destructive font-medium">
+                  {t("pages.agent.load_error")}
+                </p>
+              </CardContent>
+            </Card>
+          ) : isLoading ? (
+            <div className="grid gap-4 lg:grid-cols-2">
+              {[1, 2].map((i) => (
+                <Card key={i} className="border-border/60 shadow-none">
+                  <CardHeader>
+                    <Skeleton className="mb-2 h-5 w-48" />
+                    <Skeleton className="h-4 w-full" />
+                  </CardHeader>
+                </Card>
+              ))}
+            </div>
+          ) : (
+            <>
+              {links.length === 0 ? (
+                <Card className="bg-muted/30 cursor-default border-dashed">
+                  <CardContent className="flex flex-col items-center justify-center py-16 text-center text-sm">
+                    <div className="bg-muted mb-4 rounded-full p-4">
+                      <IconLink className="text-muted-foreground size-8" />
+                    </div>
+                    <h3 className="mb-1 text-lg font-medium">
+                      {t("pages.agent.identities.empty")}
+                    </h3>
+                    <p className="text-muted-foreground">
+                      {t("pages.agent.identities.empty_hint")}
+                    </p>
+                  </CardContent>
+                </Card>
+              ) : (
+                <div className="grid gap-4 lg:grid-cols-2">
+                  {links.map((group) => (
+                    <LinkGroupCard
+                      key={group.name}
+                      group={group}
+                      pendingID={
+                        unlinkMutation.isPending
+                          ? unlinkMutation.variables
+                          : undefined
+                      }
+                      onUnlink={(id) => unlinkMutation.mutate(id)}
+                    />
+                  ))}
+                </div>
+              )}
+
+              {configured.length > 0 && (
+                <div className="space-y-4">
+                  <h3 className="text-foreground text-sm font-semibold tracking-wide uppercase">
+                    {t("pages.agent.identities.configured")}
+                  </h3>
+                  <div className="grid gap-4 lg:grid-cols-2">
+                    {configured.map((group) => (
+                      <LinkGroupCard key={group.name} group={group} />
+                    ))}
+                  </div>
+                </div>
+              )}
+            </>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
+
+function LinkGroupCard({
+  group,
+  pendingID,
+  onUnlink,
+}: {
+  group: IdentityLinkGroup
+  pendingID?: string
+  onUnlink?: (id: string) => void
+}) {
+  const { t } = useTranslation()
+
+  return (
+    <Card className="border-border/60 cursor-default">
+      <CardHeader className="pb-3">
+        <CardTitle className="font-mono text-sm font-semibold break-all">
+          {group.name}
+        </CardTitle>
+        <CardDescription className="text-xs">
+          {t("pages.agent.identities.count", {
+            count: group.identities.length,
+          })}
+        </CardDescription>
+      </CardHeader>
+      <CardContent className="space-y-1 pt-0">
+        {group.identities.map((id) => (
+          <div
+            key={id}
+            className="flex items-center justify-between gap-2 font-mono text-xs"
+          >
+            <span className="break-all">{id}</span>
+            {onUnlink && (
+              <Button
+                variant="ghost"
+                size="icon-xs"
+                title={t("pages.agent.identities.unlink")}
+                disabled={pendingID === id}
+                onClick={() => onUnlink(id)}
+              >
+                <IconX />
+              </Button>
+            )}
+          </div>
+        ))}
+      </CardContent>
+    </Card>
+  )
+}
@@ -0,0 +1,123 @@
+import type { SkillSecurityReport } from "@/api/skills"
+import {
+  AlertDialog,
+  AlertDialogAction,
+  AlertDialogCancel,
+  AlertDialogContent,
+  AlertDialogDescription,
+  AlertDialogFooter,
+  AlertDialogHeader,
+  AlertDialogTitle,
+} from "@/components/ui/alert-dialog"
+import { cn } from "@/lib/utils"
+import { IconLoader2, IconShieldExclamation } from "@tabler/icons-react"
+import { useTranslation } from "react-i18next"
+
+interface SecurityReportDialogProps {
+  report: SkillSecurityReport | null
+  isPending: boolean
+  onOpenChange: (open: boolean) => void
+  onConfirm: () => void
+}
+
+export function SecurityReportDialog({
+  report,
+  isPending,
+  onOpenChange,
+  onConfirm,
+}: SecurityReportDialogProps) {
+  const { t } = useTranslation()
+  const isBlocked = report?.verdict === "block"
+
+  return (
+    <AlertDialog open={report !== null} onOpenChange={onOpenChange}>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>
+            {isBlocked
+              ? t("pages.agent.skills.security.blocked_title")
+              : t("pages.agent.skills.security.review_title")}
+          </AlertDialogTitle>
+          <AlertDialogDescription>
+            {isBlocked
+              ? t("pages.agent.skills.security.blocked_description", {
+                  name: report?.skill,
+                  reason: report?.reason,
+                })
+              : t("pages.agent.skills.security.review_description", {
+                  name: report?.skill,
+                })}
+          </AlertDialogDescription>
+        </AlertDialogHeader>
+
+        {report && (
+          <div className="space-y-3 text-sm">
+            <div className="text-muted-foreground">
+              {t("pages.agent.skills.security.signature")}:{" "}
+              <span className="text-foreground font-medium">
+                {t(`pages.agent.skills.security.signature_${report.signature}`, {
+                  defaultValue: report.signature,
+                  key: report.signed_by,
+                })}
+              </span>
+            </div>
+            <ul className="max-h-64 space-y-2 overflow-auto">
+              {report.findings.map((finding, index) => (
+                <li
+                  key={`${finding.file}:${finding.line ?? 0}:${finding.rule}:${index}`}
+                  className="rounded-md border px-3 py-2"
+                >
+                  <div className="flex items-center gap-2">
+                    <span
+                      className={cn(
+                        "rounded-full px-2 py-0.5 text-[11px] font-semibold",
+                        finding.severity === "critical"
+                          ? "bg-red-500/10 text-red-600"
+                          : "bg-amber-500/10 text-amber-600",
+                      )}
+                    >
+                      {t(`pages.agent.skills.security.severity_${finding.severity}`, {
+                        defaultValue: finding.severity,
+                      })}
+                    </span>
+                    <span className="font-medium">{finding.rule}</span>
+                    <span className="text-muted-foreground font-mono text-xs">
+                      {finding.line
+                        ? `${finding.file}:${finding.line}`
+                        : finding.file}
+                    </span>
+                  </div>
+                  <p className="text-muted-foreground mt-1 text-xs break-words">
+                    {finding.detail}
+                  </p>
+                </li>
+              ))}
+            </ul>
+          </div>
+        )}
+
+        <AlertDialogFooter>
+          <AlertDialogCancel disabled={isPending}>
+            {isBlocked
+              ? t("pages.agent.skills.security.close")
+              : t("common.cancel")}
+          </AlertDialogCancel>
+          {!isBlocked && (
+            <AlertDialogAction
+              variant="destructive"
+              disabled={isPending}
+              onClick={onConfirm}
+            >
+              {isPending ? (
+                <IconLoader2 className="size-4 animate-spin" />
+              ) : (
+                <IconShieldExclamation className="size-4" />
+              )}
+              {t("pages.agent.skills.security.install_anyway")}
+            </AlertDialogAction>
+          )}
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
@@ -9,6 +9,7 @@ import { DetailSheet } from "./detail-sheet"
 import { FilterBar } from "./filter-bar"
 import { ImportDialog } from "./import-dialog"
 import { PageSkeleton } from "./page-skeleton"
+import { SecurityReportDialog } from "./security-report-dialog"
 import { SkillsList } from "./skills-list"
 import { Stats } from "./stats"
 import { useSkillsPage } from "./use-skills-page"
@@ -25,6 +26,7 @@ export function SkillsPage() {
     isImportDialogOpen,
     selectedSkill,
     skillPendingDelete,
+    securityReport,
     availableOrigins,
     groupedSkills,
     stats,
@@ -47,6 +49,7 @@ export function SkillsPage() {
     handleViewSkill,
     handleRequestDelete,
     handleConfirmDelete,
+    handleConfirmImport,
     handleImportClick,
     handleImportFileChange,
     handleDropZoneDragEnter,
@@ -55,6 +58,7 @@ export function SkillsPage() {
     handleDetailSheetOpenChange,
     handleImportDialogOpenChange,
     handleDeleteDialogOpenChange,
+    handleSecurityDialogOpenChange,
   } = useSkillsPage()
 
   return (
@@ -155,6 +159,13 @@ export function SkillsPage() {
         onOpenChange={handleDeleteDialogOpenChange}
         onConfirm={handleConfirmDelete}
       />
+
+      <SecurityReportDialog
+        report={securityReport}
+        isPending={isImportPending}
+        onOpenChange={handleSecurityDialogOpenChange}
+        onConfirm={handleConfirmImport}
+      />
     </div>
   )
 }
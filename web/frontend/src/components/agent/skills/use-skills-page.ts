@@ -12,6 +12,8 @@ import { useTranslation } from "react-i18next"
 import { toast } from "sonner"
 
 import {
+  SkillSecurityError,
+  type SkillSecurityReport,
   type SkillSupportItem,
   deleteSkill,
   getSkill,
@@ -54,6 +56,10 @@ export function useSkillsPage() {
   )
   const [skillPendingDelete, setSkillPendingDelete] =
     useState<SkillSupportItem | null>(null)
+  const [pendingReview, setPendingReview] = useState<{
+    report: SkillSecurityReport
+    file: File
+  } | null>(null)
 
   const skillsQuery = useQuery({
     queryKey: ["skills"],
@@ -67,8 +73,10 @@ export function useSkillsPage() {
   })
 
   const importMutation = useMutation({
-    mutationFn: async (file: File) => importSkill(file),
+    mutationFn: async ({ file, confirm }: { file: File; confirm?: boolean }) =>
+      importSkill(file, confirm),
     onSuccess: (importedSkill) => {
+      setPendingReview(null)
       toast.success(t("pages.agent.skills.import_success"))
       startTransition(() => {
         setIsImportDialogOpen(false)
@@ -89,7 +97,11 @@ export function useSkillsPage() {
       })
       void queryClient.invalidateQueries({ queryKey: ["skills"] })
     },
-    onError: (err) => {
+    onError: (err, { file }) => {
+      if (err instanceof SkillSecurityError) {
+        setPendingReview({ report: err.report, file })
+        return
+      }
       toast.error(
         err instanceof Error
           ? err.message
@@ -255,7 +267,19 @@ export function useSkillsPage() {
       toast.error(validationMessage)
       return
     }
-    importMutation.mutate(file)
+    importMutation.mutate({ file })
+  }
+
+  const handleConfirmImport = () => {
+    if (pendingReview) {
+      importMutation.mutate({ file: pendingReview.file, confirm: true })
+    }
+  }
+
+  const handleSecurityDialogOpenChange = (open: boolean) => {
+    if (!open && !importMutation.isPending) {
+      setPendingReview(null)
+    }
   }
 
   const handleImportFileChange = (event: ChangeEvent<HTMLInputElement>) => {
@@ -302,6 +326,7 @@ export function useSkillsPage() {
     isImportDialogOpen,
     selectedSkill,
     skillPendingDelete,
+    securityReport: pendingReview?.report ?? null,
     availableOrigins,
     groupedSkills,
     stats,
@@ -324,6 +349,7 @@ export function useSkillsPage() {
     handleViewSkill,
     handleRequestDelete,
     handleConfirmDelete,
+    handleConfirmImport,
     handleImportClick,
     handleImportFileChange,
     handleDropZoneDragEnter,
@@ -332,5 +358,6 @@ export function useSkillsPage() {
     handleDetailSheetOpenChange,
     handleImportDialogOpenChange,
     handleDeleteDialogOpenChange,
+    handleSecurityDialogOpenChange,
   }
 }
@@ -4,6 +4,7 @@ import {
   IconChevronsDown,
   IconChevronsUp,
   IconKey,
+  IconLink,
   IconListDetails,
   IconMessageCircle,
   IconSearch,
@@ -159,6 +160,12 @@ export function AppSidebar({ ...props }: React.ComponentProps<typeof Sidebar>) {
             icon: IconTools,
             translateTitle: true,
           },
+          {
+            title: "navigation.identities",
+            url: "/agent/identities",
+            icon: IconLink,
+            translateTitle: true,
+          },
         ],
       },
       {
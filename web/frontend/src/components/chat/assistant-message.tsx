@@ -7,15 +7,20 @@ import remarkGfm from "remark-gfm"
 
 import { Button } from "@/components/ui/button"
 import { formatMessageTime } from "@/hooks/use-pico-chat"
+import type { ChatAction } from "@/store/chat"
 
 interface AssistantMessageProps {
   content: string
   timestamp?: string | number
+  actions?: ChatAction[]
+  onAction?: (action: ChatAction) => void
 }
 
 export function AssistantMessage({
   content,
   timestamp = "",
+  actions,
+  onAction,
 }: AssistantMessageProps) {
   const [isCopied, setIsCopied] = useState(false)
   const formattedTimestamp =
@@ -51,6 +56,20 @@ export function AssistantMessage({
             {content}
           </ReactMarkdown>
         </div>
+        {actions && actions.length > 0 && (
+          <div className="flex flex-wrap gap-2 px-4 pb-4">
+            {actions.map((action) => (
+              <Button
+                key={action.id}
+                variant="outline"
+                size="sm"
+                onClick={() => onAction?.(action)}
+              >
+                {action.label}
+              </Button>
+            ))}
+          </div>
+        )}
         <Button
           variant="ghost"
           size="icon"
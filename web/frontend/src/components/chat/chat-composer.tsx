@@ -1,10 +1,11 @@
-import { IconArrowUp } from "@tabler/icons-react"
+import { IconArrowUp, IconPhone, IconPhoneOff } from "@tabler/icons-react"
 import type { KeyboardEvent } from "react"
 import { useTranslation } from "react-i18next"
 import TextareaAutosize from "react-textarea-autosize"
 
 import { Button } from "@/components/ui/button"
 import { cn } from "@/lib/utils"
+import type { VoiceCallState } from "@/store/chat"
 
 interface ChatComposerProps {
   input: string
@@ -12,6 +13,8 @@ interface ChatComposerProps {
   onSend: () => void
   isConnected: boolean
   hasDefaultModel: boolean
+  voiceCallState: VoiceCallState
+  onToggleVoiceCall: () => void
 }
 
 export function ChatComposer({
@@ -20,6 +23,8 @@ export function ChatComposer({
   onSend,
   isConnected,
   hasDefaultModel,
+  voiceCallState,
+  onToggleVoiceCall,
 }: ChatComposerProps) {
   const { t } = useTranslation()
   const canInput = isConnected && hasDefaultModel
@@ -50,7 +55,34 @@ export function ChatComposer({
         />
 
         <div className="mt-2 flex items-center justify-between px-1">
-          <div className="flex items-center gap-1">{/* action buttons */}</div>
+          <div className="flex items-center gap-1">
+            <Button
+              size="icon"
+              variant="ghost"
+              className={cn(
+                "size-8 rounded-full",
+                voiceCallState !== "idle" && "text-red-500 hover:text-red-600",
+              )}
+              onClick={onToggleVoiceCall}
+              disabled={!canInput && voiceCallState === "idle"}
+              title={
+                voiceCallState === "idle"
+                  ? t("chat.voiceCall.start")
+                  : t("chat.voiceCall.end")
+              }
+            >
+              {voiceCallState === "idle" ? (
+                <IconPhone className="size-4" />
+              ) : (
+                <IconPhoneOff
+                  className={cn(
+                    "size-4",
+                    voiceCallState === "connecting" && "animate-pulse",
+                  )}
+                />
+              )}
+            </Button>
+          </div>
 
           <Button
             size="icon"
@@ -26,9 +26,12 @@ export function ChatPage() {
   const {
     messages,
     connectionState,
+    voiceCallState,
     isTyping,
     activeSessionId,
+    toggleVoiceCall,
     sendMessage,
+    sendAction,
     switchSession,
     newChat,
   } = usePicoChat()
@@ -152,6 +155,10 @@ export function ChatPage() {
                 <AssistantMessage
                   content={msg.content}
                   timestamp={msg.timestamp}
+                  actions={msg.actions}
+                  onAction={(action) =>
+                    msg.promptId && sendAction(msg.id, msg.promptId, action)
+                  }
                 />
               ) : (
                 <UserMessage content={msg.content} />
@@ -169,6 +176,8 @@ export function ChatPage() {
         onSend={handleSend}
         isConnected={isChatConnected}
         hasDefaultModel={Boolean(defaultModelName)}
+        voiceCallState={voiceCallState}
+        onToggleVoiceCall={toggleVoiceCall}
       />
     </div>
   )
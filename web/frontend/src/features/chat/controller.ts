@@ -12,13 +12,18 @@ import {
   generateSessionId,
   readStoredSessionId,
 } from "@/features/chat/state"
+import { endVoiceCall, startVoiceCall } from "@/features/chat/voice"
 import {
   invalidateSocket,
   isCurrentSocket,
   normalizeWsUrlForBrowser,
 } from "@/features/chat/websocket"
 import i18n from "@/i18n"
-import { getChatState, updateChatStore } from "@/store/chat"
+import {
+  type ChatAction,
+  getChatState,
+  updateChatStore,
+} from "@/store/chat"
 import { type GatewayState, gatewayAtom } from "@/store/gateway"
 
 const store = getDefaultStore()
@@ -99,6 +104,7 @@ function disconnectChatInternal({
   wsRef = null
   isConnecting = false
 
+  endVoiceCall({ notify: false })
   invalidateSocket(socket)
 
   updateChatStore({
@@ -212,6 +218,7 @@ export async function connectChat() {
       }
       wsRef = null
       isConnecting = false
+      endVoiceCall({ notify: false })
       updateChatStore({
         connectionState: "disconnected",
         isTyping: false,
@@ -360,6 +367,79 @@ export function sendChatMessage(content: string) {
   }
 }
 
+export function sendChatAction(
+  messageId: string,
+  promptId: string,
+  action: ChatAction,
+) {
+  if (!wsRef || wsRef.readyState !== WebSocket.OPEN) {
+    console.warn("WebSocket not connected")
+    return false
+  }
+
+  try {
+    wsRef.send(
+      JSON.stringify({
+        type: "message.action",
+        payload: {
+          prompt_id: promptId,
+          action_id: action.id,
+          label: action.label,
+        },
+      }),
+    )
+  } catch (error) {
+    console.error("Failed to send pico action:", error)
+    return false
+  }
+
+  updateChatStore((prev) => ({
+    messages: [
+      ...prev.messages.map((msg) =>
+        msg.id === messageId
+          ? { ...msg, promptId: undefined, actions: undefined }
+          : msg,
+      ),
+      {
+        id: `msg-${++msgIdCounter}-${Date.now()}`,
+        role: "user",
+        content: action.label,
+        timestamp: Date.now(),
+      },
+    ],
+    isTyping: true,
+  }))
+  return true
+}
+
+function sendSignal(message: PicoMessage) {
+  if (!wsRef || wsRef.readyState !== WebSocket.OPEN) {
+    return false
+  }
+
+  try {
+    wsRef.send(JSON.stringify(message))
+    return true
+  } catch (error) {
+    console.error("Failed to send pico signal:", error)
+    return false
+  }
+}
+
+export async function toggleVoiceCall() {
+  if (getChatState().voiceCallState !== "idle") {
+    endVoiceCall()
+    return
+  }
+
+  try {
+    await startVoiceCall(sendSignal)
+  } catch (error) {
+    console.error("Failed to start voice call:", error)
+    toast.error(i18n.t("chat.voiceCall.failed"))
+  }
+}
+
 export async function switchChatSession(sessionId: string) {
   if (sessionId === activeSessionIdRef) {
     return
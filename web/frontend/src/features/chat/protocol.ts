@@ -1,5 +1,10 @@
 import { normalizeUnixTimestamp } from "@/features/chat/state"
-import { updateChatStore } from "@/store/chat"
+import {
+  endVoiceCall,
+  handleVoiceSignal,
+  isVoiceError,
+} from "@/features/chat/voice"
+import { type ChatAction, updateChatStore } from "@/store/chat"
 
 export interface PicoMessage {
   type: string
@@ -28,6 +33,10 @@ export function handlePicoMessage(
         Number.isFinite(Number(message.timestamp))
           ? normalizeUnixTimestamp(Number(message.timestamp))
           : Date.now()
+      const promptId = payload.prompt_id as string | undefined
+      const actions = Array.isArray(payload.actions)
+        ? (payload.actions as ChatAction[])
+        : undefined
 
       updateChatStore((prev) => ({
         messages: [
@@ -37,6 +46,7 @@ export function handlePicoMessage(
             role: "assistant",
             content,
             timestamp,
+            ...(promptId && actions ? { promptId, actions } : {}),
           },
         ],
         isTyping: false,
@@ -67,8 +77,17 @@ export function handlePicoMessage(
       updateChatStore({ isTyping: false })
       break
 
+    case "webrtc.answer":
+    case "webrtc.candidate":
+      void handleVoiceSignal(message)
+      break
+
     case "error":
       console.error("Pico error:", payload)
+      if (isVoiceError(payload.code)) {
+        endVoiceCall({ notify: false })
+        break
+      }
       updateChatStore({ isTyping: false })
       break
 
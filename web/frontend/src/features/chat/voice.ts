@@ -0,0 +1,164 @@
+import type { PicoMessage } from "@/features/chat/protocol"
+import { updateChatStore } from "@/store/chat"
+
+type SignalSender = (message: PicoMessage) => boolean
+
+let peer: RTCPeerConnection | null = null
+let localStream: MediaStream | null = null
+let remoteAudio: HTMLAudioElement | null = null
+let sendSignal: SignalSender | null = null
+let pendingCandidates: RTCIceCandidateInit[] = []
+
+// Errors the gateway reports for a failed call negotiation.
+const VOICE_ERROR_CODES = new Set([
+  "voice_disabled",
+  "invalid_offer",
+  "webrtc_failed",
+  "no_call",
+])
+
+export function isVoiceError(code: unknown): boolean {
+  return typeof code === "string" && VOICE_ERROR_CODES.has(code)
+}
+
+function releaseCall() {
+  if (peer) {
+    peer.ontrack = null
+    peer.onicecandidate = null
+    peer.onconnectionstatechange = null
+    peer.close()
+    peer = null
+  }
+  localStream?.getTracks().forEach((track) => track.stop())
+  localStream = null
+  if (remoteAudio) {
+    remoteAudio.srcObject = null
+    remoteAudio = null
+  }
+  sendSignal = null
+  pendingCandidates = []
+  updateChatStore({ voiceCallState: "idle" })
+}
+
+export async function startVoiceCall(send: SignalSender) {
+  if (peer) {
+    return
+  }
+
+  sendSignal = send
+  updateChatStore({ voiceCallState: "connecting" })
+
+  try {
+    localStream = await navigator.mediaDevices.getUserMedia({
+      audio: { echoCancellation: true, noiseSuppression: true },
+    })
+
+    const pc = new RTCPeerConnection()
+    peer = pc
+
+    const stream = localStream
+    stream.getAudioTracks().forEach((track) => pc.addTrack(track, stream))
+
+    pc.ontrack = (event) => {
+      if (!remoteAudio) {
+        remoteAudio = new Audio()
+        remoteAudio.autoplay = true
+      }
+      remoteAudio.srcObject = event.streams[0] ?? new MediaStream([event.track])
+    }
+
+    pc.onicecandidate = (event) => {
+      if (!event.candidate) {
+        return
+      }
+      sendSignal?.({
+        type: "webrtc.candidate",
+        payload: {
+          candidate: event.candidate.candidate,
+          sdp_mid: event.candidate.sdpMid,
+          sdp_mline_index: event.candidate.sdpMLineIndex,
+        },
+      })
+    }
+
+    pc.onconnectionstatechange = () => {
+      switch (pc.connectionState) {
+        case "connected":
+          updateChatStore({ voiceCallState: "active" })
+          break
+        case "failed":
+        case "closed":
+          endVoiceCall()
+          break
+      }
+    }
+
+    const offer = await pc.createOffer()
+    await pc.setLocalDescription(offer)
+
+    const sent = send({
+      type: "webrtc.offer",
+      id: `call-${Date.now()}`,
+      payload: { sdp: offer.sdp },
+    })
+    if (!sent) {
+      throw new Error("WebSocket not connected")
+    }
+  } catch (error) {
+    releaseCall()
+    throw error
+  }
+}
+
+// endVoiceCall hangs up the current call. The gateway is only notified while
+// the signalling socket is still usable.
+export function endVoiceCall({ notify = true }: { notify?: boolean } = {}) {
+  if (!peer) {
+    return
+  }
+  if (notify) {
+    sendSignal?.({ type: "webrtc.hangup" })
+  }
+  releaseCall()
+}
+
+export async function handleVoiceSignal(message: PicoMessage) {
+  const pc = peer
+  if (!pc) {
+    return
+  }
+
+  const payload = message.payload || {}
+
+  try {
+    if (message.type === "webrtc.answer") {
+      await pc.setRemoteDescription({
+        type: "answer",
+        sdp: payload.sdp as string,
+      })
+      const queued = pendingCandidates
+      pendingCandidates = []
+      for (const candidate of queued) {
+        await pc.addIceCandidate(candidate)
+      }
+      return
+    }
+
+    if (message.type === "webrtc.candidate") {
+      const candidate: RTCIceCandidateInit = {
+        candidate: payload.candidate as string,
+        sdpMid: (payload.sdp_mid as string | undefined) ?? null,
+        sdpMLineIndex:
+          (payload.sdp_mline_index as number | undefined) ?? null,
+      }
+      if (!pc.remoteDescription) {
+        pendingCandidates.push(candidate)
+        return
+      }
+      await pc.addIceCandidate(candidate)
+    }
+  } catch (error) {
+    console.error("Voice call signalling failed:", error)
+    endVoiceCall()
+  }
+}
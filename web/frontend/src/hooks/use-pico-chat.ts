@@ -3,8 +3,10 @@ import { useAtomValue } from "jotai"
 
 import {
   newChatSession,
+  sendChatAction,
   sendChatMessage,
   switchChatSession,
+  toggleVoiceCall,
 } from "@/features/chat/controller"
 import { chatAtom } from "@/store/chat"
 
@@ -55,15 +57,23 @@ export function formatMessageTime(dateRaw: number | string | Date): string {
 }
 
 export function usePicoChat() {
-  const { messages, connectionState, isTyping, activeSessionId } =
-    useAtomValue(chatAtom)
+  const {
+    messages,
+    connectionState,
+    voiceCallState,
+    isTyping,
+    activeSessionId,
+  } = useAtomValue(chatAtom)
 
   return {
     messages,
     connectionState,
+    voiceCallState,
     isTyping,
     activeSessionId,
+    toggleVoiceCall,
     sendMessage: sendChatMessage,
+    sendAction: sendChatAction,
     switchSession: switchChatSession,
     newChat: newChatSession,
   }
@@ -21,6 +21,7 @@ import { Route as ConfigRawRouteImport } from './routes/config.raw'
 import { Route as ChannelsNameRouteImport } from './routes/channels/$name'
 import { Route as AgentToolsRouteImport } from './routes/agent/tools'
 import { Route as AgentSkillsRouteImport } from './routes/agent/skills'
+import { Route as AgentIdentitiesRouteImport } from './routes/agent/identities'
 import { Route as AgentHubRouteImport } from './routes/agent/hub'
 
 const ModelsRoute = ModelsRouteImport.update({
@@ -83,6 +84,11 @@ const AgentSkillsRoute = AgentSkillsRouteImport.update({
   path: '/skills',
   getParentRoute: () => AgentRoute,
 } as any)
+const AgentIdentitiesRoute = AgentIdentitiesRouteImport.update({
+  id: '/identities',
+  path: '/identities',
+  getParentRoute: () => AgentRoute,
+} as any)
 const AgentHubRoute = AgentHubRouteImport.update({
   id: '/hub',
   path: '/hub',
@@ -99,6 +105,7 @@ export interface FileRoutesByFullPath {
   '/logs': typeof LogsRoute
   '/models': typeof ModelsRoute
   '/agent/hub': typeof AgentHubRoute
+  '/agent/identities': typeof AgentIdentitiesRoute
   '/agent/skills': typeof AgentSkillsRoute
   '/agent/tools': typeof AgentToolsRoute
   '/channels/$name': typeof ChannelsNameRoute
@@ -114,6 +121,7 @@ export interface FileRoutesByTo {
   '/logs': typeof LogsRoute
   '/models': typeof ModelsRoute
   '/agent/hub': typeof AgentHubRoute
+  '/agent/identities': typeof AgentIdentitiesRoute
   '/agent/skills': typeof AgentSkillsRoute
   '/agent/tools': typeof AgentToolsRoute
   '/channels/$name': typeof ChannelsNameRoute
@@ -130,6 +138,7 @@ export interface FileRoutesById {
   '/logs': typeof LogsRoute
   '/models': typeof ModelsRoute
   '/agent/hub': typeof AgentHubRoute
+  '/agent/identities': typeof AgentIdentitiesRoute
   '/agent/skills': typeof AgentSkillsRoute
   '/agent/tools': typeof AgentToolsRoute
   '/channels/$name': typeof ChannelsNameRoute
@@ -147,6 +156,7 @@ export interface FileRouteTypes {
     | '/logs'
     | '/models'
     | '/agent/hub'
+    | '/agent/identities'
     | '/agent/skills'
     | '/agent/tools'
     | '/channels/$name'
@@ -162,6 +172,7 @@ export interface FileRouteTypes {
     | '/logs'
     | '/models'
     | '/agent/hub'
+    | '/agent/identities'
     | '/agent/skills'
     | '/agent/tools'
     | '/channels/$name'
@@ -177,6 +188,7 @@ export interface FileRouteTypes {
     | '/logs'
     | '/models'
     | '/agent/hub'
+    | '/agent/identities'
     | '/agent/skills'
     | '/agent/tools'
     | '/channels/$name'
@@ -280,6 +292,13 @@ declare module '@tanstack/react-router' {
       preLoaderRoute: typeof AgentSkillsRouteImport
       parentRoute: typeof AgentRoute
     }
+    '/agent/identities': {
+      id: '/agent/identities'
+      path: '/identities'
+      fullPath: '/agent/identities'
+      preLoaderRoute: typeof AgentIdentitiesRouteImport
+      parentRoute: typeof AgentRoute
+    }
     '/agent/hub': {
       id: '/agent/hub'
       path: '/hub'
@@ -304,12 +323,14 @@ const ChannelsRouteRouteWithChildren = ChannelsRouteRoute._addFileChildren(
 
 interface AgentRouteChildren {
   AgentHubRoute: typeof AgentHubRoute
+  AgentIdentitiesRoute: typeof AgentIdentitiesRoute
   AgentSkillsRoute: typeof AgentSkillsRoute
   AgentToolsRoute: typeof AgentToolsRoute
 }
 
 const AgentRouteChildren: AgentRouteChildren = {
   AgentHubRoute: AgentHubRoute,
+  AgentIdentitiesRoute: AgentIdentitiesRoute,
   AgentSkillsRoute: AgentSkillsRoute,
   AgentToolsRoute: AgentToolsRoute,
 }
@@ -0,0 +1,11 @@
+import { createFileRoute } from "@tanstack/react-router"
+
+import { IdentitiesPage } from "@/components/agent/identities/identities-page"
+
+export const Route = createFileRoute("/agent/identities")({
+  component: AgentIdentitiesRoute,
+})
+
+function AgentIdentitiesRoute() {
+  return <IdentitiesPage />
+}
@@ -5,11 +5,19 @@ import {
   writeStoredSessionId,
 } from "@/features/chat/state"
 
+export interface ChatAction {
+  id: string
+  label: string
+}
+
 export interface ChatMessage {
   id: string
   role: "user" | "assistant"
   content: string
   timestamp: number | string
+  // Choice prompt sent by the ask_user tool; cleared once answered.
+  promptId?: string
+  actions?: ChatAction[]
 }
 
 export type ConnectionState =
@@ -18,9 +26,12 @@ export type ConnectionState =
   | "connected"
   | "error"
 
+export type VoiceCallState = "idle" | "connecting" | "active"
+
 export interface ChatStoreState {
   messages: ChatMessage[]
   connectionState: ConnectionState
+  voiceCallState: VoiceCallState
   isTyping: boolean
   activeSessionId: string
   hasHydratedActiveSession: boolean
@@ -31,6 +42,7 @@ type ChatStorePatch = Partial<ChatStoreState>
 const DEFAULT_CHAT_STATE: ChatStoreState = {
   messages: [],
   connectionState: "disconnected",
+  voiceCallState: "idle",
   isTyping: false,
   activeSessionId: getInitialActiveSessionId(),
   hasHydratedActiveSession: false,